@@ -0,0 +1,1008 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"openai-mokku/api"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// adminPathPrefix is the path prefix for mokku's own control endpoints.
+const adminPathPrefix = "/_mokku"
+
+// AdminHandler serves the mokku control API under adminPathPrefix.
+type AdminHandler struct {
+	*serverState
+	instanceID string
+	mux        *http.ServeMux
+	routes     []endpointInfo
+	public     map[string]bool
+	// readOnly are the patterns of the endpoints that change no state: read tokens may call them and
+	// they are not audited.
+	readOnly map[string]bool
+}
+
+// NewAdminHandler creates a new admin handler backed by the given state.
+func NewAdminHandler(state *serverState, instanceID string) *AdminHandler {
+	h := &AdminHandler{
+		serverState: state,
+		instanceID:  instanceID,
+		mux:         http.NewServeMux(),
+		public:      map[string]bool{},
+		readOnly:    map[string]bool{},
+	}
+	h.handle(http.MethodGet, "/capabilities", h.handleGetCapabilities)
+	h.handleReadOnly(http.MethodPost, "/embeddings/search", h.handleEmbeddingSearch)
+	h.handle(http.MethodDelete, "/embeddings", h.handleEmbeddingReset)
+	h.handlePublic(http.MethodGet, "/images/{id}", h.handleGetImage)
+	h.handleReadOnly(http.MethodPost, "/tokenize", h.handleTokenize)
+	h.handle(http.MethodGet, "/streams", h.handleGetStreams)
+	h.handle(http.MethodDelete, "/streams", h.handleStreamsReset)
+	h.handle(http.MethodGet, "/connections", h.handleGetConnections)
+	h.handle(http.MethodDelete, "/connections", h.handleConnectionsReset)
+	h.handle(http.MethodGet, "/overhead", h.handleGetOverhead)
+	h.handle(http.MethodDelete, "/overhead", h.handleOverheadReset)
+	h.handle(http.MethodGet, "/models", h.handleListModelMetadata)
+	h.handle(http.MethodGet, "/models/{model}", h.handleGetModelMetadata)
+	h.handle(http.MethodGet, "/flags", h.handleGetFlags)
+	h.handle(http.MethodPut, "/flags/{name}", h.handleSetFlag)
+	h.handle(http.MethodGet, "/audit", h.handleGetAudit)
+	h.handle(http.MethodGet, "/requests", h.handleListRequests)
+	h.handle(http.MethodGet, "/requests/{id}", h.handleGetRequest)
+	h.handle(http.MethodGet, "/requests/{id}/body", h.handleGetRequestBody)
+	h.handle(http.MethodGet, "/requests/{id}/bundle", h.handleGetRequestBundle)
+	h.handle(http.MethodDelete, "/requests", h.handleRequestsReset)
+	h.handle(http.MethodGet, "/capture", h.handleGetCapture)
+	h.handle(http.MethodGet, "/tags", h.handleGetTags)
+	h.handle(http.MethodDelete, "/tags", h.handleTagsReset)
+	h.handle(http.MethodGet, "/alerts", h.handleGetAlerts)
+	h.handle(http.MethodDelete, "/alerts", h.handleAlertsReset)
+	h.handle(http.MethodGet, "/banned-users", h.handleListBannedUsers)
+	h.handle(http.MethodPut, "/banned-users/{id}", h.handleBanUser)
+	h.handle(http.MethodDelete, "/banned-users/{id}", h.handleUnbanUser)
+	h.handle(http.MethodGet, "/clock", h.handleGetClock)
+	h.handle(http.MethodPost, "/clock/advance", h.handleAdvanceClock)
+	h.handle(http.MethodDelete, "/clock", h.handleResetClock)
+	h.handle(http.MethodGet, "/scenarios", h.handleListScenarios)
+	h.handle(http.MethodPut, "/scenarios", h.handleReplaceScenarios)
+	h.handle(http.MethodGet, "/scenarios/deleted", h.handleListDeletedScenarios)
+	h.handle(http.MethodDelete, "/scenarios/{name}", h.handleDeleteScenario)
+	h.handle(http.MethodPost, "/scenarios/{name}/restore", h.handleRestoreScenario)
+	h.handleReadOnly(http.MethodPost, "/evaluate", h.handleEvaluate)
+	h.handle(http.MethodGet, "/verify", h.handleVerify)
+	h.handle(http.MethodDelete, "/verify", h.handleVerifyReset)
+	h.handle(http.MethodGet, "/regions", h.handleGetRegions)
+	h.handle(http.MethodPut, "/regions/{name}", h.handleSetRegion)
+	h.handle(http.MethodGet, "/chaos", h.handleGetChaos)
+	h.handle(http.MethodPut, "/chaos", h.handleSetChaos)
+	h.handle(http.MethodDelete, "/chaos", h.handleStopChaos)
+	h.handle(http.MethodGet, "/timeline", h.handleGetTimeline)
+	h.handle(http.MethodDelete, "/timeline", h.handleTimelineReset)
+	sortEndpoints(h.routes)
+	return h
+}
+
+// handle registers a control endpoint under adminPathPrefix and records it for capability discovery.
+// GET endpoints are read-only; endpoints of other methods change state unless registered with
+// handleReadOnly.
+func (h *AdminHandler) handle(method, path string, handler http.HandlerFunc) {
+	h.mux.HandleFunc(method+" "+adminPathPrefix+path, handler)
+	h.routes = append(h.routes, endpointInfo{Method: method, Path: adminPathPrefix + path})
+	if !isAdminMutation(method) {
+		h.readOnly[method+" "+adminPathPrefix+path] = true
+	}
+}
+
+// handleReadOnly registers a control endpoint that changes no state despite its method, such as a
+// POST carrying a query, so read tokens may call it and it is not audited.
+func (h *AdminHandler) handleReadOnly(method, path string, handler http.HandlerFunc) {
+	h.handle(method, path, handler)
+	h.readOnly[method+" "+adminPathPrefix+path] = true
+}
+
+// handlePublic registers a control endpoint that is served without authentication, for URLs that
+// the API hands out to clients.
+func (h *AdminHandler) handlePublic(method, path string, handler http.HandlerFunc) {
+	h.handle(method, path, handler)
+	h.public[method+" "+adminPathPrefix+path] = true
+}
+
+// ServeHTTP implements http.Handler
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	_, pattern := h.mux.Handler(r)
+	if h.public[pattern] {
+		h.mux.ServeHTTP(w, r)
+		return
+	}
+	principal, authenticated := h.auth.Authenticate(r)
+	// Unknown paths have no pattern and are classified by their method
+	mutation := !h.readOnly[pattern] && (pattern != "" || isAdminMutation(r.Method))
+	if !mutation {
+		if h.authorize(w, principal, authenticated, r.Method, false) {
+			h.mux.ServeHTTP(w, r)
+		}
+		return
+	}
+
+	// Record mutations in the audit trail, including the (truncated) request body and denied attempts
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeInvalidRequestError(w, "Failed to read request body")
+		return
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(body) > maxAuditBodyBytes {
+		body = body[:maxAuditBodyBytes]
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	if h.authorize(rec, principal, authenticated, r.Method, true) {
+		h.mux.ServeHTTP(rec, r)
+	}
+
+	actor := principal.Name
+	if !authenticated {
+		actor = adminActor(r)
+	}
+	entry := h.audit.Record(auditEntry{
+		Time:       time.Now(),
+		Actor:      actor,
+		RemoteAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Action:     pattern,
+		Body:       string(body),
+		Status:     rec.status,
+	})
+	log.Printf("Admin %s %s by %s -> %d (audit #%d)", entry.Method, entry.Path, entry.Actor, entry.Status, entry.ID)
+}
+
+// authorize writes a 401 for unauthenticated requests and a 403 for mutations the principal's role
+// does not allow, and reports whether the request may proceed.
+func (h *AdminHandler) authorize(w http.ResponseWriter, principal adminPrincipal, authenticated bool, method string, mutation bool) bool {
+	if !authenticated {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="mokku-admin"`)
+		writeOpenAIError(w, http.StatusUnauthorized, OpenAIErrorDetail{
+			Message: "Missing or invalid admin token. Send it as 'Authorization: Bearer <token>'.",
+			Type:    "invalid_request_error",
+			Code:    "invalid_admin_token",
+		})
+		return false
+	}
+	if !principal.Allows(mutation) {
+		writeOpenAIError(w, http.StatusForbidden, OpenAIErrorDetail{
+			Message: fmt.Sprintf("Admin token %q has the %s role and cannot make %s requests that change state.", principal.Name, principal.Role, method),
+			Type:    "invalid_request_error",
+			Code:    "insufficient_permissions",
+		})
+		return false
+	}
+	return true
+}
+
+// Capabilities describes the endpoints, modes, and flags of this instance.
+func (h *AdminHandler) Capabilities() (capabilitiesResponse, error) {
+	spec, err := loadOpenAPISpec()
+	if err != nil {
+		return capabilitiesResponse{}, err
+	}
+	return capabilitiesResponse{
+		Object:           "mokku.capabilities",
+		Version:          serviceVersion,
+		SpecVersion:      spec.Info.Version,
+		Instance:         h.instanceID,
+		Endpoints:        specEndpoints(spec),
+		ControlEndpoints: h.routes,
+		Build:            buildVariant,
+		CompatModes:      []string{"openai"},
+		MagicModels:      magicModels,
+		FeatureFlags:     h.flags.Values(),
+		Seed:             h.seeds.Global(),
+	}, nil
+}
+
+// handleGetCapabilities reports what this instance supports, so test harnesses can skip
+// tests for unsupported features.
+func (h *AdminHandler) handleGetCapabilities(w http.ResponseWriter, r *http.Request) {
+	caps, err := h.Capabilities()
+	if err != nil {
+		writeOpenAIError(w, http.StatusInternalServerError, OpenAIErrorDetail{
+			Message: err.Error(),
+			Type:    "server_error",
+			Code:    "server_error",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, caps)
+}
+
+// embeddingSearchRequest is the request body for POST /_mokku/embeddings/search
+type embeddingSearchRequest struct {
+	Query string `json:"query"`
+	Model string `json:"model"`
+	TopK  int    `json:"top_k"`
+}
+
+// embeddingSearchResponse is the response body for POST /_mokku/embeddings/search
+type embeddingSearchResponse struct {
+	Object string           `json:"object"`
+	Data   []embeddingMatch `json:"data"`
+}
+
+// handleEmbeddingSearch ranks previously embedded inputs by similarity to the query.
+func (h *AdminHandler) handleEmbeddingSearch(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.EmbeddingSearch")
+	defer span.End()
+
+	var req embeddingSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	if req.Query == "" {
+		writeInvalidRequestError(w, "query is required")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, embeddingSearchResponse{
+		Object: "list",
+		Data:   h.embeddings.Search(req.Query, req.Model, req.TopK),
+	})
+}
+
+// handleEmbeddingReset forgets all previously embedded inputs.
+func (h *AdminHandler) handleEmbeddingReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.EmbeddingReset")
+	defer span.End()
+
+	h.embeddings.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetImage serves a generated image referenced by an images API URL. Once the URL has
+// expired on the virtual clock it fails like an expired signed blob URL.
+func (h *AdminHandler) handleGetImage(w http.ResponseWriter, r *http.Request) {
+	data, expiresAt, ok := h.images.Get(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if h.images.Expired(expiresAt) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="utf-8"?><Error><Code>AuthenticationFailed</Code>`+
+			`<Message>Server failed to authenticate the request. Signed expiry time [%s] must be after current time [%s]</Message></Error>`,
+			expiresAt.UTC().Format(http.TimeFormat), h.clock.Now().UTC().Format(http.TimeFormat))
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(data)
+}
+
+// tokenizeRequest is the request body for POST /_mokku/tokenize. Either text or messages is tokenized.
+type tokenizeRequest struct {
+	Model    string                             `json:"model"`
+	Text     string                             `json:"text"`
+	Messages []api.ChatCompletionRequestMessage `json:"messages"`
+}
+
+// tokenizedPiece is a single mock token and its ID.
+type tokenizedPiece struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+}
+
+// tokenizeResponse is the response body for POST /_mokku/tokenize
+type tokenizeResponse struct {
+	Object string           `json:"object"`
+	Model  string           `json:"model,omitempty"`
+	Count  int              `json:"count"`
+	Data   []tokenizedPiece `json:"data"`
+}
+
+// handleTokenize splits text or chat messages into mock tokens with the IDs used by logit_bias.
+// count is computed exactly as the prompt_tokens of usage, so client-side budget estimators can be
+// checked against it. All models share the same mock tokenizer.
+func (h *AdminHandler) handleTokenize(w http.ResponseWriter, r *http.Request) {
+	var req tokenizeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	if req.Text != "" && len(req.Messages) > 0 {
+		writeInvalidRequestError(w, "only one of text and messages may be set")
+		return
+	}
+
+	texts := []string{req.Text}
+	count := countTokens(req.Text)
+	if len(req.Messages) > 0 {
+		audioTokens, err := countAudioTokens(req.Messages)
+		if err != nil {
+			writeInvalidRequestError(w, err.Error())
+			return
+		}
+		texts = texts[:0]
+		for _, m := range req.Messages {
+			texts = append(texts, messageContentText(m.Content.Value))
+		}
+		count = countMessageTokens(req.Messages) + audioTokens
+	}
+
+	data := []tokenizedPiece{}
+	for _, text := range texts {
+		for _, piece := range splitTokens(text) {
+			data = append(data, tokenizedPiece{ID: tokenID(piece), Text: piece})
+		}
+	}
+	writeJSON(w, http.StatusOK, tokenizeResponse{Object: "list", Model: req.Model, Count: count, Data: data})
+}
+
+// handleGetStreams reports streaming response counters and recent cancellations.
+func (h *AdminHandler) handleGetStreams(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.streams.Stats())
+}
+
+// handleStreamsReset clears streaming response counters and cancellations.
+func (h *AdminHandler) handleStreamsReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.StreamsReset")
+	defer span.End()
+
+	h.streams.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetConnections reports the client connections and the requests served on each, so tests can
+// verify that SDK connection pools reuse connections.
+func (h *AdminHandler) handleGetConnections(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.connections.Stats())
+}
+
+// handleConnectionsReset clears the connection counters.
+func (h *AdminHandler) handleConnectionsReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.ConnectionsReset")
+	defer span.End()
+
+	h.connections.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetOverhead reports mokku's own processing time per endpoint, separately from the latency it
+// injects, and the requests exceeding the overhead SLOs.
+func (h *AdminHandler) handleGetOverhead(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.overhead.Stats())
+}
+
+// handleOverheadReset clears the recorded overhead.
+func (h *AdminHandler) handleOverheadReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.OverheadReset")
+	defer span.End()
+
+	h.overhead.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetTags reports the requests, errors, streams, and durations of each tag given by the tag
+// rules, and the requests no rule matched.
+func (h *AdminHandler) handleGetTags(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.tags.Stats())
+}
+
+// handleTagsReset clears the tag roll-ups.
+func (h *AdminHandler) handleTagsReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.TagsReset")
+	defer span.End()
+
+	h.tags.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetAlerts lists the recent regressions in client behavior, newest first.
+func (h *AdminHandler) handleGetAlerts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, alertsResponse{Object: "list", Data: h.alerts.Alerts()})
+}
+
+// handleAlertsReset clears the alerts and the observed client behavior.
+func (h *AdminHandler) handleAlertsReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.AlertsReset")
+	defer span.End()
+
+	h.alerts.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// modelMetadataResponse is the response body for GET /_mokku/models
+type modelMetadataResponse struct {
+	Object string          `json:"object"`
+	Data   []modelMetadata `json:"data"`
+}
+
+// handleListModelMetadata reports the context window, output limit, modalities, and knowledge
+// cutoff of every served model.
+func (h *AdminHandler) handleListModelMetadata(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, modelMetadataResponse{Object: "list", Data: h.models.List()})
+}
+
+// handleGetModelMetadata reports the metadata of a single model.
+func (h *AdminHandler) handleGetModelMetadata(w http.ResponseWriter, r *http.Request) {
+	m, ok := h.models.Get(r.PathValue("model"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, m)
+}
+
+// featureFlagsResponse is the response body for GET /_mokku/flags
+type featureFlagsResponse struct {
+	Object string              `json:"object"`
+	Data   []featureFlagStatus `json:"data"`
+}
+
+// handleGetFlags reports every feature flag with its value, source, and evaluation counts.
+func (h *AdminHandler) handleGetFlags(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, featureFlagsResponse{Object: "list", Data: h.flags.Status()})
+}
+
+// setFlagRequest is the request body for PUT /_mokku/flags/{name}
+type setFlagRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// handleSetFlag toggles a feature flag at runtime. The change is not persisted across restarts.
+func (h *AdminHandler) handleSetFlag(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.SetFlag")
+	defer span.End()
+
+	var req setFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	if req.Enabled == nil {
+		writeInvalidRequestError(w, "enabled is required")
+		return
+	}
+	name := r.PathValue("name")
+	if err := h.flags.Set(name, *req.Enabled, flagSourceAdmin); err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	span.SetAttributes(attribute.String("flag.name", name), attribute.Bool("flag.enabled", *req.Enabled))
+	log.Printf("Feature flag %s set to %t via admin API", name, *req.Enabled)
+
+	for _, status := range h.flags.Status() {
+		if status.Name == name {
+			writeJSON(w, http.StatusOK, status)
+			return
+		}
+	}
+}
+
+// auditResponse is the response body for GET /_mokku/audit
+type auditResponse struct {
+	Object string       `json:"object"`
+	Data   []auditEntry `json:"data"`
+}
+
+// handleGetAudit lists recorded admin mutations, newest first, filtered by the actor, action,
+// since (RFC 3339), and limit query parameters.
+func (h *AdminHandler) handleGetAudit(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := auditFilter{Actor: q.Get("actor"), Action: q.Get("action")}
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeInvalidRequestError(w, "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = t
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeInvalidRequestError(w, "limit must be a non-negative integer")
+			return
+		}
+		filter.Limit = n
+	}
+	writeJSON(w, http.StatusOK, auditResponse{Object: "list", Data: h.audit.Query(filter)})
+}
+
+// capturedRequestsResponse is the response body for GET /_mokku/requests
+type capturedRequestsResponse struct {
+	Object string             `json:"object"`
+	Data   []capturedExchange `json:"data"`
+}
+
+// handleListRequests lists captured API requests, newest first, filtered by the method, path,
+// model, since (RFC 3339), and limit query parameters. Response bodies are left out; fetch a
+// single request for them. With format=jsonl, the requests are written oldest first as a
+// replay-load traffic log; with format=postman, as a Postman collection sent to base_url (default:
+// this server).
+func (h *AdminHandler) handleListRequests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	filter := captureFilter{Method: q.Get("method"), Path: q.Get("path"), Model: q.Get("model"), Tag: q.Get("tag"),
+		RequestID: q.Get("request_id"), SafetyIdentifier: q.Get("safety_identifier"), User: q.Get("user")}
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeInvalidRequestError(w, "since must be an RFC 3339 timestamp")
+			return
+		}
+		filter.Since = t
+	}
+	if limit := q.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 0 {
+			writeInvalidRequestError(w, "limit must be a non-negative integer")
+			return
+		}
+		filter.Limit = n
+	}
+	entries := h.capture.Query(filter)
+
+	switch q.Get("format") {
+	case "", "json":
+	case "jsonl":
+		slices.Reverse(entries)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		for _, e := range entries {
+			// Pin the seed so that replaying the request reproduces its randomized behavior
+			req := e.capturedRequest
+			req.Headers = maps.Clone(req.Headers)
+			if req.Headers == nil {
+				req.Headers = map[string]string{}
+			}
+			req.Headers[seedHeader] = strconv.FormatUint(e.Seed, 10)
+			_ = enc.Encode(req)
+		}
+		return
+	case "postman":
+		slices.Reverse(entries)
+		baseURL := q.Get("base_url")
+		if baseURL == "" {
+			baseURL = requestBaseURL(r)
+		}
+		w.Header().Set("Content-Disposition", `attachment; filename="mokku-requests.postman_collection.json"`)
+		writeJSON(w, http.StatusOK, newPostmanCollection("openai-mokku captured requests", baseURL, entries))
+		return
+	default:
+		writeInvalidRequestError(w, "format must be json, jsonl, or postman")
+		return
+	}
+	for i := range entries {
+		entries[i].Response.Body = nil
+	}
+	writeJSON(w, http.StatusOK, capturedRequestsResponse{Object: "list", Data: entries})
+}
+
+// handleGetRequest returns a captured request with its full body and response.
+func (h *AdminHandler) handleGetRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	e, ok := h.capture.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, e)
+}
+
+// handleGetRequestBody streams the full response body of a captured stream from its spill file.
+func (h *AdminHandler) handleGetRequestBody(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	e, ok := h.capture.Get(id)
+	if !ok || e.Response.BodyFile == "" {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(e.Response.BodyFile)
+	if err != nil {
+		// Evicted since it was looked up
+		http.NotFound(w, r)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	w.Header().Set("Content-Type", "text/event-stream")
+	_, _ = io.Copy(w, f)
+}
+
+// handleGetCapture reports the occupancy of the request capture and the requests it evicted.
+func (h *AdminHandler) handleGetCapture(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.capture.Stats())
+}
+
+// handleRequestsReset forgets all captured requests, e.g. between tests.
+func (h *AdminHandler) handleRequestsReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.RequestsReset")
+	defer span.End()
+
+	h.capture.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// bannedUsersResponse is the response body for GET /_mokku/banned-users
+type bannedUsersResponse struct {
+	Object string       `json:"object"`
+	Data   []bannedUser `json:"data"`
+}
+
+// handleListBannedUsers lists the end-users whose requests are rejected.
+func (h *AdminHandler) handleListBannedUsers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, bannedUsersResponse{Object: "list", Data: h.bans.List()})
+}
+
+// handleBanUser bans an end-user at runtime, e.g. in the middle of an enforcement test. An empty
+// body bans without a reason. The ban is not persisted across restarts and is replaced on config
+// reload.
+func (h *AdminHandler) handleBanUser(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.BanUser")
+	defer span.End()
+
+	var req banRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	user, err := h.bans.Ban(r.PathValue("id"), req.Reason)
+	if err != nil {
+		writeInvalidRequestError(w, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.String("user", user.ID))
+	log.Printf("User %s banned via admin API (reason %q)", user.ID, user.Reason)
+	writeJSON(w, http.StatusOK, user)
+}
+
+// handleUnbanUser lifts the ban of an end-user, as an appeal would.
+func (h *AdminHandler) handleUnbanUser(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.UnbanUser")
+	defer span.End()
+
+	id := r.PathValue("id")
+	span.SetAttributes(attribute.String("user", id))
+	if !h.bans.Unban(id) {
+		http.NotFound(w, r)
+		return
+	}
+	log.Printf("User %s unbanned via admin API", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// regionsResponse is the response body for GET /_mokku/regions
+type regionsResponse struct {
+	Object string         `json:"object"`
+	Data   []regionStatus `json:"data"`
+}
+
+// scenariosResponse is the response body for GET and PUT /_mokku/scenarios: the rule names in match
+// order.
+type scenariosResponse struct {
+	Object string   `json:"object"`
+	Data   []string `json:"data"`
+}
+
+// handleListScenarios lists the names of the scenario rules in match order.
+func (h *AdminHandler) handleListScenarios(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, scenariosResponse{Object: "list", Data: h.scenarios.Names()})
+}
+
+// handleReplaceScenarios replaces every scenario rule with those of the scenario file (YAML or JSON)
+// in the body, so a test suite can install its rules in one call. The rules are validated before any
+// is applied; an invalid one rejects the whole set and keeps the current rules. The change is not
+// persisted across restarts and is replaced on scenario reload.
+func (h *AdminHandler) handleReplaceScenarios(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.ReplaceScenarios")
+	defer span.End()
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeInvalidRequestError(w, "Failed to read request body")
+		return
+	}
+	if err := h.scenarios.Replace(data); err != nil {
+		writeInvalidRequestError(w, fmt.Sprintf("Invalid scenarios, keeping the current ones: %v", err))
+		return
+	}
+	names := h.scenarios.Names()
+	span.SetAttributes(attribute.Int("scenarios.count", len(names)))
+	log.Printf("Scenarios replaced via admin API (%d rules)", len(names))
+	writeJSON(w, http.StatusOK, scenariosResponse{Object: "list", Data: names})
+}
+
+// deletedScenariosResponse is the response body for GET /_mokku/scenarios/deleted
+type deletedScenariosResponse struct {
+	Object string            `json:"object"`
+	Data   []deletedScenario `json:"data"`
+}
+
+// handleListDeletedScenarios lists the deleted scenario rules that can still be restored.
+func (h *AdminHandler) handleListDeletedScenarios(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, deletedScenariosResponse{Object: "list", Data: h.scenarios.Deleted()})
+}
+
+// handleDeleteScenario deletes a scenario rule, keeping it restorable for the restore window.
+func (h *AdminHandler) handleDeleteScenario(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.DeleteScenario")
+	defer span.End()
+
+	name := r.PathValue("name")
+	span.SetAttributes(attribute.String("scenario.name", name))
+	deleted, ok := h.scenarios.Delete(name)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	log.Printf("Scenario %s deleted via admin API, restorable until %s", name, deleted.ExpiresAt.Format(time.RFC3339))
+	writeJSON(w, http.StatusOK, deleted)
+}
+
+// handleRestoreScenario puts a deleted scenario rule back at its position in match order.
+func (h *AdminHandler) handleRestoreScenario(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.RestoreScenario")
+	defer span.End()
+
+	name := r.PathValue("name")
+	span.SetAttributes(attribute.String("scenario.name", name))
+	switch err := h.scenarios.Restore(name); {
+	case errors.Is(err, errScenarioNotDeleted):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, errScenarioActive):
+		writeOpenAIError(w, http.StatusConflict, OpenAIErrorDetail{
+			Message: fmt.Sprintf("Scenario %q is active; delete or rename it before restoring", name),
+			Type:    "invalid_request_error",
+			Code:    "scenario_active",
+		})
+		return
+	}
+	log.Printf("Scenario %s restored via admin API", name)
+	writeJSON(w, http.StatusOK, scenariosResponse{Object: "list", Data: h.scenarios.Names()})
+}
+
+// evaluateRequest is the request body for POST /_mokku/evaluate: a candidate API request.
+type evaluateRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+	// Seed is the request seed; it defaults to the X-Mokku-Seed header, then 0.
+	Seed *uint64 `json:"seed"`
+}
+
+// handleEvaluate reports which scenario rule a candidate request would match, the response the
+// rule would render, and why the other rules do not match, without sending the request.
+func (h *AdminHandler) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	var req evaluateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	if req.Method == "" {
+		req.Method = http.MethodPost
+	}
+	if !strings.HasPrefix(req.Path, "/v1/") {
+		writeInvalidRequestError(w, fmt.Sprintf("path must be an API path starting with /v1/, got %q", req.Path))
+		return
+	}
+	candidate, err := http.NewRequestWithContext(r.Context(), req.Method, req.Path, nil)
+	if err != nil {
+		writeInvalidRequestError(w, err.Error())
+		return
+	}
+	for name, value := range req.Headers {
+		candidate.Header.Set(name, value)
+	}
+	var seed uint64
+	switch {
+	case req.Seed != nil:
+		seed = *req.Seed
+	case candidate.Header.Get(seedHeader) != "":
+		if seed, err = parseSeed(candidate.Header.Get(seedHeader)); err != nil {
+			writeInvalidRequestError(w, fmt.Sprintf("%s %v", seedHeader, err))
+			return
+		}
+	}
+	candidate = candidate.WithContext(withSeed(candidate.Context(), seed))
+	var doc any
+	if len(req.Body) > 0 {
+		if err := json.Unmarshal(req.Body, &doc); err != nil {
+			writeInvalidRequestError(w, "body must be a JSON request body")
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, h.scenarios.Evaluate(candidate, doc))
+}
+
+// handleVerify reports the requests no scenario matched in strict_scenarios mode since the last
+// reset, so a contract test can assert that none arrived.
+func (h *AdminHandler) handleVerify(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.scenarios.Unexpected().Verification())
+}
+
+// handleVerifyReset forgets the unexpected requests.
+func (h *AdminHandler) handleVerifyReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.VerifyReset")
+	defer span.End()
+
+	h.scenarios.Unexpected().Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetRegions reports the simulated health of every region with its request and failure counts.
+func (h *AdminHandler) handleGetRegions(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, regionsResponse{Object: "list", Data: h.regions.Status()})
+}
+
+// handleSetRegion changes the health of a region at runtime, e.g. to take it down in the middle of a
+// failover test. The change is not persisted across restarts and is replaced on config reload.
+func (h *AdminHandler) handleSetRegion(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.SetRegion")
+	defer span.End()
+
+	var cfg regionConfig
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	cfg.Name = r.PathValue("name")
+	status, err := h.regions.Set(cfg)
+	if err != nil {
+		writeInvalidRequestError(w, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.String("region", status.Name), attribute.Bool("region.healthy", status.Healthy))
+	log.Printf("Region %s set via admin API (status %d, error_rate %g, latency %q)", status.Name, status.Status, status.ErrorRate, status.Latency)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// chaosRequest is the request body for PUT /_mokku/chaos
+type chaosRequest struct {
+	Profile string `json:"profile"`
+	// Define defines the profile named Profile before activating it.
+	Define *chaosProfileConfig `json:"define,omitempty"`
+}
+
+// handleGetChaos reports the active chaos profile, the faults it injected, and every profile.
+func (h *AdminHandler) handleGetChaos(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.chaos.Status())
+}
+
+// handleSetChaos activates a chaos profile at runtime, replacing the active one, after defining it
+// when the request carries its faults. The change is not persisted across restarts and is replaced
+// on config reload.
+func (h *AdminHandler) handleSetChaos(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.SetChaos")
+	defer span.End()
+
+	var req chaosRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	var status chaosStatus
+	var err error
+	if req.Define != nil {
+		profile := *req.Define
+		profile.Name = req.Profile
+		status, err = h.chaos.Define(profile)
+	} else {
+		status, err = h.chaos.Activate(req.Profile)
+	}
+	if err != nil {
+		writeInvalidRequestError(w, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.String("chaos.profile", status.Active))
+	log.Printf("Chaos profile %s activated via admin API", status.Active)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleStopChaos turns chaos off.
+func (h *AdminHandler) handleStopChaos(w http.ResponseWriter, r *http.Request) {
+	status := h.chaos.Deactivate()
+	log.Println("Chaos turned off via admin API")
+	writeJSON(w, http.StatusOK, status)
+}
+
+// timelineResponse is the response body for GET /_mokku/timeline
+type timelineResponse struct {
+	Object string        `json:"object"`
+	Data   []faultPeriod `json:"data"`
+}
+
+// handleGetTimeline exports when chaos profiles and region outages were active, oldest first. The
+// since and until query parameters (RFC 3339) keep the periods overlapping a test run; at keeps
+// those active at one instant, such as when a client saw an error.
+func (h *AdminHandler) handleGetTimeline(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	times := map[string]time.Time{}
+	for _, param := range []string{"since", "until", "at"} {
+		value := q.Get(param)
+		if value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeInvalidRequestError(w, param+" must be an RFC 3339 timestamp")
+			return
+		}
+		times[param] = t
+	}
+	if at, ok := times["at"]; ok {
+		writeJSON(w, http.StatusOK, timelineResponse{Object: "list", Data: h.timeline.Active(at)})
+		return
+	}
+	writeJSON(w, http.StatusOK, timelineResponse{Object: "list", Data: h.timeline.Periods(times["since"], times["until"])})
+}
+
+// handleTimelineReset drops the ended periods of the timeline, e.g. between test runs.
+func (h *AdminHandler) handleTimelineReset(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.TimelineReset")
+	defer span.End()
+
+	h.timeline.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetClock returns the virtual clock stored objects expire by.
+func (h *AdminHandler) handleGetClock(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.clock.Status())
+}
+
+// handleAdvanceClock moves the virtual clock forward, expiring the stored objects it passes.
+func (h *AdminHandler) handleAdvanceClock(w http.ResponseWriter, r *http.Request) {
+	_, span := tracer.Start(r.Context(), "Admin.AdvanceClock")
+	defer span.End()
+
+	var req clockAdvanceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeInvalidRequestError(w, "Failed to parse request body")
+		return
+	}
+	status, err := h.clock.Advance(req.Seconds)
+	if err != nil {
+		writeInvalidRequestError(w, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Float64("clock.offset_seconds", status.OffsetSeconds))
+	log.Printf("Virtual clock advanced by %gs via admin API (offset %gs)", req.Seconds, status.OffsetSeconds)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleResetClock puts the virtual clock back on the wall clock.
+func (h *AdminHandler) handleResetClock(w http.ResponseWriter, r *http.Request) {
+	status := h.clock.Reset()
+	log.Println("Virtual clock reset via admin API")
+	writeJSON(w, http.StatusOK, status)
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert kinds reported by the alert monitor.
+const (
+	alertPromptTokens = "prompt_tokens"
+	alertRetries      = "retries"
+	alertSDKDowngrade = "sdk_downgrade"
+)
+
+// defaultAlertWindow is the length of the windows compared when alerts.window is unset.
+const defaultAlertWindow = 5 * time.Minute
+
+// defaultAlertMinRequests is the number of requests both windows need before they are compared when
+// alerts.min_requests is unset.
+const defaultAlertMinRequests = 20
+
+// minRetryBaseline is the retried share of requests a window is compared against at least, so a
+// handful of retries after a retry-free window does not alert.
+const minRetryBaseline = 0.01
+
+// maxAlerts is the number of recent alerts reported by GET /_mokku/alerts.
+const maxAlerts = 100
+
+// maxAlertSDKs is the number of SDKs, and of downgraded SDK versions, that are tracked; the least
+// recently added is forgotten beyond it.
+const maxAlertSDKs = 100
+
+// alertWebhookTimeout bounds the delivery of an alert to the webhook.
+const alertWebhookTimeout = 5 * time.Second
+
+// alertConfig is the alerts section of the config file. Each check is off unless configured.
+type alertConfig struct {
+	// Window is the length of the windows whose client behavior is compared, each against the one
+	// before it (5m if unset).
+	Window string `yaml:"window" json:"window,omitempty"`
+	// MinRequests is the number of requests both windows need before they are compared (20 if unset).
+	MinRequests int `yaml:"min_requests" json:"min_requests,omitempty"`
+	// PromptTokensRatio alerts when the average prompt tokens per request grow by this factor.
+	PromptTokensRatio float64 `yaml:"prompt_tokens_ratio" json:"prompt_tokens_ratio,omitempty"`
+	// RetryRatio alerts when the share of retried requests grows by this factor.
+	RetryRatio float64 `yaml:"retry_ratio" json:"retry_ratio,omitempty"`
+	// SDKDowngrade alerts when an SDK sends a lower version than it sent before.
+	SDKDowngrade bool `yaml:"sdk_downgrade" json:"sdk_downgrade,omitempty"`
+	// Webhook receives each alert as a JSON POST; alerts are logged either way.
+	Webhook string `yaml:"webhook" json:"webhook,omitempty"`
+}
+
+// clientAlert is a regression in client behavior, logged, posted to the webhook, and reported by
+// GET /_mokku/alerts.
+type clientAlert struct {
+	Time    time.Time `json:"time"`
+	Kind    string    `json:"kind"`
+	Message string    `json:"message"`
+	// Baseline and Observed are the average prompt tokens or retried share of the previous and
+	// current window.
+	Baseline float64 `json:"baseline,omitempty"`
+	Observed float64 `json:"observed,omitempty"`
+	// SDK, Version, and Previous name a downgraded SDK, its version, and the higher version seen before.
+	SDK      string `json:"sdk,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// alertsResponse is the response body of GET /_mokku/alerts.
+type alertsResponse struct {
+	Object string        `json:"object"`
+	Data   []clientAlert `json:"data"`
+}
+
+// behaviorWindow sums the client behavior observed during one window.
+type behaviorWindow struct {
+	start        time.Time
+	requests     int
+	promptTokens int64
+	retried      int
+}
+
+// alertRules is a compiled alertConfig.
+type alertRules struct {
+	window            time.Duration
+	minRequests       int
+	promptTokensRatio float64
+	retryRatio        float64
+	sdkDowngrade      bool
+	webhook           string
+}
+
+// alertMonitor watches the behavior of API clients and alerts when it regresses: prompts growing,
+// retries spiking, or an SDK version going backwards, the regressions a staging mock is best placed to
+// catch before production. It is safe for concurrent use.
+type alertMonitor struct {
+	mu       sync.Mutex
+	rules    alertRules
+	current  behaviorWindow
+	previous *behaviorWindow
+	// versions is the highest version seen per SDK and downgrades the SDK versions alerted on.
+	versions   *boundedMap[string, string]
+	downgrades *boundedMap[string, bool]
+	alerts     *ringBuffer[clientAlert]
+	now        func() time.Time
+	// notify delivers an alert; it is replaced in tests.
+	notify func(alertRules, clientAlert)
+}
+
+// newAlertMonitor creates a monitor with the configured checks.
+func newAlertMonitor(cfg alertConfig) (*alertMonitor, error) {
+	m := &alertMonitor{
+		versions:   newBoundedMap[string, string](maxAlertSDKs),
+		downgrades: newBoundedMap[string, bool](maxAlertSDKs),
+		alerts:     newRingBuffer[clientAlert](maxAlerts),
+		now:        time.Now,
+		notify:     deliverAlert,
+	}
+	if err := m.Load(cfg); err != nil {
+		return nil, err
+	}
+	m.current.start = m.now()
+	return m, nil
+}
+
+// Load replaces the checks. Observed behavior is kept. On error the current checks are kept.
+func (m *alertMonitor) Load(cfg alertConfig) error {
+	return installNow(m.prepare(cfg))
+}
+
+// prepare validates the checks and returns the function installing them.
+func (m *alertMonitor) prepare(cfg alertConfig) (func(), error) {
+	rules := alertRules{window: defaultAlertWindow, minRequests: cfg.MinRequests, promptTokensRatio: cfg.PromptTokensRatio,
+		retryRatio: cfg.RetryRatio, sdkDowngrade: cfg.SDKDowngrade, webhook: cfg.Webhook}
+	if cfg.Window != "" {
+		d, err := time.ParseDuration(cfg.Window)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("alerts.window must be a positive duration such as 5m, got %q", cfg.Window)
+		}
+		rules.window = d
+	}
+	switch {
+	case cfg.MinRequests < 0:
+		return nil, fmt.Errorf("alerts.min_requests must not be negative, got %d", cfg.MinRequests)
+	case cfg.MinRequests == 0:
+		rules.minRequests = defaultAlertMinRequests
+	}
+	for field, ratio := range map[string]float64{"prompt_tokens_ratio": cfg.PromptTokensRatio, "retry_ratio": cfg.RetryRatio} {
+		if ratio != 0 && ratio <= 1 {
+			return nil, fmt.Errorf("alerts.%s must be greater than 1, got %g", field, ratio)
+		}
+	}
+	if cfg.Webhook != "" {
+		if u, err := url.Parse(cfg.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("alerts.webhook must be an http or https URL, got %q", cfg.Webhook)
+		}
+	}
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.rules = rules
+	}, nil
+}
+
+// Active reports whether any check is configured.
+func (m *alertMonitor) Active() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rules.promptTokensRatio > 0 || m.rules.retryRatio > 0 || m.rules.sdkDowngrade
+}
+
+// Observe records the behavior of an API request. It reads the request body and restores it for the
+// handlers.
+func (m *alertMonitor) Observe(r *http.Request) {
+	var doc any
+	if r.Method == http.MethodPost {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		_ = r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		_ = json.Unmarshal(body, &doc)
+	}
+	// The official SDKs number their attempts and name their language and version in these headers
+	retries, _ := strconv.Atoi(r.Header.Get("X-Stainless-Retry-Count"))
+	m.Record(estimatePromptTokens(doc), retries > 0, r.Header.Get("X-Stainless-Lang"), r.Header.Get("X-Stainless-Package-Version"))
+}
+
+// Record adds a request with its prompt tokens, whether it was a retry, and the SDK (language) and
+// version that sent it, which may be empty.
+func (m *alertMonitor) Record(promptTokens int, retried bool, sdk, version string) {
+	m.mu.Lock()
+	var fired []clientAlert
+	now := m.now()
+	if now.Sub(m.current.start) >= m.rules.window {
+		fired = m.closeWindowLocked(now)
+	}
+	m.current.requests++
+	m.current.promptTokens += int64(promptTokens)
+	if retried {
+		m.current.retried++
+	}
+	if m.rules.sdkDowngrade && sdk != "" && version != "" {
+		if alert, ok := m.checkVersionLocked(now, sdk, version); ok {
+			fired = append(fired, alert)
+		}
+	}
+	for _, alert := range fired {
+		m.alerts.Push(alert)
+	}
+	rules := m.rules
+	m.mu.Unlock()
+
+	for _, alert := range fired {
+		m.notify(rules, alert)
+	}
+}
+
+// closeWindowLocked compares the current window with the previous one and starts a new window. A
+// window followed by a gap of a whole window without requests is not compared. The caller holds m.mu.
+func (m *alertMonitor) closeWindowLocked(now time.Time) []clientAlert {
+	var fired []clientAlert
+	cur, prev := m.current, m.previous
+	if prev != nil && prev.requests >= m.rules.minRequests && cur.requests >= m.rules.minRequests {
+		baseline := float64(prev.promptTokens) / float64(prev.requests)
+		observed := float64(cur.promptTokens) / float64(cur.requests)
+		if m.rules.promptTokensRatio > 0 && baseline > 0 && observed >= baseline*m.rules.promptTokensRatio {
+			fired = append(fired, clientAlert{Time: now, Kind: alertPromptTokens, Baseline: baseline, Observed: observed,
+				Message: fmt.Sprintf("average prompt tokens per request grew %.1fx, from %.0f to %.0f", observed/baseline, baseline, observed)})
+		}
+		baseline = float64(prev.retried) / float64(prev.requests)
+		observed = float64(cur.retried) / float64(cur.requests)
+		if m.rules.retryRatio > 0 && observed >= max(baseline, minRetryBaseline)*m.rules.retryRatio {
+			fired = append(fired, clientAlert{Time: now, Kind: alertRetries, Baseline: baseline, Observed: observed,
+				Message: fmt.Sprintf("retried requests grew from %.1f%% to %.1f%%", baseline*100, observed*100)})
+		}
+	}
+	m.previous = &cur
+	elapsed := now.Sub(cur.start)
+	if elapsed >= 2*m.rules.window {
+		m.previous = nil
+	}
+	m.current = behaviorWindow{start: cur.start.Add(elapsed.Truncate(m.rules.window))}
+	return fired
+}
+
+// checkVersionLocked records the version an SDK sent and returns an alert the first time a version
+// lower than the highest seen arrives. The caller holds m.mu.
+func (m *alertMonitor) checkVersionLocked(now time.Time, sdk, version string) (clientAlert, bool) {
+	highest, ok := m.versions.Get(sdk)
+	if !ok || compareVersions(version, highest) > 0 {
+		m.versions.Put(sdk, version)
+		return clientAlert{}, false
+	}
+	if compareVersions(version, highest) == 0 {
+		return clientAlert{}, false
+	}
+	// Alert once per downgraded version
+	key := sdk + " " + version
+	if _, seen := m.downgrades.Get(key); seen {
+		return clientAlert{}, false
+	}
+	m.downgrades.Put(key, true)
+	return clientAlert{Time: now, Kind: alertSDKDowngrade, SDK: sdk, Version: version, Previous: highest,
+		Message: fmt.Sprintf("%s SDK %s downgraded from %s", sdk, version, highest)}, true
+}
+
+// Alerts returns the recent alerts, newest first.
+func (m *alertMonitor) Alerts() []clientAlert {
+	m.mu.Lock()
+	alerts := m.alerts.Snapshot()
+	m.mu.Unlock()
+	for i, j := 0, len(alerts)-1; i < j; i, j = i+1, j-1 {
+		alerts[i], alerts[j] = alerts[j], alerts[i]
+	}
+	return alerts
+}
+
+// Reset forgets the alerts, the observed windows, and the SDK versions.
+func (m *alertMonitor) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.current = behaviorWindow{start: m.now()}
+	m.previous = nil
+	m.versions.Clear()
+	m.downgrades.Clear()
+	m.alerts = newRingBuffer[clientAlert](maxAlerts)
+}
+
+// deliverAlert logs an alert and posts it to the webhook, if any, in the background.
+func deliverAlert(rules alertRules, alert clientAlert) {
+	log.Printf("Alert: %s: %s", alert.Kind, alert.Message)
+	if rules.webhook == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+	go func() {
+		client := &http.Client{Timeout: alertWebhookTimeout}
+		resp, err := client.Post(rules.webhook, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("Alert webhook failed: %v", err)
+			return
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest {
+			log.Printf("Alert webhook failed: %s", resp.Status)
+		}
+	}()
+}
+
+// compareVersions compares dotted numeric versions such as 1.55.0, ignoring pre-release and build
+// suffixes; a missing component counts as 0.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts returns the numeric components of a version, dropping a leading "v".
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	var parts []int
+	for _, s := range strings.Split(version, ".") {
+		n, _ := strconv.Atoi(s)
+		parts = append(parts, n)
+	}
+	return parts
+}
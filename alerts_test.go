@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestAlertMonitor creates a monitor on a fake clock that collects its alerts instead of
+// delivering them.
+func newTestAlertMonitor(t *testing.T, cfg alertConfig) (*alertMonitor, *time.Time, *[]clientAlert) {
+	t.Helper()
+	m, err := newAlertMonitor(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var mu sync.Mutex
+	var delivered []clientAlert
+	m.now = func() time.Time { return now }
+	m.notify = func(_ alertRules, alert clientAlert) {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered = append(delivered, alert)
+	}
+	m.Reset()
+	return m, &now, &delivered
+}
+
+// --- newAlertMonitor ---
+
+func TestNewAlertMonitor_RejectsInvalidConfig(t *testing.T) {
+	// Given
+	for name, cfg := range map[string]alertConfig{
+		"bad window":           {Window: "soon"},
+		"negative window":      {Window: "-1m"},
+		"negative min":         {MinRequests: -1},
+		"ratio of one":         {PromptTokensRatio: 1},
+		"negative retry ratio": {RetryRatio: -2},
+		"webhook without http": {SDKDowngrade: true, Webhook: "ftp://example.com/hook"},
+	} {
+		// When
+		_, err := newAlertMonitor(cfg)
+
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// --- Record ---
+
+func TestAlertMonitor_Record_AlertsOnPromptTokenJump(t *testing.T) {
+	// Given: a baseline window of 100-token prompts
+	m, now, delivered := newTestAlertMonitor(t, alertConfig{Window: "1m", MinRequests: 2, PromptTokensRatio: 3})
+	m.Record(100, false, "", "")
+	m.Record(100, false, "", "")
+	*now = now.Add(time.Minute)
+
+	// When: the next window's prompts are four times as long, and a third window starts
+	m.Record(400, false, "", "")
+	m.Record(400, false, "", "")
+	*now = now.Add(time.Minute)
+	m.Record(400, false, "", "")
+
+	// Then
+	if len(*delivered) != 1 {
+		t.Fatalf("expected one alert, got %+v", *delivered)
+	}
+	alert := (*delivered)[0]
+	if alert.Kind != alertPromptTokens || alert.Baseline != 100 || alert.Observed != 400 {
+		t.Errorf("unexpected alert %+v", alert)
+	}
+	if got := m.Alerts(); len(got) != 1 || got[0].Kind != alertPromptTokens {
+		t.Errorf("expected the alert to be listed, got %+v", got)
+	}
+}
+
+func TestAlertMonitor_Record_AlertsOnRetrySpike(t *testing.T) {
+	// Given: a window without retries followed by one where half the requests are retries
+	m, now, delivered := newTestAlertMonitor(t, alertConfig{Window: "1m", MinRequests: 4, RetryRatio: 5})
+	for range 4 {
+		m.Record(10, false, "", "")
+	}
+	*now = now.Add(time.Minute)
+	for i := range 4 {
+		m.Record(10, i%2 == 0, "", "")
+	}
+
+	// When
+	*now = now.Add(time.Minute)
+	m.Record(10, false, "", "")
+
+	// Then
+	if len(*delivered) != 1 || (*delivered)[0].Kind != alertRetries || (*delivered)[0].Observed != 0.5 {
+		t.Errorf("expected a retry alert, got %+v", *delivered)
+	}
+}
+
+func TestAlertMonitor_Record_SkipsSmallAndNonAdjacentWindows(t *testing.T) {
+	// Given
+	m, now, delivered := newTestAlertMonitor(t, alertConfig{Window: "1m", MinRequests: 2, PromptTokensRatio: 2})
+
+	// When: a window below min_requests, then a jump after an idle window
+	m.Record(10, false, "", "")
+	*now = now.Add(time.Minute)
+	m.Record(100, false, "", "")
+	m.Record(100, false, "", "")
+	*now = now.Add(time.Minute)
+	m.Record(10, false, "", "")
+	m.Record(10, false, "", "")
+	*now = now.Add(3 * time.Minute)
+	m.Record(100, false, "", "")
+	m.Record(100, false, "", "")
+	*now = now.Add(time.Minute)
+	m.Record(100, false, "", "")
+
+	// Then
+	if len(*delivered) != 0 {
+		t.Errorf("expected no alerts, got %+v", *delivered)
+	}
+}
+
+func TestAlertMonitor_Record_AlertsOnceOnSDKDowngrade(t *testing.T) {
+	// Given
+	m, _, delivered := newTestAlertMonitor(t, alertConfig{SDKDowngrade: true})
+	m.Record(1, false, "python", "1.55.0")
+	m.Record(1, false, "js", "4.0.0")
+
+	// When: an older python SDK appears twice, and a newer one after it
+	m.Record(1, false, "python", "1.40.2")
+	m.Record(1, false, "python", "1.40.2")
+	m.Record(1, false, "python", "1.56.0")
+
+	// Then
+	if len(*delivered) != 1 {
+		t.Fatalf("expected one alert, got %+v", *delivered)
+	}
+	if a := (*delivered)[0]; a.Kind != alertSDKDowngrade || a.SDK != "python" || a.Version != "1.40.2" || a.Previous != "1.55.0" {
+		t.Errorf("unexpected alert %+v", a)
+	}
+}
+
+func TestAlertMonitor_Concurrent_RecordAlertsAndReset(t *testing.T) {
+	// Given: windows short enough to close while requests are recorded
+	m, err := newAlertMonitor(alertConfig{Window: "1ms", MinRequests: 1, PromptTokensRatio: 2, RetryRatio: 2, SDKDowngrade: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.notify = func(alertRules, clientAlert) {}
+	var wg sync.WaitGroup
+
+	// When: more SDKs than are tracked send requests while alerts are listed and reset
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 500 {
+				m.Record(j%7*100, j%3 == 0, fmt.Sprintf("sdk-%d", j%(maxAlertSDKs+20)), fmt.Sprintf("1.%d.0", (i+j)%5))
+				if j%50 == 0 {
+					_ = m.Alerts()
+				}
+				if j%200 == 0 {
+					m.Reset()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: the alerts and tracked SDKs stay bounded
+	if n := len(m.Alerts()); n > maxAlerts {
+		t.Errorf("expected at most %d alerts, got %d", maxAlerts, n)
+	}
+	if n := m.versions.Len(); n > maxAlertSDKs {
+		t.Errorf("expected at most %d SDKs, got %d", maxAlertSDKs, n)
+	}
+}
+
+// --- Observe ---
+
+func TestAlertMonitor_Observe_ReadsSDKHeadersAndRestoresBody(t *testing.T) {
+	// Given
+	m, _, delivered := newTestAlertMonitor(t, alertConfig{SDKDowngrade: true})
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}]}`
+	for _, version := range []string{"1.9.0", "1.10.0", "1.9.0"} {
+		r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		r.Header.Set("X-Stainless-Lang", "go")
+		r.Header.Set("X-Stainless-Package-Version", version)
+
+		// When
+		m.Observe(r)
+
+		// Then
+		if got, _ := io.ReadAll(r.Body); string(got) != body {
+			t.Errorf("expected the body to be restored, got %q", got)
+		}
+	}
+	if len(*delivered) != 1 || (*delivered)[0].Previous != "1.10.0" {
+		t.Errorf("expected a downgrade from 1.10.0, got %+v", *delivered)
+	}
+}
+
+// --- deliverAlert ---
+
+func TestDeliverAlert_PostsToWebhook(t *testing.T) {
+	// Given
+	received := make(chan clientAlert, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert clientAlert
+		_ = json.NewDecoder(r.Body).Decode(&alert)
+		received <- alert
+	}))
+	defer srv.Close()
+
+	// When
+	deliverAlert(alertRules{webhook: srv.URL}, clientAlert{Kind: alertRetries, Message: "retries grew"})
+
+	// Then
+	select {
+	case alert := <-received:
+		if alert.Kind != alertRetries || alert.Message != "retries grew" {
+			t.Errorf("unexpected alert %+v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the webhook received no alert")
+	}
+}
+
+// --- compareVersions ---
+
+func TestCompareVersions_NumericComponents(t *testing.T) {
+	// Given
+	for _, tc := range []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.0", 1},
+		{"1.9", "1.9.0", 0},
+		{"v2.0.0", "1.99.9", 1},
+		{"1.2.3-beta.1", "1.2.3", 0},
+		{"0.9.1", "0.10.0", -1},
+	} {
+		// When
+		got := compareVersions(tc.a, tc.b)
+
+		// Then
+		if got != tc.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -28,6 +28,10 @@ func (s *CreateChatCompletionRequest) setDefaults() {
 		val := float64(0)
 		s.FrequencyPenalty.SetTo(val)
 	}
+	{
+		val := bool(true)
+		s.ParallelToolCalls.SetTo(val)
+	}
 }
 
 // setDefaults set default value of fields.
@@ -77,3 +81,23 @@ func (s *CreateEmbeddingRequest) setDefaults() {
 		s.EncodingFormat.SetTo(val)
 	}
 }
+
+// setDefaults set default value of fields.
+func (s *CreateImageRequest) setDefaults() {
+	{
+		val := string("dall-e-2")
+		s.Model.SetTo(val)
+	}
+	{
+		val := int(1)
+		s.N.SetTo(val)
+	}
+	{
+		val := CreateImageRequestSize("1024x1024")
+		s.Size.SetTo(val)
+	}
+	{
+		val := CreateImageRequestResponseFormat("url")
+		s.ResponseFormat.SetTo(val)
+	}
+}
@@ -394,6 +394,153 @@ func (s *ChatCompletionJSONSchemaSpec) UnmarshalJSON(data []byte) error {
 	return s.Decode(d)
 }
 
+// Encode implements json.Marshaler.
+func (s *ChatCompletionMessageAnnotation) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *ChatCompletionMessageAnnotation) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("type")
+		s.Type.Encode(e)
+	}
+	{
+		e.FieldStart("url_citation")
+		s.URLCitation.Encode(e)
+	}
+}
+
+var jsonFieldsNameOfChatCompletionMessageAnnotation = [2]string{
+	0: "type",
+	1: "url_citation",
+}
+
+// Decode decodes ChatCompletionMessageAnnotation from json.
+func (s *ChatCompletionMessageAnnotation) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionMessageAnnotation to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "type":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				if err := s.Type.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"type\"")
+			}
+		case "url_citation":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				if err := s.URLCitation.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"url_citation\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode ChatCompletionMessageAnnotation")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000011,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionMessageAnnotation) {
+					name = jsonFieldsNameOfChatCompletionMessageAnnotation[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *ChatCompletionMessageAnnotation) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionMessageAnnotation) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ChatCompletionMessageAnnotationType as json.
+func (s ChatCompletionMessageAnnotationType) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes ChatCompletionMessageAnnotationType from json.
+func (s *ChatCompletionMessageAnnotationType) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionMessageAnnotationType to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch ChatCompletionMessageAnnotationType(v) {
+	case ChatCompletionMessageAnnotationTypeURLCitation:
+		*s = ChatCompletionMessageAnnotationTypeURLCitation
+	default:
+		*s = ChatCompletionMessageAnnotationType(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s ChatCompletionMessageAnnotationType) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionMessageAnnotationType) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
 // Encode implements json.Marshaler.
 func (s *ChatCompletionMessageToolCall) Encode(e *jx.Encoder) {
 	e.ObjStart()
@@ -672,145 +819,64 @@ func (s *ChatCompletionMessageToolCallType) UnmarshalJSON(data []byte) error {
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionRequestMessage) Encode(e *jx.Encoder) {
+func (s *ChatCompletionNamedToolChoice) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionRequestMessage) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("role")
-		s.Role.Encode(e)
-	}
-	{
-		e.FieldStart("content")
-		e.Str(s.Content)
-	}
+func (s *ChatCompletionNamedToolChoice) encodeFields(e *jx.Encoder) {
 	{
-		if s.Name.Set {
-			e.FieldStart("name")
-			s.Name.Encode(e)
-		}
-	}
-	{
-		if s.ToolCalls != nil {
-			e.FieldStart("tool_calls")
-			e.ArrStart()
-			for _, elem := range s.ToolCalls {
-				elem.Encode(e)
-			}
-			e.ArrEnd()
-		}
-	}
-	{
-		if s.ToolCallID.Set {
-			e.FieldStart("tool_call_id")
-			s.ToolCallID.Encode(e)
-		}
+		e.FieldStart("type")
+		s.Type.Encode(e)
 	}
 	{
-		if s.FunctionCall.Set {
-			e.FieldStart("function_call")
-			s.FunctionCall.Encode(e)
-		}
+		e.FieldStart("function")
+		s.Function.Encode(e)
 	}
 }
 
-var jsonFieldsNameOfChatCompletionRequestMessage = [6]string{
-	0: "role",
-	1: "content",
-	2: "name",
-	3: "tool_calls",
-	4: "tool_call_id",
-	5: "function_call",
+var jsonFieldsNameOfChatCompletionNamedToolChoice = [2]string{
+	0: "type",
+	1: "function",
 }
 
-// Decode decodes ChatCompletionRequestMessage from json.
-func (s *ChatCompletionRequestMessage) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionNamedToolChoice from json.
+func (s *ChatCompletionNamedToolChoice) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionRequestMessage to nil")
+		return errors.New("invalid: unable to decode ChatCompletionNamedToolChoice to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "role":
+		case "type":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				if err := s.Role.Decode(d); err != nil {
+				if err := s.Type.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"role\"")
+				return errors.Wrap(err, "decode field \"type\"")
 			}
-		case "content":
+		case "function":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Str()
-				s.Content = string(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"content\"")
-			}
-		case "name":
-			if err := func() error {
-				s.Name.Reset()
-				if err := s.Name.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"name\"")
-			}
-		case "tool_calls":
-			if err := func() error {
-				s.ToolCalls = make([]ChatCompletionMessageToolCall, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ChatCompletionMessageToolCall
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.ToolCalls = append(s.ToolCalls, elem)
-					return nil
-				}); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"tool_calls\"")
-			}
-		case "tool_call_id":
-			if err := func() error {
-				s.ToolCallID.Reset()
-				if err := s.ToolCallID.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"tool_call_id\"")
-			}
-		case "function_call":
-			if err := func() error {
-				s.FunctionCall.Reset()
-				if err := s.FunctionCall.Decode(d); err != nil {
+				if err := s.Function.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"function_call\"")
+				return errors.Wrap(err, "decode field \"function\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionRequestMessage")
+		return errors.Wrap(err, "decode ChatCompletionNamedToolChoice")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -827,8 +893,8 @@ func (s *ChatCompletionRequestMessage) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessage) {
-					name = jsonFieldsNameOfChatCompletionRequestMessage[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionNamedToolChoice) {
+					name = jsonFieldsNameOfChatCompletionNamedToolChoice[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -849,46 +915,41 @@ func (s *ChatCompletionRequestMessage) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionRequestMessage) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionNamedToolChoice) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionRequestMessage) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionNamedToolChoice) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionRequestMessageFunctionCall) Encode(e *jx.Encoder) {
+func (s *ChatCompletionNamedToolChoiceFunction) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionRequestMessageFunctionCall) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionNamedToolChoiceFunction) encodeFields(e *jx.Encoder) {
 	{
 		e.FieldStart("name")
 		e.Str(s.Name)
 	}
-	{
-		e.FieldStart("arguments")
-		e.Str(s.Arguments)
-	}
 }
 
-var jsonFieldsNameOfChatCompletionRequestMessageFunctionCall = [2]string{
+var jsonFieldsNameOfChatCompletionNamedToolChoiceFunction = [1]string{
 	0: "name",
-	1: "arguments",
 }
 
-// Decode decodes ChatCompletionRequestMessageFunctionCall from json.
-func (s *ChatCompletionRequestMessageFunctionCall) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionNamedToolChoiceFunction from json.
+func (s *ChatCompletionNamedToolChoiceFunction) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionRequestMessageFunctionCall to nil")
+		return errors.New("invalid: unable to decode ChatCompletionNamedToolChoiceFunction to nil")
 	}
 	var requiredBitSet [1]uint8
 
@@ -906,29 +967,17 @@ func (s *ChatCompletionRequestMessageFunctionCall) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"name\"")
 			}
-		case "arguments":
-			requiredBitSet[0] |= 1 << 1
-			if err := func() error {
-				v, err := d.Str()
-				s.Arguments = string(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"arguments\"")
-			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionRequestMessageFunctionCall")
+		return errors.Wrap(err, "decode ChatCompletionNamedToolChoiceFunction")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00000011,
+		0b00000001,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -940,8 +989,8 @@ func (s *ChatCompletionRequestMessageFunctionCall) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessageFunctionCall) {
-					name = jsonFieldsNameOfChatCompletionRequestMessageFunctionCall[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionNamedToolChoiceFunction) {
+					name = jsonFieldsNameOfChatCompletionNamedToolChoiceFunction[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -962,125 +1011,196 @@ func (s *ChatCompletionRequestMessageFunctionCall) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionRequestMessageFunctionCall) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionNamedToolChoiceFunction) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionRequestMessageFunctionCall) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionNamedToolChoiceFunction) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes ChatCompletionRequestMessageRole as json.
-func (s ChatCompletionRequestMessageRole) Encode(e *jx.Encoder) {
+// Encode encodes ChatCompletionNamedToolChoiceType as json.
+func (s ChatCompletionNamedToolChoiceType) Encode(e *jx.Encoder) {
 	e.Str(string(s))
 }
 
-// Decode decodes ChatCompletionRequestMessageRole from json.
-func (s *ChatCompletionRequestMessageRole) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionNamedToolChoiceType from json.
+func (s *ChatCompletionNamedToolChoiceType) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionRequestMessageRole to nil")
+		return errors.New("invalid: unable to decode ChatCompletionNamedToolChoiceType to nil")
 	}
 	v, err := d.StrBytes()
 	if err != nil {
 		return err
 	}
 	// Try to use constant string.
-	switch ChatCompletionRequestMessageRole(v) {
-	case ChatCompletionRequestMessageRoleSystem:
-		*s = ChatCompletionRequestMessageRoleSystem
-	case ChatCompletionRequestMessageRoleUser:
-		*s = ChatCompletionRequestMessageRoleUser
-	case ChatCompletionRequestMessageRoleAssistant:
-		*s = ChatCompletionRequestMessageRoleAssistant
-	case ChatCompletionRequestMessageRoleTool:
-		*s = ChatCompletionRequestMessageRoleTool
-	case ChatCompletionRequestMessageRoleFunction:
-		*s = ChatCompletionRequestMessageRoleFunction
+	switch ChatCompletionNamedToolChoiceType(v) {
+	case ChatCompletionNamedToolChoiceTypeFunction:
+		*s = ChatCompletionNamedToolChoiceTypeFunction
 	default:
-		*s = ChatCompletionRequestMessageRole(v)
+		*s = ChatCompletionNamedToolChoiceType(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s ChatCompletionRequestMessageRole) MarshalJSON() ([]byte, error) {
+func (s ChatCompletionNamedToolChoiceType) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionRequestMessageRole) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionNamedToolChoiceType) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionResponseFormat) Encode(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessage) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionResponseFormat) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessage) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("type")
-		s.Type.Encode(e)
+		e.FieldStart("role")
+		s.Role.Encode(e)
 	}
 	{
-		if s.JSONSchema.Set {
-			e.FieldStart("json_schema")
-			s.JSONSchema.Encode(e)
+		if s.Content.Set {
+			e.FieldStart("content")
+			s.Content.Encode(e)
+		}
+	}
+	{
+		if s.Name.Set {
+			e.FieldStart("name")
+			s.Name.Encode(e)
+		}
+	}
+	{
+		if s.ToolCalls != nil {
+			e.FieldStart("tool_calls")
+			e.ArrStart()
+			for _, elem := range s.ToolCalls {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
+		}
+	}
+	{
+		if s.ToolCallID.Set {
+			e.FieldStart("tool_call_id")
+			s.ToolCallID.Encode(e)
+		}
+	}
+	{
+		if s.FunctionCall.Set {
+			e.FieldStart("function_call")
+			s.FunctionCall.Encode(e)
 		}
 	}
 }
 
-var jsonFieldsNameOfChatCompletionResponseFormat = [2]string{
-	0: "type",
-	1: "json_schema",
+var jsonFieldsNameOfChatCompletionRequestMessage = [6]string{
+	0: "role",
+	1: "content",
+	2: "name",
+	3: "tool_calls",
+	4: "tool_call_id",
+	5: "function_call",
 }
 
-// Decode decodes ChatCompletionResponseFormat from json.
-func (s *ChatCompletionResponseFormat) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessage from json.
+func (s *ChatCompletionRequestMessage) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionResponseFormat to nil")
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessage to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "type":
+		case "role":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				if err := s.Type.Decode(d); err != nil {
+				if err := s.Role.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"type\"")
+				return errors.Wrap(err, "decode field \"role\"")
 			}
-		case "json_schema":
+		case "content":
 			if err := func() error {
-				s.JSONSchema.Reset()
-				if err := s.JSONSchema.Decode(d); err != nil {
+				s.Content.Reset()
+				if err := s.Content.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"json_schema\"")
+				return errors.Wrap(err, "decode field \"content\"")
+			}
+		case "name":
+			if err := func() error {
+				s.Name.Reset()
+				if err := s.Name.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"name\"")
+			}
+		case "tool_calls":
+			if err := func() error {
+				s.ToolCalls = make([]ChatCompletionMessageToolCall, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionMessageToolCall
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.ToolCalls = append(s.ToolCalls, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"tool_calls\"")
+			}
+		case "tool_call_id":
+			if err := func() error {
+				s.ToolCallID.Reset()
+				if err := s.ToolCallID.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"tool_call_id\"")
+			}
+		case "function_call":
+			if err := func() error {
+				s.FunctionCall.Reset()
+				if err := s.FunctionCall.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"function_call\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionResponseFormat")
+		return errors.Wrap(err, "decode ChatCompletionRequestMessage")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -1097,8 +1217,8 @@ func (s *ChatCompletionResponseFormat) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionResponseFormat) {
-					name = jsonFieldsNameOfChatCompletionResponseFormat[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessage) {
+					name = jsonFieldsNameOfChatCompletionRequestMessage[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -1119,186 +1239,178 @@ func (s *ChatCompletionResponseFormat) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionResponseFormat) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionRequestMessage) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionResponseFormat) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessage) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes ChatCompletionResponseFormatType as json.
-func (s ChatCompletionResponseFormatType) Encode(e *jx.Encoder) {
-	e.Str(string(s))
+// Encode encodes ChatCompletionRequestMessageContent as json.
+func (s ChatCompletionRequestMessageContent) Encode(e *jx.Encoder) {
+	switch s.Type {
+	case StringChatCompletionRequestMessageContent:
+		e.Str(s.String)
+	case ChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent:
+		e.ArrStart()
+		for _, elem := range s.ChatCompletionRequestMessageContentPartArray {
+			elem.Encode(e)
+		}
+		e.ArrEnd()
+	}
 }
 
-// Decode decodes ChatCompletionResponseFormatType from json.
-func (s *ChatCompletionResponseFormatType) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessageContent from json.
+func (s *ChatCompletionRequestMessageContent) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionResponseFormatType to nil")
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageContent to nil")
 	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
-	}
-	// Try to use constant string.
-	switch ChatCompletionResponseFormatType(v) {
-	case ChatCompletionResponseFormatTypeText:
-		*s = ChatCompletionResponseFormatTypeText
-	case ChatCompletionResponseFormatTypeJSONObject:
-		*s = ChatCompletionResponseFormatTypeJSONObject
-	case ChatCompletionResponseFormatTypeJSONSchema:
-		*s = ChatCompletionResponseFormatTypeJSONSchema
+	// Sum type type_discriminator.
+	switch t := d.Next(); t {
+	case jx.Array:
+		s.ChatCompletionRequestMessageContentPartArray = make([]ChatCompletionRequestMessageContentPart, 0)
+		if err := d.Arr(func(d *jx.Decoder) error {
+			var elem ChatCompletionRequestMessageContentPart
+			if err := elem.Decode(d); err != nil {
+				return err
+			}
+			s.ChatCompletionRequestMessageContentPartArray = append(s.ChatCompletionRequestMessageContentPartArray, elem)
+			return nil
+		}); err != nil {
+			return err
+		}
+		s.Type = ChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent
+	case jx.String:
+		v, err := d.Str()
+		s.String = string(v)
+		if err != nil {
+			return err
+		}
+		s.Type = StringChatCompletionRequestMessageContent
 	default:
-		*s = ChatCompletionResponseFormatType(v)
+		return errors.Errorf("unexpected json type %q", t)
 	}
-
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s ChatCompletionResponseFormatType) MarshalJSON() ([]byte, error) {
+func (s ChatCompletionRequestMessageContent) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionResponseFormatType) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessageContent) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionResponseMessage) Encode(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageContentPart) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionResponseMessage) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("role")
-		s.Role.Encode(e)
-	}
+func (s *ChatCompletionRequestMessageContentPart) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("content")
-		s.Content.Encode(e)
+		e.FieldStart("type")
+		s.Type.Encode(e)
 	}
 	{
-		if s.Refusal.Set {
-			e.FieldStart("refusal")
-			s.Refusal.Encode(e)
+		if s.Text.Set {
+			e.FieldStart("text")
+			s.Text.Encode(e)
 		}
 	}
 	{
-		if s.ToolCalls != nil {
-			e.FieldStart("tool_calls")
-			e.ArrStart()
-			for _, elem := range s.ToolCalls {
-				elem.Encode(e)
-			}
-			e.ArrEnd()
+		if s.ImageURL.Set {
+			e.FieldStart("image_url")
+			s.ImageURL.Encode(e)
 		}
 	}
 	{
-		if s.FunctionCall.Set {
-			e.FieldStart("function_call")
-			s.FunctionCall.Encode(e)
+		if s.InputAudio.Set {
+			e.FieldStart("input_audio")
+			s.InputAudio.Encode(e)
 		}
 	}
 }
 
-var jsonFieldsNameOfChatCompletionResponseMessage = [5]string{
-	0: "role",
-	1: "content",
-	2: "refusal",
-	3: "tool_calls",
-	4: "function_call",
+var jsonFieldsNameOfChatCompletionRequestMessageContentPart = [4]string{
+	0: "type",
+	1: "text",
+	2: "image_url",
+	3: "input_audio",
 }
 
-// Decode decodes ChatCompletionResponseMessage from json.
-func (s *ChatCompletionResponseMessage) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessageContentPart from json.
+func (s *ChatCompletionRequestMessageContentPart) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionResponseMessage to nil")
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageContentPart to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "role":
+		case "type":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				if err := s.Role.Decode(d); err != nil {
+				if err := s.Type.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"role\"")
+				return errors.Wrap(err, "decode field \"type\"")
 			}
-		case "content":
-			requiredBitSet[0] |= 1 << 1
+		case "text":
 			if err := func() error {
-				if err := s.Content.Decode(d); err != nil {
+				s.Text.Reset()
+				if err := s.Text.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"content\"")
+				return errors.Wrap(err, "decode field \"text\"")
 			}
-		case "refusal":
+		case "image_url":
 			if err := func() error {
-				s.Refusal.Reset()
-				if err := s.Refusal.Decode(d); err != nil {
+				s.ImageURL.Reset()
+				if err := s.ImageURL.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"refusal\"")
+				return errors.Wrap(err, "decode field \"image_url\"")
 			}
-		case "tool_calls":
+		case "input_audio":
 			if err := func() error {
-				s.ToolCalls = make([]ChatCompletionMessageToolCall, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ChatCompletionMessageToolCall
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.ToolCalls = append(s.ToolCalls, elem)
-					return nil
-				}); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"tool_calls\"")
-			}
-		case "function_call":
-			if err := func() error {
-				s.FunctionCall.Reset()
-				if err := s.FunctionCall.Decode(d); err != nil {
+				s.InputAudio.Reset()
+				if err := s.InputAudio.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"function_call\"")
+				return errors.Wrap(err, "decode field \"input_audio\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionResponseMessage")
+		return errors.Wrap(err, "decode ChatCompletionRequestMessageContentPart")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00000011,
+		0b00000001,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -1310,8 +1422,8 @@ func (s *ChatCompletionResponseMessage) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionResponseMessage) {
-					name = jsonFieldsNameOfChatCompletionResponseMessage[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessageContentPart) {
+					name = jsonFieldsNameOfChatCompletionRequestMessageContentPart[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -1332,86 +1444,86 @@ func (s *ChatCompletionResponseMessage) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionResponseMessage) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionRequestMessageContentPart) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionResponseMessage) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessageContentPart) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionResponseMessageFunctionCall) Encode(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageContentPartImageURL) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionResponseMessageFunctionCall) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageContentPartImageURL) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("name")
-		e.Str(s.Name)
+		e.FieldStart("url")
+		e.Str(s.URL)
 	}
 	{
-		e.FieldStart("arguments")
-		e.Str(s.Arguments)
+		if s.Detail.Set {
+			e.FieldStart("detail")
+			s.Detail.Encode(e)
+		}
 	}
 }
 
-var jsonFieldsNameOfChatCompletionResponseMessageFunctionCall = [2]string{
-	0: "name",
-	1: "arguments",
+var jsonFieldsNameOfChatCompletionRequestMessageContentPartImageURL = [2]string{
+	0: "url",
+	1: "detail",
 }
 
-// Decode decodes ChatCompletionResponseMessageFunctionCall from json.
-func (s *ChatCompletionResponseMessageFunctionCall) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessageContentPartImageURL from json.
+func (s *ChatCompletionRequestMessageContentPartImageURL) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionResponseMessageFunctionCall to nil")
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageContentPartImageURL to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "name":
+		case "url":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
 				v, err := d.Str()
-				s.Name = string(v)
+				s.URL = string(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"name\"")
+				return errors.Wrap(err, "decode field \"url\"")
 			}
-		case "arguments":
-			requiredBitSet[0] |= 1 << 1
+		case "detail":
 			if err := func() error {
-				v, err := d.Str()
-				s.Arguments = string(v)
-				if err != nil {
+				s.Detail.Reset()
+				if err := s.Detail.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"arguments\"")
+				return errors.Wrap(err, "decode field \"detail\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionResponseMessageFunctionCall")
+		return errors.Wrap(err, "decode ChatCompletionRequestMessageContentPartImageURL")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00000011,
+		0b00000001,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -1423,8 +1535,8 @@ func (s *ChatCompletionResponseMessageFunctionCall) Decode(d *jx.Decoder) error
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionResponseMessageFunctionCall) {
-					name = jsonFieldsNameOfChatCompletionResponseMessageFunctionCall[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessageContentPartImageURL) {
+					name = jsonFieldsNameOfChatCompletionRequestMessageContentPartImageURL[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -1445,191 +1557,84 @@ func (s *ChatCompletionResponseMessageFunctionCall) Decode(d *jx.Decoder) error
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionResponseMessageFunctionCall) MarshalJSON() ([]byte, error) {
-	e := jx.Encoder{}
-	s.Encode(&e)
-	return e.Bytes(), nil
-}
-
-// UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionResponseMessageFunctionCall) UnmarshalJSON(data []byte) error {
-	d := jx.DecodeBytes(data)
-	return s.Decode(d)
-}
-
-// Encode encodes ChatCompletionResponseMessageRole as json.
-func (s ChatCompletionResponseMessageRole) Encode(e *jx.Encoder) {
-	e.Str(string(s))
-}
-
-// Decode decodes ChatCompletionResponseMessageRole from json.
-func (s *ChatCompletionResponseMessageRole) Decode(d *jx.Decoder) error {
-	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionResponseMessageRole to nil")
-	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
-	}
-	// Try to use constant string.
-	switch ChatCompletionResponseMessageRole(v) {
-	case ChatCompletionResponseMessageRoleAssistant:
-		*s = ChatCompletionResponseMessageRoleAssistant
-	default:
-		*s = ChatCompletionResponseMessageRole(v)
-	}
-
-	return nil
-}
-
-// MarshalJSON implements stdjson.Marshaler.
-func (s ChatCompletionResponseMessageRole) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionRequestMessageContentPartImageURL) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionResponseMessageRole) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessageContentPartImageURL) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionTokenLogprob) Encode(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageContentPartInputAudio) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionTokenLogprob) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageContentPartInputAudio) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("token")
-		e.Str(s.Token)
-	}
-	{
-		e.FieldStart("logprob")
-		e.Float64(s.Logprob)
-	}
-	{
-		e.FieldStart("bytes")
-		if s.Bytes == nil {
-			e.Null()
-		} else {
-			e.ArrStart()
-			for _, elem := range s.Bytes {
-				e.Int(elem)
-			}
-			e.ArrEnd()
-		}
+		e.FieldStart("data")
+		e.Str(s.Data)
 	}
 	{
-		e.FieldStart("top_logprobs")
-		e.ArrStart()
-		for _, elem := range s.TopLogprobs {
-			elem.Encode(e)
-		}
-		e.ArrEnd()
+		e.FieldStart("format")
+		s.Format.Encode(e)
 	}
 }
 
-var jsonFieldsNameOfChatCompletionTokenLogprob = [4]string{
-	0: "token",
-	1: "logprob",
-	2: "bytes",
-	3: "top_logprobs",
+var jsonFieldsNameOfChatCompletionRequestMessageContentPartInputAudio = [2]string{
+	0: "data",
+	1: "format",
 }
 
-// Decode decodes ChatCompletionTokenLogprob from json.
-func (s *ChatCompletionTokenLogprob) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessageContentPartInputAudio from json.
+func (s *ChatCompletionRequestMessageContentPartInputAudio) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionTokenLogprob to nil")
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageContentPartInputAudio to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "token":
+		case "data":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
 				v, err := d.Str()
-				s.Token = string(v)
+				s.Data = string(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"token\"")
+				return errors.Wrap(err, "decode field \"data\"")
 			}
-		case "logprob":
+		case "format":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Float64()
-				s.Logprob = float64(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"logprob\"")
-			}
-		case "bytes":
-			requiredBitSet[0] |= 1 << 2
-			if err := func() error {
-				switch tt := d.Next(); tt {
-				case jx.Null:
-					if err := d.Skip(); err != nil {
-						return err
-					}
-				default:
-					s.Bytes = make([]int, 0)
-					if err := d.Arr(func(d *jx.Decoder) error {
-						var elem int
-						v, err := d.Int()
-						elem = int(v)
-						if err != nil {
-							return err
-						}
-						s.Bytes = append(s.Bytes, elem)
-						return nil
-					}); err != nil {
-						return err
-					}
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"bytes\"")
-			}
-		case "top_logprobs":
-			requiredBitSet[0] |= 1 << 3
-			if err := func() error {
-				s.TopLogprobs = make([]ChatCompletionTokenLogprobTopLogprobsItem, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ChatCompletionTokenLogprobTopLogprobsItem
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.TopLogprobs = append(s.TopLogprobs, elem)
-					return nil
-				}); err != nil {
+				if err := s.Format.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"top_logprobs\"")
+				return errors.Wrap(err, "decode field \"format\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionTokenLogprob")
+		return errors.Wrap(err, "decode ChatCompletionRequestMessageContentPartInputAudio")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00001111,
+		0b00000011,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -1641,8 +1646,8 @@ func (s *ChatCompletionTokenLogprob) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionTokenLogprob) {
-					name = jsonFieldsNameOfChatCompletionTokenLogprob[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessageContentPartInputAudio) {
+					name = jsonFieldsNameOfChatCompletionRequestMessageContentPartInputAudio[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -1663,230 +1668,163 @@ func (s *ChatCompletionTokenLogprob) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionTokenLogprob) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionRequestMessageContentPartInputAudio) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionTokenLogprob) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessageContentPartInputAudio) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode implements json.Marshaler.
-func (s *ChatCompletionTokenLogprobTopLogprobsItem) Encode(e *jx.Encoder) {
-	e.ObjStart()
-	s.encodeFields(e)
-	e.ObjEnd()
+// Encode encodes ChatCompletionRequestMessageContentPartInputAudioFormat as json.
+func (s ChatCompletionRequestMessageContentPartInputAudioFormat) Encode(e *jx.Encoder) {
+	e.Str(string(s))
 }
 
-// encodeFields encodes fields.
-func (s *ChatCompletionTokenLogprobTopLogprobsItem) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("token")
-		e.Str(s.Token)
+// Decode decodes ChatCompletionRequestMessageContentPartInputAudioFormat from json.
+func (s *ChatCompletionRequestMessageContentPartInputAudioFormat) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageContentPartInputAudioFormat to nil")
 	}
-	{
-		e.FieldStart("logprob")
-		e.Float64(s.Logprob)
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
 	}
-	{
-		e.FieldStart("bytes")
-		if s.Bytes == nil {
-			e.Null()
-		} else {
-			e.ArrStart()
-			for _, elem := range s.Bytes {
-				e.Int(elem)
-			}
-			e.ArrEnd()
-		}
+	// Try to use constant string.
+	switch ChatCompletionRequestMessageContentPartInputAudioFormat(v) {
+	case ChatCompletionRequestMessageContentPartInputAudioFormatWav:
+		*s = ChatCompletionRequestMessageContentPartInputAudioFormatWav
+	case ChatCompletionRequestMessageContentPartInputAudioFormatMp3:
+		*s = ChatCompletionRequestMessageContentPartInputAudioFormatMp3
+	default:
+		*s = ChatCompletionRequestMessageContentPartInputAudioFormat(v)
 	}
+
+	return nil
 }
 
-var jsonFieldsNameOfChatCompletionTokenLogprobTopLogprobsItem = [3]string{
-	0: "token",
-	1: "logprob",
-	2: "bytes",
+// MarshalJSON implements stdjson.Marshaler.
+func (s ChatCompletionRequestMessageContentPartInputAudioFormat) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
 }
 
-// Decode decodes ChatCompletionTokenLogprobTopLogprobsItem from json.
-func (s *ChatCompletionTokenLogprobTopLogprobsItem) Decode(d *jx.Decoder) error {
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionRequestMessageContentPartInputAudioFormat) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ChatCompletionRequestMessageContentPartType as json.
+func (s ChatCompletionRequestMessageContentPartType) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes ChatCompletionRequestMessageContentPartType from json.
+func (s *ChatCompletionRequestMessageContentPartType) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionTokenLogprobTopLogprobsItem to nil")
-	}
-	var requiredBitSet [1]uint8
-
-	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
-		switch string(k) {
-		case "token":
-			requiredBitSet[0] |= 1 << 0
-			if err := func() error {
-				v, err := d.Str()
-				s.Token = string(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"token\"")
-			}
-		case "logprob":
-			requiredBitSet[0] |= 1 << 1
-			if err := func() error {
-				v, err := d.Float64()
-				s.Logprob = float64(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"logprob\"")
-			}
-		case "bytes":
-			requiredBitSet[0] |= 1 << 2
-			if err := func() error {
-				switch tt := d.Next(); tt {
-				case jx.Null:
-					if err := d.Skip(); err != nil {
-						return err
-					}
-				default:
-					s.Bytes = make([]int, 0)
-					if err := d.Arr(func(d *jx.Decoder) error {
-						var elem int
-						v, err := d.Int()
-						elem = int(v)
-						if err != nil {
-							return err
-						}
-						s.Bytes = append(s.Bytes, elem)
-						return nil
-					}); err != nil {
-						return err
-					}
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"bytes\"")
-			}
-		default:
-			return d.Skip()
-		}
-		return nil
-	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionTokenLogprobTopLogprobsItem")
-	}
-	// Validate required fields.
-	var failures []validate.FieldError
-	for i, mask := range [1]uint8{
-		0b00000111,
-	} {
-		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
-			// Mask only required fields and check equality to mask using XOR.
-			//
-			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
-			// Bits of fields which would be set are actually bits of missed fields.
-			missed := bits.OnesCount8(result)
-			for bitN := 0; bitN < missed; bitN++ {
-				bitIdx := bits.TrailingZeros8(result)
-				fieldIdx := i*8 + bitIdx
-				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionTokenLogprobTopLogprobsItem) {
-					name = jsonFieldsNameOfChatCompletionTokenLogprobTopLogprobsItem[fieldIdx]
-				} else {
-					name = strconv.Itoa(fieldIdx)
-				}
-				failures = append(failures, validate.FieldError{
-					Name:  name,
-					Error: validate.ErrFieldRequired,
-				})
-				// Reset bit.
-				result &^= 1 << bitIdx
-			}
-		}
-	}
-	if len(failures) > 0 {
-		return &validate.Error{Fields: failures}
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageContentPartType to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch ChatCompletionRequestMessageContentPartType(v) {
+	case ChatCompletionRequestMessageContentPartTypeText:
+		*s = ChatCompletionRequestMessageContentPartTypeText
+	case ChatCompletionRequestMessageContentPartTypeImageURL:
+		*s = ChatCompletionRequestMessageContentPartTypeImageURL
+	case ChatCompletionRequestMessageContentPartTypeInputAudio:
+		*s = ChatCompletionRequestMessageContentPartTypeInputAudio
+	default:
+		*s = ChatCompletionRequestMessageContentPartType(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionTokenLogprobTopLogprobsItem) MarshalJSON() ([]byte, error) {
+func (s ChatCompletionRequestMessageContentPartType) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionTokenLogprobTopLogprobsItem) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessageContentPartType) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionTool) Encode(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageFunctionCall) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionTool) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionRequestMessageFunctionCall) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("type")
-		s.Type.Encode(e)
+		e.FieldStart("name")
+		e.Str(s.Name)
 	}
 	{
-		e.FieldStart("function")
-		s.Function.Encode(e)
+		e.FieldStart("arguments")
+		e.Str(s.Arguments)
 	}
 }
 
-var jsonFieldsNameOfChatCompletionTool = [2]string{
-	0: "type",
-	1: "function",
+var jsonFieldsNameOfChatCompletionRequestMessageFunctionCall = [2]string{
+	0: "name",
+	1: "arguments",
 }
 
-// Decode decodes ChatCompletionTool from json.
-func (s *ChatCompletionTool) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessageFunctionCall from json.
+func (s *ChatCompletionRequestMessageFunctionCall) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionTool to nil")
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageFunctionCall to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "type":
+		case "name":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				if err := s.Type.Decode(d); err != nil {
+				v, err := d.Str()
+				s.Name = string(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"type\"")
+				return errors.Wrap(err, "decode field \"name\"")
 			}
-		case "function":
+		case "arguments":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				if err := s.Function.Decode(d); err != nil {
+				v, err := d.Str()
+				s.Arguments = string(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"function\"")
+				return errors.Wrap(err, "decode field \"arguments\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionTool")
+		return errors.Wrap(err, "decode ChatCompletionRequestMessageFunctionCall")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -1903,8 +1841,8 @@ func (s *ChatCompletionTool) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionTool) {
-					name = jsonFieldsNameOfChatCompletionTool[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionRequestMessageFunctionCall) {
+					name = jsonFieldsNameOfChatCompletionRequestMessageFunctionCall[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -1925,99 +1863,125 @@ func (s *ChatCompletionTool) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionTool) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionRequestMessageFunctionCall) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionTool) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionRequestMessageFunctionCall) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ChatCompletionRequestMessageRole as json.
+func (s ChatCompletionRequestMessageRole) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes ChatCompletionRequestMessageRole from json.
+func (s *ChatCompletionRequestMessageRole) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionRequestMessageRole to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch ChatCompletionRequestMessageRole(v) {
+	case ChatCompletionRequestMessageRoleSystem:
+		*s = ChatCompletionRequestMessageRoleSystem
+	case ChatCompletionRequestMessageRoleUser:
+		*s = ChatCompletionRequestMessageRoleUser
+	case ChatCompletionRequestMessageRoleAssistant:
+		*s = ChatCompletionRequestMessageRoleAssistant
+	case ChatCompletionRequestMessageRoleTool:
+		*s = ChatCompletionRequestMessageRoleTool
+	case ChatCompletionRequestMessageRoleFunction:
+		*s = ChatCompletionRequestMessageRoleFunction
+	default:
+		*s = ChatCompletionRequestMessageRole(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s ChatCompletionRequestMessageRole) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionRequestMessageRole) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *ChatCompletionToolFunction) Encode(e *jx.Encoder) {
+func (s *ChatCompletionResponseFormat) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *ChatCompletionToolFunction) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("name")
-		e.Str(s.Name)
-	}
+func (s *ChatCompletionResponseFormat) encodeFields(e *jx.Encoder) {
 	{
-		if s.Description.Set {
-			e.FieldStart("description")
-			s.Description.Encode(e)
-		}
+		e.FieldStart("type")
+		s.Type.Encode(e)
 	}
 	{
-		if len(s.Parameters) != 0 {
-			e.FieldStart("parameters")
-			e.Raw(s.Parameters)
+		if s.JSONSchema.Set {
+			e.FieldStart("json_schema")
+			s.JSONSchema.Encode(e)
 		}
 	}
 }
 
-var jsonFieldsNameOfChatCompletionToolFunction = [3]string{
-	0: "name",
-	1: "description",
-	2: "parameters",
+var jsonFieldsNameOfChatCompletionResponseFormat = [2]string{
+	0: "type",
+	1: "json_schema",
 }
 
-// Decode decodes ChatCompletionToolFunction from json.
-func (s *ChatCompletionToolFunction) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionResponseFormat from json.
+func (s *ChatCompletionResponseFormat) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionToolFunction to nil")
+		return errors.New("invalid: unable to decode ChatCompletionResponseFormat to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "name":
+		case "type":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				v, err := d.Str()
-				s.Name = string(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"name\"")
-			}
-		case "description":
-			if err := func() error {
-				s.Description.Reset()
-				if err := s.Description.Decode(d); err != nil {
+				if err := s.Type.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"description\"")
+				return errors.Wrap(err, "decode field \"type\"")
 			}
-		case "parameters":
+		case "json_schema":
 			if err := func() error {
-				v, err := d.RawAppend(nil)
-				s.Parameters = jx.Raw(v)
-				if err != nil {
+				s.JSONSchema.Reset()
+				if err := s.JSONSchema.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"parameters\"")
+				return errors.Wrap(err, "decode field \"json_schema\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode ChatCompletionToolFunction")
+		return errors.Wrap(err, "decode ChatCompletionResponseFormat")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -2034,8 +1998,8 @@ func (s *ChatCompletionToolFunction) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfChatCompletionToolFunction) {
-					name = jsonFieldsNameOfChatCompletionToolFunction[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionResponseFormat) {
+					name = jsonFieldsNameOfChatCompletionResponseFormat[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -2056,156 +2020,214 @@ func (s *ChatCompletionToolFunction) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *ChatCompletionToolFunction) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionResponseFormat) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionToolFunction) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionResponseFormat) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes ChatCompletionToolType as json.
-func (s ChatCompletionToolType) Encode(e *jx.Encoder) {
+// Encode encodes ChatCompletionResponseFormatType as json.
+func (s ChatCompletionResponseFormatType) Encode(e *jx.Encoder) {
 	e.Str(string(s))
 }
 
-// Decode decodes ChatCompletionToolType from json.
-func (s *ChatCompletionToolType) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionResponseFormatType from json.
+func (s *ChatCompletionResponseFormatType) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode ChatCompletionToolType to nil")
+		return errors.New("invalid: unable to decode ChatCompletionResponseFormatType to nil")
 	}
 	v, err := d.StrBytes()
 	if err != nil {
 		return err
 	}
 	// Try to use constant string.
-	switch ChatCompletionToolType(v) {
-	case ChatCompletionToolTypeFunction:
-		*s = ChatCompletionToolTypeFunction
+	switch ChatCompletionResponseFormatType(v) {
+	case ChatCompletionResponseFormatTypeText:
+		*s = ChatCompletionResponseFormatTypeText
+	case ChatCompletionResponseFormatTypeJSONObject:
+		*s = ChatCompletionResponseFormatTypeJSONObject
+	case ChatCompletionResponseFormatTypeJSONSchema:
+		*s = ChatCompletionResponseFormatTypeJSONSchema
 	default:
-		*s = ChatCompletionToolType(v)
+		*s = ChatCompletionResponseFormatType(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s ChatCompletionToolType) MarshalJSON() ([]byte, error) {
+func (s ChatCompletionResponseFormatType) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *ChatCompletionToolType) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionResponseFormatType) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CompletionChoice) Encode(e *jx.Encoder) {
+func (s *ChatCompletionResponseMessage) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CompletionChoice) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionResponseMessage) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("index")
-		e.Int(s.Index)
+		e.FieldStart("role")
+		s.Role.Encode(e)
 	}
 	{
-		e.FieldStart("text")
-		e.Str(s.Text)
+		e.FieldStart("content")
+		s.Content.Encode(e)
 	}
 	{
-		if s.Logprobs.Set {
-			e.FieldStart("logprobs")
-			s.Logprobs.Encode(e)
+		if s.Refusal.Set {
+			e.FieldStart("refusal")
+			s.Refusal.Encode(e)
 		}
 	}
 	{
-		e.FieldStart("finish_reason")
-		s.FinishReason.Encode(e)
+		if s.ToolCalls != nil {
+			e.FieldStart("tool_calls")
+			e.ArrStart()
+			for _, elem := range s.ToolCalls {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
+		}
+	}
+	{
+		if s.FunctionCall.Set {
+			e.FieldStart("function_call")
+			s.FunctionCall.Encode(e)
+		}
+	}
+	{
+		if s.Annotations != nil {
+			e.FieldStart("annotations")
+			e.ArrStart()
+			for _, elem := range s.Annotations {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
+		}
 	}
 }
 
-var jsonFieldsNameOfCompletionChoice = [4]string{
-	0: "index",
-	1: "text",
-	2: "logprobs",
-	3: "finish_reason",
+var jsonFieldsNameOfChatCompletionResponseMessage = [6]string{
+	0: "role",
+	1: "content",
+	2: "refusal",
+	3: "tool_calls",
+	4: "function_call",
+	5: "annotations",
 }
 
-// Decode decodes CompletionChoice from json.
-func (s *CompletionChoice) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionResponseMessage from json.
+func (s *ChatCompletionResponseMessage) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CompletionChoice to nil")
+		return errors.New("invalid: unable to decode ChatCompletionResponseMessage to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "index":
+		case "role":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				v, err := d.Int()
-				s.Index = int(v)
-				if err != nil {
+				if err := s.Role.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"index\"")
+				return errors.Wrap(err, "decode field \"role\"")
 			}
-		case "text":
+		case "content":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Str()
-				s.Text = string(v)
-				if err != nil {
+				if err := s.Content.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"text\"")
+				return errors.Wrap(err, "decode field \"content\"")
 			}
-		case "logprobs":
+		case "refusal":
 			if err := func() error {
-				s.Logprobs.Reset()
-				if err := s.Logprobs.Decode(d); err != nil {
+				s.Refusal.Reset()
+				if err := s.Refusal.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"logprobs\"")
+				return errors.Wrap(err, "decode field \"refusal\"")
 			}
-		case "finish_reason":
-			requiredBitSet[0] |= 1 << 3
+		case "tool_calls":
 			if err := func() error {
-				if err := s.FinishReason.Decode(d); err != nil {
+				s.ToolCalls = make([]ChatCompletionMessageToolCall, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionMessageToolCall
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.ToolCalls = append(s.ToolCalls, elem)
+					return nil
+				}); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"finish_reason\"")
+				return errors.Wrap(err, "decode field \"tool_calls\"")
+			}
+		case "function_call":
+			if err := func() error {
+				s.FunctionCall.Reset()
+				if err := s.FunctionCall.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"function_call\"")
+			}
+		case "annotations":
+			if err := func() error {
+				s.Annotations = make([]ChatCompletionMessageAnnotation, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionMessageAnnotation
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Annotations = append(s.Annotations, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"annotations\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CompletionChoice")
+		return errors.Wrap(err, "decode ChatCompletionResponseMessage")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00001011,
+		0b00000011,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -2217,8 +2239,8 @@ func (s *CompletionChoice) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCompletionChoice) {
-					name = jsonFieldsNameOfCompletionChoice[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionResponseMessage) {
+					name = jsonFieldsNameOfChatCompletionResponseMessage[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -2239,508 +2261,452 @@ func (s *CompletionChoice) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CompletionChoice) MarshalJSON() ([]byte, error) {
-	e := jx.Encoder{}
-	s.Encode(&e)
-	return e.Bytes(), nil
-}
-
-// UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CompletionChoice) UnmarshalJSON(data []byte) error {
-	d := jx.DecodeBytes(data)
-	return s.Decode(d)
-}
-
-// Encode encodes CompletionChoiceFinishReason as json.
-func (s CompletionChoiceFinishReason) Encode(e *jx.Encoder) {
-	e.Str(string(s))
-}
-
-// Decode decodes CompletionChoiceFinishReason from json.
-func (s *CompletionChoiceFinishReason) Decode(d *jx.Decoder) error {
-	if s == nil {
-		return errors.New("invalid: unable to decode CompletionChoiceFinishReason to nil")
-	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
-	}
-	// Try to use constant string.
-	switch CompletionChoiceFinishReason(v) {
-	case CompletionChoiceFinishReasonStop:
-		*s = CompletionChoiceFinishReasonStop
-	case CompletionChoiceFinishReasonLength:
-		*s = CompletionChoiceFinishReasonLength
-	case CompletionChoiceFinishReasonContentFilter:
-		*s = CompletionChoiceFinishReasonContentFilter
-	default:
-		*s = CompletionChoiceFinishReason(v)
-	}
-
-	return nil
-}
-
-// MarshalJSON implements stdjson.Marshaler.
-func (s CompletionChoiceFinishReason) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionResponseMessage) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CompletionChoiceFinishReason) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionResponseMessage) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CompletionChoiceLogprobs) Encode(e *jx.Encoder) {
+func (s *ChatCompletionResponseMessageFunctionCall) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CompletionChoiceLogprobs) encodeFields(e *jx.Encoder) {
-	{
-		if s.Tokens != nil {
-			e.FieldStart("tokens")
-			e.ArrStart()
-			for _, elem := range s.Tokens {
-				e.Str(elem)
-			}
-			e.ArrEnd()
-		}
-	}
-	{
-		if s.TokenLogprobs != nil {
-			e.FieldStart("token_logprobs")
-			e.ArrStart()
-			for _, elem := range s.TokenLogprobs {
-				e.Float64(elem)
-			}
-			e.ArrEnd()
-		}
-	}
+func (s *ChatCompletionResponseMessageFunctionCall) encodeFields(e *jx.Encoder) {
 	{
-		if s.TopLogprobs != nil {
-			e.FieldStart("top_logprobs")
-			e.ArrStart()
-			for _, elem := range s.TopLogprobs {
-				elem.Encode(e)
-			}
-			e.ArrEnd()
-		}
+		e.FieldStart("name")
+		e.Str(s.Name)
 	}
 	{
-		if s.TextOffset != nil {
-			e.FieldStart("text_offset")
-			e.ArrStart()
-			for _, elem := range s.TextOffset {
-				e.Int(elem)
-			}
-			e.ArrEnd()
-		}
+		e.FieldStart("arguments")
+		e.Str(s.Arguments)
 	}
 }
 
-var jsonFieldsNameOfCompletionChoiceLogprobs = [4]string{
-	0: "tokens",
-	1: "token_logprobs",
-	2: "top_logprobs",
-	3: "text_offset",
+var jsonFieldsNameOfChatCompletionResponseMessageFunctionCall = [2]string{
+	0: "name",
+	1: "arguments",
 }
 
-// Decode decodes CompletionChoiceLogprobs from json.
-func (s *CompletionChoiceLogprobs) Decode(d *jx.Decoder) error {
-	if s == nil {
-		return errors.New("invalid: unable to decode CompletionChoiceLogprobs to nil")
+// Decode decodes ChatCompletionResponseMessageFunctionCall from json.
+func (s *ChatCompletionResponseMessageFunctionCall) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionResponseMessageFunctionCall to nil")
 	}
+	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "tokens":
-			if err := func() error {
-				s.Tokens = make([]string, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem string
-					v, err := d.Str()
-					elem = string(v)
-					if err != nil {
-						return err
-					}
-					s.Tokens = append(s.Tokens, elem)
-					return nil
-				}); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"tokens\"")
-			}
-		case "token_logprobs":
-			if err := func() error {
-				s.TokenLogprobs = make([]float64, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem float64
-					v, err := d.Float64()
-					elem = float64(v)
-					if err != nil {
-						return err
-					}
-					s.TokenLogprobs = append(s.TokenLogprobs, elem)
-					return nil
-				}); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"token_logprobs\"")
-			}
-		case "top_logprobs":
+		case "name":
+			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				s.TopLogprobs = make([]CompletionChoiceLogprobsTopLogprobsItem, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem CompletionChoiceLogprobsTopLogprobsItem
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.TopLogprobs = append(s.TopLogprobs, elem)
-					return nil
-				}); err != nil {
+				v, err := d.Str()
+				s.Name = string(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"top_logprobs\"")
+				return errors.Wrap(err, "decode field \"name\"")
 			}
-		case "text_offset":
+		case "arguments":
+			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				s.TextOffset = make([]int, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem int
-					v, err := d.Int()
-					elem = int(v)
-					if err != nil {
-						return err
-					}
-					s.TextOffset = append(s.TextOffset, elem)
-					return nil
-				}); err != nil {
+				v, err := d.Str()
+				s.Arguments = string(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"text_offset\"")
+				return errors.Wrap(err, "decode field \"arguments\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CompletionChoiceLogprobs")
+		return errors.Wrap(err, "decode ChatCompletionResponseMessageFunctionCall")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000011,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionResponseMessageFunctionCall) {
+					name = jsonFieldsNameOfChatCompletionResponseMessageFunctionCall[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CompletionChoiceLogprobs) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionResponseMessageFunctionCall) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CompletionChoiceLogprobs) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionResponseMessageFunctionCall) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode implements json.Marshaler.
-func (s CompletionChoiceLogprobsTopLogprobsItem) Encode(e *jx.Encoder) {
-	e.ObjStart()
-	s.encodeFields(e)
-	e.ObjEnd()
-}
-
-// encodeFields implements json.Marshaler.
-func (s CompletionChoiceLogprobsTopLogprobsItem) encodeFields(e *jx.Encoder) {
-	for k, elem := range s {
-		e.FieldStart(k)
-
-		e.Float64(elem)
-	}
+// Encode encodes ChatCompletionResponseMessageRole as json.
+func (s ChatCompletionResponseMessageRole) Encode(e *jx.Encoder) {
+	e.Str(string(s))
 }
 
-// Decode decodes CompletionChoiceLogprobsTopLogprobsItem from json.
-func (s *CompletionChoiceLogprobsTopLogprobsItem) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionResponseMessageRole from json.
+func (s *ChatCompletionResponseMessageRole) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CompletionChoiceLogprobsTopLogprobsItem to nil")
+		return errors.New("invalid: unable to decode ChatCompletionResponseMessageRole to nil")
 	}
-	m := s.init()
-	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
-		var elem float64
-		if err := func() error {
-			v, err := d.Float64()
-			elem = float64(v)
-			if err != nil {
-				return err
-			}
-			return nil
-		}(); err != nil {
-			return errors.Wrapf(err, "decode field %q", k)
-		}
-		m[string(k)] = elem
-		return nil
-	}); err != nil {
-		return errors.Wrap(err, "decode CompletionChoiceLogprobsTopLogprobsItem")
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch ChatCompletionResponseMessageRole(v) {
+	case ChatCompletionResponseMessageRoleAssistant:
+		*s = ChatCompletionResponseMessageRoleAssistant
+	default:
+		*s = ChatCompletionResponseMessageRole(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CompletionChoiceLogprobsTopLogprobsItem) MarshalJSON() ([]byte, error) {
+func (s ChatCompletionResponseMessageRole) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CompletionChoiceLogprobsTopLogprobsItem) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionResponseMessageRole) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CompletionTokensDetails) Encode(e *jx.Encoder) {
+func (s *ChatCompletionTokenLogprob) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CompletionTokensDetails) encodeFields(e *jx.Encoder) {
+func (s *ChatCompletionTokenLogprob) encodeFields(e *jx.Encoder) {
 	{
-		if s.ReasoningTokens.Set {
-			e.FieldStart("reasoning_tokens")
-			s.ReasoningTokens.Encode(e)
-		}
+		e.FieldStart("token")
+		e.Str(s.Token)
 	}
 	{
-		if s.AudioTokens.Set {
-			e.FieldStart("audio_tokens")
-			s.AudioTokens.Encode(e)
-		}
+		e.FieldStart("logprob")
+		e.Float64(s.Logprob)
 	}
 	{
-		if s.AcceptedPredictionTokens.Set {
-			e.FieldStart("accepted_prediction_tokens")
-			s.AcceptedPredictionTokens.Encode(e)
+		e.FieldStart("bytes")
+		if s.Bytes == nil {
+			e.Null()
+		} else {
+			e.ArrStart()
+			for _, elem := range s.Bytes {
+				e.Int(elem)
+			}
+			e.ArrEnd()
 		}
 	}
 	{
-		if s.RejectedPredictionTokens.Set {
-			e.FieldStart("rejected_prediction_tokens")
-			s.RejectedPredictionTokens.Encode(e)
+		e.FieldStart("top_logprobs")
+		e.ArrStart()
+		for _, elem := range s.TopLogprobs {
+			elem.Encode(e)
 		}
+		e.ArrEnd()
 	}
 }
 
-var jsonFieldsNameOfCompletionTokensDetails = [4]string{
-	0: "reasoning_tokens",
-	1: "audio_tokens",
-	2: "accepted_prediction_tokens",
-	3: "rejected_prediction_tokens",
+var jsonFieldsNameOfChatCompletionTokenLogprob = [4]string{
+	0: "token",
+	1: "logprob",
+	2: "bytes",
+	3: "top_logprobs",
 }
 
-// Decode decodes CompletionTokensDetails from json.
-func (s *CompletionTokensDetails) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionTokenLogprob from json.
+func (s *ChatCompletionTokenLogprob) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CompletionTokensDetails to nil")
+		return errors.New("invalid: unable to decode ChatCompletionTokenLogprob to nil")
 	}
+	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "reasoning_tokens":
+		case "token":
+			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				s.ReasoningTokens.Reset()
-				if err := s.ReasoningTokens.Decode(d); err != nil {
+				v, err := d.Str()
+				s.Token = string(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"reasoning_tokens\"")
+				return errors.Wrap(err, "decode field \"token\"")
 			}
-		case "audio_tokens":
+		case "logprob":
+			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				s.AudioTokens.Reset()
-				if err := s.AudioTokens.Decode(d); err != nil {
+				v, err := d.Float64()
+				s.Logprob = float64(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"audio_tokens\"")
+				return errors.Wrap(err, "decode field \"logprob\"")
 			}
-		case "accepted_prediction_tokens":
+		case "bytes":
+			requiredBitSet[0] |= 1 << 2
 			if err := func() error {
-				s.AcceptedPredictionTokens.Reset()
-				if err := s.AcceptedPredictionTokens.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"accepted_prediction_tokens\"")
-			}
-		case "rejected_prediction_tokens":
+				switch tt := d.Next(); tt {
+				case jx.Null:
+					if err := d.Skip(); err != nil {
+						return err
+					}
+				default:
+					s.Bytes = make([]int, 0)
+					if err := d.Arr(func(d *jx.Decoder) error {
+						var elem int
+						v, err := d.Int()
+						elem = int(v)
+						if err != nil {
+							return err
+						}
+						s.Bytes = append(s.Bytes, elem)
+						return nil
+					}); err != nil {
+						return err
+					}
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"bytes\"")
+			}
+		case "top_logprobs":
+			requiredBitSet[0] |= 1 << 3
 			if err := func() error {
-				s.RejectedPredictionTokens.Reset()
-				if err := s.RejectedPredictionTokens.Decode(d); err != nil {
+				s.TopLogprobs = make([]ChatCompletionTokenLogprobTopLogprobsItem, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionTokenLogprobTopLogprobsItem
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.TopLogprobs = append(s.TopLogprobs, elem)
+					return nil
+				}); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"rejected_prediction_tokens\"")
+				return errors.Wrap(err, "decode field \"top_logprobs\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CompletionTokensDetails")
+		return errors.Wrap(err, "decode ChatCompletionTokenLogprob")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00001111,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionTokenLogprob) {
+					name = jsonFieldsNameOfChatCompletionTokenLogprob[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CompletionTokensDetails) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionTokenLogprob) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CompletionTokensDetails) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionTokenLogprob) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CompletionUsage) Encode(e *jx.Encoder) {
+func (s *ChatCompletionTokenLogprobTopLogprobsItem) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CompletionUsage) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("prompt_tokens")
-		e.Int(s.PromptTokens)
-	}
-	{
-		e.FieldStart("completion_tokens")
-		e.Int(s.CompletionTokens)
-	}
+func (s *ChatCompletionTokenLogprobTopLogprobsItem) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("total_tokens")
-		e.Int(s.TotalTokens)
+		e.FieldStart("token")
+		e.Str(s.Token)
 	}
 	{
-		if s.PromptTokensDetails.Set {
-			e.FieldStart("prompt_tokens_details")
-			s.PromptTokensDetails.Encode(e)
-		}
+		e.FieldStart("logprob")
+		e.Float64(s.Logprob)
 	}
 	{
-		if s.CompletionTokensDetails.Set {
-			e.FieldStart("completion_tokens_details")
-			s.CompletionTokensDetails.Encode(e)
+		e.FieldStart("bytes")
+		if s.Bytes == nil {
+			e.Null()
+		} else {
+			e.ArrStart()
+			for _, elem := range s.Bytes {
+				e.Int(elem)
+			}
+			e.ArrEnd()
 		}
 	}
 }
 
-var jsonFieldsNameOfCompletionUsage = [5]string{
-	0: "prompt_tokens",
-	1: "completion_tokens",
-	2: "total_tokens",
-	3: "prompt_tokens_details",
-	4: "completion_tokens_details",
+var jsonFieldsNameOfChatCompletionTokenLogprobTopLogprobsItem = [3]string{
+	0: "token",
+	1: "logprob",
+	2: "bytes",
 }
 
-// Decode decodes CompletionUsage from json.
-func (s *CompletionUsage) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionTokenLogprobTopLogprobsItem from json.
+func (s *ChatCompletionTokenLogprobTopLogprobsItem) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CompletionUsage to nil")
+		return errors.New("invalid: unable to decode ChatCompletionTokenLogprobTopLogprobsItem to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "prompt_tokens":
+		case "token":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				v, err := d.Int()
-				s.PromptTokens = int(v)
+				v, err := d.Str()
+				s.Token = string(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"prompt_tokens\"")
+				return errors.Wrap(err, "decode field \"token\"")
 			}
-		case "completion_tokens":
+		case "logprob":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Int()
-				s.CompletionTokens = int(v)
+				v, err := d.Float64()
+				s.Logprob = float64(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"completion_tokens\"")
+				return errors.Wrap(err, "decode field \"logprob\"")
 			}
-		case "total_tokens":
+		case "bytes":
 			requiredBitSet[0] |= 1 << 2
 			if err := func() error {
-				v, err := d.Int()
-				s.TotalTokens = int(v)
-				if err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"total_tokens\"")
-			}
-		case "prompt_tokens_details":
-			if err := func() error {
-				s.PromptTokensDetails.Reset()
-				if err := s.PromptTokensDetails.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"prompt_tokens_details\"")
-			}
-		case "completion_tokens_details":
-			if err := func() error {
-				s.CompletionTokensDetails.Reset()
-				if err := s.CompletionTokensDetails.Decode(d); err != nil {
-					return err
+				switch tt := d.Next(); tt {
+				case jx.Null:
+					if err := d.Skip(); err != nil {
+						return err
+					}
+				default:
+					s.Bytes = make([]int, 0)
+					if err := d.Arr(func(d *jx.Decoder) error {
+						var elem int
+						v, err := d.Int()
+						elem = int(v)
+						if err != nil {
+							return err
+						}
+						s.Bytes = append(s.Bytes, elem)
+						return nil
+					}); err != nil {
+						return err
+					}
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"completion_tokens_details\"")
+				return errors.Wrap(err, "decode field \"bytes\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CompletionUsage")
+		return errors.Wrap(err, "decode ChatCompletionTokenLogprobTopLogprobsItem")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -2757,8 +2723,8 @@ func (s *CompletionUsage) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCompletionUsage) {
-					name = jsonFieldsNameOfCompletionUsage[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionTokenLogprobTopLogprobsItem) {
+					name = jsonFieldsNameOfChatCompletionTokenLogprobTopLogprobsItem[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -2779,68 +2745,1913 @@ func (s *CompletionUsage) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CompletionUsage) MarshalJSON() ([]byte, error) {
+func (s *ChatCompletionTokenLogprobTopLogprobsItem) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CompletionUsage) UnmarshalJSON(data []byte) error {
+func (s *ChatCompletionTokenLogprobTopLogprobsItem) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateChatCompletionRequest) Encode(e *jx.Encoder) {
+func (s *ChatCompletionTool) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateChatCompletionRequest) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("model")
-		e.Str(s.Model)
-	}
-	{
-		e.FieldStart("messages")
-		e.ArrStart()
-		for _, elem := range s.Messages {
-			elem.Encode(e)
-		}
-		e.ArrEnd()
-	}
-	{
-		if s.Temperature.Set {
-			e.FieldStart("temperature")
-			s.Temperature.Encode(e)
-		}
-	}
+func (s *ChatCompletionTool) encodeFields(e *jx.Encoder) {
 	{
-		if s.TopP.Set {
-			e.FieldStart("top_p")
-			s.TopP.Encode(e)
-		}
+		e.FieldStart("type")
+		s.Type.Encode(e)
 	}
 	{
-		if s.N.Set {
-			e.FieldStart("n")
-			s.N.Encode(e)
-		}
+		e.FieldStart("function")
+		s.Function.Encode(e)
 	}
-	{
-		if s.Stream.Set {
-			e.FieldStart("stream")
-			s.Stream.Encode(e)
-		}
+}
+
+var jsonFieldsNameOfChatCompletionTool = [2]string{
+	0: "type",
+	1: "function",
+}
+
+// Decode decodes ChatCompletionTool from json.
+func (s *ChatCompletionTool) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionTool to nil")
 	}
-	{
-		if s.Stop.Set {
-			e.FieldStart("stop")
-			s.Stop.Encode(e)
-		}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "type":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				if err := s.Type.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"type\"")
+			}
+		case "function":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				if err := s.Function.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"function\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode ChatCompletionTool")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000011,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionTool) {
+					name = jsonFieldsNameOfChatCompletionTool[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *ChatCompletionTool) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionTool) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *ChatCompletionToolFunction) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *ChatCompletionToolFunction) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("name")
+		e.Str(s.Name)
+	}
+	{
+		if s.Description.Set {
+			e.FieldStart("description")
+			s.Description.Encode(e)
+		}
+	}
+	{
+		if len(s.Parameters) != 0 {
+			e.FieldStart("parameters")
+			e.Raw(s.Parameters)
+		}
+	}
+}
+
+var jsonFieldsNameOfChatCompletionToolFunction = [3]string{
+	0: "name",
+	1: "description",
+	2: "parameters",
+}
+
+// Decode decodes ChatCompletionToolFunction from json.
+func (s *ChatCompletionToolFunction) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionToolFunction to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "name":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Str()
+				s.Name = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"name\"")
+			}
+		case "description":
+			if err := func() error {
+				s.Description.Reset()
+				if err := s.Description.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"description\"")
+			}
+		case "parameters":
+			if err := func() error {
+				v, err := d.RawAppend(nil)
+				s.Parameters = jx.Raw(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"parameters\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode ChatCompletionToolFunction")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000001,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfChatCompletionToolFunction) {
+					name = jsonFieldsNameOfChatCompletionToolFunction[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *ChatCompletionToolFunction) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionToolFunction) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ChatCompletionToolType as json.
+func (s ChatCompletionToolType) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes ChatCompletionToolType from json.
+func (s *ChatCompletionToolType) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ChatCompletionToolType to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch ChatCompletionToolType(v) {
+	case ChatCompletionToolTypeFunction:
+		*s = ChatCompletionToolTypeFunction
+	default:
+		*s = ChatCompletionToolType(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s ChatCompletionToolType) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ChatCompletionToolType) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CompletionChoice) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CompletionChoice) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("index")
+		e.Int(s.Index)
+	}
+	{
+		e.FieldStart("text")
+		e.Str(s.Text)
+	}
+	{
+		if s.Logprobs.Set {
+			e.FieldStart("logprobs")
+			s.Logprobs.Encode(e)
+		}
+	}
+	{
+		e.FieldStart("finish_reason")
+		s.FinishReason.Encode(e)
+	}
+}
+
+var jsonFieldsNameOfCompletionChoice = [4]string{
+	0: "index",
+	1: "text",
+	2: "logprobs",
+	3: "finish_reason",
+}
+
+// Decode decodes CompletionChoice from json.
+func (s *CompletionChoice) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CompletionChoice to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "index":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Int()
+				s.Index = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"index\"")
+			}
+		case "text":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				v, err := d.Str()
+				s.Text = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"text\"")
+			}
+		case "logprobs":
+			if err := func() error {
+				s.Logprobs.Reset()
+				if err := s.Logprobs.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"logprobs\"")
+			}
+		case "finish_reason":
+			requiredBitSet[0] |= 1 << 3
+			if err := func() error {
+				if err := s.FinishReason.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"finish_reason\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CompletionChoice")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00001011,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfCompletionChoice) {
+					name = jsonFieldsNameOfCompletionChoice[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CompletionChoice) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CompletionChoice) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CompletionChoiceFinishReason as json.
+func (s CompletionChoiceFinishReason) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CompletionChoiceFinishReason from json.
+func (s *CompletionChoiceFinishReason) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CompletionChoiceFinishReason to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CompletionChoiceFinishReason(v) {
+	case CompletionChoiceFinishReasonStop:
+		*s = CompletionChoiceFinishReasonStop
+	case CompletionChoiceFinishReasonLength:
+		*s = CompletionChoiceFinishReasonLength
+	case CompletionChoiceFinishReasonContentFilter:
+		*s = CompletionChoiceFinishReasonContentFilter
+	default:
+		*s = CompletionChoiceFinishReason(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CompletionChoiceFinishReason) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CompletionChoiceFinishReason) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CompletionChoiceLogprobs) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CompletionChoiceLogprobs) encodeFields(e *jx.Encoder) {
+	{
+		if s.Tokens != nil {
+			e.FieldStart("tokens")
+			e.ArrStart()
+			for _, elem := range s.Tokens {
+				e.Str(elem)
+			}
+			e.ArrEnd()
+		}
+	}
+	{
+		if s.TokenLogprobs != nil {
+			e.FieldStart("token_logprobs")
+			e.ArrStart()
+			for _, elem := range s.TokenLogprobs {
+				e.Float64(elem)
+			}
+			e.ArrEnd()
+		}
+	}
+	{
+		if s.TopLogprobs != nil {
+			e.FieldStart("top_logprobs")
+			e.ArrStart()
+			for _, elem := range s.TopLogprobs {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
+		}
+	}
+	{
+		if s.TextOffset != nil {
+			e.FieldStart("text_offset")
+			e.ArrStart()
+			for _, elem := range s.TextOffset {
+				e.Int(elem)
+			}
+			e.ArrEnd()
+		}
+	}
+}
+
+var jsonFieldsNameOfCompletionChoiceLogprobs = [4]string{
+	0: "tokens",
+	1: "token_logprobs",
+	2: "top_logprobs",
+	3: "text_offset",
+}
+
+// Decode decodes CompletionChoiceLogprobs from json.
+func (s *CompletionChoiceLogprobs) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CompletionChoiceLogprobs to nil")
+	}
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "tokens":
+			if err := func() error {
+				s.Tokens = make([]string, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem string
+					v, err := d.Str()
+					elem = string(v)
+					if err != nil {
+						return err
+					}
+					s.Tokens = append(s.Tokens, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"tokens\"")
+			}
+		case "token_logprobs":
+			if err := func() error {
+				s.TokenLogprobs = make([]float64, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem float64
+					v, err := d.Float64()
+					elem = float64(v)
+					if err != nil {
+						return err
+					}
+					s.TokenLogprobs = append(s.TokenLogprobs, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"token_logprobs\"")
+			}
+		case "top_logprobs":
+			if err := func() error {
+				s.TopLogprobs = make([]CompletionChoiceLogprobsTopLogprobsItem, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem CompletionChoiceLogprobsTopLogprobsItem
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.TopLogprobs = append(s.TopLogprobs, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"top_logprobs\"")
+			}
+		case "text_offset":
+			if err := func() error {
+				s.TextOffset = make([]int, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem int
+					v, err := d.Int()
+					elem = int(v)
+					if err != nil {
+						return err
+					}
+					s.TextOffset = append(s.TextOffset, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"text_offset\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CompletionChoiceLogprobs")
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CompletionChoiceLogprobs) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CompletionChoiceLogprobs) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s CompletionChoiceLogprobsTopLogprobsItem) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields implements json.Marshaler.
+func (s CompletionChoiceLogprobsTopLogprobsItem) encodeFields(e *jx.Encoder) {
+	for k, elem := range s {
+		e.FieldStart(k)
+
+		e.Float64(elem)
+	}
+}
+
+// Decode decodes CompletionChoiceLogprobsTopLogprobsItem from json.
+func (s *CompletionChoiceLogprobsTopLogprobsItem) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CompletionChoiceLogprobsTopLogprobsItem to nil")
+	}
+	m := s.init()
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		var elem float64
+		if err := func() error {
+			v, err := d.Float64()
+			elem = float64(v)
+			if err != nil {
+				return err
+			}
+			return nil
+		}(); err != nil {
+			return errors.Wrapf(err, "decode field %q", k)
+		}
+		m[string(k)] = elem
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CompletionChoiceLogprobsTopLogprobsItem")
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CompletionChoiceLogprobsTopLogprobsItem) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CompletionChoiceLogprobsTopLogprobsItem) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CompletionTokensDetails) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CompletionTokensDetails) encodeFields(e *jx.Encoder) {
+	{
+		if s.ReasoningTokens.Set {
+			e.FieldStart("reasoning_tokens")
+			s.ReasoningTokens.Encode(e)
+		}
+	}
+	{
+		if s.AudioTokens.Set {
+			e.FieldStart("audio_tokens")
+			s.AudioTokens.Encode(e)
+		}
+	}
+	{
+		if s.AcceptedPredictionTokens.Set {
+			e.FieldStart("accepted_prediction_tokens")
+			s.AcceptedPredictionTokens.Encode(e)
+		}
+	}
+	{
+		if s.RejectedPredictionTokens.Set {
+			e.FieldStart("rejected_prediction_tokens")
+			s.RejectedPredictionTokens.Encode(e)
+		}
+	}
+}
+
+var jsonFieldsNameOfCompletionTokensDetails = [4]string{
+	0: "reasoning_tokens",
+	1: "audio_tokens",
+	2: "accepted_prediction_tokens",
+	3: "rejected_prediction_tokens",
+}
+
+// Decode decodes CompletionTokensDetails from json.
+func (s *CompletionTokensDetails) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CompletionTokensDetails to nil")
+	}
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "reasoning_tokens":
+			if err := func() error {
+				s.ReasoningTokens.Reset()
+				if err := s.ReasoningTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"reasoning_tokens\"")
+			}
+		case "audio_tokens":
+			if err := func() error {
+				s.AudioTokens.Reset()
+				if err := s.AudioTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"audio_tokens\"")
+			}
+		case "accepted_prediction_tokens":
+			if err := func() error {
+				s.AcceptedPredictionTokens.Reset()
+				if err := s.AcceptedPredictionTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"accepted_prediction_tokens\"")
+			}
+		case "rejected_prediction_tokens":
+			if err := func() error {
+				s.RejectedPredictionTokens.Reset()
+				if err := s.RejectedPredictionTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"rejected_prediction_tokens\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CompletionTokensDetails")
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CompletionTokensDetails) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CompletionTokensDetails) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CompletionUsage) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CompletionUsage) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("prompt_tokens")
+		e.Int(s.PromptTokens)
+	}
+	{
+		e.FieldStart("completion_tokens")
+		e.Int(s.CompletionTokens)
+	}
+	{
+		e.FieldStart("total_tokens")
+		e.Int(s.TotalTokens)
+	}
+	{
+		if s.PromptTokensDetails.Set {
+			e.FieldStart("prompt_tokens_details")
+			s.PromptTokensDetails.Encode(e)
+		}
+	}
+	{
+		if s.CompletionTokensDetails.Set {
+			e.FieldStart("completion_tokens_details")
+			s.CompletionTokensDetails.Encode(e)
+		}
+	}
+}
+
+var jsonFieldsNameOfCompletionUsage = [5]string{
+	0: "prompt_tokens",
+	1: "completion_tokens",
+	2: "total_tokens",
+	3: "prompt_tokens_details",
+	4: "completion_tokens_details",
+}
+
+// Decode decodes CompletionUsage from json.
+func (s *CompletionUsage) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CompletionUsage to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "prompt_tokens":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Int()
+				s.PromptTokens = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"prompt_tokens\"")
+			}
+		case "completion_tokens":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				v, err := d.Int()
+				s.CompletionTokens = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"completion_tokens\"")
+			}
+		case "total_tokens":
+			requiredBitSet[0] |= 1 << 2
+			if err := func() error {
+				v, err := d.Int()
+				s.TotalTokens = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"total_tokens\"")
+			}
+		case "prompt_tokens_details":
+			if err := func() error {
+				s.PromptTokensDetails.Reset()
+				if err := s.PromptTokensDetails.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"prompt_tokens_details\"")
+			}
+		case "completion_tokens_details":
+			if err := func() error {
+				s.CompletionTokensDetails.Reset()
+				if err := s.CompletionTokensDetails.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"completion_tokens_details\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CompletionUsage")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000111,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfCompletionUsage) {
+					name = jsonFieldsNameOfCompletionUsage[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CompletionUsage) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CompletionUsage) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CreateChatCompletionRequest) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CreateChatCompletionRequest) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("model")
+		e.Str(s.Model)
+	}
+	{
+		e.FieldStart("messages")
+		e.ArrStart()
+		for _, elem := range s.Messages {
+			elem.Encode(e)
+		}
+		e.ArrEnd()
+	}
+	{
+		if s.Temperature.Set {
+			e.FieldStart("temperature")
+			s.Temperature.Encode(e)
+		}
+	}
+	{
+		if s.TopP.Set {
+			e.FieldStart("top_p")
+			s.TopP.Encode(e)
+		}
+	}
+	{
+		if s.N.Set {
+			e.FieldStart("n")
+			s.N.Encode(e)
+		}
+	}
+	{
+		if s.Stream.Set {
+			e.FieldStart("stream")
+			s.Stream.Encode(e)
+		}
+	}
+	{
+		if s.Stop.Set {
+			e.FieldStart("stop")
+			s.Stop.Encode(e)
+		}
+	}
+	{
+		if s.MaxTokens.Set {
+			e.FieldStart("max_tokens")
+			s.MaxTokens.Encode(e)
+		}
+	}
+	{
+		if s.MaxCompletionTokens.Set {
+			e.FieldStart("max_completion_tokens")
+			s.MaxCompletionTokens.Encode(e)
+		}
+	}
+	{
+		if s.PresencePenalty.Set {
+			e.FieldStart("presence_penalty")
+			s.PresencePenalty.Encode(e)
+		}
+	}
+	{
+		if s.FrequencyPenalty.Set {
+			e.FieldStart("frequency_penalty")
+			s.FrequencyPenalty.Encode(e)
+		}
+	}
+	{
+		if s.LogitBias.Set {
+			e.FieldStart("logit_bias")
+			s.LogitBias.Encode(e)
+		}
+	}
+	{
+		if s.User.Set {
+			e.FieldStart("user")
+			s.User.Encode(e)
+		}
+	}
+	{
+		if s.SafetyIdentifier.Set {
+			e.FieldStart("safety_identifier")
+			s.SafetyIdentifier.Encode(e)
+		}
+	}
+	{
+		if s.Seed.Set {
+			e.FieldStart("seed")
+			s.Seed.Encode(e)
+		}
+	}
+	{
+		if s.Tools != nil {
+			e.FieldStart("tools")
+			e.ArrStart()
+			for _, elem := range s.Tools {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
+		}
+	}
+	{
+		if s.ToolChoice.Set {
+			e.FieldStart("tool_choice")
+			s.ToolChoice.Encode(e)
+		}
+	}
+	{
+		if s.ParallelToolCalls.Set {
+			e.FieldStart("parallel_tool_calls")
+			s.ParallelToolCalls.Encode(e)
+		}
+	}
+	{
+		if s.ResponseFormat.Set {
+			e.FieldStart("response_format")
+			s.ResponseFormat.Encode(e)
+		}
+	}
+	{
+		if s.WebSearchOptions.Set {
+			e.FieldStart("web_search_options")
+			s.WebSearchOptions.Encode(e)
+		}
+	}
+}
+
+var jsonFieldsNameOfCreateChatCompletionRequest = [20]string{
+	0:  "model",
+	1:  "messages",
+	2:  "temperature",
+	3:  "top_p",
+	4:  "n",
+	5:  "stream",
+	6:  "stop",
+	7:  "max_tokens",
+	8:  "max_completion_tokens",
+	9:  "presence_penalty",
+	10: "frequency_penalty",
+	11: "logit_bias",
+	12: "user",
+	13: "safety_identifier",
+	14: "seed",
+	15: "tools",
+	16: "tool_choice",
+	17: "parallel_tool_calls",
+	18: "response_format",
+	19: "web_search_options",
+}
+
+// Decode decodes CreateChatCompletionRequest from json.
+func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionRequest to nil")
+	}
+	var requiredBitSet [3]uint8
+	s.setDefaults()
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "model":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Str()
+				s.Model = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"model\"")
+			}
+		case "messages":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				s.Messages = make([]ChatCompletionRequestMessage, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionRequestMessage
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Messages = append(s.Messages, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"messages\"")
+			}
+		case "temperature":
+			if err := func() error {
+				s.Temperature.Reset()
+				if err := s.Temperature.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"temperature\"")
+			}
+		case "top_p":
+			if err := func() error {
+				s.TopP.Reset()
+				if err := s.TopP.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"top_p\"")
+			}
+		case "n":
+			if err := func() error {
+				s.N.Reset()
+				if err := s.N.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"n\"")
+			}
+		case "stream":
+			if err := func() error {
+				s.Stream.Reset()
+				if err := s.Stream.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"stream\"")
+			}
+		case "stop":
+			if err := func() error {
+				s.Stop.Reset()
+				if err := s.Stop.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"stop\"")
+			}
+		case "max_tokens":
+			if err := func() error {
+				s.MaxTokens.Reset()
+				if err := s.MaxTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"max_tokens\"")
+			}
+		case "max_completion_tokens":
+			if err := func() error {
+				s.MaxCompletionTokens.Reset()
+				if err := s.MaxCompletionTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"max_completion_tokens\"")
+			}
+		case "presence_penalty":
+			if err := func() error {
+				s.PresencePenalty.Reset()
+				if err := s.PresencePenalty.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"presence_penalty\"")
+			}
+		case "frequency_penalty":
+			if err := func() error {
+				s.FrequencyPenalty.Reset()
+				if err := s.FrequencyPenalty.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"frequency_penalty\"")
+			}
+		case "logit_bias":
+			if err := func() error {
+				s.LogitBias.Reset()
+				if err := s.LogitBias.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"logit_bias\"")
+			}
+		case "user":
+			if err := func() error {
+				s.User.Reset()
+				if err := s.User.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"user\"")
+			}
+		case "safety_identifier":
+			if err := func() error {
+				s.SafetyIdentifier.Reset()
+				if err := s.SafetyIdentifier.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"safety_identifier\"")
+			}
+		case "seed":
+			if err := func() error {
+				s.Seed.Reset()
+				if err := s.Seed.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"seed\"")
+			}
+		case "tools":
+			if err := func() error {
+				s.Tools = make([]ChatCompletionTool, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionTool
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Tools = append(s.Tools, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"tools\"")
+			}
+		case "tool_choice":
+			if err := func() error {
+				s.ToolChoice.Reset()
+				if err := s.ToolChoice.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"tool_choice\"")
+			}
+		case "parallel_tool_calls":
+			if err := func() error {
+				s.ParallelToolCalls.Reset()
+				if err := s.ParallelToolCalls.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"parallel_tool_calls\"")
+			}
+		case "response_format":
+			if err := func() error {
+				s.ResponseFormat.Reset()
+				if err := s.ResponseFormat.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"response_format\"")
+			}
+		case "web_search_options":
+			if err := func() error {
+				s.WebSearchOptions.Reset()
+				if err := s.WebSearchOptions.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"web_search_options\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CreateChatCompletionRequest")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [3]uint8{
+		0b00000011,
+		0b00000000,
+		0b00000000,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfCreateChatCompletionRequest) {
+					name = jsonFieldsNameOfCreateChatCompletionRequest[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CreateChatCompletionRequest) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionRequest) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s CreateChatCompletionRequestLogitBias) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields implements json.Marshaler.
+func (s CreateChatCompletionRequestLogitBias) encodeFields(e *jx.Encoder) {
+	for k, elem := range s {
+		e.FieldStart(k)
+
+		e.Int(elem)
+	}
+}
+
+// Decode decodes CreateChatCompletionRequestLogitBias from json.
+func (s *CreateChatCompletionRequestLogitBias) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionRequestLogitBias to nil")
+	}
+	m := s.init()
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		var elem int
+		if err := func() error {
+			v, err := d.Int()
+			elem = int(v)
+			if err != nil {
+				return err
+			}
+			return nil
+		}(); err != nil {
+			return errors.Wrapf(err, "decode field %q", k)
+		}
+		m[string(k)] = elem
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CreateChatCompletionRequestLogitBias")
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateChatCompletionRequestLogitBias) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionRequestLogitBias) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateChatCompletionRequestStop as json.
+func (s CreateChatCompletionRequestStop) Encode(e *jx.Encoder) {
+	switch s.Type {
+	case StringCreateChatCompletionRequestStop:
+		e.Str(s.String)
+	case StringArrayCreateChatCompletionRequestStop:
+		e.ArrStart()
+		for _, elem := range s.StringArray {
+			e.Str(elem)
+		}
+		e.ArrEnd()
+	}
+}
+
+// Decode decodes CreateChatCompletionRequestStop from json.
+func (s *CreateChatCompletionRequestStop) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionRequestStop to nil")
+	}
+	// Sum type type_discriminator.
+	switch t := d.Next(); t {
+	case jx.Array:
+		s.StringArray = make([]string, 0)
+		if err := d.Arr(func(d *jx.Decoder) error {
+			var elem string
+			v, err := d.Str()
+			elem = string(v)
+			if err != nil {
+				return err
+			}
+			s.StringArray = append(s.StringArray, elem)
+			return nil
+		}); err != nil {
+			return err
+		}
+		s.Type = StringArrayCreateChatCompletionRequestStop
+	case jx.String:
+		v, err := d.Str()
+		s.String = string(v)
+		if err != nil {
+			return err
+		}
+		s.Type = StringCreateChatCompletionRequestStop
+	default:
+		return errors.Errorf("unexpected json type %q", t)
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateChatCompletionRequestStop) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionRequestStop) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateChatCompletionRequestToolChoice as json.
+func (s CreateChatCompletionRequestToolChoice) Encode(e *jx.Encoder) {
+	switch s.Type {
+	case CreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice:
+		s.CreateChatCompletionRequestToolChoice0.Encode(e)
+	case ChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice:
+		s.ChatCompletionNamedToolChoice.Encode(e)
+	}
+}
+
+// Decode decodes CreateChatCompletionRequestToolChoice from json.
+func (s *CreateChatCompletionRequestToolChoice) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionRequestToolChoice to nil")
+	}
+	// Sum type type_discriminator.
+	switch t := d.Next(); t {
+	case jx.Object:
+		if err := s.ChatCompletionNamedToolChoice.Decode(d); err != nil {
+			return err
+		}
+		s.Type = ChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice
+	case jx.String:
+		if err := s.CreateChatCompletionRequestToolChoice0.Decode(d); err != nil {
+			return err
+		}
+		s.Type = CreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice
+	default:
+		return errors.Errorf("unexpected json type %q", t)
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateChatCompletionRequestToolChoice) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionRequestToolChoice) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateChatCompletionRequestToolChoice0 as json.
+func (s CreateChatCompletionRequestToolChoice0) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateChatCompletionRequestToolChoice0 from json.
+func (s *CreateChatCompletionRequestToolChoice0) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionRequestToolChoice0 to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateChatCompletionRequestToolChoice0(v) {
+	case CreateChatCompletionRequestToolChoice0None:
+		*s = CreateChatCompletionRequestToolChoice0None
+	case CreateChatCompletionRequestToolChoice0Auto:
+		*s = CreateChatCompletionRequestToolChoice0Auto
+	case CreateChatCompletionRequestToolChoice0Required:
+		*s = CreateChatCompletionRequestToolChoice0Required
+	default:
+		*s = CreateChatCompletionRequestToolChoice0(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateChatCompletionRequestToolChoice0) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionRequestToolChoice0) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CreateChatCompletionResponse) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CreateChatCompletionResponse) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("id")
+		e.Str(s.ID)
+	}
+	{
+		e.FieldStart("object")
+		s.Object.Encode(e)
+	}
+	{
+		e.FieldStart("created")
+		e.Int64(s.Created)
+	}
+	{
+		e.FieldStart("model")
+		e.Str(s.Model)
+	}
+	{
+		e.FieldStart("choices")
+		e.ArrStart()
+		for _, elem := range s.Choices {
+			elem.Encode(e)
+		}
+		e.ArrEnd()
+	}
+	{
+		if s.Usage.Set {
+			e.FieldStart("usage")
+			s.Usage.Encode(e)
+		}
+	}
+	{
+		if s.SystemFingerprint.Set {
+			e.FieldStart("system_fingerprint")
+			s.SystemFingerprint.Encode(e)
+		}
+	}
+	{
+		if s.ServiceTier.Set {
+			e.FieldStart("service_tier")
+			s.ServiceTier.Encode(e)
+		}
+	}
+}
+
+var jsonFieldsNameOfCreateChatCompletionResponse = [8]string{
+	0: "id",
+	1: "object",
+	2: "created",
+	3: "model",
+	4: "choices",
+	5: "usage",
+	6: "system_fingerprint",
+	7: "service_tier",
+}
+
+// Decode decodes CreateChatCompletionResponse from json.
+func (s *CreateChatCompletionResponse) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionResponse to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "id":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Str()
+				s.ID = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"id\"")
+			}
+		case "object":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				if err := s.Object.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"object\"")
+			}
+		case "created":
+			requiredBitSet[0] |= 1 << 2
+			if err := func() error {
+				v, err := d.Int64()
+				s.Created = int64(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"created\"")
+			}
+		case "model":
+			requiredBitSet[0] |= 1 << 3
+			if err := func() error {
+				v, err := d.Str()
+				s.Model = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"model\"")
+			}
+		case "choices":
+			requiredBitSet[0] |= 1 << 4
+			if err := func() error {
+				s.Choices = make([]ChatCompletionChoice, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ChatCompletionChoice
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Choices = append(s.Choices, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"choices\"")
+			}
+		case "usage":
+			if err := func() error {
+				s.Usage.Reset()
+				if err := s.Usage.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"usage\"")
+			}
+		case "system_fingerprint":
+			if err := func() error {
+				s.SystemFingerprint.Reset()
+				if err := s.SystemFingerprint.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"system_fingerprint\"")
+			}
+		case "service_tier":
+			if err := func() error {
+				s.ServiceTier.Reset()
+				if err := s.ServiceTier.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"service_tier\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CreateChatCompletionResponse")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00011111,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfCreateChatCompletionResponse) {
+					name = jsonFieldsNameOfCreateChatCompletionResponse[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CreateChatCompletionResponse) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionResponse) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateChatCompletionResponseObject as json.
+func (s CreateChatCompletionResponseObject) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateChatCompletionResponseObject from json.
+func (s *CreateChatCompletionResponseObject) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateChatCompletionResponseObject to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateChatCompletionResponseObject(v) {
+	case CreateChatCompletionResponseObjectChatCompletion:
+		*s = CreateChatCompletionResponseObjectChatCompletion
+	default:
+		*s = CreateChatCompletionResponseObject(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateChatCompletionResponseObject) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateChatCompletionResponseObject) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CreateCompletionRequest) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CreateCompletionRequest) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("model")
+		e.Str(s.Model)
+	}
+	{
+		e.FieldStart("prompt")
+		s.Prompt.Encode(e)
+	}
+	{
+		if s.Suffix.Set {
+			e.FieldStart("suffix")
+			s.Suffix.Encode(e)
+		}
 	}
 	{
 		if s.MaxTokens.Set {
@@ -2849,9 +4660,45 @@ func (s *CreateChatCompletionRequest) encodeFields(e *jx.Encoder) {
 		}
 	}
 	{
-		if s.MaxCompletionTokens.Set {
-			e.FieldStart("max_completion_tokens")
-			s.MaxCompletionTokens.Encode(e)
+		if s.Temperature.Set {
+			e.FieldStart("temperature")
+			s.Temperature.Encode(e)
+		}
+	}
+	{
+		if s.TopP.Set {
+			e.FieldStart("top_p")
+			s.TopP.Encode(e)
+		}
+	}
+	{
+		if s.N.Set {
+			e.FieldStart("n")
+			s.N.Encode(e)
+		}
+	}
+	{
+		if s.Stream.Set {
+			e.FieldStart("stream")
+			s.Stream.Encode(e)
+		}
+	}
+	{
+		if s.Logprobs.Set {
+			e.FieldStart("logprobs")
+			s.Logprobs.Encode(e)
+		}
+	}
+	{
+		if s.Echo.Set {
+			e.FieldStart("echo")
+			s.Echo.Encode(e)
+		}
+	}
+	{
+		if s.Stop.Set {
+			e.FieldStart("stop")
+			s.Stop.Encode(e)
 		}
 	}
 	{
@@ -2866,6 +4713,12 @@ func (s *CreateChatCompletionRequest) encodeFields(e *jx.Encoder) {
 			s.FrequencyPenalty.Encode(e)
 		}
 	}
+	{
+		if s.BestOf.Set {
+			e.FieldStart("best_of")
+			s.BestOf.Encode(e)
+		}
+	}
 	{
 		if s.LogitBias.Set {
 			e.FieldStart("logit_bias")
@@ -2884,49 +4737,34 @@ func (s *CreateChatCompletionRequest) encodeFields(e *jx.Encoder) {
 			s.Seed.Encode(e)
 		}
 	}
-	{
-		if s.Tools != nil {
-			e.FieldStart("tools")
-			e.ArrStart()
-			for _, elem := range s.Tools {
-				elem.Encode(e)
-			}
-			e.ArrEnd()
-		}
-	}
-	{
-		if s.ResponseFormat.Set {
-			e.FieldStart("response_format")
-			s.ResponseFormat.Encode(e)
-		}
-	}
 }
 
-var jsonFieldsNameOfCreateChatCompletionRequest = [16]string{
+var jsonFieldsNameOfCreateCompletionRequest = [17]string{
 	0:  "model",
-	1:  "messages",
-	2:  "temperature",
-	3:  "top_p",
-	4:  "n",
-	5:  "stream",
-	6:  "stop",
-	7:  "max_tokens",
-	8:  "max_completion_tokens",
-	9:  "presence_penalty",
-	10: "frequency_penalty",
-	11: "logit_bias",
-	12: "user",
-	13: "seed",
-	14: "tools",
-	15: "response_format",
+	1:  "prompt",
+	2:  "suffix",
+	3:  "max_tokens",
+	4:  "temperature",
+	5:  "top_p",
+	6:  "n",
+	7:  "stream",
+	8:  "logprobs",
+	9:  "echo",
+	10: "stop",
+	11: "presence_penalty",
+	12: "frequency_penalty",
+	13: "best_of",
+	14: "logit_bias",
+	15: "user",
+	16: "seed",
 }
 
-// Decode decodes CreateChatCompletionRequest from json.
-func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
+// Decode decodes CreateCompletionRequest from json.
+func (s *CreateCompletionRequest) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateChatCompletionRequest to nil")
+		return errors.New("invalid: unable to decode CreateCompletionRequest to nil")
 	}
-	var requiredBitSet [2]uint8
+	var requiredBitSet [3]uint8
 	s.setDefaults()
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
@@ -2943,23 +4781,35 @@ func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"model\"")
 			}
-		case "messages":
+		case "prompt":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				s.Messages = make([]ChatCompletionRequestMessage, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ChatCompletionRequestMessage
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.Messages = append(s.Messages, elem)
-					return nil
-				}); err != nil {
+				if err := s.Prompt.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"messages\"")
+				return errors.Wrap(err, "decode field \"prompt\"")
+			}
+		case "suffix":
+			if err := func() error {
+				s.Suffix.Reset()
+				if err := s.Suffix.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"suffix\"")
+			}
+		case "max_tokens":
+			if err := func() error {
+				s.MaxTokens.Reset()
+				if err := s.MaxTokens.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"max_tokens\"")
 			}
 		case "temperature":
 			if err := func() error {
@@ -3001,35 +4851,35 @@ func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"stream\"")
 			}
-		case "stop":
+		case "logprobs":
 			if err := func() error {
-				s.Stop.Reset()
-				if err := s.Stop.Decode(d); err != nil {
+				s.Logprobs.Reset()
+				if err := s.Logprobs.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"stop\"")
+				return errors.Wrap(err, "decode field \"logprobs\"")
 			}
-		case "max_tokens":
+		case "echo":
 			if err := func() error {
-				s.MaxTokens.Reset()
-				if err := s.MaxTokens.Decode(d); err != nil {
+				s.Echo.Reset()
+				if err := s.Echo.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"max_tokens\"")
+				return errors.Wrap(err, "decode field \"echo\"")
 			}
-		case "max_completion_tokens":
+		case "stop":
 			if err := func() error {
-				s.MaxCompletionTokens.Reset()
-				if err := s.MaxCompletionTokens.Decode(d); err != nil {
+				s.Stop.Reset()
+				if err := s.Stop.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"max_completion_tokens\"")
+				return errors.Wrap(err, "decode field \"stop\"")
 			}
 		case "presence_penalty":
 			if err := func() error {
@@ -3051,6 +4901,16 @@ func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"frequency_penalty\"")
 			}
+		case "best_of":
+			if err := func() error {
+				s.BestOf.Reset()
+				if err := s.BestOf.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"best_of\"")
+			}
 		case "logit_bias":
 			if err := func() error {
 				s.LogitBias.Reset()
@@ -3073,53 +4933,27 @@ func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
 			}
 		case "seed":
 			if err := func() error {
-				s.Seed.Reset()
-				if err := s.Seed.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"seed\"")
-			}
-		case "tools":
-			if err := func() error {
-				s.Tools = make([]ChatCompletionTool, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ChatCompletionTool
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.Tools = append(s.Tools, elem)
-					return nil
-				}); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"tools\"")
-			}
-		case "response_format":
-			if err := func() error {
-				s.ResponseFormat.Reset()
-				if err := s.ResponseFormat.Decode(d); err != nil {
+				s.Seed.Reset()
+				if err := s.Seed.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"response_format\"")
+				return errors.Wrap(err, "decode field \"seed\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateChatCompletionRequest")
+		return errors.Wrap(err, "decode CreateCompletionRequest")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
-	for i, mask := range [2]uint8{
+	for i, mask := range [3]uint8{
 		0b00000011,
 		0b00000000,
+		0b00000000,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -3131,8 +4965,8 @@ func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateChatCompletionRequest) {
-					name = jsonFieldsNameOfCreateChatCompletionRequest[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfCreateCompletionRequest) {
+					name = jsonFieldsNameOfCreateCompletionRequest[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -3153,27 +4987,27 @@ func (s *CreateChatCompletionRequest) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateChatCompletionRequest) MarshalJSON() ([]byte, error) {
+func (s *CreateCompletionRequest) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateChatCompletionRequest) UnmarshalJSON(data []byte) error {
+func (s *CreateCompletionRequest) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s CreateChatCompletionRequestLogitBias) Encode(e *jx.Encoder) {
+func (s CreateCompletionRequestLogitBias) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields implements json.Marshaler.
-func (s CreateChatCompletionRequestLogitBias) encodeFields(e *jx.Encoder) {
+func (s CreateCompletionRequestLogitBias) encodeFields(e *jx.Encoder) {
 	for k, elem := range s {
 		e.FieldStart(k)
 
@@ -3181,10 +5015,10 @@ func (s CreateChatCompletionRequestLogitBias) encodeFields(e *jx.Encoder) {
 	}
 }
 
-// Decode decodes CreateChatCompletionRequestLogitBias from json.
-func (s *CreateChatCompletionRequestLogitBias) Decode(d *jx.Decoder) error {
+// Decode decodes CreateCompletionRequestLogitBias from json.
+func (s *CreateCompletionRequestLogitBias) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateChatCompletionRequestLogitBias to nil")
+		return errors.New("invalid: unable to decode CreateCompletionRequestLogitBias to nil")
 	}
 	m := s.init()
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
@@ -3202,31 +5036,95 @@ func (s *CreateChatCompletionRequestLogitBias) Decode(d *jx.Decoder) error {
 		m[string(k)] = elem
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateChatCompletionRequestLogitBias")
+		return errors.Wrap(err, "decode CreateCompletionRequestLogitBias")
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateChatCompletionRequestLogitBias) MarshalJSON() ([]byte, error) {
+func (s CreateCompletionRequestLogitBias) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateChatCompletionRequestLogitBias) UnmarshalJSON(data []byte) error {
+func (s *CreateCompletionRequestLogitBias) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateChatCompletionRequestStop as json.
-func (s CreateChatCompletionRequestStop) Encode(e *jx.Encoder) {
+// Encode encodes CreateCompletionRequestPrompt as json.
+func (s CreateCompletionRequestPrompt) Encode(e *jx.Encoder) {
 	switch s.Type {
-	case StringCreateChatCompletionRequestStop:
+	case StringCreateCompletionRequestPrompt:
 		e.Str(s.String)
-	case StringArrayCreateChatCompletionRequestStop:
+	case AnyArrayCreateCompletionRequestPrompt:
+		e.ArrStart()
+		for _, elem := range s.AnyArray {
+			if len(elem) != 0 {
+				e.Raw(elem)
+			}
+		}
+		e.ArrEnd()
+	}
+}
+
+// Decode decodes CreateCompletionRequestPrompt from json.
+func (s *CreateCompletionRequestPrompt) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateCompletionRequestPrompt to nil")
+	}
+	// Sum type type_discriminator.
+	switch t := d.Next(); t {
+	case jx.Array:
+		s.AnyArray = make([]jx.Raw, 0)
+		if err := d.Arr(func(d *jx.Decoder) error {
+			var elem jx.Raw
+			v, err := d.RawAppend(nil)
+			elem = jx.Raw(v)
+			if err != nil {
+				return err
+			}
+			s.AnyArray = append(s.AnyArray, elem)
+			return nil
+		}); err != nil {
+			return err
+		}
+		s.Type = AnyArrayCreateCompletionRequestPrompt
+	case jx.String:
+		v, err := d.Str()
+		s.String = string(v)
+		if err != nil {
+			return err
+		}
+		s.Type = StringCreateCompletionRequestPrompt
+	default:
+		return errors.Errorf("unexpected json type %q", t)
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateCompletionRequestPrompt) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateCompletionRequestPrompt) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateCompletionRequestStop as json.
+func (s CreateCompletionRequestStop) Encode(e *jx.Encoder) {
+	switch s.Type {
+	case StringCreateCompletionRequestStop:
+		e.Str(s.String)
+	case StringArrayCreateCompletionRequestStop:
 		e.ArrStart()
 		for _, elem := range s.StringArray {
 			e.Str(elem)
@@ -3235,10 +5133,10 @@ func (s CreateChatCompletionRequestStop) Encode(e *jx.Encoder) {
 	}
 }
 
-// Decode decodes CreateChatCompletionRequestStop from json.
-func (s *CreateChatCompletionRequestStop) Decode(d *jx.Decoder) error {
+// Decode decodes CreateCompletionRequestStop from json.
+func (s *CreateCompletionRequestStop) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateChatCompletionRequestStop to nil")
+		return errors.New("invalid: unable to decode CreateCompletionRequestStop to nil")
 	}
 	// Sum type type_discriminator.
 	switch t := d.Next(); t {
@@ -3256,14 +5154,14 @@ func (s *CreateChatCompletionRequestStop) Decode(d *jx.Decoder) error {
 		}); err != nil {
 			return err
 		}
-		s.Type = StringArrayCreateChatCompletionRequestStop
+		s.Type = StringArrayCreateCompletionRequestStop
 	case jx.String:
 		v, err := d.Str()
 		s.String = string(v)
 		if err != nil {
 			return err
 		}
-		s.Type = StringCreateChatCompletionRequestStop
+		s.Type = StringCreateCompletionRequestStop
 	default:
 		return errors.Errorf("unexpected json type %q", t)
 	}
@@ -3271,27 +5169,27 @@ func (s *CreateChatCompletionRequestStop) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateChatCompletionRequestStop) MarshalJSON() ([]byte, error) {
+func (s CreateCompletionRequestStop) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateChatCompletionRequestStop) UnmarshalJSON(data []byte) error {
+func (s *CreateCompletionRequestStop) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateChatCompletionResponse) Encode(e *jx.Encoder) {
+func (s *CreateCompletionResponse) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateChatCompletionResponse) encodeFields(e *jx.Encoder) {
+func (s *CreateCompletionResponse) encodeFields(e *jx.Encoder) {
 	{
 		e.FieldStart("id")
 		e.Str(s.ID)
@@ -3328,15 +5226,9 @@ func (s *CreateChatCompletionResponse) encodeFields(e *jx.Encoder) {
 			s.SystemFingerprint.Encode(e)
 		}
 	}
-	{
-		if s.ServiceTier.Set {
-			e.FieldStart("service_tier")
-			s.ServiceTier.Encode(e)
-		}
-	}
 }
 
-var jsonFieldsNameOfCreateChatCompletionResponse = [8]string{
+var jsonFieldsNameOfCreateCompletionResponse = [7]string{
 	0: "id",
 	1: "object",
 	2: "created",
@@ -3344,13 +5236,12 @@ var jsonFieldsNameOfCreateChatCompletionResponse = [8]string{
 	4: "choices",
 	5: "usage",
 	6: "system_fingerprint",
-	7: "service_tier",
 }
 
-// Decode decodes CreateChatCompletionResponse from json.
-func (s *CreateChatCompletionResponse) Decode(d *jx.Decoder) error {
+// Decode decodes CreateCompletionResponse from json.
+func (s *CreateCompletionResponse) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateChatCompletionResponse to nil")
+		return errors.New("invalid: unable to decode CreateCompletionResponse to nil")
 	}
 	var requiredBitSet [1]uint8
 
@@ -3405,9 +5296,9 @@ func (s *CreateChatCompletionResponse) Decode(d *jx.Decoder) error {
 		case "choices":
 			requiredBitSet[0] |= 1 << 4
 			if err := func() error {
-				s.Choices = make([]ChatCompletionChoice, 0)
+				s.Choices = make([]CompletionChoice, 0)
 				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ChatCompletionChoice
+					var elem CompletionChoice
 					if err := elem.Decode(d); err != nil {
 						return err
 					}
@@ -3440,22 +5331,12 @@ func (s *CreateChatCompletionResponse) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"system_fingerprint\"")
 			}
-		case "service_tier":
-			if err := func() error {
-				s.ServiceTier.Reset()
-				if err := s.ServiceTier.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"service_tier\"")
-			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateChatCompletionResponse")
+		return errors.Wrap(err, "decode CreateCompletionResponse")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -3472,8 +5353,8 @@ func (s *CreateChatCompletionResponse) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateChatCompletionResponse) {
-					name = jsonFieldsNameOfCreateChatCompletionResponse[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfCreateCompletionResponse) {
+					name = jsonFieldsNameOfCreateCompletionResponse[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -3494,149 +5375,83 @@ func (s *CreateChatCompletionResponse) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateChatCompletionResponse) MarshalJSON() ([]byte, error) {
+func (s *CreateCompletionResponse) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateChatCompletionResponse) UnmarshalJSON(data []byte) error {
+func (s *CreateCompletionResponse) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateChatCompletionResponseObject as json.
-func (s CreateChatCompletionResponseObject) Encode(e *jx.Encoder) {
+// Encode encodes CreateCompletionResponseObject as json.
+func (s CreateCompletionResponseObject) Encode(e *jx.Encoder) {
 	e.Str(string(s))
 }
 
-// Decode decodes CreateChatCompletionResponseObject from json.
-func (s *CreateChatCompletionResponseObject) Decode(d *jx.Decoder) error {
+// Decode decodes CreateCompletionResponseObject from json.
+func (s *CreateCompletionResponseObject) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateChatCompletionResponseObject to nil")
+		return errors.New("invalid: unable to decode CreateCompletionResponseObject to nil")
 	}
 	v, err := d.StrBytes()
 	if err != nil {
 		return err
 	}
 	// Try to use constant string.
-	switch CreateChatCompletionResponseObject(v) {
-	case CreateChatCompletionResponseObjectChatCompletion:
-		*s = CreateChatCompletionResponseObjectChatCompletion
+	switch CreateCompletionResponseObject(v) {
+	case CreateCompletionResponseObjectTextCompletion:
+		*s = CreateCompletionResponseObjectTextCompletion
 	default:
-		*s = CreateChatCompletionResponseObject(v)
+		*s = CreateCompletionResponseObject(v)
 	}
 
 	return nil
-}
-
-// MarshalJSON implements stdjson.Marshaler.
-func (s CreateChatCompletionResponseObject) MarshalJSON() ([]byte, error) {
-	e := jx.Encoder{}
-	s.Encode(&e)
-	return e.Bytes(), nil
-}
-
-// UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateChatCompletionResponseObject) UnmarshalJSON(data []byte) error {
-	d := jx.DecodeBytes(data)
-	return s.Decode(d)
-}
-
-// Encode implements json.Marshaler.
-func (s *CreateCompletionRequest) Encode(e *jx.Encoder) {
-	e.ObjStart()
-	s.encodeFields(e)
-	e.ObjEnd()
-}
-
-// encodeFields encodes fields.
-func (s *CreateCompletionRequest) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("model")
-		e.Str(s.Model)
-	}
-	{
-		e.FieldStart("prompt")
-		s.Prompt.Encode(e)
-	}
-	{
-		if s.Suffix.Set {
-			e.FieldStart("suffix")
-			s.Suffix.Encode(e)
-		}
-	}
-	{
-		if s.MaxTokens.Set {
-			e.FieldStart("max_tokens")
-			s.MaxTokens.Encode(e)
-		}
-	}
-	{
-		if s.Temperature.Set {
-			e.FieldStart("temperature")
-			s.Temperature.Encode(e)
-		}
-	}
-	{
-		if s.TopP.Set {
-			e.FieldStart("top_p")
-			s.TopP.Encode(e)
-		}
-	}
-	{
-		if s.N.Set {
-			e.FieldStart("n")
-			s.N.Encode(e)
-		}
-	}
-	{
-		if s.Stream.Set {
-			e.FieldStart("stream")
-			s.Stream.Encode(e)
-		}
-	}
-	{
-		if s.Logprobs.Set {
-			e.FieldStart("logprobs")
-			s.Logprobs.Encode(e)
-		}
-	}
-	{
-		if s.Echo.Set {
-			e.FieldStart("echo")
-			s.Echo.Encode(e)
-		}
-	}
-	{
-		if s.Stop.Set {
-			e.FieldStart("stop")
-			s.Stop.Encode(e)
-		}
-	}
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateCompletionResponseObject) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateCompletionResponseObject) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *CreateEmbeddingRequest) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CreateEmbeddingRequest) encodeFields(e *jx.Encoder) {
 	{
-		if s.PresencePenalty.Set {
-			e.FieldStart("presence_penalty")
-			s.PresencePenalty.Encode(e)
-		}
+		e.FieldStart("model")
+		e.Str(s.Model)
 	}
 	{
-		if s.FrequencyPenalty.Set {
-			e.FieldStart("frequency_penalty")
-			s.FrequencyPenalty.Encode(e)
-		}
+		e.FieldStart("input")
+		s.Input.Encode(e)
 	}
 	{
-		if s.BestOf.Set {
-			e.FieldStart("best_of")
-			s.BestOf.Encode(e)
+		if s.EncodingFormat.Set {
+			e.FieldStart("encoding_format")
+			s.EncodingFormat.Encode(e)
 		}
 	}
 	{
-		if s.LogitBias.Set {
-			e.FieldStart("logit_bias")
-			s.LogitBias.Encode(e)
+		if s.Dimensions.Set {
+			e.FieldStart("dimensions")
+			s.Dimensions.Encode(e)
 		}
 	}
 	{
@@ -3645,40 +5460,22 @@ func (s *CreateCompletionRequest) encodeFields(e *jx.Encoder) {
 			s.User.Encode(e)
 		}
 	}
-	{
-		if s.Seed.Set {
-			e.FieldStart("seed")
-			s.Seed.Encode(e)
-		}
-	}
 }
 
-var jsonFieldsNameOfCreateCompletionRequest = [17]string{
-	0:  "model",
-	1:  "prompt",
-	2:  "suffix",
-	3:  "max_tokens",
-	4:  "temperature",
-	5:  "top_p",
-	6:  "n",
-	7:  "stream",
-	8:  "logprobs",
-	9:  "echo",
-	10: "stop",
-	11: "presence_penalty",
-	12: "frequency_penalty",
-	13: "best_of",
-	14: "logit_bias",
-	15: "user",
-	16: "seed",
+var jsonFieldsNameOfCreateEmbeddingRequest = [5]string{
+	0: "model",
+	1: "input",
+	2: "encoding_format",
+	3: "dimensions",
+	4: "user",
 }
 
-// Decode decodes CreateCompletionRequest from json.
-func (s *CreateCompletionRequest) Decode(d *jx.Decoder) error {
+// Decode decodes CreateEmbeddingRequest from json.
+func (s *CreateEmbeddingRequest) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateCompletionRequest to nil")
+		return errors.New("invalid: unable to decode CreateEmbeddingRequest to nil")
 	}
-	var requiredBitSet [3]uint8
+	var requiredBitSet [1]uint8
 	s.setDefaults()
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
@@ -3695,145 +5492,35 @@ func (s *CreateCompletionRequest) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"model\"")
 			}
-		case "prompt":
+		case "input":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				if err := s.Prompt.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"prompt\"")
-			}
-		case "suffix":
-			if err := func() error {
-				s.Suffix.Reset()
-				if err := s.Suffix.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"suffix\"")
-			}
-		case "max_tokens":
-			if err := func() error {
-				s.MaxTokens.Reset()
-				if err := s.MaxTokens.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"max_tokens\"")
-			}
-		case "temperature":
-			if err := func() error {
-				s.Temperature.Reset()
-				if err := s.Temperature.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"temperature\"")
-			}
-		case "top_p":
-			if err := func() error {
-				s.TopP.Reset()
-				if err := s.TopP.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"top_p\"")
-			}
-		case "n":
-			if err := func() error {
-				s.N.Reset()
-				if err := s.N.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"n\"")
-			}
-		case "stream":
-			if err := func() error {
-				s.Stream.Reset()
-				if err := s.Stream.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"stream\"")
-			}
-		case "logprobs":
-			if err := func() error {
-				s.Logprobs.Reset()
-				if err := s.Logprobs.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"logprobs\"")
-			}
-		case "echo":
-			if err := func() error {
-				s.Echo.Reset()
-				if err := s.Echo.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"echo\"")
-			}
-		case "stop":
-			if err := func() error {
-				s.Stop.Reset()
-				if err := s.Stop.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"stop\"")
-			}
-		case "presence_penalty":
-			if err := func() error {
-				s.PresencePenalty.Reset()
-				if err := s.PresencePenalty.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"presence_penalty\"")
-			}
-		case "frequency_penalty":
-			if err := func() error {
-				s.FrequencyPenalty.Reset()
-				if err := s.FrequencyPenalty.Decode(d); err != nil {
+				if err := s.Input.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"frequency_penalty\"")
+				return errors.Wrap(err, "decode field \"input\"")
 			}
-		case "best_of":
+		case "encoding_format":
 			if err := func() error {
-				s.BestOf.Reset()
-				if err := s.BestOf.Decode(d); err != nil {
+				s.EncodingFormat.Reset()
+				if err := s.EncodingFormat.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"best_of\"")
+				return errors.Wrap(err, "decode field \"encoding_format\"")
 			}
-		case "logit_bias":
+		case "dimensions":
 			if err := func() error {
-				s.LogitBias.Reset()
-				if err := s.LogitBias.Decode(d); err != nil {
+				s.Dimensions.Reset()
+				if err := s.Dimensions.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"logit_bias\"")
+				return errors.Wrap(err, "decode field \"dimensions\"")
 			}
 		case "user":
 			if err := func() error {
@@ -3845,29 +5532,17 @@ func (s *CreateCompletionRequest) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"user\"")
 			}
-		case "seed":
-			if err := func() error {
-				s.Seed.Reset()
-				if err := s.Seed.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"seed\"")
-			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateCompletionRequest")
+		return errors.Wrap(err, "decode CreateEmbeddingRequest")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
-	for i, mask := range [3]uint8{
+	for i, mask := range [1]uint8{
 		0b00000011,
-		0b00000000,
-		0b00000000,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -3879,8 +5554,8 @@ func (s *CreateCompletionRequest) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateCompletionRequest) {
-					name = jsonFieldsNameOfCreateCompletionRequest[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfCreateEmbeddingRequest) {
+					name = jsonFieldsNameOfCreateEmbeddingRequest[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -3901,80 +5576,62 @@ func (s *CreateCompletionRequest) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateCompletionRequest) MarshalJSON() ([]byte, error) {
+func (s *CreateEmbeddingRequest) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateCompletionRequest) UnmarshalJSON(data []byte) error {
+func (s *CreateEmbeddingRequest) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode implements json.Marshaler.
-func (s CreateCompletionRequestLogitBias) Encode(e *jx.Encoder) {
-	e.ObjStart()
-	s.encodeFields(e)
-	e.ObjEnd()
-}
-
-// encodeFields implements json.Marshaler.
-func (s CreateCompletionRequestLogitBias) encodeFields(e *jx.Encoder) {
-	for k, elem := range s {
-		e.FieldStart(k)
-
-		e.Int(elem)
-	}
+// Encode encodes CreateEmbeddingRequestEncodingFormat as json.
+func (s CreateEmbeddingRequestEncodingFormat) Encode(e *jx.Encoder) {
+	e.Str(string(s))
 }
 
-// Decode decodes CreateCompletionRequestLogitBias from json.
-func (s *CreateCompletionRequestLogitBias) Decode(d *jx.Decoder) error {
+// Decode decodes CreateEmbeddingRequestEncodingFormat from json.
+func (s *CreateEmbeddingRequestEncodingFormat) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateCompletionRequestLogitBias to nil")
+		return errors.New("invalid: unable to decode CreateEmbeddingRequestEncodingFormat to nil")
 	}
-	m := s.init()
-	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
-		var elem int
-		if err := func() error {
-			v, err := d.Int()
-			elem = int(v)
-			if err != nil {
-				return err
-			}
-			return nil
-		}(); err != nil {
-			return errors.Wrapf(err, "decode field %q", k)
-		}
-		m[string(k)] = elem
-		return nil
-	}); err != nil {
-		return errors.Wrap(err, "decode CreateCompletionRequestLogitBias")
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateEmbeddingRequestEncodingFormat(v) {
+	case CreateEmbeddingRequestEncodingFormatFloat:
+		*s = CreateEmbeddingRequestEncodingFormatFloat
+	default:
+		*s = CreateEmbeddingRequestEncodingFormat(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateCompletionRequestLogitBias) MarshalJSON() ([]byte, error) {
+func (s CreateEmbeddingRequestEncodingFormat) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateCompletionRequestLogitBias) UnmarshalJSON(data []byte) error {
+func (s *CreateEmbeddingRequestEncodingFormat) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateCompletionRequestPrompt as json.
-func (s CreateCompletionRequestPrompt) Encode(e *jx.Encoder) {
+// Encode encodes CreateEmbeddingRequestInput as json.
+func (s CreateEmbeddingRequestInput) Encode(e *jx.Encoder) {
 	switch s.Type {
-	case StringCreateCompletionRequestPrompt:
+	case StringCreateEmbeddingRequestInput:
 		e.Str(s.String)
-	case StringArrayCreateCompletionRequestPrompt:
+	case StringArrayCreateEmbeddingRequestInput:
 		e.ArrStart()
 		for _, elem := range s.StringArray {
 			e.Str(elem)
@@ -3983,10 +5640,10 @@ func (s CreateCompletionRequestPrompt) Encode(e *jx.Encoder) {
 	}
 }
 
-// Decode decodes CreateCompletionRequestPrompt from json.
-func (s *CreateCompletionRequestPrompt) Decode(d *jx.Decoder) error {
+// Decode decodes CreateEmbeddingRequestInput from json.
+func (s *CreateEmbeddingRequestInput) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateCompletionRequestPrompt to nil")
+		return errors.New("invalid: unable to decode CreateEmbeddingRequestInput to nil")
 	}
 	// Sum type type_discriminator.
 	switch t := d.Next(); t {
@@ -4004,14 +5661,14 @@ func (s *CreateCompletionRequestPrompt) Decode(d *jx.Decoder) error {
 		}); err != nil {
 			return err
 		}
-		s.Type = StringArrayCreateCompletionRequestPrompt
+		s.Type = StringArrayCreateEmbeddingRequestInput
 	case jx.String:
 		v, err := d.Str()
 		s.String = string(v)
 		if err != nil {
 			return err
 		}
-		s.Type = StringCreateCompletionRequestPrompt
+		s.Type = StringCreateEmbeddingRequestInput
 	default:
 		return errors.Errorf("unexpected json type %q", t)
 	}
@@ -4019,241 +5676,449 @@ func (s *CreateCompletionRequestPrompt) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateCompletionRequestPrompt) MarshalJSON() ([]byte, error) {
+func (s CreateEmbeddingRequestInput) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateCompletionRequestPrompt) UnmarshalJSON(data []byte) error {
+func (s *CreateEmbeddingRequestInput) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateCompletionRequestStop as json.
-func (s CreateCompletionRequestStop) Encode(e *jx.Encoder) {
-	switch s.Type {
-	case StringCreateCompletionRequestStop:
-		e.Str(s.String)
-	case StringArrayCreateCompletionRequestStop:
+// Encode implements json.Marshaler.
+func (s *CreateEmbeddingResponse) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *CreateEmbeddingResponse) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("object")
+		s.Object.Encode(e)
+	}
+	{
+		e.FieldStart("data")
 		e.ArrStart()
-		for _, elem := range s.StringArray {
-			e.Str(elem)
+		for _, elem := range s.Data {
+			elem.Encode(e)
 		}
 		e.ArrEnd()
 	}
+	{
+		e.FieldStart("model")
+		e.Str(s.Model)
+	}
+	{
+		e.FieldStart("usage")
+		s.Usage.Encode(e)
+	}
 }
 
-// Decode decodes CreateCompletionRequestStop from json.
-func (s *CreateCompletionRequestStop) Decode(d *jx.Decoder) error {
+var jsonFieldsNameOfCreateEmbeddingResponse = [4]string{
+	0: "object",
+	1: "data",
+	2: "model",
+	3: "usage",
+}
+
+// Decode decodes CreateEmbeddingResponse from json.
+func (s *CreateEmbeddingResponse) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateCompletionRequestStop to nil")
+		return errors.New("invalid: unable to decode CreateEmbeddingResponse to nil")
 	}
-	// Sum type type_discriminator.
-	switch t := d.Next(); t {
-	case jx.Array:
-		s.StringArray = make([]string, 0)
-		if err := d.Arr(func(d *jx.Decoder) error {
-			var elem string
-			v, err := d.Str()
-			elem = string(v)
-			if err != nil {
-				return err
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "object":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				if err := s.Object.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"object\"")
 			}
-			s.StringArray = append(s.StringArray, elem)
-			return nil
-		}); err != nil {
-			return err
+		case "data":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				s.Data = make([]Embedding, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem Embedding
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Data = append(s.Data, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"data\"")
+			}
+		case "model":
+			requiredBitSet[0] |= 1 << 2
+			if err := func() error {
+				v, err := d.Str()
+				s.Model = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"model\"")
+			}
+		case "usage":
+			requiredBitSet[0] |= 1 << 3
+			if err := func() error {
+				if err := s.Usage.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"usage\"")
+			}
+		default:
+			return d.Skip()
 		}
-		s.Type = StringArrayCreateCompletionRequestStop
-	case jx.String:
-		v, err := d.Str()
-		s.String = string(v)
-		if err != nil {
-			return err
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode CreateEmbeddingResponse")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00001111,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfCreateEmbeddingResponse) {
+					name = jsonFieldsNameOfCreateEmbeddingResponse[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
 		}
-		s.Type = StringCreateCompletionRequestStop
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *CreateEmbeddingResponse) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateEmbeddingResponse) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateEmbeddingResponseObject as json.
+func (s CreateEmbeddingResponseObject) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateEmbeddingResponseObject from json.
+func (s *CreateEmbeddingResponseObject) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateEmbeddingResponseObject to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateEmbeddingResponseObject(v) {
+	case CreateEmbeddingResponseObjectList:
+		*s = CreateEmbeddingResponseObjectList
 	default:
-		return errors.Errorf("unexpected json type %q", t)
+		*s = CreateEmbeddingResponseObject(v)
 	}
+
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateCompletionRequestStop) MarshalJSON() ([]byte, error) {
+func (s CreateEmbeddingResponseObject) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateCompletionRequestStop) UnmarshalJSON(data []byte) error {
+func (s *CreateEmbeddingResponseObject) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateCompletionResponse) Encode(e *jx.Encoder) {
+func (s *CreateImageRequest) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateCompletionResponse) encodeFields(e *jx.Encoder) {
+func (s *CreateImageRequest) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("id")
-		e.Str(s.ID)
+		e.FieldStart("prompt")
+		e.Str(s.Prompt)
 	}
 	{
-		e.FieldStart("object")
-		s.Object.Encode(e)
+		if s.Model.Set {
+			e.FieldStart("model")
+			s.Model.Encode(e)
+		}
 	}
 	{
-		e.FieldStart("created")
-		e.Int64(s.Created)
+		if s.N.Set {
+			e.FieldStart("n")
+			s.N.Encode(e)
+		}
 	}
 	{
-		e.FieldStart("model")
-		e.Str(s.Model)
+		if s.Size.Set {
+			e.FieldStart("size")
+			s.Size.Encode(e)
+		}
 	}
 	{
-		e.FieldStart("choices")
-		e.ArrStart()
-		for _, elem := range s.Choices {
-			elem.Encode(e)
+		if s.ResponseFormat.Set {
+			e.FieldStart("response_format")
+			s.ResponseFormat.Encode(e)
 		}
-		e.ArrEnd()
 	}
 	{
-		if s.Usage.Set {
-			e.FieldStart("usage")
-			s.Usage.Encode(e)
+		if s.Quality.Set {
+			e.FieldStart("quality")
+			s.Quality.Encode(e)
 		}
 	}
 	{
-		if s.SystemFingerprint.Set {
-			e.FieldStart("system_fingerprint")
-			s.SystemFingerprint.Encode(e)
+		if s.Style.Set {
+			e.FieldStart("style")
+			s.Style.Encode(e)
+		}
+	}
+	{
+		if s.Background.Set {
+			e.FieldStart("background")
+			s.Background.Encode(e)
+		}
+	}
+	{
+		if s.OutputFormat.Set {
+			e.FieldStart("output_format")
+			s.OutputFormat.Encode(e)
+		}
+	}
+	{
+		if s.OutputCompression.Set {
+			e.FieldStart("output_compression")
+			s.OutputCompression.Encode(e)
+		}
+	}
+	{
+		if s.Moderation.Set {
+			e.FieldStart("moderation")
+			s.Moderation.Encode(e)
+		}
+	}
+	{
+		if s.User.Set {
+			e.FieldStart("user")
+			s.User.Encode(e)
 		}
 	}
 }
 
-var jsonFieldsNameOfCreateCompletionResponse = [7]string{
-	0: "id",
-	1: "object",
-	2: "created",
-	3: "model",
-	4: "choices",
-	5: "usage",
-	6: "system_fingerprint",
+var jsonFieldsNameOfCreateImageRequest = [12]string{
+	0:  "prompt",
+	1:  "model",
+	2:  "n",
+	3:  "size",
+	4:  "response_format",
+	5:  "quality",
+	6:  "style",
+	7:  "background",
+	8:  "output_format",
+	9:  "output_compression",
+	10: "moderation",
+	11: "user",
 }
 
-// Decode decodes CreateCompletionResponse from json.
-func (s *CreateCompletionResponse) Decode(d *jx.Decoder) error {
+// Decode decodes CreateImageRequest from json.
+func (s *CreateImageRequest) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateCompletionResponse to nil")
+		return errors.New("invalid: unable to decode CreateImageRequest to nil")
 	}
-	var requiredBitSet [1]uint8
+	var requiredBitSet [2]uint8
+	s.setDefaults()
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "id":
+		case "prompt":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
 				v, err := d.Str()
-				s.ID = string(v)
+				s.Prompt = string(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"id\"")
+				return errors.Wrap(err, "decode field \"prompt\"")
 			}
-		case "object":
-			requiredBitSet[0] |= 1 << 1
+		case "model":
 			if err := func() error {
-				if err := s.Object.Decode(d); err != nil {
+				s.Model.Reset()
+				if err := s.Model.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"object\"")
+				return errors.Wrap(err, "decode field \"model\"")
 			}
-		case "created":
-			requiredBitSet[0] |= 1 << 2
+		case "n":
 			if err := func() error {
-				v, err := d.Int64()
-				s.Created = int64(v)
-				if err != nil {
+				s.N.Reset()
+				if err := s.N.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"created\"")
+				return errors.Wrap(err, "decode field \"n\"")
 			}
-		case "model":
-			requiredBitSet[0] |= 1 << 3
+		case "size":
 			if err := func() error {
-				v, err := d.Str()
-				s.Model = string(v)
-				if err != nil {
+				s.Size.Reset()
+				if err := s.Size.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"model\"")
+				return errors.Wrap(err, "decode field \"size\"")
 			}
-		case "choices":
-			requiredBitSet[0] |= 1 << 4
+		case "response_format":
 			if err := func() error {
-				s.Choices = make([]CompletionChoice, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem CompletionChoice
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.Choices = append(s.Choices, elem)
-					return nil
-				}); err != nil {
+				s.ResponseFormat.Reset()
+				if err := s.ResponseFormat.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"choices\"")
+				return errors.Wrap(err, "decode field \"response_format\"")
 			}
-		case "usage":
+		case "quality":
 			if err := func() error {
-				s.Usage.Reset()
-				if err := s.Usage.Decode(d); err != nil {
+				s.Quality.Reset()
+				if err := s.Quality.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"usage\"")
+				return errors.Wrap(err, "decode field \"quality\"")
 			}
-		case "system_fingerprint":
+		case "style":
 			if err := func() error {
-				s.SystemFingerprint.Reset()
-				if err := s.SystemFingerprint.Decode(d); err != nil {
+				s.Style.Reset()
+				if err := s.Style.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"system_fingerprint\"")
+				return errors.Wrap(err, "decode field \"style\"")
+			}
+		case "background":
+			if err := func() error {
+				s.Background.Reset()
+				if err := s.Background.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"background\"")
+			}
+		case "output_format":
+			if err := func() error {
+				s.OutputFormat.Reset()
+				if err := s.OutputFormat.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"output_format\"")
+			}
+		case "output_compression":
+			if err := func() error {
+				s.OutputCompression.Reset()
+				if err := s.OutputCompression.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"output_compression\"")
+			}
+		case "moderation":
+			if err := func() error {
+				s.Moderation.Reset()
+				if err := s.Moderation.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"moderation\"")
+			}
+		case "user":
+			if err := func() error {
+				s.User.Reset()
+				if err := s.User.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"user\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateCompletionResponse")
+		return errors.Wrap(err, "decode CreateImageRequest")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
-	for i, mask := range [1]uint8{
-		0b00011111,
+	for i, mask := range [2]uint8{
+		0b00000001,
+		0b00000000,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -4265,8 +6130,8 @@ func (s *CreateCompletionResponse) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateCompletionResponse) {
-					name = jsonFieldsNameOfCreateCompletionResponse[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfCreateImageRequest) {
+					name = jsonFieldsNameOfCreateImageRequest[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -4287,83 +6152,225 @@ func (s *CreateCompletionResponse) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateCompletionResponse) MarshalJSON() ([]byte, error) {
+func (s *CreateImageRequest) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateCompletionResponse) UnmarshalJSON(data []byte) error {
+func (s *CreateImageRequest) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateCompletionResponseObject as json.
-func (s CreateCompletionResponseObject) Encode(e *jx.Encoder) {
+// Encode encodes CreateImageRequestBackground as json.
+func (s CreateImageRequestBackground) Encode(e *jx.Encoder) {
 	e.Str(string(s))
 }
 
-// Decode decodes CreateCompletionResponseObject from json.
-func (s *CreateCompletionResponseObject) Decode(d *jx.Decoder) error {
+// Decode decodes CreateImageRequestBackground from json.
+func (s *CreateImageRequestBackground) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateCompletionResponseObject to nil")
+		return errors.New("invalid: unable to decode CreateImageRequestBackground to nil")
 	}
 	v, err := d.StrBytes()
 	if err != nil {
 		return err
 	}
 	// Try to use constant string.
-	switch CreateCompletionResponseObject(v) {
-	case CreateCompletionResponseObjectTextCompletion:
-		*s = CreateCompletionResponseObjectTextCompletion
+	switch CreateImageRequestBackground(v) {
+	case CreateImageRequestBackgroundTransparent:
+		*s = CreateImageRequestBackgroundTransparent
+	case CreateImageRequestBackgroundOpaque:
+		*s = CreateImageRequestBackgroundOpaque
+	case CreateImageRequestBackgroundAuto:
+		*s = CreateImageRequestBackgroundAuto
 	default:
-		*s = CreateCompletionResponseObject(v)
+		*s = CreateImageRequestBackground(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateCompletionResponseObject) MarshalJSON() ([]byte, error) {
+func (s CreateImageRequestBackground) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateCompletionResponseObject) UnmarshalJSON(data []byte) error {
+func (s *CreateImageRequestBackground) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateImageRequestOutputFormat as json.
+func (s CreateImageRequestOutputFormat) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateImageRequestOutputFormat from json.
+func (s *CreateImageRequestOutputFormat) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateImageRequestOutputFormat to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateImageRequestOutputFormat(v) {
+	case CreateImageRequestOutputFormatPNG:
+		*s = CreateImageRequestOutputFormatPNG
+	case CreateImageRequestOutputFormatJpeg:
+		*s = CreateImageRequestOutputFormatJpeg
+	case CreateImageRequestOutputFormatWEBP:
+		*s = CreateImageRequestOutputFormatWEBP
+	default:
+		*s = CreateImageRequestOutputFormat(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateImageRequestOutputFormat) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateImageRequestOutputFormat) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateImageRequestResponseFormat as json.
+func (s CreateImageRequestResponseFormat) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateImageRequestResponseFormat from json.
+func (s *CreateImageRequestResponseFormat) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateImageRequestResponseFormat to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateImageRequestResponseFormat(v) {
+	case CreateImageRequestResponseFormatURL:
+		*s = CreateImageRequestResponseFormatURL
+	case CreateImageRequestResponseFormatB64JSON:
+		*s = CreateImageRequestResponseFormatB64JSON
+	default:
+		*s = CreateImageRequestResponseFormat(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateImageRequestResponseFormat) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateImageRequestResponseFormat) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateImageRequestSize as json.
+func (s CreateImageRequestSize) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateImageRequestSize from json.
+func (s *CreateImageRequestSize) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateImageRequestSize to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateImageRequestSize(v) {
+	case CreateImageRequestSizeAuto:
+		*s = CreateImageRequestSizeAuto
+	case CreateImageRequestSize256x256:
+		*s = CreateImageRequestSize256x256
+	case CreateImageRequestSize512x512:
+		*s = CreateImageRequestSize512x512
+	case CreateImageRequestSize1024x1024:
+		*s = CreateImageRequestSize1024x1024
+	case CreateImageRequestSize1536x1024:
+		*s = CreateImageRequestSize1536x1024
+	case CreateImageRequestSize1024x1536:
+		*s = CreateImageRequestSize1024x1536
+	case CreateImageRequestSize1792x1024:
+		*s = CreateImageRequestSize1792x1024
+	case CreateImageRequestSize1024x1792:
+		*s = CreateImageRequestSize1024x1792
+	default:
+		*s = CreateImageRequestSize(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateImageRequestSize) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateImageRequestSize) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateEmbeddingRequest) Encode(e *jx.Encoder) {
+func (s *CreateResponseRequest) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateEmbeddingRequest) encodeFields(e *jx.Encoder) {
+func (s *CreateResponseRequest) encodeFields(e *jx.Encoder) {
 	{
 		e.FieldStart("model")
 		e.Str(s.Model)
 	}
 	{
 		e.FieldStart("input")
-		s.Input.Encode(e)
+		e.Str(s.Input)
 	}
 	{
-		if s.EncodingFormat.Set {
-			e.FieldStart("encoding_format")
-			s.EncodingFormat.Encode(e)
+		if s.Tools != nil {
+			e.FieldStart("tools")
+			e.ArrStart()
+			for _, elem := range s.Tools {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
 		}
 	}
 	{
-		if s.Dimensions.Set {
-			e.FieldStart("dimensions")
-			s.Dimensions.Encode(e)
+		if s.Text.Set {
+			e.FieldStart("text")
+			s.Text.Encode(e)
 		}
 	}
 	{
@@ -4372,23 +6379,29 @@ func (s *CreateEmbeddingRequest) encodeFields(e *jx.Encoder) {
 			s.User.Encode(e)
 		}
 	}
+	{
+		if s.SafetyIdentifier.Set {
+			e.FieldStart("safety_identifier")
+			s.SafetyIdentifier.Encode(e)
+		}
+	}
 }
 
-var jsonFieldsNameOfCreateEmbeddingRequest = [5]string{
+var jsonFieldsNameOfCreateResponseRequest = [6]string{
 	0: "model",
 	1: "input",
-	2: "encoding_format",
-	3: "dimensions",
+	2: "tools",
+	3: "text",
 	4: "user",
+	5: "safety_identifier",
 }
 
-// Decode decodes CreateEmbeddingRequest from json.
-func (s *CreateEmbeddingRequest) Decode(d *jx.Decoder) error {
+// Decode decodes CreateResponseRequest from json.
+func (s *CreateResponseRequest) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateEmbeddingRequest to nil")
+		return errors.New("invalid: unable to decode CreateResponseRequest to nil")
 	}
 	var requiredBitSet [1]uint8
-	s.setDefaults()
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
@@ -4407,32 +6420,41 @@ func (s *CreateEmbeddingRequest) Decode(d *jx.Decoder) error {
 		case "input":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				if err := s.Input.Decode(d); err != nil {
+				v, err := d.Str()
+				s.Input = string(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"input\"")
 			}
-		case "encoding_format":
+		case "tools":
 			if err := func() error {
-				s.EncodingFormat.Reset()
-				if err := s.EncodingFormat.Decode(d); err != nil {
+				s.Tools = make([]ResponseTool, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ResponseTool
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Tools = append(s.Tools, elem)
+					return nil
+				}); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"encoding_format\"")
+				return errors.Wrap(err, "decode field \"tools\"")
 			}
-		case "dimensions":
+		case "text":
 			if err := func() error {
-				s.Dimensions.Reset()
-				if err := s.Dimensions.Decode(d); err != nil {
+				s.Text.Reset()
+				if err := s.Text.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"dimensions\"")
+				return errors.Wrap(err, "decode field \"text\"")
 			}
 		case "user":
 			if err := func() error {
@@ -4444,12 +6466,22 @@ func (s *CreateEmbeddingRequest) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"user\"")
 			}
+		case "safety_identifier":
+			if err := func() error {
+				s.SafetyIdentifier.Reset()
+				if err := s.SafetyIdentifier.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"safety_identifier\"")
+			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateEmbeddingRequest")
+		return errors.Wrap(err, "decode CreateResponseRequest")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -4466,8 +6498,8 @@ func (s *CreateEmbeddingRequest) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateEmbeddingRequest) {
-					name = jsonFieldsNameOfCreateEmbeddingRequest[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfCreateResponseRequest) {
+					name = jsonFieldsNameOfCreateResponseRequest[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -4488,195 +6520,140 @@ func (s *CreateEmbeddingRequest) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateEmbeddingRequest) MarshalJSON() ([]byte, error) {
-	e := jx.Encoder{}
-	s.Encode(&e)
-	return e.Bytes(), nil
-}
-
-// UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateEmbeddingRequest) UnmarshalJSON(data []byte) error {
-	d := jx.DecodeBytes(data)
-	return s.Decode(d)
-}
-
-// Encode encodes CreateEmbeddingRequestEncodingFormat as json.
-func (s CreateEmbeddingRequestEncodingFormat) Encode(e *jx.Encoder) {
-	e.Str(string(s))
-}
-
-// Decode decodes CreateEmbeddingRequestEncodingFormat from json.
-func (s *CreateEmbeddingRequestEncodingFormat) Decode(d *jx.Decoder) error {
-	if s == nil {
-		return errors.New("invalid: unable to decode CreateEmbeddingRequestEncodingFormat to nil")
-	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
-	}
-	// Try to use constant string.
-	switch CreateEmbeddingRequestEncodingFormat(v) {
-	case CreateEmbeddingRequestEncodingFormatFloat:
-		*s = CreateEmbeddingRequestEncodingFormatFloat
-	default:
-		*s = CreateEmbeddingRequestEncodingFormat(v)
-	}
-
-	return nil
-}
-
-// MarshalJSON implements stdjson.Marshaler.
-func (s CreateEmbeddingRequestEncodingFormat) MarshalJSON() ([]byte, error) {
-	e := jx.Encoder{}
-	s.Encode(&e)
-	return e.Bytes(), nil
-}
-
-// UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateEmbeddingRequestEncodingFormat) UnmarshalJSON(data []byte) error {
-	d := jx.DecodeBytes(data)
-	return s.Decode(d)
-}
-
-// Encode encodes CreateEmbeddingRequestInput as json.
-func (s CreateEmbeddingRequestInput) Encode(e *jx.Encoder) {
-	switch s.Type {
-	case StringCreateEmbeddingRequestInput:
-		e.Str(s.String)
-	case StringArrayCreateEmbeddingRequestInput:
-		e.ArrStart()
-		for _, elem := range s.StringArray {
-			e.Str(elem)
-		}
-		e.ArrEnd()
-	}
-}
-
-// Decode decodes CreateEmbeddingRequestInput from json.
-func (s *CreateEmbeddingRequestInput) Decode(d *jx.Decoder) error {
-	if s == nil {
-		return errors.New("invalid: unable to decode CreateEmbeddingRequestInput to nil")
-	}
-	// Sum type type_discriminator.
-	switch t := d.Next(); t {
-	case jx.Array:
-		s.StringArray = make([]string, 0)
-		if err := d.Arr(func(d *jx.Decoder) error {
-			var elem string
-			v, err := d.Str()
-			elem = string(v)
-			if err != nil {
-				return err
-			}
-			s.StringArray = append(s.StringArray, elem)
-			return nil
-		}); err != nil {
-			return err
-		}
-		s.Type = StringArrayCreateEmbeddingRequestInput
-	case jx.String:
-		v, err := d.Str()
-		s.String = string(v)
-		if err != nil {
-			return err
-		}
-		s.Type = StringCreateEmbeddingRequestInput
-	default:
-		return errors.Errorf("unexpected json type %q", t)
-	}
-	return nil
-}
-
-// MarshalJSON implements stdjson.Marshaler.
-func (s CreateEmbeddingRequestInput) MarshalJSON() ([]byte, error) {
+func (s *CreateResponseRequest) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateEmbeddingRequestInput) UnmarshalJSON(data []byte) error {
+func (s *CreateResponseRequest) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateEmbeddingResponse) Encode(e *jx.Encoder) {
+func (s *CreateResponseResponse) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateEmbeddingResponse) encodeFields(e *jx.Encoder) {
+func (s *CreateResponseResponse) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("id")
+		e.Str(s.ID)
+	}
 	{
 		e.FieldStart("object")
 		s.Object.Encode(e)
 	}
 	{
-		e.FieldStart("data")
-		e.ArrStart()
-		for _, elem := range s.Data {
-			elem.Encode(e)
+		if s.CreatedAt.Set {
+			e.FieldStart("created_at")
+			s.CreatedAt.Encode(e)
 		}
-		e.ArrEnd()
+	}
+	{
+		e.FieldStart("status")
+		s.Status.Encode(e)
 	}
 	{
 		e.FieldStart("model")
 		e.Str(s.Model)
 	}
+	{
+		e.FieldStart("output")
+		e.ArrStart()
+		for _, elem := range s.Output {
+			elem.Encode(e)
+		}
+		e.ArrEnd()
+	}
 	{
 		e.FieldStart("usage")
 		s.Usage.Encode(e)
 	}
+	{
+		if s.User.Set {
+			e.FieldStart("user")
+			s.User.Encode(e)
+		}
+	}
+	{
+		if s.SafetyIdentifier.Set {
+			e.FieldStart("safety_identifier")
+			s.SafetyIdentifier.Encode(e)
+		}
+	}
 }
 
-var jsonFieldsNameOfCreateEmbeddingResponse = [4]string{
-	0: "object",
-	1: "data",
-	2: "model",
-	3: "usage",
+var jsonFieldsNameOfCreateResponseResponse = [9]string{
+	0: "id",
+	1: "object",
+	2: "created_at",
+	3: "status",
+	4: "model",
+	5: "output",
+	6: "usage",
+	7: "user",
+	8: "safety_identifier",
 }
 
-// Decode decodes CreateEmbeddingResponse from json.
-func (s *CreateEmbeddingResponse) Decode(d *jx.Decoder) error {
+// Decode decodes CreateResponseResponse from json.
+func (s *CreateResponseResponse) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateEmbeddingResponse to nil")
+		return errors.New("invalid: unable to decode CreateResponseResponse to nil")
 	}
-	var requiredBitSet [1]uint8
+	var requiredBitSet [2]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "object":
+		case "id":
 			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Str()
+				s.ID = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"id\"")
+			}
+		case "object":
+			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
 				if err := s.Object.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"object\"")
+				return errors.Wrap(err, "decode field \"object\"")
+			}
+		case "created_at":
+			if err := func() error {
+				s.CreatedAt.Reset()
+				if err := s.CreatedAt.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"created_at\"")
 			}
-		case "data":
-			requiredBitSet[0] |= 1 << 1
+		case "status":
+			requiredBitSet[0] |= 1 << 3
 			if err := func() error {
-				s.Data = make([]Embedding, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem Embedding
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.Data = append(s.Data, elem)
-					return nil
-				}); err != nil {
+				if err := s.Status.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"data\"")
+				return errors.Wrap(err, "decode field \"status\"")
 			}
 		case "model":
-			requiredBitSet[0] |= 1 << 2
+			requiredBitSet[0] |= 1 << 4
 			if err := func() error {
 				v, err := d.Str()
 				s.Model = string(v)
@@ -4687,8 +6664,26 @@ func (s *CreateEmbeddingResponse) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"model\"")
 			}
+		case "output":
+			requiredBitSet[0] |= 1 << 5
+			if err := func() error {
+				s.Output = make([]ResponseOutputItem, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ResponseOutputItem
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Output = append(s.Output, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"output\"")
+			}
 		case "usage":
-			requiredBitSet[0] |= 1 << 3
+			requiredBitSet[0] |= 1 << 6
 			if err := func() error {
 				if err := s.Usage.Decode(d); err != nil {
 					return err
@@ -4697,17 +6692,38 @@ func (s *CreateEmbeddingResponse) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"usage\"")
 			}
+		case "user":
+			if err := func() error {
+				s.User.Reset()
+				if err := s.User.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"user\"")
+			}
+		case "safety_identifier":
+			if err := func() error {
+				s.SafetyIdentifier.Reset()
+				if err := s.SafetyIdentifier.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"safety_identifier\"")
+			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateEmbeddingResponse")
+		return errors.Wrap(err, "decode CreateResponseResponse")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
-	for i, mask := range [1]uint8{
-		0b00001111,
+	for i, mask := range [2]uint8{
+		0b01111011,
+		0b00000000,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -4719,8 +6735,8 @@ func (s *CreateEmbeddingResponse) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateEmbeddingResponse) {
-					name = jsonFieldsNameOfCreateEmbeddingResponse[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfCreateResponseResponse) {
+					name = jsonFieldsNameOfCreateResponseResponse[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -4741,169 +6757,197 @@ func (s *CreateEmbeddingResponse) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateEmbeddingResponse) MarshalJSON() ([]byte, error) {
+func (s *CreateResponseResponse) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateEmbeddingResponse) UnmarshalJSON(data []byte) error {
+func (s *CreateResponseResponse) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateEmbeddingResponseObject as json.
-func (s CreateEmbeddingResponseObject) Encode(e *jx.Encoder) {
+// Encode encodes CreateResponseResponseObject as json.
+func (s CreateResponseResponseObject) Encode(e *jx.Encoder) {
 	e.Str(string(s))
 }
 
-// Decode decodes CreateEmbeddingResponseObject from json.
-func (s *CreateEmbeddingResponseObject) Decode(d *jx.Decoder) error {
+// Decode decodes CreateResponseResponseObject from json.
+func (s *CreateResponseResponseObject) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateEmbeddingResponseObject to nil")
+		return errors.New("invalid: unable to decode CreateResponseResponseObject to nil")
 	}
 	v, err := d.StrBytes()
 	if err != nil {
 		return err
 	}
 	// Try to use constant string.
-	switch CreateEmbeddingResponseObject(v) {
-	case CreateEmbeddingResponseObjectList:
-		*s = CreateEmbeddingResponseObjectList
+	switch CreateResponseResponseObject(v) {
+	case CreateResponseResponseObjectResponse:
+		*s = CreateResponseResponseObjectResponse
 	default:
-		*s = CreateEmbeddingResponseObject(v)
+		*s = CreateResponseResponseObject(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateEmbeddingResponseObject) MarshalJSON() ([]byte, error) {
+func (s CreateResponseResponseObject) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateEmbeddingResponseObject) UnmarshalJSON(data []byte) error {
+func (s *CreateResponseResponseObject) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateResponseResponseStatus as json.
+func (s CreateResponseResponseStatus) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes CreateResponseResponseStatus from json.
+func (s *CreateResponseResponseStatus) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode CreateResponseResponseStatus to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch CreateResponseResponseStatus(v) {
+	case CreateResponseResponseStatusCompleted:
+		*s = CreateResponseResponseStatusCompleted
+	case CreateResponseResponseStatusFailed:
+		*s = CreateResponseResponseStatusFailed
+	case CreateResponseResponseStatusCancelled:
+		*s = CreateResponseResponseStatusCancelled
+	case CreateResponseResponseStatusQueued:
+		*s = CreateResponseResponseStatusQueued
+	case CreateResponseResponseStatusInProgress:
+		*s = CreateResponseResponseStatusInProgress
+	default:
+		*s = CreateResponseResponseStatus(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s CreateResponseResponseStatus) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *CreateResponseResponseStatus) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateResponseRequest) Encode(e *jx.Encoder) {
+func (s *Embedding) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateResponseRequest) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("model")
-		e.Str(s.Model)
-	}
+func (s *Embedding) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("input")
-		e.Str(s.Input)
+		e.FieldStart("index")
+		e.Int(s.Index)
 	}
 	{
-		if s.Tools != nil {
-			e.FieldStart("tools")
-			e.ArrStart()
-			for _, elem := range s.Tools {
-				elem.Encode(e)
-			}
-			e.ArrEnd()
-		}
+		e.FieldStart("object")
+		s.Object.Encode(e)
 	}
 	{
-		if s.Text.Set {
-			e.FieldStart("text")
-			s.Text.Encode(e)
+		e.FieldStart("embedding")
+		e.ArrStart()
+		for _, elem := range s.Embedding {
+			e.Float64(elem)
 		}
+		e.ArrEnd()
 	}
 }
 
-var jsonFieldsNameOfCreateResponseRequest = [4]string{
-	0: "model",
-	1: "input",
-	2: "tools",
-	3: "text",
+var jsonFieldsNameOfEmbedding = [3]string{
+	0: "index",
+	1: "object",
+	2: "embedding",
 }
 
-// Decode decodes CreateResponseRequest from json.
-func (s *CreateResponseRequest) Decode(d *jx.Decoder) error {
+// Decode decodes Embedding from json.
+func (s *Embedding) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateResponseRequest to nil")
+		return errors.New("invalid: unable to decode Embedding to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "model":
+		case "index":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				v, err := d.Str()
-				s.Model = string(v)
+				v, err := d.Int()
+				s.Index = int(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"model\"")
+				return errors.Wrap(err, "decode field \"index\"")
 			}
-		case "input":
+		case "object":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Str()
-				s.Input = string(v)
-				if err != nil {
+				if err := s.Object.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"input\"")
+				return errors.Wrap(err, "decode field \"object\"")
 			}
-		case "tools":
+		case "embedding":
+			requiredBitSet[0] |= 1 << 2
 			if err := func() error {
-				s.Tools = make([]ResponseTool, 0)
+				s.Embedding = make([]float64, 0)
 				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ResponseTool
-					if err := elem.Decode(d); err != nil {
+					var elem float64
+					v, err := d.Float64()
+					elem = float64(v)
+					if err != nil {
 						return err
 					}
-					s.Tools = append(s.Tools, elem)
+					s.Embedding = append(s.Embedding, elem)
 					return nil
 				}); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"tools\"")
-			}
-		case "text":
-			if err := func() error {
-				s.Text.Reset()
-				if err := s.Text.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"text\"")
+				return errors.Wrap(err, "decode field \"embedding\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateResponseRequest")
+		return errors.Wrap(err, "decode Embedding")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00000011,
+		0b00000111,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -4915,8 +6959,8 @@ func (s *CreateResponseRequest) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateResponseRequest) {
-					name = jsonFieldsNameOfCreateResponseRequest[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfEmbedding) {
+					name = jsonFieldsNameOfEmbedding[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -4929,183 +6973,132 @@ func (s *CreateResponseRequest) Decode(d *jx.Decoder) error {
 			}
 		}
 	}
-	if len(failures) > 0 {
-		return &validate.Error{Fields: failures}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *Embedding) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *Embedding) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes EmbeddingObject as json.
+func (s EmbeddingObject) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes EmbeddingObject from json.
+func (s *EmbeddingObject) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode EmbeddingObject to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch EmbeddingObject(v) {
+	case EmbeddingObjectEmbedding:
+		*s = EmbeddingObjectEmbedding
+	default:
+		*s = EmbeddingObject(v)
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateResponseRequest) MarshalJSON() ([]byte, error) {
+func (s EmbeddingObject) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateResponseRequest) UnmarshalJSON(data []byte) error {
+func (s *EmbeddingObject) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *CreateResponseResponse) Encode(e *jx.Encoder) {
+func (s *EmbeddingUsage) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *CreateResponseResponse) encodeFields(e *jx.Encoder) {
-	{
-		e.FieldStart("id")
-		e.Str(s.ID)
-	}
-	{
-		e.FieldStart("object")
-		s.Object.Encode(e)
-	}
-	{
-		if s.CreatedAt.Set {
-			e.FieldStart("created_at")
-			s.CreatedAt.Encode(e)
-		}
-	}
-	{
-		e.FieldStart("status")
-		s.Status.Encode(e)
-	}
-	{
-		e.FieldStart("model")
-		e.Str(s.Model)
-	}
+func (s *EmbeddingUsage) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("output")
-		e.ArrStart()
-		for _, elem := range s.Output {
-			elem.Encode(e)
-		}
-		e.ArrEnd()
+		e.FieldStart("prompt_tokens")
+		e.Int(s.PromptTokens)
 	}
 	{
-		e.FieldStart("usage")
-		s.Usage.Encode(e)
+		e.FieldStart("total_tokens")
+		e.Int(s.TotalTokens)
 	}
 }
 
-var jsonFieldsNameOfCreateResponseResponse = [7]string{
-	0: "id",
-	1: "object",
-	2: "created_at",
-	3: "status",
-	4: "model",
-	5: "output",
-	6: "usage",
+var jsonFieldsNameOfEmbeddingUsage = [2]string{
+	0: "prompt_tokens",
+	1: "total_tokens",
 }
 
-// Decode decodes CreateResponseResponse from json.
-func (s *CreateResponseResponse) Decode(d *jx.Decoder) error {
+// Decode decodes EmbeddingUsage from json.
+func (s *EmbeddingUsage) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateResponseResponse to nil")
+		return errors.New("invalid: unable to decode EmbeddingUsage to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "id":
+		case "prompt_tokens":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				v, err := d.Str()
-				s.ID = string(v)
+				v, err := d.Int()
+				s.PromptTokens = int(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"id\"")
+				return errors.Wrap(err, "decode field \"prompt_tokens\"")
 			}
-		case "object":
+		case "total_tokens":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				if err := s.Object.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"object\"")
-			}
-		case "created_at":
-			if err := func() error {
-				s.CreatedAt.Reset()
-				if err := s.CreatedAt.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"created_at\"")
-			}
-		case "status":
-			requiredBitSet[0] |= 1 << 3
-			if err := func() error {
-				if err := s.Status.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"status\"")
-			}
-		case "model":
-			requiredBitSet[0] |= 1 << 4
-			if err := func() error {
-				v, err := d.Str()
-				s.Model = string(v)
+				v, err := d.Int()
+				s.TotalTokens = int(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"model\"")
-			}
-		case "output":
-			requiredBitSet[0] |= 1 << 5
-			if err := func() error {
-				s.Output = make([]ResponseOutputItem, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem ResponseOutputItem
-					if err := elem.Decode(d); err != nil {
-						return err
-					}
-					s.Output = append(s.Output, elem)
-					return nil
-				}); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"output\"")
-			}
-		case "usage":
-			requiredBitSet[0] |= 1 << 6
-			if err := func() error {
-				if err := s.Usage.Decode(d); err != nil {
-					return err
-				}
-				return nil
-			}(); err != nil {
-				return errors.Wrap(err, "decode field \"usage\"")
+				return errors.Wrap(err, "decode field \"total_tokens\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode CreateResponseResponse")
+		return errors.Wrap(err, "decode EmbeddingUsage")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b01111011,
+		0b00000011,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -5117,8 +7110,8 @@ func (s *CreateResponseResponse) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfCreateResponseResponse) {
-					name = jsonFieldsNameOfCreateResponseResponse[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfEmbeddingUsage) {
+					name = jsonFieldsNameOfEmbeddingUsage[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -5139,197 +7132,215 @@ func (s *CreateResponseResponse) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *CreateResponseResponse) MarshalJSON() ([]byte, error) {
+func (s *EmbeddingUsage) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateResponseResponse) UnmarshalJSON(data []byte) error {
+func (s *EmbeddingUsage) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes CreateResponseResponseObject as json.
-func (s CreateResponseResponseObject) Encode(e *jx.Encoder) {
-	e.Str(string(s))
+// Encode implements json.Marshaler.
+func (s *Image) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
 }
 
-// Decode decodes CreateResponseResponseObject from json.
-func (s *CreateResponseResponseObject) Decode(d *jx.Decoder) error {
-	if s == nil {
-		return errors.New("invalid: unable to decode CreateResponseResponseObject to nil")
+// encodeFields encodes fields.
+func (s *Image) encodeFields(e *jx.Encoder) {
+	{
+		if s.B64JSON.Set {
+			e.FieldStart("b64_json")
+			s.B64JSON.Encode(e)
+		}
 	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
+	{
+		if s.URL.Set {
+			e.FieldStart("url")
+			s.URL.Encode(e)
+		}
 	}
-	// Try to use constant string.
-	switch CreateResponseResponseObject(v) {
-	case CreateResponseResponseObjectResponse:
-		*s = CreateResponseResponseObjectResponse
-	default:
-		*s = CreateResponseResponseObject(v)
+	{
+		if s.RevisedPrompt.Set {
+			e.FieldStart("revised_prompt")
+			s.RevisedPrompt.Encode(e)
+		}
 	}
-
-	return nil
-}
-
-// MarshalJSON implements stdjson.Marshaler.
-func (s CreateResponseResponseObject) MarshalJSON() ([]byte, error) {
-	e := jx.Encoder{}
-	s.Encode(&e)
-	return e.Bytes(), nil
 }
 
-// UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateResponseResponseObject) UnmarshalJSON(data []byte) error {
-	d := jx.DecodeBytes(data)
-	return s.Decode(d)
-}
-
-// Encode encodes CreateResponseResponseStatus as json.
-func (s CreateResponseResponseStatus) Encode(e *jx.Encoder) {
-	e.Str(string(s))
+var jsonFieldsNameOfImage = [3]string{
+	0: "b64_json",
+	1: "url",
+	2: "revised_prompt",
 }
 
-// Decode decodes CreateResponseResponseStatus from json.
-func (s *CreateResponseResponseStatus) Decode(d *jx.Decoder) error {
+// Decode decodes Image from json.
+func (s *Image) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode CreateResponseResponseStatus to nil")
-	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
+		return errors.New("invalid: unable to decode Image to nil")
 	}
-	// Try to use constant string.
-	switch CreateResponseResponseStatus(v) {
-	case CreateResponseResponseStatusCompleted:
-		*s = CreateResponseResponseStatusCompleted
-	case CreateResponseResponseStatusFailed:
-		*s = CreateResponseResponseStatusFailed
-	case CreateResponseResponseStatusCancelled:
-		*s = CreateResponseResponseStatusCancelled
-	case CreateResponseResponseStatusQueued:
-		*s = CreateResponseResponseStatusQueued
-	case CreateResponseResponseStatusInProgress:
-		*s = CreateResponseResponseStatusInProgress
-	default:
-		*s = CreateResponseResponseStatus(v)
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "b64_json":
+			if err := func() error {
+				s.B64JSON.Reset()
+				if err := s.B64JSON.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"b64_json\"")
+			}
+		case "url":
+			if err := func() error {
+				s.URL.Reset()
+				if err := s.URL.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"url\"")
+			}
+		case "revised_prompt":
+			if err := func() error {
+				s.RevisedPrompt.Reset()
+				if err := s.RevisedPrompt.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"revised_prompt\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode Image")
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s CreateResponseResponseStatus) MarshalJSON() ([]byte, error) {
+func (s *Image) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *CreateResponseResponseStatus) UnmarshalJSON(data []byte) error {
+func (s *Image) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *Embedding) Encode(e *jx.Encoder) {
+func (s *ImageUsage) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *Embedding) encodeFields(e *jx.Encoder) {
+func (s *ImageUsage) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("index")
-		e.Int(s.Index)
+		e.FieldStart("input_tokens")
+		e.Int(s.InputTokens)
 	}
 	{
-		e.FieldStart("object")
-		s.Object.Encode(e)
+		e.FieldStart("output_tokens")
+		e.Int(s.OutputTokens)
 	}
 	{
-		e.FieldStart("embedding")
-		e.ArrStart()
-		for _, elem := range s.Embedding {
-			e.Float64(elem)
-		}
-		e.ArrEnd()
+		e.FieldStart("total_tokens")
+		e.Int(s.TotalTokens)
+	}
+	{
+		e.FieldStart("input_tokens_details")
+		s.InputTokensDetails.Encode(e)
 	}
 }
 
-var jsonFieldsNameOfEmbedding = [3]string{
-	0: "index",
-	1: "object",
-	2: "embedding",
+var jsonFieldsNameOfImageUsage = [4]string{
+	0: "input_tokens",
+	1: "output_tokens",
+	2: "total_tokens",
+	3: "input_tokens_details",
 }
 
-// Decode decodes Embedding from json.
-func (s *Embedding) Decode(d *jx.Decoder) error {
+// Decode decodes ImageUsage from json.
+func (s *ImageUsage) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode Embedding to nil")
+		return errors.New("invalid: unable to decode ImageUsage to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "index":
+		case "input_tokens":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
 				v, err := d.Int()
-				s.Index = int(v)
+				s.InputTokens = int(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"index\"")
+				return errors.Wrap(err, "decode field \"input_tokens\"")
 			}
-		case "object":
+		case "output_tokens":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				if err := s.Object.Decode(d); err != nil {
+				v, err := d.Int()
+				s.OutputTokens = int(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"object\"")
+				return errors.Wrap(err, "decode field \"output_tokens\"")
 			}
-		case "embedding":
+		case "total_tokens":
 			requiredBitSet[0] |= 1 << 2
 			if err := func() error {
-				s.Embedding = make([]float64, 0)
-				if err := d.Arr(func(d *jx.Decoder) error {
-					var elem float64
-					v, err := d.Float64()
-					elem = float64(v)
-					if err != nil {
-						return err
-					}
-					s.Embedding = append(s.Embedding, elem)
-					return nil
-				}); err != nil {
+				v, err := d.Int()
+				s.TotalTokens = int(v)
+				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"embedding\"")
+				return errors.Wrap(err, "decode field \"total_tokens\"")
+			}
+		case "input_tokens_details":
+			requiredBitSet[0] |= 1 << 3
+			if err := func() error {
+				if err := s.InputTokensDetails.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"input_tokens_details\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode Embedding")
+		return errors.Wrap(err, "decode ImageUsage")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00000111,
+		0b00001111,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -5341,8 +7352,8 @@ func (s *Embedding) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfEmbedding) {
-					name = jsonFieldsNameOfEmbedding[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfImageUsage) {
+					name = jsonFieldsNameOfImageUsage[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -5363,119 +7374,289 @@ func (s *Embedding) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *Embedding) MarshalJSON() ([]byte, error) {
+func (s *ImageUsage) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *Embedding) UnmarshalJSON(data []byte) error {
+func (s *ImageUsage) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes EmbeddingObject as json.
-func (s EmbeddingObject) Encode(e *jx.Encoder) {
-	e.Str(string(s))
+// Encode implements json.Marshaler.
+func (s *ImageUsageInputTokensDetails) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
 }
 
-// Decode decodes EmbeddingObject from json.
-func (s *EmbeddingObject) Decode(d *jx.Decoder) error {
+// encodeFields encodes fields.
+func (s *ImageUsageInputTokensDetails) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("text_tokens")
+		e.Int(s.TextTokens)
+	}
+	{
+		e.FieldStart("image_tokens")
+		e.Int(s.ImageTokens)
+	}
+}
+
+var jsonFieldsNameOfImageUsageInputTokensDetails = [2]string{
+	0: "text_tokens",
+	1: "image_tokens",
+}
+
+// Decode decodes ImageUsageInputTokensDetails from json.
+func (s *ImageUsageInputTokensDetails) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode EmbeddingObject to nil")
+		return errors.New("invalid: unable to decode ImageUsageInputTokensDetails to nil")
 	}
-	v, err := d.StrBytes()
-	if err != nil {
-		return err
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "text_tokens":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Int()
+				s.TextTokens = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"text_tokens\"")
+			}
+		case "image_tokens":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				v, err := d.Int()
+				s.ImageTokens = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"image_tokens\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode ImageUsageInputTokensDetails")
 	}
-	// Try to use constant string.
-	switch EmbeddingObject(v) {
-	case EmbeddingObjectEmbedding:
-		*s = EmbeddingObjectEmbedding
-	default:
-		*s = EmbeddingObject(v)
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000011,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfImageUsageInputTokensDetails) {
+					name = jsonFieldsNameOfImageUsageInputTokensDetails[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
 	}
 
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s EmbeddingObject) MarshalJSON() ([]byte, error) {
+func (s *ImageUsageInputTokensDetails) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *EmbeddingObject) UnmarshalJSON(data []byte) error {
+func (s *ImageUsageInputTokensDetails) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
 // Encode implements json.Marshaler.
-func (s *EmbeddingUsage) Encode(e *jx.Encoder) {
+func (s *ImagesResponse) Encode(e *jx.Encoder) {
 	e.ObjStart()
 	s.encodeFields(e)
 	e.ObjEnd()
 }
 
 // encodeFields encodes fields.
-func (s *EmbeddingUsage) encodeFields(e *jx.Encoder) {
+func (s *ImagesResponse) encodeFields(e *jx.Encoder) {
 	{
-		e.FieldStart("prompt_tokens")
-		e.Int(s.PromptTokens)
+		e.FieldStart("created")
+		e.Int64(s.Created)
 	}
 	{
-		e.FieldStart("total_tokens")
-		e.Int(s.TotalTokens)
+		e.FieldStart("data")
+		e.ArrStart()
+		for _, elem := range s.Data {
+			elem.Encode(e)
+		}
+		e.ArrEnd()
+	}
+	{
+		if s.Background.Set {
+			e.FieldStart("background")
+			s.Background.Encode(e)
+		}
+	}
+	{
+		if s.OutputFormat.Set {
+			e.FieldStart("output_format")
+			s.OutputFormat.Encode(e)
+		}
+	}
+	{
+		if s.Quality.Set {
+			e.FieldStart("quality")
+			s.Quality.Encode(e)
+		}
+	}
+	{
+		if s.Size.Set {
+			e.FieldStart("size")
+			s.Size.Encode(e)
+		}
+	}
+	{
+		if s.Usage.Set {
+			e.FieldStart("usage")
+			s.Usage.Encode(e)
+		}
 	}
 }
 
-var jsonFieldsNameOfEmbeddingUsage = [2]string{
-	0: "prompt_tokens",
-	1: "total_tokens",
+var jsonFieldsNameOfImagesResponse = [7]string{
+	0: "created",
+	1: "data",
+	2: "background",
+	3: "output_format",
+	4: "quality",
+	5: "size",
+	6: "usage",
 }
 
-// Decode decodes EmbeddingUsage from json.
-func (s *EmbeddingUsage) Decode(d *jx.Decoder) error {
+// Decode decodes ImagesResponse from json.
+func (s *ImagesResponse) Decode(d *jx.Decoder) error {
 	if s == nil {
-		return errors.New("invalid: unable to decode EmbeddingUsage to nil")
+		return errors.New("invalid: unable to decode ImagesResponse to nil")
 	}
 	var requiredBitSet [1]uint8
 
 	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
 		switch string(k) {
-		case "prompt_tokens":
+		case "created":
 			requiredBitSet[0] |= 1 << 0
 			if err := func() error {
-				v, err := d.Int()
-				s.PromptTokens = int(v)
+				v, err := d.Int64()
+				s.Created = int64(v)
 				if err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"prompt_tokens\"")
+				return errors.Wrap(err, "decode field \"created\"")
 			}
-		case "total_tokens":
+		case "data":
 			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Int()
-				s.TotalTokens = int(v)
-				if err != nil {
+				s.Data = make([]Image, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem Image
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Data = append(s.Data, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"data\"")
+			}
+		case "background":
+			if err := func() error {
+				s.Background.Reset()
+				if err := s.Background.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"background\"")
+			}
+		case "output_format":
+			if err := func() error {
+				s.OutputFormat.Reset()
+				if err := s.OutputFormat.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"output_format\"")
+			}
+		case "quality":
+			if err := func() error {
+				s.Quality.Reset()
+				if err := s.Quality.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"quality\"")
+			}
+		case "size":
+			if err := func() error {
+				s.Size.Reset()
+				if err := s.Size.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"size\"")
+			}
+		case "usage":
+			if err := func() error {
+				s.Usage.Reset()
+				if err := s.Usage.Decode(d); err != nil {
 					return err
 				}
 				return nil
 			}(); err != nil {
-				return errors.Wrap(err, "decode field \"total_tokens\"")
+				return errors.Wrap(err, "decode field \"usage\"")
 			}
 		default:
 			return d.Skip()
 		}
 		return nil
 	}); err != nil {
-		return errors.Wrap(err, "decode EmbeddingUsage")
+		return errors.Wrap(err, "decode ImagesResponse")
 	}
 	// Validate required fields.
 	var failures []validate.FieldError
@@ -5492,8 +7673,8 @@ func (s *EmbeddingUsage) Decode(d *jx.Decoder) error {
 				bitIdx := bits.TrailingZeros8(result)
 				fieldIdx := i*8 + bitIdx
 				var name string
-				if fieldIdx < len(jsonFieldsNameOfEmbeddingUsage) {
-					name = jsonFieldsNameOfEmbeddingUsage[fieldIdx]
+				if fieldIdx < len(jsonFieldsNameOfImagesResponse) {
+					name = jsonFieldsNameOfImagesResponse[fieldIdx]
 				} else {
 					name = strconv.Itoa(fieldIdx)
 				}
@@ -5514,14 +7695,14 @@ func (s *EmbeddingUsage) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *EmbeddingUsage) MarshalJSON() ([]byte, error) {
+func (s *ImagesResponse) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *EmbeddingUsage) UnmarshalJSON(data []byte) error {
+func (s *ImagesResponse) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
@@ -5982,6 +8163,72 @@ func (s *OptChatCompletionJSONSchemaSpec) UnmarshalJSON(data []byte) error {
 	return s.Decode(d)
 }
 
+// Encode encodes ChatCompletionRequestMessageContentPartImageURL as json.
+func (o OptChatCompletionRequestMessageContentPartImageURL) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	o.Value.Encode(e)
+}
+
+// Decode decodes ChatCompletionRequestMessageContentPartImageURL from json.
+func (o *OptChatCompletionRequestMessageContentPartImageURL) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptChatCompletionRequestMessageContentPartImageURL to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptChatCompletionRequestMessageContentPartImageURL) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptChatCompletionRequestMessageContentPartImageURL) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ChatCompletionRequestMessageContentPartInputAudio as json.
+func (o OptChatCompletionRequestMessageContentPartInputAudio) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	o.Value.Encode(e)
+}
+
+// Decode decodes ChatCompletionRequestMessageContentPartInputAudio from json.
+func (o *OptChatCompletionRequestMessageContentPartInputAudio) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptChatCompletionRequestMessageContentPartInputAudio to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptChatCompletionRequestMessageContentPartInputAudio) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptChatCompletionRequestMessageContentPartInputAudio) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
 // Encode encodes ChatCompletionRequestMessageFunctionCall as json.
 func (o OptChatCompletionRequestMessageFunctionCall) Encode(e *jx.Encoder) {
 	if !o.Set {
@@ -6214,6 +8461,39 @@ func (s *OptCreateChatCompletionRequestStop) UnmarshalJSON(data []byte) error {
 	return s.Decode(d)
 }
 
+// Encode encodes CreateChatCompletionRequestToolChoice as json.
+func (o OptCreateChatCompletionRequestToolChoice) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	o.Value.Encode(e)
+}
+
+// Decode decodes CreateChatCompletionRequestToolChoice from json.
+func (o *OptCreateChatCompletionRequestToolChoice) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptCreateChatCompletionRequestToolChoice to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptCreateChatCompletionRequestToolChoice) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptCreateChatCompletionRequestToolChoice) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
 // Encode encodes CreateCompletionRequestLogitBias as json.
 func (o OptCreateCompletionRequestLogitBias) Encode(e *jx.Encoder) {
 	if !o.Set {
@@ -6314,6 +8594,138 @@ func (s *OptCreateEmbeddingRequestEncodingFormat) UnmarshalJSON(data []byte) err
 	return s.Decode(d)
 }
 
+// Encode encodes CreateImageRequestBackground as json.
+func (o OptCreateImageRequestBackground) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	e.Str(string(o.Value))
+}
+
+// Decode decodes CreateImageRequestBackground from json.
+func (o *OptCreateImageRequestBackground) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptCreateImageRequestBackground to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptCreateImageRequestBackground) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptCreateImageRequestBackground) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateImageRequestOutputFormat as json.
+func (o OptCreateImageRequestOutputFormat) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	e.Str(string(o.Value))
+}
+
+// Decode decodes CreateImageRequestOutputFormat from json.
+func (o *OptCreateImageRequestOutputFormat) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptCreateImageRequestOutputFormat to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptCreateImageRequestOutputFormat) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptCreateImageRequestOutputFormat) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateImageRequestResponseFormat as json.
+func (o OptCreateImageRequestResponseFormat) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	e.Str(string(o.Value))
+}
+
+// Decode decodes CreateImageRequestResponseFormat from json.
+func (o *OptCreateImageRequestResponseFormat) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptCreateImageRequestResponseFormat to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptCreateImageRequestResponseFormat) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptCreateImageRequestResponseFormat) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes CreateImageRequestSize as json.
+func (o OptCreateImageRequestSize) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	e.Str(string(o.Value))
+}
+
+// Decode decodes CreateImageRequestSize from json.
+func (o *OptCreateImageRequestSize) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptCreateImageRequestSize to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptCreateImageRequestSize) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptCreateImageRequestSize) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
 // Encode encodes float64 as json.
 func (o OptFloat64) Encode(e *jx.Encoder) {
 	if !o.Set {
@@ -6349,6 +8761,39 @@ func (s *OptFloat64) UnmarshalJSON(data []byte) error {
 	return s.Decode(d)
 }
 
+// Encode encodes ImageUsage as json.
+func (o OptImageUsage) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	o.Value.Encode(e)
+}
+
+// Decode decodes ImageUsage from json.
+func (o *OptImageUsage) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptImageUsage to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptImageUsage) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptImageUsage) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
 // Encode encodes int as json.
 func (o OptInt) Encode(e *jx.Encoder) {
 	if !o.Set {
@@ -6395,32 +8840,81 @@ func (o OptInt64) Encode(e *jx.Encoder) {
 // Decode decodes int64 from json.
 func (o *OptInt64) Decode(d *jx.Decoder) error {
 	if o == nil {
-		return errors.New("invalid: unable to decode OptInt64 to nil")
+		return errors.New("invalid: unable to decode OptInt64 to nil")
+	}
+	o.Set = true
+	v, err := d.Int64()
+	if err != nil {
+		return err
+	}
+	o.Value = int64(v)
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptInt64) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptInt64) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ChatCompletionChoiceLogprobs as json.
+func (o OptNilChatCompletionChoiceLogprobs) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	if o.Null {
+		e.Null()
+		return
+	}
+	o.Value.Encode(e)
+}
+
+// Decode decodes ChatCompletionChoiceLogprobs from json.
+func (o *OptNilChatCompletionChoiceLogprobs) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptNilChatCompletionChoiceLogprobs to nil")
+	}
+	if d.Next() == jx.Null {
+		if err := d.Null(); err != nil {
+			return err
+		}
+
+		var v ChatCompletionChoiceLogprobs
+		o.Value = v
+		o.Set = true
+		o.Null = true
+		return nil
 	}
 	o.Set = true
-	v, err := d.Int64()
-	if err != nil {
+	o.Null = false
+	if err := o.Value.Decode(d); err != nil {
 		return err
 	}
-	o.Value = int64(v)
 	return nil
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s OptInt64) MarshalJSON() ([]byte, error) {
+func (s OptNilChatCompletionChoiceLogprobs) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *OptInt64) UnmarshalJSON(data []byte) error {
+func (s *OptNilChatCompletionChoiceLogprobs) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
 
-// Encode encodes ChatCompletionChoiceLogprobs as json.
-func (o OptNilChatCompletionChoiceLogprobs) Encode(e *jx.Encoder) {
+// Encode encodes ChatCompletionRequestMessageContent as json.
+func (o OptNilChatCompletionRequestMessageContent) Encode(e *jx.Encoder) {
 	if !o.Set {
 		return
 	}
@@ -6431,17 +8925,17 @@ func (o OptNilChatCompletionChoiceLogprobs) Encode(e *jx.Encoder) {
 	o.Value.Encode(e)
 }
 
-// Decode decodes ChatCompletionChoiceLogprobs from json.
-func (o *OptNilChatCompletionChoiceLogprobs) Decode(d *jx.Decoder) error {
+// Decode decodes ChatCompletionRequestMessageContent from json.
+func (o *OptNilChatCompletionRequestMessageContent) Decode(d *jx.Decoder) error {
 	if o == nil {
-		return errors.New("invalid: unable to decode OptNilChatCompletionChoiceLogprobs to nil")
+		return errors.New("invalid: unable to decode OptNilChatCompletionRequestMessageContent to nil")
 	}
 	if d.Next() == jx.Null {
 		if err := d.Null(); err != nil {
 			return err
 		}
 
-		var v ChatCompletionChoiceLogprobs
+		var v ChatCompletionRequestMessageContent
 		o.Value = v
 		o.Set = true
 		o.Null = true
@@ -6456,14 +8950,14 @@ func (o *OptNilChatCompletionChoiceLogprobs) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s OptNilChatCompletionChoiceLogprobs) MarshalJSON() ([]byte, error) {
+func (s OptNilChatCompletionRequestMessageContent) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *OptNilChatCompletionChoiceLogprobs) UnmarshalJSON(data []byte) error {
+func (s *OptNilChatCompletionRequestMessageContent) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
@@ -6814,6 +9308,72 @@ func (s *OptString) UnmarshalJSON(data []byte) error {
 	return s.Decode(d)
 }
 
+// Encode encodes WebSearchOptions as json.
+func (o OptWebSearchOptions) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	o.Value.Encode(e)
+}
+
+// Decode decodes WebSearchOptions from json.
+func (o *OptWebSearchOptions) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptWebSearchOptions to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptWebSearchOptions) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptWebSearchOptions) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes WebSearchOptionsSearchContextSize as json.
+func (o OptWebSearchOptionsSearchContextSize) Encode(e *jx.Encoder) {
+	if !o.Set {
+		return
+	}
+	e.Str(string(o.Value))
+}
+
+// Decode decodes WebSearchOptionsSearchContextSize from json.
+func (o *OptWebSearchOptionsSearchContextSize) Decode(d *jx.Decoder) error {
+	if o == nil {
+		return errors.New("invalid: unable to decode OptWebSearchOptionsSearchContextSize to nil")
+	}
+	o.Set = true
+	if err := o.Value.Decode(d); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s OptWebSearchOptionsSearchContextSize) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *OptWebSearchOptionsSearchContextSize) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
 // Encode implements json.Marshaler.
 func (s *PromptTokensDetails) Encode(e *jx.Encoder) {
 	e.ObjStart()
@@ -6882,14 +9442,267 @@ func (s *PromptTokensDetails) Decode(d *jx.Decoder) error {
 }
 
 // MarshalJSON implements stdjson.Marshaler.
-func (s *PromptTokensDetails) MarshalJSON() ([]byte, error) {
+func (s *PromptTokensDetails) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *PromptTokensDetails) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *ResponseOutputAnnotation) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *ResponseOutputAnnotation) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("type")
+		s.Type.Encode(e)
+	}
+	{
+		if s.StartIndex.Set {
+			e.FieldStart("start_index")
+			s.StartIndex.Encode(e)
+		}
+	}
+	{
+		if s.EndIndex.Set {
+			e.FieldStart("end_index")
+			s.EndIndex.Encode(e)
+		}
+	}
+	{
+		if s.URL.Set {
+			e.FieldStart("url")
+			s.URL.Encode(e)
+		}
+	}
+	{
+		if s.Title.Set {
+			e.FieldStart("title")
+			s.Title.Encode(e)
+		}
+	}
+	{
+		if s.Index.Set {
+			e.FieldStart("index")
+			s.Index.Encode(e)
+		}
+	}
+	{
+		if s.FileID.Set {
+			e.FieldStart("file_id")
+			s.FileID.Encode(e)
+		}
+	}
+	{
+		if s.Filename.Set {
+			e.FieldStart("filename")
+			s.Filename.Encode(e)
+		}
+	}
+}
+
+var jsonFieldsNameOfResponseOutputAnnotation = [8]string{
+	0: "type",
+	1: "start_index",
+	2: "end_index",
+	3: "url",
+	4: "title",
+	5: "index",
+	6: "file_id",
+	7: "filename",
+}
+
+// Decode decodes ResponseOutputAnnotation from json.
+func (s *ResponseOutputAnnotation) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ResponseOutputAnnotation to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "type":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				if err := s.Type.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"type\"")
+			}
+		case "start_index":
+			if err := func() error {
+				s.StartIndex.Reset()
+				if err := s.StartIndex.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"start_index\"")
+			}
+		case "end_index":
+			if err := func() error {
+				s.EndIndex.Reset()
+				if err := s.EndIndex.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"end_index\"")
+			}
+		case "url":
+			if err := func() error {
+				s.URL.Reset()
+				if err := s.URL.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"url\"")
+			}
+		case "title":
+			if err := func() error {
+				s.Title.Reset()
+				if err := s.Title.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"title\"")
+			}
+		case "index":
+			if err := func() error {
+				s.Index.Reset()
+				if err := s.Index.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"index\"")
+			}
+		case "file_id":
+			if err := func() error {
+				s.FileID.Reset()
+				if err := s.FileID.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"file_id\"")
+			}
+		case "filename":
+			if err := func() error {
+				s.Filename.Reset()
+				if err := s.Filename.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"filename\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode ResponseOutputAnnotation")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00000001,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfResponseOutputAnnotation) {
+					name = jsonFieldsNameOfResponseOutputAnnotation[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *ResponseOutputAnnotation) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *ResponseOutputAnnotation) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes ResponseOutputAnnotationType as json.
+func (s ResponseOutputAnnotationType) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes ResponseOutputAnnotationType from json.
+func (s *ResponseOutputAnnotationType) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode ResponseOutputAnnotationType to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch ResponseOutputAnnotationType(v) {
+	case ResponseOutputAnnotationTypeURLCitation:
+		*s = ResponseOutputAnnotationTypeURLCitation
+	case ResponseOutputAnnotationTypeFileCitation:
+		*s = ResponseOutputAnnotationTypeFileCitation
+	default:
+		*s = ResponseOutputAnnotationType(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s ResponseOutputAnnotationType) MarshalJSON() ([]byte, error) {
 	e := jx.Encoder{}
 	s.Encode(&e)
 	return e.Bytes(), nil
 }
 
 // UnmarshalJSON implements stdjson.Unmarshaler.
-func (s *PromptTokensDetails) UnmarshalJSON(data []byte) error {
+func (s *ResponseOutputAnnotationType) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
@@ -6911,11 +9724,22 @@ func (s *ResponseOutputContent) encodeFields(e *jx.Encoder) {
 		e.FieldStart("text")
 		e.Str(s.Text)
 	}
+	{
+		if s.Annotations != nil {
+			e.FieldStart("annotations")
+			e.ArrStart()
+			for _, elem := range s.Annotations {
+				elem.Encode(e)
+			}
+			e.ArrEnd()
+		}
+	}
 }
 
-var jsonFieldsNameOfResponseOutputContent = [2]string{
+var jsonFieldsNameOfResponseOutputContent = [3]string{
 	0: "type",
 	1: "text",
+	2: "annotations",
 }
 
 // Decode decodes ResponseOutputContent from json.
@@ -6949,6 +9773,23 @@ func (s *ResponseOutputContent) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"text\"")
 			}
+		case "annotations":
+			if err := func() error {
+				s.Annotations = make([]ResponseOutputAnnotation, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem ResponseOutputAnnotation
+					if err := elem.Decode(d); err != nil {
+						return err
+					}
+					s.Annotations = append(s.Annotations, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"annotations\"")
+			}
 		default:
 			return d.Skip()
 		}
@@ -7555,8 +10396,10 @@ func (s *ResponseTool) encodeFields(e *jx.Encoder) {
 		s.Type.Encode(e)
 	}
 	{
-		e.FieldStart("name")
-		e.Str(s.Name)
+		if s.Name.Set {
+			e.FieldStart("name")
+			s.Name.Encode(e)
+		}
 	}
 	{
 		if s.Description.Set {
@@ -7570,13 +10413,24 @@ func (s *ResponseTool) encodeFields(e *jx.Encoder) {
 			e.Raw(s.Parameters)
 		}
 	}
+	{
+		if s.VectorStoreIds != nil {
+			e.FieldStart("vector_store_ids")
+			e.ArrStart()
+			for _, elem := range s.VectorStoreIds {
+				e.Str(elem)
+			}
+			e.ArrEnd()
+		}
+	}
 }
 
-var jsonFieldsNameOfResponseTool = [4]string{
+var jsonFieldsNameOfResponseTool = [5]string{
 	0: "type",
 	1: "name",
 	2: "description",
 	3: "parameters",
+	4: "vector_store_ids",
 }
 
 // Decode decodes ResponseTool from json.
@@ -7599,11 +10453,9 @@ func (s *ResponseTool) Decode(d *jx.Decoder) error {
 				return errors.Wrap(err, "decode field \"type\"")
 			}
 		case "name":
-			requiredBitSet[0] |= 1 << 1
 			if err := func() error {
-				v, err := d.Str()
-				s.Name = string(v)
-				if err != nil {
+				s.Name.Reset()
+				if err := s.Name.Decode(d); err != nil {
 					return err
 				}
 				return nil
@@ -7631,6 +10483,25 @@ func (s *ResponseTool) Decode(d *jx.Decoder) error {
 			}(); err != nil {
 				return errors.Wrap(err, "decode field \"parameters\"")
 			}
+		case "vector_store_ids":
+			if err := func() error {
+				s.VectorStoreIds = make([]string, 0)
+				if err := d.Arr(func(d *jx.Decoder) error {
+					var elem string
+					v, err := d.Str()
+					elem = string(v)
+					if err != nil {
+						return err
+					}
+					s.VectorStoreIds = append(s.VectorStoreIds, elem)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"vector_store_ids\"")
+			}
 		default:
 			return d.Skip()
 		}
@@ -7641,7 +10512,7 @@ func (s *ResponseTool) Decode(d *jx.Decoder) error {
 	// Validate required fields.
 	var failures []validate.FieldError
 	for i, mask := range [1]uint8{
-		0b00000011,
+		0b00000001,
 	} {
 		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
 			// Mask only required fields and check equality to mask using XOR.
@@ -7705,6 +10576,12 @@ func (s *ResponseToolType) Decode(d *jx.Decoder) error {
 	switch ResponseToolType(v) {
 	case ResponseToolTypeFunction:
 		*s = ResponseToolTypeFunction
+	case ResponseToolTypeWebSearch:
+		*s = ResponseToolTypeWebSearch
+	case ResponseToolTypeWebSearchPreview:
+		*s = ResponseToolTypeWebSearchPreview
+	case ResponseToolTypeFileSearch:
+		*s = ResponseToolTypeFileSearch
 	default:
 		*s = ResponseToolType(v)
 	}
@@ -7854,3 +10731,273 @@ func (s *ResponseUsage) UnmarshalJSON(data []byte) error {
 	d := jx.DecodeBytes(data)
 	return s.Decode(d)
 }
+
+// Encode implements json.Marshaler.
+func (s *URLCitation) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *URLCitation) encodeFields(e *jx.Encoder) {
+	{
+		e.FieldStart("start_index")
+		e.Int(s.StartIndex)
+	}
+	{
+		e.FieldStart("end_index")
+		e.Int(s.EndIndex)
+	}
+	{
+		e.FieldStart("url")
+		e.Str(s.URL)
+	}
+	{
+		e.FieldStart("title")
+		e.Str(s.Title)
+	}
+}
+
+var jsonFieldsNameOfURLCitation = [4]string{
+	0: "start_index",
+	1: "end_index",
+	2: "url",
+	3: "title",
+}
+
+// Decode decodes URLCitation from json.
+func (s *URLCitation) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode URLCitation to nil")
+	}
+	var requiredBitSet [1]uint8
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "start_index":
+			requiredBitSet[0] |= 1 << 0
+			if err := func() error {
+				v, err := d.Int()
+				s.StartIndex = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"start_index\"")
+			}
+		case "end_index":
+			requiredBitSet[0] |= 1 << 1
+			if err := func() error {
+				v, err := d.Int()
+				s.EndIndex = int(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"end_index\"")
+			}
+		case "url":
+			requiredBitSet[0] |= 1 << 2
+			if err := func() error {
+				v, err := d.Str()
+				s.URL = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"url\"")
+			}
+		case "title":
+			requiredBitSet[0] |= 1 << 3
+			if err := func() error {
+				v, err := d.Str()
+				s.Title = string(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"title\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode URLCitation")
+	}
+	// Validate required fields.
+	var failures []validate.FieldError
+	for i, mask := range [1]uint8{
+		0b00001111,
+	} {
+		if result := (requiredBitSet[i] & mask) ^ mask; result != 0 {
+			// Mask only required fields and check equality to mask using XOR.
+			//
+			// If XOR result is not zero, result is not equal to expected, so some fields are missed.
+			// Bits of fields which would be set are actually bits of missed fields.
+			missed := bits.OnesCount8(result)
+			for bitN := 0; bitN < missed; bitN++ {
+				bitIdx := bits.TrailingZeros8(result)
+				fieldIdx := i*8 + bitIdx
+				var name string
+				if fieldIdx < len(jsonFieldsNameOfURLCitation) {
+					name = jsonFieldsNameOfURLCitation[fieldIdx]
+				} else {
+					name = strconv.Itoa(fieldIdx)
+				}
+				failures = append(failures, validate.FieldError{
+					Name:  name,
+					Error: validate.ErrFieldRequired,
+				})
+				// Reset bit.
+				result &^= 1 << bitIdx
+			}
+		}
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *URLCitation) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *URLCitation) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode implements json.Marshaler.
+func (s *WebSearchOptions) Encode(e *jx.Encoder) {
+	e.ObjStart()
+	s.encodeFields(e)
+	e.ObjEnd()
+}
+
+// encodeFields encodes fields.
+func (s *WebSearchOptions) encodeFields(e *jx.Encoder) {
+	{
+		if s.SearchContextSize.Set {
+			e.FieldStart("search_context_size")
+			s.SearchContextSize.Encode(e)
+		}
+	}
+	{
+		if len(s.UserLocation) != 0 {
+			e.FieldStart("user_location")
+			e.Raw(s.UserLocation)
+		}
+	}
+}
+
+var jsonFieldsNameOfWebSearchOptions = [2]string{
+	0: "search_context_size",
+	1: "user_location",
+}
+
+// Decode decodes WebSearchOptions from json.
+func (s *WebSearchOptions) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode WebSearchOptions to nil")
+	}
+
+	if err := d.ObjBytes(func(d *jx.Decoder, k []byte) error {
+		switch string(k) {
+		case "search_context_size":
+			if err := func() error {
+				s.SearchContextSize.Reset()
+				if err := s.SearchContextSize.Decode(d); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"search_context_size\"")
+			}
+		case "user_location":
+			if err := func() error {
+				v, err := d.RawAppend(nil)
+				s.UserLocation = jx.Raw(v)
+				if err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return errors.Wrap(err, "decode field \"user_location\"")
+			}
+		default:
+			return d.Skip()
+		}
+		return nil
+	}); err != nil {
+		return errors.Wrap(err, "decode WebSearchOptions")
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s *WebSearchOptions) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *WebSearchOptions) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
+
+// Encode encodes WebSearchOptionsSearchContextSize as json.
+func (s WebSearchOptionsSearchContextSize) Encode(e *jx.Encoder) {
+	e.Str(string(s))
+}
+
+// Decode decodes WebSearchOptionsSearchContextSize from json.
+func (s *WebSearchOptionsSearchContextSize) Decode(d *jx.Decoder) error {
+	if s == nil {
+		return errors.New("invalid: unable to decode WebSearchOptionsSearchContextSize to nil")
+	}
+	v, err := d.StrBytes()
+	if err != nil {
+		return err
+	}
+	// Try to use constant string.
+	switch WebSearchOptionsSearchContextSize(v) {
+	case WebSearchOptionsSearchContextSizeLow:
+		*s = WebSearchOptionsSearchContextSizeLow
+	case WebSearchOptionsSearchContextSizeMedium:
+		*s = WebSearchOptionsSearchContextSizeMedium
+	case WebSearchOptionsSearchContextSizeHigh:
+		*s = WebSearchOptionsSearchContextSizeHigh
+	default:
+		*s = WebSearchOptionsSearchContextSize(v)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements stdjson.Marshaler.
+func (s WebSearchOptionsSearchContextSize) MarshalJSON() ([]byte, error) {
+	e := jx.Encoder{}
+	s.Encode(&e)
+	return e.Bytes(), nil
+}
+
+// UnmarshalJSON implements stdjson.Unmarshaler.
+func (s *WebSearchOptionsSearchContextSize) UnmarshalJSON(data []byte) error {
+	d := jx.DecodeBytes(data)
+	return s.Decode(d)
+}
@@ -9,6 +9,7 @@ const (
 	CreateChatCompletionOperation OperationName = "CreateChatCompletion"
 	CreateCompletionOperation     OperationName = "CreateCompletion"
 	CreateEmbeddingOperation      OperationName = "CreateEmbedding"
+	CreateImageOperation          OperationName = "CreateImage"
 	CreateResponseOperation       OperationName = "CreateResponse"
 	ListModelsOperation           OperationName = "ListModels"
 	RetrieveModelOperation        OperationName = "RetrieveModel"
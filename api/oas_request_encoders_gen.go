@@ -52,6 +52,20 @@ func encodeCreateEmbeddingRequest(
 	return nil
 }
 
+func encodeCreateImageRequest(
+	req *CreateImageRequest,
+	r *http.Request,
+) error {
+	const contentType = "application/json"
+	e := new(jx.Encoder)
+	{
+		req.Encode(e)
+	}
+	encoded := e.Bytes()
+	ht.SetBody(r, bytes.NewReader(encoded), contentType)
+	return nil
+}
+
 func encodeCreateResponseRequest(
 	req *CreateResponseRequest,
 	r *http.Request,
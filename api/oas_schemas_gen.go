@@ -169,6 +169,66 @@ func (s *ChatCompletionJSONSchemaSpec) SetSchema(val jx.Raw) {
 	s.Schema = val
 }
 
+// Ref: #/components/schemas/ChatCompletionMessageAnnotation
+type ChatCompletionMessageAnnotation struct {
+	Type        ChatCompletionMessageAnnotationType `json:"type"`
+	URLCitation URLCitation                         `json:"url_citation"`
+}
+
+// GetType returns the value of Type.
+func (s *ChatCompletionMessageAnnotation) GetType() ChatCompletionMessageAnnotationType {
+	return s.Type
+}
+
+// GetURLCitation returns the value of URLCitation.
+func (s *ChatCompletionMessageAnnotation) GetURLCitation() URLCitation {
+	return s.URLCitation
+}
+
+// SetType sets the value of Type.
+func (s *ChatCompletionMessageAnnotation) SetType(val ChatCompletionMessageAnnotationType) {
+	s.Type = val
+}
+
+// SetURLCitation sets the value of URLCitation.
+func (s *ChatCompletionMessageAnnotation) SetURLCitation(val URLCitation) {
+	s.URLCitation = val
+}
+
+type ChatCompletionMessageAnnotationType string
+
+const (
+	ChatCompletionMessageAnnotationTypeURLCitation ChatCompletionMessageAnnotationType = "url_citation"
+)
+
+// AllValues returns all ChatCompletionMessageAnnotationType values.
+func (ChatCompletionMessageAnnotationType) AllValues() []ChatCompletionMessageAnnotationType {
+	return []ChatCompletionMessageAnnotationType{
+		ChatCompletionMessageAnnotationTypeURLCitation,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ChatCompletionMessageAnnotationType) MarshalText() ([]byte, error) {
+	switch s {
+	case ChatCompletionMessageAnnotationTypeURLCitation:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ChatCompletionMessageAnnotationType) UnmarshalText(data []byte) error {
+	switch ChatCompletionMessageAnnotationType(data) {
+	case ChatCompletionMessageAnnotationTypeURLCitation:
+		*s = ChatCompletionMessageAnnotationTypeURLCitation
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
 // Ref: #/components/schemas/ChatCompletionMessageToolCall
 type ChatCompletionMessageToolCall struct {
 	ID       string                                `json:"id"`
@@ -265,10 +325,84 @@ func (s *ChatCompletionMessageToolCallType) UnmarshalText(data []byte) error {
 	}
 }
 
+// Ref: #/components/schemas/ChatCompletionNamedToolChoice
+type ChatCompletionNamedToolChoice struct {
+	Type     ChatCompletionNamedToolChoiceType     `json:"type"`
+	Function ChatCompletionNamedToolChoiceFunction `json:"function"`
+}
+
+// GetType returns the value of Type.
+func (s *ChatCompletionNamedToolChoice) GetType() ChatCompletionNamedToolChoiceType {
+	return s.Type
+}
+
+// GetFunction returns the value of Function.
+func (s *ChatCompletionNamedToolChoice) GetFunction() ChatCompletionNamedToolChoiceFunction {
+	return s.Function
+}
+
+// SetType sets the value of Type.
+func (s *ChatCompletionNamedToolChoice) SetType(val ChatCompletionNamedToolChoiceType) {
+	s.Type = val
+}
+
+// SetFunction sets the value of Function.
+func (s *ChatCompletionNamedToolChoice) SetFunction(val ChatCompletionNamedToolChoiceFunction) {
+	s.Function = val
+}
+
+type ChatCompletionNamedToolChoiceFunction struct {
+	Name string `json:"name"`
+}
+
+// GetName returns the value of Name.
+func (s *ChatCompletionNamedToolChoiceFunction) GetName() string {
+	return s.Name
+}
+
+// SetName sets the value of Name.
+func (s *ChatCompletionNamedToolChoiceFunction) SetName(val string) {
+	s.Name = val
+}
+
+type ChatCompletionNamedToolChoiceType string
+
+const (
+	ChatCompletionNamedToolChoiceTypeFunction ChatCompletionNamedToolChoiceType = "function"
+)
+
+// AllValues returns all ChatCompletionNamedToolChoiceType values.
+func (ChatCompletionNamedToolChoiceType) AllValues() []ChatCompletionNamedToolChoiceType {
+	return []ChatCompletionNamedToolChoiceType{
+		ChatCompletionNamedToolChoiceTypeFunction,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ChatCompletionNamedToolChoiceType) MarshalText() ([]byte, error) {
+	switch s {
+	case ChatCompletionNamedToolChoiceTypeFunction:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ChatCompletionNamedToolChoiceType) UnmarshalText(data []byte) error {
+	switch ChatCompletionNamedToolChoiceType(data) {
+	case ChatCompletionNamedToolChoiceTypeFunction:
+		*s = ChatCompletionNamedToolChoiceTypeFunction
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
 // Ref: #/components/schemas/ChatCompletionRequestMessage
 type ChatCompletionRequestMessage struct {
 	Role         ChatCompletionRequestMessageRole            `json:"role"`
-	Content      string                                      `json:"content"`
+	Content      OptNilChatCompletionRequestMessageContent   `json:"content"`
 	Name         OptString                                   `json:"name"`
 	ToolCalls    []ChatCompletionMessageToolCall             `json:"tool_calls"`
 	ToolCallID   OptString                                   `json:"tool_call_id"`
@@ -281,7 +415,7 @@ func (s *ChatCompletionRequestMessage) GetRole() ChatCompletionRequestMessageRol
 }
 
 // GetContent returns the value of Content.
-func (s *ChatCompletionRequestMessage) GetContent() string {
+func (s *ChatCompletionRequestMessage) GetContent() OptNilChatCompletionRequestMessageContent {
 	return s.Content
 }
 
@@ -311,7 +445,7 @@ func (s *ChatCompletionRequestMessage) SetRole(val ChatCompletionRequestMessageR
 }
 
 // SetContent sets the value of Content.
-func (s *ChatCompletionRequestMessage) SetContent(val string) {
+func (s *ChatCompletionRequestMessage) SetContent(val OptNilChatCompletionRequestMessageContent) {
 	s.Content = val
 }
 
@@ -335,6 +469,263 @@ func (s *ChatCompletionRequestMessage) SetFunctionCall(val OptChatCompletionRequ
 	s.FunctionCall = val
 }
 
+// ChatCompletionRequestMessageContent represents sum type.
+type ChatCompletionRequestMessageContent struct {
+	// Type selects the active sum variant, switch on this field.
+	Type                                         ChatCompletionRequestMessageContentType
+	String                                       string
+	ChatCompletionRequestMessageContentPartArray []ChatCompletionRequestMessageContentPart
+}
+
+// ChatCompletionRequestMessageContentType is oneOf type of ChatCompletionRequestMessageContent.
+type ChatCompletionRequestMessageContentType string
+
+// Possible values for ChatCompletionRequestMessageContentType.
+const (
+	StringChatCompletionRequestMessageContent                                       ChatCompletionRequestMessageContentType = "string"
+	ChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent ChatCompletionRequestMessageContentType = "[]ChatCompletionRequestMessageContentPart"
+)
+
+// IsString reports whether ChatCompletionRequestMessageContent is string.
+func (s ChatCompletionRequestMessageContent) IsString() bool {
+	return s.Type == StringChatCompletionRequestMessageContent
+}
+
+// IsChatCompletionRequestMessageContentPartArray reports whether ChatCompletionRequestMessageContent is []ChatCompletionRequestMessageContentPart.
+func (s ChatCompletionRequestMessageContent) IsChatCompletionRequestMessageContentPartArray() bool {
+	return s.Type == ChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent
+}
+
+// SetString sets ChatCompletionRequestMessageContent to string.
+func (s *ChatCompletionRequestMessageContent) SetString(v string) {
+	s.Type = StringChatCompletionRequestMessageContent
+	s.String = v
+}
+
+// GetString returns string and true boolean if ChatCompletionRequestMessageContent is string.
+func (s ChatCompletionRequestMessageContent) GetString() (v string, ok bool) {
+	if !s.IsString() {
+		return v, false
+	}
+	return s.String, true
+}
+
+// NewStringChatCompletionRequestMessageContent returns new ChatCompletionRequestMessageContent from string.
+func NewStringChatCompletionRequestMessageContent(v string) ChatCompletionRequestMessageContent {
+	var s ChatCompletionRequestMessageContent
+	s.SetString(v)
+	return s
+}
+
+// SetChatCompletionRequestMessageContentPartArray sets ChatCompletionRequestMessageContent to []ChatCompletionRequestMessageContentPart.
+func (s *ChatCompletionRequestMessageContent) SetChatCompletionRequestMessageContentPartArray(v []ChatCompletionRequestMessageContentPart) {
+	s.Type = ChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent
+	s.ChatCompletionRequestMessageContentPartArray = v
+}
+
+// GetChatCompletionRequestMessageContentPartArray returns []ChatCompletionRequestMessageContentPart and true boolean if ChatCompletionRequestMessageContent is []ChatCompletionRequestMessageContentPart.
+func (s ChatCompletionRequestMessageContent) GetChatCompletionRequestMessageContentPartArray() (v []ChatCompletionRequestMessageContentPart, ok bool) {
+	if !s.IsChatCompletionRequestMessageContentPartArray() {
+		return v, false
+	}
+	return s.ChatCompletionRequestMessageContentPartArray, true
+}
+
+// NewChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent returns new ChatCompletionRequestMessageContent from []ChatCompletionRequestMessageContentPart.
+func NewChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent(v []ChatCompletionRequestMessageContentPart) ChatCompletionRequestMessageContent {
+	var s ChatCompletionRequestMessageContent
+	s.SetChatCompletionRequestMessageContentPartArray(v)
+	return s
+}
+
+// Ref: #/components/schemas/ChatCompletionRequestMessageContentPart
+type ChatCompletionRequestMessageContentPart struct {
+	Type       ChatCompletionRequestMessageContentPartType          `json:"type"`
+	Text       OptString                                            `json:"text"`
+	ImageURL   OptChatCompletionRequestMessageContentPartImageURL   `json:"image_url"`
+	InputAudio OptChatCompletionRequestMessageContentPartInputAudio `json:"input_audio"`
+}
+
+// GetType returns the value of Type.
+func (s *ChatCompletionRequestMessageContentPart) GetType() ChatCompletionRequestMessageContentPartType {
+	return s.Type
+}
+
+// GetText returns the value of Text.
+func (s *ChatCompletionRequestMessageContentPart) GetText() OptString {
+	return s.Text
+}
+
+// GetImageURL returns the value of ImageURL.
+func (s *ChatCompletionRequestMessageContentPart) GetImageURL() OptChatCompletionRequestMessageContentPartImageURL {
+	return s.ImageURL
+}
+
+// GetInputAudio returns the value of InputAudio.
+func (s *ChatCompletionRequestMessageContentPart) GetInputAudio() OptChatCompletionRequestMessageContentPartInputAudio {
+	return s.InputAudio
+}
+
+// SetType sets the value of Type.
+func (s *ChatCompletionRequestMessageContentPart) SetType(val ChatCompletionRequestMessageContentPartType) {
+	s.Type = val
+}
+
+// SetText sets the value of Text.
+func (s *ChatCompletionRequestMessageContentPart) SetText(val OptString) {
+	s.Text = val
+}
+
+// SetImageURL sets the value of ImageURL.
+func (s *ChatCompletionRequestMessageContentPart) SetImageURL(val OptChatCompletionRequestMessageContentPartImageURL) {
+	s.ImageURL = val
+}
+
+// SetInputAudio sets the value of InputAudio.
+func (s *ChatCompletionRequestMessageContentPart) SetInputAudio(val OptChatCompletionRequestMessageContentPartInputAudio) {
+	s.InputAudio = val
+}
+
+type ChatCompletionRequestMessageContentPartImageURL struct {
+	URL    string    `json:"url"`
+	Detail OptString `json:"detail"`
+}
+
+// GetURL returns the value of URL.
+func (s *ChatCompletionRequestMessageContentPartImageURL) GetURL() string {
+	return s.URL
+}
+
+// GetDetail returns the value of Detail.
+func (s *ChatCompletionRequestMessageContentPartImageURL) GetDetail() OptString {
+	return s.Detail
+}
+
+// SetURL sets the value of URL.
+func (s *ChatCompletionRequestMessageContentPartImageURL) SetURL(val string) {
+	s.URL = val
+}
+
+// SetDetail sets the value of Detail.
+func (s *ChatCompletionRequestMessageContentPartImageURL) SetDetail(val OptString) {
+	s.Detail = val
+}
+
+type ChatCompletionRequestMessageContentPartInputAudio struct {
+	// Base64 encoded audio data.
+	Data   string                                                  `json:"data"`
+	Format ChatCompletionRequestMessageContentPartInputAudioFormat `json:"format"`
+}
+
+// GetData returns the value of Data.
+func (s *ChatCompletionRequestMessageContentPartInputAudio) GetData() string {
+	return s.Data
+}
+
+// GetFormat returns the value of Format.
+func (s *ChatCompletionRequestMessageContentPartInputAudio) GetFormat() ChatCompletionRequestMessageContentPartInputAudioFormat {
+	return s.Format
+}
+
+// SetData sets the value of Data.
+func (s *ChatCompletionRequestMessageContentPartInputAudio) SetData(val string) {
+	s.Data = val
+}
+
+// SetFormat sets the value of Format.
+func (s *ChatCompletionRequestMessageContentPartInputAudio) SetFormat(val ChatCompletionRequestMessageContentPartInputAudioFormat) {
+	s.Format = val
+}
+
+type ChatCompletionRequestMessageContentPartInputAudioFormat string
+
+const (
+	ChatCompletionRequestMessageContentPartInputAudioFormatWav ChatCompletionRequestMessageContentPartInputAudioFormat = "wav"
+	ChatCompletionRequestMessageContentPartInputAudioFormatMp3 ChatCompletionRequestMessageContentPartInputAudioFormat = "mp3"
+)
+
+// AllValues returns all ChatCompletionRequestMessageContentPartInputAudioFormat values.
+func (ChatCompletionRequestMessageContentPartInputAudioFormat) AllValues() []ChatCompletionRequestMessageContentPartInputAudioFormat {
+	return []ChatCompletionRequestMessageContentPartInputAudioFormat{
+		ChatCompletionRequestMessageContentPartInputAudioFormatWav,
+		ChatCompletionRequestMessageContentPartInputAudioFormatMp3,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ChatCompletionRequestMessageContentPartInputAudioFormat) MarshalText() ([]byte, error) {
+	switch s {
+	case ChatCompletionRequestMessageContentPartInputAudioFormatWav:
+		return []byte(s), nil
+	case ChatCompletionRequestMessageContentPartInputAudioFormatMp3:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ChatCompletionRequestMessageContentPartInputAudioFormat) UnmarshalText(data []byte) error {
+	switch ChatCompletionRequestMessageContentPartInputAudioFormat(data) {
+	case ChatCompletionRequestMessageContentPartInputAudioFormatWav:
+		*s = ChatCompletionRequestMessageContentPartInputAudioFormatWav
+		return nil
+	case ChatCompletionRequestMessageContentPartInputAudioFormatMp3:
+		*s = ChatCompletionRequestMessageContentPartInputAudioFormatMp3
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type ChatCompletionRequestMessageContentPartType string
+
+const (
+	ChatCompletionRequestMessageContentPartTypeText       ChatCompletionRequestMessageContentPartType = "text"
+	ChatCompletionRequestMessageContentPartTypeImageURL   ChatCompletionRequestMessageContentPartType = "image_url"
+	ChatCompletionRequestMessageContentPartTypeInputAudio ChatCompletionRequestMessageContentPartType = "input_audio"
+)
+
+// AllValues returns all ChatCompletionRequestMessageContentPartType values.
+func (ChatCompletionRequestMessageContentPartType) AllValues() []ChatCompletionRequestMessageContentPartType {
+	return []ChatCompletionRequestMessageContentPartType{
+		ChatCompletionRequestMessageContentPartTypeText,
+		ChatCompletionRequestMessageContentPartTypeImageURL,
+		ChatCompletionRequestMessageContentPartTypeInputAudio,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ChatCompletionRequestMessageContentPartType) MarshalText() ([]byte, error) {
+	switch s {
+	case ChatCompletionRequestMessageContentPartTypeText:
+		return []byte(s), nil
+	case ChatCompletionRequestMessageContentPartTypeImageURL:
+		return []byte(s), nil
+	case ChatCompletionRequestMessageContentPartTypeInputAudio:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ChatCompletionRequestMessageContentPartType) UnmarshalText(data []byte) error {
+	switch ChatCompletionRequestMessageContentPartType(data) {
+	case ChatCompletionRequestMessageContentPartTypeText:
+		*s = ChatCompletionRequestMessageContentPartTypeText
+		return nil
+	case ChatCompletionRequestMessageContentPartTypeImageURL:
+		*s = ChatCompletionRequestMessageContentPartTypeImageURL
+		return nil
+	case ChatCompletionRequestMessageContentPartTypeInputAudio:
+		*s = ChatCompletionRequestMessageContentPartTypeInputAudio
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
 // Ref: #/components/schemas/ChatCompletionRequestMessageFunctionCall
 type ChatCompletionRequestMessageFunctionCall struct {
 	Name      string `json:"name"`
@@ -504,6 +895,7 @@ type ChatCompletionResponseMessage struct {
 	Refusal      OptNilString                                 `json:"refusal"`
 	ToolCalls    []ChatCompletionMessageToolCall              `json:"tool_calls"`
 	FunctionCall OptChatCompletionResponseMessageFunctionCall `json:"function_call"`
+	Annotations  []ChatCompletionMessageAnnotation            `json:"annotations"`
 }
 
 // GetRole returns the value of Role.
@@ -531,6 +923,11 @@ func (s *ChatCompletionResponseMessage) GetFunctionCall() OptChatCompletionRespo
 	return s.FunctionCall
 }
 
+// GetAnnotations returns the value of Annotations.
+func (s *ChatCompletionResponseMessage) GetAnnotations() []ChatCompletionMessageAnnotation {
+	return s.Annotations
+}
+
 // SetRole sets the value of Role.
 func (s *ChatCompletionResponseMessage) SetRole(val ChatCompletionResponseMessageRole) {
 	s.Role = val
@@ -556,6 +953,11 @@ func (s *ChatCompletionResponseMessage) SetFunctionCall(val OptChatCompletionRes
 	s.FunctionCall = val
 }
 
+// SetAnnotations sets the value of Annotations.
+func (s *ChatCompletionResponseMessage) SetAnnotations(val []ChatCompletionMessageAnnotation) {
+	s.Annotations = val
+}
+
 // Ref: #/components/schemas/ChatCompletionResponseMessageFunctionCall
 type ChatCompletionResponseMessageFunctionCall struct {
 	Name      string `json:"name"`
@@ -1083,11 +1485,18 @@ type CreateChatCompletionRequest struct {
 	LogitBias           OptCreateChatCompletionRequestLogitBias `json:"logit_bias"`
 	// A unique identifier representing your end-user.
 	User OptString `json:"user"`
+	// A stable identifier of the end-user, used to detect and block abuse.
+	SafetyIdentifier OptString `json:"safety_identifier"`
 	// Seed for deterministic sampling.
 	Seed OptInt `json:"seed"`
 	// A list of tools the model may call.
-	Tools          []ChatCompletionTool            `json:"tools"`
-	ResponseFormat OptChatCompletionResponseFormat `json:"response_format"`
+	Tools []ChatCompletionTool `json:"tools"`
+	// Controls which (if any) tool is called by the model.
+	ToolChoice OptCreateChatCompletionRequestToolChoice `json:"tool_choice"`
+	// Whether the model may call several tools in one response.
+	ParallelToolCalls OptBool                         `json:"parallel_tool_calls"`
+	ResponseFormat    OptChatCompletionResponseFormat `json:"response_format"`
+	WebSearchOptions  OptWebSearchOptions             `json:"web_search_options"`
 }
 
 // GetModel returns the value of Model.
@@ -1155,6 +1564,11 @@ func (s *CreateChatCompletionRequest) GetUser() OptString {
 	return s.User
 }
 
+// GetSafetyIdentifier returns the value of SafetyIdentifier.
+func (s *CreateChatCompletionRequest) GetSafetyIdentifier() OptString {
+	return s.SafetyIdentifier
+}
+
 // GetSeed returns the value of Seed.
 func (s *CreateChatCompletionRequest) GetSeed() OptInt {
 	return s.Seed
@@ -1165,11 +1579,26 @@ func (s *CreateChatCompletionRequest) GetTools() []ChatCompletionTool {
 	return s.Tools
 }
 
+// GetToolChoice returns the value of ToolChoice.
+func (s *CreateChatCompletionRequest) GetToolChoice() OptCreateChatCompletionRequestToolChoice {
+	return s.ToolChoice
+}
+
+// GetParallelToolCalls returns the value of ParallelToolCalls.
+func (s *CreateChatCompletionRequest) GetParallelToolCalls() OptBool {
+	return s.ParallelToolCalls
+}
+
 // GetResponseFormat returns the value of ResponseFormat.
 func (s *CreateChatCompletionRequest) GetResponseFormat() OptChatCompletionResponseFormat {
 	return s.ResponseFormat
 }
 
+// GetWebSearchOptions returns the value of WebSearchOptions.
+func (s *CreateChatCompletionRequest) GetWebSearchOptions() OptWebSearchOptions {
+	return s.WebSearchOptions
+}
+
 // SetModel sets the value of Model.
 func (s *CreateChatCompletionRequest) SetModel(val string) {
 	s.Model = val
@@ -1235,6 +1664,11 @@ func (s *CreateChatCompletionRequest) SetUser(val OptString) {
 	s.User = val
 }
 
+// SetSafetyIdentifier sets the value of SafetyIdentifier.
+func (s *CreateChatCompletionRequest) SetSafetyIdentifier(val OptString) {
+	s.SafetyIdentifier = val
+}
+
 // SetSeed sets the value of Seed.
 func (s *CreateChatCompletionRequest) SetSeed(val OptInt) {
 	s.Seed = val
@@ -1245,11 +1679,26 @@ func (s *CreateChatCompletionRequest) SetTools(val []ChatCompletionTool) {
 	s.Tools = val
 }
 
+// SetToolChoice sets the value of ToolChoice.
+func (s *CreateChatCompletionRequest) SetToolChoice(val OptCreateChatCompletionRequestToolChoice) {
+	s.ToolChoice = val
+}
+
+// SetParallelToolCalls sets the value of ParallelToolCalls.
+func (s *CreateChatCompletionRequest) SetParallelToolCalls(val OptBool) {
+	s.ParallelToolCalls = val
+}
+
 // SetResponseFormat sets the value of ResponseFormat.
 func (s *CreateChatCompletionRequest) SetResponseFormat(val OptChatCompletionResponseFormat) {
 	s.ResponseFormat = val
 }
 
+// SetWebSearchOptions sets the value of WebSearchOptions.
+func (s *CreateChatCompletionRequest) SetWebSearchOptions(val OptWebSearchOptions) {
+	s.WebSearchOptions = val
+}
+
 type CreateChatCompletionRequestLogitBias map[string]int
 
 func (s *CreateChatCompletionRequestLogitBias) init() CreateChatCompletionRequestLogitBias {
@@ -1264,7 +1713,8 @@ func (s *CreateChatCompletionRequestLogitBias) init() CreateChatCompletionReques
 // Stop sequences.
 // CreateChatCompletionRequestStop represents sum type.
 type CreateChatCompletionRequestStop struct {
-	Type        CreateChatCompletionRequestStopType // switch on this field
+	// Type selects the active sum variant, switch on this field.
+	Type        CreateChatCompletionRequestStopType
 	String      string
 	StringArray []string
 }
@@ -1330,22 +1780,140 @@ func NewStringArrayCreateChatCompletionRequestStop(v []string) CreateChatComplet
 	return s
 }
 
-// Ref: #/components/schemas/CreateChatCompletionResponse
-type CreateChatCompletionResponse struct {
-	ID                string                             `json:"id"`
-	Object            CreateChatCompletionResponseObject `json:"object"`
-	Created           int64                              `json:"created"`
-	Model             string                             `json:"model"`
-	Choices           []ChatCompletionChoice             `json:"choices"`
-	Usage             OptCompletionUsage                 `json:"usage"`
-	SystemFingerprint OptString                          `json:"system_fingerprint"`
-	ServiceTier       OptString                          `json:"service_tier"`
+// Controls which (if any) tool is called by the model.
+// CreateChatCompletionRequestToolChoice represents sum type.
+type CreateChatCompletionRequestToolChoice struct {
+	// Type selects the active sum variant, switch on this field.
+	Type                                   CreateChatCompletionRequestToolChoiceType
+	CreateChatCompletionRequestToolChoice0 CreateChatCompletionRequestToolChoice0
+	ChatCompletionNamedToolChoice          ChatCompletionNamedToolChoice
 }
 
-// GetID returns the value of ID.
-func (s *CreateChatCompletionResponse) GetID() string {
-	return s.ID
-}
+// CreateChatCompletionRequestToolChoiceType is oneOf type of CreateChatCompletionRequestToolChoice.
+type CreateChatCompletionRequestToolChoiceType string
+
+// Possible values for CreateChatCompletionRequestToolChoiceType.
+const (
+	CreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice CreateChatCompletionRequestToolChoiceType = "CreateChatCompletionRequestToolChoice0"
+	ChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice          CreateChatCompletionRequestToolChoiceType = "ChatCompletionNamedToolChoice"
+)
+
+// IsCreateChatCompletionRequestToolChoice0 reports whether CreateChatCompletionRequestToolChoice is CreateChatCompletionRequestToolChoice0.
+func (s CreateChatCompletionRequestToolChoice) IsCreateChatCompletionRequestToolChoice0() bool {
+	return s.Type == CreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice
+}
+
+// IsChatCompletionNamedToolChoice reports whether CreateChatCompletionRequestToolChoice is ChatCompletionNamedToolChoice.
+func (s CreateChatCompletionRequestToolChoice) IsChatCompletionNamedToolChoice() bool {
+	return s.Type == ChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice
+}
+
+// SetCreateChatCompletionRequestToolChoice0 sets CreateChatCompletionRequestToolChoice to CreateChatCompletionRequestToolChoice0.
+func (s *CreateChatCompletionRequestToolChoice) SetCreateChatCompletionRequestToolChoice0(v CreateChatCompletionRequestToolChoice0) {
+	s.Type = CreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice
+	s.CreateChatCompletionRequestToolChoice0 = v
+}
+
+// GetCreateChatCompletionRequestToolChoice0 returns CreateChatCompletionRequestToolChoice0 and true boolean if CreateChatCompletionRequestToolChoice is CreateChatCompletionRequestToolChoice0.
+func (s CreateChatCompletionRequestToolChoice) GetCreateChatCompletionRequestToolChoice0() (v CreateChatCompletionRequestToolChoice0, ok bool) {
+	if !s.IsCreateChatCompletionRequestToolChoice0() {
+		return v, false
+	}
+	return s.CreateChatCompletionRequestToolChoice0, true
+}
+
+// NewCreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice returns new CreateChatCompletionRequestToolChoice from CreateChatCompletionRequestToolChoice0.
+func NewCreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice(v CreateChatCompletionRequestToolChoice0) CreateChatCompletionRequestToolChoice {
+	var s CreateChatCompletionRequestToolChoice
+	s.SetCreateChatCompletionRequestToolChoice0(v)
+	return s
+}
+
+// SetChatCompletionNamedToolChoice sets CreateChatCompletionRequestToolChoice to ChatCompletionNamedToolChoice.
+func (s *CreateChatCompletionRequestToolChoice) SetChatCompletionNamedToolChoice(v ChatCompletionNamedToolChoice) {
+	s.Type = ChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice
+	s.ChatCompletionNamedToolChoice = v
+}
+
+// GetChatCompletionNamedToolChoice returns ChatCompletionNamedToolChoice and true boolean if CreateChatCompletionRequestToolChoice is ChatCompletionNamedToolChoice.
+func (s CreateChatCompletionRequestToolChoice) GetChatCompletionNamedToolChoice() (v ChatCompletionNamedToolChoice, ok bool) {
+	if !s.IsChatCompletionNamedToolChoice() {
+		return v, false
+	}
+	return s.ChatCompletionNamedToolChoice, true
+}
+
+// NewChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice returns new CreateChatCompletionRequestToolChoice from ChatCompletionNamedToolChoice.
+func NewChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice(v ChatCompletionNamedToolChoice) CreateChatCompletionRequestToolChoice {
+	var s CreateChatCompletionRequestToolChoice
+	s.SetChatCompletionNamedToolChoice(v)
+	return s
+}
+
+type CreateChatCompletionRequestToolChoice0 string
+
+const (
+	CreateChatCompletionRequestToolChoice0None     CreateChatCompletionRequestToolChoice0 = "none"
+	CreateChatCompletionRequestToolChoice0Auto     CreateChatCompletionRequestToolChoice0 = "auto"
+	CreateChatCompletionRequestToolChoice0Required CreateChatCompletionRequestToolChoice0 = "required"
+)
+
+// AllValues returns all CreateChatCompletionRequestToolChoice0 values.
+func (CreateChatCompletionRequestToolChoice0) AllValues() []CreateChatCompletionRequestToolChoice0 {
+	return []CreateChatCompletionRequestToolChoice0{
+		CreateChatCompletionRequestToolChoice0None,
+		CreateChatCompletionRequestToolChoice0Auto,
+		CreateChatCompletionRequestToolChoice0Required,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s CreateChatCompletionRequestToolChoice0) MarshalText() ([]byte, error) {
+	switch s {
+	case CreateChatCompletionRequestToolChoice0None:
+		return []byte(s), nil
+	case CreateChatCompletionRequestToolChoice0Auto:
+		return []byte(s), nil
+	case CreateChatCompletionRequestToolChoice0Required:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CreateChatCompletionRequestToolChoice0) UnmarshalText(data []byte) error {
+	switch CreateChatCompletionRequestToolChoice0(data) {
+	case CreateChatCompletionRequestToolChoice0None:
+		*s = CreateChatCompletionRequestToolChoice0None
+		return nil
+	case CreateChatCompletionRequestToolChoice0Auto:
+		*s = CreateChatCompletionRequestToolChoice0Auto
+		return nil
+	case CreateChatCompletionRequestToolChoice0Required:
+		*s = CreateChatCompletionRequestToolChoice0Required
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/CreateChatCompletionResponse
+type CreateChatCompletionResponse struct {
+	ID                string                             `json:"id"`
+	Object            CreateChatCompletionResponseObject `json:"object"`
+	Created           int64                              `json:"created"`
+	Model             string                             `json:"model"`
+	Choices           []ChatCompletionChoice             `json:"choices"`
+	Usage             OptCompletionUsage                 `json:"usage"`
+	SystemFingerprint OptString                          `json:"system_fingerprint"`
+	ServiceTier       OptString                          `json:"service_tier"`
+}
+
+// GetID returns the value of ID.
+func (s *CreateChatCompletionResponse) GetID() string {
+	return s.ID
+}
 
 // GetObject returns the value of Object.
 func (s *CreateChatCompletionResponse) GetObject() CreateChatCompletionResponseObject {
@@ -1660,9 +2228,10 @@ func (s *CreateCompletionRequestLogitBias) init() CreateCompletionRequestLogitBi
 
 // CreateCompletionRequestPrompt represents sum type.
 type CreateCompletionRequestPrompt struct {
-	Type        CreateCompletionRequestPromptType // switch on this field
-	String      string
-	StringArray []string
+	// Type selects the active sum variant, switch on this field.
+	Type     CreateCompletionRequestPromptType
+	String   string
+	AnyArray []jx.Raw
 }
 
 // CreateCompletionRequestPromptType is oneOf type of CreateCompletionRequestPrompt.
@@ -1670,8 +2239,8 @@ type CreateCompletionRequestPromptType string
 
 // Possible values for CreateCompletionRequestPromptType.
 const (
-	StringCreateCompletionRequestPrompt      CreateCompletionRequestPromptType = "string"
-	StringArrayCreateCompletionRequestPrompt CreateCompletionRequestPromptType = "[]string"
+	StringCreateCompletionRequestPrompt   CreateCompletionRequestPromptType = "string"
+	AnyArrayCreateCompletionRequestPrompt CreateCompletionRequestPromptType = "[]jx.Raw"
 )
 
 // IsString reports whether CreateCompletionRequestPrompt is string.
@@ -1679,9 +2248,9 @@ func (s CreateCompletionRequestPrompt) IsString() bool {
 	return s.Type == StringCreateCompletionRequestPrompt
 }
 
-// IsStringArray reports whether CreateCompletionRequestPrompt is []string.
-func (s CreateCompletionRequestPrompt) IsStringArray() bool {
-	return s.Type == StringArrayCreateCompletionRequestPrompt
+// IsAnyArray reports whether CreateCompletionRequestPrompt is []jx.Raw.
+func (s CreateCompletionRequestPrompt) IsAnyArray() bool {
+	return s.Type == AnyArrayCreateCompletionRequestPrompt
 }
 
 // SetString sets CreateCompletionRequestPrompt to string.
@@ -1705,30 +2274,31 @@ func NewStringCreateCompletionRequestPrompt(v string) CreateCompletionRequestPro
 	return s
 }
 
-// SetStringArray sets CreateCompletionRequestPrompt to []string.
-func (s *CreateCompletionRequestPrompt) SetStringArray(v []string) {
-	s.Type = StringArrayCreateCompletionRequestPrompt
-	s.StringArray = v
+// SetAnyArray sets CreateCompletionRequestPrompt to []jx.Raw.
+func (s *CreateCompletionRequestPrompt) SetAnyArray(v []jx.Raw) {
+	s.Type = AnyArrayCreateCompletionRequestPrompt
+	s.AnyArray = v
 }
 
-// GetStringArray returns []string and true boolean if CreateCompletionRequestPrompt is []string.
-func (s CreateCompletionRequestPrompt) GetStringArray() (v []string, ok bool) {
-	if !s.IsStringArray() {
+// GetAnyArray returns []jx.Raw and true boolean if CreateCompletionRequestPrompt is []jx.Raw.
+func (s CreateCompletionRequestPrompt) GetAnyArray() (v []jx.Raw, ok bool) {
+	if !s.IsAnyArray() {
 		return v, false
 	}
-	return s.StringArray, true
+	return s.AnyArray, true
 }
 
-// NewStringArrayCreateCompletionRequestPrompt returns new CreateCompletionRequestPrompt from []string.
-func NewStringArrayCreateCompletionRequestPrompt(v []string) CreateCompletionRequestPrompt {
+// NewAnyArrayCreateCompletionRequestPrompt returns new CreateCompletionRequestPrompt from []jx.Raw.
+func NewAnyArrayCreateCompletionRequestPrompt(v []jx.Raw) CreateCompletionRequestPrompt {
 	var s CreateCompletionRequestPrompt
-	s.SetStringArray(v)
+	s.SetAnyArray(v)
 	return s
 }
 
 // CreateCompletionRequestStop represents sum type.
 type CreateCompletionRequestStop struct {
-	Type        CreateCompletionRequestStopType // switch on this field
+	// Type selects the active sum variant, switch on this field.
+	Type        CreateCompletionRequestStopType
 	String      string
 	StringArray []string
 }
@@ -2004,7 +2574,8 @@ func (s *CreateEmbeddingRequestEncodingFormat) UnmarshalText(data []byte) error
 
 // CreateEmbeddingRequestInput represents sum type.
 type CreateEmbeddingRequestInput struct {
-	Type        CreateEmbeddingRequestInputType // switch on this field
+	// Type selects the active sum variant, switch on this field.
+	Type        CreateEmbeddingRequestInputType
 	String      string
 	StringArray []string
 }
@@ -2152,152 +2723,173 @@ func (s *CreateEmbeddingResponseObject) UnmarshalText(data []byte) error {
 	}
 }
 
-// Ref: #/components/schemas/CreateResponseRequest
-type CreateResponseRequest struct {
-	Model string                `json:"model"`
-	Input string                `json:"input"`
-	Tools []ResponseTool        `json:"tools"`
-	Text  OptResponseTextConfig `json:"text"`
+// Ref: #/components/schemas/CreateImageRequest
+type CreateImageRequest struct {
+	// A text description of the desired image(s).
+	Prompt string    `json:"prompt"`
+	Model  OptString `json:"model"`
+	N      OptInt    `json:"n"`
+	// The size of the generated images, formatted as WIDTHxHEIGHT.
+	Size           OptCreateImageRequestSize           `json:"size"`
+	ResponseFormat OptCreateImageRequestResponseFormat `json:"response_format"`
+	// Standard or hd for DALL·E models; low, medium, high, or auto for GPT image models.
+	Quality OptString `json:"quality"`
+	Style   OptString `json:"style"`
+	// Background transparency (GPT image models only).
+	Background OptCreateImageRequestBackground `json:"background"`
+	// Output image format (GPT image models only).
+	OutputFormat      OptCreateImageRequestOutputFormat `json:"output_format"`
+	OutputCompression OptInt                            `json:"output_compression"`
+	Moderation        OptString                         `json:"moderation"`
+	User              OptString                         `json:"user"`
+}
+
+// GetPrompt returns the value of Prompt.
+func (s *CreateImageRequest) GetPrompt() string {
+	return s.Prompt
 }
 
 // GetModel returns the value of Model.
-func (s *CreateResponseRequest) GetModel() string {
+func (s *CreateImageRequest) GetModel() OptString {
 	return s.Model
 }
 
-// GetInput returns the value of Input.
-func (s *CreateResponseRequest) GetInput() string {
-	return s.Input
+// GetN returns the value of N.
+func (s *CreateImageRequest) GetN() OptInt {
+	return s.N
 }
 
-// GetTools returns the value of Tools.
-func (s *CreateResponseRequest) GetTools() []ResponseTool {
-	return s.Tools
+// GetSize returns the value of Size.
+func (s *CreateImageRequest) GetSize() OptCreateImageRequestSize {
+	return s.Size
 }
 
-// GetText returns the value of Text.
-func (s *CreateResponseRequest) GetText() OptResponseTextConfig {
-	return s.Text
+// GetResponseFormat returns the value of ResponseFormat.
+func (s *CreateImageRequest) GetResponseFormat() OptCreateImageRequestResponseFormat {
+	return s.ResponseFormat
 }
 
-// SetModel sets the value of Model.
-func (s *CreateResponseRequest) SetModel(val string) {
-	s.Model = val
+// GetQuality returns the value of Quality.
+func (s *CreateImageRequest) GetQuality() OptString {
+	return s.Quality
 }
 
-// SetInput sets the value of Input.
-func (s *CreateResponseRequest) SetInput(val string) {
-	s.Input = val
+// GetStyle returns the value of Style.
+func (s *CreateImageRequest) GetStyle() OptString {
+	return s.Style
 }
 
-// SetTools sets the value of Tools.
-func (s *CreateResponseRequest) SetTools(val []ResponseTool) {
-	s.Tools = val
+// GetBackground returns the value of Background.
+func (s *CreateImageRequest) GetBackground() OptCreateImageRequestBackground {
+	return s.Background
 }
 
-// SetText sets the value of Text.
-func (s *CreateResponseRequest) SetText(val OptResponseTextConfig) {
-	s.Text = val
+// GetOutputFormat returns the value of OutputFormat.
+func (s *CreateImageRequest) GetOutputFormat() OptCreateImageRequestOutputFormat {
+	return s.OutputFormat
 }
 
-// Ref: #/components/schemas/CreateResponseResponse
-type CreateResponseResponse struct {
-	ID        string                       `json:"id"`
-	Object    CreateResponseResponseObject `json:"object"`
-	CreatedAt OptInt64                     `json:"created_at"`
-	Status    CreateResponseResponseStatus `json:"status"`
-	Model     string                       `json:"model"`
-	Output    []ResponseOutputItem         `json:"output"`
-	Usage     ResponseUsage                `json:"usage"`
+// GetOutputCompression returns the value of OutputCompression.
+func (s *CreateImageRequest) GetOutputCompression() OptInt {
+	return s.OutputCompression
 }
 
-// GetID returns the value of ID.
-func (s *CreateResponseResponse) GetID() string {
-	return s.ID
+// GetModeration returns the value of Moderation.
+func (s *CreateImageRequest) GetModeration() OptString {
+	return s.Moderation
 }
 
-// GetObject returns the value of Object.
-func (s *CreateResponseResponse) GetObject() CreateResponseResponseObject {
-	return s.Object
+// GetUser returns the value of User.
+func (s *CreateImageRequest) GetUser() OptString {
+	return s.User
 }
 
-// GetCreatedAt returns the value of CreatedAt.
-func (s *CreateResponseResponse) GetCreatedAt() OptInt64 {
-	return s.CreatedAt
+// SetPrompt sets the value of Prompt.
+func (s *CreateImageRequest) SetPrompt(val string) {
+	s.Prompt = val
 }
 
-// GetStatus returns the value of Status.
-func (s *CreateResponseResponse) GetStatus() CreateResponseResponseStatus {
-	return s.Status
+// SetModel sets the value of Model.
+func (s *CreateImageRequest) SetModel(val OptString) {
+	s.Model = val
 }
 
-// GetModel returns the value of Model.
-func (s *CreateResponseResponse) GetModel() string {
-	return s.Model
+// SetN sets the value of N.
+func (s *CreateImageRequest) SetN(val OptInt) {
+	s.N = val
 }
 
-// GetOutput returns the value of Output.
-func (s *CreateResponseResponse) GetOutput() []ResponseOutputItem {
-	return s.Output
+// SetSize sets the value of Size.
+func (s *CreateImageRequest) SetSize(val OptCreateImageRequestSize) {
+	s.Size = val
 }
 
-// GetUsage returns the value of Usage.
-func (s *CreateResponseResponse) GetUsage() ResponseUsage {
-	return s.Usage
+// SetResponseFormat sets the value of ResponseFormat.
+func (s *CreateImageRequest) SetResponseFormat(val OptCreateImageRequestResponseFormat) {
+	s.ResponseFormat = val
 }
 
-// SetID sets the value of ID.
-func (s *CreateResponseResponse) SetID(val string) {
-	s.ID = val
+// SetQuality sets the value of Quality.
+func (s *CreateImageRequest) SetQuality(val OptString) {
+	s.Quality = val
 }
 
-// SetObject sets the value of Object.
-func (s *CreateResponseResponse) SetObject(val CreateResponseResponseObject) {
-	s.Object = val
+// SetStyle sets the value of Style.
+func (s *CreateImageRequest) SetStyle(val OptString) {
+	s.Style = val
 }
 
-// SetCreatedAt sets the value of CreatedAt.
-func (s *CreateResponseResponse) SetCreatedAt(val OptInt64) {
-	s.CreatedAt = val
+// SetBackground sets the value of Background.
+func (s *CreateImageRequest) SetBackground(val OptCreateImageRequestBackground) {
+	s.Background = val
 }
 
-// SetStatus sets the value of Status.
-func (s *CreateResponseResponse) SetStatus(val CreateResponseResponseStatus) {
-	s.Status = val
+// SetOutputFormat sets the value of OutputFormat.
+func (s *CreateImageRequest) SetOutputFormat(val OptCreateImageRequestOutputFormat) {
+	s.OutputFormat = val
 }
 
-// SetModel sets the value of Model.
-func (s *CreateResponseResponse) SetModel(val string) {
-	s.Model = val
+// SetOutputCompression sets the value of OutputCompression.
+func (s *CreateImageRequest) SetOutputCompression(val OptInt) {
+	s.OutputCompression = val
 }
 
-// SetOutput sets the value of Output.
-func (s *CreateResponseResponse) SetOutput(val []ResponseOutputItem) {
-	s.Output = val
+// SetModeration sets the value of Moderation.
+func (s *CreateImageRequest) SetModeration(val OptString) {
+	s.Moderation = val
 }
 
-// SetUsage sets the value of Usage.
-func (s *CreateResponseResponse) SetUsage(val ResponseUsage) {
-	s.Usage = val
+// SetUser sets the value of User.
+func (s *CreateImageRequest) SetUser(val OptString) {
+	s.User = val
 }
 
-type CreateResponseResponseObject string
+// Background transparency (GPT image models only).
+type CreateImageRequestBackground string
 
 const (
-	CreateResponseResponseObjectResponse CreateResponseResponseObject = "response"
+	CreateImageRequestBackgroundTransparent CreateImageRequestBackground = "transparent"
+	CreateImageRequestBackgroundOpaque      CreateImageRequestBackground = "opaque"
+	CreateImageRequestBackgroundAuto        CreateImageRequestBackground = "auto"
 )
 
-// AllValues returns all CreateResponseResponseObject values.
-func (CreateResponseResponseObject) AllValues() []CreateResponseResponseObject {
-	return []CreateResponseResponseObject{
-		CreateResponseResponseObjectResponse,
+// AllValues returns all CreateImageRequestBackground values.
+func (CreateImageRequestBackground) AllValues() []CreateImageRequestBackground {
+	return []CreateImageRequestBackground{
+		CreateImageRequestBackgroundTransparent,
+		CreateImageRequestBackgroundOpaque,
+		CreateImageRequestBackgroundAuto,
 	}
 }
 
 // MarshalText implements encoding.TextMarshaler.
-func (s CreateResponseResponseObject) MarshalText() ([]byte, error) {
+func (s CreateImageRequestBackground) MarshalText() ([]byte, error) {
 	switch s {
-	case CreateResponseResponseObjectResponse:
+	case CreateImageRequestBackgroundTransparent:
+		return []byte(s), nil
+	case CreateImageRequestBackgroundOpaque:
+		return []byte(s), nil
+	case CreateImageRequestBackgroundAuto:
 		return []byte(s), nil
 	default:
 		return nil, errors.Errorf("invalid value: %q", s)
@@ -2305,49 +2897,48 @@ func (s CreateResponseResponseObject) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
-func (s *CreateResponseResponseObject) UnmarshalText(data []byte) error {
-	switch CreateResponseResponseObject(data) {
-	case CreateResponseResponseObjectResponse:
-		*s = CreateResponseResponseObjectResponse
+func (s *CreateImageRequestBackground) UnmarshalText(data []byte) error {
+	switch CreateImageRequestBackground(data) {
+	case CreateImageRequestBackgroundTransparent:
+		*s = CreateImageRequestBackgroundTransparent
+		return nil
+	case CreateImageRequestBackgroundOpaque:
+		*s = CreateImageRequestBackgroundOpaque
+		return nil
+	case CreateImageRequestBackgroundAuto:
+		*s = CreateImageRequestBackgroundAuto
 		return nil
 	default:
 		return errors.Errorf("invalid value: %q", data)
 	}
 }
 
-type CreateResponseResponseStatus string
+// Output image format (GPT image models only).
+type CreateImageRequestOutputFormat string
 
 const (
-	CreateResponseResponseStatusCompleted  CreateResponseResponseStatus = "completed"
-	CreateResponseResponseStatusFailed     CreateResponseResponseStatus = "failed"
-	CreateResponseResponseStatusCancelled  CreateResponseResponseStatus = "cancelled"
-	CreateResponseResponseStatusQueued     CreateResponseResponseStatus = "queued"
-	CreateResponseResponseStatusInProgress CreateResponseResponseStatus = "in_progress"
+	CreateImageRequestOutputFormatPNG  CreateImageRequestOutputFormat = "png"
+	CreateImageRequestOutputFormatJpeg CreateImageRequestOutputFormat = "jpeg"
+	CreateImageRequestOutputFormatWEBP CreateImageRequestOutputFormat = "webp"
 )
 
-// AllValues returns all CreateResponseResponseStatus values.
-func (CreateResponseResponseStatus) AllValues() []CreateResponseResponseStatus {
-	return []CreateResponseResponseStatus{
-		CreateResponseResponseStatusCompleted,
-		CreateResponseResponseStatusFailed,
-		CreateResponseResponseStatusCancelled,
-		CreateResponseResponseStatusQueued,
-		CreateResponseResponseStatusInProgress,
+// AllValues returns all CreateImageRequestOutputFormat values.
+func (CreateImageRequestOutputFormat) AllValues() []CreateImageRequestOutputFormat {
+	return []CreateImageRequestOutputFormat{
+		CreateImageRequestOutputFormatPNG,
+		CreateImageRequestOutputFormatJpeg,
+		CreateImageRequestOutputFormatWEBP,
 	}
 }
 
 // MarshalText implements encoding.TextMarshaler.
-func (s CreateResponseResponseStatus) MarshalText() ([]byte, error) {
+func (s CreateImageRequestOutputFormat) MarshalText() ([]byte, error) {
 	switch s {
-	case CreateResponseResponseStatusCompleted:
-		return []byte(s), nil
-	case CreateResponseResponseStatusFailed:
-		return []byte(s), nil
-	case CreateResponseResponseStatusCancelled:
+	case CreateImageRequestOutputFormatPNG:
 		return []byte(s), nil
-	case CreateResponseResponseStatusQueued:
+	case CreateImageRequestOutputFormatJpeg:
 		return []byte(s), nil
-	case CreateResponseResponseStatusInProgress:
+	case CreateImageRequestOutputFormatWEBP:
 		return []byte(s), nil
 	default:
 		return nil, errors.Errorf("invalid value: %q", s)
@@ -2355,53 +2946,445 @@ func (s CreateResponseResponseStatus) MarshalText() ([]byte, error) {
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
-func (s *CreateResponseResponseStatus) UnmarshalText(data []byte) error {
-	switch CreateResponseResponseStatus(data) {
-	case CreateResponseResponseStatusCompleted:
-		*s = CreateResponseResponseStatusCompleted
-		return nil
-	case CreateResponseResponseStatusFailed:
-		*s = CreateResponseResponseStatusFailed
-		return nil
-	case CreateResponseResponseStatusCancelled:
-		*s = CreateResponseResponseStatusCancelled
+func (s *CreateImageRequestOutputFormat) UnmarshalText(data []byte) error {
+	switch CreateImageRequestOutputFormat(data) {
+	case CreateImageRequestOutputFormatPNG:
+		*s = CreateImageRequestOutputFormatPNG
 		return nil
-	case CreateResponseResponseStatusQueued:
-		*s = CreateResponseResponseStatusQueued
+	case CreateImageRequestOutputFormatJpeg:
+		*s = CreateImageRequestOutputFormatJpeg
 		return nil
-	case CreateResponseResponseStatusInProgress:
-		*s = CreateResponseResponseStatusInProgress
+	case CreateImageRequestOutputFormatWEBP:
+		*s = CreateImageRequestOutputFormatWEBP
 		return nil
 	default:
 		return errors.Errorf("invalid value: %q", data)
 	}
 }
 
-// Ref: #/components/schemas/Embedding
-type Embedding struct {
-	Index     int             `json:"index"`
-	Object    EmbeddingObject `json:"object"`
-	Embedding []float64       `json:"embedding"`
-}
-
-// GetIndex returns the value of Index.
-func (s *Embedding) GetIndex() int {
-	return s.Index
-}
+type CreateImageRequestResponseFormat string
 
-// GetObject returns the value of Object.
-func (s *Embedding) GetObject() EmbeddingObject {
-	return s.Object
-}
+const (
+	CreateImageRequestResponseFormatURL     CreateImageRequestResponseFormat = "url"
+	CreateImageRequestResponseFormatB64JSON CreateImageRequestResponseFormat = "b64_json"
+)
 
-// GetEmbedding returns the value of Embedding.
-func (s *Embedding) GetEmbedding() []float64 {
-	return s.Embedding
+// AllValues returns all CreateImageRequestResponseFormat values.
+func (CreateImageRequestResponseFormat) AllValues() []CreateImageRequestResponseFormat {
+	return []CreateImageRequestResponseFormat{
+		CreateImageRequestResponseFormatURL,
+		CreateImageRequestResponseFormatB64JSON,
+	}
 }
 
-// SetIndex sets the value of Index.
-func (s *Embedding) SetIndex(val int) {
-	s.Index = val
+// MarshalText implements encoding.TextMarshaler.
+func (s CreateImageRequestResponseFormat) MarshalText() ([]byte, error) {
+	switch s {
+	case CreateImageRequestResponseFormatURL:
+		return []byte(s), nil
+	case CreateImageRequestResponseFormatB64JSON:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CreateImageRequestResponseFormat) UnmarshalText(data []byte) error {
+	switch CreateImageRequestResponseFormat(data) {
+	case CreateImageRequestResponseFormatURL:
+		*s = CreateImageRequestResponseFormatURL
+		return nil
+	case CreateImageRequestResponseFormatB64JSON:
+		*s = CreateImageRequestResponseFormatB64JSON
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// The size of the generated images, formatted as WIDTHxHEIGHT.
+type CreateImageRequestSize string
+
+const (
+	CreateImageRequestSizeAuto      CreateImageRequestSize = "auto"
+	CreateImageRequestSize256x256   CreateImageRequestSize = "256x256"
+	CreateImageRequestSize512x512   CreateImageRequestSize = "512x512"
+	CreateImageRequestSize1024x1024 CreateImageRequestSize = "1024x1024"
+	CreateImageRequestSize1536x1024 CreateImageRequestSize = "1536x1024"
+	CreateImageRequestSize1024x1536 CreateImageRequestSize = "1024x1536"
+	CreateImageRequestSize1792x1024 CreateImageRequestSize = "1792x1024"
+	CreateImageRequestSize1024x1792 CreateImageRequestSize = "1024x1792"
+)
+
+// AllValues returns all CreateImageRequestSize values.
+func (CreateImageRequestSize) AllValues() []CreateImageRequestSize {
+	return []CreateImageRequestSize{
+		CreateImageRequestSizeAuto,
+		CreateImageRequestSize256x256,
+		CreateImageRequestSize512x512,
+		CreateImageRequestSize1024x1024,
+		CreateImageRequestSize1536x1024,
+		CreateImageRequestSize1024x1536,
+		CreateImageRequestSize1792x1024,
+		CreateImageRequestSize1024x1792,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s CreateImageRequestSize) MarshalText() ([]byte, error) {
+	switch s {
+	case CreateImageRequestSizeAuto:
+		return []byte(s), nil
+	case CreateImageRequestSize256x256:
+		return []byte(s), nil
+	case CreateImageRequestSize512x512:
+		return []byte(s), nil
+	case CreateImageRequestSize1024x1024:
+		return []byte(s), nil
+	case CreateImageRequestSize1536x1024:
+		return []byte(s), nil
+	case CreateImageRequestSize1024x1536:
+		return []byte(s), nil
+	case CreateImageRequestSize1792x1024:
+		return []byte(s), nil
+	case CreateImageRequestSize1024x1792:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CreateImageRequestSize) UnmarshalText(data []byte) error {
+	switch CreateImageRequestSize(data) {
+	case CreateImageRequestSizeAuto:
+		*s = CreateImageRequestSizeAuto
+		return nil
+	case CreateImageRequestSize256x256:
+		*s = CreateImageRequestSize256x256
+		return nil
+	case CreateImageRequestSize512x512:
+		*s = CreateImageRequestSize512x512
+		return nil
+	case CreateImageRequestSize1024x1024:
+		*s = CreateImageRequestSize1024x1024
+		return nil
+	case CreateImageRequestSize1536x1024:
+		*s = CreateImageRequestSize1536x1024
+		return nil
+	case CreateImageRequestSize1024x1536:
+		*s = CreateImageRequestSize1024x1536
+		return nil
+	case CreateImageRequestSize1792x1024:
+		*s = CreateImageRequestSize1792x1024
+		return nil
+	case CreateImageRequestSize1024x1792:
+		*s = CreateImageRequestSize1024x1792
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/CreateResponseRequest
+type CreateResponseRequest struct {
+	Model string                `json:"model"`
+	Input string                `json:"input"`
+	Tools []ResponseTool        `json:"tools"`
+	Text  OptResponseTextConfig `json:"text"`
+	// A unique identifier representing your end-user.
+	User OptString `json:"user"`
+	// A stable identifier of the end-user, used to detect and block abuse.
+	SafetyIdentifier OptString `json:"safety_identifier"`
+}
+
+// GetModel returns the value of Model.
+func (s *CreateResponseRequest) GetModel() string {
+	return s.Model
+}
+
+// GetInput returns the value of Input.
+func (s *CreateResponseRequest) GetInput() string {
+	return s.Input
+}
+
+// GetTools returns the value of Tools.
+func (s *CreateResponseRequest) GetTools() []ResponseTool {
+	return s.Tools
+}
+
+// GetText returns the value of Text.
+func (s *CreateResponseRequest) GetText() OptResponseTextConfig {
+	return s.Text
+}
+
+// GetUser returns the value of User.
+func (s *CreateResponseRequest) GetUser() OptString {
+	return s.User
+}
+
+// GetSafetyIdentifier returns the value of SafetyIdentifier.
+func (s *CreateResponseRequest) GetSafetyIdentifier() OptString {
+	return s.SafetyIdentifier
+}
+
+// SetModel sets the value of Model.
+func (s *CreateResponseRequest) SetModel(val string) {
+	s.Model = val
+}
+
+// SetInput sets the value of Input.
+func (s *CreateResponseRequest) SetInput(val string) {
+	s.Input = val
+}
+
+// SetTools sets the value of Tools.
+func (s *CreateResponseRequest) SetTools(val []ResponseTool) {
+	s.Tools = val
+}
+
+// SetText sets the value of Text.
+func (s *CreateResponseRequest) SetText(val OptResponseTextConfig) {
+	s.Text = val
+}
+
+// SetUser sets the value of User.
+func (s *CreateResponseRequest) SetUser(val OptString) {
+	s.User = val
+}
+
+// SetSafetyIdentifier sets the value of SafetyIdentifier.
+func (s *CreateResponseRequest) SetSafetyIdentifier(val OptString) {
+	s.SafetyIdentifier = val
+}
+
+// Ref: #/components/schemas/CreateResponseResponse
+type CreateResponseResponse struct {
+	ID        string                       `json:"id"`
+	Object    CreateResponseResponseObject `json:"object"`
+	CreatedAt OptInt64                     `json:"created_at"`
+	Status    CreateResponseResponseStatus `json:"status"`
+	Model     string                       `json:"model"`
+	Output    []ResponseOutputItem         `json:"output"`
+	Usage     ResponseUsage                `json:"usage"`
+	// The end-user identifier of the request, echoed back.
+	User OptString `json:"user"`
+	// The safety identifier of the request, echoed back.
+	SafetyIdentifier OptString `json:"safety_identifier"`
+}
+
+// GetID returns the value of ID.
+func (s *CreateResponseResponse) GetID() string {
+	return s.ID
+}
+
+// GetObject returns the value of Object.
+func (s *CreateResponseResponse) GetObject() CreateResponseResponseObject {
+	return s.Object
+}
+
+// GetCreatedAt returns the value of CreatedAt.
+func (s *CreateResponseResponse) GetCreatedAt() OptInt64 {
+	return s.CreatedAt
+}
+
+// GetStatus returns the value of Status.
+func (s *CreateResponseResponse) GetStatus() CreateResponseResponseStatus {
+	return s.Status
+}
+
+// GetModel returns the value of Model.
+func (s *CreateResponseResponse) GetModel() string {
+	return s.Model
+}
+
+// GetOutput returns the value of Output.
+func (s *CreateResponseResponse) GetOutput() []ResponseOutputItem {
+	return s.Output
+}
+
+// GetUsage returns the value of Usage.
+func (s *CreateResponseResponse) GetUsage() ResponseUsage {
+	return s.Usage
+}
+
+// GetUser returns the value of User.
+func (s *CreateResponseResponse) GetUser() OptString {
+	return s.User
+}
+
+// GetSafetyIdentifier returns the value of SafetyIdentifier.
+func (s *CreateResponseResponse) GetSafetyIdentifier() OptString {
+	return s.SafetyIdentifier
+}
+
+// SetID sets the value of ID.
+func (s *CreateResponseResponse) SetID(val string) {
+	s.ID = val
+}
+
+// SetObject sets the value of Object.
+func (s *CreateResponseResponse) SetObject(val CreateResponseResponseObject) {
+	s.Object = val
+}
+
+// SetCreatedAt sets the value of CreatedAt.
+func (s *CreateResponseResponse) SetCreatedAt(val OptInt64) {
+	s.CreatedAt = val
+}
+
+// SetStatus sets the value of Status.
+func (s *CreateResponseResponse) SetStatus(val CreateResponseResponseStatus) {
+	s.Status = val
+}
+
+// SetModel sets the value of Model.
+func (s *CreateResponseResponse) SetModel(val string) {
+	s.Model = val
+}
+
+// SetOutput sets the value of Output.
+func (s *CreateResponseResponse) SetOutput(val []ResponseOutputItem) {
+	s.Output = val
+}
+
+// SetUsage sets the value of Usage.
+func (s *CreateResponseResponse) SetUsage(val ResponseUsage) {
+	s.Usage = val
+}
+
+// SetUser sets the value of User.
+func (s *CreateResponseResponse) SetUser(val OptString) {
+	s.User = val
+}
+
+// SetSafetyIdentifier sets the value of SafetyIdentifier.
+func (s *CreateResponseResponse) SetSafetyIdentifier(val OptString) {
+	s.SafetyIdentifier = val
+}
+
+type CreateResponseResponseObject string
+
+const (
+	CreateResponseResponseObjectResponse CreateResponseResponseObject = "response"
+)
+
+// AllValues returns all CreateResponseResponseObject values.
+func (CreateResponseResponseObject) AllValues() []CreateResponseResponseObject {
+	return []CreateResponseResponseObject{
+		CreateResponseResponseObjectResponse,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s CreateResponseResponseObject) MarshalText() ([]byte, error) {
+	switch s {
+	case CreateResponseResponseObjectResponse:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CreateResponseResponseObject) UnmarshalText(data []byte) error {
+	switch CreateResponseResponseObject(data) {
+	case CreateResponseResponseObjectResponse:
+		*s = CreateResponseResponseObjectResponse
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+type CreateResponseResponseStatus string
+
+const (
+	CreateResponseResponseStatusCompleted  CreateResponseResponseStatus = "completed"
+	CreateResponseResponseStatusFailed     CreateResponseResponseStatus = "failed"
+	CreateResponseResponseStatusCancelled  CreateResponseResponseStatus = "cancelled"
+	CreateResponseResponseStatusQueued     CreateResponseResponseStatus = "queued"
+	CreateResponseResponseStatusInProgress CreateResponseResponseStatus = "in_progress"
+)
+
+// AllValues returns all CreateResponseResponseStatus values.
+func (CreateResponseResponseStatus) AllValues() []CreateResponseResponseStatus {
+	return []CreateResponseResponseStatus{
+		CreateResponseResponseStatusCompleted,
+		CreateResponseResponseStatusFailed,
+		CreateResponseResponseStatusCancelled,
+		CreateResponseResponseStatusQueued,
+		CreateResponseResponseStatusInProgress,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s CreateResponseResponseStatus) MarshalText() ([]byte, error) {
+	switch s {
+	case CreateResponseResponseStatusCompleted:
+		return []byte(s), nil
+	case CreateResponseResponseStatusFailed:
+		return []byte(s), nil
+	case CreateResponseResponseStatusCancelled:
+		return []byte(s), nil
+	case CreateResponseResponseStatusQueued:
+		return []byte(s), nil
+	case CreateResponseResponseStatusInProgress:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *CreateResponseResponseStatus) UnmarshalText(data []byte) error {
+	switch CreateResponseResponseStatus(data) {
+	case CreateResponseResponseStatusCompleted:
+		*s = CreateResponseResponseStatusCompleted
+		return nil
+	case CreateResponseResponseStatusFailed:
+		*s = CreateResponseResponseStatusFailed
+		return nil
+	case CreateResponseResponseStatusCancelled:
+		*s = CreateResponseResponseStatusCancelled
+		return nil
+	case CreateResponseResponseStatusQueued:
+		*s = CreateResponseResponseStatusQueued
+		return nil
+	case CreateResponseResponseStatusInProgress:
+		*s = CreateResponseResponseStatusInProgress
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
+// Ref: #/components/schemas/Embedding
+type Embedding struct {
+	Index     int             `json:"index"`
+	Object    EmbeddingObject `json:"object"`
+	Embedding []float64       `json:"embedding"`
+}
+
+// GetIndex returns the value of Index.
+func (s *Embedding) GetIndex() int {
+	return s.Index
+}
+
+// GetObject returns the value of Object.
+func (s *Embedding) GetObject() EmbeddingObject {
+	return s.Object
+}
+
+// GetEmbedding returns the value of Embedding.
+func (s *Embedding) GetEmbedding() []float64 {
+	return s.Embedding
+}
+
+// SetIndex sets the value of Index.
+func (s *Embedding) SetIndex(val int) {
+	s.Index = val
 }
 
 // SetObject sets the value of Object.
@@ -2474,6 +3457,197 @@ func (s *EmbeddingUsage) SetTotalTokens(val int) {
 	s.TotalTokens = val
 }
 
+// Ref: #/components/schemas/Image
+type Image struct {
+	B64JSON       OptString `json:"b64_json"`
+	URL           OptString `json:"url"`
+	RevisedPrompt OptString `json:"revised_prompt"`
+}
+
+// GetB64JSON returns the value of B64JSON.
+func (s *Image) GetB64JSON() OptString {
+	return s.B64JSON
+}
+
+// GetURL returns the value of URL.
+func (s *Image) GetURL() OptString {
+	return s.URL
+}
+
+// GetRevisedPrompt returns the value of RevisedPrompt.
+func (s *Image) GetRevisedPrompt() OptString {
+	return s.RevisedPrompt
+}
+
+// SetB64JSON sets the value of B64JSON.
+func (s *Image) SetB64JSON(val OptString) {
+	s.B64JSON = val
+}
+
+// SetURL sets the value of URL.
+func (s *Image) SetURL(val OptString) {
+	s.URL = val
+}
+
+// SetRevisedPrompt sets the value of RevisedPrompt.
+func (s *Image) SetRevisedPrompt(val OptString) {
+	s.RevisedPrompt = val
+}
+
+// Ref: #/components/schemas/ImageUsage
+type ImageUsage struct {
+	InputTokens        int                          `json:"input_tokens"`
+	OutputTokens       int                          `json:"output_tokens"`
+	TotalTokens        int                          `json:"total_tokens"`
+	InputTokensDetails ImageUsageInputTokensDetails `json:"input_tokens_details"`
+}
+
+// GetInputTokens returns the value of InputTokens.
+func (s *ImageUsage) GetInputTokens() int {
+	return s.InputTokens
+}
+
+// GetOutputTokens returns the value of OutputTokens.
+func (s *ImageUsage) GetOutputTokens() int {
+	return s.OutputTokens
+}
+
+// GetTotalTokens returns the value of TotalTokens.
+func (s *ImageUsage) GetTotalTokens() int {
+	return s.TotalTokens
+}
+
+// GetInputTokensDetails returns the value of InputTokensDetails.
+func (s *ImageUsage) GetInputTokensDetails() ImageUsageInputTokensDetails {
+	return s.InputTokensDetails
+}
+
+// SetInputTokens sets the value of InputTokens.
+func (s *ImageUsage) SetInputTokens(val int) {
+	s.InputTokens = val
+}
+
+// SetOutputTokens sets the value of OutputTokens.
+func (s *ImageUsage) SetOutputTokens(val int) {
+	s.OutputTokens = val
+}
+
+// SetTotalTokens sets the value of TotalTokens.
+func (s *ImageUsage) SetTotalTokens(val int) {
+	s.TotalTokens = val
+}
+
+// SetInputTokensDetails sets the value of InputTokensDetails.
+func (s *ImageUsage) SetInputTokensDetails(val ImageUsageInputTokensDetails) {
+	s.InputTokensDetails = val
+}
+
+type ImageUsageInputTokensDetails struct {
+	TextTokens  int `json:"text_tokens"`
+	ImageTokens int `json:"image_tokens"`
+}
+
+// GetTextTokens returns the value of TextTokens.
+func (s *ImageUsageInputTokensDetails) GetTextTokens() int {
+	return s.TextTokens
+}
+
+// GetImageTokens returns the value of ImageTokens.
+func (s *ImageUsageInputTokensDetails) GetImageTokens() int {
+	return s.ImageTokens
+}
+
+// SetTextTokens sets the value of TextTokens.
+func (s *ImageUsageInputTokensDetails) SetTextTokens(val int) {
+	s.TextTokens = val
+}
+
+// SetImageTokens sets the value of ImageTokens.
+func (s *ImageUsageInputTokensDetails) SetImageTokens(val int) {
+	s.ImageTokens = val
+}
+
+// Ref: #/components/schemas/ImagesResponse
+type ImagesResponse struct {
+	Created      int64         `json:"created"`
+	Data         []Image       `json:"data"`
+	Background   OptString     `json:"background"`
+	OutputFormat OptString     `json:"output_format"`
+	Quality      OptString     `json:"quality"`
+	Size         OptString     `json:"size"`
+	Usage        OptImageUsage `json:"usage"`
+}
+
+// GetCreated returns the value of Created.
+func (s *ImagesResponse) GetCreated() int64 {
+	return s.Created
+}
+
+// GetData returns the value of Data.
+func (s *ImagesResponse) GetData() []Image {
+	return s.Data
+}
+
+// GetBackground returns the value of Background.
+func (s *ImagesResponse) GetBackground() OptString {
+	return s.Background
+}
+
+// GetOutputFormat returns the value of OutputFormat.
+func (s *ImagesResponse) GetOutputFormat() OptString {
+	return s.OutputFormat
+}
+
+// GetQuality returns the value of Quality.
+func (s *ImagesResponse) GetQuality() OptString {
+	return s.Quality
+}
+
+// GetSize returns the value of Size.
+func (s *ImagesResponse) GetSize() OptString {
+	return s.Size
+}
+
+// GetUsage returns the value of Usage.
+func (s *ImagesResponse) GetUsage() OptImageUsage {
+	return s.Usage
+}
+
+// SetCreated sets the value of Created.
+func (s *ImagesResponse) SetCreated(val int64) {
+	s.Created = val
+}
+
+// SetData sets the value of Data.
+func (s *ImagesResponse) SetData(val []Image) {
+	s.Data = val
+}
+
+// SetBackground sets the value of Background.
+func (s *ImagesResponse) SetBackground(val OptString) {
+	s.Background = val
+}
+
+// SetOutputFormat sets the value of OutputFormat.
+func (s *ImagesResponse) SetOutputFormat(val OptString) {
+	s.OutputFormat = val
+}
+
+// SetQuality sets the value of Quality.
+func (s *ImagesResponse) SetQuality(val OptString) {
+	s.Quality = val
+}
+
+// SetSize sets the value of Size.
+func (s *ImagesResponse) SetSize(val OptString) {
+	s.Size = val
+}
+
+// SetUsage sets the value of Usage.
+func (s *ImagesResponse) SetUsage(val OptImageUsage) {
+	s.Usage = val
+}
+
 // Ref: #/components/schemas/ListModelsResponse
 type ListModelsResponse struct {
 	Object ListModelsResponseObject `json:"object"`
@@ -2710,38 +3884,130 @@ func (o OptBool) Or(d bool) bool {
 	return d
 }
 
-// NewOptChatCompletionJSONSchemaSpec returns new OptChatCompletionJSONSchemaSpec with value set to v.
-func NewOptChatCompletionJSONSchemaSpec(v ChatCompletionJSONSchemaSpec) OptChatCompletionJSONSchemaSpec {
-	return OptChatCompletionJSONSchemaSpec{
+// NewOptChatCompletionJSONSchemaSpec returns new OptChatCompletionJSONSchemaSpec with value set to v.
+func NewOptChatCompletionJSONSchemaSpec(v ChatCompletionJSONSchemaSpec) OptChatCompletionJSONSchemaSpec {
+	return OptChatCompletionJSONSchemaSpec{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptChatCompletionJSONSchemaSpec is optional ChatCompletionJSONSchemaSpec.
+type OptChatCompletionJSONSchemaSpec struct {
+	Value ChatCompletionJSONSchemaSpec
+	Set   bool
+}
+
+// IsSet returns true if OptChatCompletionJSONSchemaSpec was set.
+func (o OptChatCompletionJSONSchemaSpec) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptChatCompletionJSONSchemaSpec) Reset() {
+	var v ChatCompletionJSONSchemaSpec
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptChatCompletionJSONSchemaSpec) SetTo(v ChatCompletionJSONSchemaSpec) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptChatCompletionJSONSchemaSpec) Get() (v ChatCompletionJSONSchemaSpec, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptChatCompletionJSONSchemaSpec) Or(d ChatCompletionJSONSchemaSpec) ChatCompletionJSONSchemaSpec {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptChatCompletionRequestMessageContentPartImageURL returns new OptChatCompletionRequestMessageContentPartImageURL with value set to v.
+func NewOptChatCompletionRequestMessageContentPartImageURL(v ChatCompletionRequestMessageContentPartImageURL) OptChatCompletionRequestMessageContentPartImageURL {
+	return OptChatCompletionRequestMessageContentPartImageURL{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptChatCompletionRequestMessageContentPartImageURL is optional ChatCompletionRequestMessageContentPartImageURL.
+type OptChatCompletionRequestMessageContentPartImageURL struct {
+	Value ChatCompletionRequestMessageContentPartImageURL
+	Set   bool
+}
+
+// IsSet returns true if OptChatCompletionRequestMessageContentPartImageURL was set.
+func (o OptChatCompletionRequestMessageContentPartImageURL) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptChatCompletionRequestMessageContentPartImageURL) Reset() {
+	var v ChatCompletionRequestMessageContentPartImageURL
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptChatCompletionRequestMessageContentPartImageURL) SetTo(v ChatCompletionRequestMessageContentPartImageURL) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptChatCompletionRequestMessageContentPartImageURL) Get() (v ChatCompletionRequestMessageContentPartImageURL, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptChatCompletionRequestMessageContentPartImageURL) Or(d ChatCompletionRequestMessageContentPartImageURL) ChatCompletionRequestMessageContentPartImageURL {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptChatCompletionRequestMessageContentPartInputAudio returns new OptChatCompletionRequestMessageContentPartInputAudio with value set to v.
+func NewOptChatCompletionRequestMessageContentPartInputAudio(v ChatCompletionRequestMessageContentPartInputAudio) OptChatCompletionRequestMessageContentPartInputAudio {
+	return OptChatCompletionRequestMessageContentPartInputAudio{
 		Value: v,
 		Set:   true,
 	}
 }
 
-// OptChatCompletionJSONSchemaSpec is optional ChatCompletionJSONSchemaSpec.
-type OptChatCompletionJSONSchemaSpec struct {
-	Value ChatCompletionJSONSchemaSpec
+// OptChatCompletionRequestMessageContentPartInputAudio is optional ChatCompletionRequestMessageContentPartInputAudio.
+type OptChatCompletionRequestMessageContentPartInputAudio struct {
+	Value ChatCompletionRequestMessageContentPartInputAudio
 	Set   bool
 }
 
-// IsSet returns true if OptChatCompletionJSONSchemaSpec was set.
-func (o OptChatCompletionJSONSchemaSpec) IsSet() bool { return o.Set }
+// IsSet returns true if OptChatCompletionRequestMessageContentPartInputAudio was set.
+func (o OptChatCompletionRequestMessageContentPartInputAudio) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptChatCompletionJSONSchemaSpec) Reset() {
-	var v ChatCompletionJSONSchemaSpec
+func (o *OptChatCompletionRequestMessageContentPartInputAudio) Reset() {
+	var v ChatCompletionRequestMessageContentPartInputAudio
 	o.Value = v
 	o.Set = false
 }
 
 // SetTo sets value to v.
-func (o *OptChatCompletionJSONSchemaSpec) SetTo(v ChatCompletionJSONSchemaSpec) {
+func (o *OptChatCompletionRequestMessageContentPartInputAudio) SetTo(v ChatCompletionRequestMessageContentPartInputAudio) {
 	o.Set = true
 	o.Value = v
 }
 
 // Get returns value and boolean that denotes whether value was set.
-func (o OptChatCompletionJSONSchemaSpec) Get() (v ChatCompletionJSONSchemaSpec, ok bool) {
+func (o OptChatCompletionRequestMessageContentPartInputAudio) Get() (v ChatCompletionRequestMessageContentPartInputAudio, ok bool) {
 	if !o.Set {
 		return v, false
 	}
@@ -2749,7 +4015,7 @@ func (o OptChatCompletionJSONSchemaSpec) Get() (v ChatCompletionJSONSchemaSpec,
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptChatCompletionJSONSchemaSpec) Or(d ChatCompletionJSONSchemaSpec) ChatCompletionJSONSchemaSpec {
+func (o OptChatCompletionRequestMessageContentPartInputAudio) Or(d ChatCompletionRequestMessageContentPartInputAudio) ChatCompletionRequestMessageContentPartInputAudio {
 	if v, ok := o.Get(); ok {
 		return v
 	}
@@ -3078,6 +4344,52 @@ func (o OptCreateChatCompletionRequestStop) Or(d CreateChatCompletionRequestStop
 	return d
 }
 
+// NewOptCreateChatCompletionRequestToolChoice returns new OptCreateChatCompletionRequestToolChoice with value set to v.
+func NewOptCreateChatCompletionRequestToolChoice(v CreateChatCompletionRequestToolChoice) OptCreateChatCompletionRequestToolChoice {
+	return OptCreateChatCompletionRequestToolChoice{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCreateChatCompletionRequestToolChoice is optional CreateChatCompletionRequestToolChoice.
+type OptCreateChatCompletionRequestToolChoice struct {
+	Value CreateChatCompletionRequestToolChoice
+	Set   bool
+}
+
+// IsSet returns true if OptCreateChatCompletionRequestToolChoice was set.
+func (o OptCreateChatCompletionRequestToolChoice) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCreateChatCompletionRequestToolChoice) Reset() {
+	var v CreateChatCompletionRequestToolChoice
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCreateChatCompletionRequestToolChoice) SetTo(v CreateChatCompletionRequestToolChoice) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCreateChatCompletionRequestToolChoice) Get() (v CreateChatCompletionRequestToolChoice, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCreateChatCompletionRequestToolChoice) Or(d CreateChatCompletionRequestToolChoice) CreateChatCompletionRequestToolChoice {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // NewOptCreateCompletionRequestLogitBias returns new OptCreateCompletionRequestLogitBias with value set to v.
 func NewOptCreateCompletionRequestLogitBias(v CreateCompletionRequestLogitBias) OptCreateCompletionRequestLogitBias {
 	return OptCreateCompletionRequestLogitBias{
@@ -3216,6 +4528,190 @@ func (o OptCreateEmbeddingRequestEncodingFormat) Or(d CreateEmbeddingRequestEnco
 	return d
 }
 
+// NewOptCreateImageRequestBackground returns new OptCreateImageRequestBackground with value set to v.
+func NewOptCreateImageRequestBackground(v CreateImageRequestBackground) OptCreateImageRequestBackground {
+	return OptCreateImageRequestBackground{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCreateImageRequestBackground is optional CreateImageRequestBackground.
+type OptCreateImageRequestBackground struct {
+	Value CreateImageRequestBackground
+	Set   bool
+}
+
+// IsSet returns true if OptCreateImageRequestBackground was set.
+func (o OptCreateImageRequestBackground) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCreateImageRequestBackground) Reset() {
+	var v CreateImageRequestBackground
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCreateImageRequestBackground) SetTo(v CreateImageRequestBackground) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCreateImageRequestBackground) Get() (v CreateImageRequestBackground, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCreateImageRequestBackground) Or(d CreateImageRequestBackground) CreateImageRequestBackground {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCreateImageRequestOutputFormat returns new OptCreateImageRequestOutputFormat with value set to v.
+func NewOptCreateImageRequestOutputFormat(v CreateImageRequestOutputFormat) OptCreateImageRequestOutputFormat {
+	return OptCreateImageRequestOutputFormat{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCreateImageRequestOutputFormat is optional CreateImageRequestOutputFormat.
+type OptCreateImageRequestOutputFormat struct {
+	Value CreateImageRequestOutputFormat
+	Set   bool
+}
+
+// IsSet returns true if OptCreateImageRequestOutputFormat was set.
+func (o OptCreateImageRequestOutputFormat) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCreateImageRequestOutputFormat) Reset() {
+	var v CreateImageRequestOutputFormat
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCreateImageRequestOutputFormat) SetTo(v CreateImageRequestOutputFormat) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCreateImageRequestOutputFormat) Get() (v CreateImageRequestOutputFormat, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCreateImageRequestOutputFormat) Or(d CreateImageRequestOutputFormat) CreateImageRequestOutputFormat {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCreateImageRequestResponseFormat returns new OptCreateImageRequestResponseFormat with value set to v.
+func NewOptCreateImageRequestResponseFormat(v CreateImageRequestResponseFormat) OptCreateImageRequestResponseFormat {
+	return OptCreateImageRequestResponseFormat{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCreateImageRequestResponseFormat is optional CreateImageRequestResponseFormat.
+type OptCreateImageRequestResponseFormat struct {
+	Value CreateImageRequestResponseFormat
+	Set   bool
+}
+
+// IsSet returns true if OptCreateImageRequestResponseFormat was set.
+func (o OptCreateImageRequestResponseFormat) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCreateImageRequestResponseFormat) Reset() {
+	var v CreateImageRequestResponseFormat
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCreateImageRequestResponseFormat) SetTo(v CreateImageRequestResponseFormat) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCreateImageRequestResponseFormat) Get() (v CreateImageRequestResponseFormat, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCreateImageRequestResponseFormat) Or(d CreateImageRequestResponseFormat) CreateImageRequestResponseFormat {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptCreateImageRequestSize returns new OptCreateImageRequestSize with value set to v.
+func NewOptCreateImageRequestSize(v CreateImageRequestSize) OptCreateImageRequestSize {
+	return OptCreateImageRequestSize{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptCreateImageRequestSize is optional CreateImageRequestSize.
+type OptCreateImageRequestSize struct {
+	Value CreateImageRequestSize
+	Set   bool
+}
+
+// IsSet returns true if OptCreateImageRequestSize was set.
+func (o OptCreateImageRequestSize) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptCreateImageRequestSize) Reset() {
+	var v CreateImageRequestSize
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptCreateImageRequestSize) SetTo(v CreateImageRequestSize) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptCreateImageRequestSize) Get() (v CreateImageRequestSize, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptCreateImageRequestSize) Or(d CreateImageRequestSize) CreateImageRequestSize {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // NewOptFloat64 returns new OptFloat64 with value set to v.
 func NewOptFloat64(v float64) OptFloat64 {
 	return OptFloat64{
@@ -3262,6 +4758,52 @@ func (o OptFloat64) Or(d float64) float64 {
 	return d
 }
 
+// NewOptImageUsage returns new OptImageUsage with value set to v.
+func NewOptImageUsage(v ImageUsage) OptImageUsage {
+	return OptImageUsage{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptImageUsage is optional ImageUsage.
+type OptImageUsage struct {
+	Value ImageUsage
+	Set   bool
+}
+
+// IsSet returns true if OptImageUsage was set.
+func (o OptImageUsage) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptImageUsage) Reset() {
+	var v ImageUsage
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptImageUsage) SetTo(v ImageUsage) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptImageUsage) Get() (v ImageUsage, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptImageUsage) Or(d ImageUsage) ImageUsage {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // NewOptInt returns new OptInt with value set to v.
 func NewOptInt(v int) OptInt {
 	return OptInt{
@@ -3326,20 +4868,88 @@ type OptInt64 struct {
 func (o OptInt64) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptInt64) Reset() {
-	var v int64
+func (o *OptInt64) Reset() {
+	var v int64
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptInt64) SetTo(v int64) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptInt64) Get() (v int64, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptInt64) Or(d int64) int64 {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptNilChatCompletionChoiceLogprobs returns new OptNilChatCompletionChoiceLogprobs with value set to v.
+func NewOptNilChatCompletionChoiceLogprobs(v ChatCompletionChoiceLogprobs) OptNilChatCompletionChoiceLogprobs {
+	return OptNilChatCompletionChoiceLogprobs{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptNilChatCompletionChoiceLogprobs is optional nullable ChatCompletionChoiceLogprobs.
+type OptNilChatCompletionChoiceLogprobs struct {
+	Value ChatCompletionChoiceLogprobs
+	Set   bool
+	Null  bool
+}
+
+// IsSet returns true if OptNilChatCompletionChoiceLogprobs was set.
+func (o OptNilChatCompletionChoiceLogprobs) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptNilChatCompletionChoiceLogprobs) Reset() {
+	var v ChatCompletionChoiceLogprobs
 	o.Value = v
 	o.Set = false
+	o.Null = false
 }
 
 // SetTo sets value to v.
-func (o *OptInt64) SetTo(v int64) {
+func (o *OptNilChatCompletionChoiceLogprobs) SetTo(v ChatCompletionChoiceLogprobs) {
+	o.Set = true
+	o.Null = false
+	o.Value = v
+}
+
+// IsNull returns true if value is Null.
+func (o OptNilChatCompletionChoiceLogprobs) IsNull() bool { return o.Null }
+
+// SetToNull sets value to null.
+func (o *OptNilChatCompletionChoiceLogprobs) SetToNull() {
 	o.Set = true
+	o.Null = true
+	var v ChatCompletionChoiceLogprobs
 	o.Value = v
 }
 
+// IsEmpty returns true if the field was omitted from the payload (not Set and not Null).
+func (o OptNilChatCompletionChoiceLogprobs) IsEmpty() bool {
+	return !o.Set && !o.Null
+}
+
 // Get returns value and boolean that denotes whether value was set.
-func (o OptInt64) Get() (v int64, ok bool) {
+func (o OptNilChatCompletionChoiceLogprobs) Get() (v ChatCompletionChoiceLogprobs, ok bool) {
+	if o.Null {
+		return v, false
+	}
 	if !o.Set {
 		return v, false
 	}
@@ -3347,59 +4957,64 @@ func (o OptInt64) Get() (v int64, ok bool) {
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptInt64) Or(d int64) int64 {
+func (o OptNilChatCompletionChoiceLogprobs) Or(d ChatCompletionChoiceLogprobs) ChatCompletionChoiceLogprobs {
 	if v, ok := o.Get(); ok {
 		return v
 	}
 	return d
 }
 
-// NewOptNilChatCompletionChoiceLogprobs returns new OptNilChatCompletionChoiceLogprobs with value set to v.
-func NewOptNilChatCompletionChoiceLogprobs(v ChatCompletionChoiceLogprobs) OptNilChatCompletionChoiceLogprobs {
-	return OptNilChatCompletionChoiceLogprobs{
+// NewOptNilChatCompletionRequestMessageContent returns new OptNilChatCompletionRequestMessageContent with value set to v.
+func NewOptNilChatCompletionRequestMessageContent(v ChatCompletionRequestMessageContent) OptNilChatCompletionRequestMessageContent {
+	return OptNilChatCompletionRequestMessageContent{
 		Value: v,
 		Set:   true,
 	}
 }
 
-// OptNilChatCompletionChoiceLogprobs is optional nullable ChatCompletionChoiceLogprobs.
-type OptNilChatCompletionChoiceLogprobs struct {
-	Value ChatCompletionChoiceLogprobs
+// OptNilChatCompletionRequestMessageContent is optional nullable ChatCompletionRequestMessageContent.
+type OptNilChatCompletionRequestMessageContent struct {
+	Value ChatCompletionRequestMessageContent
 	Set   bool
 	Null  bool
 }
 
-// IsSet returns true if OptNilChatCompletionChoiceLogprobs was set.
-func (o OptNilChatCompletionChoiceLogprobs) IsSet() bool { return o.Set }
+// IsSet returns true if OptNilChatCompletionRequestMessageContent was set.
+func (o OptNilChatCompletionRequestMessageContent) IsSet() bool { return o.Set }
 
 // Reset unsets value.
-func (o *OptNilChatCompletionChoiceLogprobs) Reset() {
-	var v ChatCompletionChoiceLogprobs
+func (o *OptNilChatCompletionRequestMessageContent) Reset() {
+	var v ChatCompletionRequestMessageContent
 	o.Value = v
 	o.Set = false
 	o.Null = false
 }
 
 // SetTo sets value to v.
-func (o *OptNilChatCompletionChoiceLogprobs) SetTo(v ChatCompletionChoiceLogprobs) {
+func (o *OptNilChatCompletionRequestMessageContent) SetTo(v ChatCompletionRequestMessageContent) {
 	o.Set = true
 	o.Null = false
 	o.Value = v
 }
 
 // IsNull returns true if value is Null.
-func (o OptNilChatCompletionChoiceLogprobs) IsNull() bool { return o.Null }
+func (o OptNilChatCompletionRequestMessageContent) IsNull() bool { return o.Null }
 
 // SetToNull sets value to null.
-func (o *OptNilChatCompletionChoiceLogprobs) SetToNull() {
+func (o *OptNilChatCompletionRequestMessageContent) SetToNull() {
 	o.Set = true
 	o.Null = true
-	var v ChatCompletionChoiceLogprobs
+	var v ChatCompletionRequestMessageContent
 	o.Value = v
 }
 
+// IsEmpty returns true if the field was omitted from the payload (not Set and not Null).
+func (o OptNilChatCompletionRequestMessageContent) IsEmpty() bool {
+	return !o.Set && !o.Null
+}
+
 // Get returns value and boolean that denotes whether value was set.
-func (o OptNilChatCompletionChoiceLogprobs) Get() (v ChatCompletionChoiceLogprobs, ok bool) {
+func (o OptNilChatCompletionRequestMessageContent) Get() (v ChatCompletionRequestMessageContent, ok bool) {
 	if o.Null {
 		return v, false
 	}
@@ -3410,7 +5025,7 @@ func (o OptNilChatCompletionChoiceLogprobs) Get() (v ChatCompletionChoiceLogprob
 }
 
 // Or returns value if set, or given parameter if does not.
-func (o OptNilChatCompletionChoiceLogprobs) Or(d ChatCompletionChoiceLogprobs) ChatCompletionChoiceLogprobs {
+func (o OptNilChatCompletionRequestMessageContent) Or(d ChatCompletionRequestMessageContent) ChatCompletionRequestMessageContent {
 	if v, ok := o.Get(); ok {
 		return v
 	}
@@ -3461,6 +5076,11 @@ func (o *OptNilChatCompletionTokenLogprobArray) SetToNull() {
 	o.Value = v
 }
 
+// IsEmpty returns true if the field was omitted from the payload (not Set and not Null).
+func (o OptNilChatCompletionTokenLogprobArray) IsEmpty() bool {
+	return !o.Set && !o.Null
+}
+
 // Get returns value and boolean that denotes whether value was set.
 func (o OptNilChatCompletionTokenLogprobArray) Get() (v []ChatCompletionTokenLogprob, ok bool) {
 	if o.Null {
@@ -3524,6 +5144,11 @@ func (o *OptNilCompletionChoiceLogprobs) SetToNull() {
 	o.Value = v
 }
 
+// IsEmpty returns true if the field was omitted from the payload (not Set and not Null).
+func (o OptNilCompletionChoiceLogprobs) IsEmpty() bool {
+	return !o.Set && !o.Null
+}
+
 // Get returns value and boolean that denotes whether value was set.
 func (o OptNilCompletionChoiceLogprobs) Get() (v CompletionChoiceLogprobs, ok bool) {
 	if o.Null {
@@ -3587,6 +5212,11 @@ func (o *OptNilInt) SetToNull() {
 	o.Value = v
 }
 
+// IsEmpty returns true if the field was omitted from the payload (not Set and not Null).
+func (o OptNilInt) IsEmpty() bool {
+	return !o.Set && !o.Null
+}
+
 // Get returns value and boolean that denotes whether value was set.
 func (o OptNilInt) Get() (v int, ok bool) {
 	if o.Null {
@@ -3650,6 +5280,11 @@ func (o *OptNilString) SetToNull() {
 	o.Value = v
 }
 
+// IsEmpty returns true if the field was omitted from the payload (not Set and not Null).
+func (o OptNilString) IsEmpty() bool {
+	return !o.Set && !o.Null
+}
+
 // Get returns value and boolean that denotes whether value was set.
 func (o OptNilString) Get() (v string, ok bool) {
 	if o.Null {
@@ -3853,6 +5488,98 @@ func (o OptString) Or(d string) string {
 	return d
 }
 
+// NewOptWebSearchOptions returns new OptWebSearchOptions with value set to v.
+func NewOptWebSearchOptions(v WebSearchOptions) OptWebSearchOptions {
+	return OptWebSearchOptions{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptWebSearchOptions is optional WebSearchOptions.
+type OptWebSearchOptions struct {
+	Value WebSearchOptions
+	Set   bool
+}
+
+// IsSet returns true if OptWebSearchOptions was set.
+func (o OptWebSearchOptions) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptWebSearchOptions) Reset() {
+	var v WebSearchOptions
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptWebSearchOptions) SetTo(v WebSearchOptions) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptWebSearchOptions) Get() (v WebSearchOptions, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptWebSearchOptions) Or(d WebSearchOptions) WebSearchOptions {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
+// NewOptWebSearchOptionsSearchContextSize returns new OptWebSearchOptionsSearchContextSize with value set to v.
+func NewOptWebSearchOptionsSearchContextSize(v WebSearchOptionsSearchContextSize) OptWebSearchOptionsSearchContextSize {
+	return OptWebSearchOptionsSearchContextSize{
+		Value: v,
+		Set:   true,
+	}
+}
+
+// OptWebSearchOptionsSearchContextSize is optional WebSearchOptionsSearchContextSize.
+type OptWebSearchOptionsSearchContextSize struct {
+	Value WebSearchOptionsSearchContextSize
+	Set   bool
+}
+
+// IsSet returns true if OptWebSearchOptionsSearchContextSize was set.
+func (o OptWebSearchOptionsSearchContextSize) IsSet() bool { return o.Set }
+
+// Reset unsets value.
+func (o *OptWebSearchOptionsSearchContextSize) Reset() {
+	var v WebSearchOptionsSearchContextSize
+	o.Value = v
+	o.Set = false
+}
+
+// SetTo sets value to v.
+func (o *OptWebSearchOptionsSearchContextSize) SetTo(v WebSearchOptionsSearchContextSize) {
+	o.Set = true
+	o.Value = v
+}
+
+// Get returns value and boolean that denotes whether value was set.
+func (o OptWebSearchOptionsSearchContextSize) Get() (v WebSearchOptionsSearchContextSize, ok bool) {
+	if !o.Set {
+		return v, false
+	}
+	return o.Value, true
+}
+
+// Or returns value if set, or given parameter if does not.
+func (o OptWebSearchOptionsSearchContextSize) Or(d WebSearchOptionsSearchContextSize) WebSearchOptionsSearchContextSize {
+	if v, ok := o.Get(); ok {
+		return v
+	}
+	return d
+}
+
 // Ref: #/components/schemas/PromptTokensDetails
 type PromptTokensDetails struct {
 	CachedTokens OptInt `json:"cached_tokens"`
@@ -3879,10 +5606,144 @@ func (s *PromptTokensDetails) SetAudioTokens(val OptInt) {
 	s.AudioTokens = val
 }
 
+// Ref: #/components/schemas/ResponseOutputAnnotation
+type ResponseOutputAnnotation struct {
+	Type       ResponseOutputAnnotationType `json:"type"`
+	StartIndex OptInt                       `json:"start_index"`
+	EndIndex   OptInt                       `json:"end_index"`
+	URL        OptString                    `json:"url"`
+	Title      OptString                    `json:"title"`
+	Index      OptInt                       `json:"index"`
+	FileID     OptString                    `json:"file_id"`
+	Filename   OptString                    `json:"filename"`
+}
+
+// GetType returns the value of Type.
+func (s *ResponseOutputAnnotation) GetType() ResponseOutputAnnotationType {
+	return s.Type
+}
+
+// GetStartIndex returns the value of StartIndex.
+func (s *ResponseOutputAnnotation) GetStartIndex() OptInt {
+	return s.StartIndex
+}
+
+// GetEndIndex returns the value of EndIndex.
+func (s *ResponseOutputAnnotation) GetEndIndex() OptInt {
+	return s.EndIndex
+}
+
+// GetURL returns the value of URL.
+func (s *ResponseOutputAnnotation) GetURL() OptString {
+	return s.URL
+}
+
+// GetTitle returns the value of Title.
+func (s *ResponseOutputAnnotation) GetTitle() OptString {
+	return s.Title
+}
+
+// GetIndex returns the value of Index.
+func (s *ResponseOutputAnnotation) GetIndex() OptInt {
+	return s.Index
+}
+
+// GetFileID returns the value of FileID.
+func (s *ResponseOutputAnnotation) GetFileID() OptString {
+	return s.FileID
+}
+
+// GetFilename returns the value of Filename.
+func (s *ResponseOutputAnnotation) GetFilename() OptString {
+	return s.Filename
+}
+
+// SetType sets the value of Type.
+func (s *ResponseOutputAnnotation) SetType(val ResponseOutputAnnotationType) {
+	s.Type = val
+}
+
+// SetStartIndex sets the value of StartIndex.
+func (s *ResponseOutputAnnotation) SetStartIndex(val OptInt) {
+	s.StartIndex = val
+}
+
+// SetEndIndex sets the value of EndIndex.
+func (s *ResponseOutputAnnotation) SetEndIndex(val OptInt) {
+	s.EndIndex = val
+}
+
+// SetURL sets the value of URL.
+func (s *ResponseOutputAnnotation) SetURL(val OptString) {
+	s.URL = val
+}
+
+// SetTitle sets the value of Title.
+func (s *ResponseOutputAnnotation) SetTitle(val OptString) {
+	s.Title = val
+}
+
+// SetIndex sets the value of Index.
+func (s *ResponseOutputAnnotation) SetIndex(val OptInt) {
+	s.Index = val
+}
+
+// SetFileID sets the value of FileID.
+func (s *ResponseOutputAnnotation) SetFileID(val OptString) {
+	s.FileID = val
+}
+
+// SetFilename sets the value of Filename.
+func (s *ResponseOutputAnnotation) SetFilename(val OptString) {
+	s.Filename = val
+}
+
+type ResponseOutputAnnotationType string
+
+const (
+	ResponseOutputAnnotationTypeURLCitation  ResponseOutputAnnotationType = "url_citation"
+	ResponseOutputAnnotationTypeFileCitation ResponseOutputAnnotationType = "file_citation"
+)
+
+// AllValues returns all ResponseOutputAnnotationType values.
+func (ResponseOutputAnnotationType) AllValues() []ResponseOutputAnnotationType {
+	return []ResponseOutputAnnotationType{
+		ResponseOutputAnnotationTypeURLCitation,
+		ResponseOutputAnnotationTypeFileCitation,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ResponseOutputAnnotationType) MarshalText() ([]byte, error) {
+	switch s {
+	case ResponseOutputAnnotationTypeURLCitation:
+		return []byte(s), nil
+	case ResponseOutputAnnotationTypeFileCitation:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ResponseOutputAnnotationType) UnmarshalText(data []byte) error {
+	switch ResponseOutputAnnotationType(data) {
+	case ResponseOutputAnnotationTypeURLCitation:
+		*s = ResponseOutputAnnotationTypeURLCitation
+		return nil
+	case ResponseOutputAnnotationTypeFileCitation:
+		*s = ResponseOutputAnnotationTypeFileCitation
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
+
 // Ref: #/components/schemas/ResponseOutputContent
 type ResponseOutputContent struct {
-	Type ResponseOutputContentType `json:"type"`
-	Text string                    `json:"text"`
+	Type        ResponseOutputContentType  `json:"type"`
+	Text        string                     `json:"text"`
+	Annotations []ResponseOutputAnnotation `json:"annotations"`
 }
 
 // GetType returns the value of Type.
@@ -3895,6 +5756,11 @@ func (s *ResponseOutputContent) GetText() string {
 	return s.Text
 }
 
+// GetAnnotations returns the value of Annotations.
+func (s *ResponseOutputContent) GetAnnotations() []ResponseOutputAnnotation {
+	return s.Annotations
+}
+
 // SetType sets the value of Type.
 func (s *ResponseOutputContent) SetType(val ResponseOutputContentType) {
 	s.Type = val
@@ -3905,6 +5771,11 @@ func (s *ResponseOutputContent) SetText(val string) {
 	s.Text = val
 }
 
+// SetAnnotations sets the value of Annotations.
+func (s *ResponseOutputContent) SetAnnotations(val []ResponseOutputAnnotation) {
+	s.Annotations = val
+}
+
 type ResponseOutputContentType string
 
 const (
@@ -4174,10 +6045,11 @@ func (s *ResponseTextFormatType) UnmarshalText(data []byte) error {
 
 // Ref: #/components/schemas/ResponseTool
 type ResponseTool struct {
-	Type        ResponseToolType `json:"type"`
-	Name        string           `json:"name"`
-	Description OptString        `json:"description"`
-	Parameters  jx.Raw           `json:"parameters"`
+	Type           ResponseToolType `json:"type"`
+	Name           OptString        `json:"name"`
+	Description    OptString        `json:"description"`
+	Parameters     jx.Raw           `json:"parameters"`
+	VectorStoreIds []string         `json:"vector_store_ids"`
 }
 
 // GetType returns the value of Type.
@@ -4186,7 +6058,7 @@ func (s *ResponseTool) GetType() ResponseToolType {
 }
 
 // GetName returns the value of Name.
-func (s *ResponseTool) GetName() string {
+func (s *ResponseTool) GetName() OptString {
 	return s.Name
 }
 
@@ -4200,13 +6072,18 @@ func (s *ResponseTool) GetParameters() jx.Raw {
 	return s.Parameters
 }
 
+// GetVectorStoreIds returns the value of VectorStoreIds.
+func (s *ResponseTool) GetVectorStoreIds() []string {
+	return s.VectorStoreIds
+}
+
 // SetType sets the value of Type.
 func (s *ResponseTool) SetType(val ResponseToolType) {
 	s.Type = val
 }
 
 // SetName sets the value of Name.
-func (s *ResponseTool) SetName(val string) {
+func (s *ResponseTool) SetName(val OptString) {
 	s.Name = val
 }
 
@@ -4220,16 +6097,27 @@ func (s *ResponseTool) SetParameters(val jx.Raw) {
 	s.Parameters = val
 }
 
+// SetVectorStoreIds sets the value of VectorStoreIds.
+func (s *ResponseTool) SetVectorStoreIds(val []string) {
+	s.VectorStoreIds = val
+}
+
 type ResponseToolType string
 
 const (
-	ResponseToolTypeFunction ResponseToolType = "function"
+	ResponseToolTypeFunction         ResponseToolType = "function"
+	ResponseToolTypeWebSearch        ResponseToolType = "web_search"
+	ResponseToolTypeWebSearchPreview ResponseToolType = "web_search_preview"
+	ResponseToolTypeFileSearch       ResponseToolType = "file_search"
 )
 
 // AllValues returns all ResponseToolType values.
 func (ResponseToolType) AllValues() []ResponseToolType {
 	return []ResponseToolType{
 		ResponseToolTypeFunction,
+		ResponseToolTypeWebSearch,
+		ResponseToolTypeWebSearchPreview,
+		ResponseToolTypeFileSearch,
 	}
 }
 
@@ -4238,6 +6126,12 @@ func (s ResponseToolType) MarshalText() ([]byte, error) {
 	switch s {
 	case ResponseToolTypeFunction:
 		return []byte(s), nil
+	case ResponseToolTypeWebSearch:
+		return []byte(s), nil
+	case ResponseToolTypeWebSearchPreview:
+		return []byte(s), nil
+	case ResponseToolTypeFileSearch:
+		return []byte(s), nil
 	default:
 		return nil, errors.Errorf("invalid value: %q", s)
 	}
@@ -4249,6 +6143,15 @@ func (s *ResponseToolType) UnmarshalText(data []byte) error {
 	case ResponseToolTypeFunction:
 		*s = ResponseToolTypeFunction
 		return nil
+	case ResponseToolTypeWebSearch:
+		*s = ResponseToolTypeWebSearch
+		return nil
+	case ResponseToolTypeWebSearchPreview:
+		*s = ResponseToolTypeWebSearchPreview
+		return nil
+	case ResponseToolTypeFileSearch:
+		*s = ResponseToolTypeFileSearch
+		return nil
 	default:
 		return errors.Errorf("invalid value: %q", data)
 	}
@@ -4290,3 +6193,126 @@ func (s *ResponseUsage) SetOutputTokens(val int) {
 func (s *ResponseUsage) SetTotalTokens(val int) {
 	s.TotalTokens = val
 }
+
+// Ref: #/components/schemas/URLCitation
+type URLCitation struct {
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+}
+
+// GetStartIndex returns the value of StartIndex.
+func (s *URLCitation) GetStartIndex() int {
+	return s.StartIndex
+}
+
+// GetEndIndex returns the value of EndIndex.
+func (s *URLCitation) GetEndIndex() int {
+	return s.EndIndex
+}
+
+// GetURL returns the value of URL.
+func (s *URLCitation) GetURL() string {
+	return s.URL
+}
+
+// GetTitle returns the value of Title.
+func (s *URLCitation) GetTitle() string {
+	return s.Title
+}
+
+// SetStartIndex sets the value of StartIndex.
+func (s *URLCitation) SetStartIndex(val int) {
+	s.StartIndex = val
+}
+
+// SetEndIndex sets the value of EndIndex.
+func (s *URLCitation) SetEndIndex(val int) {
+	s.EndIndex = val
+}
+
+// SetURL sets the value of URL.
+func (s *URLCitation) SetURL(val string) {
+	s.URL = val
+}
+
+// SetTitle sets the value of Title.
+func (s *URLCitation) SetTitle(val string) {
+	s.Title = val
+}
+
+// Searches the web for the response; the message then carries url_citation annotations.
+// Ref: #/components/schemas/WebSearchOptions
+type WebSearchOptions struct {
+	SearchContextSize OptWebSearchOptionsSearchContextSize `json:"search_context_size"`
+	UserLocation      jx.Raw                               `json:"user_location"`
+}
+
+// GetSearchContextSize returns the value of SearchContextSize.
+func (s *WebSearchOptions) GetSearchContextSize() OptWebSearchOptionsSearchContextSize {
+	return s.SearchContextSize
+}
+
+// GetUserLocation returns the value of UserLocation.
+func (s *WebSearchOptions) GetUserLocation() jx.Raw {
+	return s.UserLocation
+}
+
+// SetSearchContextSize sets the value of SearchContextSize.
+func (s *WebSearchOptions) SetSearchContextSize(val OptWebSearchOptionsSearchContextSize) {
+	s.SearchContextSize = val
+}
+
+// SetUserLocation sets the value of UserLocation.
+func (s *WebSearchOptions) SetUserLocation(val jx.Raw) {
+	s.UserLocation = val
+}
+
+type WebSearchOptionsSearchContextSize string
+
+const (
+	WebSearchOptionsSearchContextSizeLow    WebSearchOptionsSearchContextSize = "low"
+	WebSearchOptionsSearchContextSizeMedium WebSearchOptionsSearchContextSize = "medium"
+	WebSearchOptionsSearchContextSizeHigh   WebSearchOptionsSearchContextSize = "high"
+)
+
+// AllValues returns all WebSearchOptionsSearchContextSize values.
+func (WebSearchOptionsSearchContextSize) AllValues() []WebSearchOptionsSearchContextSize {
+	return []WebSearchOptionsSearchContextSize{
+		WebSearchOptionsSearchContextSizeLow,
+		WebSearchOptionsSearchContextSizeMedium,
+		WebSearchOptionsSearchContextSizeHigh,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s WebSearchOptionsSearchContextSize) MarshalText() ([]byte, error) {
+	switch s {
+	case WebSearchOptionsSearchContextSizeLow:
+		return []byte(s), nil
+	case WebSearchOptionsSearchContextSizeMedium:
+		return []byte(s), nil
+	case WebSearchOptionsSearchContextSizeHigh:
+		return []byte(s), nil
+	default:
+		return nil, errors.Errorf("invalid value: %q", s)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *WebSearchOptionsSearchContextSize) UnmarshalText(data []byte) error {
+	switch WebSearchOptionsSearchContextSize(data) {
+	case WebSearchOptionsSearchContextSizeLow:
+		*s = WebSearchOptionsSearchContextSizeLow
+		return nil
+	case WebSearchOptionsSearchContextSizeMedium:
+		*s = WebSearchOptionsSearchContextSizeMedium
+		return nil
+	case WebSearchOptionsSearchContextSizeHigh:
+		*s = WebSearchOptionsSearchContextSizeHigh
+		return nil
+	default:
+		return errors.Errorf("invalid value: %q", data)
+	}
+}
@@ -26,6 +26,12 @@ type Handler interface {
 	//
 	// POST /embeddings
 	CreateEmbedding(ctx context.Context, req *CreateEmbeddingRequest) (*CreateEmbeddingResponse, error)
+	// CreateImage implements createImage operation.
+	//
+	// Creates an image given a prompt.
+	//
+	// POST /images/generations
+	CreateImage(ctx context.Context, req *CreateImageRequest) (*ImagesResponse, error)
 	// CreateResponse implements createResponse operation.
 	//
 	// Creates a model response using the Responses API.
@@ -40,6 +40,15 @@ func (UnimplementedHandler) CreateEmbedding(ctx context.Context, req *CreateEmbe
 	return r, ht.ErrNotImplemented
 }
 
+// CreateImage implements createImage operation.
+//
+// Creates an image given a prompt.
+//
+// POST /images/generations
+func (UnimplementedHandler) CreateImage(ctx context.Context, req *CreateImageRequest) (r *ImagesResponse, _ error) {
+	return r, ht.ErrNotImplemented
+}
+
 // CreateResponse implements createResponse operation.
 //
 // Creates a model response using the Responses API.
@@ -125,6 +125,38 @@ func (s *ChatCompletionChoiceLogprobs) Validate() error {
 	return nil
 }
 
+func (s *ChatCompletionMessageAnnotation) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if err := s.Type.Validate(); err != nil {
+			return err
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "type",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s ChatCompletionMessageAnnotationType) Validate() error {
+	switch s {
+	case "url_citation":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
 func (s *ChatCompletionMessageToolCall) Validate() error {
 	if s == nil {
 		return validate.ErrNilPointer
@@ -157,6 +189,38 @@ func (s ChatCompletionMessageToolCallType) Validate() error {
 	}
 }
 
+func (s *ChatCompletionNamedToolChoice) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if err := s.Type.Validate(); err != nil {
+			return err
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "type",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s ChatCompletionNamedToolChoiceType) Validate() error {
+	switch s {
+	case "function":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
 func (s *ChatCompletionRequestMessage) Validate() error {
 	if s == nil {
 		return validate.ErrNilPointer
@@ -174,6 +238,24 @@ func (s *ChatCompletionRequestMessage) Validate() error {
 			Error: err,
 		})
 	}
+	if err := func() error {
+		if value, ok := s.Content.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "content",
+			Error: err,
+		})
+	}
 	if err := func() error {
 		var failures []validate.FieldError
 		for i, elem := range s.ToolCalls {
@@ -205,6 +287,125 @@ func (s *ChatCompletionRequestMessage) Validate() error {
 	return nil
 }
 
+func (s ChatCompletionRequestMessageContent) Validate() error {
+	switch s.Type {
+	case StringChatCompletionRequestMessageContent:
+		return nil // no validation needed
+	case ChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent:
+		if s.ChatCompletionRequestMessageContentPartArray == nil {
+			return errors.New("nil is invalid value")
+		}
+		var failures []validate.FieldError
+		for i, elem := range s.ChatCompletionRequestMessageContentPartArray {
+			if err := func() error {
+				if err := elem.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				failures = append(failures, validate.FieldError{
+					Name:  fmt.Sprintf("[%d]", i),
+					Error: err,
+				})
+			}
+		}
+		if len(failures) > 0 {
+			return &validate.Error{Fields: failures}
+		}
+		return nil
+	default:
+		return errors.Errorf("invalid type %q", s.Type)
+	}
+}
+
+func (s *ChatCompletionRequestMessageContentPart) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if err := s.Type.Validate(); err != nil {
+			return err
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "type",
+			Error: err,
+		})
+	}
+	if err := func() error {
+		if value, ok := s.InputAudio.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "input_audio",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s *ChatCompletionRequestMessageContentPartInputAudio) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if err := s.Format.Validate(); err != nil {
+			return err
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "format",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s ChatCompletionRequestMessageContentPartInputAudioFormat) Validate() error {
+	switch s {
+	case "wav":
+		return nil
+	case "mp3":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
+func (s ChatCompletionRequestMessageContentPartType) Validate() error {
+	switch s {
+	case "text":
+		return nil
+	case "image_url":
+		return nil
+	case "input_audio":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
 func (s ChatCompletionRequestMessageRole) Validate() error {
 	switch s {
 	case "system":
@@ -300,6 +501,31 @@ func (s *ChatCompletionResponseMessage) Validate() error {
 			Error: err,
 		})
 	}
+	if err := func() error {
+		var failures []validate.FieldError
+		for i, elem := range s.Annotations {
+			if err := func() error {
+				if err := elem.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				failures = append(failures, validate.FieldError{
+					Name:  fmt.Sprintf("[%d]", i),
+					Error: err,
+				})
+			}
+		}
+		if len(failures) > 0 {
+			return &validate.Error{Fields: failures}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "annotations",
+			Error: err,
+		})
+	}
 	if len(failures) > 0 {
 		return &validate.Error{Fields: failures}
 	}
@@ -784,6 +1010,24 @@ func (s *CreateChatCompletionRequest) Validate() error {
 			Error: err,
 		})
 	}
+	if err := func() error {
+		if value, ok := s.ToolChoice.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "tool_choice",
+			Error: err,
+		})
+	}
 	if err := func() error {
 		if value, ok := s.ResponseFormat.Get(); ok {
 			if err := func() error {
@@ -802,6 +1046,24 @@ func (s *CreateChatCompletionRequest) Validate() error {
 			Error: err,
 		})
 	}
+	if err := func() error {
+		if value, ok := s.WebSearchOptions.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "web_search_options",
+			Error: err,
+		})
+	}
 	if len(failures) > 0 {
 		return &validate.Error{Fields: failures}
 	}
@@ -830,6 +1092,36 @@ func (s CreateChatCompletionRequestStop) Validate() error {
 	}
 }
 
+func (s CreateChatCompletionRequestToolChoice) Validate() error {
+	switch s.Type {
+	case CreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice:
+		if err := s.CreateChatCompletionRequestToolChoice0.Validate(); err != nil {
+			return err
+		}
+		return nil
+	case ChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice:
+		if err := s.ChatCompletionNamedToolChoice.Validate(); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return errors.Errorf("invalid type %q", s.Type)
+	}
+}
+
+func (s CreateChatCompletionRequestToolChoice0) Validate() error {
+	switch s {
+	case "none":
+		return nil
+	case "auto":
+		return nil
+	case "required":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
 func (s *CreateChatCompletionResponse) Validate() error {
 	if s == nil {
 		return validate.ErrNilPointer
@@ -1159,8 +1451,8 @@ func (s CreateCompletionRequestPrompt) Validate() error {
 	switch s.Type {
 	case StringCreateCompletionRequestPrompt:
 		return nil // no validation needed
-	case StringArrayCreateCompletionRequestPrompt:
-		if s.StringArray == nil {
+	case AnyArrayCreateCompletionRequestPrompt:
+		if s.AnyArray == nil {
 			return errors.New("nil is invalid value")
 		}
 		return nil
@@ -1403,6 +1695,206 @@ func (s CreateEmbeddingResponseObject) Validate() error {
 	}
 }
 
+func (s *CreateImageRequest) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if value, ok := s.N.Get(); ok {
+			if err := func() error {
+				if err := (validate.Int{
+					MinSet:        true,
+					Min:           1,
+					MaxSet:        true,
+					Max:           10,
+					MinExclusive:  false,
+					MaxExclusive:  false,
+					MultipleOfSet: false,
+					MultipleOf:    0,
+					Pattern:       nil,
+				}).Validate(int64(value)); err != nil {
+					return errors.Wrap(err, "int")
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "n",
+			Error: err,
+		})
+	}
+	if err := func() error {
+		if value, ok := s.Size.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "size",
+			Error: err,
+		})
+	}
+	if err := func() error {
+		if value, ok := s.ResponseFormat.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "response_format",
+			Error: err,
+		})
+	}
+	if err := func() error {
+		if value, ok := s.Background.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "background",
+			Error: err,
+		})
+	}
+	if err := func() error {
+		if value, ok := s.OutputFormat.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "output_format",
+			Error: err,
+		})
+	}
+	if err := func() error {
+		if value, ok := s.OutputCompression.Get(); ok {
+			if err := func() error {
+				if err := (validate.Int{
+					MinSet:        true,
+					Min:           0,
+					MaxSet:        true,
+					Max:           100,
+					MinExclusive:  false,
+					MaxExclusive:  false,
+					MultipleOfSet: false,
+					MultipleOf:    0,
+					Pattern:       nil,
+				}).Validate(int64(value)); err != nil {
+					return errors.Wrap(err, "int")
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "output_compression",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s CreateImageRequestBackground) Validate() error {
+	switch s {
+	case "transparent":
+		return nil
+	case "opaque":
+		return nil
+	case "auto":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
+func (s CreateImageRequestOutputFormat) Validate() error {
+	switch s {
+	case "png":
+		return nil
+	case "jpeg":
+		return nil
+	case "webp":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
+func (s CreateImageRequestResponseFormat) Validate() error {
+	switch s {
+	case "url":
+		return nil
+	case "b64_json":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
+func (s CreateImageRequestSize) Validate() error {
+	switch s {
+	case "auto":
+		return nil
+	case "256x256":
+		return nil
+	case "512x512":
+		return nil
+	case "1024x1024":
+		return nil
+	case "1536x1024":
+		return nil
+	case "1024x1536":
+		return nil
+	case "1792x1024":
+		return nil
+	case "1024x1792":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
 func (s *CreateResponseRequest) Validate() error {
 	if s == nil {
 		return validate.ErrNilPointer
@@ -1606,6 +2098,29 @@ func (s EmbeddingObject) Validate() error {
 	}
 }
 
+func (s *ImagesResponse) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if s.Data == nil {
+			return errors.New("nil is invalid value")
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "data",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
 func (s *ListModelsResponse) Validate() error {
 	if s == nil {
 		return validate.ErrNilPointer
@@ -1698,6 +2213,40 @@ func (s ModelObject) Validate() error {
 	}
 }
 
+func (s *ResponseOutputAnnotation) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if err := s.Type.Validate(); err != nil {
+			return err
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "type",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s ResponseOutputAnnotationType) Validate() error {
+	switch s {
+	case "url_citation":
+		return nil
+	case "file_citation":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
 func (s *ResponseOutputContent) Validate() error {
 	if s == nil {
 		return validate.ErrNilPointer
@@ -1715,6 +2264,31 @@ func (s *ResponseOutputContent) Validate() error {
 			Error: err,
 		})
 	}
+	if err := func() error {
+		var failures []validate.FieldError
+		for i, elem := range s.Annotations {
+			if err := func() error {
+				if err := elem.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				failures = append(failures, validate.FieldError{
+					Name:  fmt.Sprintf("[%d]", i),
+					Error: err,
+				})
+			}
+		}
+		if len(failures) > 0 {
+			return &validate.Error{Fields: failures}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "annotations",
+			Error: err,
+		})
+	}
 	if len(failures) > 0 {
 		return &validate.Error{Fields: failures}
 	}
@@ -1882,6 +2456,55 @@ func (s ResponseToolType) Validate() error {
 	switch s {
 	case "function":
 		return nil
+	case "web_search":
+		return nil
+	case "web_search_preview":
+		return nil
+	case "file_search":
+		return nil
+	default:
+		return errors.Errorf("invalid value: %v", s)
+	}
+}
+
+func (s *WebSearchOptions) Validate() error {
+	if s == nil {
+		return validate.ErrNilPointer
+	}
+
+	var failures []validate.FieldError
+	if err := func() error {
+		if value, ok := s.SearchContextSize.Get(); ok {
+			if err := func() error {
+				if err := value.Validate(); err != nil {
+					return err
+				}
+				return nil
+			}(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}(); err != nil {
+		failures = append(failures, validate.FieldError{
+			Name:  "search_context_size",
+			Error: err,
+		})
+	}
+	if len(failures) > 0 {
+		return &validate.Error{Fields: failures}
+	}
+	return nil
+}
+
+func (s WebSearchOptionsSearchContextSize) Validate() error {
+	switch s {
+	case "low":
+		return nil
+	case "medium":
+		return nil
+	case "high":
+		return nil
 	default:
 		return errors.Errorf("invalid value: %v", s)
 	}
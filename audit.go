@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// maxAuditEntries is the number of admin mutations kept in the audit trail.
+const maxAuditEntries = 1000
+
+// maxAuditBodyBytes is the number of request body bytes recorded per audit entry.
+const maxAuditBodyBytes = 1024
+
+// auditEntry records one admin API mutation: who made it, when, and what was requested.
+type auditEntry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"`
+	RemoteAddr string    `json:"remote_addr"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Action     string    `json:"action"`
+	Body       string    `json:"body,omitempty"`
+	Status     int       `json:"status"`
+}
+
+// auditFilter selects audit entries. Empty fields match everything.
+type auditFilter struct {
+	Actor  string
+	Action string
+	Since  time.Time
+	Limit  int
+}
+
+// auditLog keeps the most recent admin mutations. It is safe for concurrent use.
+type auditLog struct {
+	entries *ringBuffer[auditEntry]
+	nextID  atomic.Int64
+}
+
+// newAuditLog creates an empty audit log.
+func newAuditLog() *auditLog {
+	return &auditLog{entries: newRingBuffer[auditEntry](maxAuditEntries)}
+}
+
+// Record assigns the entry an ID and appends it.
+func (l *auditLog) Record(e auditEntry) auditEntry {
+	e.ID = l.nextID.Add(1)
+	l.entries.Push(e)
+	return e
+}
+
+// Query returns the matching entries, newest first.
+func (l *auditLog) Query(f auditFilter) []auditEntry {
+	entries := l.entries.Snapshot()
+	slices.Reverse(entries)
+	matches := []auditEntry{}
+	for _, e := range entries {
+		if f.Actor != "" && e.Actor != f.Actor {
+			continue
+		}
+		if f.Action != "" && e.Action != f.Action {
+			continue
+		}
+		if !f.Since.IsZero() && e.Time.Before(f.Since) {
+			continue
+		}
+		matches = append(matches, e)
+		if f.Limit > 0 && len(matches) == f.Limit {
+			break
+		}
+	}
+	return matches
+}
+
+// adminActor identifies who made an admin request: a fingerprint of the bearer token, so the
+// token itself is never stored, or "anonymous" without one.
+func adminActor(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:4])
+}
+
+// isAdminMutation reports whether a request with the given method changes state by default. Control
+// endpoints registered with handleReadOnly are exceptions, see AdminHandler.readOnly.
+func isAdminMutation(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- auditLog.Query ---
+
+func TestAuditLog_QueryNewestFirstWithFilters(t *testing.T) {
+	// Given
+	l := newAuditLog()
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.Record(auditEntry{Time: base, Actor: "alice", Action: "DELETE /_mokku/streams"})
+	l.Record(auditEntry{Time: base.Add(time.Minute), Actor: "bob", Action: "PUT /_mokku/flags/{name}"})
+	l.Record(auditEntry{Time: base.Add(2 * time.Minute), Actor: "alice", Action: "PUT /_mokku/flags/{name}"})
+
+	// When
+	all := l.Query(auditFilter{})
+	alice := l.Query(auditFilter{Actor: "alice"})
+	recentFlags := l.Query(auditFilter{Action: "PUT /_mokku/flags/{name}", Since: base.Add(90 * time.Second)})
+	limited := l.Query(auditFilter{Limit: 1})
+
+	// Then
+	if len(all) != 3 || all[0].ID != 3 || all[2].ID != 1 {
+		t.Errorf("expected 3 entries newest first, got %+v", all)
+	}
+	if len(alice) != 2 {
+		t.Errorf("expected 2 entries by alice, got %+v", alice)
+	}
+	if len(recentFlags) != 1 || recentFlags[0].Actor != "alice" {
+		t.Errorf("expected alice's flag change, got %+v", recentFlags)
+	}
+	if len(limited) != 1 || limited[0].ID != 3 {
+		t.Errorf("expected only the newest entry, got %+v", limited)
+	}
+}
+
+// --- adminActor ---
+
+func TestAdminActor_FingerprintsBearerToken(t *testing.T) {
+	// Given
+	r := httptest.NewRequest("PUT", "/_mokku/flags/x", nil)
+	r.Header.Set("Authorization", "Bearer s3cret")
+	// When
+	actor := adminActor(r)
+	// Then: stable, prefixed, and not the token itself
+	if !strings.HasPrefix(actor, "token:") || strings.Contains(actor, "s3cret") || actor != adminActor(r) {
+		t.Errorf("unexpected actor %q", actor)
+	}
+}
+
+func TestAdminActor_Anonymous(t *testing.T) {
+	// When
+	actor := adminActor(httptest.NewRequest("PUT", "/_mokku/flags/x", nil))
+	// Then
+	if actor != "anonymous" {
+		t.Errorf("expected anonymous, got %q", actor)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// Admin roles. Readers may only use GET, HEAD, and OPTIONS on the control API; writers may do anything.
+const (
+	adminRoleRead  = "read"
+	adminRoleWrite = "write"
+)
+
+// adminTokenConfig is an admin token in the admin section of the config file.
+type adminTokenConfig struct {
+	Name  string `yaml:"name" json:"name"`
+	Token string `yaml:"token" json:"token"`
+	Role  string `yaml:"role" json:"role"`
+}
+
+// adminConfig is the admin section of the config file.
+type adminConfig struct {
+	Tokens []adminTokenConfig `yaml:"tokens" json:"tokens"`
+	// RestoreWindow is how long deleted scenario rules can be restored, such as 30m; "0s" makes
+	// deletions final. Defaults to defaultRestoreWindow.
+	RestoreWindow string `yaml:"restore_window" json:"restore_window"`
+}
+
+// adminPrincipal is the identity behind an admin request.
+type adminPrincipal struct {
+	Name string
+	Role string
+}
+
+// Allows reports whether the principal may make a request, a mutation or a read-only one.
+func (p adminPrincipal) Allows(mutation bool) bool {
+	return p.Role == adminRoleWrite || !mutation
+}
+
+// adminAuth authenticates control API requests by bearer token. Without configured tokens the
+// control API is open. Tokens can be replaced on config reload.
+type adminAuth struct {
+	tokens atomic.Pointer[map[[sha256.Size]byte]adminPrincipal]
+}
+
+// newAdminAuth creates an authenticator for the configured tokens and MOKKU_ADMIN_TOKEN.
+func newAdminAuth(cfg adminConfig, envToken string) (*adminAuth, error) {
+	a := &adminAuth{}
+	if err := a.Load(cfg, envToken); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Load replaces the tokens. envToken, if set, is a read-write token named "admin". Nothing
+// changes if a token is empty, duplicated, or has an unknown role.
+func (a *adminAuth) Load(cfg adminConfig, envToken string) error {
+	return installNow(a.prepare(cfg, envToken))
+}
+
+// prepare validates the tokens and returns the function installing them.
+func (a *adminAuth) prepare(cfg adminConfig, envToken string) (func(), error) {
+	configured := cfg.Tokens
+	if envToken != "" {
+		configured = append(configured, adminTokenConfig{Name: "admin", Token: envToken, Role: adminRoleWrite})
+	}
+	tokens := map[[sha256.Size]byte]adminPrincipal{}
+	for _, t := range configured {
+		if t.Name == "" || t.Token == "" {
+			return nil, fmt.Errorf("admin tokens need a name and a token")
+		}
+		if t.Role != adminRoleRead && t.Role != adminRoleWrite {
+			return nil, fmt.Errorf("admin token %q has role %q, want %q or %q", t.Name, t.Role, adminRoleRead, adminRoleWrite)
+		}
+		key := sha256.Sum256([]byte(t.Token))
+		if _, ok := tokens[key]; ok {
+			return nil, fmt.Errorf("admin token %q duplicates another token", t.Name)
+		}
+		tokens[key] = adminPrincipal{Name: t.Name, Role: t.Role}
+	}
+	return func() {
+		a.tokens.Store(&tokens)
+	}, nil
+}
+
+// Enabled reports whether any admin token is configured.
+func (a *adminAuth) Enabled() bool {
+	return len(*a.tokens.Load()) > 0
+}
+
+// Authenticate returns the principal behind the request's bearer token. Without configured tokens
+// every request is a writer identified by adminActor. Tokens are compared by their SHA-256 digest.
+func (a *adminAuth) Authenticate(r *http.Request) (adminPrincipal, bool) {
+	if !a.Enabled() {
+		return adminPrincipal{Name: adminActor(r), Role: adminRoleWrite}, true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return adminPrincipal{}, false
+	}
+	p, ok := (*a.tokens.Load())[sha256.Sum256([]byte(token))]
+	return p, ok
+}
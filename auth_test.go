@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// --- newAdminAuth ---
+
+func TestNewAdminAuth_DisabledWithoutTokens(t *testing.T) {
+	// Given
+	a, err := newAdminAuth(adminConfig{}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// When
+	p, ok := a.Authenticate(httptest.NewRequest(http.MethodPut, "/_mokku/flags/x", nil))
+	// Then: open access as an anonymous writer
+	if a.Enabled() || !ok || p.Name != "anonymous" || !p.Allows(true) {
+		t.Errorf("expected open access, got %+v, %v", p, ok)
+	}
+}
+
+func TestNewAdminAuth_InvalidTokens(t *testing.T) {
+	// Given
+	cases := map[string]adminConfig{
+		"unknown role": {Tokens: []adminTokenConfig{{Name: "ci", Token: "t", Role: "owner"}}},
+		"empty token":  {Tokens: []adminTokenConfig{{Name: "ci", Role: adminRoleRead}}},
+		"duplicate": {Tokens: []adminTokenConfig{
+			{Name: "a", Token: "t", Role: adminRoleRead},
+			{Name: "b", Token: "t", Role: adminRoleWrite},
+		}},
+	}
+	for name, cfg := range cases {
+		// When
+		_, err := newAdminAuth(cfg, "")
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// --- adminAuth.Authenticate ---
+
+func TestAdminAuth_Authenticate(t *testing.T) {
+	// Given: a configured reader and the environment token
+	a, err := newAdminAuth(adminConfig{Tokens: []adminTokenConfig{{Name: "dashboard", Token: "r-token", Role: adminRoleRead}}}, "w-token")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	request := func(auth string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/_mokku/streams", nil)
+		if auth != "" {
+			r.Header.Set("Authorization", auth)
+		}
+		return r
+	}
+
+	// When
+	reader, readerOK := a.Authenticate(request("Bearer r-token"))
+	writer, writerOK := a.Authenticate(request("Bearer w-token"))
+	_, wrongOK := a.Authenticate(request("Bearer nope"))
+	_, missingOK := a.Authenticate(request(""))
+
+	// Then
+	if !readerOK || reader.Name != "dashboard" || reader.Allows(true) || !reader.Allows(false) {
+		t.Errorf("unexpected reader: %+v, %v", reader, readerOK)
+	}
+	if !writerOK || writer.Name != "admin" || !writer.Allows(true) {
+		t.Errorf("unexpected writer: %+v, %v", writer, writerOK)
+	}
+	if wrongOK || missingOK {
+		t.Error("expected unknown and missing tokens to be rejected")
+	}
+}
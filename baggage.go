@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/baggage"
+)
+
+// baggageHeader is the response header listing the baggage overrides applied to a request.
+const baggageHeader = "X-Mokku-Baggage"
+
+// baggagePrefix is the prefix of the baggage members read as behavior overrides. Other members are
+// left alone, as they belong to the system under test.
+const baggagePrefix = "mokku."
+
+// Baggage override members.
+const (
+	baggageLatency = baggagePrefix + "latency"
+	baggageError   = baggagePrefix + "error"
+)
+
+// maxBaggageLatency is the longest latency a baggage override can add, so a stray member cannot hang
+// a test run.
+const maxBaggageLatency = 5 * time.Minute
+
+// baggageOverrides are the behavior overrides carried in the W3C baggage of an API request, so end to
+// end tests can steer the mock from deep inside the calling service, which propagates the baggage of
+// the test's trace, without changing request bodies.
+type baggageOverrides struct {
+	// Latency delays the request before it is served.
+	Latency time.Duration
+	// Err fails the request instead of serving it; nil for none.
+	Err *APIError
+	// Applied lists the overrides as member=value, in member name order (baggage members are
+	// unordered).
+	Applied []string
+}
+
+// parseBaggageOverrides reads the mokku.* members of the baggage header of a request. Unknown mokku.*
+// members are rejected so typos are caught; the baggage header itself is parsed leniently, as
+// malformed baggage is dropped by OpenTelemetry too.
+func parseBaggageOverrides(r *http.Request) (baggageOverrides, error) {
+	var o baggageOverrides
+	header := strings.Join(r.Header.Values("Baggage"), ",")
+	if header == "" {
+		return o, nil
+	}
+	bag, err := baggage.Parse(header)
+	if err != nil {
+		return o, nil
+	}
+	for _, member := range bag.Members() {
+		key, value := member.Key(), strings.TrimSpace(member.Value())
+		if !strings.HasPrefix(key, baggagePrefix) {
+			continue
+		}
+		switch key {
+		case baggageLatency:
+			latency, err := time.ParseDuration(value)
+			if err != nil || latency < 0 || latency > maxBaggageLatency {
+				return o, fmt.Errorf("baggage %s must be a duration between 0s and %s, got %q", key, maxBaggageLatency, value)
+			}
+			o.Latency = latency
+		case baggageError:
+			status, err := strconv.Atoi(value)
+			if err != nil || status < 400 || status > 599 {
+				return o, fmt.Errorf("baggage %s must be an HTTP error status (400-599), got %q", key, value)
+			}
+			o.Err = scenarioError(status, nil)
+		default:
+			return o, fmt.Errorf("unknown baggage member %s, expected %s or %s", key, baggageLatency, baggageError)
+		}
+		o.Applied = append(o.Applied, key+"="+value)
+	}
+	slices.Sort(o.Applied)
+	return o, nil
+}
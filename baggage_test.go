@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- parseBaggageOverrides ---
+
+func TestParseBaggageOverrides(t *testing.T) {
+	tests := []struct {
+		name    string
+		baggage []string
+		latency time.Duration
+		status  int
+		applied string
+		wantErr string
+	}{
+		{name: "no baggage"},
+		{name: "caller members only", baggage: []string{"tenant=acme,userId=42"}},
+		{name: "latency", baggage: []string{"mokku.latency=2s"}, latency: 2 * time.Second, applied: "mokku.latency=2s"},
+		{name: "error across headers", baggage: []string{"tenant=acme", "mokku.error=503;ttl=1"}, status: 503, applied: "mokku.error=503"},
+		{name: "malformed baggage is ignored", baggage: []string{"not baggage"}},
+		{name: "invalid latency", baggage: []string{"mokku.latency=soon"}, wantErr: "mokku.latency must be a duration"},
+		{name: "latency too long", baggage: []string{"mokku.latency=1h"}, wantErr: "mokku.latency must be a duration"},
+		{name: "success status", baggage: []string{"mokku.error=200"}, wantErr: "mokku.error must be an HTTP error status"},
+		{name: "unknown member", baggage: []string{"mokku.latncy=2s"}, wantErr: "unknown baggage member mokku.latncy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			for _, value := range tt.baggage {
+				r.Header.Add("Baggage", value)
+			}
+
+			// When
+			o, err := parseBaggageOverrides(r)
+
+			// Then
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			status := 0
+			if o.Err != nil {
+				status = o.Err.StatusCode
+			}
+			if o.Latency != tt.latency || status != tt.status || strings.Join(o.Applied, ",") != tt.applied {
+				t.Errorf("unexpected overrides %+v", o)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxBannedUsers is the number of end-users that can be banned, including those banned at runtime.
+const maxBannedUsers = 10000
+
+// Ban sources.
+const (
+	banSourceConfig = "config"
+	banSourceAdmin  = "admin"
+)
+
+// bannedUser is an end-user on the denylist, as returned by GET /_mokku/banned-users.
+type bannedUser struct {
+	ID       string    `json:"id"`
+	Reason   string    `json:"reason,omitempty"`
+	Source   string    `json:"source"`
+	BannedAt time.Time `json:"banned_at"`
+	// Blocked counts the requests rejected for the user since the ban.
+	Blocked int64 `json:"blocked"`
+}
+
+// banRequest is the request body for PUT /_mokku/banned-users/{id}; it may be empty.
+type banRequest struct {
+	Reason string `json:"reason"`
+}
+
+// userDenylist holds the banned end-users, from the moderation section of the config file and
+// PUT /_mokku/banned-users/{id}, so enforcement and appeal (DELETE) flows can be tested. IDs are
+// compared exactly with the safety_identifier and user fields of request bodies. It is safe for
+// concurrent use.
+type userDenylist struct {
+	// mu serializes updates so the capacity check and Put of Ban are atomic.
+	mu    sync.Mutex
+	users *boundedMap[string, bannedUser]
+	now   func() time.Time
+}
+
+// newUserDenylist creates a denylist of the configured IDs.
+func newUserDenylist(ids []string) *userDenylist {
+	d := &userDenylist{users: newBoundedMap[string, bannedUser](maxBannedUsers), now: time.Now}
+	d.Load(ids)
+	return d
+}
+
+// Load replaces all bans, including those made at runtime, with the configured IDs; blank IDs are
+// ignored and IDs beyond maxBannedUsers are dropped.
+func (d *userDenylist) Load(ids []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.users.Clear()
+	now := d.now()
+	for _, id := range ids {
+		if id != "" {
+			d.users.Put(id, bannedUser{ID: id, Source: banSourceConfig, BannedAt: now})
+		}
+	}
+}
+
+// Active reports whether any user is banned.
+func (d *userDenylist) Active() bool {
+	return d.users.Len() > 0
+}
+
+// Ban adds an end-user to the denylist, or replaces the reason of a banned one.
+func (d *userDenylist) Ban(id, reason string) (bannedUser, error) {
+	if strings.TrimSpace(id) == "" {
+		return bannedUser{}, fmt.Errorf("id is required")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	user, ok := d.users.Get(id)
+	if !ok {
+		if d.users.Len() >= maxBannedUsers {
+			return bannedUser{}, fmt.Errorf("at most %d users can be banned", maxBannedUsers)
+		}
+		user = bannedUser{ID: id, BannedAt: d.now()}
+	}
+	user.Reason, user.Source = reason, banSourceAdmin
+	d.users.Put(id, user)
+	return user, nil
+}
+
+// Restore replaces the denylist with the users handed off by a previous process, keeping their
+// sources and blocked counts.
+func (d *userDenylist) Restore(users []bannedUser) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.users.Clear()
+	for _, user := range users {
+		d.users.Put(user.ID, user)
+	}
+}
+
+// Unban removes an end-user from the denylist and reports whether it was banned.
+func (d *userDenylist) Unban(id string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.users.Delete(id)
+}
+
+// List returns the banned end-users sorted by ID.
+func (d *userDenylist) List() []bannedUser {
+	users := d.users.Values()
+	slices.SortFunc(users, func(a, b bannedUser) int { return strings.Compare(a.ID, b.ID) })
+	return users
+}
+
+// Check returns the field (safety_identifier, then user) of a decoded JSON request body naming a
+// banned end-user, and counts the blocked request.
+func (d *userDenylist) Check(doc any) (string, bool) {
+	if !d.Active() {
+		return "", false
+	}
+	id := requestEndUser(doc)
+	for _, f := range []struct{ field, id string }{{"safety_identifier", id.SafetyIdentifier}, {"user", id.User}} {
+		if f.id == "" {
+			continue
+		}
+		d.mu.Lock()
+		user, ok := d.users.Get(f.id)
+		if ok {
+			user.Blocked++
+			d.users.Put(f.id, user)
+		}
+		d.mu.Unlock()
+		if ok {
+			return f.field, true
+		}
+	}
+	return "", false
+}
+
+// newBlockedUserError returns the policy error for a request made on behalf of a banned end-user,
+// named by the body field param.
+func newBlockedUserError(param string) *APIError {
+	return &APIError{
+		StatusCode: http.StatusForbidden,
+		Detail: OpenAIErrorDetail{
+			Message: "Your request was blocked because the user it was made for has been banned for " +
+				"violating our usage policies.",
+			Type:  "request_forbidden",
+			Param: &param,
+			Code:  "user_blocked",
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// --- userDenylist ---
+
+func TestUserDenylist_BanUnbanAndReload(t *testing.T) {
+	// Given: a configured ban
+	d := newUserDenylist([]string{"u-config", ""})
+
+	// When: a user is banned at runtime, banned again with a reason, and the config user appeals
+	if _, err := d.Ban("u-admin", ""); err != nil {
+		t.Fatal(err)
+	}
+	rebanned, _ := d.Ban("u-admin", "fraud")
+	unbanned := d.Unban("u-config")
+	missing := d.Unban("u-config")
+
+	// Then
+	if !unbanned || missing {
+		t.Errorf("expected only the first unban to succeed, got %v and %v", unbanned, missing)
+	}
+	if list := d.List(); len(list) != 1 || list[0].ID != "u-admin" || list[0].Reason != "fraud" || list[0].Source != banSourceAdmin {
+		t.Errorf("unexpected list %+v", list)
+	}
+	if rebanned.BannedAt.IsZero() {
+		t.Error("expected a ban time")
+	}
+	d.Load([]string{"u-config"})
+	if list := d.List(); len(list) != 1 || list[0].ID != "u-config" || list[0].Source != banSourceConfig {
+		t.Errorf("expected a reload to replace runtime bans, got %+v", list)
+	}
+}
+
+func TestUserDenylist_Ban_RejectsBlankIDs(t *testing.T) {
+	// When
+	_, err := newUserDenylist(nil).Ban(" ", "")
+
+	// Then
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestUserDenylist_Check_CountsBlockedRequests(t *testing.T) {
+	// Given
+	d := newUserDenylist([]string{"u-1"})
+
+	// When
+	field, blocked := d.Check(map[string]any{"user": "u-1"})
+	_, _ = d.Check(map[string]any{"safety_identifier": "u-1"})
+	_, allowed := d.Check(map[string]any{"safety_identifier": "u-2"})
+
+	// Then
+	if field != "user" || !blocked || allowed {
+		t.Errorf("unexpected checks %q %v %v", field, blocked, allowed)
+	}
+	if list := d.List(); list[0].Blocked != 2 {
+		t.Errorf("expected two blocked requests, got %+v", list[0])
+	}
+}
+
+func TestUserDenylist_Concurrent_BanCheckAndUnban(t *testing.T) {
+	// Given
+	d := newUserDenylist(nil)
+	var wg sync.WaitGroup
+
+	// When: users are banned, checked, listed, and unbanned from many goroutines
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 300 {
+				id := fmt.Sprintf("u-%d", (i+j)%50)
+				_, _ = d.Ban(id, "")
+				_, _ = d.Check(map[string]any{"user": id})
+				if j%10 == 0 {
+					_ = d.List()
+					d.Unban(id)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: the denylist stays bounded and consistent
+	checkBoundedMapInvariants(t, d.users)
+	if n := len(d.List()); n > 50 {
+		t.Errorf("expected at most 50 bans, got %d", n)
+	}
+}
+
+// --- newBlockedUserError ---
+
+func TestNewBlockedUserError_NamesField(t *testing.T) {
+	// When
+	err := newBlockedUserError("safety_identifier")
+
+	// Then
+	if err.StatusCode != http.StatusForbidden || err.Detail.Code != "user_blocked" || *err.Detail.Param != "safety_identifier" {
+		t.Errorf("unexpected error %+v", err)
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// maxDiffValueLen is the length a differing value is cut to in a diff-requests report.
+const maxDiffValueLen = 60
+
+// requestDiff compares the requests captured in a test run with a baseline run. Requests are
+// compared by method, path, and JSON body; the time and headers of a request are volatile (auth,
+// trace, and seed headers change between runs) and are ignored, as are the body fields in Ignore.
+type requestDiff struct {
+	// Ignore lists body fields left out of the comparison, as dot-separated paths from the top
+	// level such as "metadata.run_id".
+	Ignore []string
+}
+
+// requestChange is a baseline request changed in the current run.
+type requestChange struct {
+	Baseline, Current capturedRequest
+	// Fields describes the differing body fields, one line each.
+	Fields []string
+}
+
+// requestDiffReport is the result of a requestDiff.
+type requestDiffReport struct {
+	Baseline, Current int
+	// New are the current requests without a baseline counterpart, and Missing the baseline requests
+	// no longer sent.
+	New, Missing []capturedRequest
+	Changed      []requestChange
+}
+
+// runDiffRequests implements "openai-mokku diff-requests" and returns the exit code: 0 without
+// differences, 1 with differences or on error.
+func runDiffRequests(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("diff-requests", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("target", "http://localhost:8080", "base URL of the mokku instance holding the captured requests of the current run")
+	current := fs.String("current", "", "traffic log of the current run, instead of fetching it from -target")
+	token := fs.String("token", os.Getenv("MOKKU_ADMIN_TOKEN"), "admin token of the control API (default $MOKKU_ADMIN_TOKEN)")
+	update := fs.Bool("update", false, "write the current run to the baseline file instead of comparing")
+	var ignore stringListFlag
+	fs.Var(&ignore, "ignore", "body field to ignore, as a dot-separated path such as metadata.run_id (repeatable)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "usage: openai-mokku diff-requests [flags] <baseline.jsonl>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	var log []byte
+	var err error
+	if *current != "" {
+		log, err = os.ReadFile(*current)
+	} else {
+		log, err = fetchTrafficLog(context.Background(), http.DefaultClient, strings.TrimSuffix(*target, "/"), *token)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff-requests: %v\n", err)
+		return 1
+	}
+	if *update {
+		if err := os.WriteFile(fs.Arg(0), log, 0o644); err != nil {
+			_, _ = fmt.Fprintf(stderr, "diff-requests: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	currentReqs, err := readTrafficLog(bytes.NewReader(log))
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff-requests: current run: %v\n", err)
+		return 1
+	}
+	f, err := os.Open(fs.Arg(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff-requests: %v\n", err)
+		return 1
+	}
+	defer func() { _ = f.Close() }()
+	baselineReqs, err := readTrafficLog(f)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "diff-requests: baseline: %v\n", err)
+		return 1
+	}
+
+	report := requestDiff{Ignore: ignore}.Compare(baselineReqs, currentReqs)
+	report.write(stdout)
+	if report.Differs() {
+		return 1
+	}
+	return 0
+}
+
+// stringListFlag collects the values of a repeated flag.
+type stringListFlag []string
+
+func (l *stringListFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *stringListFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// fetchTrafficLog downloads the captured requests of a mokku instance as a traffic log.
+func fetchTrafficLog(ctx context.Context, client *http.Client, base, token string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, base+"/_mokku/requests?format=jsonl", nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET /_mokku/requests: %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// readTrafficLog parses a traffic log like readCapturedRequests, but a run without requests is not
+// an error.
+func readTrafficLog(r io.Reader) ([]capturedRequest, error) {
+	reqs, err := readCapturedRequests(r)
+	if errors.Is(err, errNoRequests) {
+		return nil, nil
+	}
+	return reqs, err
+}
+
+// Compare matches the current requests with the baseline ones. Requests sent unchanged are matched
+// first, wherever they are in the runs, so tests running in a different order do not report
+// differences. The remaining requests are paired in order by method, path, and model as changed;
+// what is left over is new or missing.
+func (d requestDiff) Compare(baseline, current []capturedRequest) requestDiffReport {
+	report := requestDiffReport{Baseline: len(baseline), Current: len(current)}
+	bodies := func(reqs []capturedRequest) []any {
+		out := make([]any, len(reqs))
+		for i, req := range reqs {
+			out[i] = d.normalize(req.Body)
+		}
+		return out
+	}
+	baseBodies, curBodies := bodies(baseline), bodies(current)
+	matched := make([]bool, len(current))
+	var unmatched []int
+	for i, base := range baseline {
+		found := -1
+		for j, cur := range current {
+			if !matched[j] && requestKey(base) == requestKey(cur) && jsonEqual(baseBodies[i], curBodies[j]) {
+				found = j
+				break
+			}
+		}
+		if found < 0 {
+			unmatched = append(unmatched, i)
+			continue
+		}
+		matched[found] = true
+	}
+	for _, i := range unmatched {
+		found := -1
+		for j, cur := range current {
+			if !matched[j] && requestKey(baseline[i]) == requestKey(cur) {
+				found = j
+				break
+			}
+		}
+		if found < 0 {
+			report.Missing = append(report.Missing, baseline[i])
+			continue
+		}
+		matched[found] = true
+		var fields []string
+		diffJSON("", baseBodies[i], curBodies[found], &fields)
+		report.Changed = append(report.Changed, requestChange{Baseline: baseline[i], Current: current[found], Fields: fields})
+	}
+	for j, cur := range current {
+		if !matched[j] {
+			report.New = append(report.New, cur)
+		}
+	}
+	return report
+}
+
+// normalize decodes a request body without the ignored fields. A body that is not JSON is compared
+// as a string.
+func (d requestDiff) normalize(body json.RawMessage) any {
+	if len(body) == 0 {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	for _, field := range d.Ignore {
+		removeJSONPath(v, strings.Split(field, "."))
+	}
+	return v
+}
+
+// removeJSONPath deletes the object member at path from v, if present.
+func removeJSONPath(v any, path []string) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		delete(obj, path[0])
+		return
+	}
+	removeJSONPath(obj[path[0]], path[1:])
+}
+
+// requestKey identifies the requests that are paired when they differ: the method, path, and model.
+func requestKey(req capturedRequest) string {
+	var body struct {
+		Model string `json:"model"`
+	}
+	_ = json.Unmarshal(req.Body, &body)
+	return req.Method + " " + req.Path + " " + body.Model
+}
+
+// jsonEqual reports whether two decoded JSON values are equal.
+func jsonEqual(a, b any) bool {
+	var fields []string
+	diffJSON("", a, b, &fields)
+	return len(fields) == 0
+}
+
+// diffJSON appends a line for every field that differs between two decoded JSON values, named by
+// its path from the top level.
+func diffJSON(path string, a, b any, fields *[]string) {
+	name := path
+	if name == "" {
+		name = "body"
+	}
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := slices.AppendSeq(slices.Collect(maps.Keys(a)), maps.Keys(b))
+		slices.Sort(keys)
+		for _, k := range slices.Compact(keys) {
+			child := k
+			if path != "" {
+				child = path + "." + k
+			}
+			av, aok := a[k]
+			bv, bok := b[k]
+			switch {
+			case !aok:
+				*fields = append(*fields, fmt.Sprintf("%s: added %s", child, diffValue(bv)))
+			case !bok:
+				*fields = append(*fields, fmt.Sprintf("%s: removed %s", child, diffValue(av)))
+			default:
+				diffJSON(child, av, bv, fields)
+			}
+		}
+		return
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			break
+		}
+		for i := range a {
+			diffJSON(fmt.Sprintf("%s[%d]", path, i), a[i], b[i], fields)
+		}
+		return
+	}
+	if !reflect.DeepEqual(a, b) {
+		*fields = append(*fields, fmt.Sprintf("%s: %s -> %s", name, diffValue(a), diffValue(b)))
+	}
+}
+
+// diffValue formats a decoded JSON value for a diff report, cut to maxDiffValueLen.
+func diffValue(v any) string {
+	data, _ := json.Marshal(v)
+	s := string(data)
+	if len(s) > maxDiffValueLen {
+		s = s[:maxDiffValueLen-3] + "..."
+	}
+	return s
+}
+
+// Differs reports whether any request is new, missing, or changed.
+func (r requestDiffReport) Differs() bool {
+	return len(r.New)+len(r.Missing)+len(r.Changed) > 0
+}
+
+// write prints the report: a summary line, then a line per new (+), missing (-), and changed (~)
+// request, followed by its differing fields.
+func (r requestDiffReport) write(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "%d baseline requests, %d current: %d new, %d missing, %d changed\n",
+		r.Baseline, r.Current, len(r.New), len(r.Missing), len(r.Changed))
+	for _, req := range r.New {
+		_, _ = fmt.Fprintf(w, "+ %s\n", describeRequest(req))
+	}
+	for _, req := range r.Missing {
+		_, _ = fmt.Fprintf(w, "- %s\n", describeRequest(req))
+	}
+	for _, c := range r.Changed {
+		_, _ = fmt.Fprintf(w, "~ %s\n", describeRequest(c.Current))
+		for _, field := range c.Fields {
+			_, _ = fmt.Fprintf(w, "    %s\n", field)
+		}
+	}
+}
+
+// describeRequest names a request in a report by its method, path, and model.
+func describeRequest(req capturedRequest) string {
+	return strings.TrimSpace(requestKey(req))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// --- requestDiff.Compare ---
+
+func TestRequestDiff_ReportsNewMissingAndChangedRequests(t *testing.T) {
+	// Given: a baseline run, and a current run in another order with a changed prompt, a new
+	// embeddings call, and no moderation call
+	baseline, err := readCapturedRequests(strings.NewReader(`
+{"time":"2026-01-01T00:00:00Z","method":"POST","path":"/v1/chat/completions","headers":{"X-Mokku-Seed":"1"},"body":{"model":"gpt-4o","temperature":0.2,"messages":[{"role":"user","content":"Summarize the ticket"}]}}
+{"time":"2026-01-01T00:00:01Z","method":"GET","path":"/v1/models"}
+{"time":"2026-01-01T00:00:02Z","method":"POST","path":"/v1/moderations","body":{"model":"omni-moderation-latest","input":"hi"}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := readCapturedRequests(strings.NewReader(`
+{"time":"2026-02-01T00:00:00Z","method":"GET","path":"/v1/models","headers":{"X-Mokku-Seed":"7"}}
+{"time":"2026-02-01T00:00:01Z","method":"POST","path":"/v1/chat/completions","body":{"model":"gpt-4o","messages":[{"role":"user","content":"Summarise the ticket"}],"metadata":{"run_id":"42"}}}
+{"time":"2026-02-01T00:00:02Z","method":"POST","path":"/v1/embeddings","body":{"model":"text-embedding-3-small","input":"hi"}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	report := requestDiff{Ignore: []string{"metadata.run_id"}}.Compare(baseline, current)
+
+	// Then: times, headers, order, and ignored fields make no difference
+	if !report.Differs() || len(report.New) != 1 || len(report.Missing) != 1 || len(report.Changed) != 1 {
+		t.Fatalf("expected 1 new, 1 missing, and 1 changed request, got %+v", report)
+	}
+	if report.New[0].Path != "/v1/embeddings" || report.Missing[0].Path != "/v1/moderations" {
+		t.Errorf("unexpected new %s and missing %s", report.New[0].Path, report.Missing[0].Path)
+	}
+	fields := strings.Join(report.Changed[0].Fields, "\n")
+	want := `messages[0].content: "Summarize the ticket" -> "Summarise the ticket"
+metadata: added {}
+temperature: removed 0.2`
+	if fields != want {
+		t.Errorf("expected the changed fields\n%s\ngot\n%s", want, fields)
+	}
+}
+
+func TestRequestDiff_SameRunDoesNotDiffer(t *testing.T) {
+	// Given
+	reqs := []capturedRequest{{Method: http.MethodPost, Path: "/v1/responses", Body: []byte(`{"model":"gpt-4o","input":"hi"}`)}}
+
+	// When
+	report := requestDiff{}.Compare(reqs, reqs)
+
+	// Then
+	if report.Differs() {
+		t.Errorf("expected no differences, got %+v", report)
+	}
+}
+
+// --- runDiffRequests ---
+
+func TestRunDiffRequests_ComparesCapturedRunWithBaseline(t *testing.T) {
+	// Given: a baseline recorded from one run
+	srv := newTestServer(t)
+	defer srv.Close()
+	send := func(content string) {
+		resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"`+content+`"}]}`)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	send("hello")
+	baseline := filepath.Join(t.TempDir(), "baseline.jsonl")
+	var stdout, stderr bytes.Buffer
+	if code := runDiffRequests([]string{"-target", srv.URL, "-update", baseline}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected the baseline to be written, got exit code %d: %s", code, stderr.String())
+	}
+	if data, err := os.ReadFile(baseline); err != nil || !strings.Contains(string(data), "hello") {
+		t.Fatalf("expected the captured request in the baseline, got %q (%v)", data, err)
+	}
+
+	// When: the next run sends another prompt
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/requests", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	send("hello there")
+	stdout.Reset()
+	code := runDiffRequests([]string{"-target", srv.URL, baseline}, &stdout, &stderr)
+
+	// Then
+	if code != 1 {
+		t.Errorf("expected exit code 1 for a difference, got %d: %s", code, stderr.String())
+	}
+	want := `1 baseline requests, 1 current: 0 new, 0 missing, 1 changed
+~ POST /v1/chat/completions gpt-4o
+    messages[0].content: "hello" -> "hello there"
+`
+	if stdout.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, stdout.String())
+	}
+}
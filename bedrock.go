@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// Paths of the Bedrock runtime InvokeModel and InvokeModelWithResponseStream operations, around the
+// model ID.
+const (
+	bedrockModelPath    = "/model/"
+	bedrockInvokeSuffix = "/invoke"
+	bedrockStreamSuffix = "/invoke-with-response-stream"
+)
+
+// bedrockAnthropicVersion is the anthropic_version Bedrock requires in the bodies of Claude models.
+const bedrockAnthropicVersion = "bedrock-2023-05-31"
+
+// eventStreamContentType is the content type of InvokeModelWithResponseStream responses.
+const eventStreamContentType = "application/vnd.amazon.eventstream"
+
+// eventStreamStringHeader is the value type of string headers in the event stream encoding.
+const eventStreamStringHeader = 7
+
+// bedrockDialect is the format of the Bedrock runtime InvokeModel and InvokeModelWithResponseStream
+// operations for Anthropic Claude models: the Messages API body with anthropic_version instead of
+// model, which is the model ID of the path. Streams are in the AWS event stream encoding, each
+// message carrying a Messages API stream event.
+type bedrockDialect struct {
+	// stream is set for InvokeModelWithResponseStream.
+	stream bool
+}
+
+// bedrockErrorTypes are the exceptions of the Bedrock runtime by status.
+var bedrockErrorTypes = map[int]string{
+	http.StatusBadRequest:          "ValidationException",
+	http.StatusForbidden:           "AccessDeniedException",
+	http.StatusNotFound:            "ResourceNotFoundException",
+	http.StatusRequestTimeout:      "ModelTimeoutException",
+	http.StatusFailedDependency:    "ModelErrorException",
+	http.StatusTooManyRequests:     "ThrottlingException",
+	http.StatusInternalServerError: "InternalServerException",
+	http.StatusServiceUnavailable:  "ServiceUnavailableException",
+}
+
+func (bedrockDialect) provider() attribute.KeyValue {
+	return semconv.GenAIProviderNameAWSBedrock
+}
+
+func (bedrockDialect) newID() string {
+	return "msg_bdrk_" + strings.ReplaceAll(uuid.New().String(), "-", "")[:24]
+}
+
+func (d bedrockDialect) decodeRequest(body []byte, model string) (canonicalRequest, error) {
+	var in struct {
+		AnthropicVersion string `json:"anthropic_version"`
+	}
+	if err := json.Unmarshal(body, &in); err != nil {
+		return canonicalRequest{}, fmt.Errorf("Malformed input request: %v, please reformat your input and try again.", err)
+	}
+	if in.AnthropicVersion != bedrockAnthropicVersion {
+		return canonicalRequest{}, fmt.Errorf("Malformed input request: anthropic_version must be %s, please reformat your input and try again.", bedrockAnthropicVersion)
+	}
+	req, err := anthropicDialect{}.decodeRequest(body, model)
+	if err != nil {
+		return canonicalRequest{}, fmt.Errorf("Malformed input request: %v, please reformat your input and try again.", err)
+	}
+	req.Stream = d.stream
+	return req, nil
+}
+
+func (bedrockDialect) encodeResponse(c canonicalCompletion) any {
+	return anthropicDialect{}.message(c, true)
+}
+
+// streamResponse sends the Messages API events of a completion as event stream chunks, their JSON
+// base64 encoded in the bytes field. message_stop carries the invocation metrics, as on Bedrock.
+func (bedrockDialect) streamResponse(stream *sseWriter, c canonicalCompletion) bool {
+	stream.w.Header().Set("Content-Type", eventStreamContentType)
+	events := anthropicDialect{}.events(c)
+	events[len(events)-1].data["amazon-bedrock-invocationMetrics"] = map[string]int{
+		"inputTokenCount":  c.InputTokens,
+		"outputTokenCount": c.OutputTokens,
+	}
+	for _, e := range events {
+		data, err := json.Marshal(e.data)
+		if err != nil {
+			stream.err = err
+			return false
+		}
+		payload, _ := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString(data)})
+		frame := encodeEventStreamMessage([][2]string{
+			{":event-type", "chunk"},
+			{":content-type", "application/json"},
+			{":message-type", "event"},
+		}, payload)
+		if !stream.write(string(frame)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (bedrockDialect) writeError(w http.ResponseWriter, e *APIError) {
+	errorType, ok := bedrockErrorTypes[e.StatusCode]
+	if !ok {
+		errorType = "ValidationException"
+		if e.StatusCode >= 500 {
+			errorType = "InternalServerException"
+		}
+	}
+	w.Header().Set("X-Amzn-ErrorType", errorType+":http://internal.amazon.com/coral/com.amazon.bedrock/")
+	writeJSON(w, e.StatusCode, map[string]string{"message": e.Detail.Message})
+}
+
+// encodeEventStreamMessage encodes a message of the AWS event stream encoding: a prelude of the total
+// and header lengths and its CRC32, the string headers, the payload, and the CRC32 of all of it.
+func encodeEventStreamMessage(headers [][2]string, payload []byte) []byte {
+	var h bytes.Buffer
+	for _, header := range headers {
+		h.WriteByte(byte(len(header[0])))
+		h.WriteString(header[0])
+		h.WriteByte(eventStreamStringHeader)
+		_ = binary.Write(&h, binary.BigEndian, uint16(len(header[1])))
+		h.WriteString(header[1])
+	}
+	var m bytes.Buffer
+	_ = binary.Write(&m, binary.BigEndian, uint32(12+h.Len()+len(payload)+4))
+	_ = binary.Write(&m, binary.BigEndian, uint32(h.Len()))
+	_ = binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	m.Write(h.Bytes())
+	m.Write(payload)
+	_ = binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	return m.Bytes()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"testing"
+)
+
+// readEventStreamMessage decodes the next message of an AWS event stream, verifying its checksums.
+func readEventStreamMessage(t *testing.T, r io.Reader) (map[string]string, []byte, bool) {
+	t.Helper()
+	prelude := make([]byte, 12)
+	if _, err := io.ReadFull(r, prelude); err == io.EOF {
+		return nil, nil, false
+	} else if err != nil {
+		t.Fatalf("reading prelude: %v", err)
+	}
+	total, headersLen := binary.BigEndian.Uint32(prelude[0:4]), binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:8]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		t.Fatal("prelude checksum mismatch")
+	}
+	rest := make([]byte, total-12)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		t.Fatalf("reading message: %v", err)
+	}
+	message := append(prelude, rest...)
+	if crc32.ChecksumIEEE(message[:total-4]) != binary.BigEndian.Uint32(message[total-4:]) {
+		t.Fatal("message checksum mismatch")
+	}
+	headers := map[string]string{}
+	h := bytes.NewReader(rest[:headersLen])
+	for h.Len() > 0 {
+		nameLen, _ := h.ReadByte()
+		name := make([]byte, nameLen)
+		_, _ = io.ReadFull(h, name)
+		if valueType, _ := h.ReadByte(); valueType != eventStreamStringHeader {
+			t.Fatalf("unexpected header value type %d", valueType)
+		}
+		var valueLen uint16
+		_ = binary.Read(h, binary.BigEndian, &valueLen)
+		value := make([]byte, valueLen)
+		_, _ = io.ReadFull(h, value)
+		headers[string(name)] = string(value)
+	}
+	return headers, rest[headersLen : len(rest)-4], true
+}
+
+// --- encodeEventStreamMessage ---
+
+func TestEncodeEventStreamMessage_RoundTrips(t *testing.T) {
+	// Given
+	frame := encodeEventStreamMessage([][2]string{{":event-type", "chunk"}, {":message-type", "event"}}, []byte(`{"bytes":"e30="}`))
+
+	// When
+	headers, payload, ok := readEventStreamMessage(t, bytes.NewReader(frame))
+
+	// Then
+	if !ok || headers[":event-type"] != "chunk" || headers[":message-type"] != "event" || string(payload) != `{"bytes":"e30="}` {
+		t.Errorf("unexpected message %v %s", headers, payload)
+	}
+}
+
+// --- bedrockDialect ---
+
+func TestBedrockDialect_DecodeRequest(t *testing.T) {
+	// Given
+	body := []byte(`{"anthropic_version":"bedrock-2023-05-31","max_tokens":10,"messages":[{"role":"user","content":"hi"}]}`)
+
+	// When
+	req, err := bedrockDialect{stream: true}.decodeRequest(body, "anthropic.claude-3-haiku-20240307-v1:0")
+	_, missingVersion := bedrockDialect{}.decodeRequest([]byte(`{"max_tokens":10,"messages":[{"role":"user","content":"hi"}]}`), "anthropic.claude-3-haiku-20240307-v1:0")
+
+	// Then: the model comes from the path and the stream from the operation
+	if err != nil || req.Model != "anthropic.claude-3-haiku-20240307-v1:0" || !req.Stream || req.MaxTokens != 10 {
+		t.Errorf("unexpected request %+v, %v", req, err)
+	}
+	if missingVersion == nil {
+		t.Error("expected an error without anthropic_version")
+	}
+}
@@ -0,0 +1,32 @@
+//go:build !mokku_minimal
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// buildVariant is the variant of this build: "full", or "minimal" with the mokku_minimal build tag
+// (see build_minimal.go).
+const buildVariant = "full"
+
+// minimalBuild reports whether this is the minimal build, which leaves out the OTLP exporter, the
+// TUI, and the provider dialects.
+const minimalBuild = false
+
+// newSpanExporter creates the OTLP exporter spans are sent to, at OTEL_EXPORTER_OTLP_ENDPOINT
+// (jaeger:4317 by default).
+func newSpanExporter(ctx context.Context) (sdktrace.SpanExporter, error) {
+	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if otlpEndpoint == "" {
+		otlpEndpoint = "jaeger:4317"
+	}
+	return otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(otlpEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+}
@@ -0,0 +1,36 @@
+//go:build mokku_minimal
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// buildVariant is the variant of this build: "minimal", built with the mokku_minimal build tag for
+// size-constrained test images.
+const buildVariant = "minimal"
+
+// minimalBuild reports whether this is the minimal build, which leaves out the OTLP exporter (and
+// the gRPC stack it pulls in), the TUI, and the provider dialects.
+const minimalBuild = true
+
+// newSpanExporter returns no exporter: spans are still recorded, so traceparent headers, captured
+// trace IDs, and debug bundles work, but they are not sent anywhere.
+func newSpanExporter(context.Context) (sdktrace.SpanExporter, error) {
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); endpoint != "" {
+		log.Printf("OTEL_EXPORTER_OTLP_ENDPOINT=%s is ignored: the minimal build does not export spans", endpoint)
+	}
+	return nil, nil
+}
+
+// runTUI reports that the TUI is not part of the minimal build.
+func runTUI(_ []string, _, stderr io.Writer) int {
+	_, _ = fmt.Fprintln(stderr, "tui: not available in the minimal build; use the full binary")
+	return 2
+}
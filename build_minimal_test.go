@@ -0,0 +1,45 @@
+//go:build mokku_minimal
+
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+)
+
+// --- minimal build ---
+
+func TestMinimalBuild_ServesNoProviderDialects(t *testing.T) {
+	// Given: the provider dialects flag enabled
+	srv := newTestServerWithConfig(t, Config{Features: map[string]bool{flagProviderDialects: true}})
+	defer srv.Close()
+
+	// When: the Anthropic surface is called
+	resp := postJSON(t, srv.URL+"/v1/messages", `{"model":"claude-sonnet-4-5","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}`)
+	_ = resp.Body.Close()
+
+	// Then: it is not served, and the capabilities name the minimal build
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 in the minimal build, got %d", resp.StatusCode)
+	}
+	caps, err := http.Get(srv.URL + "/_mokku/capabilities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = caps.Body.Close() }()
+	if got := mustDecodeJSON(t, caps.Body)["build"]; got != "minimal" {
+		t.Errorf("expected the minimal build, got %v", got)
+	}
+}
+
+func TestMinimalBuild_TUIIsNotAvailable(t *testing.T) {
+	// Given/When: the tui subcommand is run
+	var stdout, stderr bytes.Buffer
+	code := runTUI(nil, &stdout, &stderr)
+
+	// Then: it fails with a pointer to the full binary
+	if code != 2 || !bytes.Contains(stderr.Bytes(), []byte("full binary")) {
+		t.Errorf("expected exit code 2 and a message, got %d %q", code, stderr.String())
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+)
+
+// maxBundleStreamBytes is the size of the full body of a spilled stream included in a debug bundle.
+const maxBundleStreamBytes = 4 << 20
+
+// faultSteps are the request steps that change the outcome of a request: the faults injected by
+// chaos profiles, regional outages, and baggage overrides, the rejections of moderation, rate
+// limits, and disabled endpoints, and cold-start waits.
+var faultSteps = map[string]bool{
+	"Baggage.override":       true,
+	"Chaos.fault":            true,
+	"Chaos.truncate":         true,
+	"ColdStart.wait":         true,
+	"Fidelity.disabled":      true,
+	"Moderation.blockedUser": true,
+	"Moderation.flagged":     true,
+	"RateLimit.exceeded":     true,
+	"Region.outage":          true,
+}
+
+// requestBundle is everything mokku knows about a captured request, returned by GET
+// /_mokku/requests/{id}/bundle as one document to attach to a bug report: the captured request and
+// response with its chunk timings and trace, the scenario matched, the faults injected, and the full
+// body of a spilled stream.
+type requestBundle struct {
+	Object string `json:"object"`
+	// Instance is the mokku instance that served the request.
+	Instance string `json:"instance"`
+	capturedExchange
+	// Scenario is the name of the scenario rule matched, if any.
+	Scenario string `json:"scenario,omitempty"`
+	// Faults are the steps of Trace that injected a fault or rejected the request.
+	Faults []requestStep `json:"faults"`
+	// StreamBody is the full body of a stream spilled to BodyFile, cut to maxBundleStreamBytes.
+	StreamBody          string `json:"stream_body,omitempty"`
+	StreamBodyTruncated bool   `json:"stream_body_truncated,omitempty"`
+}
+
+// newRequestBundle assembles the debug bundle of a captured request.
+func newRequestBundle(instance string, e capturedExchange) requestBundle {
+	b := requestBundle{Object: "mokku.request_bundle", Instance: instance, capturedExchange: e, Faults: []requestStep{}}
+	if e.Trace != nil {
+		for _, step := range e.Trace.Steps {
+			switch {
+			case step.Name == "Scenario.matched":
+				b.Scenario = step.Attributes["scenario.name"]
+			case faultSteps[step.Name]:
+				b.Faults = append(b.Faults, step)
+			}
+		}
+	}
+	if e.Response.BodyFile != "" {
+		if f, err := os.Open(e.Response.BodyFile); err == nil {
+			data, _ := io.ReadAll(io.LimitReader(f, maxBundleStreamBytes+1))
+			_ = f.Close()
+			b.StreamBodyTruncated = len(data) > maxBundleStreamBytes
+			b.StreamBody = string(data[:min(len(data), maxBundleStreamBytes)])
+		}
+	}
+	return b
+}
+
+// handleGetRequestBundle returns the debug bundle of a captured request.
+func (h *AdminHandler) handleGetRequestBundle(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	e, ok := h.capture.Get(id)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, newRequestBundle(h.instanceID, e))
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cadenceConfig is the streaming cadence of a model family in the streaming_cadence section of the
+// config file. A family named like a built-in one overrides its non-zero fields; other families are
+// matched before the built-in ones.
+type cadenceConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Models are the glob patterns (path.Match syntax) of the model IDs in the family.
+	Models []string `yaml:"models" json:"models"`
+	// TimeToFirstToken is the wait before the first content chunk.
+	TimeToFirstToken string  `yaml:"time_to_first_token" json:"time_to_first_token"`
+	TokensPerSecond  float64 `yaml:"tokens_per_second" json:"tokens_per_second"`
+	// ChunkTokens is the number of tokens sent in each chunk: the n of the n-grams the content is
+	// streamed in.
+	ChunkTokens int `yaml:"chunk_tokens" json:"chunk_tokens"`
+}
+
+// builtinCadences are the built-in model families, matched in order: reasoning models think before
+// answering and stream slowly, mini models answer fast in larger chunks, and every other model
+// streams like a standard one.
+var builtinCadences = []cadenceConfig{
+	{Name: "reasoning", Models: []string{"o1*", "o3*", "o4*", "gpt-5*"}, TimeToFirstToken: "2s", TokensPerSecond: 50, ChunkTokens: 1},
+	{Name: "mini", Models: []string{"*-mini*", "*-nano*"}, TimeToFirstToken: "200ms", TokensPerSecond: 150, ChunkTokens: 3},
+	{Name: "standard", Models: []string{"*"}, TimeToFirstToken: "500ms", TokensPerSecond: 80, ChunkTokens: 1},
+}
+
+// streamCadence is a compiled cadenceConfig.
+type streamCadence struct {
+	family           string
+	models           []string
+	timeToFirstToken time.Duration
+	tokensPerSecond  float64
+	chunkTokens      int
+}
+
+// cadenceTable holds the streaming cadences of model families, so the speed of streamed responses
+// can be compared between model choices in staging. It is safe for concurrent use and can be
+// replaced on config reload.
+type cadenceTable struct {
+	mu       sync.RWMutex
+	families []streamCadence
+}
+
+// newCadenceTable creates a table from builtinCadences and the configured families.
+func newCadenceTable(configured []cadenceConfig) (*cadenceTable, error) {
+	t := &cadenceTable{}
+	if err := t.Load(configured); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Load replaces the table with builtinCadences merged with the configured families. On error the
+// current table is kept.
+func (t *cadenceTable) Load(configured []cadenceConfig) error {
+	return installNow(t.prepare(configured))
+}
+
+// prepare validates the configured families and returns the function installing the table.
+func (t *cadenceTable) prepare(configured []cadenceConfig) (func(), error) {
+	builtins := make([]cadenceConfig, len(builtinCadences))
+	copy(builtins, builtinCadences)
+	var added []cadenceConfig
+	for _, c := range configured {
+		if c.Name == "" {
+			return nil, fmt.Errorf("streaming_cadence: family without name")
+		}
+		i := -1
+		for j, b := range builtins {
+			if b.Name == c.Name {
+				i = j
+			}
+		}
+		if i < 0 {
+			added = append(added, c)
+			continue
+		}
+		builtins[i] = mergeCadenceConfig(builtins[i], c)
+	}
+	families := make([]streamCadence, 0, len(added)+len(builtins))
+	for _, c := range append(added, builtins...) {
+		cadence, err := compileCadence(c)
+		if err != nil {
+			return nil, fmt.Errorf("streaming_cadence.%s: %w", c.Name, err)
+		}
+		families = append(families, cadence)
+	}
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.families = families
+	}, nil
+}
+
+// mergeCadenceConfig returns base with the non-zero fields of override applied.
+func mergeCadenceConfig(base, override cadenceConfig) cadenceConfig {
+	if len(override.Models) > 0 {
+		base.Models = override.Models
+	}
+	if override.TimeToFirstToken != "" {
+		base.TimeToFirstToken = override.TimeToFirstToken
+	}
+	if override.TokensPerSecond > 0 {
+		base.TokensPerSecond = override.TokensPerSecond
+	}
+	if override.ChunkTokens > 0 {
+		base.ChunkTokens = override.ChunkTokens
+	}
+	return base
+}
+
+// compileCadence validates the cadence of a family.
+func compileCadence(c cadenceConfig) (streamCadence, error) {
+	cadence := streamCadence{family: c.Name, models: c.Models, tokensPerSecond: c.TokensPerSecond, chunkTokens: c.ChunkTokens}
+	if len(c.Models) == 0 {
+		return cadence, fmt.Errorf("models is required")
+	}
+	for _, pattern := range c.Models {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return cadence, fmt.Errorf("invalid model pattern %q", pattern)
+		}
+	}
+	if c.TimeToFirstToken != "" {
+		d, err := time.ParseDuration(c.TimeToFirstToken)
+		if err != nil || d < 0 {
+			return cadence, fmt.Errorf("time_to_first_token must be a duration such as 500ms, got %q", c.TimeToFirstToken)
+		}
+		cadence.timeToFirstToken = d
+	}
+	if c.TokensPerSecond <= 0 {
+		return cadence, fmt.Errorf("tokens_per_second must be positive")
+	}
+	if cadence.chunkTokens <= 0 {
+		cadence.chunkTokens = 1
+	}
+	return cadence, nil
+}
+
+// For returns the cadence of the first family a model belongs to.
+func (t *cadenceTable) For(model string) (streamCadence, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, family := range t.families {
+		for _, pattern := range family.models {
+			if ok, _ := path.Match(pattern, model); ok {
+				return family, true
+			}
+		}
+	}
+	return streamCadence{}, false
+}
+
+// streamPacer paces the content chunks of a streamed response and measures the rate they were
+// sent at. Without a cadence, content is sent as it is generated, without waiting. The pause markers
+// of scenario content are waited for at their position either way.
+type streamPacer struct {
+	ctx context.Context
+	// cadence is nil when the stream is not paced.
+	cadence    *streamCadence
+	start      time.Time
+	tokens     int
+	throughput *streamThroughput
+	// pauses are those of the scenario content streamed; offset is the bytes of the current content sent and
+	// next the first pause not waited for. paused is the time waited for pauses, which delays the
+	// chunks after them.
+	pauses []contentPause
+	offset int
+	next   int
+	paused time.Duration
+}
+
+// newStreamPacer creates the pacer of a stream for model: paced at its family's cadence with the
+// realistic_streaming flag, unpaced otherwise.
+func (h *StreamingHandler) newStreamPacer(ctx context.Context, model string) *streamPacer {
+	p := &streamPacer{ctx: ctx, start: time.Now(), throughput: streamThroughputFromContext(ctx)}
+	if h.flags.Enabled(flagRealisticStreaming) {
+		if cadence, ok := h.cadence.For(model); ok {
+			p.cadence = &cadence
+		}
+	}
+	return p
+}
+
+// split cuts content into the chunks it is streamed in: chunkTokens tokens each when paced, or
+// pieces when not, cut again at the pauses. It starts a new content, whose pauses are waited for
+// again.
+func (p *streamPacer) split(content string, pieces []string) []string {
+	p.offset, p.next = 0, 0
+	chunks := pieces
+	if p.cadence != nil {
+		tokens := splitTokens(content)
+		chunks = make([]string, 0, (len(tokens)+p.cadence.chunkTokens-1)/p.cadence.chunkTokens)
+		for i := 0; i < len(tokens); i += p.cadence.chunkTokens {
+			chunks = append(chunks, strings.Join(tokens[i:min(i+p.cadence.chunkTokens, len(tokens))], ""))
+		}
+	}
+	if len(p.pauses) == 0 {
+		return chunks
+	}
+	return cutAtPauses(chunks, p.pauses)
+}
+
+// wait waits until chunk is due: the pauses at its position, then the time to first token after the
+// start of the stream and the time the tokens before it take at the family's rate, both shifted by
+// the pauses. It records the chunk as sent.
+func (p *streamPacer) wait(chunk string) {
+	p.pause(p.offset)
+	if p.cadence != nil {
+		due := p.paused + p.cadence.timeToFirstToken + time.Duration(float64(p.tokens)/p.cadence.tokensPerSecond*float64(time.Second))
+		waitLatency(p.ctx, time.Until(p.start.Add(due)))
+	}
+	p.offset += len(chunk)
+	tokens := countTokens(chunk)
+	p.tokens += tokens
+	p.throughput.sent(tokens)
+}
+
+// finish waits for the pauses at the end of the content, or past it when it was cut.
+func (p *streamPacer) finish() {
+	p.pause(math.MaxInt)
+}
+
+// pause waits for the pauses not waited for up to offset.
+func (p *streamPacer) pause(offset int) {
+	var delay time.Duration
+	for ; p.next < len(p.pauses) && p.pauses[p.next].Offset <= offset; p.next++ {
+		delay += p.pauses[p.next].Delay
+	}
+	if delay > 0 {
+		waitLatency(p.ctx, delay)
+		p.paused += delay
+	}
+}
+
+// streamThroughput measures the content tokens of a streamed response and when they were sent.
+// It is safe for concurrent use; a nil throughput measures nothing.
+type streamThroughput struct {
+	mu     sync.Mutex
+	tokens int
+	// lastTokens is the size of the last chunk.
+	lastTokens  int
+	first, last time.Time
+}
+
+// sent records a content chunk of tokens sent now.
+func (t *streamThroughput) sent(tokens int) {
+	if t == nil || tokens == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	if t.tokens == 0 {
+		t.first = now
+	}
+	t.tokens += tokens
+	t.last, t.lastTokens = now, tokens
+}
+
+// snapshot returns the tokens sent, the time the first was sent, and the rate tokens were sent at
+// from the first chunk to the last: the tokens before the last chunk over the time between them
+// (0 when every token came in one chunk).
+func (t *streamThroughput) snapshot() (tokens int, first time.Time, tokensPerSecond float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if elapsed := t.last.Sub(t.first); elapsed > 0 {
+		tokensPerSecond = float64(t.tokens-t.lastTokens) / elapsed.Seconds()
+	}
+	return t.tokens, t.first, tokensPerSecond
+}
+
+type streamThroughputContextKey struct{}
+
+// withStreamThroughput stores the throughput measured for a request in the context.
+func withStreamThroughput(ctx context.Context, t *streamThroughput) context.Context {
+	return context.WithValue(ctx, streamThroughputContextKey{}, t)
+}
+
+// streamThroughputFromContext returns the throughput stored by withStreamThroughput, or nil.
+func streamThroughputFromContext(ctx context.Context) *streamThroughput {
+	t, _ := ctx.Value(streamThroughputContextKey{}).(*streamThroughput)
+	return t
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// --- cadenceTable.For ---
+
+func TestCadenceTable_BuiltinFamilies(t *testing.T) {
+	// Given
+	table, err := newCadenceTable(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{"o3-mini": "reasoning", "gpt-5": "reasoning", "gpt-4o-mini": "mini", "gpt-4.1-nano": "mini", "gpt-4o": "standard", "mokku-echo-1": "standard"}
+	for model, want := range cases {
+		// When
+		cadence, ok := table.For(model)
+		// Then
+		if !ok || cadence.family != want {
+			t.Errorf("%s: expected the %s family, got %+v", model, want, cadence)
+		}
+	}
+}
+
+func TestCadenceTable_ConfiguredFamiliesOverrideAndComeFirst(t *testing.T) {
+	// Given: a faster mini family and a family for fine-tuned models
+	table, err := newCadenceTable([]cadenceConfig{
+		{Name: "mini", TokensPerSecond: 400},
+		{Name: "fine-tuned", Models: []string{"ft:*"}, TimeToFirstToken: "50ms", TokensPerSecond: 90, ChunkTokens: 2},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	mini, _ := table.For("gpt-4o-mini")
+	tuned, _ := table.For("ft:gpt-4o-mini:acme")
+
+	// Then: mini keeps its built-in fields, and the fine-tuned family wins over mini
+	if mini.tokensPerSecond != 400 || mini.chunkTokens != 3 || mini.timeToFirstToken != 200*time.Millisecond {
+		t.Errorf("unexpected mini cadence %+v", mini)
+	}
+	if tuned.family != "fine-tuned" || tuned.chunkTokens != 2 || tuned.timeToFirstToken != 50*time.Millisecond {
+		t.Errorf("unexpected fine-tuned cadence %+v", tuned)
+	}
+}
+
+func TestNewCadenceTable_InvalidFamilyIsError(t *testing.T) {
+	cases := map[string]cadenceConfig{
+		"no name":        {Models: []string{"*"}, TokensPerSecond: 10},
+		"no models":      {Name: "x", TokensPerSecond: 10},
+		"bad pattern":    {Name: "x", Models: []string{"["}, TokensPerSecond: 10},
+		"bad ttft":       {Name: "x", Models: []string{"*"}, TimeToFirstToken: "soon", TokensPerSecond: 10},
+		"no token speed": {Name: "x", Models: []string{"*"}},
+	}
+	for name, c := range cases {
+		// When
+		_, err := newCadenceTable([]cadenceConfig{c})
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// --- streamPacer ---
+
+func TestStreamPacer_SplitsIntoNGramsAndPaces(t *testing.T) {
+	// Given: 2-token chunks 20ms after the start, at 100 tokens per second
+	throughput := &streamThroughput{}
+	p := &streamPacer{
+		ctx:        withStreamThroughput(context.Background(), throughput),
+		cadence:    &streamCadence{timeToFirstToken: 20 * time.Millisecond, tokensPerSecond: 100, chunkTokens: 2},
+		start:      time.Now(),
+		throughput: throughput,
+	}
+
+	// When
+	chunks := p.split("a b c d e", nil)
+	for _, chunk := range chunks {
+		p.wait(chunk)
+	}
+
+	// Then: 3 chunks sent 20ms apart after the first, at about 100 tokens per second
+	if len(chunks) != 3 || chunks[0] != "a b" || chunks[2] != " e" {
+		t.Fatalf("unexpected chunks %q", chunks)
+	}
+	tokens, first, rate := throughput.snapshot()
+	if tokens != 5 || time.Since(first) < 0 || rate < 60 || rate > 110 {
+		t.Errorf("expected 5 tokens at about 100 tokens per second, got %d at %.1f", tokens, rate)
+	}
+	if elapsed := time.Since(p.start); elapsed < 60*time.Millisecond {
+		t.Errorf("expected the stream to take 60ms, took %s", elapsed)
+	}
+}
+
+func TestStreamPacer_WaitsForPausesAtTheirPosition(t *testing.T) {
+	// Given: an unpaced stream pausing 50ms after "Hello," and 30ms at the end
+	throughput := &streamThroughput{}
+	p := &streamPacer{
+		ctx:        withStreamThroughput(context.Background(), throughput),
+		start:      time.Now(),
+		throughput: throughput,
+		pauses:     []contentPause{{Offset: 6, Delay: 50 * time.Millisecond}, {Offset: 12, Delay: 30 * time.Millisecond}},
+	}
+
+	// When
+	chunks := p.split("Hello, world", []string{"Hello, world"})
+	p.wait(chunks[0])
+	beforePause := time.Since(p.start)
+	for _, chunk := range chunks[1:] {
+		p.wait(chunk)
+	}
+	afterPause := time.Since(p.start)
+	p.finish()
+
+	// Then: the content is cut at the pause, which is waited for before the second chunk
+	if len(chunks) != 2 || chunks[0] != "Hello," || chunks[1] != " world" {
+		t.Fatalf("unexpected chunks %q", chunks)
+	}
+	if beforePause >= 50*time.Millisecond || afterPause < 50*time.Millisecond {
+		t.Errorf("expected the pause between the chunks, got %s and %s", beforePause, afterPause)
+	}
+	if elapsed := time.Since(p.start); elapsed < 80*time.Millisecond || p.paused != 80*time.Millisecond {
+		t.Errorf("expected both pauses to be waited for, took %s (%s paused)", elapsed, p.paused)
+	}
+}
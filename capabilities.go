@@ -0,0 +1,127 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-faster/yaml"
+)
+
+// serviceVersion is the version of this mokku build.
+const serviceVersion = "1.0.0"
+
+// openAPISpecYAML is the OpenAPI spec the API server is generated from.
+//
+//go:embed openapi.yml
+var openAPISpecYAML []byte
+
+// streamingPaths are the API paths that support stream: true.
+var streamingPaths = map[string]bool{
+	"/v1/chat/completions": true,
+	"/v1/completions":      true,
+}
+
+// magicModels are the model names that trigger special mock behavior.
+var magicModels = []string{CreditErrorModelName, LoremModelName}
+
+// endpointInfo describes a served endpoint.
+type endpointInfo struct {
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	Streaming bool   `json:"streaming,omitempty"`
+}
+
+// capabilitiesResponse is the response body for GET /_mokku/capabilities
+type capabilitiesResponse struct {
+	Object  string `json:"object"`
+	Version string `json:"version"`
+	// Build is the build variant: "full", or "minimal" without provider dialects.
+	Build            string          `json:"build"`
+	SpecVersion      string          `json:"spec_version"`
+	Instance         string          `json:"instance"`
+	Endpoints        []endpointInfo  `json:"endpoints"`
+	ControlEndpoints []endpointInfo  `json:"control_endpoints"`
+	CompatModes      []string        `json:"compat_modes"`
+	MagicModels      []string        `json:"magic_models"`
+	FeatureFlags     map[string]bool `json:"feature_flags"`
+	// Seed is the global seed request seeds are derived from (MOKKU_SEED).
+	Seed uint64 `json:"seed"`
+}
+
+// openAPISpec is the subset of the OpenAPI spec needed to describe the served API.
+type openAPISpec struct {
+	Info struct {
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]map[string]any `yaml:"paths"`
+}
+
+// loadOpenAPISpec parses the embedded OpenAPI spec once.
+var loadOpenAPISpec = sync.OnceValues(func() (openAPISpec, error) {
+	var spec openAPISpec
+	if err := yaml.Unmarshal(openAPISpecYAML, &spec); err != nil {
+		return spec, fmt.Errorf("failed to parse embedded OpenAPI spec: %w", err)
+	}
+	return spec, nil
+})
+
+// specEndpoints lists the API endpoints declared in the spec, sorted by path and method.
+func specEndpoints(spec openAPISpec) []endpointInfo {
+	prefix := ""
+	if len(spec.Servers) > 0 {
+		prefix = spec.Servers[0].URL
+	}
+	var endpoints []endpointInfo
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			method = strings.ToUpper(method)
+			switch method {
+			case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				continue
+			}
+			endpoints = append(endpoints, endpointInfo{
+				Method:    method,
+				Path:      prefix + path,
+				Streaming: streamingPaths[prefix+path],
+			})
+		}
+	}
+	sortEndpoints(endpoints)
+	return endpoints
+}
+
+// sortEndpoints sorts endpoints by path, then method.
+func sortEndpoints(endpoints []endpointInfo) {
+	sort.Slice(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+}
+
+// logStartupBanner logs what this instance serves.
+func logStartupBanner(addr string, caps capabilitiesResponse) {
+	log.Printf("openai-mokku %s (OpenAPI spec %s), instance %s, listening on %s",
+		caps.Version, caps.SpecVersion, caps.Instance, addr)
+	for _, e := range caps.Endpoints {
+		streaming := ""
+		if e.Streaming {
+			streaming = " (streaming)"
+		}
+		log.Printf("  %-6s %s%s", e.Method, e.Path, streaming)
+	}
+	log.Printf("  %d control endpoints under %s, see GET %s/capabilities",
+		len(caps.ControlEndpoints), adminPathPrefix, adminPathPrefix)
+	log.Printf("  magic models: %s", strings.Join(caps.MagicModels, ", "))
+	log.Printf("  seed: %d (set MOKKU_SEED=%d to repeat this run)", caps.Seed, caps.Seed)
+}
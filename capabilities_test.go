@@ -0,0 +1,94 @@
+package main
+
+import "testing"
+
+// --- loadOpenAPISpec ---
+
+func TestLoadOpenAPISpec_ParsesEmbeddedSpec(t *testing.T) {
+	// When
+	spec, err := loadOpenAPISpec()
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.Info.Version == "" {
+		t.Error("expected a spec version")
+	}
+	if len(spec.Paths) == 0 {
+		t.Error("expected paths")
+	}
+}
+
+// --- specEndpoints ---
+
+func TestSpecEndpoints_PrefixesServerURLAndMarksStreaming(t *testing.T) {
+	// Given
+	spec, err := loadOpenAPISpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// When
+	endpoints := specEndpoints(spec)
+	// Then: chat completions is listed under /v1 as a streaming endpoint, models is not streaming
+	found := map[string]endpointInfo{}
+	for _, e := range endpoints {
+		found[e.Method+" "+e.Path] = e
+	}
+	chat, ok := found["POST /v1/chat/completions"]
+	if !ok || !chat.Streaming {
+		t.Errorf("expected streaming POST /v1/chat/completions, got %v", endpoints)
+	}
+	models, ok := found["GET /v1/models"]
+	if !ok || models.Streaming {
+		t.Errorf("expected non-streaming GET /v1/models, got %v", endpoints)
+	}
+}
+
+func TestSpecEndpoints_SortedByPathThenMethod(t *testing.T) {
+	// Given
+	spec, err := loadOpenAPISpec()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// When
+	endpoints := specEndpoints(spec)
+	// Then
+	for i := 1; i < len(endpoints); i++ {
+		prev, cur := endpoints[i-1], endpoints[i]
+		if prev.Path > cur.Path || (prev.Path == cur.Path && prev.Method > cur.Method) {
+			t.Errorf("endpoints out of order at %d: %v before %v", i, prev, cur)
+		}
+	}
+}
+
+// --- AdminHandler.Capabilities ---
+
+func TestCapabilities_ListsControlEndpoints(t *testing.T) {
+	// Given
+	state, err := newServerState(Config{}, serverOptions{Seed: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminHandler(state, "replica-1")
+	// When
+	caps, err := h.Capabilities()
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if caps.Instance != "replica-1" || caps.Version != serviceVersion || caps.Build != buildVariant || caps.Seed != 7 {
+		t.Errorf("unexpected identity: %+v", caps)
+	}
+	if enabled, ok := caps.FeatureFlags[flagStrictModelValidation]; !ok || enabled {
+		t.Errorf("expected strict_model_validation reported as off, got %v", caps.FeatureFlags)
+	}
+	found := false
+	for _, e := range caps.ControlEndpoints {
+		if e.Method == "GET" && e.Path == "/_mokku/capabilities" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the capabilities endpoint itself to be listed, got %v", caps.ControlEndpoints)
+	}
+}
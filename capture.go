@@ -0,0 +1,680 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultCaptureSize is the number of API requests kept for verification unless MOKKU_CAPTURE_SIZE is set.
+const defaultCaptureSize = 1000
+
+// defaultCaptureMaxBytes is the memory captured requests may take unless MOKKU_CAPTURE_MAX_BYTES is set.
+const defaultCaptureMaxBytes = 64 << 20
+
+// Eviction policies of the request capture.
+const (
+	// captureEvictOldest evicts the earliest captured request first.
+	captureEvictOldest = "oldest"
+	// captureEvictLRU evicts the least recently captured or fetched (GET /_mokku/requests/{id})
+	// request first, so requests a test is inspecting stay available.
+	captureEvictLRU = "lru"
+)
+
+// maxCapturedBodyBytes is the number of request and response body bytes kept per captured request.
+const maxCapturedBodyBytes = 256 * 1024
+
+// defaultCaptureStreamPreview is the number of streamed response bytes kept per captured request
+// unless MOKKU_CAPTURE_STREAM_PREVIEW is set.
+const defaultCaptureStreamPreview = 16 * 1024
+
+// maxCapturedChunkTimings is the number of chunks of a streamed response whose timing is kept.
+const maxCapturedChunkTimings = 1000
+
+// capturedExchange is an API request and the response mokku sent. The request fields are those of
+// a replay-load traffic log line.
+type capturedExchange struct {
+	ID int64 `json:"id"`
+	// RequestID is the request ID of the watermark of the response, if watermarking is on.
+	RequestID string `json:"request_id,omitempty"`
+	capturedRequest
+	Model  string `json:"model,omitempty"`
+	Stream bool   `json:"stream"`
+	// endUser is the end-user the request was made for (safety_identifier, user).
+	endUser
+	// Seed is the seed the request's randomized behavior was drawn from.
+	Seed uint64 `json:"seed"`
+	// RemoteAddr is the client address, resolved through trusted proxies.
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// Tags are the tags the request was classified with.
+	Tags []string `json:"tags,omitempty"`
+	// Connection is the client connection the request arrived on.
+	Connection *connectionRequest `json:"connection,omitempty"`
+	Response   capturedResponse   `json:"response"`
+	// Chunks is the timing of a streamed response.
+	Chunks *capturedChunks `json:"chunks,omitempty"`
+	// Cancellation is where a streamed response was cut off, if it was.
+	Cancellation *capturedCancellation `json:"cancellation,omitempty"`
+	// DurationMS is the time until the response was complete, including streaming, and
+	// InjectedLatencyMS the part of it that was simulated latency.
+	DurationMS        int64   `json:"duration_ms"`
+	InjectedLatencyMS float64 `json:"injected_latency_ms,omitempty"`
+	// Trace is the tracing state of the request: its trace and span IDs and the steps taken before
+	// the operation, such as the scenario matched and the faults injected.
+	Trace *capturedTrace `json:"trace,omitempty"`
+	// Truncated reports whether a body exceeded maxCapturedBodyBytes.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// capturedResponse is the response to a captured request. Body is the JSON document, or a string
+// holding the raw body (a preview of the server-sent events of a streaming response) when it is not
+// JSON. BodyFile is the file the full body of a stream was spilled to.
+type capturedResponse struct {
+	Status   int               `json:"status"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Body     json.RawMessage   `json:"body,omitempty"`
+	BodyFile string            `json:"body_file,omitempty"`
+}
+
+// capturedChunks is the timing of the chunks (server-sent events) of a streamed response. Offsets
+// are milliseconds since the request arrived.
+type capturedChunks struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+	// Timings lists the first maxCapturedChunkTimings chunks; TimingsTruncated reports more.
+	Timings          []chunkTiming `json:"timings"`
+	TimingsTruncated bool          `json:"timings_truncated,omitempty"`
+	// Tokens is the number of content tokens streamed, TimeToFirstTokenMS the offset of the first,
+	// and TokensPerSecond the rate they were streamed at from the first chunk to the last.
+	Tokens             int     `json:"tokens,omitempty"`
+	TimeToFirstTokenMS float64 `json:"time_to_first_token_ms,omitempty"`
+	TokensPerSecond    float64 `json:"tokens_per_second,omitempty"`
+}
+
+// capturedCancellation is where a captured stream was cut off: the reason, as in GET
+// /_mokku/streams, and the events and bytes sent before.
+type capturedCancellation struct {
+	Reason     string `json:"reason"`
+	ChunksSent int    `json:"chunks_sent"`
+	BytesSent  int    `json:"bytes_sent"`
+}
+
+type capturedCancellationContextKey struct{}
+
+// withCapturedCancellation stores in the context where the cancellation of a captured stream is
+// recorded.
+func withCapturedCancellation(ctx context.Context, c **capturedCancellation) context.Context {
+	return context.WithValue(ctx, capturedCancellationContextKey{}, c)
+}
+
+// recordCapturedCancellation records the cancellation of the stream of a captured request. It does
+// nothing if the request is not captured.
+func recordCapturedCancellation(ctx context.Context, c streamCancellation) {
+	if slot, ok := ctx.Value(capturedCancellationContextKey{}).(**capturedCancellation); ok {
+		*slot = &capturedCancellation{Reason: c.Reason, ChunksSent: c.ChunksSent, BytesSent: c.BytesSent}
+	}
+}
+
+// chunkTiming is the arrival of one chunk of a streamed response.
+type chunkTiming struct {
+	OffsetMS float64 `json:"offset_ms"`
+	Bytes    int     `json:"bytes"`
+}
+
+// captureFilter selects captured requests. Empty fields match everything.
+type captureFilter struct {
+	Method string
+	Path   string
+	Model  string
+	Tag    string
+	// RequestID selects the request of a watermark.
+	RequestID string
+	// SafetyIdentifier and User select requests made for an end-user.
+	SafetyIdentifier string
+	User             string
+	Since            time.Time
+	Limit            int
+}
+
+// captureConfig bounds the request capture.
+type captureConfig struct {
+	// Size is the maximum number of requests kept; 0 disables capturing.
+	Size int
+	// MaxBytes is the maximum approximate memory of the kept requests; 0 means no byte limit.
+	MaxBytes int64
+	// Eviction is captureEvictOldest or captureEvictLRU.
+	Eviction string
+	// TTL evicts requests captured longer ago; 0 keeps them until they are evicted for room.
+	TTL time.Duration
+	// StreamPreview is the number of bytes of a streamed response body kept in memory.
+	StreamPreview int
+	// SpillDir, if set, receives the full body of every streamed response, one file per request,
+	// deleted with the request.
+	SpillDir string
+}
+
+// captureStats is the response body of GET /_mokku/capture.
+type captureStats struct {
+	Object   string `json:"object"`
+	Enabled  bool   `json:"enabled"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	MaxSize  int    `json:"max_entries"`
+	MaxBytes int64  `json:"max_bytes"`
+	Eviction string `json:"eviction"`
+	TTL      string `json:"ttl,omitempty"`
+	// Captured counts the requests captured since startup and Evicted those evicted since, by reason:
+	// "size" and "bytes" to make room, "ttl" for age. A request larger than MaxBytes on its own is
+	// evicted for "bytes" right away.
+	Captured     int64            `json:"captured"`
+	Evicted      map[string]int64 `json:"evicted"`
+	EvictedBytes int64            `json:"evicted_bytes"`
+}
+
+// capturedEntry is a kept request with its approximate memory.
+type capturedEntry struct {
+	exchange capturedExchange
+	size     int64
+}
+
+// requestCapture records API requests and their responses for test assertions. It keeps the most
+// recent requests within a number and a byte budget, evicting by captureConfig.Eviction and TTL, and
+// is disabled with a size of 0. It is safe for concurrent use.
+type requestCapture struct {
+	cfg    captureConfig
+	nextID atomic.Int64
+	now    func() time.Time
+
+	mu           sync.Mutex
+	order        *list.List // of *capturedEntry, next to evict first
+	byID         map[int64]*list.Element
+	bytes        int64
+	captured     int64
+	evicted      map[string]int64
+	evictedBytes int64
+}
+
+// newRequestCapture creates a recorder with the given bounds.
+func newRequestCapture(cfg captureConfig) *requestCapture {
+	if cfg.Eviction == "" {
+		cfg.Eviction = captureEvictOldest
+	}
+	return &requestCapture{
+		cfg:     cfg,
+		now:     time.Now,
+		order:   list.New(),
+		byID:    map[int64]*list.Element{},
+		evicted: map[string]int64{},
+	}
+}
+
+// captureConfigFromEnv reads MOKKU_CAPTURE_SIZE, MOKKU_CAPTURE_MAX_BYTES, MOKKU_CAPTURE_EVICTION,
+// MOKKU_CAPTURE_TTL, MOKKU_CAPTURE_STREAM_PREVIEW, and MOKKU_CAPTURE_SPILL_DIR.
+func captureConfigFromEnv(getenv func(string) string) (captureConfig, error) {
+	size, err := captureSizeFromEnv(getenv("MOKKU_CAPTURE_SIZE"))
+	if err != nil {
+		return captureConfig{}, err
+	}
+	cfg := captureConfig{Size: size, MaxBytes: defaultCaptureMaxBytes, Eviction: captureEvictOldest,
+		StreamPreview: defaultCaptureStreamPreview, SpillDir: getenv("MOKKU_CAPTURE_SPILL_DIR")}
+	if value := getenv("MOKKU_CAPTURE_MAX_BYTES"); value != "" {
+		cfg.MaxBytes, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || cfg.MaxBytes < 0 {
+			return captureConfig{}, fmt.Errorf("MOKKU_CAPTURE_MAX_BYTES must be a non-negative integer, got %q", value)
+		}
+	}
+	switch value := getenv("MOKKU_CAPTURE_EVICTION"); value {
+	case "":
+	case captureEvictOldest, captureEvictLRU:
+		cfg.Eviction = value
+	default:
+		return captureConfig{}, fmt.Errorf("MOKKU_CAPTURE_EVICTION must be %q or %q, got %q", captureEvictOldest, captureEvictLRU, value)
+	}
+	if value := getenv("MOKKU_CAPTURE_STREAM_PREVIEW"); value != "" {
+		cfg.StreamPreview, err = strconv.Atoi(value)
+		if err != nil || cfg.StreamPreview < 0 || cfg.StreamPreview > maxCapturedBodyBytes {
+			return captureConfig{}, fmt.Errorf("MOKKU_CAPTURE_STREAM_PREVIEW must be an integer from 0 to %d, got %q", maxCapturedBodyBytes, value)
+		}
+	}
+	if value := getenv("MOKKU_CAPTURE_TTL"); value != "" {
+		cfg.TTL, err = time.ParseDuration(value)
+		if err != nil || cfg.TTL < 0 {
+			return captureConfig{}, fmt.Errorf("MOKKU_CAPTURE_TTL must be a duration such as 10m, got %q", value)
+		}
+	}
+	return cfg, nil
+}
+
+// captureSizeFromEnv parses MOKKU_CAPTURE_SIZE, defaulting to defaultCaptureSize.
+func captureSizeFromEnv(value string) (int, error) {
+	if value == "" {
+		return defaultCaptureSize, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("MOKKU_CAPTURE_SIZE must be a non-negative integer, got %q", value)
+	}
+	return size, nil
+}
+
+// Enabled reports whether requests are recorded.
+func (c *requestCapture) Enabled() bool {
+	return c.cfg.Size > 0
+}
+
+// Len returns the number of recorded requests.
+func (c *requestCapture) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireLocked()
+	return c.order.Len()
+}
+
+// store keeps a captured request and evicts others until the bounds hold again.
+func (c *requestCapture) store(e capturedExchange) {
+	size := capturedExchangeSize(e)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.captured++
+	c.expireLocked()
+	if c.cfg.MaxBytes > 0 && size > c.cfg.MaxBytes {
+		c.evicted["bytes"]++
+		c.evictedBytes += size
+		removeSpilledBody(e)
+		return
+	}
+	c.byID[e.ID] = c.order.PushBack(&capturedEntry{exchange: e, size: size})
+	c.bytes += size
+	for c.order.Len() > c.cfg.Size {
+		c.evictLocked(c.order.Front(), "size")
+	}
+	for c.cfg.MaxBytes > 0 && c.bytes > c.cfg.MaxBytes {
+		c.evictLocked(c.order.Front(), "bytes")
+	}
+}
+
+// evictLocked removes an entry, counting it under reason. The caller holds c.mu.
+func (c *requestCapture) evictLocked(el *list.Element, reason string) {
+	entry := c.order.Remove(el).(*capturedEntry)
+	delete(c.byID, entry.exchange.ID)
+	removeSpilledBody(entry.exchange)
+	c.bytes -= entry.size
+	c.evicted[reason]++
+	c.evictedBytes += entry.size
+}
+
+// expireLocked evicts the requests older than the TTL. The caller holds c.mu.
+func (c *requestCapture) expireLocked() {
+	if c.cfg.TTL <= 0 {
+		return
+	}
+	cutoff := c.now().Add(-c.cfg.TTL)
+	for el := c.order.Front(); el != nil; {
+		next := el.Next()
+		if el.Value.(*capturedEntry).exchange.Time.Before(cutoff) {
+			c.evictLocked(el, "ttl")
+		}
+		el = next
+	}
+}
+
+// capturedExchangeSize approximates the memory a captured request takes by the length of its
+// variable-size fields.
+func capturedExchangeSize(e capturedExchange) int64 {
+	size := len(e.Method) + len(e.Path) + len(e.Model) + len(e.SafetyIdentifier) + len(e.User) + len(e.RemoteAddr) +
+		len(e.Body) + len(e.Response.Body) + len(e.Response.BodyFile)
+	if e.Chunks != nil {
+		size += len(e.Chunks.Timings) * 16
+	}
+	for name, value := range e.Headers {
+		size += len(name) + len(value)
+	}
+	for name, value := range e.Response.Headers {
+		size += len(name) + len(value)
+	}
+	if e.Trace != nil {
+		for _, step := range e.Trace.Steps {
+			size += len(step.Name) + len(step.SpanID)
+			for name, value := range step.Attributes {
+				size += len(name) + len(value)
+			}
+		}
+	}
+	for _, tag := range e.Tags {
+		size += len(tag)
+	}
+	return int64(size)
+}
+
+// Stats returns the occupancy of the capture and its eviction counters.
+func (c *requestCapture) Stats() captureStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireLocked()
+	stats := captureStats{
+		Object:       "mokku.capture",
+		Enabled:      c.Enabled(),
+		Entries:      c.order.Len(),
+		Bytes:        c.bytes,
+		MaxSize:      c.cfg.Size,
+		MaxBytes:     c.cfg.MaxBytes,
+		Eviction:     c.cfg.Eviction,
+		Captured:     c.captured,
+		Evicted:      map[string]int64{"size": c.evicted["size"], "bytes": c.evicted["bytes"], "ttl": c.evicted["ttl"]},
+		EvictedBytes: c.evictedBytes,
+	}
+	if c.cfg.TTL > 0 {
+		stats.TTL = c.cfg.TTL.String()
+	}
+	return stats
+}
+
+// Begin starts recording a request. It reads the first maxCapturedBodyBytes of the request body,
+// puts them back in front of the rest for the handlers, and returns a writer recording the response;
+// done stores the exchange once the response is complete. The model, stream flag, and end-user are
+// only read from bodies within maxCapturedBodyBytes.
+func (c *requestCapture) Begin(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, func()) {
+	start := time.Now()
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCapturedBodyBytes+1))
+	if err != nil {
+		return w, r, func() {}
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	throughput := &streamThroughput{}
+	var cancellation *capturedCancellation
+	r = r.WithContext(withCapturedCancellation(withStreamThroughput(r.Context(), throughput), &cancellation))
+	rt, delay := requestTraceFromContext(r.Context()), injectedDelayFromContext(r.Context())
+
+	e := capturedExchange{
+		capturedRequest: capturedRequest{Time: start, Method: r.Method, Path: r.URL.Path, Headers: capturedHeaders(r.Header)},
+	}
+	e.Seed, _ = seedFromContext(r.Context())
+	if mark := watermarkFromContext(r.Context()); mark != nil {
+		e.RequestID = mark.RequestID
+	}
+	e.RemoteAddr = r.RemoteAddr
+	e.Tags = requestTagsFromContext(r.Context())
+	if cr, ok := connectionRequestFromContext(r.Context()); ok {
+		e.Connection = &cr
+	}
+	if len(body) > 0 {
+		var truncated bool
+		e.Body, truncated = capturedBody(body, maxCapturedBodyBytes)
+		e.Truncated = truncated
+	}
+	var doc struct {
+		Model  string `json:"model"`
+		Stream bool   `json:"stream"`
+		endUser
+	}
+	if json.Unmarshal(body, &doc) == nil {
+		e.Model, e.Stream, e.endUser = doc.Model, doc.Stream, doc.endUser
+	}
+
+	rec := &captureWriter{ResponseWriter: w, status: http.StatusOK, start: start, preview: c.cfg.StreamPreview, spillDir: c.cfg.SpillDir}
+	return rec, r, func() {
+		e.Response.Status = rec.status
+		e.Response.Headers = capturedHeaders(rec.Header())
+		if rec.body.Len() > 0 {
+			var truncated bool
+			e.Response.Body, truncated = capturedBody(rec.body.Bytes(), rec.limit())
+			e.Truncated = e.Truncated || truncated || rec.truncated
+		}
+		if rec.stream {
+			e.Chunks = &rec.chunks
+			var first time.Time
+			if e.Chunks.Tokens, first, e.Chunks.TokensPerSecond = throughput.snapshot(); e.Chunks.Tokens > 0 {
+				e.Chunks.TimeToFirstTokenMS = durationMS(first.Sub(start))
+			}
+			e.Response.BodyFile = rec.closeSpill()
+		}
+		e.Cancellation = cancellation
+		e.DurationMS = time.Since(start).Milliseconds()
+		e.InjectedLatencyMS = durationMS(delay.Total())
+		e.Trace = rt.captured()
+		e.ID = c.nextID.Add(1)
+		c.store(e)
+	}
+}
+
+// Query returns the matching requests, newest first.
+func (c *requestCapture) Query(f captureFilter) []capturedExchange {
+	c.mu.Lock()
+	c.expireLocked()
+	entries := make([]capturedExchange, 0, c.order.Len())
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		entries = append(entries, el.Value.(*capturedEntry).exchange)
+	}
+	c.mu.Unlock()
+	slices.SortFunc(entries, func(a, b capturedExchange) int { return cmp.Compare(b.ID, a.ID) })
+	matches := []capturedExchange{}
+	for _, e := range entries {
+		if f.Method != "" && e.Method != f.Method {
+			continue
+		}
+		if f.Path != "" && e.Path != f.Path {
+			continue
+		}
+		if f.Model != "" && e.Model != f.Model {
+			continue
+		}
+		if f.RequestID != "" && e.RequestID != f.RequestID {
+			continue
+		}
+		if f.Tag != "" && !slices.Contains(e.Tags, f.Tag) {
+			continue
+		}
+		if f.SafetyIdentifier != "" && e.SafetyIdentifier != f.SafetyIdentifier {
+			continue
+		}
+		if f.User != "" && e.User != f.User {
+			continue
+		}
+		if !f.Since.IsZero() && e.Time.Before(f.Since) {
+			continue
+		}
+		matches = append(matches, e)
+		if f.Limit > 0 && len(matches) == f.Limit {
+			break
+		}
+	}
+	return matches
+}
+
+// Get returns the captured request with the given ID. With LRU eviction, it is then evicted last.
+func (c *requestCapture) Get(id int64) (capturedExchange, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expireLocked()
+	el, ok := c.byID[id]
+	if !ok {
+		return capturedExchange{}, false
+	}
+	if c.cfg.Eviction == captureEvictLRU {
+		c.order.MoveToBack(el)
+	}
+	return el.Value.(*capturedEntry).exchange, true
+}
+
+// Restore keeps the requests captured by a previous process under their IDs, oldest first, and
+// continues numbering after them. The bounds of this process apply.
+func (c *requestCapture) Restore(entries []capturedExchange) {
+	entries = slices.Clone(entries)
+	slices.SortFunc(entries, func(a, b capturedExchange) int { return cmp.Compare(a.ID, b.ID) })
+	for _, e := range entries {
+		for {
+			next := c.nextID.Load()
+			if next >= e.ID || c.nextID.CompareAndSwap(next, e.ID) {
+				break
+			}
+		}
+		c.store(e)
+	}
+}
+
+// Reset forgets all captured requests. The capture and eviction counters keep counting.
+func (c *requestCapture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		removeSpilledBody(el.Value.(*capturedEntry).exchange)
+	}
+	c.order.Init()
+	clear(c.byID)
+	c.bytes = 0
+}
+
+// removeSpilledBody deletes the file a captured request's stream was spilled to, if any.
+func removeSpilledBody(e capturedExchange) {
+	if e.Response.BodyFile != "" {
+		_ = os.Remove(e.Response.BodyFile)
+	}
+}
+
+// capturedHeaders returns the first value of each request header. Credentials are left out so
+// they are neither exposed by the control API nor replayed.
+func capturedHeaders(h http.Header) map[string]string {
+	headers := make(map[string]string, len(h))
+	for name, values := range h {
+		switch http.CanonicalHeaderKey(name) {
+		case "Authorization", "Cookie", "Api-Key":
+			continue
+		}
+		if len(values) > 0 {
+			headers[name] = values[0]
+		}
+	}
+	return headers
+}
+
+// capturedBody returns a body as JSON: the body itself if it is a JSON document, otherwise a JSON
+// string of it, cut to limit bytes. truncated reports whether it was cut.
+func capturedBody(body []byte, limit int) (raw json.RawMessage, truncated bool) {
+	if len(body) <= limit && json.Valid(body) {
+		return json.RawMessage(body), false
+	}
+	if len(body) > limit {
+		body, truncated = body[:limit], true
+	}
+	s, _ := json.Marshal(string(body))
+	return s, truncated
+}
+
+// captureWriter records the status and (bounded) body of a response. Of a streamed response
+// (text/event-stream), it keeps a preview of the body and the timing of each write (chunk), and
+// spills the full body to a file in spillDir if set.
+type captureWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	truncated   bool
+
+	start    time.Time
+	preview  int
+	spillDir string
+	stream   bool
+	chunks   capturedChunks
+	spill    *os.File
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *captureWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.begin(status)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// begin records the status and whether the response is a stream.
+func (w *captureWriter) begin(status int) {
+	w.wroteHeader = true
+	w.status = status
+	w.stream = strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream")
+	if w.stream && w.spillDir != "" {
+		// A failed spill only loses the full body; the preview is still kept
+		w.spill, _ = os.CreateTemp(w.spillDir, "mokku-stream-*.sse")
+	}
+}
+
+// limit returns the number of body bytes kept in memory.
+func (w *captureWriter) limit() int {
+	if w.stream {
+		return w.preview
+	}
+	return maxCapturedBodyBytes
+}
+
+// Write implements http.ResponseWriter
+func (w *captureWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.begin(http.StatusOK)
+	}
+	if room := w.limit() + 1 - w.body.Len(); room > 0 {
+		w.body.Write(b[:min(len(b), room)])
+	} else {
+		w.truncated = true
+	}
+	if w.stream {
+		w.recordChunk(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// recordChunk records the timing of a streamed write and spills it.
+func (w *captureWriter) recordChunk(b []byte) {
+	w.chunks.Count++
+	w.chunks.Bytes += int64(len(b))
+	if len(w.chunks.Timings) < maxCapturedChunkTimings {
+		w.chunks.Timings = append(w.chunks.Timings, chunkTiming{OffsetMS: durationMS(time.Since(w.start)), Bytes: len(b)})
+	} else {
+		w.chunks.TimingsTruncated = true
+	}
+	if w.spill != nil {
+		if _, err := w.spill.Write(b); err != nil {
+			_ = w.spill.Close()
+			_ = os.Remove(w.spill.Name())
+			w.spill = nil
+		}
+	}
+}
+
+// closeSpill closes the spill file and returns its path, or "" without one.
+func (w *captureWriter) closeSpill() string {
+	if w.spill == nil {
+		return ""
+	}
+	name := w.spill.Name()
+	if err := w.spill.Close(); err != nil {
+		_ = os.Remove(name)
+		return ""
+	}
+	return name
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *captureWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *captureWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,431 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureRequest records one request answered with the given status and body.
+func captureRequest(c *requestCapture, method, path, body string, status int, respBody string) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer sk-secret")
+	w, _, done := c.Begin(httptest.NewRecorder(), req)
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(respBody))
+	done()
+}
+
+// --- captureSizeFromEnv ---
+
+func TestCaptureSizeFromEnv(t *testing.T) {
+	cases := map[string]struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		"unset":    {"", defaultCaptureSize, false},
+		"zero":     {"0", 0, false},
+		"custom":   {"50", 50, false},
+		"negative": {"-1", 0, true},
+		"invalid":  {"many", 0, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			got, err := captureSizeFromEnv(tc.value)
+			// Then
+			if (err != nil) != tc.wantErr || got != tc.want {
+				t.Errorf("expected %d (error %t), got %d, %v", tc.want, tc.wantErr, got, err)
+			}
+		})
+	}
+}
+
+// --- captureConfigFromEnv ---
+
+func TestCaptureConfigFromEnv(t *testing.T) {
+	cases := map[string]struct {
+		env     map[string]string
+		want    captureConfig
+		wantErr bool
+	}{
+		"defaults": {env: nil, want: captureConfig{Size: defaultCaptureSize, MaxBytes: defaultCaptureMaxBytes, Eviction: captureEvictOldest, StreamPreview: defaultCaptureStreamPreview}},
+		"custom": {
+			env: map[string]string{"MOKKU_CAPTURE_SIZE": "10", "MOKKU_CAPTURE_MAX_BYTES": "0", "MOKKU_CAPTURE_EVICTION": "lru", "MOKKU_CAPTURE_TTL": "5m",
+				"MOKKU_CAPTURE_STREAM_PREVIEW": "0", "MOKKU_CAPTURE_SPILL_DIR": "/var/spill"},
+			want: captureConfig{Size: 10, Eviction: captureEvictLRU, TTL: 5 * time.Minute, SpillDir: "/var/spill"},
+		},
+		"preview too large": {env: map[string]string{"MOKKU_CAPTURE_STREAM_PREVIEW": "300000"}, wantErr: true},
+		"negative bytes":    {env: map[string]string{"MOKKU_CAPTURE_MAX_BYTES": "-1"}, wantErr: true},
+		"unknown eviction":  {env: map[string]string{"MOKKU_CAPTURE_EVICTION": "random"}, wantErr: true},
+		"invalid ttl":       {env: map[string]string{"MOKKU_CAPTURE_TTL": "soon"}, wantErr: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			got, err := captureConfigFromEnv(func(key string) string { return tc.env[key] })
+			// Then
+			if (err != nil) != tc.wantErr || got != tc.want {
+				t.Errorf("expected %+v (error %t), got %+v, %v", tc.want, tc.wantErr, got, err)
+			}
+		})
+	}
+}
+
+// --- requestCapture ---
+
+func TestRequestCapture_Begin_RecordsRequestAndResponse(t *testing.T) {
+	// Given
+	c := newRequestCapture(captureConfig{Size: 10})
+	// When: a request is served
+	captureRequest(c, http.MethodPost, "/v1/chat/completions", `{"model":"gpt-4o","stream":true}`, http.StatusTeapot, "data: x\n\n")
+	// Then: request fields, model, stream flag, and the raw response are recorded; credentials are not
+	entries := c.Query(captureFilter{})
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.ID != 1 || e.Method != http.MethodPost || e.Path != "/v1/chat/completions" || e.Model != "gpt-4o" || !e.Stream {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if string(e.Body) != `{"model":"gpt-4o","stream":true}` {
+		t.Errorf("unexpected body %s", e.Body)
+	}
+	if _, ok := e.Headers["Authorization"]; ok {
+		t.Error("expected Authorization to be left out")
+	}
+	if e.Headers["Content-Type"] != "application/json" {
+		t.Errorf("expected Content-Type header, got %v", e.Headers)
+	}
+	var body string
+	if err := json.Unmarshal(e.Response.Body, &body); err != nil || body != "data: x\n\n" || e.Response.Status != http.StatusTeapot {
+		t.Errorf("unexpected response %+v", e.Response)
+	}
+}
+
+func TestRequestCapture_Begin_TruncatesLargeBodies(t *testing.T) {
+	// Given
+	c := newRequestCapture(captureConfig{Size: 10})
+	large := `{"input":"` + strings.Repeat("a", maxCapturedBodyBytes) + `"}`
+	// When
+	captureRequest(c, http.MethodPost, "/v1/embeddings", large, http.StatusOK, large)
+	// Then: both bodies are cut to a JSON string of maxCapturedBodyBytes
+	e := c.Query(captureFilter{})[0]
+	if !e.Truncated {
+		t.Error("expected truncated")
+	}
+	for _, raw := range []json.RawMessage{e.Body, e.Response.Body} {
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil || len(s) != maxCapturedBodyBytes {
+			t.Errorf("expected a %d byte string, got %d bytes (%v)", maxCapturedBodyBytes, len(s), err)
+		}
+	}
+}
+
+func TestRequestCapture_Begin_PassesLargeBodiesThroughInFull(t *testing.T) {
+	// Given
+	c := newRequestCapture(captureConfig{Size: 10})
+	large := `{"input":"` + strings.Repeat("a", 2*maxCapturedBodyBytes) + `"}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(large))
+	// When: the handler reads the body after Begin
+	_, req, done := c.Begin(httptest.NewRecorder(), req)
+	read, err := io.ReadAll(req.Body)
+	done()
+	// Then: the handler reads the whole body, while the capture keeps only its first bytes
+	if err != nil || string(read) != large {
+		t.Errorf("expected the %d byte body, read %d bytes (%v)", len(large), len(read), err)
+	}
+	e, _ := c.Get(1)
+	var kept string
+	if err := json.Unmarshal(e.Body, &kept); err != nil || len(kept) != maxCapturedBodyBytes || !e.Truncated {
+		t.Errorf("expected a truncated %d byte body, got %d bytes (truncated %t, %v)", maxCapturedBodyBytes, len(kept), e.Truncated, err)
+	}
+}
+
+func TestRequestCapture_Query_FiltersNewestFirst(t *testing.T) {
+	// Given: requests to two paths and models
+	c := newRequestCapture(captureConfig{Size: 10})
+	captureRequest(c, http.MethodPost, "/v1/chat/completions", `{"model":"a"}`, http.StatusOK, `{}`)
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{"model":"a"}`, http.StatusOK, `{}`)
+	captureRequest(c, http.MethodPost, "/v1/chat/completions", `{"model":"b","safety_identifier":"u-1","user":"legacy"}`, http.StatusOK, `{}`)
+	cases := map[string]struct {
+		filter captureFilter
+		want   []int64
+	}{
+		"all":     {captureFilter{}, []int64{3, 2, 1}},
+		"path":    {captureFilter{Path: "/v1/chat/completions"}, []int64{3, 1}},
+		"model":   {captureFilter{Model: "a"}, []int64{2, 1}},
+		"method":  {captureFilter{Method: http.MethodGet}, nil},
+		"limit":   {captureFilter{Limit: 1}, []int64{3}},
+		"since":   {captureFilter{Since: time.Now().Add(time.Hour)}, nil},
+		"combine": {captureFilter{Path: "/v1/chat/completions", Model: "b"}, []int64{3}},
+		"safety":  {captureFilter{SafetyIdentifier: "u-1"}, []int64{3}},
+		"user":    {captureFilter{User: "legacy"}, []int64{3}},
+		"no user": {captureFilter{User: "u-1"}, nil},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			entries := c.Query(tc.filter)
+			// Then
+			var got []int64
+			for _, e := range entries {
+				got = append(got, e.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestRequestCapture_Push_EvictsOldest(t *testing.T) {
+	// Given: a capture of size 2
+	c := newRequestCapture(captureConfig{Size: 2})
+	// When: three requests are captured
+	for range 3 {
+		captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+	}
+	// Then: the first is gone and IDs keep increasing
+	if _, ok := c.Get(1); ok {
+		t.Error("expected request 1 to be evicted")
+	}
+	if e, ok := c.Get(3); !ok || e.ID != 3 {
+		t.Errorf("expected request 3, got %+v, %t", e, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestRequestCapture_Store_EvictsForBytes(t *testing.T) {
+	// Given: room for about two 1 KiB requests
+	body := `{"input":"` + strings.Repeat("a", 1000) + `"}`
+	c := newRequestCapture(captureConfig{Size: 10, MaxBytes: 2500})
+
+	// When: three requests and one larger than the whole budget are captured
+	for range 3 {
+		captureRequest(c, http.MethodPost, "/v1/embeddings", body, http.StatusOK, `{}`)
+	}
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{"input":"`+strings.Repeat("a", 3000)+`"}`, http.StatusOK, `{}`)
+
+	// Then: the oldest and the oversized request are evicted for bytes
+	stats := c.Stats()
+	if stats.Entries != 2 || stats.Bytes > 2500 || stats.Captured != 4 || stats.Evicted["bytes"] != 2 || stats.Evicted["size"] != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if _, ok := c.Get(1); ok {
+		t.Error("expected request 1 to be evicted")
+	}
+}
+
+func TestRequestCapture_Store_LRUKeepsFetchedRequests(t *testing.T) {
+	// Given: a capture of size 2 evicting the least recently used request
+	c := newRequestCapture(captureConfig{Size: 2, Eviction: captureEvictLRU})
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+
+	// When: the first request is fetched before a third is captured
+	_, _ = c.Get(1)
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+
+	// Then: the second request is evicted instead, and the list stays newest first
+	if _, ok := c.Get(2); ok {
+		t.Error("expected request 2 to be evicted")
+	}
+	entries := c.Query(captureFilter{})
+	if len(entries) != 2 || entries[0].ID != 3 || entries[1].ID != 1 {
+		t.Errorf("expected requests 3 and 1, got %+v", entries)
+	}
+}
+
+func TestRequestCapture_Store_EvictsExpiredRequests(t *testing.T) {
+	// Given: a one-minute TTL
+	now := time.Now()
+	c := newRequestCapture(captureConfig{Size: 10, TTL: time.Minute})
+	c.now = func() time.Time { return now }
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+
+	// When: two minutes pass
+	now = now.Add(2 * time.Minute)
+
+	// Then
+	if c.Len() != 0 || c.Stats().Evicted["ttl"] != 1 {
+		t.Errorf("expected the request to expire, got %+v", c.Stats())
+	}
+}
+
+func TestRequestCapture_Reset_RemovesAll(t *testing.T) {
+	// Given
+	c := newRequestCapture(captureConfig{Size: 10})
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+	// When
+	c.Reset()
+	// Then
+	if c.Len() != 0 || len(c.Query(captureFilter{})) != 0 {
+		t.Error("expected no entries after reset")
+	}
+}
+
+func TestRequestCapture_Disabled_RecordsNothing(t *testing.T) {
+	// Given: a capture of size 0
+	c := newRequestCapture(captureConfig{Size: 0})
+	// Then: it is disabled and every query is empty
+	if c.Enabled() {
+		t.Error("expected capture to be disabled")
+	}
+	if c.Len() != 0 || len(c.Query(captureFilter{})) != 0 {
+		t.Error("expected no entries")
+	}
+	if _, ok := c.Get(1); ok {
+		t.Error("expected no entry")
+	}
+	c.Reset()
+}
+
+func TestRequestCapture_Concurrent_AssignsUniqueIDs(t *testing.T) {
+	// Given: a capture smaller than the number of requests
+	c := newRequestCapture(captureConfig{Size: 50})
+	var wg sync.WaitGroup
+	// When: requests are captured and queried concurrently
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 25 {
+				captureRequest(c, http.MethodPost, "/v1/embeddings", `{"model":"m"}`, http.StatusOK, `{}`)
+				_ = c.Query(captureFilter{Model: "m", Limit: 5})
+			}
+		}()
+	}
+	wg.Wait()
+	// Then: the capture is full and every ID is unique
+	entries := c.Query(captureFilter{})
+	if len(entries) != 50 {
+		t.Fatalf("expected 50 entries, got %d", len(entries))
+	}
+	seen := map[int64]bool{}
+	for _, e := range entries {
+		if seen[e.ID] {
+			t.Fatalf("duplicate ID %d", e.ID)
+		}
+		seen[e.ID] = true
+	}
+}
+
+func TestRequestCapture_Concurrent_StaysWithinByteBudget(t *testing.T) {
+	// Given: a byte budget smaller than the captured requests
+	c := newRequestCapture(captureConfig{Size: 1000, MaxBytes: 20000, Eviction: captureEvictLRU})
+	var wg sync.WaitGroup
+	// When: requests are captured, fetched, and counted concurrently
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 50 {
+				captureRequest(c, http.MethodPost, "/v1/embeddings", fmt.Sprintf(`{"input":"%d-%d %s"}`, i, j, strings.Repeat("x", 500)), http.StatusOK, `{}`)
+				_, _ = c.Get(int64(j))
+				_ = c.Stats()
+			}
+		}()
+	}
+	wg.Wait()
+	// Then: the byte budget holds and every request is either kept or counted as evicted
+	stats := c.Stats()
+	if stats.Bytes > 20000 || stats.Captured != 400 || int64(stats.Entries)+stats.Evicted["bytes"] != 400 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+// captureStream records one streamed response of the given events.
+func captureStream(c *requestCapture, events ...string) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"model":"gpt-4o","stream":true}`))
+	w, _, done := c.Begin(httptest.NewRecorder(), req)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	for _, event := range events {
+		_, _ = w.Write([]byte(event))
+	}
+	done()
+}
+
+func TestRequestCapture_Begin_KeepsStreamPreviewAndChunkTimings(t *testing.T) {
+	// Given: a 16 byte stream preview
+	c := newRequestCapture(captureConfig{Size: 10, StreamPreview: 16})
+	// When: a stream of three events is captured
+	captureStream(c, "data: {\"a\":1}\n\n", "data: {\"b\":2}\n\n", "data: [DONE]\n\n")
+	// Then: only the preview is kept, with the timing of every chunk
+	e, _ := c.Get(1)
+	var preview string
+	if err := json.Unmarshal(e.Response.Body, &preview); err != nil || preview != "data: {\"a\":1}\n\nd" || !e.Truncated {
+		t.Errorf("expected a 16 byte preview, got %s (truncated %t)", e.Response.Body, e.Truncated)
+	}
+	if e.Chunks == nil || e.Chunks.Count != 3 || e.Chunks.Bytes != 44 || len(e.Chunks.Timings) != 3 || e.Chunks.Timings[2].Bytes != 14 {
+		t.Errorf("unexpected chunks %+v", e.Chunks)
+	}
+	if e.Response.BodyFile != "" {
+		t.Errorf("expected no spill file, got %q", e.Response.BodyFile)
+	}
+}
+
+func TestRequestCapture_Begin_SpillsStreamsUntilEvicted(t *testing.T) {
+	// Given: a capture of size 1 spilling streams to a directory
+	dir := t.TempDir()
+	c := newRequestCapture(captureConfig{Size: 1, StreamPreview: 4, SpillDir: dir})
+	captureStream(c, "data: one\n\n", "data: [DONE]\n\n")
+	first, _ := c.Get(1)
+
+	// When: a JSON response and a second stream are captured
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+	captureStream(c, "data: two\n\n")
+
+	// Then: the first stream's full body was spilled, then deleted with it
+	if first.Response.BodyFile == "" || filepath.Dir(first.Response.BodyFile) != dir {
+		t.Fatalf("expected a spill file in %s, got %q", dir, first.Response.BodyFile)
+	}
+	if _, err := os.Stat(first.Response.BodyFile); !os.IsNotExist(err) {
+		t.Errorf("expected the evicted spill file to be deleted, got %v", err)
+	}
+	second, _ := c.Get(3)
+	body, err := os.ReadFile(second.Response.BodyFile)
+	if err != nil || string(body) != "data: two\n\n" {
+		t.Errorf("expected the full second stream, got %q, %v", body, err)
+	}
+	c.Reset()
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected reset to delete the spill files, got %v", entries)
+	}
+}
+
+func TestAdminHandler_GetRequestBody_ServesSpilledStream(t *testing.T) {
+	// Given: a captured stream spilled to disk and a captured JSON response
+	c := newRequestCapture(captureConfig{Size: 10, SpillDir: t.TempDir()})
+	captureStream(c, "data: one\n\n", "data: [DONE]\n\n")
+	captureRequest(c, http.MethodPost, "/v1/embeddings", `{}`, http.StatusOK, `{}`)
+	state, _ := newServerState(Config{}, serverOptions{})
+	state.capture = c
+	h := NewAdminHandler(state, "test")
+
+	// When
+	stream, other := httptest.NewRecorder(), httptest.NewRecorder()
+	h.ServeHTTP(stream, httptest.NewRequest(http.MethodGet, "/_mokku/requests/1/body", nil))
+	h.ServeHTTP(other, httptest.NewRequest(http.MethodGet, "/_mokku/requests/2/body", nil))
+
+	// Then
+	if stream.Code != http.StatusOK || stream.Body.String() != "data: one\n\ndata: [DONE]\n\n" || stream.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("unexpected spilled body: %d %q", stream.Code, stream.Body.String())
+	}
+	if other.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without a spill file, got %d", other.Code)
+	}
+}
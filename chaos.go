@@ -0,0 +1,438 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// chaosHeader is the response header naming the active chaos profile.
+const chaosHeader = "X-Mokku-Chaos"
+
+// maxChaosProfiles is the number of custom chaos profiles that can be configured.
+const maxChaosProfiles = 100
+
+// chaosStreamEvents is the number of server-sent events a truncated stream sends before it is cut off:
+// the role chunk and the first content chunk.
+const chaosStreamEvents = 2
+
+// Chaos activation sources.
+const (
+	chaosSourceConfig = "config"
+	chaosSourceAdmin  = "admin"
+)
+
+// chaosConfig is the chaos section of the config file.
+type chaosConfig struct {
+	// Profile is the profile active at startup.
+	Profile string `yaml:"profile" json:"profile"`
+	// Profiles adds profiles or replaces built-in ones with the same name.
+	Profiles []chaosProfileConfig `yaml:"profiles" json:"profiles"`
+}
+
+// chaosProfileConfig is a named combination of faults applied to every API request while active.
+// Rates are fractions (0-1) of requests.
+type chaosProfileConfig struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// ErrorRate is the fraction of requests failing with ErrorStatus (500 if unset).
+	ErrorRate   float64 `yaml:"error_rate" json:"error_rate,omitempty"`
+	ErrorStatus int     `yaml:"error_status" json:"error_status,omitempty"`
+	// Latency delays every request; LatencyJitter adds a random delay of up to its value.
+	Latency       string `yaml:"latency" json:"latency,omitempty"`
+	LatencyJitter string `yaml:"latency_jitter" json:"latency_jitter,omitempty"`
+	// TruncateRate is the fraction of streams cut off mid-response without finish_reason or [DONE].
+	TruncateRate float64 `yaml:"truncate_rate" json:"truncate_rate,omitempty"`
+	// RateLimitRate is the fraction of requests rejected with 429 rate_limit_exceeded.
+	RateLimitRate float64 `yaml:"rate_limit_rate" json:"rate_limit_rate,omitempty"`
+	// Ramp grows every fault linearly from none to full over this duration after activation.
+	Ramp string `yaml:"ramp" json:"ramp,omitempty"`
+}
+
+// builtinChaosProfiles are the profiles available without configuration, modelled on common
+// provider failure modes.
+var builtinChaosProfiles = []chaosProfileConfig{
+	{
+		Name:          "flaky-network",
+		Description:   "Jittery latency, occasional 502s, and streams cut off mid-response",
+		ErrorRate:     0.05,
+		ErrorStatus:   http.StatusBadGateway,
+		Latency:       "100ms",
+		LatencyJitter: "900ms",
+		TruncateRate:  0.2,
+	},
+	{
+		Name:          "provider-incident",
+		Description:   "A third of requests fail with 503 or 429 and the rest are slow",
+		ErrorRate:     0.2,
+		ErrorStatus:   http.StatusServiceUnavailable,
+		Latency:       "2s",
+		LatencyJitter: "3s",
+		TruncateRate:  0.05,
+		RateLimitRate: 0.15,
+	},
+	{
+		Name:          "slow-degradation",
+		Description:   "Latency and 500s grow over ten minutes until requests take over ten seconds",
+		ErrorRate:     0.25,
+		ErrorStatus:   http.StatusInternalServerError,
+		Latency:       "10s",
+		LatencyJitter: "2s",
+		TruncateRate:  0.1,
+		Ramp:          "10m",
+	},
+}
+
+// chaosProfile is a compiled chaos profile.
+type chaosProfile struct {
+	cfg     chaosProfileConfig
+	builtin bool
+	// defined is set for profiles defined through the admin API.
+	defined bool
+	err     *APIError
+	latency time.Duration
+	jitter  time.Duration
+	ramp    time.Duration
+}
+
+// chaosFault is the faults drawn for one request.
+type chaosFault struct {
+	Profile     string
+	Latency     time.Duration
+	Err         *APIError
+	RateLimited bool
+	Truncate    bool
+}
+
+// chaosStats counts the faults injected since the profile was activated.
+type chaosStats struct {
+	Requests    int64 `json:"requests"`
+	Errors      int64 `json:"errors"`
+	RateLimited int64 `json:"rate_limited"`
+	Truncated   int64 `json:"truncated"`
+}
+
+// chaosProfileStatus is a profile as listed by GET /_mokku/chaos.
+type chaosProfileStatus struct {
+	chaosProfileConfig
+	Builtin bool `json:"builtin"`
+	Defined bool `json:"defined,omitempty"`
+}
+
+// chaosStatus is the response body of GET /_mokku/chaos.
+type chaosStatus struct {
+	// Active is the active profile, "" when chaos is off.
+	Active string `json:"active"`
+	Source string `json:"source,omitempty"`
+	// Since is when the active profile was activated.
+	Since *time.Time `json:"since,omitempty"`
+	// Intensity is the fraction of the active profile's faults applied, below 1 while ramping up.
+	Intensity float64              `json:"intensity"`
+	Stats     chaosStats           `json:"stats"`
+	Profiles  []chaosProfileStatus `json:"profiles"`
+}
+
+// chaosEngine injects the faults of the active chaos profile into API requests, so game-day
+// exercises can switch between realistic provider failure modes at runtime. Faults are drawn from
+// the request's seed, so a request resent with the same seed meets the same faults (unless the
+// profile is ramping up). It is safe for concurrent use.
+type chaosEngine struct {
+	mu       sync.Mutex
+	profiles map[string]*chaosProfile
+	active   *chaosProfile
+	source   string
+	since    time.Time
+	stats    chaosStats
+	now      func() time.Time
+	// timeline records the activations, nil until Observe is called.
+	timeline *faultTimeline
+}
+
+// newChaosEngine creates an engine with the built-in and configured profiles.
+func newChaosEngine(cfg chaosConfig) (*chaosEngine, error) {
+	e := &chaosEngine{now: time.Now}
+	if err := e.Load(cfg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Load replaces the custom profiles and activates the configured profile, or turns chaos off.
+// A profile activated or defined at runtime is dropped. On error the current profiles are kept.
+func (e *chaosEngine) Load(cfg chaosConfig) error {
+	return installNow(e.prepare(cfg))
+}
+
+// prepare validates the profiles and returns the function installing them.
+func (e *chaosEngine) prepare(cfg chaosConfig) (func(), error) {
+	if len(cfg.Profiles) > maxChaosProfiles {
+		return nil, fmt.Errorf("chaos: at most %d profiles can be configured", maxChaosProfiles)
+	}
+	profiles := make(map[string]*chaosProfile, len(builtinChaosProfiles)+len(cfg.Profiles))
+	for _, p := range builtinChaosProfiles {
+		profile, err := compileChaosProfile(p)
+		if err != nil {
+			return nil, fmt.Errorf("chaos: built-in profile %s: %w", p.Name, err)
+		}
+		profile.builtin = true
+		profiles[profile.cfg.Name] = profile
+	}
+	custom := map[string]bool{}
+	for i, p := range cfg.Profiles {
+		profile, err := compileChaosProfile(p)
+		if err != nil {
+			return nil, fmt.Errorf("chaos.profiles[%d]: %w", i, err)
+		}
+		if custom[profile.cfg.Name] {
+			return nil, fmt.Errorf("chaos.profiles[%d]: duplicate name %q", i, p.Name)
+		}
+		custom[profile.cfg.Name] = true
+		profiles[profile.cfg.Name] = profile
+	}
+	var active *chaosProfile
+	if cfg.Profile != "" {
+		var ok bool
+		if active, ok = profiles[strings.ToLower(cfg.Profile)]; !ok {
+			return nil, fmt.Errorf("chaos.profile: unknown profile %q", cfg.Profile)
+		}
+	}
+	return func() {
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.profiles = profiles
+		e.activate(active, chaosSourceConfig)
+	}, nil
+}
+
+// compileChaosProfile validates a profile and normalizes its name to lower case.
+func compileChaosProfile(cfg chaosProfileConfig) (*chaosProfile, error) {
+	cfg.Name = strings.ToLower(strings.TrimSpace(cfg.Name))
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	rates := []struct {
+		field string
+		value float64
+	}{{"error_rate", cfg.ErrorRate}, {"truncate_rate", cfg.TruncateRate}, {"rate_limit_rate", cfg.RateLimitRate}}
+	for _, rate := range rates {
+		if rate.value < 0 || rate.value > 1 {
+			return nil, fmt.Errorf("%s must be between 0 and 1, got %v", rate.field, rate.value)
+		}
+	}
+	if cfg.ErrorStatus != 0 && (cfg.ErrorStatus < 400 || cfg.ErrorStatus > 599) {
+		return nil, fmt.Errorf("error_status must be an HTTP error status (400-599), got %d", cfg.ErrorStatus)
+	}
+	if cfg.ErrorRate > 0 && cfg.ErrorStatus == 0 {
+		cfg.ErrorStatus = http.StatusInternalServerError
+	}
+	profile := &chaosProfile{cfg: cfg}
+	durations := []struct {
+		field string
+		value string
+		dst   *time.Duration
+	}{{"latency", cfg.Latency, &profile.latency}, {"latency_jitter", cfg.LatencyJitter, &profile.jitter}, {"ramp", cfg.Ramp, &profile.ramp}}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil || parsed < 0 {
+			return nil, fmt.Errorf("%s must be a duration such as 500ms, got %q", d.field, d.value)
+		}
+		*d.dst = parsed
+	}
+	if cfg.ErrorStatus != 0 {
+		profile.err = scenarioError(cfg.ErrorStatus, nil)
+	}
+	return profile, nil
+}
+
+// Active reports whether a chaos profile is active.
+func (e *chaosEngine) Active() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.active != nil
+}
+
+// Activate switches to the named profile, resetting the stats and restarting its ramp.
+func (e *chaosEngine) Activate(name string) (chaosStatus, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	profile, ok := e.profiles[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return chaosStatus{}, fmt.Errorf("unknown chaos profile %q", name)
+	}
+	e.activate(profile, chaosSourceAdmin)
+	return e.status(), nil
+}
+
+// Define adds a profile, or replaces one defined before with the same name, and activates it, so
+// faults can be tuned at runtime without a config reload. Built-in and configured profiles cannot be
+// replaced, and at most maxChaosProfiles profiles can be defined. Defined profiles are dropped on
+// config reload.
+func (e *chaosEngine) Define(cfg chaosProfileConfig) (chaosStatus, error) {
+	profile, err := compileChaosProfile(cfg)
+	if err != nil {
+		return chaosStatus{}, err
+	}
+	profile.defined = true
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	existing, ok := e.profiles[profile.cfg.Name]
+	if ok && !existing.defined {
+		return chaosStatus{}, fmt.Errorf("chaos profile %q is not defined through the admin API and cannot be replaced", profile.cfg.Name)
+	}
+	if !ok {
+		defined := 0
+		for _, p := range e.profiles {
+			if p.defined {
+				defined++
+			}
+		}
+		if defined >= maxChaosProfiles {
+			return chaosStatus{}, fmt.Errorf("at most %d chaos profiles can be defined", maxChaosProfiles)
+		}
+	}
+	e.profiles[profile.cfg.Name] = profile
+	e.activate(profile, chaosSourceAdmin)
+	return e.status(), nil
+}
+
+// Deactivate turns chaos off.
+func (e *chaosEngine) Deactivate() chaosStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.activate(nil, chaosSourceAdmin)
+	return e.status()
+}
+
+// activate switches to profile, or turns chaos off for nil. The caller holds e.mu.
+func (e *chaosEngine) activate(profile *chaosProfile, source string) {
+	e.active, e.source, e.since, e.stats = profile, source, e.now(), chaosStats{}
+	e.record()
+}
+
+// Observe records the activations in timeline from now on, starting with the active profile.
+func (e *chaosEngine) Observe(timeline *faultTimeline) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timeline = timeline
+	e.record()
+}
+
+// record starts the period of the active profile in the timeline, or ends the current one when chaos
+// is off. The caller holds e.mu.
+func (e *chaosEngine) record() {
+	if e.active == nil {
+		e.timeline.finish(faultKindChaos, "")
+		return
+	}
+	cfg := e.active.cfg
+	e.timeline.begin(faultPeriod{Kind: faultKindChaos, Name: cfg.Name, Source: e.source, Chaos: &cfg})
+}
+
+// Draw draws the faults of the active profile for a request with the given seed. The zero
+// chaosFault is returned when chaos is off.
+func (e *chaosEngine) Draw(seed uint64) chaosFault {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	p := e.active
+	if p == nil {
+		return chaosFault{}
+	}
+	rng := seededRand(seed, "chaos")
+	intensity := e.intensity()
+	fault := chaosFault{Profile: p.cfg.Name, Latency: time.Duration(float64(p.latency) * intensity)}
+	if p.jitter > 0 {
+		fault.Latency += time.Duration(rng.Float64() * float64(p.jitter) * intensity)
+	}
+	e.stats.Requests++
+	switch roll := rng.Float64(); {
+	case roll < p.cfg.RateLimitRate*intensity:
+		fault.RateLimited = true
+		e.stats.RateLimited++
+	case p.err != nil && roll < (p.cfg.RateLimitRate+p.cfg.ErrorRate)*intensity:
+		fault.Err = p.err
+		e.stats.Errors++
+	}
+	fault.Truncate = rng.Float64() < p.cfg.TruncateRate*intensity
+	e.timeline.count(faultKindChaos, p.cfg.Name, func(s *chaosStats) {
+		s.Requests++
+		if fault.RateLimited {
+			s.RateLimited++
+		} else if fault.Err != nil {
+			s.Errors++
+		}
+	})
+	return fault
+}
+
+// Truncated counts a stream cut off by the active profile.
+func (e *chaosEngine) Truncated() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats.Truncated++
+	e.timeline.count(faultKindChaos, "", func(s *chaosStats) { s.Truncated++ })
+}
+
+// intensity returns the fraction of the active profile's faults to apply. The caller holds e.mu.
+func (e *chaosEngine) intensity() float64 {
+	if e.active == nil {
+		return 0
+	}
+	if e.active.ramp <= 0 {
+		return 1
+	}
+	return min(1, float64(e.now().Sub(e.since))/float64(e.active.ramp))
+}
+
+// Status returns the active profile, its stats, and every profile sorted by name.
+func (e *chaosEngine) Status() chaosStatus {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.status()
+}
+
+// status implements Status. The caller holds e.mu.
+func (e *chaosEngine) status() chaosStatus {
+	s := chaosStatus{Stats: e.stats, Intensity: e.intensity(), Profiles: make([]chaosProfileStatus, 0, len(e.profiles))}
+	if e.active != nil {
+		since := e.since
+		s.Active, s.Source, s.Since = e.active.cfg.Name, e.source, &since
+	}
+	for _, p := range e.profiles {
+		s.Profiles = append(s.Profiles, chaosProfileStatus{chaosProfileConfig: p.cfg, Builtin: p.builtin, Defined: p.defined})
+	}
+	slices.SortFunc(s.Profiles, func(a, b chaosProfileStatus) int { return strings.Compare(a.Name, b.Name) })
+	return s
+}
+
+// chaosRateLimitError is the 429 returned for requests rejected by a chaos profile.
+func chaosRateLimitError() *APIError {
+	return &APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Detail: OpenAIErrorDetail{
+			Message: "Rate limit reached for requests. Please try again in 1s.",
+			Type:    "requests",
+			Code:    "rate_limit_exceeded",
+		},
+	}
+}
+
+type chaosTruncateContextKey struct{}
+
+// withChaosTruncation marks the stream of a request to be cut off.
+func withChaosTruncation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, chaosTruncateContextKey{}, true)
+}
+
+// chaosTruncationFromContext reports whether the stream of a request is to be cut off.
+func chaosTruncationFromContext(ctx context.Context) bool {
+	truncate, _ := ctx.Value(chaosTruncateContextKey{}).(bool)
+	return truncate
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// --- newChaosEngine ---
+
+func TestNewChaosEngine_BuiltinProfilesAreOff(t *testing.T) {
+	// When
+	e, err := newChaosEngine(chaosConfig{})
+	// Then: the built-in profiles are listed and none is active
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := e.Status()
+	if e.Active() || status.Active != "" || status.Since != nil {
+		t.Errorf("expected chaos to be off, got %+v", status)
+	}
+	var names []string
+	for _, p := range status.Profiles {
+		if !p.Builtin {
+			t.Errorf("expected %s to be built in", p.Name)
+		}
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "flaky-network,provider-incident,slow-degradation" {
+		t.Errorf("unexpected profiles %v", names)
+	}
+	if fault := e.Draw(1); fault != (chaosFault{}) {
+		t.Errorf("expected no fault, got %+v", fault)
+	}
+}
+
+func TestNewChaosEngine_RejectsInvalidConfig(t *testing.T) {
+	cases := map[string]chaosConfig{
+		"missing name":    {Profiles: []chaosProfileConfig{{ErrorRate: 0.5}}},
+		"duplicate name":  {Profiles: []chaosProfileConfig{{Name: "a"}, {Name: "A"}}},
+		"bad error rate":  {Profiles: []chaosProfileConfig{{Name: "a", ErrorRate: 2}}},
+		"bad truncation":  {Profiles: []chaosProfileConfig{{Name: "a", TruncateRate: -0.1}}},
+		"bad status":      {Profiles: []chaosProfileConfig{{Name: "a", ErrorStatus: 302}}},
+		"bad jitter":      {Profiles: []chaosProfileConfig{{Name: "a", LatencyJitter: "often"}}},
+		"unknown profile": {Profile: "meltdown"},
+	}
+	for name, cfg := range cases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			_, err := newChaosEngine(cfg)
+			// Then
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestNewChaosEngine_ConfiguredProfileReplacesBuiltin(t *testing.T) {
+	// Given: a custom flaky-network active at startup
+	cfg := chaosConfig{Profile: "flaky-network", Profiles: []chaosProfileConfig{{Name: "Flaky-Network", ErrorRate: 1}}}
+	// When
+	e, err := newChaosEngine(cfg)
+	// Then: it replaces the built-in one and fails with the default 500
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := e.Status()
+	if status.Active != "flaky-network" || status.Source != chaosSourceConfig || len(status.Profiles) != 3 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	fault := e.Draw(1)
+	if fault.Err == nil || fault.Err.StatusCode != http.StatusInternalServerError || fault.Latency != 0 || fault.Truncate {
+		t.Errorf("unexpected fault %+v", fault)
+	}
+}
+
+// --- chaosEngine.Draw ---
+
+func TestChaosEngine_Draw_AppliesEveryFault(t *testing.T) {
+	// Given: a profile that always rate limits, truncates, and delays by 100-150ms
+	e, _ := newChaosEngine(chaosConfig{Profile: "all", Profiles: []chaosProfileConfig{{
+		Name: "all", ErrorRate: 1, ErrorStatus: 503, RateLimitRate: 1, TruncateRate: 1, Latency: "100ms", LatencyJitter: "50ms",
+	}}})
+	for seed := range uint64(20) {
+		// When
+		fault := e.Draw(seed)
+		// Then: rate limiting takes precedence over the error
+		if !fault.RateLimited || fault.Err != nil || !fault.Truncate || fault.Profile != "all" {
+			t.Fatalf("unexpected fault %+v", fault)
+		}
+		if fault.Latency < 100*time.Millisecond || fault.Latency > 150*time.Millisecond {
+			t.Fatalf("expected 100-150ms latency, got %s", fault.Latency)
+		}
+	}
+	if stats := e.Status().Stats; stats.Requests != 20 || stats.RateLimited != 20 || stats.Errors != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestChaosEngine_Draw_RampsUpFaults(t *testing.T) {
+	// Given: a profile ramping up over 10 minutes, activated at t0
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	e, _ := newChaosEngine(chaosConfig{Profiles: []chaosProfileConfig{{Name: "ramp", Latency: "10s", Ramp: "10m"}}})
+	e.now = func() time.Time { return t0 }
+	if _, err := e.Activate("ramp"); err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		after time.Duration
+		want  time.Duration
+	}{{0, 0}, {5 * time.Minute, 5 * time.Second}, {time.Hour, 10 * time.Second}}
+	for _, tc := range cases {
+		// When
+		e.now = func() time.Time { return t0.Add(tc.after) }
+		fault := e.Draw(1)
+		// Then
+		if fault.Latency != tc.want {
+			t.Errorf("after %s: expected %s latency, got %s", tc.after, tc.want, fault.Latency)
+		}
+	}
+	if intensity := e.Status().Intensity; intensity != 1 {
+		t.Errorf("expected full intensity, got %v", intensity)
+	}
+}
+
+func TestChaosEngine_Draw_SameSeedSameFaults(t *testing.T) {
+	// Given: the flaky-network profile
+	e, _ := newChaosEngine(chaosConfig{Profile: "flaky-network"})
+	differs := false
+	for seed := range uint64(50) {
+		// When: a seed is drawn twice
+		first, second := e.Draw(seed), e.Draw(seed)
+		// Then: the faults are the same
+		if first != second {
+			t.Fatalf("seed %d: expected the same faults, got %+v and %+v", seed, first, second)
+		}
+		differs = differs || first != e.Draw(seed+1000)
+	}
+	if !differs {
+		t.Error("expected different seeds to draw different faults")
+	}
+}
+
+// --- chaosEngine.Activate / Deactivate / Load ---
+
+func TestChaosEngine_Activate_SwitchesProfileAndResetsStats(t *testing.T) {
+	// Given: an active profile that injected faults
+	e, _ := newChaosEngine(chaosConfig{Profile: "provider-incident"})
+	e.Draw(1)
+	// When
+	status, err := e.Activate("Slow-Degradation")
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Active != "slow-degradation" || status.Source != chaosSourceAdmin || status.Stats.Requests != 0 || status.Intensity >= 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if _, err := e.Activate("meltdown"); err == nil {
+		t.Error("expected an unknown profile error")
+	}
+	if e.Status().Active != "slow-degradation" {
+		t.Error("expected the active profile to be kept after an error")
+	}
+}
+
+func TestChaosEngine_Deactivate_StopsFaults(t *testing.T) {
+	// Given
+	e, _ := newChaosEngine(chaosConfig{Profile: "flaky-network"})
+	// When
+	status := e.Deactivate()
+	// Then
+	if e.Active() || status.Active != "" || e.Draw(1) != (chaosFault{}) {
+		t.Errorf("expected chaos to be off, got %+v", status)
+	}
+}
+
+func TestChaosEngine_Define_AddsAndActivatesRuntimeProfile(t *testing.T) {
+	// Given
+	e, _ := newChaosEngine(chaosConfig{})
+
+	// When: a profile is defined, then redefined with other faults
+	if _, err := e.Define(chaosProfileConfig{Name: "tui", Latency: "1s"}); err != nil {
+		t.Fatal(err)
+	}
+	status, err := e.Define(chaosProfileConfig{Name: "tui", ErrorRate: 1})
+
+	// Then: the latest definition is active
+	if err != nil || status.Active != "tui" || status.Source != chaosSourceAdmin {
+		t.Fatalf("expected tui to be active, got %+v, %v", status, err)
+	}
+	if fault := e.Draw(1); fault.Latency != 0 || fault.Err == nil || fault.Err.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected the redefined faults, got %+v", fault)
+	}
+
+	// When: a built-in profile is redefined
+	_, err = e.Define(chaosProfileConfig{Name: "flaky-network"})
+
+	// Then: it is refused
+	if err == nil {
+		t.Error("expected built-in profiles to be kept")
+	}
+
+	// When: the config is reloaded
+	if err := e.Load(chaosConfig{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: the defined profile is dropped
+	if _, err := e.Activate("tui"); err == nil {
+		t.Error("expected the defined profile to be dropped on reload")
+	}
+}
+
+func TestChaosEngine_Load_ReplacesRuntimeActivation(t *testing.T) {
+	// Given: a profile activated at runtime
+	e, _ := newChaosEngine(chaosConfig{})
+	_, _ = e.Activate("flaky-network")
+	// When: the config is reloaded without chaos
+	if err := e.Load(chaosConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	// Then
+	if e.Active() {
+		t.Error("expected chaos to be off after reload")
+	}
+	// When: an invalid config is loaded
+	_, _ = e.Activate("flaky-network")
+	err := e.Load(chaosConfig{Profile: "meltdown"})
+	// Then: the active profile is kept
+	if err == nil || e.Status().Active != "flaky-network" {
+		t.Errorf("expected an error and flaky-network to stay active, got %v", err)
+	}
+}
+
+func TestChaosEngine_Concurrent_CountsEveryRequest(t *testing.T) {
+	// Given: an active profile
+	e, _ := newChaosEngine(chaosConfig{Profile: "provider-incident"})
+	var wg sync.WaitGroup
+	// When: faults are drawn while the status is read concurrently
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 100 {
+				if e.Draw(uint64(i*100 + j)).Truncate {
+					e.Truncated()
+				}
+				_ = e.Status()
+			}
+		}()
+	}
+	wg.Wait()
+	// Then
+	stats := e.Status().Stats
+	if stats.Requests != 800 || stats.Errors+stats.RateLimited > 800 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// maxClockOffset bounds how far the virtual clock can be advanced.
+const maxClockOffset = 10 * 365 * 24 * time.Hour
+
+// virtualClock is the time stored objects expire by: the wall clock shifted by an offset that
+// POST /_mokku/clock/advance moves forward, so expirations measured in hours can be triggered in
+// tests. It is safe for concurrent use.
+type virtualClock struct {
+	offset atomic.Int64
+	now    func() time.Time
+}
+
+// clockStatus is the state of the virtual clock, as returned by GET /_mokku/clock.
+type clockStatus struct {
+	Now           time.Time `json:"now"`
+	OffsetSeconds float64   `json:"offset_seconds"`
+}
+
+// clockAdvanceRequest is the request body for POST /_mokku/clock/advance.
+type clockAdvanceRequest struct {
+	Seconds float64 `json:"seconds"`
+}
+
+// newVirtualClock creates a clock that follows the wall clock.
+func newVirtualClock() *virtualClock {
+	return &virtualClock{now: time.Now}
+}
+
+// Now returns the virtual time.
+func (c *virtualClock) Now() time.Time {
+	return c.now().Add(time.Duration(c.offset.Load()))
+}
+
+// Advance moves the clock forward by a positive number of seconds, up to maxClockOffset in total.
+func (c *virtualClock) Advance(seconds float64) (clockStatus, error) {
+	if seconds <= 0 {
+		return clockStatus{}, fmt.Errorf("seconds must be positive")
+	}
+	if seconds > maxClockOffset.Seconds() {
+		// Clamped so the conversion cannot overflow; it still fails the total check below.
+		seconds = maxClockOffset.Seconds() + 1
+	}
+	d := time.Duration(seconds * float64(time.Second))
+	for {
+		offset := c.offset.Load()
+		if time.Duration(offset) > maxClockOffset-d {
+			return clockStatus{}, fmt.Errorf("the clock can be advanced by at most %.0f seconds in total", maxClockOffset.Seconds())
+		}
+		if c.offset.CompareAndSwap(offset, offset+int64(d)) {
+			return c.Status(), nil
+		}
+	}
+}
+
+// Offset returns how far the clock was advanced.
+func (c *virtualClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Restore sets the offset handed off by a previous process, up to maxClockOffset.
+func (c *virtualClock) Restore(offset time.Duration) {
+	c.offset.Store(int64(min(max(offset, 0), maxClockOffset)))
+}
+
+// Reset puts the clock back on the wall clock.
+func (c *virtualClock) Reset() clockStatus {
+	c.offset.Store(0)
+	return c.Status()
+}
+
+// Status returns the virtual time and its offset from the wall clock.
+func (c *virtualClock) Status() clockStatus {
+	offset := time.Duration(c.offset.Load())
+	return clockStatus{Now: c.now().Add(offset), OffsetSeconds: offset.Seconds()}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestClock creates a virtual clock whose wall clock is stopped at a fixed time.
+func newTestClock() *virtualClock {
+	c := newVirtualClock()
+	wall := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return wall }
+	return c
+}
+
+func TestVirtualClock_AdvanceAndReset(t *testing.T) {
+	// Given
+	c := newTestClock()
+	wall := c.now()
+
+	// When
+	if _, err := c.Advance(90); err != nil {
+		t.Fatal(err)
+	}
+	status, err := c.Advance(0.5)
+
+	// Then: advances add up, and a reset returns to the wall clock
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.OffsetSeconds != 90.5 || !c.Now().Equal(wall.Add(90500*time.Millisecond)) {
+		t.Errorf("expected a 90.5s offset, got %+v", status)
+	}
+	if reset := c.Reset(); reset.OffsetSeconds != 0 || !reset.Now.Equal(wall) {
+		t.Errorf("expected the wall clock after a reset, got %+v", reset)
+	}
+}
+
+func TestVirtualClock_Advance_RejectsInvalidSeconds(t *testing.T) {
+	// Given
+	c := newTestClock()
+
+	// When / Then
+	for _, seconds := range []float64{0, -1, maxClockOffset.Seconds() + 1, 1e300} {
+		if _, err := c.Advance(seconds); err == nil {
+			t.Errorf("expected %g seconds to be rejected", seconds)
+		}
+	}
+	if c.Status().OffsetSeconds != 0 {
+		t.Errorf("expected rejected advances to leave the clock, got %+v", c.Status())
+	}
+}
+
+func TestVirtualClock_Concurrent_AdvanceAndRead(t *testing.T) {
+	// Given
+	c := newTestClock()
+	var wg sync.WaitGroup
+
+	// When: advances and reads race
+	for range 50 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _ = c.Advance(1)
+		}()
+		go func() {
+			defer wg.Done()
+			_ = c.Now()
+			_ = c.Status()
+		}()
+	}
+	wg.Wait()
+
+	// Then: no advance is lost
+	if got := c.Status().OffsetSeconds; got != 50 {
+		t.Errorf("expected a 50s offset, got %g", got)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// instanceHeader is the response header naming the mokku instance that served the request.
+const instanceHeader = "X-Mokku-Instance"
+
+// Session affinity between replicas (MOKKU_PEERS).
+const (
+	// affinityHeader carries a key, such as a test run ID, whose requests are all served by the
+	// replica the key hashes to.
+	affinityHeader = "X-Mokku-Affinity"
+	// affinityCookie names the replica that serves a client keeping cookies; it is set on responses
+	// to requests without affinity.
+	affinityCookie = "mokku_affinity"
+	// forwardedHeader names the replica that forwarded a request, which is then served where it
+	// arrives.
+	forwardedHeader = "X-Mokku-Forwarded-By"
+)
+
+// statefulEndpoints lists the endpoints whose responses depend on, or change, state kept in memory by
+// a single instance. Behind a load balancer with several replicas they need session affinity, from
+// the load balancer or from MOKKU_PEERS.
+var statefulEndpoints = []string{
+	"GET " + adminPathPrefix + "/images/{id} (URLs returned by POST /v1/images/generations)",
+	"POST " + adminPathPrefix + "/embeddings/search (inputs sent to POST /v1/embeddings)",
+	"GET " + adminPathPrefix + "/streams (streams served by the same instance)",
+	"GET " + adminPathPrefix + "/connections (connections accepted by the same instance)",
+	"GET " + adminPathPrefix + "/overhead (requests served by the same instance)",
+	"GET " + adminPathPrefix + "/requests (requests captured by the same instance)",
+	"GET " + adminPathPrefix + "/capture (requests captured by the same instance)",
+	"GET " + adminPathPrefix + "/tags (requests tagged by the same instance)",
+	"GET " + adminPathPrefix + "/alerts (client behavior observed by the same instance)",
+	"PUT " + adminPathPrefix + "/scenarios (scenario rules replaced on the same instance)",
+	"DELETE " + adminPathPrefix + "/scenarios/{name} (scenario rules deleted on the same instance)",
+	"POST " + adminPathPrefix + "/scenarios/{name}/restore (scenario rules restored from the same instance)",
+	"GET " + adminPathPrefix + "/verify (unexpected requests counted by the same instance)",
+	"PUT " + adminPathPrefix + "/flags/{name} (feature flags toggled on the same instance)",
+	"PUT " + adminPathPrefix + "/regions/{name} (region outages set on the same instance)",
+	"PUT " + adminPathPrefix + "/chaos (chaos profile activated on the same instance)",
+	"GET " + adminPathPrefix + "/timeline (faults injected by the same instance)",
+	"PUT " + adminPathPrefix + "/banned-users/{id} (users banned on the same instance)",
+	"POST " + adminPathPrefix + "/clock/advance (virtual clock advanced on the same instance)",
+	"POST /v1/* with rate_limits (usage counted by the same instance)",
+}
+
+// resolveInstanceID returns the ID of this instance: MOKKU_INSTANCE_ID if set, otherwise the
+// hostname (the pod name on Kubernetes).
+func resolveInstanceID() string {
+	if id := os.Getenv("MOKKU_INSTANCE_ID"); id != "" {
+		return id
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		return hostname
+	}
+	return "mokku"
+}
+
+// withInstanceHeader sets instanceHeader on every response so clients and load balancers can
+// tell which replica served a request.
+func withInstanceHeader(instanceID string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(instanceHeader, instanceID)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// warnIfReplicated logs a warning listing the stateful endpoints when MOKKU_REPLICAS declares
+// more than one replica without MOKKU_PEERS, since state is not shared between instances.
+func warnIfReplicated(affinity *affinityRouter) {
+	replicas, err := strconv.Atoi(os.Getenv("MOKKU_REPLICAS"))
+	if err != nil || replicas <= 1 || affinity != nil {
+		return
+	}
+	log.Printf("Warning: running as one of %d replicas without shared state. "+
+		"Set MOKKU_PEERS or configure session affinity on the load balancer for these endpoints:\n  %s",
+		replicas, strings.Join(statefulEndpoints, "\n  "))
+}
+
+// affinityPeer is a replica requests can be forwarded to.
+type affinityPeer struct {
+	id    string
+	proxy *httputil.ReverseProxy
+}
+
+// affinityRouter gives replicas session affinity behind a load balancer that spreads requests: a
+// request is forwarded to the replica owning its affinity key, so the state it creates or reads
+// stays on one instance. The key is the affinityHeader, hashed to a replica by rendezvous hashing,
+// or else the affinityCookie naming one. Requests without either are served where they arrive and
+// get the cookie naming this instance.
+type affinityRouter struct {
+	self  string
+	peers []affinityPeer
+}
+
+// affinityRouterFromEnv reads MOKKU_PEERS, a comma-separated list of id=url for every replica,
+// this one (instanceID) included. It returns nil when MOKKU_PEERS is not set.
+func affinityRouterFromEnv(instanceID, value string) (*affinityRouter, error) {
+	if value == "" {
+		return nil, nil
+	}
+	a := &affinityRouter{self: instanceID}
+	var found bool
+	for _, entry := range strings.Split(value, ",") {
+		id, raw, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		target, err := url.Parse(raw)
+		if !ok || id == "" || err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return nil, fmt.Errorf("MOKKU_PEERS entries must be id=http(s)://host:port, got %q", entry)
+		}
+		if a.peer(id) != nil {
+			return nil, fmt.Errorf("MOKKU_PEERS lists %q twice", id)
+		}
+		found = found || id == instanceID
+		a.peers = append(a.peers, affinityPeer{id: id, proxy: newAffinityProxy(instanceID, id, target)})
+	}
+	if !found {
+		return nil, fmt.Errorf("MOKKU_PEERS must list this instance (%q); set MOKKU_INSTANCE_ID to its id", instanceID)
+	}
+	return a, nil
+}
+
+// newAffinityProxy forwards requests to the replica id at target, streaming responses as they are
+// written.
+func newAffinityProxy(self, id string, target *url.URL) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+			r.SetXForwarded()
+			r.Out.Header.Set(forwardedHeader, self)
+		},
+		FlushInterval: -1,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			log.Printf("Forwarding %s %s to replica %s failed: %v", r.Method, r.URL.Path, id, err)
+			writeOpenAIError(w, http.StatusBadGateway, OpenAIErrorDetail{
+				Message: fmt.Sprintf("The mokku replica %s owning this request's affinity is unreachable.", id),
+				Type:    "server_error",
+				Code:    "replica_unreachable",
+			})
+		},
+	}
+}
+
+// peer returns the replica with id, or nil.
+func (a *affinityRouter) peer(id string) *affinityPeer {
+	for i := range a.peers {
+		if a.peers[i].id == id {
+			return &a.peers[i]
+		}
+	}
+	return nil
+}
+
+// owner returns the replica a request has affinity with, or nil when it has none.
+func (a *affinityRouter) owner(r *http.Request) *affinityPeer {
+	if key := r.Header.Get(affinityHeader); key != "" {
+		return a.hash(key)
+	}
+	if c, err := r.Cookie(affinityCookie); err == nil {
+		return a.peer(c.Value)
+	}
+	return nil
+}
+
+// hash returns the replica key hashes to by rendezvous hashing, so adding or removing a replica only
+// moves the keys of that replica.
+func (a *affinityRouter) hash(key string) *affinityPeer {
+	var owner *affinityPeer
+	var best uint64
+	for i := range a.peers {
+		sum := sha256.Sum256([]byte(a.peers[i].id + "\x00" + key))
+		if score := binary.BigEndian.Uint64(sum[:8]); owner == nil || score > best {
+			owner, best = &a.peers[i], score
+		}
+	}
+	return owner
+}
+
+// withAffinity forwards requests to the replica owning their affinity and serves the others with
+// next. Forwarded requests are always served where they arrive, so replicas disagreeing on
+// MOKKU_PEERS cannot loop. Without a router, next serves every request.
+func withAffinity(a *affinityRouter, next http.Handler) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(forwardedHeader) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		owner := a.owner(r)
+		if owner == nil {
+			http.SetCookie(w, &http.Cookie{Name: affinityCookie, Value: a.self, Path: "/", HttpOnly: true, SameSite: http.SameSiteLaxMode})
+			next.ServeHTTP(w, r)
+			return
+		}
+		if owner.id == a.self {
+			next.ServeHTTP(w, r)
+			return
+		}
+		owner.proxy.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// --- resolveInstanceID ---
+
+func TestResolveInstanceID_EnvOverridesHostname(t *testing.T) {
+	// Given
+	t.Setenv("MOKKU_INSTANCE_ID", "replica-7")
+	// When
+	got := resolveInstanceID()
+	// Then
+	if got != "replica-7" {
+		t.Errorf("expected replica-7, got %q", got)
+	}
+}
+
+func TestResolveInstanceID_DefaultsToNonEmpty(t *testing.T) {
+	// Given: no override
+	t.Setenv("MOKKU_INSTANCE_ID", "")
+	// When
+	got := resolveInstanceID()
+	// Then
+	if got == "" {
+		t.Error("expected a non-empty instance ID")
+	}
+}
+
+// --- withInstanceHeader ---
+
+func TestWithInstanceHeader_SetsHeader(t *testing.T) {
+	// Given
+	h := withInstanceHeader("replica-7", http.NotFoundHandler())
+	rec := httptest.NewRecorder()
+	// When
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	// Then: the header is set even on error responses
+	if got := rec.Header().Get(instanceHeader); got != "replica-7" {
+		t.Errorf("expected %s=replica-7, got %q", instanceHeader, got)
+	}
+}
+
+// --- statefulEndpoints ---
+
+func TestStatefulEndpoints_ListsEveryInstanceChange(t *testing.T) {
+	// Given: the control endpoints setting or replacing state, which other replicas do not see
+	state, err := newServerState(Config{}, serverOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewAdminHandler(state, "replica-1")
+	for _, route := range h.routes {
+		pattern := route.Method + " " + route.Path
+		if h.readOnly[pattern] || route.Method == http.MethodDelete {
+			continue
+		}
+		// When
+		listed := slices.ContainsFunc(statefulEndpoints, func(e string) bool { return strings.HasPrefix(e, pattern+" ") })
+		// Then
+		if !listed {
+			t.Errorf("expected %s in statefulEndpoints", pattern)
+		}
+	}
+}
+
+// --- affinityRouterFromEnv ---
+
+func TestAffinityRouterFromEnv(t *testing.T) {
+	// Given
+	for value, wantErr := range map[string]bool{
+		"":                                    false,
+		"a=http://a:8080,b=http://b:8080":     false,
+		" a=http://a:8080 , b=https://b":      false,
+		"b=http://b:8080":                     true,
+		"a=http://a:8080,a=http://other:8080": true,
+		"a=a:8080":                            true,
+		"a=http://a:8080,http://b:8080":       true,
+		"a=http://a:8080,b=ftp://b:21":        true,
+	} {
+		// When
+		_, err := affinityRouterFromEnv("a", value)
+		// Then
+		if (err != nil) != wantErr {
+			t.Errorf("%q: expected error %t, got %v", value, wantErr, err)
+		}
+	}
+}
+
+// --- affinityRouter.hash ---
+
+func TestAffinityRouter_Hash_IsStableAndSpreadsKeys(t *testing.T) {
+	// Given
+	a, err := affinityRouterFromEnv("a", "a=http://a:8080,b=http://b:8080,c=http://c:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// When: 300 keys are hashed twice
+	owners := map[string]int{}
+	for i := range 300 {
+		key := fmt.Sprintf("run-%d", i)
+		owner := a.hash(key)
+		if again := a.hash(key); again != owner {
+			t.Fatalf("%s: expected a stable owner, got %s then %s", key, owner.id, again.id)
+		}
+		owners[owner.id]++
+	}
+	// Then: every replica owns some keys
+	for _, id := range []string{"a", "b", "c"} {
+		if owners[id] < 50 {
+			t.Errorf("expected replica %s to own a share of the keys, got %v", id, owners)
+		}
+	}
+}
+
+// --- withAffinity ---
+
+// newAffinityPair returns a router for replica "a" whose peer "b" is served by a test server
+// answering with the replica forwarding to it.
+func newAffinityPair(t *testing.T) (*affinityRouter, http.Handler) {
+	t.Helper()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "b, forwarded by %s", r.Header.Get(forwardedHeader))
+	}))
+	t.Cleanup(b.Close)
+	a, err := affinityRouterFromEnv("a", "a=http://127.0.0.1:1,b="+b.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	local := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = fmt.Fprint(w, "a") })
+	return a, withAffinity(a, local)
+}
+
+// affinityKey returns a key the router hashes to replica id.
+func affinityKey(t *testing.T, a *affinityRouter, id string) string {
+	t.Helper()
+	for i := range 1000 {
+		if key := fmt.Sprintf("run-%d", i); a.hash(key).id == id {
+			return key
+		}
+	}
+	t.Fatalf("no key hashes to %s", id)
+	return ""
+}
+
+func TestWithAffinity_RoutesByHeaderAndCookie(t *testing.T) {
+	// Given
+	a, h := newAffinityPair(t)
+	tests := []struct {
+		name   string
+		header string
+		cookie string
+		want   string
+	}{
+		{"key owned by this replica", affinityKey(t, a, "a"), "", "a"},
+		{"key owned by the peer", affinityKey(t, a, "b"), "", "b, forwarded by a"},
+		{"header before cookie", affinityKey(t, a, "a"), "b", "a"},
+		{"cookie naming the peer", "", "b", "b, forwarded by a"},
+		{"cookie naming an unknown replica", "", "gone", "a"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		if tt.header != "" {
+			req.Header.Set(affinityHeader, tt.header)
+		}
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: affinityCookie, Value: tt.cookie})
+		}
+		rec := httptest.NewRecorder()
+		// When
+		h.ServeHTTP(rec, req)
+		// Then
+		if rec.Body.String() != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.want, rec.Body.String())
+		}
+	}
+}
+
+func TestWithAffinity_SetsCookieWithoutAffinity(t *testing.T) {
+	// Given
+	_, h := newAffinityPair(t)
+	rec := httptest.NewRecorder()
+	// When
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/models", nil))
+	// Then: the request is served here, and the cookie pins the client to this replica
+	cookies := rec.Result().Cookies()
+	if rec.Body.String() != "a" || len(cookies) != 1 || cookies[0].Name != affinityCookie || cookies[0].Value != "a" {
+		t.Errorf("expected a local response with the affinity cookie, got %q and %v", rec.Body.String(), cookies)
+	}
+}
+
+func TestWithAffinity_ServesForwardedRequestsLocally(t *testing.T) {
+	// Given: a request another replica forwarded, whose key hashes to the peer
+	a, h := newAffinityPair(t)
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set(affinityHeader, affinityKey(t, a, "b"))
+	req.Header.Set(forwardedHeader, "b")
+	rec := httptest.NewRecorder()
+	// When
+	h.ServeHTTP(rec, req)
+	// Then: it is not forwarded again
+	if rec.Body.String() != "a" {
+		t.Errorf("expected a local response, got %q", rec.Body.String())
+	}
+}
+
+func TestWithAffinity_UnreachableOwnerReturnsBadGateway(t *testing.T) {
+	// Given: a peer nothing listens on
+	a, err := affinityRouterFromEnv("a", "a=http://127.0.0.1:1,b=http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := withAffinity(a, http.NotFoundHandler())
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set(affinityHeader, affinityKey(t, a, "b"))
+	rec := httptest.NewRecorder()
+	// When
+	h.ServeHTTP(rec, req)
+	// Then
+	if rec.Code != http.StatusBadGateway || !strings.Contains(rec.Body.String(), "replica_unreachable") {
+		t.Errorf("expected 502 replica_unreachable, got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// coldStartHeader is the response header carrying the cold-start latency added to a request, in
+// milliseconds.
+const coldStartHeader = "X-Mokku-Cold-Start"
+
+// coldStartAnyModel is the cold_start key applying to models without their own entry.
+const coldStartAnyModel = "*"
+
+// maxColdStartModels is the number of models whose warm state is tracked; the least recently added
+// model is forgotten (and starts cold again) beyond it.
+const maxColdStartModels = 1000
+
+// coldStartConfig is the cold-start behavior of a model in the cold_start section of the config
+// file, keyed by model ID or "*" for every other model.
+type coldStartConfig struct {
+	// Latency is added to each request served while the model is cold.
+	Latency string `yaml:"latency" json:"latency"`
+	// Requests is the number of requests served cold after startup or an idle period (1 if unset).
+	Requests int `yaml:"requests" json:"requests,omitempty"`
+	// Idle makes the model cold again after this long without requests; unset means never.
+	Idle string `yaml:"idle" json:"idle,omitempty"`
+}
+
+// coldStartRule is a compiled coldStartConfig.
+type coldStartRule struct {
+	latency  time.Duration
+	requests int
+	idle     time.Duration
+}
+
+// modelWarmth is the warm state of one model.
+type modelWarmth struct {
+	served int
+	last   time.Time
+}
+
+// coldStartTracker emulates provider cold starts: the first requests to a model after startup, or
+// after it has been idle, are delayed, so connection-warming and pre-flight strategies can be
+// measured. It is safe for concurrent use.
+type coldStartTracker struct {
+	mu     sync.Mutex
+	rules  map[string]coldStartRule
+	models *boundedMap[string, *modelWarmth]
+	now    func() time.Time
+}
+
+// newColdStartTracker creates a tracker with the configured cold starts; every model starts cold.
+func newColdStartTracker(cfg map[string]coldStartConfig) (*coldStartTracker, error) {
+	t := &coldStartTracker{models: newBoundedMap[string, *modelWarmth](maxColdStartModels), now: time.Now}
+	if err := t.Load(cfg); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Load replaces the configured cold starts. Models keep their warm state. On error the current
+// configuration is kept.
+func (t *coldStartTracker) Load(cfg map[string]coldStartConfig) error {
+	return installNow(t.prepare(cfg))
+}
+
+// prepare validates the cold starts and returns the function installing them.
+func (t *coldStartTracker) prepare(cfg map[string]coldStartConfig) (func(), error) {
+	rules := make(map[string]coldStartRule, len(cfg))
+	for model, c := range cfg {
+		rule, err := compileColdStart(c)
+		if err != nil {
+			return nil, fmt.Errorf("cold_start.%s: %w", model, err)
+		}
+		rules[model] = rule
+	}
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.rules = rules
+	}, nil
+}
+
+// compileColdStart validates a model's cold-start config.
+func compileColdStart(cfg coldStartConfig) (coldStartRule, error) {
+	rule := coldStartRule{requests: cfg.Requests}
+	if rule.requests < 0 {
+		return rule, fmt.Errorf("requests must not be negative, got %d", cfg.Requests)
+	}
+	if rule.requests == 0 {
+		rule.requests = 1
+	}
+	durations := []struct {
+		field string
+		value string
+		dst   *time.Duration
+	}{{"latency", cfg.Latency, &rule.latency}, {"idle", cfg.Idle, &rule.idle}}
+	for _, d := range durations {
+		if d.value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(d.value)
+		if err != nil || parsed < 0 {
+			return rule, fmt.Errorf("%s must be a duration such as 500ms, got %q", d.field, d.value)
+		}
+		*d.dst = parsed
+	}
+	if rule.latency == 0 {
+		return rule, fmt.Errorf("latency is required")
+	}
+	return rule, nil
+}
+
+// Active reports whether any cold start is configured.
+func (t *coldStartTracker) Active() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.rules) > 0
+}
+
+// Serve records a request to model and returns the cold-start latency to add to it, 0 when the
+// model is warm or has no cold start.
+func (t *coldStartTracker) Serve(model string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rule, ok := t.rules[model]
+	if !ok {
+		if rule, ok = t.rules[coldStartAnyModel]; !ok || model == "" {
+			return 0
+		}
+	}
+	now := t.now()
+	state, ok := t.models.Get(model)
+	if !ok {
+		state = &modelWarmth{}
+		t.models.Put(model, state)
+	}
+	if rule.idle > 0 && !state.last.IsZero() && now.Sub(state.last) >= rule.idle {
+		state.served = 0
+	}
+	state.last = now
+	if state.served >= rule.requests {
+		return 0
+	}
+	state.served++
+	return rule.latency
+}
+
+// requestModel returns the model of a decoded request body, or "".
+func requestModel(doc any) string {
+	m, _ := doc.(map[string]any)
+	model, _ := m["model"].(string)
+	return model
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// --- newColdStartTracker ---
+
+func TestNewColdStartTracker_RejectsInvalidConfig(t *testing.T) {
+	// Given
+	for name, cfg := range map[string]coldStartConfig{
+		"no latency":        {Requests: 2},
+		"bad latency":       {Latency: "soon"},
+		"negative requests": {Latency: "1s", Requests: -1},
+		"bad idle":          {Latency: "1s", Idle: "-5m"},
+	} {
+		// When
+		_, err := newColdStartTracker(map[string]coldStartConfig{"gpt-4o": cfg})
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// --- coldStartTracker.Serve ---
+
+func TestColdStartTracker_Serve_FirstRequestsAreCold(t *testing.T) {
+	// Given: gpt-4o is cold for its first two requests and every other model for one
+	tr, err := newColdStartTracker(map[string]coldStartConfig{
+		"gpt-4o":          {Latency: "2s", Requests: 2},
+		coldStartAnyModel: {Latency: "500ms"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	got := []time.Duration{tr.Serve("gpt-4o"), tr.Serve("gpt-4o"), tr.Serve("gpt-4o"), tr.Serve("gpt-4o-mini"), tr.Serve("gpt-4o-mini"), tr.Serve("")}
+
+	// Then
+	want := []time.Duration{2 * time.Second, 2 * time.Second, 0, 500 * time.Millisecond, 0, 0}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("request %d: expected %v, got %v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestColdStartTracker_Serve_ColdAgainAfterIdle(t *testing.T) {
+	// Given: a model that goes cold after a minute without requests, warmed by one request
+	tr, _ := newColdStartTracker(map[string]coldStartConfig{"gpt-4o": {Latency: "1s", Idle: "1m"}})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+	tr.Serve("gpt-4o")
+
+	// When / Then: requests within the idle period are warm and keep it warm
+	now = now.Add(59 * time.Second)
+	if got := tr.Serve("gpt-4o"); got != 0 {
+		t.Errorf("expected a warm request, got %v", got)
+	}
+	now = now.Add(59 * time.Second)
+	if got := tr.Serve("gpt-4o"); got != 0 {
+		t.Errorf("expected a warm request, got %v", got)
+	}
+
+	// When / Then: a request after the idle period is cold again
+	now = now.Add(time.Minute)
+	if got := tr.Serve("gpt-4o"); got != time.Second {
+		t.Errorf("expected a cold request, got %v", got)
+	}
+}
+
+func TestColdStartTracker_Concurrent_ServesConfiguredColdRequests(t *testing.T) {
+	// Given: a model cold for its first ten requests
+	tr, _ := newColdStartTracker(map[string]coldStartConfig{coldStartAnyModel: {Latency: "1s", Requests: 10}})
+	var mu sync.Mutex
+	cold := 0
+	var wg sync.WaitGroup
+	// When: requests to the model and to 800 others are served concurrently
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 200 {
+				if tr.Serve("gpt-4o") > 0 {
+					mu.Lock()
+					cold++
+					mu.Unlock()
+				}
+				tr.Serve(fmt.Sprintf("model-%d-%d", i, j%100))
+			}
+		}()
+	}
+	wg.Wait()
+	// Then: exactly ten requests were cold and the tracked models stay bounded
+	if cold != 10 {
+		t.Errorf("expected 10 cold requests, got %d", cold)
+	}
+	if n := tr.models.Len(); n > maxColdStartModels {
+		t.Errorf("expected at most %d tracked models, got %d", maxColdStartModels, n)
+	}
+}
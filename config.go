@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/go-faster/yaml"
+)
+
+// currentConfigVersion is the config file format version written by this build.
+const currentConfigVersion = 1
+
+// Config is the optional configuration file named by MOKKU_CONFIG.
+type Config struct {
+	// Version is the config file format version. Older versions are migrated on load.
+	Version int `yaml:"version" json:"version"`
+	// Features enables or disables feature flags by name.
+	Features map[string]bool `yaml:"features" json:"features"`
+	// Models overrides the metadata of built-in models and adds new models.
+	Models []modelMetadata `yaml:"models" json:"models"`
+	// Moderation configures the banned phrase filter.
+	Moderation moderationConfig `yaml:"moderation" json:"moderation"`
+	// Admin configures the tokens and roles of the control API.
+	Admin adminConfig `yaml:"admin" json:"admin"`
+	// RateLimits configures per-tenant request and token budgets.
+	RateLimits rateLimitConfig `yaml:"rate_limits" json:"rate_limits"`
+	// Regions simulates the health of regions selected with the X-Mokku-Region header.
+	Regions []regionConfig `yaml:"regions" json:"regions"`
+	// Chaos defines chaos profiles and the one active at startup.
+	Chaos chaosConfig `yaml:"chaos" json:"chaos"`
+	// ColdStart delays the first requests to a model, keyed by model ID or "*" for every model.
+	ColdStart map[string]coldStartConfig `yaml:"cold_start" json:"cold_start"`
+	// StreamingCadence overrides the streaming speed of built-in model families and adds new ones.
+	StreamingCadence []cadenceConfig `yaml:"streaming_cadence" json:"streaming_cadence"`
+	// EndpointFidelity sets the fidelity level (strict, lenient, or disabled) of endpoints, keyed by
+	// method and path, path, or "*" for every other endpoint.
+	EndpointFidelity map[string]string `yaml:"endpoint_fidelity" json:"endpoint_fidelity"`
+	// OverheadSLO is the objective for mokku's own processing time, keyed by endpoint (such as
+	// "POST /v1/chat/completions") or "*" for every endpoint.
+	OverheadSLO map[string]string `yaml:"overhead_slo" json:"overhead_slo"`
+	// TLS serves HTTPS and optionally requires client certificates.
+	TLS tlsConfig `yaml:"tls" json:"tls"`
+	// Proxies lists the trusted load balancers whose PROXY protocol and X-Forwarded-For headers name
+	// the client.
+	Proxies proxyConfig `yaml:"proxies" json:"proxies"`
+	// Tags classifies API requests; every matching rule adds its tag.
+	Tags []tagConfig `yaml:"tags" json:"tags"`
+	// Alerts reports regressions in client behavior.
+	Alerts alertConfig `yaml:"alerts" json:"alerts"`
+}
+
+// configMigration upgrades a config document from version From to From+1.
+type configMigration struct {
+	From        int
+	Description string
+	Migrate     func(doc map[string]any) error
+}
+
+// configMigrations lists the migrations from every older config version, in order. When the format
+// changes, bump currentConfigVersion and append a migration from the previous version.
+var configMigrations = []configMigration{
+	{
+		From:        0,
+		Description: "files without a version field are version 1; add \"version: 1\"",
+		Migrate:     func(doc map[string]any) error { return nil },
+	},
+}
+
+// configKeys are the top-level keys understood by the current config version.
+var configKeys = map[string]bool{"version": true, "features": true, "models": true, "moderation": true, "admin": true, "rate_limits": true, "regions": true, "chaos": true, "cold_start": true, "streaming_cadence": true, "endpoint_fidelity": true, "overhead_slo": true, "tls": true, "proxies": true, "tags": true, "alerts": true, "include": true}
+
+// loadConfig reads the YAML (or JSON) config file at path with the files it includes and its
+// MOKKU_ENV overlays (see loadFragments), migrating older versions and logging a warning for each
+// applied migration and unknown key. The fragments are merged with mergeDocs, later ones winning.
+// An empty path yields the zero Config.
+func loadConfig(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+	fragments, err := loadFragments(path, os.Getenv(envVar))
+	if err != nil {
+		return cfg, fmt.Errorf("failed to load config: %w", err)
+	}
+	merged := map[string]any{}
+	for _, f := range fragments {
+		doc, warnings, err := migrateConfig(f.data)
+		if err != nil {
+			return cfg, fmt.Errorf("failed to parse config %s: %w", f.path, err)
+		}
+		for _, warning := range warnings {
+			log.Printf("Warning: config %s: %s", f.path, warning)
+		}
+		delete(doc, "include")
+		mergeDocs(merged, doc)
+	}
+	if cfg, err = decodeConfig(merged); err != nil {
+		return cfg, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// parseConfig decodes a config document, migrating it to currentConfigVersion. It returns warnings
+// for applied migrations and for keys the current version does not understand.
+func parseConfig(data []byte) (Config, []string, error) {
+	doc, warnings, err := migrateConfig(data)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	cfg, err := decodeConfig(doc)
+	return cfg, warnings, err
+}
+
+// migrateConfig decodes a config document into a map migrated to currentConfigVersion, with the
+// warnings of parseConfig.
+func migrateConfig(data []byte) (map[string]any, []string, error) {
+	doc := map[string]any{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	version := 0
+	if v, ok := doc["version"]; ok {
+		n, ok := v.(int)
+		if !ok || n < 1 {
+			return nil, nil, fmt.Errorf("version must be a positive integer, got %v", v)
+		}
+		version = n
+	}
+	if version > currentConfigVersion {
+		return nil, nil, fmt.Errorf("config version %d is newer than the supported version %d; upgrade mokku",
+			version, currentConfigVersion)
+	}
+
+	var warnings []string
+	for _, m := range configMigrations {
+		if m.From < version {
+			continue
+		}
+		if err := m.Migrate(doc); err != nil {
+			return nil, nil, fmt.Errorf("migrating config from version %d: %w", m.From, err)
+		}
+		warnings = append(warnings, fmt.Sprintf("migrated from version %d to %d: %s", m.From, m.From+1, m.Description))
+	}
+	doc["version"] = currentConfigVersion
+
+	var unknown []string
+	for key := range doc {
+		if !configKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		warnings = append(warnings, fmt.Sprintf("unknown key %q is ignored", key))
+	}
+
+	return doc, warnings, nil
+}
+
+// decodeConfig decodes a migrated config document.
+func decodeConfig(doc map[string]any) (Config, error) {
+	var cfg Config
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(migrated, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// installNow installs a config section prepared by one of the prepare methods, or returns the error
+// it failed validation with. A reload prepares every section before installing any, see
+// runtimeControls.reload.
+func installNow(install func(), err error) error {
+	if err != nil {
+		return err
+	}
+	install()
+	return nil
+}
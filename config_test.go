@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// --- loadConfig ---
+
+func TestLoadConfig_EmptyPath(t *testing.T) {
+	// When
+	cfg, err := loadConfig("")
+	// Then
+	if err != nil || len(cfg.Features) != 0 {
+		t.Errorf("expected empty config, got %+v, %v", cfg, err)
+	}
+}
+
+func TestLoadConfig_ReadsFeatures(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "mokku.yml")
+	if err := os.WriteFile(path, []byte("features:\n  strict_model_validation: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// When
+	cfg, err := loadConfig(path)
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Features[flagStrictModelValidation] {
+		t.Errorf("expected strict_model_validation enabled, got %+v", cfg)
+	}
+}
+
+func TestLoadConfig_MissingFile(t *testing.T) {
+	// When
+	_, err := loadConfig(filepath.Join(t.TempDir(), "missing.yml"))
+	// Then
+	if err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+// --- parseConfig ---
+
+func TestParseConfig_CurrentVersionHasNoWarnings(t *testing.T) {
+	// When
+	cfg, warnings, err := parseConfig([]byte("version: 1\nfeatures:\n  strict_model_validation: true\n"))
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+	if cfg.Version != currentConfigVersion || !cfg.Features[flagStrictModelValidation] {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestParseConfig_UnversionedIsMigratedWithWarning(t *testing.T) {
+	// When
+	cfg, warnings, err := parseConfig([]byte("features:\n  strict_model_validation: true\n"))
+	// Then: the file still loads, at the current version, with a migration warning
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Version != currentConfigVersion || !cfg.Features[flagStrictModelValidation] {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "migrated from version 0") {
+		t.Errorf("expected one migration warning, got %v", warnings)
+	}
+}
+
+func TestParseConfig_NewerVersionIsError(t *testing.T) {
+	// When
+	_, _, err := parseConfig([]byte("version: 99\n"))
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "upgrade mokku") {
+		t.Errorf("expected an upgrade error, got %v", err)
+	}
+}
+
+func TestParseConfig_InvalidVersionIsError(t *testing.T) {
+	// When
+	_, _, err := parseConfig([]byte("version: one\n"))
+	// Then
+	if err == nil {
+		t.Error("expected an error for a non-integer version")
+	}
+}
+
+func TestParseConfig_UnknownKeysWarn(t *testing.T) {
+	// When
+	_, warnings, err := parseConfig([]byte("version: 1\nfeature:\n  strict_model_validation: true\n"))
+	// Then: a misspelled section is reported rather than silently ignored
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], `"feature"`) {
+		t.Errorf("expected an unknown key warning, got %v", warnings)
+	}
+}
+
+func TestParseConfig_JSON(t *testing.T) {
+	// When
+	cfg, _, err := parseConfig([]byte(`{"version": 1, "features": {"strict_model_validation": true}}`))
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !cfg.Features[flagStrictModelValidation] {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestLoadConfig_MergesIncludesAndOverlay(t *testing.T) {
+	// Given: team fragments included by the root config, and a staging overlay
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"mokku.yml":         "version: 1\ninclude: [teams/*.yml]\nfeatures: {strict_model_validation: true}\n",
+		"mokku.staging.yml": "version: 1\nregions: [{name: eu, status: 500}]\n",
+		"teams/search.yml":  "version: 1\nregions: [{name: eu, status: 503, latency: 1s}]\nmoderation: {banned_phrases: [secret]}\n",
+		"teams/billing.yml": "version: 1\nmoderation: {banned_phrases: [card number]}\n",
+	})
+	t.Setenv(envVar, "staging")
+
+	// When
+	cfg, err := loadConfig(filepath.Join(dir, "mokku.yml"))
+
+	// Then: lists are concatenated, and the overlay overrides the eu region by name
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cfg.Features[flagStrictModelValidation] || len(cfg.Moderation.BannedPhrases) != 2 || cfg.Moderation.BannedPhrases[0] != "card number" {
+		t.Errorf("unexpected merged config %+v", cfg)
+	}
+	if len(cfg.Regions) != 1 || cfg.Regions[0].Status != 500 || cfg.Regions[0].Latency != "1s" {
+		t.Errorf("expected the overlaid eu region, got %+v", cfg.Regions)
+	}
+}
+
+func TestLoadConfig_RejectsInvalidFragment(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"mokku.yml": "include: [team.yml]\n", "team.yml": "version: 99\n"})
+	// When
+	_, err := loadConfig(filepath.Join(dir, "mokku.yml"))
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "team.yml") {
+		t.Errorf("expected an error naming the fragment, got %v", err)
+	}
+}
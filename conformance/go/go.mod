@@ -0,0 +1,12 @@
+module openai-mokku/conformance/go
+
+go 1.25.4
+
+require github.com/openai/openai-go v1.12.0
+
+require (
+	github.com/tidwall/gjson v1.14.4 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	github.com/tidwall/sjson v1.2.5 // indirect
+)
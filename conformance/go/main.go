@@ -0,0 +1,544 @@
+// Command conformance checks the wire compatibility of the official openai-go SDK with mokku.
+//
+// It reads OPENAI_BASE_URL (mokku's /v1 URL) and prints one "PASS <check>" or
+// "FAIL <check>: <reason>" line per check. It exits 1 if any check fails.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+type check struct {
+	name string
+	run  func(ctx context.Context, client openai.Client) error
+}
+
+var checks = []check{
+	{"chat", checkChat},
+	{"chat_stream", checkChatStream},
+	{"tools", checkTools},
+	{"tools_stream", checkToolsStream},
+	{"embeddings", checkEmbeddings},
+	{"models", checkModels},
+	{"error_insufficient_quota", checkErrorInsufficientQuota},
+	{"error_invalid_request", checkErrorInvalidRequest},
+	{"error_region_outage", checkErrorRegionOutage},
+	{"error_blocked_user", checkErrorBlockedUser},
+	{"stream_truncated", checkStreamTruncated},
+	{"seed_header", checkSeedHeader},
+	{"cold_start", checkColdStart},
+	{"connection_reuse", checkConnectionReuse},
+	{"forwarded_client", checkForwardedClient},
+	{"overhead_header", checkOverheadHeader},
+	{"citations", checkCitations},
+	{"citations_stream", checkCitationsStream},
+	{"image_url_expiry", checkImageURLExpiry},
+}
+
+func main() {
+	client := openai.NewClient(option.WithAPIKey("sk-conformance"), option.WithMaxRetries(0))
+	ctx := context.Background()
+	failed := false
+	for _, c := range checks {
+		if err := c.run(ctx, client); err != nil {
+			failed = true
+			fmt.Printf("FAIL %s: %s\n", c.name, strings.Join(strings.Fields(err.Error()), " "))
+			continue
+		}
+		fmt.Printf("PASS %s\n", c.name)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func userMessage(content string) []openai.ChatCompletionMessageParamUnion {
+	return []openai.ChatCompletionMessageParamUnion{openai.UserMessage(content)}
+}
+
+func checkChat(ctx context.Context, client openai.Client) error {
+	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return fmt.Errorf("empty content: %s", resp.RawJSON())
+	}
+	if resp.Usage.PromptTokens == 0 {
+		return fmt.Errorf("missing usage: %s", resp.RawJSON())
+	}
+	return nil
+}
+
+func checkChatStream(ctx context.Context, client openai.Client) error {
+	stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	})
+	acc := openai.ChatCompletionAccumulator{}
+	for stream.Next() {
+		chunk := stream.Current()
+		if chunk.Object != "chat.completion.chunk" {
+			return fmt.Errorf("unexpected object %q", chunk.Object)
+		}
+		acc.AddChunk(chunk)
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	if len(acc.Choices) == 0 || acc.Choices[0].Message.Content == "" {
+		return errors.New("empty streamed content")
+	}
+	if reason := acc.Choices[0].FinishReason; reason != "stop" {
+		return fmt.Errorf("unexpected finish_reason %q", reason)
+	}
+	return nil
+}
+
+func checkTools(ctx context.Context, client openai.Client) error {
+	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("What is the weather in Tokyo?"),
+		Tools: []openai.ChatCompletionToolParam{{
+			Function: openai.FunctionDefinitionParam{
+				Name: "get_weather",
+				Parameters: openai.FunctionParameters{
+					"type":       "object",
+					"properties": map[string]any{"city": map[string]any{"type": "string"}},
+				},
+			},
+		}},
+	})
+	if err != nil {
+		return err
+	}
+	if reason := resp.Choices[0].FinishReason; reason != "stop" && reason != "tool_calls" {
+		return fmt.Errorf("unexpected finish_reason %q", reason)
+	}
+	return nil
+}
+
+func checkToolsStream(ctx context.Context, client openai.Client) error {
+	stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage(`call:get_weather {"city":"Tokyo"}`),
+		Tools: []openai.ChatCompletionToolParam{{
+			Function: openai.FunctionDefinitionParam{Name: "get_weather"},
+		}},
+	})
+	acc := openai.ChatCompletionAccumulator{}
+	for stream.Next() {
+		acc.AddChunk(stream.Current())
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	if len(acc.Choices) == 0 || len(acc.Choices[0].Message.ToolCalls) != 1 {
+		return errors.New("expected one streamed tool call")
+	}
+	call := acc.Choices[0].Message.ToolCalls[0]
+	if call.ID == "" || call.Function.Name != "get_weather" || call.Function.Arguments != `{"city":"Tokyo"}` {
+		return fmt.Errorf("unexpected tool call %+v", call)
+	}
+	if reason := acc.Choices[0].FinishReason; reason != "tool_calls" {
+		return fmt.Errorf("unexpected finish_reason %q", reason)
+	}
+	return nil
+}
+
+func checkEmbeddings(ctx context.Context, client openai.Client) error {
+	resp, err := client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+		Model: openai.EmbeddingModelTextEmbedding3Small,
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: []string{"a", "b"}},
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Data) != 2 || len(resp.Data[0].Embedding) == 0 {
+		return fmt.Errorf("unexpected embeddings: %s", resp.RawJSON())
+	}
+	return nil
+}
+
+func checkModels(ctx context.Context, client openai.Client) error {
+	iter := client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		if iter.Current().ID == "gpt-4o" {
+			return nil
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	return errors.New("gpt-4o is not listed")
+}
+
+func checkErrorInsufficientQuota(ctx context.Context, client openai.Client) error {
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "credit-error",
+		Messages: userMessage("hello"),
+	})
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != 402 || apiErr.Code != "insufficient_quota" {
+		return fmt.Errorf("expected 402 insufficient_quota, got %d %q", apiErr.StatusCode, apiErr.Code)
+	}
+	return nil
+}
+
+func checkErrorInvalidRequest(ctx context.Context, client openai.Client) error {
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:     "gpt-4o",
+		Messages:  userMessage("hello"),
+		LogitBias: map[string]int64{"-1": 1},
+	})
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != 400 || apiErr.Param != "logit_bias" {
+		return fmt.Errorf("expected 400 on logit_bias, got %d %q", apiErr.StatusCode, apiErr.Param)
+	}
+	return nil
+}
+
+func checkErrorRegionOutage(ctx context.Context, client openai.Client) error {
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	}, option.WithHeader("X-Mokku-Region", "conformance-down"))
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != 503 || apiErr.Type != "server_error" {
+		return fmt.Errorf("expected 503 server_error, got %d %q", apiErr.StatusCode, apiErr.Type)
+	}
+	return nil
+}
+
+func checkErrorBlockedUser(ctx context.Context, client openai.Client) error {
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:            "gpt-4o",
+		Messages:         userMessage("hello"),
+		SafetyIdentifier: openai.String("conformance-banned"),
+	})
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return fmt.Errorf("expected an API error, got %v", err)
+	}
+	if apiErr.StatusCode != 403 || apiErr.Code != "user_blocked" || apiErr.Param != "safety_identifier" {
+		return fmt.Errorf("expected 403 user_blocked on safety_identifier, got %d %q %q", apiErr.StatusCode, apiErr.Code, apiErr.Param)
+	}
+	return nil
+}
+
+// mokkuURL returns the URL of a mokku control API endpoint, such as "/chaos".
+func mokkuURL(path string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(os.Getenv("OPENAI_BASE_URL"), "/"), "/v1") + "/_mokku" + path
+}
+
+// setChaos activates a chaos profile through mokku's control API, or turns chaos off for "".
+func setChaos(ctx context.Context, profile string) error {
+	adminURL := mokkuURL("/chaos")
+	method, body := http.MethodDelete, ""
+	if profile != "" {
+		method, body = http.MethodPut, fmt.Sprintf(`{"profile":%q}`, profile)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, adminURL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %d", method, adminURL, resp.StatusCode)
+	}
+	return nil
+}
+
+func checkStreamTruncated(ctx context.Context, client openai.Client) error {
+	if err := setChaos(ctx, "conformance-truncate"); err != nil {
+		return err
+	}
+	defer func() { _ = setChaos(ctx, "") }()
+	stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	})
+	for stream.Next() {
+		if len(stream.Current().Choices) > 0 && stream.Current().Choices[0].FinishReason != "" {
+			return errors.New("unexpected finish_reason in a truncated stream")
+		}
+	}
+	if stream.Err() == nil {
+		return errors.New("expected the truncated stream to fail")
+	}
+	return nil
+}
+
+func checkSeedHeader(ctx context.Context, client openai.Client) error {
+	var resp *http.Response
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	}, option.WithHeader("X-Mokku-Seed", "42"), option.WithResponseInto(&resp))
+	if err != nil {
+		return err
+	}
+	if seed := resp.Header.Get("X-Mokku-Seed"); seed != "42" {
+		return fmt.Errorf("expected seed 42, got %q", seed)
+	}
+	return nil
+}
+
+func checkColdStart(ctx context.Context, client openai.Client) error {
+	for i, want := range []string{"10", ""} {
+		var resp *http.Response
+		_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+			Model:    "gpt-4o-mini",
+			Messages: userMessage("hello"),
+		}, option.WithResponseInto(&resp))
+		if err != nil {
+			return err
+		}
+		if got := resp.Header.Get("X-Mokku-Cold-Start"); got != want {
+			return fmt.Errorf("request %d: expected cold start %q, got %q", i+1, want, got)
+		}
+	}
+	return nil
+}
+
+func checkConnectionReuse(ctx context.Context, client openai.Client) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, mokkuURL("/connections"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	for range 2 {
+		stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+			Model:    "gpt-4o",
+			Messages: userMessage("hello"),
+		})
+		for stream.Next() {
+		}
+		if err := stream.Err(); err != nil {
+			return err
+		}
+	}
+	resp, err = http.Get(mokkuURL("/connections"))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var stats struct {
+		ReusedRequests int `json:"reused_requests"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return err
+	}
+	if stats.ReusedRequests == 0 {
+		return errors.New("expected the streams to reuse a pooled connection")
+	}
+	return nil
+}
+
+func checkForwardedClient(ctx context.Context, client openai.Client) error {
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	}, option.WithHeader("X-Forwarded-For", "198.51.100.1"))
+	if err != nil {
+		return err
+	}
+	resp, err := http.Get(mokkuURL("/requests?limit=1"))
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var captured struct {
+		Data []struct {
+			RemoteAddr string `json:"remote_addr"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&captured); err != nil {
+		return err
+	}
+	if len(captured.Data) != 1 || captured.Data[0].RemoteAddr != "198.51.100.1" {
+		return fmt.Errorf("expected the forwarded client 198.51.100.1, got %+v", captured.Data)
+	}
+	return nil
+}
+
+func checkOverheadHeader(ctx context.Context, client openai.Client) error {
+	var resp *http.Response
+	_, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: userMessage("hello"),
+	}, option.WithResponseInto(&resp))
+	if err != nil {
+		return err
+	}
+	overhead, err := strconv.ParseFloat(resp.Header.Get("X-Mokku-Overhead-Ms"), 64)
+	if err != nil || overhead < 0 {
+		return fmt.Errorf("expected a non-negative X-Mokku-Overhead-Ms, got %q", resp.Header.Get("X-Mokku-Overhead-Ms"))
+	}
+	return nil
+}
+
+// citedSpan returns the code points of content a citation addresses, or an error if its
+// indices fall outside the content.
+func citedSpan(content string, start, end int64) (string, error) {
+	runes := []rune(content)
+	if start < 0 || start > end || end > int64(len(runes)) {
+		return "", fmt.Errorf("citation [%d, %d) outside content %q", start, end, content)
+	}
+	return string(runes[start:end]), nil
+}
+
+func checkCitations(ctx context.Context, client openai.Client) error {
+	resp, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model:            "gpt-4o",
+		Messages:         userMessage("latest news"),
+		WebSearchOptions: openai.ChatCompletionNewParamsWebSearchOptions{SearchContextSize: "low"},
+	})
+	if err != nil {
+		return err
+	}
+	message := resp.Choices[0].Message
+	if len(message.Annotations) == 0 {
+		return fmt.Errorf("missing annotations: %s", resp.RawJSON())
+	}
+	citation := message.Annotations[0].URLCitation
+	span, err := citedSpan(message.Content, citation.StartIndex, citation.EndIndex)
+	if err != nil {
+		return err
+	}
+	if citation.Title == "" || !strings.Contains(span, citation.URL) {
+		return fmt.Errorf("unexpected citation %+v of %q", citation, span)
+	}
+	return nil
+}
+
+func checkCitationsStream(ctx context.Context, client openai.Client) error {
+	stream := client.Chat.Completions.NewStreaming(ctx, openai.ChatCompletionNewParams{
+		Model:    "gpt-4o-search-preview",
+		Messages: userMessage("latest news"),
+	})
+	var content string
+	var annotations []openai.ChatCompletionMessageAnnotation
+	for stream.Next() {
+		chunk := stream.Current()
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		content += delta.Content
+		// openai-go's delta has no annotations field; they arrive as an extra field.
+		if raw, ok := delta.JSON.ExtraFields["annotations"]; ok {
+			var more []openai.ChatCompletionMessageAnnotation
+			if err := json.Unmarshal([]byte(raw.Raw()), &more); err != nil {
+				return err
+			}
+			annotations = append(annotations, more...)
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return err
+	}
+	if len(annotations) == 0 {
+		return errors.New("no streamed annotations")
+	}
+	citation := annotations[0].URLCitation
+	span, err := citedSpan(content, citation.StartIndex, citation.EndIndex)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(span, citation.URL) {
+		return fmt.Errorf("unexpected citation %+v of %q", citation, span)
+	}
+	return nil
+}
+
+// advanceClock moves mokku's virtual clock forward through its control API, or resets it for 0.
+func advanceClock(ctx context.Context, seconds int) error {
+	adminURL, method, body := mokkuURL("/clock"), http.MethodDelete, ""
+	if seconds != 0 {
+		adminURL, method, body = mokkuURL("/clock/advance"), http.MethodPost, fmt.Sprintf(`{"seconds":%d}`, seconds)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, adminURL, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s returned %d", method, adminURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// fetchStatus returns the status code of a GET request for url.
+func fetchStatus(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+func checkImageURLExpiry(ctx context.Context, client openai.Client) error {
+	images, err := client.Images.Generate(ctx, openai.ImageGenerateParams{
+		Model:  openai.ImageModelDallE2,
+		Prompt: "a clock",
+		Size:   openai.ImageGenerateParamsSize256x256,
+	})
+	if err != nil {
+		return err
+	}
+	if len(images.Data) != 1 || images.Data[0].URL == "" {
+		return fmt.Errorf("expected one image URL, got %+v", images.Data)
+	}
+	url := images.Data[0].URL
+	if status, err := fetchStatus(ctx, url); err != nil || status != http.StatusOK {
+		return fmt.Errorf("expected the new URL to be served, got %d %v", status, err)
+	}
+	if err := advanceClock(ctx, 3600); err != nil {
+		return err
+	}
+	defer func() { _ = advanceClock(ctx, 0) }()
+	if status, err := fetchStatus(ctx, url); err != nil || status != http.StatusForbidden {
+		return fmt.Errorf("expected the URL to expire after an hour, got %d %v", status, err)
+	}
+	return nil
+}
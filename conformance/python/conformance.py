@@ -0,0 +1,335 @@
+"""Wire compatibility checks of the official openai-python SDK against mokku.
+
+Reads OPENAI_BASE_URL (mokku's /v1 URL) and prints one "PASS <check>" or
+"FAIL <check>: <reason>" line per check. Exits 1 if any check fails.
+"""
+
+import json
+import os
+import sys
+import urllib.error
+import urllib.request
+
+import httpx
+import openai
+
+client = openai.OpenAI(api_key="sk-conformance", max_retries=0)
+
+
+def check_chat():
+    resp = client.chat.completions.create(
+        model="gpt-4o", messages=[{"role": "user", "content": "hello"}]
+    )
+    assert resp.object == "chat.completion", resp.object
+    assert resp.choices[0].message.role == "assistant"
+    assert resp.choices[0].message.content, "empty content"
+    assert resp.usage.prompt_tokens > 0, resp.usage
+
+
+def check_chat_stream():
+    stream = client.chat.completions.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": "hello"}],
+        stream=True,
+    )
+    content, finish_reason = "", None
+    for chunk in stream:
+        assert chunk.object == "chat.completion.chunk", chunk.object
+        for choice in chunk.choices:
+            content += choice.delta.content or ""
+            finish_reason = choice.finish_reason or finish_reason
+    assert content, "empty streamed content"
+    assert finish_reason == "stop", finish_reason
+
+
+def check_tools():
+    resp = client.chat.completions.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": "What is the weather in Tokyo?"}],
+        tools=[
+            {
+                "type": "function",
+                "function": {
+                    "name": "get_weather",
+                    "parameters": {
+                        "type": "object",
+                        "properties": {"city": {"type": "string"}},
+                    },
+                },
+            }
+        ],
+    )
+    assert resp.choices[0].finish_reason in ("stop", "tool_calls"), resp.choices[0].finish_reason
+
+
+def check_tools_stream():
+    stream = client.chat.completions.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": 'call:get_weather {"city":"Tokyo"}'}],
+        tools=[{"type": "function", "function": {"name": "get_weather"}}],
+        stream=True,
+    )
+    calls, finish_reason = {}, None
+    for chunk in stream:
+        for choice in chunk.choices:
+            for delta in choice.delta.tool_calls or []:
+                call = calls.setdefault(delta.index, {"id": "", "name": "", "arguments": ""})
+                call["id"] += delta.id or ""
+                call["name"] += delta.function.name or ""
+                call["arguments"] += delta.function.arguments or ""
+            finish_reason = choice.finish_reason or finish_reason
+    assert len(calls) == 1, calls
+    assert calls[0]["id"] and calls[0]["name"] == "get_weather", calls
+    assert calls[0]["arguments"] == '{"city":"Tokyo"}', calls
+    assert finish_reason == "tool_calls", finish_reason
+
+
+def check_embeddings():
+    resp = client.embeddings.create(model="text-embedding-3-small", input=["a", "b"])
+    assert len(resp.data) == 2, len(resp.data)
+    assert len(resp.data[0].embedding) > 0
+    assert resp.usage.prompt_tokens > 0, resp.usage
+
+
+def check_models():
+    ids = [m.id for m in client.models.list()]
+    assert "gpt-4o" in ids, ids
+
+
+def check_error_insufficient_quota():
+    try:
+        client.chat.completions.create(
+            model="credit-error", messages=[{"role": "user", "content": "hello"}]
+        )
+    except openai.APIStatusError as e:
+        assert e.status_code == 402, e.status_code
+        assert e.code == "insufficient_quota", e.code
+    else:
+        raise AssertionError("expected an error")
+
+
+def check_error_invalid_request():
+    try:
+        client.chat.completions.create(
+            model="gpt-4o",
+            messages=[{"role": "user", "content": "hello"}],
+            logit_bias={"-1": 1},
+        )
+    except openai.BadRequestError as e:
+        assert e.param == "logit_bias", e.param
+    else:
+        raise AssertionError("expected a BadRequestError")
+
+
+def check_error_region_outage():
+    try:
+        client.chat.completions.create(
+            model="gpt-4o",
+            messages=[{"role": "user", "content": "hello"}],
+            extra_headers={"X-Mokku-Region": "conformance-down"},
+        )
+    except openai.InternalServerError as e:
+        assert e.status_code == 503, e.status_code
+        assert e.type == "server_error", e.type
+    else:
+        raise AssertionError("expected an InternalServerError")
+
+
+def check_error_blocked_user():
+    try:
+        client.chat.completions.create(
+            model="gpt-4o",
+            messages=[{"role": "user", "content": "hello"}],
+            safety_identifier="conformance-banned",
+        )
+    except openai.PermissionDeniedError as e:
+        assert e.code == "user_blocked", e.code
+        assert e.param == "safety_identifier", e.param
+    else:
+        raise AssertionError("expected a PermissionDeniedError")
+
+
+def mokku_url(path):
+    """Returns the URL of a mokku control API endpoint, such as "/chaos"."""
+    return os.environ["OPENAI_BASE_URL"].rstrip("/").removesuffix("/v1") + "/_mokku" + path
+
+
+def set_chaos(profile):
+    """Activates a chaos profile through mokku's control API, or turns chaos off for None."""
+    if profile is None:
+        req = urllib.request.Request(mokku_url("/chaos"), method="DELETE")
+    else:
+        body = ('{"profile":"%s"}' % profile).encode()
+        req = urllib.request.Request(mokku_url("/chaos"), data=body, method="PUT")
+    urllib.request.urlopen(req).close()
+
+
+def check_stream_truncated():
+    set_chaos("conformance-truncate")
+    try:
+        stream = client.chat.completions.create(
+            model="gpt-4o",
+            messages=[{"role": "user", "content": "hello"}],
+            stream=True,
+        )
+        for chunk in stream:
+            for choice in chunk.choices:
+                assert choice.finish_reason is None, choice.finish_reason
+    except (openai.APIError, httpx.TransportError):
+        pass
+    else:
+        raise AssertionError("expected the truncated stream to fail")
+    finally:
+        set_chaos(None)
+
+
+def check_seed_header():
+    resp = client.chat.completions.with_raw_response.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": "hello"}],
+        extra_headers={"X-Mokku-Seed": "42"},
+    )
+    assert resp.headers.get("x-mokku-seed") == "42", resp.headers.get("x-mokku-seed")
+    assert resp.parse().choices[0].message.content, "empty content"
+
+
+def check_cold_start():
+    for want in ("10", None):
+        resp = client.chat.completions.with_raw_response.create(
+            model="gpt-4o-mini",
+            messages=[{"role": "user", "content": "hello"}],
+        )
+        assert resp.headers.get("x-mokku-cold-start") == want, resp.headers.get("x-mokku-cold-start")
+
+
+def check_connection_reuse():
+    urllib.request.urlopen(urllib.request.Request(mokku_url("/connections"), method="DELETE")).close()
+    for _ in range(2):
+        stream = client.chat.completions.create(
+            model="gpt-4o",
+            messages=[{"role": "user", "content": "hello"}],
+            stream=True,
+        )
+        for _ in stream:
+            pass
+    with urllib.request.urlopen(mokku_url("/connections")) as resp:
+        stats = json.load(resp)
+    assert stats["reused_requests"] > 0, "expected the streams to reuse a pooled connection"
+
+
+def check_forwarded_client():
+    client.chat.completions.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": "hello"}],
+        extra_headers={"X-Forwarded-For": "198.51.100.1"},
+    )
+    with urllib.request.urlopen(mokku_url("/requests?limit=1")) as resp:
+        captured = json.load(resp)["data"]
+    assert len(captured) == 1, captured
+    assert captured[0]["remote_addr"] == "198.51.100.1", captured[0]["remote_addr"]
+
+
+def check_overhead_header():
+    resp = client.chat.completions.with_raw_response.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": "hello"}],
+    )
+    overhead = resp.headers.get("x-mokku-overhead-ms")
+    assert overhead is not None and float(overhead) >= 0, overhead
+
+
+def check_citations():
+    resp = client.chat.completions.create(
+        model="gpt-4o",
+        messages=[{"role": "user", "content": "latest news"}],
+        web_search_options={"search_context_size": "low"},
+    )
+    message = resp.choices[0].message
+    assert message.annotations, resp
+    citation = message.annotations[0].url_citation
+    assert citation.title, citation
+    # Indices count code points, which is how python indexes str.
+    assert citation.url in message.content[citation.start_index : citation.end_index], citation
+
+
+def check_citations_stream():
+    stream = client.chat.completions.create(
+        model="gpt-4o-search-preview",
+        messages=[{"role": "user", "content": "latest news"}],
+        stream=True,
+    )
+    content, annotations = "", []
+    for chunk in stream:
+        for choice in chunk.choices:
+            content += choice.delta.content or ""
+            # The SDK's delta model has no annotations field; it keeps them as an extra.
+            annotations += getattr(choice.delta, "annotations", None) or []
+    assert annotations, "no streamed annotations"
+    citation = annotations[0]["url_citation"]
+    assert citation["url"] in content[citation["start_index"] : citation["end_index"]], citation
+
+
+
+def advance_clock(seconds):
+    """Moves mokku's virtual clock forward through its control API, or resets it for 0."""
+    if seconds == 0:
+        req = urllib.request.Request(mokku_url("/clock"), method="DELETE")
+    else:
+        body = ('{"seconds":%d}' % seconds).encode()
+        req = urllib.request.Request(mokku_url("/clock/advance"), data=body, method="POST")
+    urllib.request.urlopen(req).close()
+
+
+def check_image_url_expiry():
+    images = client.images.generate(model="dall-e-2", prompt="a clock", size="256x256")
+    url = images.data[0].url
+    urllib.request.urlopen(url).close()
+    advance_clock(3600)
+    try:
+        urllib.request.urlopen(url).close()
+    except urllib.error.HTTPError as e:
+        assert e.code == 403, e.code
+    else:
+        raise AssertionError("expected the image URL to expire after an hour")
+    finally:
+        advance_clock(0)
+
+CHECKS = {
+    "chat": check_chat,
+    "chat_stream": check_chat_stream,
+    "tools": check_tools,
+    "tools_stream": check_tools_stream,
+    "embeddings": check_embeddings,
+    "models": check_models,
+    "error_insufficient_quota": check_error_insufficient_quota,
+    "error_invalid_request": check_error_invalid_request,
+    "error_region_outage": check_error_region_outage,
+    "error_blocked_user": check_error_blocked_user,
+    "stream_truncated": check_stream_truncated,
+    "seed_header": check_seed_header,
+    "cold_start": check_cold_start,
+    "connection_reuse": check_connection_reuse,
+    "forwarded_client": check_forwarded_client,
+    "overhead_header": check_overhead_header,
+    "citations": check_citations,
+    "citations_stream": check_citations_stream,
+    "image_url_expiry": check_image_url_expiry,
+}
+
+
+def main():
+    failed = False
+    for name, check in CHECKS.items():
+        try:
+            check()
+        except Exception as e:  # noqa: BLE001 - every failure is reported
+            failed = True
+            reason = " ".join(f"{type(e).__name__}: {e}".split())
+            print(f"FAIL {name}: {reason}", flush=True)
+        else:
+            print(f"PASS {name}", flush=True)
+    sys.exit(1 if failed else 0)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,94 @@
+//go:build conformance
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The conformance suite runs the official SDKs against an in-process mokku in subprocesses:
+//
+//	go test -tags conformance -run Conformance ./...
+//
+// The openai-go checks live in conformance/go (a separate module with its own go.sum) and the
+// openai-python checks in conformance/python (pip install -r conformance/python/requirements.txt).
+// Each prints one "PASS <check>" or "FAIL <check>: <reason>" line per check, reported as subtests.
+
+const conformanceTimeout = 5 * time.Minute
+
+// conformanceConfig is the config of the mokku the checks run against.
+var conformanceConfig = Config{
+	Regions:    []regionConfig{{Name: "conformance-down", Status: 503}},
+	Chaos:      chaosConfig{Profiles: []chaosProfileConfig{{Name: "conformance-truncate", TruncateRate: 1}}},
+	ColdStart:  map[string]coldStartConfig{"gpt-4o-mini": {Latency: "10ms"}},
+	Proxies:    proxyConfig{Trusted: []string{"127.0.0.1"}},
+	Moderation: moderationConfig{BannedUsers: []string{"conformance-banned"}},
+}
+
+func TestConformance_GoSDK(t *testing.T) {
+	// Given: mokku and the openai-go checks
+	srv := newTestServerWithConfig(t, conformanceConfig)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "run", ".")
+	cmd.Dir = "conformance/go"
+
+	// When / Then
+	runConformanceChecks(t, cmd, srv.URL)
+}
+
+func TestConformance_PythonSDK(t *testing.T) {
+	// Given: mokku and the openai-python checks
+	python := os.Getenv("MOKKU_CONFORMANCE_PYTHON")
+	if python == "" {
+		python = "python3"
+	}
+	if err := exec.Command(python, "-c", "import openai").Run(); err != nil {
+		t.Skipf("openai-python is not installed for %s: pip install -r conformance/python/requirements.txt", python)
+	}
+	srv := newTestServerWithConfig(t, conformanceConfig)
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), conformanceTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, python, "conformance/python/conformance.py")
+
+	// When / Then
+	runConformanceChecks(t, cmd, srv.URL)
+}
+
+// runConformanceChecks runs an SDK check program against the mokku at baseURL and reports each
+// check as a subtest.
+func runConformanceChecks(t *testing.T, cmd *exec.Cmd, baseURL string) {
+	t.Helper()
+	cmd.Env = append(os.Environ(), "OPENAI_BASE_URL="+baseURL+"/v1", "OPENAI_API_KEY=sk-conformance")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+
+	checks := 0
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "PASS "); ok {
+			checks++
+			t.Run(name, func(t *testing.T) {})
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, "FAIL "); ok {
+			checks++
+			name, reason, _ := strings.Cut(rest, ": ")
+			t.Run(name, func(t *testing.T) { t.Error(reason) })
+		}
+	}
+	if checks == 0 {
+		t.Fatalf("no checks ran (%v):\n%s%s", err, out, stderr.String())
+	}
+}
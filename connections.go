@@ -0,0 +1,191 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// maxStoredClosedConnections is the number of closed connections kept for GET /_mokku/connections.
+const maxStoredClosedConnections = 100
+
+// connectionInfo is a client connection reported by GET /_mokku/connections.
+type connectionInfo struct {
+	ID         int64      `json:"id"`
+	RemoteAddr string     `json:"remote_addr,omitempty"`
+	Proto      string     `json:"proto,omitempty"`
+	Opened     time.Time  `json:"opened"`
+	Closed     *time.Time `json:"closed,omitempty"`
+	// Requests is the number of requests served on the connection.
+	Requests int64 `json:"requests"`
+}
+
+// connectionRequest identifies the connection a request arrived on and its position on it, as
+// recorded with captured requests.
+type connectionRequest struct {
+	ID int64 `json:"id"`
+	// Request is the 1-based number of the request on the connection.
+	Request int64  `json:"request"`
+	Proto   string `json:"proto"`
+	// Reused reports whether an earlier request was served on the connection.
+	Reused bool `json:"reused"`
+}
+
+// connectionStats is the response body of GET /_mokku/connections.
+type connectionStats struct {
+	Object string `json:"object"`
+	Opened int64  `json:"opened"`
+	Closed int64  `json:"closed"`
+	// Requests counts the requests served, of which ReusedRequests arrived on a connection that had
+	// already served one.
+	Requests       int64 `json:"requests"`
+	ReusedRequests int64 `json:"reused_requests"`
+	// RequestsPerConnection is Requests divided by Opened.
+	RequestsPerConnection float64          `json:"requests_per_connection"`
+	Protocols             map[string]int64 `json:"protocols"`
+	// Open lists the open connections, oldest first, and Recent the latest closed ones.
+	Open   []connectionInfo `json:"open"`
+	Recent []connectionInfo `json:"recent"`
+}
+
+// connectionContextKey is the context key of the connection a request arrived on.
+type connectionContextKey struct{}
+
+// connectionRequestContextKey is the context key of a request's connectionRequest.
+type connectionRequestContextKey struct{}
+
+// connectionTracker counts the client connections of the server and the requests served on each,
+// so tests can verify that SDK connection pools reuse connections. Open connections are removed
+// when they close, so it holds no more than the open connections and the recent closed ones. It is
+// safe for concurrent use.
+type connectionTracker struct {
+	mu        sync.Mutex
+	nextID    int64
+	open      map[net.Conn]*connectionInfo
+	closed    *ringBuffer[connectionInfo]
+	opened    int64
+	closedN   int64
+	requests  int64
+	reused    int64
+	protocols map[string]int64
+	now       func() time.Time
+}
+
+// newConnectionTracker creates a tracker without connections.
+func newConnectionTracker() *connectionTracker {
+	return &connectionTracker{
+		open:      map[net.Conn]*connectionInfo{},
+		closed:    newRingBuffer[connectionInfo](maxStoredClosedConnections),
+		protocols: map[string]int64{},
+		now:       time.Now,
+	}
+}
+
+// ConnContext registers a new connection; it is the http.Server ConnContext hook. It runs in the
+// accept loop, so the remote address, which may wait for a PROXY protocol header, is only recorded
+// with the first request.
+func (t *connectionTracker) ConnContext(ctx context.Context, c net.Conn) context.Context {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	t.opened++
+	info := &connectionInfo{ID: t.nextID, Opened: t.now()}
+	t.open[c] = info
+	return context.WithValue(ctx, connectionContextKey{}, info)
+}
+
+// ConnState moves closed and hijacked connections to the recent list; it is the http.Server
+// ConnState hook.
+func (t *connectionTracker) ConnState(c net.Conn, state http.ConnState) {
+	if state != http.StateClosed && state != http.StateHijacked {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info, ok := t.open[c]
+	if !ok {
+		return
+	}
+	delete(t.open, c)
+	closed := t.now()
+	info.Closed = &closed
+	t.closedN++
+	t.closed.Push(*info)
+}
+
+// Serve counts a request on its connection and returns the request with its connectionRequest in
+// the context. Requests without a tracked connection are returned unchanged.
+func (t *connectionTracker) Serve(r *http.Request) *http.Request {
+	info, ok := r.Context().Value(connectionContextKey{}).(*connectionInfo)
+	if !ok {
+		return r
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	info.Requests++
+	info.Proto = r.Proto
+	if info.RemoteAddr == "" {
+		info.RemoteAddr = r.RemoteAddr
+	}
+	t.requests++
+	t.protocols[r.Proto]++
+	cr := connectionRequest{ID: info.ID, Request: info.Requests, Proto: r.Proto, Reused: info.Requests > 1}
+	if cr.Reused {
+		t.reused++
+	}
+	return r.WithContext(context.WithValue(r.Context(), connectionRequestContextKey{}, cr))
+}
+
+// connectionRequestFromContext returns the connectionRequest of a request served by Serve.
+func connectionRequestFromContext(ctx context.Context) (connectionRequest, bool) {
+	cr, ok := ctx.Value(connectionRequestContextKey{}).(connectionRequest)
+	return cr, ok
+}
+
+// Stats returns the counters, the open connections, and the recent closed ones.
+func (t *connectionTracker) Stats() connectionStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := connectionStats{
+		Object:         "mokku.connections",
+		Opened:         t.opened,
+		Closed:         t.closedN,
+		Requests:       t.requests,
+		ReusedRequests: t.reused,
+		Protocols:      make(map[string]int64, len(t.protocols)),
+		Open:           make([]connectionInfo, 0, len(t.open)),
+		Recent:         t.closed.Snapshot(),
+	}
+	if t.opened > 0 {
+		stats.RequestsPerConnection = float64(t.requests) / float64(t.opened)
+	}
+	for proto, n := range t.protocols {
+		stats.Protocols[proto] = n
+	}
+	for _, info := range t.open {
+		stats.Open = append(stats.Open, *info)
+	}
+	slices.SortFunc(stats.Open, func(a, b connectionInfo) int { return cmp.Compare(a.ID, b.ID) })
+	return stats
+}
+
+// Reset clears the counters and the recent closed connections. Open connections stay tracked, count
+// as opened, and keep their request counts, so their next requests still count as reused.
+func (t *connectionTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.opened, t.closedN, t.requests, t.reused = int64(len(t.open)), 0, 0, 0
+	clear(t.protocols)
+	t.closed.Clear()
+}
+
+// withConnectionTracking counts every request on its connection before passing it on.
+func withConnectionTracking(connections *connectionTracker, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, connections.Serve(r))
+	})
+}
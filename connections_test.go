@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// serveOn sends a request through t on the connection c and returns the request as seen by the
+// handler.
+func serveOn(t *connectionTracker, c net.Conn) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	return t.Serve(r.WithContext(t.ConnContext(r.Context(), c)))
+}
+
+// --- connectionTracker ---
+
+func TestConnectionTracker_CountsReusedRequests(t *testing.T) {
+	// Given: two connections
+	tr := newConnectionTracker()
+	a, _ := net.Pipe()
+	b, _ := net.Pipe()
+	ctxA := tr.ConnContext(t.Context(), a)
+	ctxB := tr.ConnContext(t.Context(), b)
+
+	// When: three requests arrive on the first connection and one on the second, which then closes
+	var last connectionRequest
+	for range 3 {
+		r := tr.Serve(httptest.NewRequest(http.MethodGet, "/v1/models", nil).WithContext(ctxA))
+		last, _ = connectionRequestFromContext(r.Context())
+	}
+	tr.Serve(httptest.NewRequest(http.MethodGet, "/v1/models", nil).WithContext(ctxB))
+	tr.ConnState(b, http.StateClosed)
+
+	// Then
+	if last.ID != 1 || last.Request != 3 || !last.Reused || last.Proto != "HTTP/1.1" {
+		t.Errorf("unexpected connection request %+v", last)
+	}
+	stats := tr.Stats()
+	if stats.Opened != 2 || stats.Closed != 1 || stats.Requests != 4 || stats.ReusedRequests != 2 || stats.RequestsPerConnection != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(stats.Open) != 1 || stats.Open[0].Requests != 3 || len(stats.Recent) != 1 || stats.Recent[0].Closed == nil {
+		t.Errorf("unexpected connections: open %+v, recent %+v", stats.Open, stats.Recent)
+	}
+	if stats.Protocols["HTTP/1.1"] != 4 {
+		t.Errorf("expected 4 HTTP/1.1 requests, got %v", stats.Protocols)
+	}
+}
+
+func TestConnectionTracker_Reset_KeepsOpenConnections(t *testing.T) {
+	// Given: a connection that served a request
+	tr := newConnectionTracker()
+	c, _ := net.Pipe()
+	ctx := tr.ConnContext(t.Context(), c)
+	tr.Serve(httptest.NewRequest(http.MethodGet, "/v1/models", nil).WithContext(ctx))
+
+	// When
+	tr.Reset()
+	r := tr.Serve(httptest.NewRequest(http.MethodGet, "/v1/models", nil).WithContext(ctx))
+
+	// Then: the next request on it still counts as reused
+	stats := tr.Stats()
+	if cr, _ := connectionRequestFromContext(r.Context()); !cr.Reused {
+		t.Error("expected a reused connection")
+	}
+	if stats.Opened != 1 || stats.Requests != 1 || stats.ReusedRequests != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestConnectionTracker_Serve_UntrackedRequestIsUnchanged(t *testing.T) {
+	// Given
+	tr := newConnectionTracker()
+	r := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	// When
+	got := tr.Serve(r)
+	// Then
+	if _, ok := connectionRequestFromContext(got.Context()); ok || tr.Stats().Requests != 0 {
+		t.Error("expected an untracked request to be ignored")
+	}
+}
+
+func TestConnectionTracker_Concurrent_ForgetsClosedConnections(t *testing.T) {
+	// Given
+	tr := newConnectionTracker()
+	var wg sync.WaitGroup
+	// When: connections open, serve requests, and close while stats are read concurrently
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				c, _ := net.Pipe()
+				serveOn(tr, c)
+				_ = tr.Stats()
+				tr.ConnState(c, http.StateClosed)
+			}
+		}()
+	}
+	wg.Wait()
+	// Then: every closed connection is forgotten and only the recent ones are kept
+	stats := tr.Stats()
+	if stats.Opened != 400 || stats.Closed != 400 || stats.Requests != 400 || stats.ReusedRequests != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if len(stats.Open) != 0 || len(stats.Recent) != maxStoredClosedConnections {
+		t.Errorf("expected no open and %d recent connections, got %d and %d", maxStoredClosedConnections, len(stats.Open), len(stats.Recent))
+	}
+}
@@ -0,0 +1,804 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+)
+
+// Paths of the provider surfaces served through dialects.
+const (
+	anthropicMessagesPath = "/v1/messages"
+	azureDeploymentsPath  = "/openai/deployments/"
+	azureChatSuffix       = "/chat/completions"
+	// geminiModelsPaths are the model paths of the Gemini API versions, followed by
+	// {model}:generateContent or {model}:streamGenerateContent.
+	geminiModelsPathV1     = "/v1/models/"
+	geminiModelsPathV1Beta = "/v1beta/models/"
+)
+
+// Methods of the Gemini API models.
+const (
+	geminiGenerateContent       = "generateContent"
+	geminiStreamGenerateContent = "streamGenerateContent"
+)
+
+// Canonical finish reasons, named as in the OpenAI API, which scenarios use too. Dialects translate
+// them to the vocabulary of their provider.
+const (
+	finishReasonStop          = "stop"
+	finishReasonLength        = "length"
+	finishReasonContentFilter = "content_filter"
+)
+
+// canonicalMessage is a prompt message of a canonicalRequest.
+type canonicalMessage struct {
+	// Role is system, user, or assistant.
+	Role string
+	Text string
+}
+
+// canonicalRequest is a text completion request in the provider-neutral form dialects decode into.
+type canonicalRequest struct {
+	Model    string
+	Messages []canonicalMessage
+	Stream   bool
+	// MaxTokens caps the completion; 0 for no cap.
+	MaxTokens int
+}
+
+// lastUserMessage returns the text of the last user message, which the mock echoes.
+func (req canonicalRequest) lastUserMessage() string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			return req.Messages[i].Text
+		}
+	}
+	return ""
+}
+
+// inputTokens returns the number of tokens in the text of all messages.
+func (req canonicalRequest) inputTokens() int {
+	total := 0
+	for _, m := range req.Messages {
+		total += countTokens(m.Text)
+	}
+	return total
+}
+
+// canonicalCompletion is a text completion in the provider-neutral form dialects encode from.
+type canonicalCompletion struct {
+	ID      string
+	Model   string
+	Created time.Time
+	Content string
+	// FinishReason is one of the canonical finish reasons.
+	FinishReason string
+	InputTokens  int
+	OutputTokens int
+}
+
+// dialect is the wire format of a provider surface: it decodes requests into the canonical model and
+// encodes completions and errors from it, so scenarios, chaos, and every other behavior of the mock
+// apply to each enabled surface alike.
+type dialect interface {
+	// provider returns the GenAI provider name attribute of the surface.
+	provider() attribute.KeyValue
+	// newID returns the ID of a new completion.
+	newID() string
+	// decodeRequest decodes a request body. model is the model named by the path, empty if none.
+	decodeRequest(body []byte, model string) (canonicalRequest, error)
+	// encodeResponse returns the response body of a completion.
+	encodeResponse(c canonicalCompletion) any
+	// streamResponse streams a completion, returning false if the stream broke.
+	streamResponse(stream *sseWriter, c canonicalCompletion) bool
+	// writeError writes an error response.
+	writeError(w http.ResponseWriter, e *APIError)
+}
+
+// dialectRoute is an API request served through a dialect.
+type dialectRoute struct {
+	dialect dialect
+	// operation and pattern name the operation and its path, like the routes of the ogen server.
+	operation string
+	pattern   string
+	// model is the model named by the path, like an Azure deployment; empty if it is in the body.
+	model string
+}
+
+// resolveDialect returns the dialect route of a request to a provider surface other than the
+// OpenAI API. The surfaces are served only while the provider_dialects flag is enabled.
+// The minimal build serves none of them.
+func (h *StreamingHandler) resolveDialect(r *http.Request) (dialectRoute, bool) {
+	if minimalBuild || r.Method != http.MethodPost {
+		return dialectRoute{}, false
+	}
+	var route dialectRoute
+	switch {
+	case r.URL.Path == anthropicMessagesPath:
+		route = dialectRoute{dialect: anthropicDialect{}, operation: "CreateMessage", pattern: anthropicMessagesPath}
+	case strings.HasPrefix(r.URL.Path, azureDeploymentsPath) && strings.HasSuffix(r.URL.Path, azureChatSuffix):
+		deployment := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, azureDeploymentsPath), azureChatSuffix)
+		if deployment == "" || strings.Contains(deployment, "/") {
+			return dialectRoute{}, false
+		}
+		route = dialectRoute{dialect: azureDialect{}, operation: "CreateChatCompletion", pattern: azureDeploymentsPath + "{deployment-id}" + azureChatSuffix, model: deployment}
+	case strings.HasPrefix(r.URL.Path, bedrockModelPath) && (strings.HasSuffix(r.URL.Path, bedrockInvokeSuffix) || strings.HasSuffix(r.URL.Path, bedrockStreamSuffix)):
+		stream := strings.HasSuffix(r.URL.Path, bedrockStreamSuffix)
+		suffix, operation := bedrockInvokeSuffix, "InvokeModel"
+		if stream {
+			suffix, operation = bedrockStreamSuffix, "InvokeModelWithResponseStream"
+		}
+		// Model IDs may be ARNs, whose slashes are escaped in the path
+		model := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, bedrockModelPath), suffix)
+		if model == "" {
+			return dialectRoute{}, false
+		}
+		route = dialectRoute{dialect: bedrockDialect{stream: stream}, operation: operation, pattern: bedrockModelPath + "{modelId}" + suffix, model: model}
+	case strings.HasPrefix(r.URL.Path, geminiModelsPathV1) || strings.HasPrefix(r.URL.Path, geminiModelsPathV1Beta):
+		prefix := geminiModelsPathV1
+		if strings.HasPrefix(r.URL.Path, geminiModelsPathV1Beta) {
+			prefix = geminiModelsPathV1Beta
+		}
+		model, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, prefix), ":")
+		if !ok || model == "" || strings.Contains(model, "/") || (method != geminiGenerateContent && method != geminiStreamGenerateContent) {
+			return dialectRoute{}, false
+		}
+		operation := strings.ToUpper(method[:1]) + method[1:]
+		route = dialectRoute{dialect: geminiDialect{stream: method == geminiStreamGenerateContent}, operation: operation, pattern: prefix + "{model}:" + method, model: model}
+	default:
+		return dialectRoute{}, false
+	}
+	if !h.flags.Enabled(flagProviderDialects) {
+		return dialectRoute{}, false
+	}
+	return route, true
+}
+
+type dialectContextKey struct{}
+
+// withDialect stores the dialect route of a request in the context and names the operation of its
+// request trace after the route.
+func withDialect(ctx context.Context, route dialectRoute) context.Context {
+	if t := requestTraceFromContext(ctx); t != nil {
+		t.operation = route.operation
+		t.attrs = []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(http.MethodPost),
+			semconv.HTTPRouteKey.String(route.pattern),
+		}
+	}
+	return context.WithValue(ctx, dialectContextKey{}, route)
+}
+
+// dialectFromContext returns the dialect route stored by withDialect.
+func dialectFromContext(ctx context.Context) (dialectRoute, bool) {
+	route, ok := ctx.Value(dialectContextKey{}).(dialectRoute)
+	return route, ok
+}
+
+// serveDialect serves a completion request through its dialect: the content is the matched
+// scenario's, or generated as for chat completions.
+func (h *StreamingHandler) serveDialect(w http.ResponseWriter, r *http.Request, route dialectRoute) {
+	r, operation := startOperationSpan(r)
+	defer operation.End()
+	ctx := r.Context()
+	d := route.dialect
+
+	body, handled := readBodyAndCheckCreditError(w, r)
+	if handled {
+		return
+	}
+	req, err := d.decodeRequest(body, route.model)
+	if err != nil {
+		handleAPIError(ctx, w, r, newInvalidRequestError("body", err.Error()))
+		return
+	}
+	if err := validateModel(ctx, h.flags, h.models, req.Model); err != nil {
+		handleAPIError(ctx, w, r, err)
+		return
+	}
+	fit, err := h.models.checkContextWindow(req.Model, "messages", req.inputTokens(), req.MaxTokens)
+	if err != nil {
+		handleAPIError(ctx, w, r, err)
+		return
+	}
+	operation.SetAttributes(d.provider(), semconv.GenAIOperationNameChat, semconv.GenAIRequestModel(req.Model), attribute.Bool("stream", req.Stream))
+	if req.MaxTokens > 0 {
+		operation.SetAttributes(semconv.GenAIRequestMaxTokens(req.MaxTokens))
+	}
+	for _, m := range req.Messages {
+		addGenAITextMessageEvent(operation, m.Role, m.Text)
+	}
+
+	c := canonicalCompletion{ID: d.newID(), Model: req.Model, Created: time.Now(), FinishReason: finishReasonStop, InputTokens: fit.PromptTokens}
+	scenario, _ := scenarioFromContext(ctx)
+	if scenario.Content != nil {
+		c.Content = *scenario.Content
+	} else {
+		c.Content = generateAssistantText(ctx, req.Model, req.lastUserMessage(), 0, 0, 0)
+	}
+	if req.MaxTokens > 0 {
+		if content, cut := truncateTokens(c.Content, req.MaxTokens); cut {
+			c.Content, c.FinishReason = content, finishReasonLength
+		}
+	}
+	if content, cut := fit.limit(c.Content); cut {
+		c.Content, c.FinishReason = content, finishReasonLength
+	}
+	if scenario.FinishReason != "" {
+		c.FinishReason = scenario.FinishReason
+	}
+	c.OutputTokens = countTokens(c.Content)
+
+	addGenAIChoiceEvent(operation, 0, c.FinishReason, c.Content)
+	operation.SetAttributes(genAIResponseAttributes(c.ID, c.Model, c.InputTokens, c.OutputTokens, c.FinishReason)...)
+
+	if !req.Stream {
+		writeJSON(w, http.StatusOK, d.encodeResponse(c))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	stream := &sseWriter{ctx: ctx, w: w, flusher: flusher}
+	if chaosTruncationFromContext(ctx) {
+		stream.limit = chaosStreamEvents
+	}
+	h.streams.Started()
+	defer h.finishStream(operation, stream, c.ID, r.URL.Path, c.Model)
+	d.streamResponse(stream, c)
+}
+
+// decodeMessageContent returns the text of message content given as a string or as content
+// blocks with a type and text, as in the OpenAI and Anthropic APIs.
+func decodeMessageContent(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+	var content any
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return "", err
+	}
+	switch content.(type) {
+	case string, []any, nil:
+		return messageText(content), nil
+	}
+	return "", fmt.Errorf("content must be a string or an array of content blocks")
+}
+
+// --- OpenAI ---
+
+// openAIDialect is the OpenAI chat completions format.
+type openAIDialect struct{}
+
+// openAIChatRequest is the part of a chat completion request the dialects read.
+type openAIChatRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	Stream              bool `json:"stream"`
+	MaxTokens           int  `json:"max_tokens"`
+	MaxCompletionTokens int  `json:"max_completion_tokens"`
+}
+
+// openAIChatResponse is a chat completion, with the content filter results Azure adds.
+type openAIChatResponse struct {
+	ID                  string                    `json:"id"`
+	Object              string                    `json:"object"`
+	Created             int64                     `json:"created"`
+	Model               string                    `json:"model"`
+	SystemFingerprint   string                    `json:"system_fingerprint"`
+	PromptFilterResults []azurePromptFilterResult `json:"prompt_filter_results,omitempty"`
+	Choices             []openAIChatChoice        `json:"choices"`
+	Usage               openAIUsage               `json:"usage"`
+}
+
+// openAIChatChoice is a choice of an openAIChatResponse.
+type openAIChatChoice struct {
+	Index                int                 `json:"index"`
+	Message              openAIChatMessage   `json:"message"`
+	Logprobs             *struct{}           `json:"logprobs"`
+	FinishReason         string              `json:"finish_reason"`
+	ContentFilterResults azureContentFilters `json:"content_filter_results,omitempty"`
+}
+
+// openAIChatMessage is the message of an openAIChatChoice.
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// openAIUsage is the token usage of an openAIChatResponse.
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (openAIDialect) provider() attribute.KeyValue {
+	return semconv.GenAIProviderNameOpenAI
+}
+
+func (openAIDialect) newID() string {
+	return "chatcmpl-" + uuid.New().String()
+}
+
+func (openAIDialect) decodeRequest(body []byte, model string) (canonicalRequest, error) {
+	var in openAIChatRequest
+	if err := json.Unmarshal(body, &in); err != nil {
+		return canonicalRequest{}, fmt.Errorf("failed to parse request body: %v", err)
+	}
+	req := canonicalRequest{Model: in.Model, Stream: in.Stream, MaxTokens: in.MaxCompletionTokens}
+	if model != "" {
+		req.Model = model
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = in.MaxTokens
+	}
+	if req.Model == "" {
+		return canonicalRequest{}, fmt.Errorf("model is required")
+	}
+	if len(in.Messages) == 0 {
+		return canonicalRequest{}, fmt.Errorf("messages must not be empty")
+	}
+	for i, m := range in.Messages {
+		text, err := decodeMessageContent(m.Content)
+		if err != nil {
+			return canonicalRequest{}, fmt.Errorf("messages[%d]: %v", i, err)
+		}
+		role := m.Role
+		if role == "developer" {
+			role = "system"
+		}
+		req.Messages = append(req.Messages, canonicalMessage{Role: role, Text: text})
+	}
+	return req, nil
+}
+
+func (openAIDialect) encodeResponse(c canonicalCompletion) any {
+	return openAIChatResponse{
+		ID:                c.ID,
+		Object:            "chat.completion",
+		Created:           c.Created.Unix(),
+		Model:             c.Model,
+		SystemFingerprint: systemFingerprint,
+		Choices: []openAIChatChoice{{
+			Message:      openAIChatMessage{Role: "assistant", Content: c.Content},
+			FinishReason: c.FinishReason,
+		}},
+		Usage: openAIUsage{PromptTokens: c.InputTokens, CompletionTokens: c.OutputTokens, TotalTokens: c.InputTokens + c.OutputTokens},
+	}
+}
+
+func (openAIDialect) streamResponse(stream *sseWriter, c canonicalCompletion) bool {
+	chunk := func(delta ChatCompletionChunkDelta, finishReason *string) ChatCompletionChunk {
+		return ChatCompletionChunk{
+			ID:                c.ID,
+			Object:            chatCompletionChunkObject,
+			Created:           c.Created.Unix(),
+			Model:             c.Model,
+			SystemFingerprint: systemFingerprint,
+			Choices:           []ChatCompletionChunkChoice{{Delta: delta, FinishReason: finishReason}},
+		}
+	}
+	return stream.send(chunk(ChatCompletionChunkDelta{Role: "assistant"}, nil)) &&
+		stream.send(chunk(ChatCompletionChunkDelta{Content: c.Content}, nil)) &&
+		stream.send(chunk(ChatCompletionChunkDelta{}, &c.FinishReason)) &&
+		stream.sendDone()
+}
+
+func (openAIDialect) writeError(w http.ResponseWriter, e *APIError) {
+	writeOpenAIError(w, e.StatusCode, e.Detail)
+}
+
+// --- Azure OpenAI ---
+
+// azureDialect is the chat completions format of Azure OpenAI deployments: the OpenAI format with
+// the results of Azure's content filters, where the model is the deployment of the path.
+type azureDialect struct {
+	openAIDialect
+}
+
+// azureContentFilter is the result of one Azure content filter category.
+type azureContentFilter struct {
+	Filtered bool   `json:"filtered"`
+	Severity string `json:"severity"`
+}
+
+// azureContentFilters are the results of the Azure content filter categories.
+type azureContentFilters map[string]azureContentFilter
+
+// azurePromptFilterResult are the content filter results of a prompt.
+type azurePromptFilterResult struct {
+	PromptIndex          int                 `json:"prompt_index"`
+	ContentFilterResults azureContentFilters `json:"content_filter_results"`
+}
+
+// azureChunk is a stream chunk carrying the prompt filter results, which Azure sends first.
+type azureChunk struct {
+	ID                  string                      `json:"id"`
+	Object              string                      `json:"object"`
+	Created             int64                       `json:"created"`
+	Model               string                      `json:"model"`
+	PromptFilterResults []azurePromptFilterResult   `json:"prompt_filter_results"`
+	Choices             []ChatCompletionChunkChoice `json:"choices"`
+}
+
+// azureFilterResults returns the results of the Azure content filter categories. Content a
+// scenario finished with content_filter is reported as filtered for hate.
+func azureFilterResults(filtered bool) azureContentFilters {
+	results := azureContentFilters{}
+	for _, category := range []string{"hate", "self_harm", "sexual", "violence"} {
+		results[category] = azureContentFilter{Severity: "safe"}
+	}
+	if filtered {
+		results["hate"] = azureContentFilter{Filtered: true, Severity: "high"}
+	}
+	return results
+}
+
+func (azureDialect) provider() attribute.KeyValue {
+	return semconv.GenAIProviderNameAzureAIOpenAI
+}
+
+func (d azureDialect) encodeResponse(c canonicalCompletion) any {
+	resp := d.openAIDialect.encodeResponse(c).(openAIChatResponse)
+	resp.PromptFilterResults = []azurePromptFilterResult{{ContentFilterResults: azureFilterResults(false)}}
+	resp.Choices[0].ContentFilterResults = azureFilterResults(c.FinishReason == finishReasonContentFilter)
+	return resp
+}
+
+func (d azureDialect) streamResponse(stream *sseWriter, c canonicalCompletion) bool {
+	filters := azureChunk{
+		Model:               c.Model,
+		PromptFilterResults: []azurePromptFilterResult{{ContentFilterResults: azureFilterResults(false)}},
+		Choices:             []ChatCompletionChunkChoice{},
+	}
+	return stream.send(filters) && d.openAIDialect.streamResponse(stream, c)
+}
+
+// --- Anthropic ---
+
+// anthropicDialect is the format of the Anthropic Messages API.
+type anthropicDialect struct{}
+
+// anthropicRequest is the part of a Messages API request the dialect reads.
+type anthropicRequest struct {
+	Model    string          `json:"model"`
+	System   json.RawMessage `json:"system"`
+	Messages []struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"messages"`
+	MaxTokens int  `json:"max_tokens"`
+	Stream    bool `json:"stream"`
+}
+
+// anthropicMessage is a Messages API response.
+type anthropicMessage struct {
+	ID           string             `json:"id"`
+	Type         string             `json:"type"`
+	Role         string             `json:"role"`
+	Model        string             `json:"model"`
+	Content      []anthropicContent `json:"content"`
+	StopReason   *string            `json:"stop_reason"`
+	StopSequence *string            `json:"stop_sequence"`
+	Usage        anthropicUsage     `json:"usage"`
+}
+
+// anthropicContent is a content block of an anthropicMessage.
+type anthropicContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// anthropicUsage is the token usage of an anthropicMessage.
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicStopReasons translates the canonical finish reasons.
+var anthropicStopReasons = map[string]string{
+	finishReasonStop:          "end_turn",
+	finishReasonLength:        "max_tokens",
+	finishReasonContentFilter: "refusal",
+}
+
+// anthropicErrorTypes are the error types of the Anthropic API by status.
+var anthropicErrorTypes = map[int]string{
+	http.StatusBadRequest:            "invalid_request_error",
+	http.StatusUnauthorized:          "authentication_error",
+	http.StatusPaymentRequired:       "billing_error",
+	http.StatusForbidden:             "permission_error",
+	http.StatusNotFound:              "not_found_error",
+	http.StatusRequestEntityTooLarge: "request_too_large",
+	http.StatusTooManyRequests:       "rate_limit_error",
+	http.StatusGatewayTimeout:        "timeout_error",
+	529:                              "overloaded_error",
+}
+
+func (anthropicDialect) provider() attribute.KeyValue {
+	return semconv.GenAIProviderNameAnthropic
+}
+
+func (anthropicDialect) newID() string {
+	return "msg_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+}
+
+func (anthropicDialect) decodeRequest(body []byte, model string) (canonicalRequest, error) {
+	var in anthropicRequest
+	if err := json.Unmarshal(body, &in); err != nil {
+		return canonicalRequest{}, fmt.Errorf("failed to parse request body: %v", err)
+	}
+	if model != "" {
+		in.Model = model
+	}
+	switch {
+	case in.Model == "":
+		return canonicalRequest{}, fmt.Errorf("model: Field required")
+	case in.MaxTokens <= 0:
+		return canonicalRequest{}, fmt.Errorf("max_tokens: Field required")
+	case len(in.Messages) == 0:
+		return canonicalRequest{}, fmt.Errorf("messages: at least one message is required")
+	}
+	req := canonicalRequest{Model: in.Model, Stream: in.Stream, MaxTokens: in.MaxTokens}
+	if len(in.System) > 0 {
+		system, err := decodeMessageContent(in.System)
+		if err != nil {
+			return canonicalRequest{}, fmt.Errorf("system: %v", err)
+		}
+		req.Messages = append(req.Messages, canonicalMessage{Role: "system", Text: system})
+	}
+	for i, m := range in.Messages {
+		if m.Role != "user" && m.Role != "assistant" {
+			return canonicalRequest{}, fmt.Errorf("messages.%d.role: Input should be 'user' or 'assistant'", i)
+		}
+		text, err := decodeMessageContent(m.Content)
+		if err != nil {
+			return canonicalRequest{}, fmt.Errorf("messages.%d.content: %v", i, err)
+		}
+		req.Messages = append(req.Messages, canonicalMessage{Role: m.Role, Text: text})
+	}
+	return req, nil
+}
+
+// message returns the response message of a completion; content and stop reason are left out of
+// the message_start event of a stream.
+func (anthropicDialect) message(c canonicalCompletion, final bool) anthropicMessage {
+	m := anthropicMessage{
+		ID:      c.ID,
+		Type:    "message",
+		Role:    "assistant",
+		Model:   c.Model,
+		Content: []anthropicContent{},
+		Usage:   anthropicUsage{InputTokens: c.InputTokens},
+	}
+	if final {
+		stopReason := anthropicStopReasons[c.FinishReason]
+		m.Content = append(m.Content, anthropicContent{Type: "text", Text: c.Content})
+		m.StopReason = &stopReason
+		m.Usage.OutputTokens = c.OutputTokens
+	}
+	return m
+}
+
+func (d anthropicDialect) encodeResponse(c canonicalCompletion) any {
+	return d.message(c, true)
+}
+
+// anthropicEvent is an event of a Messages API stream.
+type anthropicEvent struct {
+	name string
+	data map[string]any
+}
+
+// events returns the stream events of a completion, from message_start to message_stop.
+func (d anthropicDialect) events(c canonicalCompletion) []anthropicEvent {
+	return []anthropicEvent{
+		{"message_start", map[string]any{"type": "message_start", "message": d.message(c, false)}},
+		{"content_block_start", map[string]any{"type": "content_block_start", "index": 0, "content_block": anthropicContent{Type: "text"}}},
+		{"content_block_delta", map[string]any{"type": "content_block_delta", "index": 0, "delta": map[string]string{"type": "text_delta", "text": c.Content}}},
+		{"content_block_stop", map[string]any{"type": "content_block_stop", "index": 0}},
+		{"message_delta", map[string]any{
+			"type":  "message_delta",
+			"delta": map[string]any{"stop_reason": anthropicStopReasons[c.FinishReason], "stop_sequence": nil},
+			"usage": map[string]int{"output_tokens": c.OutputTokens},
+		}},
+		{"message_stop", map[string]any{"type": "message_stop"}},
+	}
+}
+
+func (d anthropicDialect) streamResponse(stream *sseWriter, c canonicalCompletion) bool {
+	for _, e := range d.events(c) {
+		if !stream.sendEvent(e.name, e.data) {
+			return false
+		}
+	}
+	return true
+}
+
+func (anthropicDialect) writeError(w http.ResponseWriter, e *APIError) {
+	errorType, ok := anthropicErrorTypes[e.StatusCode]
+	if !ok {
+		errorType = "invalid_request_error"
+		if e.StatusCode >= 500 {
+			errorType = "api_error"
+		}
+	}
+	writeJSON(w, e.StatusCode, map[string]any{
+		"type":  "error",
+		"error": map[string]string{"type": errorType, "message": e.Detail.Message},
+	})
+}
+
+// --- Gemini ---
+
+// geminiDialect is the format of the Gemini API generateContent and streamGenerateContent methods,
+// where the model is named by the path. Streams are always sent as server-sent events, as with
+// alt=sse, which the Google GenAI SDKs request.
+type geminiDialect struct {
+	// stream is set for streamGenerateContent.
+	stream bool
+}
+
+// geminiContent is a turn of a Gemini conversation.
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+// geminiPart is a part of a geminiContent; the mock reads and writes text parts only.
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+// text returns the text of all parts of a turn.
+func (c geminiContent) text() string {
+	texts := make([]string, len(c.Parts))
+	for i, p := range c.Parts {
+		texts[i] = p.Text
+	}
+	return strings.Join(texts, " ")
+}
+
+// geminiRequest is the part of a generateContent request the dialect reads.
+type geminiRequest struct {
+	Contents          []geminiContent `json:"contents"`
+	SystemInstruction *geminiContent  `json:"systemInstruction"`
+	GenerationConfig  struct {
+		MaxOutputTokens int `json:"maxOutputTokens"`
+	} `json:"generationConfig"`
+}
+
+// geminiResponse is a generateContent response, and a chunk of a streamGenerateContent stream.
+type geminiResponse struct {
+	Candidates    []geminiCandidate   `json:"candidates"`
+	UsageMetadata geminiUsageMetadata `json:"usageMetadata"`
+	ModelVersion  string              `json:"modelVersion"`
+	ResponseID    string              `json:"responseId"`
+}
+
+// geminiCandidate is a candidate of a geminiResponse.
+type geminiCandidate struct {
+	Content      geminiContent `json:"content"`
+	FinishReason string        `json:"finishReason,omitempty"`
+	Index        int           `json:"index"`
+}
+
+// geminiUsageMetadata is the token usage of a geminiResponse.
+type geminiUsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
+	TotalTokenCount      int `json:"totalTokenCount"`
+}
+
+// geminiFinishReasons translates the canonical finish reasons.
+var geminiFinishReasons = map[string]string{
+	finishReasonStop:          "STOP",
+	finishReasonLength:        "MAX_TOKENS",
+	finishReasonContentFilter: "SAFETY",
+}
+
+// geminiStatuses are the RPC status names of the Gemini API errors by HTTP status.
+var geminiStatuses = map[int]string{
+	http.StatusBadRequest:          "INVALID_ARGUMENT",
+	http.StatusUnauthorized:        "UNAUTHENTICATED",
+	http.StatusForbidden:           "PERMISSION_DENIED",
+	http.StatusNotFound:            "NOT_FOUND",
+	http.StatusConflict:            "ABORTED",
+	http.StatusTooManyRequests:     "RESOURCE_EXHAUSTED",
+	http.StatusInternalServerError: "INTERNAL",
+	http.StatusNotImplemented:      "UNIMPLEMENTED",
+	http.StatusServiceUnavailable:  "UNAVAILABLE",
+	http.StatusGatewayTimeout:      "DEADLINE_EXCEEDED",
+}
+
+func (geminiDialect) provider() attribute.KeyValue {
+	return semconv.GenAIProviderNameGCPGemini
+}
+
+func (geminiDialect) newID() string {
+	return strings.ReplaceAll(uuid.New().String(), "-", "")[:22]
+}
+
+func (d geminiDialect) decodeRequest(body []byte, model string) (canonicalRequest, error) {
+	var in geminiRequest
+	if err := json.Unmarshal(body, &in); err != nil {
+		return canonicalRequest{}, fmt.Errorf("Invalid JSON payload received. %v", err)
+	}
+	if len(in.Contents) == 0 {
+		return canonicalRequest{}, fmt.Errorf("* GenerateContentRequest.contents: contents is not specified")
+	}
+	req := canonicalRequest{Model: model, Stream: d.stream, MaxTokens: in.GenerationConfig.MaxOutputTokens}
+	if in.SystemInstruction != nil {
+		req.Messages = append(req.Messages, canonicalMessage{Role: "system", Text: in.SystemInstruction.text()})
+	}
+	for i, c := range in.Contents {
+		role := "user"
+		switch c.Role {
+		case "", "user":
+		case "model":
+			role = "assistant"
+		default:
+			return canonicalRequest{}, fmt.Errorf("* GenerateContentRequest.contents[%d].role: Please use a valid role: user, model.", i)
+		}
+		req.Messages = append(req.Messages, canonicalMessage{Role: role, Text: c.text()})
+	}
+	return req, nil
+}
+
+// response returns the response or stream chunk of a completion carrying text; the finish reason
+// is set on the final one.
+func (geminiDialect) response(c canonicalCompletion, text string, final bool) geminiResponse {
+	candidate := geminiCandidate{Content: geminiContent{Role: "model", Parts: []geminiPart{{Text: text}}}}
+	if final {
+		candidate.FinishReason = geminiFinishReasons[c.FinishReason]
+	}
+	return geminiResponse{
+		Candidates: []geminiCandidate{candidate},
+		UsageMetadata: geminiUsageMetadata{
+			PromptTokenCount:     c.InputTokens,
+			CandidatesTokenCount: c.OutputTokens,
+			TotalTokenCount:      c.InputTokens + c.OutputTokens,
+		},
+		ModelVersion: c.Model,
+		ResponseID:   c.ID,
+	}
+}
+
+func (d geminiDialect) encodeResponse(c canonicalCompletion) any {
+	return d.response(c, c.Content, true)
+}
+
+func (d geminiDialect) streamResponse(stream *sseWriter, c canonicalCompletion) bool {
+	return stream.send(d.response(c, c.Content, false)) && stream.send(d.response(c, "", true))
+}
+
+func (geminiDialect) writeError(w http.ResponseWriter, e *APIError) {
+	status, ok := geminiStatuses[e.StatusCode]
+	if !ok {
+		status = "INVALID_ARGUMENT"
+		if e.StatusCode >= 500 {
+			status = "INTERNAL"
+		}
+	}
+	writeJSON(w, e.StatusCode, map[string]any{
+		"error": map[string]any{"code": e.StatusCode, "message": e.Detail.Message, "status": status},
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// --- decodeRequest ---
+
+func TestDialects_DecodeRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  dialect
+		body     string
+		model    string
+		want     string
+		messages int
+		wantErr  string
+	}{
+		{name: "openai", dialect: openAIDialect{}, body: `{"model":"gpt-4o","max_tokens":5,"messages":[{"role":"developer","content":"be brief"},{"role":"user","content":"hi"}]}`, want: "gpt-4o", messages: 2},
+		{name: "azure deployment", dialect: azureDialect{}, body: `{"messages":[{"role":"user","content":[{"type":"text","text":"hi"}]}]}`, model: "prod-gpt", want: "prod-gpt", messages: 1},
+		{name: "openai without messages", dialect: openAIDialect{}, body: `{"model":"gpt-4o","messages":[]}`, wantErr: "messages must not be empty"},
+		{name: "anthropic with system", dialect: anthropicDialect{}, body: `{"model":"claude-sonnet-4-5","max_tokens":10,"system":[{"type":"text","text":"be brief"}],"messages":[{"role":"user","content":"hi"}]}`, want: "claude-sonnet-4-5", messages: 2},
+		{name: "anthropic without max_tokens", dialect: anthropicDialect{}, body: `{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hi"}]}`, wantErr: "max_tokens: Field required"},
+		{name: "anthropic system role", dialect: anthropicDialect{}, body: `{"model":"claude-sonnet-4-5","max_tokens":10,"messages":[{"role":"system","content":"hi"}]}`, wantErr: "messages.0.role"},
+		{name: "gemini", dialect: geminiDialect{}, body: `{"systemInstruction":{"parts":[{"text":"be brief"}]},"contents":[{"parts":[{"text":"hi"}]},{"role":"model","parts":[{"text":"hello"}]}]}`, model: "gemini-2.0-flash", want: "gemini-2.0-flash", messages: 3},
+		{name: "gemini without contents", dialect: geminiDialect{}, body: `{"contents":[]}`, model: "gemini-2.0-flash", wantErr: "contents is not specified"},
+		{name: "gemini invalid role", dialect: geminiDialect{}, body: `{"contents":[{"role":"assistant","parts":[{"text":"hi"}]}]}`, model: "gemini-2.0-flash", wantErr: "valid role"},
+		{name: "anthropic invalid content", dialect: anthropicDialect{}, body: `{"model":"claude-sonnet-4-5","max_tokens":10,"messages":[{"role":"user","content":42}]}`, wantErr: "messages.0.content"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			req, err := tt.dialect.decodeRequest([]byte(tt.body), tt.model)
+
+			// Then
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if req.Model != tt.want || len(req.Messages) != tt.messages || req.lastUserMessage() != "hi" {
+				t.Errorf("unexpected request %+v", req)
+			}
+		})
+	}
+}
+
+// --- writeError ---
+
+func TestDialects_WriteError(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect dialect
+		status  int
+		want    string
+	}{
+		{name: "openai", dialect: openAIDialect{}, status: http.StatusTooManyRequests, want: `"code":"rate_limit_exceeded"`},
+		{name: "azure", dialect: azureDialect{}, status: http.StatusTooManyRequests, want: `"code":"rate_limit_exceeded"`},
+		{name: "anthropic rate limit", dialect: anthropicDialect{}, status: http.StatusTooManyRequests, want: `{"error":{"message":"Rate limit reached. Please try again later.","type":"rate_limit_error"},"type":"error"}`},
+		{name: "anthropic server error", dialect: anthropicDialect{}, status: http.StatusBadGateway, want: `"type":"api_error"`},
+		{name: "gemini", dialect: geminiDialect{}, status: http.StatusTooManyRequests, want: `{"error":{"code":429,"message":"Rate limit reached. Please try again later.","status":"RESOURCE_EXHAUSTED"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			rec := httptest.NewRecorder()
+
+			// When
+			tt.dialect.writeError(rec, scenarioError(tt.status, nil))
+
+			// Then
+			if rec.Code != tt.status || !strings.Contains(rec.Body.String(), tt.want) {
+				t.Errorf("expected %d with %s, got %d %s", tt.status, tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
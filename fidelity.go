@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"openai-mokku/api"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// fidelityHeader is the response header naming the fidelity level a request was served at, with the
+// repaired fields of a lenient request.
+const fidelityHeader = "X-Mokku-Fidelity"
+
+// fidelityAnyEndpoint is the endpoint_fidelity key applying to endpoints without their own entry.
+const fidelityAnyEndpoint = "*"
+
+// Endpoint fidelity levels. Endpoints without a level are served as they always are: requests are
+// validated against the API schema, and the realistic constraints follow the feature flags.
+const (
+	// fidelityStrict validates every request against the schema, streaming ones too, and rejects
+	// models GET /v1/models does not list, as with the strict_model_validation flag.
+	fidelityStrict = "strict"
+	// fidelityLenient accepts anything: fields of a request that do not fit the schema are dropped,
+	// required ones missing are filled in, and the model is never rejected.
+	fidelityLenient = "lenient"
+	// fidelityDisabled answers like the API does for a URL it does not serve.
+	fidelityDisabled = "disabled"
+)
+
+// fidelityTable holds the fidelity level of the endpoints, keyed by endpoint name as GET
+// /_mokku/overhead reports it ("POST /v1/chat/completions"), path for every method
+// ("/v1/chat/completions"), or "*" for every other endpoint. It lets teams dial the fidelity of each
+// endpoint: strict for those their app depends on, lenient for those still being integrated, and
+// disabled for those their app must not call. It is safe for concurrent use and can be replaced on
+// config reload.
+type fidelityTable struct {
+	mu     sync.RWMutex
+	levels map[string]string
+}
+
+// newFidelityTable creates a table with the configured levels.
+func newFidelityTable(cfg map[string]string) (*fidelityTable, error) {
+	t := &fidelityTable{}
+	if err := t.Load(cfg); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Load replaces the levels. On error the current levels are kept.
+func (t *fidelityTable) Load(cfg map[string]string) error {
+	return installNow(t.prepare(cfg))
+}
+
+// prepare validates the levels and returns the function installing them.
+func (t *fidelityTable) prepare(cfg map[string]string) (func(), error) {
+	for endpoint, level := range cfg {
+		if endpoint != fidelityAnyEndpoint && !strings.Contains(endpoint, "/") {
+			return nil, fmt.Errorf("endpoint_fidelity.%s: endpoint must be a path, a method and path, or %q", endpoint, fidelityAnyEndpoint)
+		}
+		if level != fidelityStrict && level != fidelityLenient && level != fidelityDisabled {
+			return nil, fmt.Errorf("endpoint_fidelity.%s: level must be %s, %s, or %s, got %q", endpoint, fidelityStrict, fidelityLenient, fidelityDisabled, level)
+		}
+	}
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.levels = maps.Clone(cfg)
+	}, nil
+}
+
+// Level returns the level of an endpoint, "" when none is configured.
+func (t *fidelityTable) Level(endpoint string) string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	_, path, _ := strings.Cut(endpoint, " ")
+	for _, key := range []string{endpoint, path, fidelityAnyEndpoint} {
+		if level, ok := t.levels[key]; ok {
+			return level
+		}
+	}
+	return ""
+}
+
+type fidelityContextKey struct{}
+
+// withFidelity stores the fidelity level of a request in the context.
+func withFidelity(ctx context.Context, level string) context.Context {
+	return context.WithValue(ctx, fidelityContextKey{}, level)
+}
+
+// fidelityFromContext returns the level stored by withFidelity, or "".
+func fidelityFromContext(ctx context.Context) string {
+	level, _ := ctx.Value(fidelityContextKey{}).(string)
+	return level
+}
+
+// applyFidelity serves a request at the fidelity level of its endpoint: it returns the request with
+// the level in its context, with the body of a lenient request repaired to fit the schema, or writes
+// the unknown URL error of a disabled endpoint and returns true.
+func (h *StreamingHandler) applyFidelity(w http.ResponseWriter, r *http.Request, endpoint string) (*http.Request, bool) {
+	level := h.fidelity.Level(endpoint)
+	if level == "" {
+		return r, false
+	}
+	w.Header().Set(fidelityHeader, level)
+	r = r.WithContext(withFidelity(r.Context(), level))
+	switch level {
+	case fidelityDisabled:
+		_, span := startRequestSpan(r, "Fidelity.disabled", attribute.String("fidelity.endpoint", endpoint))
+		span.End()
+		handleAPIError(r.Context(), w, r, newUnknownURLError(r))
+		return r, true
+	case fidelityLenient:
+		if r.Method != http.MethodPost {
+			return r, false
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return r, true
+		}
+		_ = r.Body.Close()
+		if repaired, fields, ok := repairLenientRequest(endpoint, body); ok {
+			body = repaired
+			r.Header.Set("Content-Type", "application/json")
+			if len(fields) > 0 {
+				w.Header().Set(fidelityHeader, level+"; repaired="+strings.Join(fields, ","))
+				_, span := startRequestSpan(r, "Fidelity.repaired", attribute.StringSlice("fidelity.repaired", fields))
+				span.End()
+			}
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+	}
+	return r, false
+}
+
+// newUnknownURLError is the error the API returns for a URL it does not serve.
+func newUnknownURLError(r *http.Request) *APIError {
+	return &APIError{
+		StatusCode: http.StatusNotFound,
+		Detail: OpenAIErrorDetail{
+			Message: fmt.Sprintf("Invalid URL (%s %s)", r.Method, r.URL.Path),
+			Type:    "invalid_request_error",
+			Code:    "unknown_url",
+		},
+	}
+}
+
+// lenientEndpoint is an endpoint whose requests can be repaired: validate checks a body against its
+// schema, and required holds values for its required fields that pass it.
+type lenientEndpoint struct {
+	validate func([]byte) error
+	required map[string]any
+}
+
+// lenientEndpoints are the endpoints lenient requests are repaired for. Requests to the others are
+// served as they are.
+var lenientEndpoints = map[string]lenientEndpoint{
+	"POST /v1/chat/completions": {validatesAs[api.CreateChatCompletionRequest], map[string]any{
+		"model": "gpt-4o-mini", "messages": []any{map[string]any{"role": "user", "content": ""}}}},
+	"POST /v1/completions":        {validatesAs[api.CreateCompletionRequest], map[string]any{"model": "gpt-3.5-turbo-instruct", "prompt": ""}},
+	"POST /v1/embeddings":         {validatesAs[api.CreateEmbeddingRequest], map[string]any{"model": "text-embedding-3-small", "input": ""}},
+	"POST /v1/images/generations": {validatesAs[api.CreateImageRequest], map[string]any{"prompt": ""}},
+	"POST /v1/responses":          {validatesAs[api.CreateResponseRequest], map[string]any{"model": "gpt-4o-mini", "input": ""}},
+}
+
+// schemaRequest is a request type generated from the API schema.
+type schemaRequest[T any] interface {
+	*T
+	UnmarshalJSON([]byte) error
+	Validate() error
+}
+
+// validatesAs checks that body decodes to a valid T, like the ogen server does before serving it.
+func validatesAs[T any, P schemaRequest[T]](body []byte) error {
+	req := P(new(T))
+	if err := req.UnmarshalJSON(body); err != nil {
+		return err
+	}
+	return req.Validate()
+}
+
+// repairLenientRequest makes the body of a request to endpoint fit its schema. Starting from
+// placeholder values for the required fields, it keeps every field of the body, in name order, that
+// leaves the request valid. It returns the repaired body and the fields dropped or filled in, and
+// false when the endpoint has no schema to repair against. A valid body is returned as it is.
+func repairLenientRequest(endpoint string, body []byte) ([]byte, []string, bool) {
+	e, ok := lenientEndpoints[endpoint]
+	if !ok {
+		return body, nil, false
+	}
+	if e.validate(body) == nil {
+		return body, nil, true
+	}
+	var doc map[string]any
+	if json.Unmarshal(body, &doc) != nil {
+		doc = map[string]any{}
+	}
+	repaired := maps.Clone(e.required)
+	kept := map[string]bool{}
+	for _, field := range slices.Sorted(maps.Keys(doc)) {
+		previous, had := repaired[field]
+		repaired[field] = doc[field]
+		if data, err := json.Marshal(repaired); err == nil && e.validate(data) == nil {
+			kept[field] = true
+			continue
+		}
+		if had {
+			repaired[field] = previous
+		} else {
+			delete(repaired, field)
+		}
+	}
+	var fields []string
+	for field := range doc {
+		if !kept[field] {
+			fields = append(fields, field)
+		}
+	}
+	for field := range e.required {
+		if _, sent := doc[field]; !sent {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+	data, err := json.Marshal(repaired)
+	if err != nil {
+		return body, nil, false
+	}
+	return data, fields, true
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- fidelityTable ---
+
+func TestFidelityTable_LevelPrefersMethodThenPathThenDefault(t *testing.T) {
+	// Given
+	table, err := newFidelityTable(map[string]string{
+		"POST /v1/chat/completions": fidelityStrict,
+		"/v1/chat/completions":      fidelityDisabled,
+		"/v1/models/{model}":        fidelityLenient,
+		"*":                         fidelityDisabled,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]string{
+		"POST /v1/chat/completions": fidelityStrict,
+		"GET /v1/chat/completions":  fidelityDisabled,
+		"GET /v1/models/{model}":    fidelityLenient,
+		"POST /v1/embeddings":       fidelityDisabled,
+	}
+	for endpoint, want := range cases {
+		// When
+		level := table.Level(endpoint)
+		// Then
+		if level != want {
+			t.Errorf("%s: expected %s, got %q", endpoint, want, level)
+		}
+	}
+}
+
+func TestFidelityTable_LoadRejectsInvalidEntries(t *testing.T) {
+	// Given
+	table, _ := newFidelityTable(map[string]string{"/v1/embeddings": fidelityLenient})
+	cases := map[string]map[string]string{
+		"unknown level":   {"/v1/embeddings": "loose"},
+		"not an endpoint": {"embeddings": fidelityStrict},
+	}
+	for name, cfg := range cases {
+		// When
+		err := table.Load(cfg)
+		// Then: the current levels are kept
+		if err == nil || table.Level("POST /v1/embeddings") != fidelityLenient {
+			t.Errorf("%s: expected an error keeping the current levels, got %v", name, err)
+		}
+	}
+}
+
+// --- repairLenientRequest ---
+
+func TestRepairLenientRequest_DropsInvalidFieldsAndFillsRequiredOnes(t *testing.T) {
+	// Given: a chat request without messages, with an out-of-range temperature and a misplaced n
+	body := `{"model":"gpt-4o","temperature":7,"n":"two","user":"u-1"}`
+
+	// When
+	repaired, fields, ok := repairLenientRequest("POST /v1/chat/completions", []byte(body))
+
+	// Then: the valid fields are kept, and the request now validates
+	if !ok || strings.Join(fields, ",") != "messages,n,temperature" {
+		t.Fatalf("expected messages, n, and temperature to be repaired, got %v (%v)", fields, ok)
+	}
+	if err := lenientEndpoints["POST /v1/chat/completions"].validate(repaired); err != nil {
+		t.Fatalf("expected a valid request, got %v: %s", err, repaired)
+	}
+	if !strings.Contains(string(repaired), `"model":"gpt-4o"`) || !strings.Contains(string(repaired), `"user":"u-1"`) {
+		t.Errorf("expected the valid fields to be kept, got %s", repaired)
+	}
+}
+
+func TestRepairLenientRequest_KeepsValidBodiesAndSkipsUnknownEndpoints(t *testing.T) {
+	// Given
+	valid := `{"model":"text-embedding-3-small","input":"hi"}`
+
+	// When
+	same, fields, ok := repairLenientRequest("POST /v1/embeddings", []byte(valid))
+	_, _, known := repairLenientRequest("POST /v1/audio/speech", []byte("{"))
+
+	// Then
+	if !ok || string(same) != valid || len(fields) != 0 {
+		t.Errorf("expected the valid body as it is, got %s %v", same, fields)
+	}
+	if known {
+		t.Error("expected no repair for an endpoint without a schema")
+	}
+	for endpoint, e := range lenientEndpoints {
+		if repaired, _, _ := repairLenientRequest(endpoint, []byte("not json")); e.validate(repaired) != nil {
+			t.Errorf("%s: expected the placeholders to validate, got %s", endpoint, repaired)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// featureFlag is an experimental behavior that operators can switch on or off.
+type featureFlag struct {
+	Name        string
+	Description string
+	Default     bool
+}
+
+// Feature flag names.
+const (
+	flagStrictModelValidation = "strict_model_validation"
+	flagStrictScenarios       = "strict_scenarios"
+	flagBaggageOverrides      = "baggage_overrides"
+	flagProviderDialects      = "provider_dialects"
+	flagStickyPersonality     = "sticky_personality"
+	flagRealisticStreaming    = "realistic_streaming"
+	flagStreamReconciliation  = "stream_reconciliation"
+)
+
+// knownFeatureFlags lists every feature flag. Unknown names are rejected so typos are caught at startup.
+var knownFeatureFlags = []featureFlag{
+	{
+		Name:        flagStrictModelValidation,
+		Description: "Reject requests for models not listed by GET /v1/models with 404 model_not_found",
+		Default:     false,
+	},
+	{
+		Name:        flagStrictScenarios,
+		Description: "Reject API requests no scenario matches with 418 scenario_not_matched, a diff against the closest scenario, and a count in GET /_mokku/verify",
+		Default:     false,
+	},
+	{
+		Name:        flagBaggageOverrides,
+		Description: "Apply mokku.latency and mokku.error members of the W3C baggage header of API requests as behavior overrides",
+		Default:     false,
+	},
+	{
+		Name:        flagProviderDialects,
+		Description: "Serve the Azure OpenAI chat completions, Anthropic Messages, Gemini generateContent, and Bedrock InvokeModel surfaces from the same scenarios as the OpenAI API",
+		Default:     false,
+	},
+	{
+		Name:        flagStickyPersonality,
+		Description: "Give every conversation a stable latency band, verbosity, and emoji usage derived from its conversation or end-user ID",
+		Default:     false,
+	},
+	{
+		Name:        flagRealisticStreaming,
+		Description: "Stream chat and legacy completions at the time to first token, tokens per second, and chunk size of the model's family",
+		Default:     false,
+	},
+	{
+		Name:        flagStreamReconciliation,
+		Description: "Reassemble every chat and legacy completion stream sent in full, check it against the non-streaming response to the same request, and report mismatches in GET /_mokku/streams",
+		Default:     false,
+	},
+}
+
+// Flag sources, from lowest to highest precedence.
+const (
+	flagSourceDefault = "default"
+	flagSourceConfig  = "config"
+	flagSourceEnv     = "env"
+	flagSourceAdmin   = "admin"
+)
+
+// flagState is the current value of a flag and how often it was evaluated.
+type flagState struct {
+	enabled     atomic.Bool
+	source      atomic.Value // string
+	evaluations atomic.Int64
+	hits        atomic.Int64
+}
+
+// featureFlagStatus is the view of a flag returned by GET /_mokku/flags
+type featureFlagStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Source      string `json:"source"`
+	Evaluations int64  `json:"evaluations"`
+	Hits        int64  `json:"hits"`
+}
+
+// featureFlags holds the feature flags of this instance. Values can be toggled at runtime; evaluation
+// counts are kept per flag so operators can see whether a flag is actually exercised. The set of
+// flags is fixed at construction, so lookups need no lock.
+type featureFlags struct {
+	states map[string]*flagState
+}
+
+// newFeatureFlags resolves flag values from defaults, the config file, and MOKKU_FEATURES, a comma
+// separated list of flag names where a leading "-" disables the flag.
+func newFeatureFlags(config map[string]bool, env string) (*featureFlags, error) {
+	f := &featureFlags{states: map[string]*flagState{}}
+	for _, flag := range knownFeatureFlags {
+		f.states[flag.Name] = &flagState{}
+	}
+	if err := f.Reload(config, env); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Reload re-resolves every flag from its default, the config file, and MOKKU_FEATURES, discarding
+// admin API toggles. Nothing changes if any flag name is unknown. Evaluation counts are kept.
+func (f *featureFlags) Reload(config map[string]bool, env string) error {
+	return installNow(f.prepare(config, env))
+}
+
+// prepare validates the flag settings and returns the function installing them.
+func (f *featureFlags) prepare(config map[string]bool, env string) (func(), error) {
+	values := map[string]bool{}
+	sources := map[string]string{}
+	for _, flag := range knownFeatureFlags {
+		values[flag.Name] = flag.Default
+		sources[flag.Name] = flagSourceDefault
+	}
+	for name, enabled := range config {
+		if _, ok := f.states[name]; !ok {
+			return nil, fmt.Errorf("unknown feature flag %q", name)
+		}
+		values[name] = enabled
+		sources[name] = flagSourceConfig
+	}
+	for _, entry := range strings.Split(env, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, disabled := strings.CutPrefix(entry, "-")
+		if _, ok := f.states[name]; !ok {
+			return nil, fmt.Errorf("unknown feature flag %q", name)
+		}
+		values[name] = !disabled
+		sources[name] = flagSourceEnv
+	}
+	return func() {
+		for name, enabled := range values {
+			_ = f.Set(name, enabled, sources[name])
+		}
+	}, nil
+}
+
+// Enabled reports whether the named flag is on and counts the evaluation. Unknown flags are off.
+func (f *featureFlags) Enabled(name string) bool {
+	s, ok := f.states[name]
+	if !ok {
+		return false
+	}
+	s.evaluations.Add(1)
+	if !s.enabled.Load() {
+		return false
+	}
+	s.hits.Add(1)
+	return true
+}
+
+// Set changes the value of the named flag, recording where the value came from.
+func (f *featureFlags) Set(name string, enabled bool, source string) error {
+	s, ok := f.states[name]
+	if !ok {
+		return fmt.Errorf("unknown feature flag %q", name)
+	}
+	s.enabled.Store(enabled)
+	s.source.Store(source)
+	return nil
+}
+
+// Values returns the current value of every flag.
+func (f *featureFlags) Values() map[string]bool {
+	values := make(map[string]bool, len(f.states))
+	for name, s := range f.states {
+		values[name] = s.enabled.Load()
+	}
+	return values
+}
+
+// Status returns every flag with its value, source, and evaluation counts, sorted by name.
+func (f *featureFlags) Status() []featureFlagStatus {
+	statuses := make([]featureFlagStatus, 0, len(knownFeatureFlags))
+	for _, flag := range knownFeatureFlags {
+		s := f.states[flag.Name]
+		statuses = append(statuses, featureFlagStatus{
+			Name:        flag.Name,
+			Description: flag.Description,
+			Enabled:     s.enabled.Load(),
+			Source:      s.source.Load().(string),
+			Evaluations: s.evaluations.Load(),
+			Hits:        s.hits.Load(),
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
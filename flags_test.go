@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// --- newFeatureFlags ---
+
+func TestNewFeatureFlags_Defaults(t *testing.T) {
+	// When
+	flags, err := newFeatureFlags(nil, "")
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flags.Enabled(flagStrictModelValidation) {
+		t.Error("expected strict_model_validation to be off by default")
+	}
+}
+
+func TestNewFeatureFlags_EnvOverridesConfig(t *testing.T) {
+	// Given: config enables the flag, the environment disables it
+	config := map[string]bool{flagStrictModelValidation: true}
+	// When
+	flags, err := newFeatureFlags(config, " -strict_model_validation ")
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	status := flagStatus(t, flags, flagStrictModelValidation)
+	if status.Enabled || status.Source != flagSourceEnv {
+		t.Errorf("expected disabled from env, got %+v", status)
+	}
+}
+
+func TestNewFeatureFlags_ConfigEnables(t *testing.T) {
+	// When
+	flags, err := newFeatureFlags(map[string]bool{flagStrictModelValidation: true}, "")
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !flags.Enabled(flagStrictModelValidation) {
+		t.Error("expected the config file to enable the flag")
+	}
+}
+
+func TestNewFeatureFlags_UnknownFlagIsError(t *testing.T) {
+	// When
+	_, err := newFeatureFlags(nil, "strict_modle_validation")
+	// Then
+	if err == nil {
+		t.Error("expected an error for a misspelled flag")
+	}
+}
+
+// --- featureFlags.Enabled ---
+
+func TestFeatureFlags_EnabledCountsEvaluationsAndHits(t *testing.T) {
+	// Given
+	flags, _ := newFeatureFlags(nil, "")
+	flags.Enabled(flagStrictModelValidation)
+	_ = flags.Set(flagStrictModelValidation, true, flagSourceAdmin)
+	// When
+	flags.Enabled(flagStrictModelValidation)
+	// Then: two evaluations, one while enabled
+	status := flagStatus(t, flags, flagStrictModelValidation)
+	if status.Evaluations != 2 || status.Hits != 1 || status.Source != flagSourceAdmin {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+// --- validateModel ---
+
+func TestValidateModel_StrictRejectsUnknownModel(t *testing.T) {
+	// Given
+	flags, _ := newFeatureFlags(nil, "strict_model_validation")
+	models, _ := newModelCatalog(nil)
+	// When
+	err := validateModel(context.Background(), flags, models, "gpt-9")
+	// Then
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.StatusCode != 404 || apiErr.Detail.Code != "model_not_found" {
+		t.Errorf("expected 404 model_not_found, got %v", err)
+	}
+}
+
+func TestValidateModel_StrictAcceptsListedAndMagicModels(t *testing.T) {
+	// Given
+	flags, _ := newFeatureFlags(nil, "strict_model_validation")
+	models, _ := newModelCatalog([]modelMetadata{{ID: "ft:gpt-4o:acme"}})
+	// When / Then
+	for _, model := range []string{"gpt-4o", "ft:gpt-4o:acme", LoremModelName, CreditErrorModelName} {
+		if err := validateModel(context.Background(), flags, models, model); err != nil {
+			t.Errorf("expected %s to be accepted, got %v", model, err)
+		}
+	}
+}
+
+// flagStatus returns the status of the named flag.
+func flagStatus(t *testing.T, flags *featureFlags, name string) featureFlagStatus {
+	t.Helper()
+	for _, status := range flags.Status() {
+		if status.Name == name {
+			return status
+		}
+	}
+	t.Fatalf("flag %s not listed", name)
+	return featureFlagStatus{}
+}
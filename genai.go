@@ -0,0 +1,154 @@
+package main
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"openai-mokku/api"
+)
+
+// GenAI span events carrying prompts and completions, as emitted by provider instrumentation.
+const (
+	genAIChoiceEvent = "gen_ai.choice"
+	// genAIContentKey is the event attribute holding the text of a message or choice.
+	genAIContentKey = attribute.Key("gen_ai.event.content")
+)
+
+// genAIMessageEvents are the span events of prompt messages by role. Results of the deprecated
+// function calls are reported as tool messages.
+var genAIMessageEvents = map[api.ChatCompletionRequestMessageRole]string{
+	api.ChatCompletionRequestMessageRoleSystem:    "gen_ai.system.message",
+	api.ChatCompletionRequestMessageRoleUser:      "gen_ai.user.message",
+	api.ChatCompletionRequestMessageRoleAssistant: "gen_ai.assistant.message",
+	api.ChatCompletionRequestMessageRoleTool:      "gen_ai.tool.message",
+	api.ChatCompletionRequestMessageRoleFunction:  "gen_ai.tool.message",
+}
+
+// genAIRequestAttributes returns the GenAI semantic convention attributes common to every model
+// request, so mokku spans can be compared with those of real provider instrumentation.
+func genAIRequestAttributes(operation attribute.KeyValue, model string) []attribute.KeyValue {
+	return []attribute.KeyValue{semconv.GenAIProviderNameOpenAI, operation, semconv.GenAIRequestModel(model)}
+}
+
+// genAIChatRequestAttributes returns the GenAI attributes of a chat completion request.
+func genAIChatRequestAttributes(req *api.CreateChatCompletionRequest) []attribute.KeyValue {
+	attrs := genAIRequestAttributes(semconv.GenAIOperationNameChat, req.Model)
+	if req.Temperature.Set {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(req.Temperature.Value))
+	}
+	if req.TopP.Set {
+		attrs = append(attrs, semconv.GenAIRequestTopP(req.TopP.Value))
+	}
+	if req.N.Set {
+		attrs = append(attrs, semconv.GenAIRequestChoiceCount(req.N.Value))
+	}
+	if maxTokens := chatMaxTokens(req); maxTokens > 0 {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(maxTokens))
+	}
+	if req.PresencePenalty.Set {
+		attrs = append(attrs, semconv.GenAIRequestPresencePenalty(req.PresencePenalty.Value))
+	}
+	if req.FrequencyPenalty.Set {
+		attrs = append(attrs, semconv.GenAIRequestFrequencyPenalty(req.FrequencyPenalty.Value))
+	}
+	if req.Seed.Set {
+		attrs = append(attrs, semconv.GenAIRequestSeed(req.Seed.Value))
+	}
+	return attrs
+}
+
+// genAICompletionRequestAttributes returns the GenAI attributes of a legacy completion request.
+func genAICompletionRequestAttributes(req *api.CreateCompletionRequest) []attribute.KeyValue {
+	attrs := genAIRequestAttributes(semconv.GenAIOperationNameTextCompletion, req.Model)
+	if req.Temperature.Set {
+		attrs = append(attrs, semconv.GenAIRequestTemperature(req.Temperature.Value))
+	}
+	if req.TopP.Set {
+		attrs = append(attrs, semconv.GenAIRequestTopP(req.TopP.Value))
+	}
+	if req.N.Set {
+		attrs = append(attrs, semconv.GenAIRequestChoiceCount(req.N.Value))
+	}
+	if req.MaxTokens.Set {
+		attrs = append(attrs, semconv.GenAIRequestMaxTokens(req.MaxTokens.Value))
+	}
+	if req.PresencePenalty.Set {
+		attrs = append(attrs, semconv.GenAIRequestPresencePenalty(req.PresencePenalty.Value))
+	}
+	if req.FrequencyPenalty.Set {
+		attrs = append(attrs, semconv.GenAIRequestFrequencyPenalty(req.FrequencyPenalty.Value))
+	}
+	if req.Seed.Set {
+		attrs = append(attrs, semconv.GenAIRequestSeed(req.Seed.Value))
+	}
+	return attrs
+}
+
+// genAIResponseAttributes returns the GenAI attributes of a model response and its usage.
+func genAIResponseAttributes(id, model string, inputTokens, outputTokens int, finishReasons ...string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.GenAIResponseModel(model),
+		semconv.GenAIUsageInputTokens(inputTokens),
+		semconv.GenAIUsageOutputTokens(outputTokens),
+	}
+	if id != "" {
+		attrs = append(attrs, semconv.GenAIResponseID(id))
+	}
+	if len(finishReasons) > 0 {
+		attrs = append(attrs, semconv.GenAIResponseFinishReasons(finishReasons...))
+	}
+	return attrs
+}
+
+// addGenAIMessageEvents adds an event per prompt message to span, in conversation order. Contents
+// are left out when bodies are omitted from spans (MOKKU_TRACE_BODIES).
+func addGenAIMessageEvents(span trace.Span, messages []api.ChatCompletionRequestMessage) {
+	if !span.IsRecording() {
+		return
+	}
+	for _, m := range messages {
+		name, ok := genAIMessageEvents[m.Role]
+		if !ok {
+			continue
+		}
+		var attrs []attribute.KeyValue
+		if !omitSpanBodies.Load() {
+			attrs = append(attrs, genAIContentKey.String(messageContentText(m.Content.Value)))
+		}
+		span.AddEvent(name, trace.WithAttributes(attrs...))
+	}
+}
+
+// addGenAIPromptEvent adds the event of a prompt given as plain text, such as a legacy completion
+// prompt, to span, like addGenAIMessageEvents.
+func addGenAIPromptEvent(span trace.Span, prompt string) {
+	addGenAITextMessageEvent(span, string(api.ChatCompletionRequestMessageRoleUser), prompt)
+}
+
+// addGenAITextMessageEvent adds the event of a prompt message of role given as plain text, such as
+// a message of another provider's API, to span, like addGenAIMessageEvents.
+func addGenAITextMessageEvent(span trace.Span, role, text string) {
+	name, ok := genAIMessageEvents[api.ChatCompletionRequestMessageRole(role)]
+	if !ok {
+		return
+	}
+	var attrs []attribute.KeyValue
+	if !omitSpanBodies.Load() {
+		attrs = append(attrs, genAIContentKey.String(text))
+	}
+	span.AddEvent(name, trace.WithAttributes(attrs...))
+}
+
+// addGenAIChoiceEvent adds the event of a completion choice to span. The content is left out when
+// bodies are omitted from spans (MOKKU_TRACE_BODIES).
+func addGenAIChoiceEvent(span trace.Span, index int, finishReason, content string) {
+	attrs := []attribute.KeyValue{
+		attribute.Int("gen_ai.choice.index", index),
+		attribute.String("gen_ai.choice.finish_reason", finishReason),
+	}
+	if !omitSpanBodies.Load() {
+		attrs = append(attrs, genAIContentKey.String(content))
+	}
+	span.AddEvent(genAIChoiceEvent, trace.WithAttributes(attrs...))
+}
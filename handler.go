@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
 	"time"
 
 	"openai-mokku/api"
@@ -11,6 +15,8 @@ import (
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var tracer = otel.Tracer("openai-mokku")
@@ -23,7 +29,12 @@ func marshalJSON(v interface{}) string {
 }
 
 // MockHandler implements the api.Handler interface
-type MockHandler struct{}
+type MockHandler struct {
+	embeddings *embeddingIndex
+	images     *imageStore
+	flags      *featureFlags
+	models     *modelCatalog
+}
 
 var _ api.Handler = (*MockHandler)(nil)
 
@@ -32,125 +43,167 @@ func (h *MockHandler) CreateChatCompletion(ctx context.Context, req *api.CreateC
 	ctx, span := tracer.Start(ctx, "CreateChatCompletion.process")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.full_json", marshalJSON(req)))
-
-	lastUserMessage := extractLastUserMessage(req.Messages)
+	setSpanBody(span, "request.full_json", req)
 
-	attrs := []attribute.KeyValue{
-		attribute.String("model", req.Model),
-		attribute.Int("message_count", len(req.Messages)),
-		attribute.String("last_user_message", lastUserMessage),
+	if err := validateModel(ctx, h.flags, h.models, req.Model); err != nil {
+		return nil, err
 	}
 
-	if req.Temperature.Set {
-		attrs = append(attrs, attribute.Float64("temperature", req.Temperature.Value))
+	lastUserMessage := extractLastUserMessage(req.Messages)
+
+	audioTokens, err := countAudioTokens(req.Messages)
+	if err != nil {
+		return nil, err
 	}
-	if req.TopP.Set {
-		attrs = append(attrs, attribute.Float64("top_p", req.TopP.Value))
+	if err := validateLogitBias(req.LogitBias.Value); err != nil {
+		return nil, err
 	}
-	if req.N.Set {
-		attrs = append(attrs, attribute.Int("n", req.N.Value))
+	fit, err := h.models.checkContextWindow(req.Model, "messages", countMessageTokens(req.Messages)+audioTokens, chatMaxTokens(req))
+	if err != nil {
+		return nil, err
 	}
+
+	attrs := genAIChatRequestAttributes(req)
 	if req.Stream.Set {
 		attrs = append(attrs, attribute.Bool("stream", req.Stream.Value))
 	}
-	if req.MaxTokens.Set {
-		attrs = append(attrs, attribute.Int("max_tokens", req.MaxTokens.Value))
-	}
-	if req.MaxCompletionTokens.Set {
-		attrs = append(attrs, attribute.Int("max_completion_tokens", req.MaxCompletionTokens.Value))
-	}
-	if req.PresencePenalty.Set {
-		attrs = append(attrs, attribute.Float64("presence_penalty", req.PresencePenalty.Value))
-	}
-	if req.FrequencyPenalty.Set {
-		attrs = append(attrs, attribute.Float64("frequency_penalty", req.FrequencyPenalty.Value))
-	}
 	if req.User.Set {
 		attrs = append(attrs, attribute.String("user", req.User.Value))
 	}
-	if req.Seed.Set {
-		attrs = append(attrs, attribute.Int("seed", req.Seed.Value))
+	if req.SafetyIdentifier.Set {
+		attrs = append(attrs, attribute.String("safety_identifier", req.SafetyIdentifier.Value))
+	}
+	if audioTokens > 0 {
+		attrs = append(attrs, attribute.Int("audio_tokens", audioTokens))
+	}
+	if req.LogitBias.Set {
+		attrs = append(attrs, attribute.String("logit_bias", marshalJSON(req.LogitBias.Value)))
 	}
 
 	span.SetAttributes(attrs...)
+	addGenAIMessageEvents(span, req.Messages)
 
-	// Priority: ResponseFormat (json_schema/json_object) > Tools
+	// Priority: scenario content > ResponseFormat (json_schema/json_object) > tool calls > text
 	var choices []api.ChatCompletionChoice
 	var completionLen int
 
-	if req.ResponseFormat.Set &&
-		(req.ResponseFormat.Value.Type == api.ChatCompletionResponseFormatTypeJSONSchema ||
-			req.ResponseFormat.Value.Type == api.ChatCompletionResponseFormatTypeJSONObject) &&
-		req.ResponseFormat.Value.JSONSchema.Set {
-		jsonContent := generateJSONFromSchemaBytes(req.ResponseFormat.Value.JSONSchema.Value.Schema)
-		completionLen = len(jsonContent)
+	scenario, _ := scenarioFromContext(ctx)
+	if scenario.Content != nil {
+		completionLen = countTokens(*scenario.Content)
 		choices = []api.ChatCompletionChoice{
 			{
 				Index: 0,
 				Message: api.ChatCompletionResponseMessage{
 					Role:    api.ChatCompletionResponseMessageRoleAssistant,
-					Content: api.NewNilString(jsonContent),
+					Content: api.NewNilString(*scenario.Content),
 				},
 				FinishReason: api.ChatCompletionChoiceFinishReasonStop,
 			},
 		}
-	} else if len(req.Tools) > 0 {
-		tool := req.Tools[0]
-		argsMap := map[string]string{"input": lastUserMessage}
-		argsBytes, _ := json.Marshal(argsMap)
-		args := string(argsBytes)
-		completionLen = len(args)
+	} else if jsonContent, ok := generateJSONModeContent(req.ResponseFormat, lastUserMessage); ok {
+		completionLen = countTokens(jsonContent)
 		choices = []api.ChatCompletionChoice{
 			{
 				Index: 0,
 				Message: api.ChatCompletionResponseMessage{
 					Role:    api.ChatCompletionResponseMessageRoleAssistant,
-					Content: api.NewNilString(""),
-					ToolCalls: []api.ChatCompletionMessageToolCall{
-						{
-							ID:   "call_" + uuid.New().String(),
-							Type: api.ChatCompletionMessageToolCallTypeFunction,
-							Function: api.ChatCompletionMessageToolCallFunction{
-								Name:      tool.Function.Name,
-								Arguments: args,
-							},
-						},
-					},
+					Content: api.NewNilString(jsonContent),
 				},
-				FinishReason: api.ChatCompletionChoiceFinishReasonToolCalls,
+				FinishReason: api.ChatCompletionChoiceFinishReasonStop,
 			},
 		}
-	} else {
-		echoMessage := generateEchoResponse(ctx, lastUserMessage)
-		completionLen = len(echoMessage)
+	} else if toolCalls, err := planToolCalls(req, lastUserMessage); err != nil {
+		return nil, err
+	} else if len(toolCalls) > 0 {
+		span.SetAttributes(attribute.Int("tool_calls", len(toolCalls)))
+		completionLen = toolCallTokens(toolCalls)
 		choices = []api.ChatCompletionChoice{
 			{
 				Index: 0,
 				Message: api.ChatCompletionResponseMessage{
-					Role:    api.ChatCompletionResponseMessageRoleAssistant,
-					Content: api.NewNilString(echoMessage),
+					Role:      api.ChatCompletionResponseMessageRoleAssistant,
+					Content:   api.NilString{Null: true},
+					ToolCalls: apiToolCalls(toolCalls),
 				},
+				FinishReason: api.ChatCompletionChoiceFinishReasonToolCalls,
+			},
+		}
+	} else {
+		text := generateAssistantText(ctx, req.Model, lastUserMessage, 0, req.PresencePenalty.Value, req.FrequencyPenalty.Value)
+		echoMessage, bannedTokens := stripBannedTokens(text, req.LogitBias.Value)
+		span.SetAttributes(attribute.Int("logit_bias.removed_tokens", bannedTokens))
+		var citations []urlCitation
+		if webSearchRequested(req) {
+			echoMessage, citations = citeWebSources(echoMessage)
+			span.SetAttributes(attribute.Int("url_citations", len(citations)))
+		}
+		completionLen = countTokens(echoMessage)
+		message := api.ChatCompletionResponseMessage{
+			Role:    api.ChatCompletionResponseMessageRoleAssistant,
+			Content: api.NewNilString(echoMessage),
+		}
+		if citations != nil {
+			message.Annotations = chatAnnotations(citations)
+		}
+		choices = []api.ChatCompletionChoice{
+			{
+				Index:        0,
+				Message:      message,
 				FinishReason: api.ChatCompletionChoiceFinishReasonStop,
 			},
 		}
 	}
 
+	// A truncated request gets the content that fits the tokens left in the context window.
+	if message := &choices[0].Message; !message.Content.Null {
+		if content, cut := fit.limit(message.Content.Value); cut {
+			message.Content.Value = content
+			message.Annotations = nil
+			choices[0].FinishReason = api.ChatCompletionChoiceFinishReasonLength
+			completionLen = countTokens(content)
+			span.SetAttributes(attribute.Bool("context_window.truncated", true))
+		}
+	}
+
+	if scenario.FinishReason != "" {
+		for i := range choices {
+			choices[i].FinishReason = api.ChatCompletionChoiceFinishReason(scenario.FinishReason)
+		}
+	}
+
+	promptTokens := fit.PromptTokens
+	usage := api.CompletionUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionLen,
+		TotalTokens:      promptTokens + completionLen,
+	}
+	if audioTokens > 0 {
+		usage.PromptTokensDetails = api.NewOptPromptTokensDetails(api.PromptTokensDetails{
+			AudioTokens: api.NewOptInt(audioTokens),
+		})
+	}
+
 	response := &api.CreateChatCompletionResponse{
-		ID:      "chatcmpl-" + uuid.New().String(),
-		Object:  api.CreateChatCompletionResponseObjectChatCompletion,
-		Created: time.Now().Unix(),
-		Model:   req.Model,
-		Choices: choices,
-		Usage: api.NewOptCompletionUsage(api.CompletionUsage{
-			PromptTokens:     len(lastUserMessage),
-			CompletionTokens: completionLen,
-			TotalTokens:      len(lastUserMessage) + completionLen,
-		}),
+		ID:                "chatcmpl-" + uuid.New().String(),
+		Object:            api.CreateChatCompletionResponseObjectChatCompletion,
+		Created:           time.Now().Unix(),
+		Model:             req.Model,
+		Choices:           choices,
+		Usage:             api.NewOptCompletionUsage(usage),
 		SystemFingerprint: api.NewOptString(systemFingerprint),
 	}
 
-	span.SetAttributes(attribute.String("response.full_json", marshalJSON(response)))
+	finishReasons := make([]string, len(choices))
+	for i, choice := range choices {
+		finishReasons[i] = string(choice.FinishReason)
+		content := choice.Message.Content.Value
+		if len(choice.Message.ToolCalls) > 0 {
+			content = marshalJSON(choice.Message.ToolCalls)
+		}
+		addGenAIChoiceEvent(span, choice.Index, finishReasons[i], content)
+	}
+	span.SetAttributes(genAIResponseAttributes(response.ID, response.Model, usage.PromptTokens, usage.CompletionTokens, finishReasons...)...)
+	setSpanBody(span, "response.full_json", response)
 
 	return response, nil
 }
@@ -160,119 +213,200 @@ func (h *MockHandler) CreateCompletion(ctx context.Context, req *api.CreateCompl
 	ctx, span := tracer.Start(ctx, "CreateCompletion.process")
 	defer span.End()
 
-	// Get the prompt (simplified: just use string version)
-	prompt := ""
-	if req.Prompt.IsString() {
-		prompt, _ = req.Prompt.GetString()
-	} else if req.Prompt.IsStringArray() {
-		arr, _ := req.Prompt.GetStringArray()
-		if len(arr) > 0 {
-			prompt = arr[0]
-		}
-	}
-
-	attrs := []attribute.KeyValue{
-		attribute.String("model", req.Model),
-		attribute.String("prompt", prompt),
+	if err := validateModel(ctx, h.flags, h.models, req.Model); err != nil {
+		return nil, err
 	}
 
-	if req.Temperature.Set {
-		attrs = append(attrs, attribute.Float64("temperature", req.Temperature.Value))
-	}
-	if req.TopP.Set {
-		attrs = append(attrs, attribute.Float64("top_p", req.TopP.Value))
+	prompt, promptTokens, err := completionPrompt(req.Prompt)
+	if err != nil {
+		return nil, err
 	}
-	if req.N.Set {
-		attrs = append(attrs, attribute.Int("n", req.N.Value))
+	fit, err := h.models.checkContextWindow(req.Model, "prompt", promptTokens, req.MaxTokens.Value)
+	if err != nil {
+		return nil, err
 	}
+
+	attrs := genAICompletionRequestAttributes(req)
 	if req.Stream.Set {
 		attrs = append(attrs, attribute.Bool("stream", req.Stream.Value))
 	}
-	if req.MaxTokens.Set {
-		attrs = append(attrs, attribute.Int("max_tokens", req.MaxTokens.Value))
-	}
 	if req.Echo.Set {
 		attrs = append(attrs, attribute.Bool("echo", req.Echo.Value))
 	}
-	if req.PresencePenalty.Set {
-		attrs = append(attrs, attribute.Float64("presence_penalty", req.PresencePenalty.Value))
-	}
-	if req.FrequencyPenalty.Set {
-		attrs = append(attrs, attribute.Float64("frequency_penalty", req.FrequencyPenalty.Value))
-	}
 	if req.BestOf.Set {
 		attrs = append(attrs, attribute.Int("best_of", req.BestOf.Value))
 	}
 	if req.User.Set {
 		attrs = append(attrs, attribute.String("user", req.User.Value))
 	}
-	if req.Seed.Set {
-		attrs = append(attrs, attribute.Int("seed", req.Seed.Value))
+	if req.LogitBias.Set {
+		attrs = append(attrs, attribute.String("logit_bias", marshalJSON(req.LogitBias.Value)))
+	}
+	if req.Suffix.Set && !req.Suffix.Null {
+		attrs = append(attrs, attribute.String("suffix", req.Suffix.Value))
 	}
 
 	span.SetAttributes(attrs...)
+	addGenAIPromptEvent(span, prompt)
 
-	echoText := generateEchoResponse(ctx, prompt)
+	choices, completionLen, err := generateCompletionChoices(ctx, req, prompt, fit)
+	if err != nil {
+		return nil, err
+	}
 
 	response := &api.CreateCompletionResponse{
 		ID:      "cmpl-" + uuid.New().String(),
 		Object:  api.CreateCompletionResponseObjectTextCompletion,
 		Created: time.Now().Unix(),
 		Model:   req.Model,
-		Choices: []api.CompletionChoice{
-			{
-				Index:        0,
-				Text:         echoText,
-				FinishReason: api.CompletionChoiceFinishReasonStop,
-			},
-		},
+		Choices: choices,
 		Usage: api.NewOptCompletionUsage(api.CompletionUsage{
-			PromptTokens:     len(prompt),
-			CompletionTokens: len(echoText),
-			TotalTokens:      len(prompt) + len(echoText),
+			PromptTokens:     fit.PromptTokens,
+			CompletionTokens: completionLen,
+			TotalTokens:      fit.PromptTokens + completionLen,
 		}),
 		SystemFingerprint: api.NewOptString(systemFingerprint),
 	}
 
+	finishReasons := make([]string, len(choices))
+	for i, choice := range choices {
+		finishReasons[i] = string(choice.FinishReason)
+		addGenAIChoiceEvent(span, choice.Index, finishReasons[i], choice.Text)
+	}
+	span.SetAttributes(genAIResponseAttributes(response.ID, response.Model, fit.PromptTokens, completionLen, finishReasons...)...)
+
 	return response, nil
 }
 
+// generateCompletionChoices generates the choices of a legacy completion and the number of
+// completion tokens billed for them. best_of candidates are generated and billed, and the n best
+// are returned; with echo set, the prompt is prepended to each returned choice. The text of each
+// candidate is cut to the tokens left in the context window when fit is truncated.
+func generateCompletionChoices(ctx context.Context, req *api.CreateCompletionRequest, prompt string, fit contextFit) ([]api.CompletionChoice, int, error) {
+	span := trace.SpanFromContext(ctx)
+
+	if err := validateLogitBias(req.LogitBias.Value); err != nil {
+		return nil, 0, err
+	}
+	n, bestOf, err := resolveCompletionCounts(req.N, req.BestOf)
+	if err != nil {
+		return nil, 0, err
+	}
+	suffix := ""
+	if req.Suffix.Set && !req.Suffix.Null {
+		suffix = req.Suffix.Value
+	}
+
+	scenario, _ := scenarioFromContext(ctx)
+	finishReason := api.CompletionChoiceFinishReasonStop
+	if scenario.FinishReason != "" {
+		finishReason = api.CompletionChoiceFinishReason(scenario.FinishReason)
+	}
+
+	candidates := make([]completionCandidate, bestOf)
+	completionLen := 0
+	bannedTokens := 0
+	for i := range candidates {
+		text := generateAssistantText(ctx, req.Model, prompt, i, req.PresencePenalty.Value, req.FrequencyPenalty.Value)
+		if scenario.Content != nil {
+			text = *scenario.Content
+		} else if suffix != "" {
+			if req.Model != LoremModelName {
+				text = fillInMiddleMarker(prompt, suffix)
+			}
+			text = fitBetween(prompt, text, suffix)
+		}
+		text, removed := stripBannedTokens(text, req.LogitBias.Value)
+		text, cut := fit.limit(text)
+		candidates[i] = completionCandidate{Text: text, Score: completionCandidateScore(text, i), Truncated: cut}
+		completionLen += countTokens(text)
+		bannedTokens += removed
+	}
+	span.SetAttributes(attribute.Int("logit_bias.removed_tokens", bannedTokens))
+
+	choices := make([]api.CompletionChoice, n)
+	for i, candidate := range bestCompletionCandidates(candidates, n) {
+		text := candidate.Text
+		if req.Echo.Value {
+			text = prompt + text
+		}
+		choices[i] = api.CompletionChoice{
+			Index:        i,
+			Text:         text,
+			FinishReason: finishReason,
+		}
+		if candidate.Truncated && scenario.FinishReason == "" {
+			choices[i].FinishReason = api.CompletionChoiceFinishReasonLength
+		}
+	}
+	return choices, completionLen, nil
+}
+
 // ListModels implements listModels operation.
 func (h *MockHandler) ListModels(ctx context.Context) (*api.ListModelsResponse, error) {
 	_, span := tracer.Start(ctx, "ListModels.process")
 	defer span.End()
 
+	models := h.models.List()
+	data := make([]api.Model, 0, len(models))
+	for _, m := range models {
+		data = append(data, api.Model{
+			ID:      m.ID,
+			Object:  api.ModelObjectModel,
+			Created: time.Now().Unix(),
+			OwnedBy: "openai-mokku",
+		})
+	}
 	return &api.ListModelsResponse{
 		Object: api.ListModelsResponseObjectList,
-		Data: []api.Model{
-			{
-				ID:      "mokku-echo-1",
-				Object:  api.ModelObjectModel,
-				Created: time.Now().Unix(),
-				OwnedBy: "openai-mokku",
-			},
-			{
-				ID:      "gpt-4o",
-				Object:  api.ModelObjectModel,
-				Created: time.Now().Unix(),
-				OwnedBy: "openai-mokku",
-			},
-			{
-				ID:      "gpt-4o-mini",
-				Object:  api.ModelObjectModel,
-				Created: time.Now().Unix(),
-				OwnedBy: "openai-mokku",
-			},
-		},
+		Data:   data,
 	}, nil
 }
 
+// validateModel rejects models that GET /v1/models does not list (magic models excepted) when
+// the strict_model_validation flag is enabled or the endpoint is strict, never when it is lenient.
+func validateModel(ctx context.Context, flags *featureFlags, models *modelCatalog, model string) error {
+	switch fidelityFromContext(ctx) {
+	case fidelityLenient:
+		return nil
+	case fidelityStrict:
+	default:
+		if !flags.Enabled(flagStrictModelValidation) {
+			return nil
+		}
+	}
+	if _, ok := models.Get(model); ok || slices.Contains(magicModels, model) {
+		return nil
+	}
+	return &APIError{
+		StatusCode: http.StatusNotFound,
+		Detail: OpenAIErrorDetail{
+			Message: fmt.Sprintf("The model `%s` does not exist or you do not have access to it.", model),
+			Type:    "invalid_request_error",
+			Code:    "model_not_found",
+		},
+	}
+}
+
+// chatMaxTokens returns the completion token limit of a chat request, preferring
+// max_completion_tokens over the deprecated max_tokens. Zero means unset.
+func chatMaxTokens(req *api.CreateChatCompletionRequest) int {
+	if req.MaxCompletionTokens.Set {
+		return req.MaxCompletionTokens.Value
+	}
+	return req.MaxTokens.Value
+}
+
 // RetrieveModel implements retrieveModel operation.
 func (h *MockHandler) RetrieveModel(ctx context.Context, params api.RetrieveModelParams) (*api.Model, error) {
 	_, span := tracer.Start(ctx, "RetrieveModel.process")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("model", params.Model))
+	span.SetAttributes(semconv.GenAIRequestModel(params.Model))
+
+	if err := validateModel(ctx, h.flags, h.models, params.Model); err != nil {
+		return nil, err
+	}
 
 	return &api.Model{
 		ID:      params.Model,
@@ -287,14 +421,33 @@ func (h *MockHandler) CreateResponse(ctx context.Context, req *api.CreateRespons
 	ctx, span := tracer.Start(ctx, "CreateResponse.process")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.full_json", marshalJSON(req)))
+	setSpanBody(span, "request.full_json", req)
+	span.SetAttributes(genAIRequestAttributes(semconv.GenAIOperationNameChat, req.Model)...)
+	addGenAIPromptEvent(span, req.Input)
 
 	var output []api.ResponseOutputItem
 	var outputText string
 
-	if len(req.Tools) > 0 {
-		// Return function_call output when tools are present
-		tool := req.Tools[0]
+	functionTool := -1
+	var webSearch, fileSearch bool
+	var vectorStoreIDs []string
+	for i, tool := range req.Tools {
+		switch tool.Type {
+		case api.ResponseToolTypeFunction:
+			if functionTool < 0 {
+				functionTool = i
+			}
+		case api.ResponseToolTypeWebSearch, api.ResponseToolTypeWebSearchPreview:
+			webSearch = true
+		case api.ResponseToolTypeFileSearch:
+			fileSearch = true
+			vectorStoreIDs = append(vectorStoreIDs, tool.VectorStoreIds...)
+		}
+	}
+
+	if functionTool >= 0 {
+		// Return function_call output when function tools are present
+		tool := req.Tools[functionTool]
 		argsMap := map[string]string{"input": req.Input}
 		argsBytes, _ := json.Marshal(argsMap)
 		args := string(argsBytes)
@@ -304,20 +457,33 @@ func (h *MockHandler) CreateResponse(ctx context.Context, req *api.CreateRespons
 				Type:      api.ResponseOutputItemTypeFunctionCall,
 				ID:        api.NewOptString("call_" + uuid.New().String()),
 				CallID:    api.NewOptString("call_" + uuid.New().String()),
-				Name:      api.NewOptString(tool.Name),
+				Name:      tool.Name,
 				Arguments: api.NewOptString(args),
 			},
 		}
 	} else {
 		var msgText string
-		if req.Text.Set &&
+		jsonMode := req.Text.Set &&
 			req.Text.Value.Format.Set &&
 			(req.Text.Value.Format.Value.Type == api.ResponseTextFormatTypeJSONSchema ||
-				req.Text.Value.Format.Value.Type == api.ResponseTextFormatTypeJSONObject) {
+				req.Text.Value.Format.Value.Type == api.ResponseTextFormatTypeJSONObject)
+		if jsonMode {
 			msgText = generateJSONFromSchemaBytes(req.Text.Value.Format.Value.Schema)
 		} else {
 			msgText = generateEchoResponse(ctx, req.Input)
 		}
+		// Hosted search tools cite their sources as output_text annotations; structured
+		// output is left intact so it still parses.
+		annotations := []api.ResponseOutputAnnotation{}
+		if webSearch && !jsonMode {
+			var citations []urlCitation
+			msgText, citations = citeWebSources(msgText)
+			annotations = append(annotations, responseURLAnnotations(citations)...)
+		}
+		if fileSearch && !jsonMode {
+			annotations = append(annotations, responseFileAnnotations(citeFiles(msgText, vectorStoreIDs))...)
+		}
+		span.SetAttributes(attribute.Int("annotations", len(annotations)))
 		outputText = msgText
 		output = []api.ResponseOutputItem{
 			{
@@ -326,8 +492,9 @@ func (h *MockHandler) CreateResponse(ctx context.Context, req *api.CreateRespons
 				Role: api.NewOptString("assistant"),
 				Content: []api.ResponseOutputContent{
 					{
-						Type: api.ResponseOutputContentTypeOutputText,
-						Text: msgText,
+						Type:        api.ResponseOutputContentTypeOutputText,
+						Text:        msgText,
+						Annotations: annotations,
 					},
 				},
 			},
@@ -346,9 +513,14 @@ func (h *MockHandler) CreateResponse(ctx context.Context, req *api.CreateRespons
 			OutputTokens: len(outputText),
 			TotalTokens:  len(req.Input) + len(outputText),
 		},
+		// The end-user identifiers are echoed back, as the real API does
+		User:             req.User,
+		SafetyIdentifier: req.SafetyIdentifier,
 	}
 
-	span.SetAttributes(attribute.String("response.full_json", marshalJSON(response)))
+	addGenAIChoiceEvent(span, 0, "stop", outputText)
+	span.SetAttributes(genAIResponseAttributes(response.ID, response.Model, response.Usage.InputTokens, response.Usage.OutputTokens)...)
+	setSpanBody(span, "response.full_json", response)
 
 	return response, nil
 }
@@ -358,7 +530,8 @@ func (h *MockHandler) CreateEmbedding(ctx context.Context, req *api.CreateEmbedd
 	_, span := tracer.Start(ctx, "CreateEmbedding.process")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.full_json", marshalJSON(req)))
+	setSpanBody(span, "request.full_json", req)
+	span.SetAttributes(genAIRequestAttributes(semconv.GenAIOperationNameEmbeddings, req.Model)...)
 
 	inputs := normalizeInputStrings(req.Input)
 
@@ -371,12 +544,13 @@ func (h *MockHandler) CreateEmbedding(ctx context.Context, req *api.CreateEmbedd
 	totalTokens := 0
 	for i, text := range inputs {
 		vector := generateVector(text, dimensions)
+		h.embeddings.Add(req.Model, text, vector)
 		embeddings[i] = api.Embedding{
 			Index:     i,
 			Object:    api.EmbeddingObjectEmbedding,
 			Embedding: vector,
 		}
-		totalTokens += len(text)
+		totalTokens += countTokens(text)
 	}
 
 	response := &api.CreateEmbeddingResponse{
@@ -389,20 +563,120 @@ func (h *MockHandler) CreateEmbedding(ctx context.Context, req *api.CreateEmbedd
 		},
 	}
 
-	span.SetAttributes(attribute.String("response.full_json", marshalJSON(response)))
+	span.SetAttributes(semconv.GenAIEmbeddingsDimensionCount(dimensions), semconv.GenAIResponseModel(req.Model), semconv.GenAIUsageInputTokens(totalTokens))
+	setSpanBody(span, "response.full_json", response)
+
+	return response, nil
+}
+
+// CreateImage implements createImage operation.
+func (h *MockHandler) CreateImage(ctx context.Context, req *api.CreateImageRequest) (*api.ImagesResponse, error) {
+	ctx, span := tracer.Start(ctx, "CreateImage.process")
+	defer span.End()
+
+	setSpanBody(span, "request.full_json", req)
+
+	model := req.Model.Or("dall-e-2")
+	n := req.N.Or(1)
+	size := string(req.Size.Or(api.CreateImageRequestSize1024x1024))
+	responseFormat := req.ResponseFormat.Or(api.CreateImageRequestResponseFormatURL)
+
+	// GPT image models always return b64_json and support background/output_format
+	gptImage := isGPTImageModel(model)
+	quality := req.Quality.Value
+	background := string(req.Background.Or(api.CreateImageRequestBackgroundAuto))
+	outputFormat := "png"
+	if gptImage {
+		outputFormat = string(req.OutputFormat.Or(api.CreateImageRequestOutputFormatPNG))
+		var err error
+		quality, size, background, err = resolveGPTImageOptions(quality, size, background, outputFormat)
+		if err != nil {
+			return nil, err
+		}
+		responseFormat = api.CreateImageRequestResponseFormatB64JSON
+	}
+
+	width, height, ok := parseImageSize(size)
+	if !ok {
+		return nil, newInvalidRequestError("size", fmt.Sprintf("Invalid value: '%s'.", size))
+	}
+
+	span.SetAttributes(genAIRequestAttributes(semconv.GenAIOperationNameGenerateContent, model)...)
+	span.SetAttributes(
+		semconv.GenAIOutputTypeImage,
+		semconv.GenAIRequestChoiceCount(n),
+		attribute.Int("width", width),
+		attribute.Int("height", height),
+		attribute.String("response_format", string(responseFormat)),
+	)
+
+	addGenAIPromptEvent(span, req.Prompt)
+
+	transparent := gptImage && background == "transparent"
+	data := make([]api.Image, n)
+	for i := range data {
+		encoded := encodeImage(renderImage(req.Prompt, i, width, height, transparent), outputFormat)
+		if responseFormat == api.CreateImageRequestResponseFormatB64JSON {
+			data[i].B64JSON = api.NewOptString(base64.StdEncoding.EncodeToString(encoded))
+		} else {
+			id := "img-" + uuid.New().String()
+			expiresAt := h.images.Put(id, encoded)
+			data[i].URL = api.NewOptString(baseURLFromContext(ctx) + adminPathPrefix + "/images/" + id +
+				"?se=" + url.QueryEscape(expiresAt.UTC().Format(time.RFC3339)))
+		}
+		if model == "dall-e-3" {
+			data[i].RevisedPrompt = api.NewOptString(req.Prompt)
+		}
+	}
+
+	response := &api.ImagesResponse{
+		Created: h.images.clock.Now().Unix(),
+		Data:    data,
+	}
+
+	if gptImage {
+		textTokens := countTokens(req.Prompt)
+		outputTokens := n * gptImageOutputTokens[quality][size]
+		span.SetAttributes(semconv.GenAIUsageInputTokens(textTokens), semconv.GenAIUsageOutputTokens(outputTokens))
+		response.Background = api.NewOptString(background)
+		response.OutputFormat = api.NewOptString(outputFormat)
+		response.Quality = api.NewOptString(quality)
+		response.Size = api.NewOptString(size)
+		response.Usage = api.NewOptImageUsage(api.ImageUsage{
+			InputTokens:  textTokens,
+			OutputTokens: outputTokens,
+			TotalTokens:  textTokens + outputTokens,
+			InputTokensDetails: api.ImageUsageInputTokensDetails{
+				TextTokens:  textTokens,
+				ImageTokens: 0,
+			},
+		})
+	}
 
 	return response, nil
 }
 
+// generateAssistantText generates the variant-th sample of the assistant text for a plain
+// (non-JSON, non-tool) response: penalty-shaped lorem ipsum for LoremModelName and an echo of
+// the message otherwise. Echoes are the same for every variant. The text carries the watermark of
+// the request in content mode.
+func generateAssistantText(ctx context.Context, model, message string, variant int, presencePenalty, frequencyPenalty float64) string {
+	p := personalityFromContext(ctx)
+	if model == LoremModelName {
+		return watermarkText(ctx, p.shape(generateLoremText(message, variant, presencePenalty, frequencyPenalty)))
+	}
+	return watermarkText(ctx, p.shape(generateEchoResponse(ctx, message)))
+}
+
 func generateEchoResponse(ctx context.Context, message string) string {
 	_, span := tracer.Start(ctx, "generateEchoResponse")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("input_message", message))
+	setSpanText(span, "input_message", message)
 
 	echoResponse := fmt.Sprintf("Echo: %s", message)
 
-	span.SetAttributes(attribute.String("echo_response", echoResponse))
+	setSpanText(span, "echo_response", echoResponse)
 
 	return echoResponse
 }
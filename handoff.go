@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// Bytes of the handoff protocol: the old process sends handoffHello with the listener, the new
+// process answers handoffAck once it serves on it.
+const (
+	handoffHello = 'M'
+	handoffAck   = 'K'
+)
+
+// handoffAckTimeout is how long the old process waits for the new one to confirm it took over.
+const handoffAckTimeout = 30 * time.Second
+
+// handoffImage is a stored image in a handoff.
+type handoffImage struct {
+	ID        string    `json:"id"`
+	Data      []byte    `json:"data"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// handoffEmbedding is an embedded input in a handoff.
+type handoffEmbedding struct {
+	Model  string    `json:"model"`
+	Input  string    `json:"input"`
+	Vector []float64 `json:"vector"`
+}
+
+// handoffState is the in-memory state a running process hands to the process replacing it during a
+// binary upgrade, so test suites in progress against a shared deployment keep their stubs, stores,
+// and captured requests. State the new process loads from the config and scenario files itself is
+// left out; so are rate limit usage, alerts, tags, the audit trail, and streams in progress.
+type handoffState struct {
+	// Version is the version of the process handing off.
+	Version string `json:"version"`
+	// Flags are the feature flags set through the admin API.
+	Flags map[string]bool `json:"flags,omitempty"`
+	// Scenarios is the scenario file sent through PUT /_mokku/scenarios, if any.
+	Scenarios string `json:"scenarios,omitempty"`
+	// Regions are the regions set through the admin API.
+	Regions []regionConfig `json:"regions,omitempty"`
+	// Chaos is the chaos profile switched through the admin API ("" for off), if it was, with its
+	// faults if it was defined there.
+	Chaos       *chaosRequest      `json:"chaos,omitempty"`
+	BannedUsers []bannedUser       `json:"banned_users"`
+	ClockOffset time.Duration      `json:"clock_offset"`
+	Images      []handoffImage     `json:"images,omitempty"`
+	Embeddings  []handoffEmbedding `json:"embeddings,omitempty"`
+	Captured    []capturedExchange `json:"captured,omitempty"`
+}
+
+// handoffSnapshot captures the state to hand off to a new process.
+func (s *serverState) handoffSnapshot() handoffState {
+	state := handoffState{
+		Version:     serviceVersion,
+		Flags:       map[string]bool{},
+		Scenarios:   string(s.scenarios.Replaced()),
+		BannedUsers: s.bans.List(),
+		ClockOffset: s.clock.Offset(),
+		Images:      s.images.Snapshot(),
+		Embeddings:  s.embeddings.Snapshot(),
+		Captured:    s.capture.Query(captureFilter{}),
+	}
+	for _, flag := range s.flags.Status() {
+		if flag.Source == flagSourceAdmin {
+			state.Flags[flag.Name] = flag.Enabled
+		}
+	}
+	for _, region := range s.regions.Status() {
+		if region.Source == regionSourceAdmin {
+			state.Regions = append(state.Regions, region.regionConfig)
+		}
+	}
+	if chaos := s.chaos.Status(); chaos.Source == chaosSourceAdmin {
+		state.Chaos = &chaosRequest{Profile: chaos.Active}
+		for _, profile := range chaos.Profiles {
+			if profile.Name == chaos.Active && profile.Defined {
+				state.Chaos.Define = &profile.chaosProfileConfig
+			}
+		}
+	}
+	return state
+}
+
+// restoreHandoff applies the state handed off by a previous process. Parts that no longer apply,
+// such as a chaos profile removed from the config, are skipped with a warning.
+func (s *serverState) restoreHandoff(state handoffState) {
+	warn := func(part string, err error) {
+		log.Printf("Warning: handoff from version %s: skipping %s: %v", state.Version, part, err)
+	}
+	for name, enabled := range state.Flags {
+		if err := s.flags.Set(name, enabled, flagSourceAdmin); err != nil {
+			warn("feature flag "+name, err)
+		}
+	}
+	if state.Scenarios != "" {
+		if err := s.scenarios.Replace([]byte(state.Scenarios)); err != nil {
+			warn("scenarios", err)
+		}
+	}
+	for _, region := range state.Regions {
+		if _, err := s.regions.Set(region); err != nil {
+			warn("region "+region.Name, err)
+		}
+	}
+	if state.Chaos != nil {
+		var err error
+		switch {
+		case state.Chaos.Profile == "":
+			s.chaos.Deactivate()
+		case state.Chaos.Define != nil:
+			_, err = s.chaos.Define(*state.Chaos.Define)
+		default:
+			_, err = s.chaos.Activate(state.Chaos.Profile)
+		}
+		if err != nil {
+			warn("chaos", err)
+		}
+	}
+	if state.BannedUsers != nil {
+		s.bans.Restore(state.BannedUsers)
+	}
+	s.clock.Restore(state.ClockOffset)
+	s.images.Restore(state.Images)
+	s.embeddings.Restore(state.Embeddings)
+	s.capture.Restore(state.Captured)
+}
+
+// summary describes a handoff for the log.
+func (h handoffState) summary() string {
+	return fmt.Sprintf("version %s: %d flags, %d regions, %d banned users, %d images, %d embeddings, %d captured requests",
+		h.Version, len(h.Flags), len(h.Regions), len(h.BannedUsers), len(h.Images), len(h.Embeddings), len(h.Captured))
+}
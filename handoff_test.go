@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// newHandoffTestState creates the state of a process for handoff tests.
+func newHandoffTestState(t *testing.T) *serverState {
+	t.Helper()
+	state, err := newServerState(Config{}, serverOptions{Capture: captureConfig{Size: defaultCaptureSize}})
+	if err != nil {
+		t.Fatalf("newServerState: %v", err)
+	}
+	return state
+}
+
+// --- handoffSnapshot / restoreHandoff ---
+
+func TestHandoff_SnapshotRestoresRuntimeState(t *testing.T) {
+	// Given: a process changed at runtime through the admin API and by API requests
+	old := newHandoffTestState(t)
+	if err := old.flags.Set(flagStickyPersonality, true, flagSourceAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := old.scenarios.Replace([]byte("scenarios:\n  - name: stub\n")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.regions.Set(regionConfig{Name: "eu", Status: 503}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.chaos.Define(chaosProfileConfig{Name: "tui", Latency: "1s"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.bans.Ban("user-1", "abuse"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := old.clock.Advance(60); err != nil {
+		t.Fatal(err)
+	}
+	expiresAt := old.images.Put("img-1", []byte("png"))
+	old.embeddings.Add("text-embedding-3-small", "hello", []float64{1, 0})
+	old.capture.store(capturedExchange{ID: old.capture.nextID.Add(1), capturedRequest: capturedRequest{Method: "POST", Path: "/v1/embeddings"}})
+
+	// When: the snapshot is sent to a new process and restored
+	data, err := json.Marshal(old.handoffSnapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received handoffState
+	if err := json.Unmarshal(data, &received); err != nil {
+		t.Fatal(err)
+	}
+	upgraded := newHandoffTestState(t)
+	upgraded.restoreHandoff(received)
+
+	// Then: every part of the runtime state carries over
+	if !upgraded.flags.Values()[flagStickyPersonality] {
+		t.Error("expected the flag set through the admin API")
+	}
+	if names := upgraded.scenarios.Names(); len(names) != 1 || names[0] != "stub" {
+		t.Errorf("expected the replaced scenarios, got %v", names)
+	}
+	if regions := upgraded.regions.Status(); len(regions) != 1 || regions[0].Name != "eu" || regions[0].Healthy {
+		t.Errorf("expected the eu outage, got %+v", regions)
+	}
+	if chaos := upgraded.chaos.Status(); chaos.Active != "tui" || upgraded.chaos.Draw(1).Latency != time.Second {
+		t.Errorf("expected the defined tui profile, got %q", chaos.Active)
+	}
+	if users := upgraded.bans.List(); len(users) != 1 || users[0].Reason != "abuse" {
+		t.Errorf("expected the ban, got %+v", users)
+	}
+	if offset := upgraded.clock.Offset(); offset != time.Minute {
+		t.Errorf("expected the clock a minute ahead, got %s", offset)
+	}
+	if data, gotExpiry, ok := upgraded.images.Get("img-1"); !ok || string(data) != "png" || !gotExpiry.Equal(expiresAt) {
+		t.Errorf("expected the stored image, got %q %s %t", data, gotExpiry, ok)
+	}
+	if matches := upgraded.embeddings.Search("hello", "", 1); len(matches) != 1 || matches[0].Input != "hello" {
+		t.Errorf("expected the indexed input, got %+v", matches)
+	}
+	if e, ok := upgraded.capture.Get(1); !ok || e.Path != "/v1/embeddings" {
+		t.Errorf("expected the captured request under its ID, got %+v %t", e, ok)
+	}
+	if next := upgraded.capture.nextID.Add(1); next != 2 {
+		t.Errorf("expected capture IDs to continue at 2, got %d", next)
+	}
+}
+
+func TestHandoff_RestoreSkipsStaleParts(t *testing.T) {
+	// Given: a handoff naming a chaos profile and a flag this version does not know
+	state := newHandoffTestState(t)
+
+	// When
+	state.restoreHandoff(handoffState{
+		Version: "0.9.0",
+		Flags:   map[string]bool{"retired_flag": true},
+		Chaos:   &chaosRequest{Profile: "retired-profile"},
+	})
+
+	// Then: the rest of the state is left as loaded
+	if chaos := state.chaos.Status(); chaos.Active != "" {
+		t.Errorf("expected chaos off, got %q", chaos.Active)
+	}
+}
@@ -0,0 +1,138 @@
+//go:build !windows
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"syscall"
+	"time"
+)
+
+// handoff is the listener and state taken over from the process being replaced.
+type handoff struct {
+	Listener net.Listener
+	State    handoffState
+	conn     *net.UnixConn
+}
+
+// dialHandoff connects to the handoff socket of a running process at path and takes over its
+// listener and state. It returns nil when no process listens on the socket, for a fresh start.
+func dialHandoff(path string) (*handoff, error) {
+	conn, err := net.DialUnix("unix", nil, &net.UnixAddr{Name: path, Net: "unix"})
+	if errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	listener, err := receiveListener(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	h := &handoff{Listener: listener, conn: conn}
+	if err := json.NewDecoder(conn).Decode(&h.State); err != nil {
+		_ = listener.Close()
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to read the handed off state: %w", err)
+	}
+	return h, nil
+}
+
+// receiveListener reads handoffHello with the file descriptor of the listener passed along.
+func receiveListener(conn *net.UnixConn) (net.Listener, error) {
+	buf, oob := make([]byte, 1), make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the handed off listener: %w", err)
+	}
+	if n != 1 || buf[0] != handoffHello {
+		return nil, fmt.Errorf("unexpected handoff message %q", buf[:n])
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		return nil, fmt.Errorf("handoff message carries no listener")
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		return nil, fmt.Errorf("handoff message carries no listener")
+	}
+	file := os.NewFile(uintptr(fds[0]), "listener")
+	defer func() { _ = file.Close() }()
+	return net.FileListener(file)
+}
+
+// Confirm tells the old process that this process serves on the listener, so it stops accepting
+// and shuts down once its requests in progress are done.
+func (h *handoff) Confirm() error {
+	defer func() { _ = h.conn.Close() }()
+	_, err := h.conn.Write([]byte{handoffAck})
+	return err
+}
+
+// listenHandoff serves the handoff socket at path. The first process that connects and confirms
+// takes over listener and a snapshot of state; the returned channel is then closed, and the caller
+// shuts down. If the new process fails before confirming, this process keeps serving.
+func listenHandoff(path string, listener net.Listener, state *serverState) (<-chan struct{}, error) {
+	tcp, ok := listener.(*net.TCPListener)
+	if !ok {
+		return nil, fmt.Errorf("cannot hand off a %T", listener)
+	}
+	// A socket left behind by a process that exited without handing off is stale
+	_ = os.Remove(path)
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		return nil, err
+	}
+	// The path belongs to the new process once it took over
+	l.SetUnlinkOnClose(false)
+	done := make(chan struct{})
+	go func() {
+		defer func() { _ = l.Close() }()
+		for {
+			conn, err := l.AcceptUnix()
+			if err != nil {
+				log.Printf("Handoff socket closed: %v", err)
+				return
+			}
+			if err := sendHandoff(conn, tcp, state); err != nil {
+				log.Printf("Handoff failed, continuing to serve: %v", err)
+				continue
+			}
+			close(done)
+			return
+		}
+	}()
+	return done, nil
+}
+
+// sendHandoff passes the listener and a snapshot of the state to a new process and waits for it to
+// confirm it took over.
+func sendHandoff(conn *net.UnixConn, listener *net.TCPListener, state *serverState) error {
+	defer func() { _ = conn.Close() }()
+	file, err := listener.File()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	if _, _, err := conn.WriteMsgUnix([]byte{handoffHello}, syscall.UnixRights(int(file.Fd())), nil); err != nil {
+		return fmt.Errorf("failed to pass the listener: %w", err)
+	}
+	snapshot := state.handoffSnapshot()
+	if err := json.NewEncoder(conn).Encode(snapshot); err != nil {
+		return fmt.Errorf("failed to send the state: %w", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(handoffAckTimeout))
+	ack := make([]byte, 1)
+	if _, err := io.ReadFull(conn, ack); err != nil || ack[0] != handoffAck {
+		return fmt.Errorf("the new process did not confirm the takeover: %v", err)
+	}
+	log.Printf("Handed off to a new process (%s)", snapshot.summary())
+	return nil
+}
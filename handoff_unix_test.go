@@ -0,0 +1,77 @@
+//go:build !windows
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// --- dialHandoff / listenHandoff ---
+
+func TestHandoff_NewProcessTakesOverListener(t *testing.T) {
+	// Given: a running process serving HTTP and the handoff socket, with a banned user
+	old := newHandoffTestState(t)
+	if _, err := old.bans.Ban("user-1", ""); err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "handoff.sock")
+	handedOff, err := listenHandoff(path, listener, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When: a new process connects, restores the state, serves, and confirms
+	h, err := dialHandoff(path)
+	if err != nil || h == nil {
+		t.Fatalf("expected a handoff, got %v %v", h, err)
+	}
+	upgraded := newHandoffTestState(t)
+	upgraded.restoreHandoff(h.State)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "upgraded")
+	})}
+	go func() { _ = srv.Serve(h.Listener) }()
+	defer func() { _ = srv.Close() }()
+	if err := h.Confirm(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: the old process is told to shut down, and the new one serves on the same address
+	select {
+	case <-handedOff:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the old process to hand off")
+	}
+	_ = listener.Close()
+	resp, err := http.Get("http://" + listener.Addr().String())
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "upgraded" {
+		t.Errorf("expected the new process to answer, got %q", body)
+	}
+	if users := upgraded.bans.List(); len(users) != 1 || users[0].ID != "user-1" {
+		t.Errorf("expected the ban to carry over, got %+v", users)
+	}
+}
+
+func TestHandoff_NoRunningProcess(t *testing.T) {
+	// When: nothing listens on the handoff socket
+	h, err := dialHandoff(filepath.Join(t.TempDir(), "handoff.sock"))
+
+	// Then: the process starts fresh
+	if h != nil || err != nil {
+		t.Errorf("expected a fresh start, got %v %v", h, err)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"net"
+)
+
+// errHandoffUnsupported is returned for MOKKU_HANDOFF_SOCKET on Windows, which cannot pass listeners
+// between processes over a Unix socket.
+var errHandoffUnsupported = errors.New("MOKKU_HANDOFF_SOCKET is not supported on Windows")
+
+// handoff is the listener and state taken over from the process being replaced.
+type handoff struct {
+	Listener net.Listener
+	State    handoffState
+}
+
+// dialHandoff is not supported on Windows.
+func dialHandoff(path string) (*handoff, error) {
+	return nil, errHandoffUnsupported
+}
+
+// Confirm is not supported on Windows.
+func (h *handoff) Confirm() error {
+	return errHandoffUnsupported
+}
+
+// listenHandoff is not supported on Windows.
+func listenHandoff(path string, listener net.Listener, state *serverState) (<-chan struct{}, error) {
+	return nil, errHandoffUnsupported
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-faster/yaml"
+)
+
+// errNoHAREntries is returned for a HAR file without API calls that can become scenarios.
+var errNoHAREntries = errors.New("HAR file contains no convertible API calls")
+
+// harFile is an HTTP Archive (HAR 1.2) as exported by browser devtools and proxies. Only the fields
+// needed to rebuild API calls are decoded.
+type harFile struct {
+	Log struct {
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+// harEntry is a recorded request and its response.
+type harEntry struct {
+	Request struct {
+		Method   string `json:"method"`
+		URL      string `json:"url"`
+		PostData *struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+		} `json:"postData"`
+	} `json:"request"`
+	Response struct {
+		Status  int `json:"status"`
+		Content struct {
+			MimeType string `json:"mimeType"`
+			Text     string `json:"text"`
+			Encoding string `json:"encoding"`
+		} `json:"content"`
+	} `json:"response"`
+	Timings struct {
+		// Wait is the time to the first response byte in milliseconds, -1 when unknown.
+		Wait float64 `json:"wait"`
+	} `json:"timings"`
+}
+
+// harOptions configures the conversion of a HAR file.
+type harOptions struct {
+	// Latency keeps the recorded time to first byte as the scenario latency.
+	Latency bool
+}
+
+// runImportHAR implements "openai-mokku import-har" and returns the exit code.
+func runImportHAR(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("import-har", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	latency := fs.Bool("latency", false, "keep the recorded time to first byte as the scenario latency")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "usage: openai-mokku import-har [flags] <flow.har | ->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	in := io.Reader(os.Stdin)
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "import-har: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	scenarios, skipped, err := readHAR(in, harOptions{Latency: *latency})
+	for _, reason := range skipped {
+		_, _ = fmt.Fprintf(stderr, "import-har: skipped %s\n", reason)
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "import-har: %v\n", err)
+		return 1
+	}
+	out := bufio.NewWriter(stdout)
+	if err := yaml.NewEncoder(out).Encode(scenarioFile{Scenarios: scenarios}); err != nil {
+		_, _ = fmt.Fprintf(stderr, "import-har: %v\n", err)
+		return 1
+	}
+	if err := out.Flush(); err != nil {
+		_, _ = fmt.Fprintf(stderr, "import-har: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// readHAR converts the API calls of a HAR file into scenarios, in recording order, and describes the
+// entries it skipped. Entries are API calls when their URL path contains /v1/, so calls through
+// gateways with a path prefix are converted too.
+func readHAR(r io.Reader, opts harOptions) ([]scenarioConfig, []string, error) {
+	var har harFile
+	if err := json.NewDecoder(r).Decode(&har); err != nil {
+		return nil, nil, fmt.Errorf("invalid HAR file: %w", err)
+	}
+	var scenarios []scenarioConfig
+	var skipped []string
+	for i, entry := range har.Log.Entries {
+		cfg, err := harScenario(entry, opts)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("entry %d (%s %s): %v", i, entry.Request.Method, entry.Request.URL, err))
+			continue
+		}
+		if cfg == nil {
+			continue
+		}
+		cfg.Name = fmt.Sprintf("har-%d", i)
+		if cfg.Match.Model != "" {
+			cfg.Name += "-" + cfg.Match.Model
+		}
+		scenarios = append(scenarios, *cfg)
+	}
+	if len(scenarios) == 0 {
+		return nil, skipped, errNoHAREntries
+	}
+	return scenarios, skipped, nil
+}
+
+// harScenario converts an entry into a scenario matching its model, path, and last user message. It
+// returns nil for entries that are not API calls, such as page loads, and an error for API calls
+// that cannot become a scenario.
+func harScenario(entry harEntry, opts harOptions) (*scenarioConfig, error) {
+	u, err := url.Parse(entry.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	idx := strings.Index(u.Path, "/v1/")
+	if idx < 0 || entry.Request.Method == "OPTIONS" {
+		return nil, nil
+	}
+	if entry.Request.PostData == nil {
+		return nil, fmt.Errorf("no JSON request body")
+	}
+	var doc any
+	if err := json.Unmarshal([]byte(entry.Request.PostData.Text), &doc); err != nil {
+		return nil, fmt.Errorf("request body is not JSON: %w", err)
+	}
+	cfg := &scenarioConfig{Match: scenarioMatchConfig{Path: u.Path[idx:]}}
+	if m, ok := doc.(map[string]any); ok {
+		cfg.Match.Model, _ = m["model"].(string)
+	}
+	if message := scenarioMessage(doc); message != "" {
+		cfg.Match.Message = "^" + regexp.QuoteMeta(message) + "$"
+	}
+	if opts.Latency && entry.Timings.Wait > 0 {
+		cfg.Response.Latency = (time.Duration(entry.Timings.Wait) * time.Millisecond).String()
+	}
+
+	body := entry.Response.Content.Text
+	if entry.Response.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, fmt.Errorf("response body: %w", err)
+		}
+		body = string(decoded)
+	}
+	status := entry.Response.Status
+	switch {
+	case status >= 400 && status <= 599:
+		cfg.Response.Status = status
+		var errBody struct {
+			Error *struct {
+				Message string  `json:"message"`
+				Type    string  `json:"type"`
+				Code    any     `json:"code"`
+				Param   *string `json:"param"`
+			} `json:"error"`
+		}
+		if json.Unmarshal([]byte(body), &errBody) == nil && errBody.Error != nil {
+			cfg.Response.Error = &scenarioErrorConfig{Message: errBody.Error.Message, Type: errBody.Error.Type}
+			if code, ok := errBody.Error.Code.(string); ok {
+				cfg.Response.Error.Code = code
+			}
+			if errBody.Error.Param != nil {
+				cfg.Response.Error.Param = *errBody.Error.Param
+			}
+		}
+		return cfg, nil
+	case status < 200 || status > 299:
+		return nil, fmt.Errorf("status %d cannot be a scenario response", status)
+	}
+
+	var content, finishReason string
+	if strings.HasPrefix(entry.Response.Content.MimeType, "text/event-stream") || strings.HasPrefix(strings.TrimSpace(body), "data:") {
+		content, finishReason, err = harStreamContent(body)
+	} else {
+		content, finishReason, err = harJSONContent(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Content is a template; recorded text containing template delimiters must be kept literally
+	content = strings.ReplaceAll(content, "{{", `{{"{{"}}`)
+	cfg.Response.Content = &content
+	if scenarioFinishReasons[finishReason] {
+		cfg.Response.FinishReason = finishReason
+	}
+	return cfg, nil
+}
+
+// harChoice is the part of a chat or legacy completion choice (or stream chunk choice) the content
+// is rebuilt from.
+type harChoice struct {
+	Index   int `json:"index"`
+	Message *struct {
+		Content *string `json:"content"`
+	} `json:"message"`
+	Delta *struct {
+		Content *string `json:"content"`
+	} `json:"delta"`
+	Text         *string `json:"text"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// harJSONContent returns the content and finish reason of the first choice of a non-streaming
+// chat or legacy completion response.
+func harJSONContent(body string) (string, string, error) {
+	var resp struct {
+		Choices []harChoice `json:"choices"`
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil || len(resp.Choices) == 0 {
+		return "", "", fmt.Errorf("response is not a chat or completion response")
+	}
+	choice := resp.Choices[0]
+	var content string
+	switch {
+	case choice.Message != nil && choice.Message.Content != nil:
+		content = *choice.Message.Content
+	case choice.Text != nil:
+		content = *choice.Text
+	default:
+		return "", "", fmt.Errorf("response has no text content")
+	}
+	var finishReason string
+	if choice.FinishReason != nil {
+		finishReason = *choice.FinishReason
+	}
+	return content, finishReason, nil
+}
+
+// harStreamContent reassembles the content and finish reason of the first choice from the
+// server-sent events of a streamed chat or legacy completion response.
+func harStreamContent(body string) (string, string, error) {
+	var content strings.Builder
+	var finishReason string
+	chunks := 0
+	for line := range strings.Lines(body) {
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:")
+		data = strings.TrimSpace(data)
+		if !ok || data == "" || data == "[DONE]" {
+			continue
+		}
+		var chunk struct {
+			Choices []harChoice `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return "", "", fmt.Errorf("stream chunk is not JSON: %w", err)
+		}
+		chunks++
+		for _, choice := range chunk.Choices {
+			if choice.Index != 0 {
+				continue
+			}
+			switch {
+			case choice.Delta != nil && choice.Delta.Content != nil:
+				content.WriteString(*choice.Delta.Content)
+			case choice.Text != nil:
+				content.WriteString(*choice.Text)
+			}
+			if choice.FinishReason != nil {
+				finishReason = *choice.FinishReason
+			}
+		}
+	}
+	if chunks == 0 {
+		return "", "", fmt.Errorf("stream has no chunks")
+	}
+	return content.String(), finishReason, nil
+}
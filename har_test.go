@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHAR records a streamed chat completion, a quota error, a non-streaming legacy completion
+// through a gateway prefix, and a page load.
+const testHAR = `{"log": {"entries": [
+  {"request": {"method": "POST", "url": "https://api.openai.com/v1/chat/completions",
+    "postData": {"mimeType": "application/json", "text": "{\"model\":\"gpt-4o\",\"stream\":true,\"messages\":[{\"role\":\"user\",\"content\":\"weather in Oslo?\"}]}"}},
+   "response": {"status": 200, "content": {"mimeType": "text/event-stream",
+    "text": "data: {\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"\"}}]}\n\ndata: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Sunny {{x}}\"}}]}\n\ndata: {\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"length\"}]}\n\ndata: [DONE]\n\n"}},
+   "timings": {"wait": 120}},
+  {"request": {"method": "POST", "url": "https://api.openai.com/v1/embeddings",
+    "postData": {"mimeType": "application/json", "text": "{\"model\":\"text-embedding-3-small\",\"input\":\"hi\"}"}},
+   "response": {"status": 429, "content": {"mimeType": "application/json",
+    "text": "{\"error\":{\"message\":\"You exceeded your current quota.\",\"type\":\"insufficient_quota\",\"code\":\"insufficient_quota\",\"param\":null}}"}},
+   "timings": {"wait": 5}},
+  {"request": {"method": "POST", "url": "https://gateway.example.com/openai/v1/completions",
+    "postData": {"mimeType": "application/json", "text": "{\"model\":\"gpt-3.5-turbo-instruct\",\"prompt\":\"Say hi\"}"}},
+   "response": {"status": 200, "content": {"mimeType": "application/json", "encoding": "base64",
+    "text": "eyJjaG9pY2VzIjpbeyJpbmRleCI6MCwidGV4dCI6ImhpIiwiZmluaXNoX3JlYXNvbiI6InN0b3AifV19"}},
+   "timings": {"wait": -1}},
+  {"request": {"method": "GET", "url": "https://example.com/index.html"},
+   "response": {"status": 200, "content": {"mimeType": "text/html", "text": "<html></html>"}}}
+]}}`
+
+// --- readHAR ---
+
+func TestReadHAR_ConvertsAPICalls(t *testing.T) {
+	// When
+	scenarios, skipped, err := readHAR(strings.NewReader(testHAR), harOptions{Latency: true})
+
+	// Then: the page load is ignored and every API call becomes a scenario in order
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skipped) != 0 || len(scenarios) != 3 {
+		t.Fatalf("expected 3 scenarios and nothing skipped, got %+v %v", scenarios, skipped)
+	}
+	stream := scenarios[0]
+	if stream.Name != "har-0-gpt-4o" || stream.Match.Model != "gpt-4o" || stream.Match.Path != "/v1/chat/completions" ||
+		stream.Match.Message != `^weather in Oslo\?$` {
+		t.Errorf("unexpected stream match: %+v", stream)
+	}
+	if stream.Response.Content == nil || *stream.Response.Content != `Sunny {{"{{"}}x}}` ||
+		stream.Response.FinishReason != "length" || stream.Response.Latency != "120ms" {
+		t.Errorf("unexpected stream response: %+v", stream.Response)
+	}
+	quota := scenarios[1].Response
+	if quota.Status != 429 || quota.Error == nil || quota.Error.Type != "insufficient_quota" || quota.Error.Code != "insufficient_quota" {
+		t.Errorf("unexpected error response: %+v", quota)
+	}
+	legacy := scenarios[2]
+	if legacy.Match.Path != "/v1/completions" || *legacy.Response.Content != "hi" || legacy.Response.FinishReason != "stop" ||
+		legacy.Response.Latency != "" {
+		t.Errorf("unexpected legacy completion: %+v", legacy)
+	}
+
+	// Then: the scenarios compile, and the content template renders the recorded text literally
+	rules, err := compileScenarios(scenarios)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := rules[0].content.Execute(&out, scenarioData{}); err != nil || out.String() != "Sunny {{x}}" {
+		t.Errorf("expected the recorded content, got %q (%v)", out.String(), err)
+	}
+}
+
+func TestReadHAR_SkipsUnconvertibleCalls(t *testing.T) {
+	// Given: an API call without a body and a redirect
+	har := `{"log": {"entries": [
+	  {"request": {"method": "GET", "url": "https://api.openai.com/v1/models"}, "response": {"status": 200}},
+	  {"request": {"method": "POST", "url": "https://api.openai.com/v1/embeddings", "postData": {"text": "{}"}}, "response": {"status": 302}}
+	]}}`
+
+	// When
+	_, skipped, err := readHAR(strings.NewReader(har), harOptions{})
+
+	// Then
+	if !errors.Is(err, errNoHAREntries) || len(skipped) != 2 {
+		t.Errorf("expected both entries skipped, got %v %v", skipped, err)
+	}
+}
+
+// --- runImportHAR ---
+
+func TestRunImportHAR_WritesScenarioFile(t *testing.T) {
+	// Given
+	path := filepath.Join(t.TempDir(), "flow.har")
+	if err := os.WriteFile(path, []byte(testHAR), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	var stdout, stderr bytes.Buffer
+	code := runImportHAR([]string{"-latency", path}, &stdout, &stderr)
+
+	// Then: the output is a scenario file without empty fields
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d: %s", code, stderr.String())
+	}
+	rules, err := parseScenarios(stdout.Bytes())
+	if err != nil || len(rules) != 3 || strings.Contains(stdout.String(), "script") {
+		t.Fatalf("expected 3 rules without empty fields, got %d (%v):\n%s", len(rules), err, stdout.String())
+	}
+	if rules[0].latency != 120*time.Millisecond {
+		t.Errorf("expected the recorded latency, got %s", rules[0].latency)
+	}
+}
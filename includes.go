@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-faster/yaml"
+)
+
+// envVar selects the per-environment overlay of config and scenario files: with MOKKU_ENV=staging,
+// mokku.yml is overlaid by mokku.staging.yml when that file exists.
+const envVar = "MOKKU_ENV"
+
+// fragment is one file of a config or scenario file and the files it includes.
+type fragment struct {
+	path string
+	data []byte
+}
+
+// includeList is the include key shared by config and scenario files.
+type includeList struct {
+	Include []string `yaml:"include" json:"include"`
+}
+
+// loadFragments reads the file at path with the files it includes and their overlays for env,
+// ordered from lowest to highest precedence: the included files in order (glob matches sorted by
+// name, skipping overlays), the file itself, then its overlay. Includes are relative to the including
+// file and every file is read once, so includes cannot loop.
+func loadFragments(path, env string) ([]fragment, error) {
+	var fragments []fragment
+	loaded := map[string]bool{}
+	if err := appendFragments(&fragments, loaded, path, env, false); err != nil {
+		return nil, err
+	}
+	return fragments, nil
+}
+
+// appendFragments appends the fragments of the file at path. A missing overlay is skipped.
+func appendFragments(fragments *[]fragment, loaded map[string]bool, path, env string, overlay bool) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if loaded[abs] {
+		return nil
+	}
+	loaded[abs] = true
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if overlay && os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var list includeList
+	if err := yaml.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, pattern := range list.Include {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: include %q: %w", path, pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return fmt.Errorf("%s: include %q: no such file", path, pattern)
+		}
+		for _, match := range withoutOverlays(matches) {
+			if err := appendFragments(fragments, loaded, match, env, false); err != nil {
+				return err
+			}
+		}
+	}
+	*fragments = append(*fragments, fragment{path: path, data: data})
+	if env != "" && !overlay {
+		return appendFragments(fragments, loaded, overlayPath(path, env), env, true)
+	}
+	return nil
+}
+
+// withoutOverlays returns the paths that are not overlays of other paths, such as a.staging.yml next
+// to a.yml; overlays are loaded with the file they overlay.
+func withoutOverlays(paths []string) []string {
+	set := map[string]bool{}
+	for _, path := range paths {
+		set[path] = true
+	}
+	var files []string
+	for _, path := range paths {
+		ext := filepath.Ext(path)
+		stem := strings.TrimSuffix(path, ext)
+		if dot := strings.LastIndex(stem, "."); dot > len(filepath.Dir(path)) && set[stem[:dot]+ext] {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files
+}
+
+// overlayPath returns the overlay of the file at path for env: dir/name.env.ext.
+func overlayPath(path, env string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env + ext
+}
+
+// mergeDocs merges the decoded document src into dst: objects are merged key by key, and src wins
+// for other values. Lists are concatenated, except that an item with the name (or id) of an item
+// already in dst is merged into that item.
+func mergeDocs(dst, src map[string]any) {
+	for key, value := range src {
+		dst[key] = mergeValue(dst[key], value)
+	}
+}
+
+// mergeValue returns src merged into dst (see mergeDocs).
+func mergeValue(dst, src any) any {
+	switch s := src.(type) {
+	case map[string]any:
+		d, ok := dst.(map[string]any)
+		if !ok {
+			return s
+		}
+		mergeDocs(d, s)
+		return d
+	case []any:
+		d, ok := dst.([]any)
+		if !ok {
+			return s
+		}
+		for _, item := range s {
+			if i := indexOfNamed(d, item); i >= 0 {
+				d[i] = mergeValue(d[i], item)
+			} else {
+				d = append(d, item)
+			}
+		}
+		return d
+	}
+	return src
+}
+
+// indexOfNamed returns the index of the item of list with the name (or id) of item, or -1.
+func indexOfNamed(list []any, item any) int {
+	id := itemName(item)
+	if id == "" {
+		return -1
+	}
+	for i, other := range list {
+		if itemName(other) == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// itemName returns the name or id of a list item, or the empty string.
+func itemName(item any) string {
+	m, ok := item.(map[string]any)
+	if !ok {
+		return ""
+	}
+	for _, key := range []string{"name", "id"} {
+		if s, ok := m[key].(string); ok && s != "" {
+			return key + "=" + s
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-faster/yaml"
+)
+
+// writeFiles writes files relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fragmentNames returns the paths of fragments relative to dir.
+func fragmentNames(t *testing.T, dir string, fragments []fragment) []string {
+	t.Helper()
+	var names []string
+	for _, f := range fragments {
+		name, err := filepath.Rel(dir, f.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, filepath.ToSlash(name))
+	}
+	return names
+}
+
+// --- loadFragments ---
+
+func TestLoadFragments_OrdersIncludesFileAndOverlays(t *testing.T) {
+	// Given: a root including team files by glob, with staging overlays for the root and one team
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"mokku.yml":                   "include: [teams/*.yml, shared.yml]\n",
+		"mokku.staging.yml":           "{}\n",
+		"shared.yml":                  "include: [teams/b.yml]\n",
+		"teams/b.yml":                 "{}\n",
+		"teams/a.yml":                 "{}\n",
+		"teams/a.staging.yml":         "{}\n",
+		"teams/a.production.yml":      "{}\n",
+		"teams/unrelated/ignored.yml": "{}\n",
+	})
+
+	// When
+	fragments, err := loadFragments(filepath.Join(dir, "mokku.yml"), "staging")
+
+	// Then: includes come first in glob order, each file once, followed by its overlay
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"teams/a.yml", "teams/a.staging.yml", "teams/b.yml", "shared.yml", "mokku.yml", "mokku.staging.yml"}
+	if got := fragmentNames(t, dir, fragments); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestLoadFragments_Errors(t *testing.T) {
+	// Given
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"missing.yml": "include: [nope.yml]\n",
+		"empty.yml":   "include: [teams/*.yml]\n",
+		"bad.yml":     "include: 3\n",
+		"loop.yml":    "include: [loop.yml]\n",
+	})
+
+	// When / Then: a missing literal include and a malformed file fail, an empty glob and a loop do not
+	if _, err := loadFragments(filepath.Join(dir, "missing.yml"), ""); err == nil || !strings.Contains(err.Error(), "no such file") {
+		t.Errorf("expected a missing include error, got %v", err)
+	}
+	if _, err := loadFragments(filepath.Join(dir, "bad.yml"), ""); err == nil {
+		t.Error("expected a parse error")
+	}
+	for _, name := range []string{"empty.yml", "loop.yml"} {
+		if fragments, err := loadFragments(filepath.Join(dir, name), ""); err != nil || len(fragments) != 1 {
+			t.Errorf("%s: expected only the file, got %d fragments, %v", name, len(fragments), err)
+		}
+	}
+}
+
+// --- mergeDocs ---
+
+func TestMergeDocs_MergesObjectsAndNamedItems(t *testing.T) {
+	// Given
+	decode := func(s string) map[string]any {
+		doc := map[string]any{}
+		if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+			t.Fatal(err)
+		}
+		return doc
+	}
+	dst := decode(`
+features: {a: true, b: true}
+moderation: {banned_phrases: [x]}
+regions: [{name: eu, status: 503, latency: 1s}]
+models: [{id: m1, context_window: 10}]
+`)
+	src := decode(`
+features: {b: false}
+moderation: {banned_phrases: [y]}
+regions: [{name: eu, status: 500}, {name: us}]
+models: [{id: m1, context_window: 20}, {id: m2}]
+`)
+
+	// When
+	mergeDocs(dst, src)
+
+	// Then
+	want := decode(`
+features: {a: true, b: false}
+moderation: {banned_phrases: [x, y]}
+regions: [{name: eu, status: 500, latency: 1s}, {name: us}]
+models: [{id: m1, context_window: 20}, {id: m2}]
+`)
+	if !reflect.DeepEqual(dst, want) {
+		t.Errorf("expected %v, got %v", want, dst)
+	}
+}
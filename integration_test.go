@@ -1,12 +1,24 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"openai-mokku/api"
 )
@@ -14,12 +26,58 @@ import (
 // newTestServer creates a test HTTP server using MockHandler + StreamingHandler.
 func newTestServer(t *testing.T) *httptest.Server {
 	t.Helper()
-	handler := &MockHandler{}
-	ogenServer, err := api.NewServer(handler, api.WithPathPrefix("/v1"))
+	return newTestServerWithConfig(t, Config{})
+}
+
+// newTestServerWithConfig creates a test HTTP server like main.go does for the given config.
+func newTestServerWithConfig(t *testing.T, cfg Config) *httptest.Server {
+	t.Helper()
+	return newTestServerWithScenarios(t, cfg, "")
+}
+
+// newTestServerWithScenarios creates a test HTTP server for the given config and MOKKU_SCENARIOS
+// file content (none if empty).
+func newTestServerWithScenarios(t *testing.T, cfg Config, scenarioFile string) *httptest.Server {
+	t.Helper()
+	srv := newUnstartedTestServer(t, cfg, scenarioFile)
+	srv.Start()
+	return srv
+}
+
+// newUnstartedTestServer creates a test server like newTestServerWithScenarios without starting it.
+// Test servers run with the stream_reconciliation flag, and the test fails if a stream differs from
+// the non-streaming response to the same request.
+func newUnstartedTestServer(t *testing.T, cfg Config, scenarioFile string) *httptest.Server {
+	t.Helper()
+	scenariosPath := ""
+	if scenarioFile != "" {
+		scenariosPath = filepath.Join(t.TempDir(), "scenarios.yml")
+		if err := os.WriteFile(scenariosPath, []byte(scenarioFile), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	state, err := newServerState(cfg, serverOptions{
+		ScenariosPath: scenariosPath,
+		Capture:       captureConfig{Size: defaultCaptureSize, StreamPreview: defaultCaptureStreamPreview},
+		Seed:          42,
+		Features:      flagStreamReconciliation,
+	})
+	if err != nil {
+		t.Fatalf("newServerState: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, m := range state.streams.Stats().Mismatches {
+			t.Errorf("stream %s %s differs from the non-streaming response: %v", m.Path, m.ID, m.Differences)
+		}
+	})
+	handler, _, err := state.newHandler("test-instance")
 	if err != nil {
-		t.Fatalf("api.NewServer: %v", err)
+		t.Fatalf("newHandler: %v", err)
 	}
-	return httptest.NewServer(NewStreamingHandler(ogenServer))
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config.ConnContext = state.connections.ConnContext
+	srv.Config.ConnState = state.connections.ConnState
+	return srv
 }
 
 // postJSON sends a POST request with a JSON body and returns the response.
@@ -52,6 +110,71 @@ func getChoices(t *testing.T, result map[string]interface{}) []interface{} {
 	return choices
 }
 
+// readStreamedContent reads an SSE chat completion stream and returns the content deltas in order.
+func readStreamedContent(t *testing.T, r io.Reader) []string {
+	t.Helper()
+	var deltas []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("decode chunk %q: %v", data, err)
+		}
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			deltas = append(deltas, chunk.Choices[0].Delta.Content)
+		}
+	}
+	return deltas
+}
+
+// readStreamedChunks reads an SSE chat completion stream and returns its chunks.
+func readStreamedChunks(t *testing.T, r io.Reader) []ChatCompletionChunk {
+	t.Helper()
+	var chunks []ChatCompletionChunk
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("decode chunk %q: %v", data, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// readStreamedCompletionText reads an SSE legacy completion stream and returns the text deltas
+// of each choice in order, keyed by choice index.
+func readStreamedCompletionText(t *testing.T, r io.Reader) map[int][]string {
+	t.Helper()
+	texts := make(map[int][]string)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk CompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("decode chunk %q: %v", data, err)
+		}
+		for _, c := range chunk.Choices {
+			if c.Text != "" {
+				texts[c.Index] = append(texts[c.Index], c.Text)
+			}
+		}
+	}
+	return texts
+}
+
 // --- Chat Completions ---
 
 func TestIntegration_ChatCompletion_Echo(t *testing.T) {
@@ -282,288 +405,3625 @@ func TestIntegration_ChatCompletion_JSONSchemaPriorityOverTools(t *testing.T) {
 	}
 }
 
-func TestIntegration_ChatCompletion_CreditError(t *testing.T) {
-	// Given: the credit-error model name
+func TestIntegration_ChatCompletion_StreamingEcho(t *testing.T) {
+	// Given: a streaming request without response_format
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"credit-error","messages":[{"role":"user","content":"hi"}]}`
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hello stream"}]}`
 
 	// When
 	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: 402 Payment Required with insufficient_quota error type
-	if resp.StatusCode != http.StatusPaymentRequired {
-		t.Errorf("expected 402, got %d", resp.StatusCode)
-	}
-	result := mustDecodeJSON(t, resp.Body)
-	errObj, ok := result["error"].(map[string]interface{})
-	if !ok {
-		t.Fatal("expected error object in response")
-	}
-	errType, _ := errObj["type"].(string)
-	if errType != "insufficient_quota" {
-		t.Errorf("expected type=insufficient_quota, got %q", errType)
+	// Then: the echo arrives as a single content delta
+	deltas := readStreamedContent(t, resp.Body)
+	if len(deltas) != 1 || !strings.Contains(deltas[0], "hello stream") {
+		t.Errorf("expected a single echo delta, got %q", deltas)
 	}
 }
 
-// --- Responses API ---
-
-func TestIntegration_Responses_PlainText(t *testing.T) {
-	// Given: POST /v1/responses with plain text input
+func TestIntegration_ChatCompletion_WebSearchCitesSources(t *testing.T) {
+	// Given: a request with web_search_options
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"gpt-4o","input":"tell me a joke"}`
+	body := `{"model":"gpt-4o","web_search_options":{"search_context_size":"low"},` +
+		`"messages":[{"role":"user","content":"latest news"}]}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: 200 OK, status=completed, output contains a message item
+	// Then: the message carries url_citation annotations addressing its content
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	for _, field := range []string{"id", "object", "status", "model", "output"} {
-		if result[field] == nil {
-			t.Errorf("expected field %q in response", field)
-		}
-	}
-	if status, _ := result["status"].(string); status != "completed" {
-		t.Errorf("expected status=completed, got %q", status)
+	var result api.CreateChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
 	}
-	output, ok := result["output"].([]interface{})
-	if !ok || len(output) == 0 {
-		t.Fatal("expected non-empty output array")
+	message := result.Choices[0].Message
+	if len(message.Annotations) != 1 {
+		t.Fatalf("expected one annotation, got %+v", message.Annotations)
 	}
-	item := output[0].(map[string]interface{})
-	if itemType, _ := item["type"].(string); itemType != "message" {
-		t.Errorf("expected output[0].type=message, got %q", itemType)
+	citation := message.Annotations[0].URLCitation
+	if span := runeSlice(message.Content.Value, citation.StartIndex, citation.EndIndex); !strings.Contains(span, citation.URL) {
+		t.Errorf("expected the span to link %s, got %q", citation.URL, span)
 	}
 }
 
-func TestIntegration_Responses_FunctionCall(t *testing.T) {
-	// Given: POST /v1/responses with tools
+func TestIntegration_ChatCompletion_StreamingWebSearchSendsAnnotationDelta(t *testing.T) {
+	// Given: a streaming request to a search model
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{
-		"model": "gpt-4o",
-		"input": "get the weather in Tokyo",
-		"tools": [{
-			"type": "function",
-			"name": "get_weather",
-			"description": "Get weather info",
-			"parameters": {"type": "object", "properties": {"city": {"type": "string"}}}
-		}]
-	}`
+	body := `{"model":"gpt-4o-search-preview","stream":true,"messages":[{"role":"user","content":"latest news"}]}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: 200 OK, output contains function_call item
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	// Then: the annotations arrive in a delta after the content
+	var content string
+	var annotations []chatAnnotation
+	for _, chunk := range readStreamedChunks(t, resp.Body) {
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		delta := chunk.Choices[0].Delta
+		if delta.Content != "" && annotations != nil {
+			t.Error("expected content before the annotations")
+		}
+		content += delta.Content
+		annotations = append(annotations, delta.Annotations...)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	output, ok := result["output"].([]interface{})
-	if !ok || len(output) == 0 {
-		t.Fatal("expected non-empty output array")
+	if len(annotations) != 1 || annotations[0].Type != "url_citation" {
+		t.Fatalf("expected one url_citation, got %+v", annotations)
 	}
-	item := output[0].(map[string]interface{})
-	if itemType, _ := item["type"].(string); itemType != "function_call" {
-		t.Errorf("expected output[0].type=function_call, got %q", itemType)
+	if c := annotations[0].URLCitation; !strings.Contains(runeSlice(content, c.StartIndex, c.EndIndex), c.URL) {
+		t.Errorf("expected the citation to address the streamed content, got %+v in %q", c, content)
 	}
 }
 
-func TestIntegration_Responses_StructuredOutput(t *testing.T) {
-	// Given: POST /v1/responses with text.format=json_schema
+func TestIntegration_ChatCompletion_StreamingJSONSchema(t *testing.T) {
+	// Given: a streaming json_schema request
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{
-		"model": "gpt-4o",
-		"input": "give me user data",
-		"text": {
-			"format": {
-				"type": "json_schema",
-				"name": "user",
-				"schema": {
-					"type": "object",
-					"properties": {
-						"username": {"type": "string"},
-						"email":    {"type": "string"}
-					},
-					"required": ["username", "email"]
-				}
-			}
-		}
-	}`
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"give me a user"}],` +
+		`"response_format":{"type":"json_schema","json_schema":{"name":"user","schema":` +
+		`{"type":"object","properties":{"name":{"type":"string"},"age":{"type":"integer"}},"required":["name","age"]}}}}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: 200 OK, output contains message item with valid JSON content matching the schema
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.StatusCode)
-	}
-	result := mustDecodeJSON(t, resp.Body)
-	output, ok := result["output"].([]interface{})
-	if !ok || len(output) == 0 {
-		t.Fatal("expected non-empty output array")
+	// Then: the JSON document is streamed over many deltas and the result is valid JSON
+	deltas := readStreamedContent(t, resp.Body)
+	if len(deltas) < 2 {
+		t.Fatalf("expected JSON to be streamed in multiple deltas, got %q", deltas)
 	}
-	item := output[0].(map[string]interface{})
-	if itemType, _ := item["type"].(string); itemType != "message" {
-		t.Errorf("expected output[0].type=message, got %q", itemType)
-	}
-	content, ok := item["content"].([]interface{})
-	if !ok || len(content) == 0 {
-		t.Fatal("expected non-empty content array")
-	}
-	contentItem := content[0].(map[string]interface{})
-	text, _ := contentItem["text"].(string)
-	var parsed map[string]interface{}
-	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
-		t.Errorf("expected valid JSON content, got %q: %v", text, err)
-	}
-	if _, ok := parsed["username"]; !ok {
-		t.Error("expected 'username' field in JSON response")
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.Join(deltas, "")), &obj); err != nil {
+		t.Fatalf("expected accumulated content to be valid JSON: %v", err)
 	}
-	if _, ok := parsed["email"]; !ok {
-		t.Error("expected 'email' field in JSON response")
+	if _, ok := obj["name"]; !ok {
+		t.Errorf("expected name property, got %v", obj)
 	}
 }
 
-func TestIntegration_Responses_HasUsage(t *testing.T) {
-	// Given: any valid responses request
+func TestIntegration_ChatCompletion_StreamingJSONObject(t *testing.T) {
+	// Given: a streaming json_object request without a schema
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"gpt-4o","input":"hello"}`
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi there"}],` +
+		`"response_format":{"type":"json_object"}}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: usage field is present
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	// Then: the accumulated content is a JSON object wrapping the message
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(strings.Join(readStreamedContent(t, resp.Body), "")), &obj); err != nil {
+		t.Fatalf("expected accumulated content to be valid JSON: %v", err)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	if result["usage"] == nil {
-		t.Error("expected usage field in responses response")
+	if obj["message"] != "hi there" {
+		t.Errorf("expected message=hi there, got %v", obj)
 	}
 }
 
-// --- Embeddings ---
-
-func TestIntegration_Embeddings_BasicStringInput(t *testing.T) {
-	// Given: POST /v1/embeddings with a single string input
+func TestIntegration_ChatCompletion_ToolCallConvention(t *testing.T) {
+	// Given: a message calling two declared tools with the call: convention
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"text-embedding-3-small","input":"hello world"}`
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"call:get_weather {\"city\":\"Tokyo\"}\ncall:get_time"}],` +
+		`"tools":[{"type":"function","function":{"name":"get_weather"}},{"type":"function","function":{"name":"get_time"}}]}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: 200 OK, data[0].embedding is a non-empty float array
+	// Then: both tools are called with the given arguments and content is null
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	if obj, _ := result["object"].(string); obj != "list" {
-		t.Errorf("expected object=list, got %q", obj)
+	choice := getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})
+	if choice["finish_reason"] != "tool_calls" {
+		t.Errorf("expected finish_reason=tool_calls, got %v", choice["finish_reason"])
 	}
-	data, ok := result["data"].([]interface{})
-	if !ok || len(data) == 0 {
-		t.Fatal("expected non-empty data array")
+	message := choice["message"].(map[string]interface{})
+	if content, ok := message["content"]; !ok || content != nil {
+		t.Errorf("expected content null, got %v", content)
 	}
-	item := data[0].(map[string]interface{})
-	if itemObj, _ := item["object"].(string); itemObj != "embedding" {
-		t.Errorf("expected data[0].object=embedding, got %q", itemObj)
+	toolCalls := message["tool_calls"].([]interface{})
+	if len(toolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %v", toolCalls)
 	}
-	embedding, ok := item["embedding"].([]interface{})
-	if !ok || len(embedding) == 0 {
-		t.Error("expected non-empty embedding vector")
+	fn := toolCalls[0].(map[string]interface{})["function"].(map[string]interface{})
+	if fn["name"] != "get_weather" || fn["arguments"] != `{"city":"Tokyo"}` {
+		t.Errorf("unexpected first call %v", fn)
+	}
+	fn = toolCalls[1].(map[string]interface{})["function"].(map[string]interface{})
+	if fn["name"] != "get_time" || fn["arguments"] != `{}` {
+		t.Errorf("unexpected second call %v", fn)
 	}
 }
 
-func TestIntegration_Embeddings_MultipleInputs(t *testing.T) {
-	// Given: a request with three string inputs
+func TestIntegration_ChatCompletion_ToolResultRoundTrip(t *testing.T) {
+	// Given: a conversation replaying the assistant tool call (content null) and its result
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"text-embedding-3-small","input":["hello","world","foo"]}`
+	body := `{"model":"gpt-4o","messages":[` +
+		`{"role":"user","content":"call:get_weather {}"},` +
+		`{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{}"}}]},` +
+		`{"role":"tool","tool_call_id":"call_1","content":"sunny"}],` +
+		`"tools":[{"type":"function","function":{"name":"get_weather"}}]}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: data has exactly 3 embeddings
+	// Then: the model answers with text
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	data, ok := result["data"].([]interface{})
-	if !ok {
-		t.Fatal("expected data array")
+	choice := getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})
+	if choice["finish_reason"] != "stop" {
+		t.Errorf("expected finish_reason=stop, got %v", choice["finish_reason"])
 	}
-	if len(data) != 3 {
-		t.Errorf("expected 3 embeddings, got %d", len(data))
+}
+
+func TestIntegration_ChatCompletion_ToolCallUndeclaredTool(t *testing.T) {
+	// Given: a call: line naming a tool that is not declared
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"call:missing"}],` +
+		`"tools":[{"type":"function","function":{"name":"get_weather"}}]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	detail := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{})
+	if detail["param"] != "messages" {
+		t.Errorf("expected param=messages, got %v", detail["param"])
 	}
 }
 
-func TestIntegration_Embeddings_DimensionsParameter(t *testing.T) {
-	// Given: request with explicit dimensions=256
+func TestIntegration_ChatCompletion_StreamingToolCalls(t *testing.T) {
+	// Given: a streaming request calling a tool
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"text-embedding-3-small","input":"hello","dimensions":256}`
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"call:get_weather {\"city\":\"Tokyo\",\"unit\":\"celsius\"}"}],` +
+		`"tools":[{"type":"function","function":{"name":"get_weather"}}]}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: embedding vector has exactly 256 elements
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	// Then: the first tool call delta carries id and name, later ones argument fragments,
+	// and the stream finishes with tool_calls
+	chunks := readStreamedChunks(t, resp.Body)
+	var deltas []ChatCompletionChunkToolCall
+	for _, chunk := range chunks {
+		if chunk.Choices[0].Delta.Content != "" {
+			t.Errorf("expected no content, got %q", chunk.Choices[0].Delta.Content)
+		}
+		deltas = append(deltas, chunk.Choices[0].Delta.ToolCalls...)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	data, ok := result["data"].([]interface{})
-	if !ok || len(data) == 0 {
-		t.Fatal("expected data array")
+	if len(deltas) < 3 {
+		t.Fatalf("expected a header and several argument fragments, got %+v", deltas)
 	}
-	item := data[0].(map[string]interface{})
-	embedding, ok := item["embedding"].([]interface{})
-	if !ok {
-		t.Fatal("expected embedding field")
+	if !strings.HasPrefix(deltas[0].ID, "call_") || deltas[0].Function.Name != "get_weather" || deltas[0].Type != "function" {
+		t.Errorf("unexpected first delta %+v", deltas[0])
 	}
-	if len(embedding) != 256 {
-		t.Errorf("expected 256 dimensions, got %d", len(embedding))
+	var args strings.Builder
+	for _, d := range deltas[1:] {
+		if d.Index != 0 || d.ID != "" || d.Function.Name != "" {
+			t.Errorf("unexpected fragment %+v", d)
+		}
+		args.WriteString(d.Function.Arguments)
+	}
+	if args.String() != `{"city":"Tokyo","unit":"celsius"}` {
+		t.Errorf("unexpected accumulated arguments %q", args.String())
+	}
+	last := chunks[len(chunks)-1].Choices[0]
+	if last.FinishReason == nil || *last.FinishReason != "tool_calls" {
+		t.Errorf("expected finish_reason=tool_calls, got %v", last.FinishReason)
 	}
 }
 
-func TestIntegration_Embeddings_HasUsage(t *testing.T) {
-	// Given: any valid embeddings request
+func TestIntegration_ChatCompletion_StreamingToolChoiceNone(t *testing.T) {
+	// Given: a streaming request with tools and tool_choice=none
 	srv := newTestServer(t)
 	defer srv.Close()
-	body := `{"model":"text-embedding-3-small","input":"usage test"}`
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hello"}],` +
+		`"tools":[{"type":"function","function":{"name":"get_weather"}}],"tool_choice":"none"}`
 
 	// When
-	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
 	defer func() { _ = resp.Body.Close() }()
 
-	// Then: usage field is present with prompt_tokens and total_tokens
+	// Then: the model answers with text
+	deltas := readStreamedContent(t, resp.Body)
+	if len(deltas) != 1 || !strings.Contains(deltas[0], "hello") {
+		t.Errorf("expected an echo delta, got %q", deltas)
+	}
+}
+
+func TestIntegration_ChatCompletion_LogitBiasBansTokens(t *testing.T) {
+	// Given: a logit_bias banning the " secret" token, looked up via the tokenize endpoint
+	srv := newTestServer(t)
+	defer srv.Close()
+	tokResp := postJSON(t, srv.URL+"/_mokku/tokenize", `{"text":"the secret word"}`)
+	tokens := mustDecodeJSON(t, tokResp.Body)["data"].([]interface{})
+	_ = tokResp.Body.Close()
+	secret := tokens[1].(map[string]interface{})
+	if secret["text"] != " secr" {
+		t.Fatalf("expected second token %q, got %v", " secr", secret)
+	}
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"the secret word"}],` +
+		`"logit_bias":{"` + strconv.Itoa(int(secret["id"].(float64))) + `":-100}}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the banned token is missing from the echoed content
 	if resp.StatusCode != http.StatusOK {
 		t.Fatalf("expected 200, got %d", resp.StatusCode)
 	}
-	result := mustDecodeJSON(t, resp.Body)
-	usage, ok := result["usage"].(map[string]interface{})
-	if !ok {
-		t.Fatal("expected usage object in response")
+	message := getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})["message"].(map[string]interface{})
+	if content := message["content"]; content != "Echo: theet word" {
+		t.Errorf("expected banned token to be stripped, got %q", content)
 	}
-	if usage["prompt_tokens"] == nil {
-		t.Error("expected prompt_tokens in usage")
+}
+
+func TestIntegration_ChatCompletion_LogitBiasInvalidKey(t *testing.T) {
+	// Given: a logit_bias with a non-integer key
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}],"logit_bias":{"hi":1}}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
 	}
-	if usage["total_tokens"] == nil {
+}
+
+func TestIntegration_ChatCompletion_LoremPenaltiesChangeRepetition(t *testing.T) {
+	// Given: the lorem model with and without a presence penalty
+	srv := newTestServer(t)
+	defer srv.Close()
+	contentFor := func(presencePenalty string) string {
+		body := `{"model":"mokku-lorem-1","presence_penalty":` + presencePenalty +
+			`,"messages":[{"role":"user","content":"write something"}]}`
+		resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+		defer func() { _ = resp.Body.Close() }()
+		message := getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})["message"].(map[string]interface{})
+		content, _ := message["content"].(string)
+		return content
+	}
+
+	// When
+	plain := contentFor("0")
+	penalized := contentFor("2")
+
+	// Then: lorem text is generated and the penalty removes repetition
+	if strings.HasPrefix(plain, "Echo:") {
+		t.Fatalf("expected lorem text, got %q", plain)
+	}
+	if distinctWords(penalized) <= distinctWords(plain) {
+		t.Errorf("expected presence_penalty to reduce repetition: %q vs %q", penalized, plain)
+	}
+}
+
+func TestIntegration_ChatCompletion_CreditError(t *testing.T) {
+	// Given: the credit-error model name
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"credit-error","messages":[{"role":"user","content":"hi"}]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 402 Payment Required with insufficient_quota error type
+	if resp.StatusCode != http.StatusPaymentRequired {
+		t.Errorf("expected 402, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	errObj, ok := result["error"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected error object in response")
+	}
+	errType, _ := errObj["type"].(string)
+	if errType != "insufficient_quota" {
+		t.Errorf("expected type=insufficient_quota, got %q", errType)
+	}
+}
+
+func TestIntegration_ChatCompletion_InputAudio(t *testing.T) {
+	// Given: a user message with a 2-second WAV clip
+	srv := newTestServer(t)
+	defer srv.Close()
+	audio := base64.StdEncoding.EncodeToString(makeWAV(32000, 64000))
+	body := `{"model":"gpt-4o-audio-preview","messages":[{"role":"user","content":[` +
+		`{"type":"text","text":"what did I say?"},` +
+		`{"type":"input_audio","input_audio":{"data":"` + audio + `","format":"wav"}}]}]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: audio tokens are reported and the transcript is echoed
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	usage := result["usage"].(map[string]interface{})
+	details, ok := usage["prompt_tokens_details"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected prompt_tokens_details, got %v", usage)
+	}
+	if audioTokens, _ := details["audio_tokens"].(float64); audioTokens != 20 {
+		t.Errorf("expected audio_tokens=20, got %v", details["audio_tokens"])
+	}
+	message := getChoices(t, result)[0].(map[string]interface{})["message"].(map[string]interface{})
+	content, _ := message["content"].(string)
+	if !strings.Contains(content, "what did I say?") || !strings.Contains(content, "[transcript 2.0s]") {
+		t.Errorf("expected text and transcript in content, got %q", content)
+	}
+}
+
+func TestIntegration_ChatCompletion_InputAudioInvalidBase64(t *testing.T) {
+	// Given: input_audio data that is not base64
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o-audio-preview","messages":[{"role":"user","content":[` +
+		`{"type":"input_audio","input_audio":{"data":"%%%","format":"mp3"}}]}]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 400 with an invalid_request_error
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	errObj, _ := result["error"].(map[string]interface{})
+	if errType, _ := errObj["type"].(string); errType != "invalid_request_error" {
+		t.Errorf("expected type=invalid_request_error, got %v", result)
+	}
+}
+
+// --- Completions ---
+
+func TestIntegration_Completions_BestOfAndN(t *testing.T) {
+	// Given: best_of=4 and n=2 on the lorem model, and the same request with a single candidate
+	srv := newTestServer(t)
+	defer srv.Close()
+	complete := func(body string) map[string]interface{} {
+		resp := postJSON(t, srv.URL+"/v1/completions", body)
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		return mustDecodeJSON(t, resp.Body)
+	}
+
+	// When
+	single := complete(`{"model":"mokku-lorem-1","prompt":"once upon a time"}`)
+	multi := complete(`{"model":"mokku-lorem-1","prompt":"once upon a time","n":2,"best_of":4}`)
+
+	// Then: n distinct choices are returned and all best_of candidates are billed
+	choices := getChoices(t, multi)
+	if len(choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(choices))
+	}
+	if choices[0].(map[string]interface{})["text"] == choices[1].(map[string]interface{})["text"] {
+		t.Error("expected distinct candidate texts")
+	}
+	singleTokens := single["usage"].(map[string]interface{})["completion_tokens"].(float64)
+	multiTokens := multi["usage"].(map[string]interface{})["completion_tokens"].(float64)
+	if multiTokens < 3*singleTokens {
+		t.Errorf("expected usage for 4 candidates, got %v vs single %v", multiTokens, singleTokens)
+	}
+}
+
+func TestIntegration_Completions_Suffix(t *testing.T) {
+	// Given: a fill-in-middle request
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-3.5-turbo-instruct","prompt":"def add(a, b):","suffix":"return result"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the completion names the gap and is padded to fit between prompt and suffix
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	text, _ := getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})["text"].(string)
+	want := ` [fill between "def add(a, b):" and "return result"] `
+	if text != want {
+		t.Errorf("expected %q, got %q", want, text)
+	}
+}
+
+func TestIntegration_Completions_EchoPrependsPrompt(t *testing.T) {
+	// Given: echo enabled
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-3.5-turbo-instruct","prompt":"Say hi","echo":true}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the text starts with the prompt followed by the completion
+	text, _ := getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})["text"].(string)
+	if text != "Say hiEcho: Say hi" {
+		t.Errorf("expected prompt + completion, got %q", text)
+	}
+}
+
+func TestIntegration_Completions_StreamingEcho(t *testing.T) {
+	// Given: a streaming request with echo enabled
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-3.5-turbo-instruct","prompt":"Say hi","echo":true,"stream":true}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the prompt arrives as the first delta, then the completion
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream, got %q", ct)
+	}
+	texts := readStreamedCompletionText(t, resp.Body)
+	if len(texts[0]) != 2 || texts[0][0] != "Say hi" || texts[0][1] != "Echo: Say hi" {
+		t.Errorf("expected [prompt completion] deltas, got %q", texts[0])
+	}
+}
+
+func TestIntegration_Completions_StreamingWithoutEcho(t *testing.T) {
+	// Given: a streaming request with n=2
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-3.5-turbo-instruct","prompt":"Say hi","n":2,"stream":true}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: each choice streams only the completion
+	texts := readStreamedCompletionText(t, resp.Body)
+	for i := 0; i < 2; i++ {
+		if strings.Join(texts[i], "") != "Echo: Say hi" {
+			t.Errorf("choice %d: expected completion only, got %q", i, texts[i])
+		}
+	}
+}
+
+func TestIntegration_Completions_StreamingBestOfRejected(t *testing.T) {
+	// Given: a streaming request with best_of greater than n
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", `{"model":"gpt-3.5-turbo-instruct","prompt":"hi","best_of":3,"stream":true}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Completions_TokenArrayPrompt(t *testing.T) {
+	// Given: a prompt given as a list of token ID lists
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", `{"model":"gpt-3.5-turbo-instruct","prompt":[[15339,1917]]}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the tokens are echoed as placeholders
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	body := mustDecodeJSON(t, resp.Body)
+	text, _ := getChoices(t, body)[0].(map[string]interface{})["text"].(string)
+	if text != "Echo: <|15339|><|1917|>" {
+		t.Errorf("expected detokenized echo, got %q", text)
+	}
+	// and each token ID is billed as one prompt token
+	usage, _ := body["usage"].(map[string]interface{})
+	if usage["prompt_tokens"] != float64(2) {
+		t.Errorf("expected 2 prompt tokens, got %v", usage["prompt_tokens"])
+	}
+}
+
+func TestIntegration_Completions_BestOfBelowN(t *testing.T) {
+	// Given: best_of smaller than n
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", `{"model":"gpt-4o","prompt":"hi","n":3,"best_of":2}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+// --- Responses API ---
+
+func TestIntegration_Responses_PlainText(t *testing.T) {
+	// Given: POST /v1/responses with plain text input
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","input":"tell me a joke"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 200 OK, status=completed, output contains a message item
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	for _, field := range []string{"id", "object", "status", "model", "output"} {
+		if result[field] == nil {
+			t.Errorf("expected field %q in response", field)
+		}
+	}
+	if status, _ := result["status"].(string); status != "completed" {
+		t.Errorf("expected status=completed, got %q", status)
+	}
+	output, ok := result["output"].([]interface{})
+	if !ok || len(output) == 0 {
+		t.Fatal("expected non-empty output array")
+	}
+	item := output[0].(map[string]interface{})
+	if itemType, _ := item["type"].(string); itemType != "message" {
+		t.Errorf("expected output[0].type=message, got %q", itemType)
+	}
+}
+
+func TestIntegration_Responses_FunctionCall(t *testing.T) {
+	// Given: POST /v1/responses with tools
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{
+		"model": "gpt-4o",
+		"input": "get the weather in Tokyo",
+		"tools": [{
+			"type": "function",
+			"name": "get_weather",
+			"description": "Get weather info",
+			"parameters": {"type": "object", "properties": {"city": {"type": "string"}}}
+		}]
+	}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 200 OK, output contains function_call item
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	output, ok := result["output"].([]interface{})
+	if !ok || len(output) == 0 {
+		t.Fatal("expected non-empty output array")
+	}
+	item := output[0].(map[string]interface{})
+	if itemType, _ := item["type"].(string); itemType != "function_call" {
+		t.Errorf("expected output[0].type=function_call, got %q", itemType)
+	}
+}
+
+func TestIntegration_Responses_StructuredOutput(t *testing.T) {
+	// Given: POST /v1/responses with text.format=json_schema
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{
+		"model": "gpt-4o",
+		"input": "give me user data",
+		"text": {
+			"format": {
+				"type": "json_schema",
+				"name": "user",
+				"schema": {
+					"type": "object",
+					"properties": {
+						"username": {"type": "string"},
+						"email":    {"type": "string"}
+					},
+					"required": ["username", "email"]
+				}
+			}
+		}
+	}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 200 OK, output contains message item with valid JSON content matching the schema
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	output, ok := result["output"].([]interface{})
+	if !ok || len(output) == 0 {
+		t.Fatal("expected non-empty output array")
+	}
+	item := output[0].(map[string]interface{})
+	if itemType, _ := item["type"].(string); itemType != "message" {
+		t.Errorf("expected output[0].type=message, got %q", itemType)
+	}
+	content, ok := item["content"].([]interface{})
+	if !ok || len(content) == 0 {
+		t.Fatal("expected non-empty content array")
+	}
+	contentItem := content[0].(map[string]interface{})
+	text, _ := contentItem["text"].(string)
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &parsed); err != nil {
+		t.Errorf("expected valid JSON content, got %q: %v", text, err)
+	}
+	if _, ok := parsed["username"]; !ok {
+		t.Error("expected 'username' field in JSON response")
+	}
+	if _, ok := parsed["email"]; !ok {
+		t.Error("expected 'email' field in JSON response")
+	}
+}
+
+func TestIntegration_Responses_HasUsage(t *testing.T) {
+	// Given: any valid responses request
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","input":"hello"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: usage field is present
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	if result["usage"] == nil {
+		t.Error("expected usage field in responses response")
+	}
+}
+
+func TestIntegration_Responses_SearchToolsAnnotateOutputText(t *testing.T) {
+	// Given: web search and file search tools
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","input":"what is our refund policy",` +
+		`"tools":[{"type":"web_search_preview"},{"type":"file_search","vector_store_ids":["vs_123"]}]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/responses", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the output text carries a url_citation and a file_citation
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	var result api.CreateResponseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	content := result.Output[0].Content[0]
+	var types []string
+	for _, a := range content.Annotations {
+		types = append(types, string(a.Type))
+	}
+	if !slices.Equal(types, []string{"url_citation", "file_citation"}) {
+		t.Fatalf("expected a url and a file citation, got %+v", content.Annotations)
+	}
+	if file := content.Annotations[1]; file.Index.Value != utf8.RuneCountInString(content.Text) || !strings.HasPrefix(file.FileID.Value, "file-") {
+		t.Errorf("expected a file cited at the end of the text, got %+v", file)
+	}
+}
+
+func TestIntegration_Responses_PlainTextHasNoAnnotations(t *testing.T) {
+	// Given: no tools
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/responses", `{"model":"gpt-4o","input":"hello"}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: annotations is an empty array, as the SDKs expect
+	var result api.CreateResponseResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if a := result.Output[0].Content[0].Annotations; a == nil || len(a) != 0 {
+		t.Errorf("expected empty annotations, got %+v", a)
+	}
+}
+
+// --- Embeddings ---
+
+func TestIntegration_Embeddings_BasicStringInput(t *testing.T) {
+	// Given: POST /v1/embeddings with a single string input
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"text-embedding-3-small","input":"hello world"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 200 OK, data[0].embedding is a non-empty float array
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	if obj, _ := result["object"].(string); obj != "list" {
+		t.Errorf("expected object=list, got %q", obj)
+	}
+	data, ok := result["data"].([]interface{})
+	if !ok || len(data) == 0 {
+		t.Fatal("expected non-empty data array")
+	}
+	item := data[0].(map[string]interface{})
+	if itemObj, _ := item["object"].(string); itemObj != "embedding" {
+		t.Errorf("expected data[0].object=embedding, got %q", itemObj)
+	}
+	embedding, ok := item["embedding"].([]interface{})
+	if !ok || len(embedding) == 0 {
+		t.Error("expected non-empty embedding vector")
+	}
+}
+
+func TestIntegration_Embeddings_MultipleInputs(t *testing.T) {
+	// Given: a request with three string inputs
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"text-embedding-3-small","input":["hello","world","foo"]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: data has exactly 3 embeddings
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	data, ok := result["data"].([]interface{})
+	if !ok {
+		t.Fatal("expected data array")
+	}
+	if len(data) != 3 {
+		t.Errorf("expected 3 embeddings, got %d", len(data))
+	}
+}
+
+func TestIntegration_Embeddings_DimensionsParameter(t *testing.T) {
+	// Given: request with explicit dimensions=256
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"text-embedding-3-small","input":"hello","dimensions":256}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: embedding vector has exactly 256 elements
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	data, ok := result["data"].([]interface{})
+	if !ok || len(data) == 0 {
+		t.Fatal("expected data array")
+	}
+	item := data[0].(map[string]interface{})
+	embedding, ok := item["embedding"].([]interface{})
+	if !ok {
+		t.Fatal("expected embedding field")
+	}
+	if len(embedding) != 256 {
+		t.Errorf("expected 256 dimensions, got %d", len(embedding))
+	}
+}
+
+func TestIntegration_Embeddings_HasUsage(t *testing.T) {
+	// Given: any valid embeddings request
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"text-embedding-3-small","input":"usage test"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: usage field is present with prompt_tokens and total_tokens
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	usage, ok := result["usage"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected usage object in response")
+	}
+	if usage["prompt_tokens"] == nil {
+		t.Error("expected prompt_tokens in usage")
+	}
+	if usage["total_tokens"] == nil {
 		t.Error("expected total_tokens in usage")
 	}
 }
+
+func TestIntegration_Embeddings_UsageSumsPerInputTokens(t *testing.T) {
+	// Given: a batch of inputs with known token counts
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"text-embedding-3-small","input":["hello world","embedding tokens!"]}`
+	want := countTokens("hello world") + countTokens("embedding tokens!")
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/embeddings", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: prompt_tokens and total_tokens equal the per-input sum
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	usage, ok := result["usage"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected usage object in response")
+	}
+	if got, _ := usage["prompt_tokens"].(float64); int(got) != want {
+		t.Errorf("expected prompt_tokens=%d, got %v", want, usage["prompt_tokens"])
+	}
+	if got, _ := usage["total_tokens"].(float64); int(got) != want {
+		t.Errorf("expected total_tokens=%d, got %v", want, usage["total_tokens"])
+	}
+}
+
+// --- Admin API ---
+
+func TestIntegration_Admin_EmbeddingSearch_RanksEmbeddedInputs(t *testing.T) {
+	// Given: a few inputs embedded through the public API
+	srv := newTestServer(t)
+	defer srv.Close()
+	embedResp := postJSON(t, srv.URL+"/v1/embeddings",
+		`{"model":"text-embedding-3-small","input":["red apple","green pear","yellow banana"],"dimensions":64}`)
+	_ = embedResp.Body.Close()
+	if embedResp.StatusCode != http.StatusOK {
+		t.Fatalf("embeddings: expected 200, got %d", embedResp.StatusCode)
+	}
+
+	// When: searching with one of the inputs as the query
+	resp := postJSON(t, srv.URL+"/_mokku/embeddings/search", `{"query":"green pear","top_k":2}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the exact input ranks first and top_k is honored
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	data, ok := result["data"].([]interface{})
+	if !ok {
+		t.Fatal("expected data array")
+	}
+	if len(data) != 2 {
+		t.Fatalf("expected 2 matches, got %d", len(data))
+	}
+	first := data[0].(map[string]interface{})
+	if input, _ := first["input"].(string); input != "green pear" {
+		t.Errorf("expected 'green pear' to rank first, got %q", input)
+	}
+}
+
+func TestIntegration_Admin_EmbeddingSearch_MissingQuery(t *testing.T) {
+	// Given: a search request without a query
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/_mokku/embeddings/search", `{}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 400 with invalid_request_error
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	errObj, ok := result["error"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected error object in response")
+	}
+	if errType, _ := errObj["type"].(string); errType != "invalid_request_error" {
+		t.Errorf("expected type=invalid_request_error, got %q", errType)
+	}
+}
+
+func TestIntegration_Admin_EmbeddingReset_ClearsIndex(t *testing.T) {
+	// Given: an embedded input
+	srv := newTestServer(t)
+	defer srv.Close()
+	embedResp := postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"forget me"}`)
+	_ = embedResp.Body.Close()
+
+	// When: resetting the index
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/embeddings", nil)
+	resetResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE: %v", err)
+	}
+	_ = resetResp.Body.Close()
+
+	// Then: 204 and subsequent searches return nothing
+	if resetResp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", resetResp.StatusCode)
+	}
+	resp := postJSON(t, srv.URL+"/_mokku/embeddings/search", `{"query":"forget me"}`)
+	defer func() { _ = resp.Body.Close() }()
+	result := mustDecodeJSON(t, resp.Body)
+	if data, _ := result["data"].([]interface{}); len(data) != 0 {
+		t.Errorf("expected no matches after reset, got %d", len(data))
+	}
+}
+
+func TestIntegration_Admin_Capabilities(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp, err := http.Get(srv.URL + "/_mokku/capabilities")
+	if err != nil {
+		t.Fatalf("GET capabilities: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the instance, spec version, and served endpoints are described
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	caps := mustDecodeJSON(t, resp.Body)
+	if caps["object"] != "mokku.capabilities" || caps["instance"] != "test-instance" {
+		t.Errorf("unexpected capabilities: %v", caps)
+	}
+	if v, _ := caps["spec_version"].(string); v == "" {
+		t.Error("expected spec_version")
+	}
+	endpoints, _ := caps["endpoints"].([]interface{})
+	found := false
+	for _, e := range endpoints {
+		ep := e.(map[string]interface{})
+		if ep["method"] == "POST" && ep["path"] == "/v1/completions" && ep["streaming"] == true {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected streaming POST /v1/completions in endpoints, got %v", endpoints)
+	}
+}
+
+// putJSON sends a PUT request with a JSON body and returns the response.
+func putJSON(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT %s: %v", url, err)
+	}
+	return resp
+}
+
+func TestIntegration_Admin_Flags_StrictModelValidation(t *testing.T) {
+	// Given: strict model validation enabled through the admin API
+	srv := newTestServer(t)
+	defer srv.Close()
+	flagResp := putJSON(t, srv.URL+"/_mokku/flags/strict_model_validation", `{"enabled":true}`)
+	_ = flagResp.Body.Close()
+	if flagResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from flag toggle, got %d", flagResp.StatusCode)
+	}
+
+	// When: an unknown and a listed model are requested, streaming and not
+	unknown := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-9","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = unknown.Body.Close() }()
+	unknownStream := postJSON(t, srv.URL+"/v1/completions", `{"model":"gpt-9","prompt":"hi","stream":true}`)
+	defer func() { _ = unknownStream.Body.Close() }()
+	known := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = known.Body.Close() }()
+
+	// Then: unknown models get 404 model_not_found, listed models still work
+	for _, resp := range []*http.Response{unknown, unknownStream} {
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("expected 404, got %d", resp.StatusCode)
+		}
+		errObj, _ := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{})
+		if errObj["code"] != "model_not_found" {
+			t.Errorf("expected model_not_found, got %v", errObj)
+		}
+	}
+	if known.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for a listed model, got %d", known.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_Flags_ReportsEvaluations(t *testing.T) {
+	// Given: one chat request with the flag at its default (off)
+	srv := newTestServer(t)
+	defer srv.Close()
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-9","messages":[{"role":"user","content":"hi"}]}`)
+	_ = resp.Body.Close()
+
+	// When
+	flagsResp, err := http.Get(srv.URL + "/_mokku/flags")
+	if err != nil {
+		t.Fatalf("GET flags: %v", err)
+	}
+	defer func() { _ = flagsResp.Body.Close() }()
+
+	// Then: the request was served and the evaluation counted without a hit
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected unknown models to be accepted by default, got %d", resp.StatusCode)
+	}
+	data, _ := mustDecodeJSON(t, flagsResp.Body)["data"].([]interface{})
+	for _, d := range data {
+		flag := d.(map[string]interface{})
+		if flag["name"] != "strict_model_validation" {
+			continue
+		}
+		if flag["enabled"] != false || flag["source"] != "default" || flag["evaluations"] != float64(1) || flag["hits"] != float64(0) {
+			t.Errorf("unexpected flag status: %v", flag)
+		}
+		return
+	}
+	t.Errorf("strict_model_validation not listed: %v", data)
+}
+
+func TestIntegration_Admin_Audit_RecordsMutations(t *testing.T) {
+	// Given: a flag toggled with a token, a reset without one, and a read
+	srv := newTestServer(t)
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/_mokku/flags/strict_model_validation", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set("Authorization", "Bearer team-a-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT flag: %v", err)
+	}
+	_ = resp.Body.Close()
+	req, _ = http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/streams", nil)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE streams: %v", err)
+	}
+	_ = resp.Body.Close()
+	resp, err = http.Get(srv.URL + "/_mokku/streams")
+	if err != nil {
+		t.Fatalf("GET streams: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// When
+	auditResp, err := http.Get(srv.URL + "/_mokku/audit")
+	if err != nil {
+		t.Fatalf("GET audit: %v", err)
+	}
+	defer func() { _ = auditResp.Body.Close() }()
+
+	// Then: both mutations are recorded newest first, reads are not
+	data, _ := mustDecodeJSON(t, auditResp.Body)["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("expected 2 audit entries, got %v", data)
+	}
+	reset := data[0].(map[string]interface{})
+	toggle := data[1].(map[string]interface{})
+	if reset["actor"] != "anonymous" || reset["action"] != "DELETE /_mokku/streams" || reset["status"] != float64(204) {
+		t.Errorf("unexpected reset entry: %v", reset)
+	}
+	actor, _ := toggle["actor"].(string)
+	if !strings.HasPrefix(actor, "token:") || toggle["action"] != "PUT /_mokku/flags/{name}" || toggle["body"] != `{"enabled":true}` {
+		t.Errorf("unexpected toggle entry: %v", toggle)
+	}
+}
+
+func TestIntegration_Admin_Auth_Roles(t *testing.T) {
+	// Given: a read-only and a read-write admin token
+	srv := newTestServerWithConfig(t, Config{Admin: adminConfig{Tokens: []adminTokenConfig{
+		{Name: "dashboard", Token: "reader-token", Role: adminRoleRead},
+		{Name: "ci", Token: "writer-token", Role: adminRoleWrite},
+	}}})
+	defer srv.Close()
+	do := func(method, path, token string) int {
+		req, _ := http.NewRequest(method, srv.URL+path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// When / Then: tokens are required, readers cannot mutate, writers can
+	if got := do(http.MethodGet, "/_mokku/streams", ""); got != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a token, got %d", got)
+	}
+	if got := do(http.MethodGet, "/_mokku/streams", "reader-token"); got != http.StatusOK {
+		t.Errorf("expected 200 for a reader, got %d", got)
+	}
+	if got := do(http.MethodDelete, "/_mokku/streams", "reader-token"); got != http.StatusForbidden {
+		t.Errorf("expected 403 for a reader mutation, got %d", got)
+	}
+	if got := do(http.MethodDelete, "/_mokku/streams", "writer-token"); got != http.StatusNoContent {
+		t.Errorf("expected 204 for a writer mutation, got %d", got)
+	}
+	if got := do(http.MethodGet, "/_mokku/images/unknown", ""); got != http.StatusNotFound {
+		t.Errorf("expected image URLs to stay public, got %d", got)
+	}
+
+	// Then: the audit trail names the token holders, including the denied attempt
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/_mokku/audit", nil)
+	req.Header.Set("Authorization", "Bearer reader-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET audit: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := mustDecodeJSON(t, resp.Body)["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("expected 2 audit entries, got %v", data)
+	}
+	allowed, denied := data[0].(map[string]interface{}), data[1].(map[string]interface{})
+	if allowed["actor"] != "ci" || allowed["status"] != float64(204) || denied["actor"] != "dashboard" || denied["status"] != float64(403) {
+		t.Errorf("unexpected audit entries: %v", data)
+	}
+}
+
+func TestIntegration_Admin_Auth_ReadOnlyPostEndpoints(t *testing.T) {
+	// Given: a read-only admin token
+	srv := newTestServerWithConfig(t, Config{Admin: adminConfig{Tokens: []adminTokenConfig{
+		{Name: "dashboard", Token: "reader-token", Role: adminRoleRead},
+	}}})
+	defer srv.Close()
+	post := func(path, body string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/_mokku"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer reader-token")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// When: the reader posts to the query endpoints and to a mutation
+	tokenize := post("/tokenize", `{"model":"gpt-4o","text":"hello"}`)
+	search := post("/embeddings/search", `{"query":"hello"}`)
+	evaluate := post("/evaluate", `{"path":"/v1/chat/completions","body":{"model":"gpt-4o"}}`)
+	advance := post("/clock/advance", `{"duration":"1h"}`)
+
+	// Then: the queries are served and not audited, the mutation is denied and audited
+	if tokenize != http.StatusOK || search != http.StatusOK || evaluate != http.StatusOK {
+		t.Errorf("expected 200 for the queries, got %d %d %d", tokenize, search, evaluate)
+	}
+	if advance != http.StatusForbidden {
+		t.Errorf("expected 403 for the clock advance, got %d", advance)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/_mokku/audit", nil)
+	req.Header.Set("Authorization", "Bearer reader-token")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET audit: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, _ := mustDecodeJSON(t, resp.Body)["data"].([]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["path"] != "/_mokku/clock/advance" {
+		t.Errorf("expected only the denied clock advance to be audited, got %v", data)
+	}
+}
+
+func TestIntegration_Admin_Flags_UnknownFlag(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := putJSON(t, srv.URL+"/_mokku/flags/no_such_flag", `{"enabled":true}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_Tokenize_CountMatchesUsage(t *testing.T) {
+	// Given: the same messages sent to the tokenize endpoint and to chat completions
+	srv := newTestServer(t)
+	defer srv.Close()
+	messages := `[{"role":"system","content":"You are terse."},{"role":"user","content":"Count these tokens, please!"}]`
+
+	// When
+	tokResp := postJSON(t, srv.URL+"/_mokku/tokenize", `{"model":"gpt-4o","messages":`+messages+`}`)
+	defer func() { _ = tokResp.Body.Close() }()
+	chatResp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":`+messages+`}`)
+	defer func() { _ = chatResp.Body.Close() }()
+
+	// Then: the token count equals prompt_tokens and every token is listed
+	if tokResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", tokResp.StatusCode)
+	}
+	tokens := mustDecodeJSON(t, tokResp.Body)
+	usage := mustDecodeJSON(t, chatResp.Body)["usage"].(map[string]interface{})
+	if tokens["count"] != usage["prompt_tokens"] {
+		t.Errorf("expected count %v to equal prompt_tokens %v", tokens["count"], usage["prompt_tokens"])
+	}
+	if data, _ := tokens["data"].([]interface{}); float64(len(data)) != tokens["count"] {
+		t.Errorf("expected %v tokens listed, got %d", tokens["count"], len(data))
+	}
+	if tokens["model"] != "gpt-4o" {
+		t.Errorf("expected model echoed, got %v", tokens["model"])
+	}
+}
+
+func TestIntegration_Admin_Tokenize_TextAndMessagesIsError(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/_mokku/tokenize", `{"text":"hi","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_ModelMetadata(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp, err := http.Get(srv.URL + "/_mokku/models/gpt-4o")
+	if err != nil {
+		t.Fatalf("GET model metadata: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	m := mustDecodeJSON(t, resp.Body)
+	if m["context_window"] != float64(128000) || m["max_output_tokens"] != float64(16384) {
+		t.Errorf("unexpected metadata: %v", m)
+	}
+}
+
+func TestIntegration_ChatCompletion_MaxTokensAboveModelLimit(t *testing.T) {
+	// Given: max_tokens above gpt-4o's output limit, streaming and not
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","max_tokens":100000,"messages":[{"role":"user","content":"hi"}]}`
+	streamBody := `{"model":"gpt-4o","stream":true,"max_completion_tokens":100000,"messages":[{"role":"user","content":"hi"}]}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = resp.Body.Close() }()
+	streamResp := postJSON(t, srv.URL+"/v1/chat/completions", streamBody)
+	defer func() { _ = streamResp.Body.Close() }()
+
+	// Then: both are rejected before any content is generated
+	for _, r := range []*http.Response{resp, streamResp} {
+		if r.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", r.StatusCode)
+		}
+		errObj, _ := mustDecodeJSON(t, r.Body)["error"].(map[string]interface{})
+		if errObj["param"] != "max_tokens" {
+			t.Errorf("expected a max_tokens error, got %v", errObj)
+		}
+	}
+}
+
+func TestIntegration_ContextOverflow_TruncateAndIgnore(t *testing.T) {
+	// Given: a 16-token model that truncates and one that ignores its window
+	srv := newTestServerWithConfig(t, Config{Models: []modelMetadata{
+		{ID: "tiny-truncate", ContextWindow: 16, ContextOverflow: contextOverflowTruncate},
+		{ID: "tiny-ignore", ContextWindow: 16, ContextOverflow: contextOverflowIgnore},
+	}})
+	defer srv.Close()
+	short := `"messages":[{"role":"user","content":"a b c d e f g h i j"}]`
+	long := `"messages":[{"role":"user","content":"a b c d e f g h i j k l m n o p q r s t"}]`
+
+	// When: a 10-token prompt asks for 10 completion tokens, streaming and not
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"tiny-truncate","max_tokens":10,`+short+`}`)
+	defer func() { _ = resp.Body.Close() }()
+	streamResp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"tiny-truncate","stream":true,"max_tokens":10,`+short+`}`)
+	defer func() { _ = streamResp.Body.Close() }()
+
+	// Then: the completion is cut to the 6 tokens left in the window
+	body := mustDecodeJSON(t, resp.Body)
+	choice := body["choices"].([]interface{})[0].(map[string]interface{})
+	usage := body["usage"].(map[string]interface{})
+	if choice["message"].(map[string]interface{})["content"] != "Echo: a b c d" || choice["finish_reason"] != "length" {
+		t.Errorf("expected content cut with finish_reason length, got %v", choice)
+	}
+	if usage["prompt_tokens"] != float64(10) || usage["completion_tokens"] != float64(6) {
+		t.Errorf("unexpected usage %v", usage)
+	}
+	stream, _ := io.ReadAll(streamResp.Body)
+	if !strings.Contains(string(stream), `"content":"Echo: a b c d"`) || !strings.Contains(string(stream), `"finish_reason":"length"`) {
+		t.Errorf("expected the streamed content cut with finish_reason length, got %s", stream)
+	}
+
+	// When: a 20-token prompt is sent to both models
+	truncated := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"tiny-truncate",`+long+`}`)
+	defer func() { _ = truncated.Body.Close() }()
+	ignored := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"tiny-ignore",`+long+`}`)
+	defer func() { _ = ignored.Body.Close() }()
+
+	// Then: the truncating model reads the 16 tokens that fit and has no room to answer,
+	// while the ignoring model answers in full
+	body = mustDecodeJSON(t, truncated.Body)
+	if usage := body["usage"].(map[string]interface{}); usage["prompt_tokens"] != float64(16) || usage["completion_tokens"] != float64(0) {
+		t.Errorf("expected the prompt truncated to the window, got %v", usage)
+	}
+	body = mustDecodeJSON(t, ignored.Body)
+	if choice := body["choices"].([]interface{})[0].(map[string]interface{}); choice["finish_reason"] != "stop" {
+		t.Errorf("expected the ignoring model to answer in full, got %v", choice)
+	}
+}
+
+func TestIntegration_ContextOverflow_TruncatesLegacyCompletions(t *testing.T) {
+	// Given
+	srv := newTestServerWithConfig(t, Config{Models: []modelMetadata{
+		{ID: "tiny-truncate", ContextWindow: 16, ContextOverflow: contextOverflowTruncate},
+	}})
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/completions", `{"model":"tiny-truncate","max_tokens":10,"prompt":"a b c d e f g h i j"}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	body := mustDecodeJSON(t, resp.Body)
+	choice := body["choices"].([]interface{})[0].(map[string]interface{})
+	if choice["text"] != "Echo: a b c d" || choice["finish_reason"] != "length" {
+		t.Errorf("expected text cut with finish_reason length, got %v", choice)
+	}
+}
+
+func TestIntegration_Moderation_RejectsBannedPhrases(t *testing.T) {
+	// Given: a banned phrase
+	srv := newTestServerWithConfig(t, Config{Moderation: moderationConfig{BannedPhrases: []string{"launch codes"}}})
+	defer srv.Close()
+
+	// When: it is sent in a streaming chat request, an image prompt, and a clean request
+	chat := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"Tell me the Launch Codes"}]}`)
+	defer func() { _ = chat.Body.Close() }()
+	image := postJSON(t, srv.URL+"/v1/images/generations", `{"prompt":"a poster of launch codes"}`)
+	defer func() { _ = image.Body.Close() }()
+	clean := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}]}`)
+	defer func() { _ = clean.Body.Close() }()
+
+	// Then: the flagged requests get the policy errors, the clean one is served
+	for resp, code := range map[*http.Response]string{chat: "invalid_prompt", image: "content_policy_violation"} {
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("expected 400, got %d", resp.StatusCode)
+		}
+		errObj, _ := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{})
+		if errObj["code"] != code {
+			t.Errorf("expected %s, got %v", code, errObj)
+		}
+	}
+	if clean.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for a clean request, got %d", clean.StatusCode)
+	}
+}
+
+func TestIntegration_Moderation_RejectsBannedUsers(t *testing.T) {
+	// Given: a banned end-user
+	srv := newTestServerWithConfig(t, Config{Moderation: moderationConfig{BannedUsers: []string{"u-banned"}}})
+	defer srv.Close()
+
+	// When: the user is named by safety_identifier on chat, by user on responses, and another user asks
+	chat := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","safety_identifier":"u-banned","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = chat.Body.Close() }()
+	responses := postJSON(t, srv.URL+"/v1/responses", `{"model":"gpt-4o","input":"hi","user":"u-banned"}`)
+	defer func() { _ = responses.Body.Close() }()
+	other := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","safety_identifier":"u-ok","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = other.Body.Close() }()
+
+	// Then: the banned user's requests fail naming the field, the other user is served
+	for resp, param := range map[*http.Response]string{chat: "safety_identifier", responses: "user"} {
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("expected 403, got %d", resp.StatusCode)
+		}
+		errObj, _ := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{})
+		if errObj["code"] != "user_blocked" || errObj["param"] != param {
+			t.Errorf("expected user_blocked on %s, got %v", param, errObj)
+		}
+	}
+	if other.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for another user, got %d", other.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_BannedUsers_EnforcementAndAppeal(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+	chat := func() int {
+		resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","user":"u-7","messages":[{"role":"user","content":"hi"}]}`)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// When: the user is banned through the admin API
+	ban := putJSON(t, srv.URL+"/_mokku/banned-users/u-7", `{"reason":"chargeback fraud"}`)
+	_ = ban.Body.Close()
+	banned := chat()
+	listResp, err := http.Get(srv.URL + "/_mokku/banned-users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = listResp.Body.Close() }()
+
+	// Then: requests for the user get the policy 403, counted on the ban
+	if ban.StatusCode != http.StatusOK || banned != http.StatusForbidden {
+		t.Fatalf("expected the ban to block requests, got %d and %d", ban.StatusCode, banned)
+	}
+	data, _ := mustDecodeJSON(t, listResp.Body)["data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected one banned user, got %v", data)
+	}
+	if entry := data[0].(map[string]interface{}); entry["reason"] != "chargeback fraud" || entry["blocked"] != 1.0 {
+		t.Errorf("unexpected entry %v", entry)
+	}
+
+	// When: the appeal succeeds and the user is unbanned twice
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/banned-users/u-7", nil)
+	unban, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = unban.Body.Close()
+	again, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = again.Body.Close()
+
+	// Then: the user is served again
+	if unban.StatusCode != http.StatusNoContent || again.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 204 then 404, got %d and %d", unban.StatusCode, again.StatusCode)
+	}
+	if status := chat(); status != http.StatusOK {
+		t.Errorf("expected 200 after the appeal, got %d", status)
+	}
+}
+
+func TestIntegration_EndUser_EchoedAndCaptured(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When: a responses request names its end-user
+	resp := postJSON(t, srv.URL+"/v1/responses", `{"model":"gpt-4o","input":"hi","safety_identifier":"u-1","user":"legacy-1"}`)
+	defer func() { _ = resp.Body.Close() }()
+	captured, err := http.Get(srv.URL + "/_mokku/requests?safety_identifier=u-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = captured.Body.Close() }()
+
+	// Then: the response echoes both IDs, and the capture records them
+	result := mustDecodeJSON(t, resp.Body)
+	if result["safety_identifier"] != "u-1" || result["user"] != "legacy-1" {
+		t.Errorf("expected the IDs to be echoed, got %v", result)
+	}
+	data, _ := mustDecodeJSON(t, captured.Body)["data"].([]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["user"] != "legacy-1" {
+		t.Errorf("expected one captured request of the user, got %v", data)
+	}
+}
+
+func TestIntegration_RateLimit_TenantBudgets(t *testing.T) {
+	// Given: a tenant allowed two requests per minute and a default token budget for other keys
+	srv := newTestServerWithConfig(t, Config{RateLimits: rateLimitConfig{
+		Default: &rateLimitBudget{TokensPerMinute: 50},
+		Tenants: []tenantBudgetConfig{{Name: "team-a", APIKey: "sk-team-a", rateLimitBudget: rateLimitBudget{RequestsPerMinute: 2}}},
+	}})
+	defer srv.Close()
+	post := func(apiKey, body string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		return resp
+	}
+	body := `{"model":"gpt-4o","max_tokens":20,"messages":[{"role":"user","content":"hello"}]}`
+
+	// When: the tenant sends three requests and another key exhausts its token budget
+	var tenant []*http.Response
+	for range 3 {
+		resp := post("sk-team-a", body)
+		_ = resp.Body.Close()
+		tenant = append(tenant, resp)
+	}
+	first := post("sk-other", body)
+	_ = first.Body.Close()
+	second := post("sk-other", body)
+	defer func() { _ = second.Body.Close() }()
+
+	// Then: the headers count down the tenant's budget until a 429 with Retry-After
+	for i, want := range []string{"1", "0"} {
+		if tenant[i].StatusCode != http.StatusOK || tenant[i].Header.Get("x-ratelimit-remaining-requests") != want {
+			t.Errorf("request %d: expected 200 with %s remaining, got %d with %q", i, want, tenant[i].StatusCode,
+				tenant[i].Header.Get("x-ratelimit-remaining-requests"))
+		}
+		if tenant[i].Header.Get("x-ratelimit-limit-requests") != "2" || tenant[i].Header.Get("x-ratelimit-limit-tokens") != "" {
+			t.Errorf("request %d: unexpected limit headers %v", i, tenant[i].Header)
+		}
+	}
+	if tenant[2].StatusCode != http.StatusTooManyRequests || tenant[2].Header.Get("Retry-After") == "" {
+		t.Errorf("expected 429 with Retry-After, got %d %v", tenant[2].StatusCode, tenant[2].Header)
+	}
+	remaining := strconv.Itoa(50 - countTokens("gpt-4o") - countTokens("user") - countTokens("hello") - 20)
+	if first.StatusCode != http.StatusOK || first.Header.Get("x-ratelimit-remaining-tokens") != remaining {
+		t.Errorf("expected %s tokens remaining, got %d %q", remaining, first.StatusCode, first.Header.Get("x-ratelimit-remaining-tokens"))
+	}
+	if second.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", second.StatusCode)
+	}
+	errObj, _ := mustDecodeJSON(t, second.Body)["error"].(map[string]interface{})
+	if errObj["code"] != "rate_limit_exceeded" || errObj["type"] != "tokens" {
+		t.Errorf("expected a tokens rate_limit_exceeded error, got %v", errObj)
+	}
+}
+
+const testScenarios = `
+scenarios:
+  - name: weather
+    match:
+      message: "(?i)weather in (\\w+)"
+    response:
+      content: "It is sunny. ({{.Model}}: {{.Message}})"
+      finish_reason: length
+  - name: overloaded
+    match:
+      headers:
+        X-Test-Case: "^overload$"
+    response:
+      status: 503
+      latency: 50ms
+  - name: no-quota
+    match:
+      model: gpt-4o-mini
+      path: /v1/completions
+    response:
+      status: 429
+      error:
+        message: "You exceeded your current quota."
+        type: insufficient_quota
+        code: insufficient_quota
+`
+
+func TestIntegration_Scenarios_ContentAppliesToStreamingAndNonStreaming(t *testing.T) {
+	// Given: a scenario matching on the message
+	srv := newTestServerWithScenarios(t, Config{}, testScenarios)
+	defer srv.Close()
+	body := func(stream bool) string {
+		return fmt.Sprintf(`{"model":"gpt-4o","stream":%t,"messages":[{"role":"user","content":"weather in Tokyo?"}]}`, stream)
+	}
+
+	// When: the same request is sent with and without streaming, and as a legacy completion
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body(false))
+	defer func() { _ = resp.Body.Close() }()
+	streamResp := postJSON(t, srv.URL+"/v1/chat/completions", body(true))
+	defer func() { _ = streamResp.Body.Close() }()
+	completion := postJSON(t, srv.URL+"/v1/completions", `{"model":"gpt-4o","prompt":"weather in Paris"}`)
+	defer func() { _ = completion.Body.Close() }()
+
+	// Then: all get the templated content and finish_reason
+	want := "It is sunny. (gpt-4o: weather in Tokyo?)"
+	var chat api.CreateChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		t.Fatal(err)
+	}
+	if chat.Choices[0].Message.Content.Value != want || chat.Choices[0].FinishReason != "length" {
+		t.Errorf("unexpected chat choice: %+v", chat.Choices[0])
+	}
+	if got := strings.Join(readStreamedContent(t, streamResp.Body), ""); got != want {
+		t.Errorf("expected streamed %q, got %q", want, got)
+	}
+	var legacy api.CreateCompletionResponse
+	if err := json.NewDecoder(completion.Body).Decode(&legacy); err != nil {
+		t.Fatal(err)
+	}
+	if legacy.Choices[0].Text != "It is sunny. (gpt-4o: weather in Paris)" || legacy.Choices[0].FinishReason != "length" {
+		t.Errorf("unexpected completion choice: %+v", legacy.Choices[0])
+	}
+}
+
+func TestIntegration_Scenarios_Errors(t *testing.T) {
+	// Given
+	srv := newTestServerWithScenarios(t, Config{}, testScenarios)
+	defer srv.Close()
+
+	// When: a request with the overload header and a legacy completion for the no-quota model
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Case", "overload")
+	start := time.Now()
+	overloaded, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = overloaded.Body.Close() }()
+	elapsed := time.Since(start)
+	quota := postJSON(t, srv.URL+"/v1/completions", `{"model":"gpt-4o-mini","prompt":"hi"}`)
+	defer func() { _ = quota.Body.Close() }()
+	other := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = other.Body.Close() }()
+
+	// Then: the errors follow the scenarios after their latency, and other requests are unaffected
+	if overloaded.StatusCode != http.StatusServiceUnavailable || elapsed < 50*time.Millisecond {
+		t.Errorf("expected a delayed 503, got %d after %s", overloaded.StatusCode, elapsed)
+	}
+	errObj, _ := mustDecodeJSON(t, overloaded.Body)["error"].(map[string]interface{})
+	if errObj["type"] != "server_error" {
+		t.Errorf("expected a server_error, got %v", errObj)
+	}
+	if quota.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", quota.StatusCode)
+	}
+	errObj, _ = mustDecodeJSON(t, quota.Body)["error"].(map[string]interface{})
+	if errObj["code"] != "insufficient_quota" || errObj["message"] != "You exceeded your current quota." {
+		t.Errorf("expected the configured error, got %v", errObj)
+	}
+	if other.StatusCode != http.StatusOK {
+		t.Errorf("expected 200 for an unmatched request, got %d", other.StatusCode)
+	}
+}
+
+func TestIntegration_Scenarios_ScriptSetsStatusAndHeaders(t *testing.T) {
+	// Given: a script failing requests without a tenant header and tagging the others
+	srv := newTestServerWithScenarios(t, Config{}, `
+scenarios:
+  - response:
+      script: |
+        {{if not (.Header "X-Tenant")}}{{.SetStatus 401}}{{end}}
+        {{.SetHeader "X-Tenant-Echo" (.Header "X-Tenant")}}
+        {{.SetContent (printf "hello %s" (.Header "X-Tenant"))}}
+`)
+	defer srv.Close()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+
+	// When
+	anonymous := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	defer func() { _ = anonymous.Body.Close() }()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant", "acme")
+	tenant, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tenant.Body.Close() }()
+
+	// Then
+	if anonymous.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a tenant, got %d", anonymous.StatusCode)
+	}
+	if tenant.StatusCode != http.StatusOK || tenant.Header.Get("X-Tenant-Echo") != "acme" {
+		t.Fatalf("expected 200 with the echoed tenant, got %d %v", tenant.StatusCode, tenant.Header)
+	}
+	var chat api.CreateChatCompletionResponse
+	if err := json.NewDecoder(tenant.Body).Decode(&chat); err != nil {
+		t.Fatal(err)
+	}
+	if got := chat.Choices[0].Message.Content.Value; got != "hello acme" {
+		t.Errorf("expected the script content, got %q", got)
+	}
+}
+
+func TestIntegration_Scenarios_MapSetsResponseFields(t *testing.T) {
+	// Given: a scenario mapping request fields into chat responses
+	srv := newTestServerWithScenarios(t, Config{}, `
+scenarios:
+  - response:
+      map:
+        - choices[0].message.content = request.messages[-1].content | upper
+        - system_fingerprint = request.user
+`)
+	defer srv.Close()
+	body := func(stream bool) string {
+		return fmt.Sprintf(`{"model":"gpt-4o","user":"fp_test","stream":%t,"messages":[{"role":"user","content":"hello"}]}`, stream)
+	}
+
+	// When: the request is sent with and without streaming
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", body(false))
+	defer func() { _ = resp.Body.Close() }()
+	streamResp := postJSON(t, srv.URL+"/v1/chat/completions", body(true))
+	defer func() { _ = streamResp.Body.Close() }()
+
+	// Then: the JSON response is mapped and still decodes, while the stream is left as generated
+	var chat api.CreateChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chat); err != nil {
+		t.Fatal(err)
+	}
+	if chat.Choices[0].Message.Content.Value != "HELLO" || chat.SystemFingerprint.Value != "fp_test" {
+		t.Errorf("expected the mapped fields, got %q and %q", chat.Choices[0].Message.Content.Value, chat.SystemFingerprint.Value)
+	}
+	if got := strings.Join(readStreamedContent(t, streamResp.Body), ""); got == "HELLO" {
+		t.Errorf("expected the stream to be unmapped, got %q", got)
+	}
+}
+
+func TestIntegration_ProcessingHeaders_IncludeScenarioLatency(t *testing.T) {
+	// Given: a scenario adding 80ms of latency to streaming requests
+	srv := newTestServerWithScenarios(t, Config{}, "scenarios:\n  - match: {message: slow}\n    response: {latency: 80ms}\n")
+	defer srv.Close()
+
+	// When: a slow streaming request and a plain request are sent
+	slow := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"slow"}]}`)
+	_ = readStreamedContent(t, slow.Body)
+	_ = slow.Body.Close()
+	fast, err := http.Get(srv.URL + "/v1/models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = fast.Body.Close()
+
+	// Then: openai-processing-ms accounts for the latency
+	slowMs, _ := strconv.Atoi(slow.Header.Get("openai-processing-ms"))
+	fastMs, err := strconv.Atoi(fast.Header.Get("openai-processing-ms"))
+	if slowMs < 80 || err != nil || fastMs >= 80 {
+		t.Errorf("expected >= 80ms and < 80ms, got %q and %q", slow.Header.Get("openai-processing-ms"), fast.Header.Get("openai-processing-ms"))
+	}
+	if slow.Header.Get("openai-version") != openAIVersion || fast.Header.Get("openai-version") != openAIVersion {
+		t.Errorf("expected openai-version on both responses")
+	}
+}
+
+func TestIntegration_Scenarios_PauseMarkersDelayStreamedContent(t *testing.T) {
+	// Given: scenario content pausing 150ms mid-sentence
+	srv := newTestServerWithScenarios(t, Config{}, "scenarios:\n  - response: {content: \"Hello,<<pause:150ms>> world\"}\n")
+	defer srv.Close()
+
+	// When: the content is streamed, then requested without streaming
+	start := time.Now()
+	stream := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	deltas := readStreamedContent(t, stream.Body)
+	_ = stream.Body.Close()
+	streamed := time.Since(start)
+	start = time.Now()
+	plain := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = plain.Body.Close() }()
+	var chat api.CreateChatCompletionResponse
+	if err := json.NewDecoder(plain.Body).Decode(&chat); err != nil {
+		t.Fatal(err)
+	}
+	waited := time.Since(start)
+
+	// Then: the stream is cut at the pause, and both responses leave the marker out and wait for it
+	if strings.Join(deltas, "|") != "Hello,| world" || streamed < 150*time.Millisecond {
+		t.Errorf("expected two chunks 150ms apart, got %q in %s", deltas, streamed)
+	}
+	if got := chat.Choices[0].Message.Content.Value; got != "Hello, world" || waited < 150*time.Millisecond {
+		t.Errorf("expected the content without the marker after 150ms, got %q in %s", got, waited)
+	}
+}
+
+// --- Images ---
+
+func TestIntegration_Admin_Requests_CapturesAPIRequests(t *testing.T) {
+	// Given: a non-streaming chat request, a streaming one, and an embeddings request
+	srv := newTestServer(t)
+	defer srv.Close()
+	for _, body := range []string{
+		`{"model":"gpt-4o","temperature":0.2,"messages":[{"role":"user","content":"first"}]}`,
+		`{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"second"}]}`,
+	} {
+		resp := postJSON(t, srv.URL+"/v1/chat/completions", body)
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+	resp := postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"third"}`)
+	_ = resp.Body.Close()
+
+	// When: chat requests are listed
+	listResp, err := http.Get(srv.URL + "/_mokku/requests?path=/v1/chat/completions")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	defer func() { _ = listResp.Body.Close() }()
+
+	// Then: both are listed newest first with their bodies but without response bodies
+	var list capturedRequestsResponse
+	if err := json.NewDecoder(listResp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Data) != 2 {
+		t.Fatalf("expected 2 chat requests, got %d", len(list.Data))
+	}
+	streamed, first := list.Data[0], list.Data[1]
+	if !streamed.Stream || first.Stream || first.Model != "gpt-4o" {
+		t.Errorf("unexpected entries %+v", list.Data)
+	}
+	var sent map[string]any
+	if err := json.Unmarshal(first.Body, &sent); err != nil || sent["temperature"] != 0.2 {
+		t.Errorf("expected the sent temperature, got %s", first.Body)
+	}
+	if first.Response.Status != http.StatusOK || first.Response.Body != nil {
+		t.Errorf("expected status without body, got %+v", first.Response)
+	}
+
+	// When: the streaming request is fetched
+	getResp, err := http.Get(fmt.Sprintf("%s/_mokku/requests/%d", srv.URL, streamed.ID))
+	if err != nil {
+		t.Fatalf("GET request: %v", err)
+	}
+	defer func() { _ = getResp.Body.Close() }()
+
+	// Then: it includes the streamed events
+	var full capturedExchange
+	if err := json.NewDecoder(getResp.Body).Decode(&full); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	var events string
+	if err := json.Unmarshal(full.Response.Body, &events); err != nil || !strings.Contains(events, "second") || !strings.HasSuffix(events, "data: [DONE]\n\n") {
+		t.Errorf("expected the streamed events, got %s", full.Response.Body)
+	}
+}
+
+func TestIntegration_RealisticStreaming_PacesAtFamilyCadence(t *testing.T) {
+	// Given: realistic streaming with standard models at 30ms to first token, 200 tokens per second,
+	// in chunks of 2 tokens
+	srv := newTestServerWithConfig(t, Config{
+		Features:         map[string]bool{flagRealisticStreaming: true},
+		StreamingCadence: []cadenceConfig{{Name: "standard", TimeToFirstToken: "30ms", TokensPerSecond: 200, ChunkTokens: 2}},
+	})
+	defer srv.Close()
+
+	// When: a 10-token answer is streamed
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"a b c d e f g h"}]}`)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	// Then: it arrives in 5 content chunks
+	if n := strings.Count(string(body), `"content":`); n != 5 {
+		t.Errorf("expected 5 content chunks, got %d in %s", n, body)
+	}
+
+	// When: the captured request is fetched
+	getResp, err := http.Get(srv.URL + "/_mokku/requests/1")
+	if err != nil {
+		t.Fatalf("GET request: %v", err)
+	}
+	defer func() { _ = getResp.Body.Close() }()
+
+	// Then: it reports the tokens streamed, the time to first token, and the rate achieved
+	var captured capturedExchange
+	if err := json.NewDecoder(getResp.Body).Decode(&captured); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	chunks := captured.Chunks
+	if chunks == nil || chunks.Tokens != 10 || chunks.TimeToFirstTokenMS < 30 {
+		t.Fatalf("expected 10 tokens after 30ms, got %+v", chunks)
+	}
+	if chunks.TokensPerSecond < 100 || chunks.TokensPerSecond > 210 {
+		t.Errorf("expected about 200 tokens per second, got %.1f", chunks.TokensPerSecond)
+	}
+}
+
+func TestIntegration_Admin_RequestBundle_CollectsEverythingAboutARequest(t *testing.T) {
+	// Given: a streamed request matching a scenario, with a baggage latency override and a trace
+	// propagated by the client
+	recordSpans(t)
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagBaggageOverrides: true}},
+		"scenarios:\n  - name: greeting\n    match: {message: hi}\n    response: {content: hello}\n")
+	defer srv.Close()
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions",
+		strings.NewReader(`{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Baggage", "mokku.latency=20ms")
+	req.Header.Set("Traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	// When
+	bundleResp, err := http.Get(srv.URL + "/_mokku/requests/1/bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = bundleResp.Body.Close() }()
+	missing, err := http.Get(srv.URL + "/_mokku/requests/2/bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = missing.Body.Close()
+
+	// Then: the bundle holds the request, the rule matched, the fault injected, the response with its
+	// chunks, and the trace
+	var bundle requestBundle
+	if err := json.NewDecoder(bundleResp.Body).Decode(&bundle); err != nil {
+		t.Fatal(err)
+	}
+	if bundle.Object != "mokku.request_bundle" || bundle.Instance != "test-instance" || bundle.Scenario != "greeting" {
+		t.Errorf("unexpected bundle %+v", bundle)
+	}
+	if !strings.Contains(string(bundle.Body), `"content":"hi"`) || bundle.Headers["Baggage"] != "mokku.latency=20ms" {
+		t.Errorf("expected the raw request, got %s %v", bundle.Body, bundle.Headers)
+	}
+	if len(bundle.Faults) != 1 || bundle.Faults[0].Name != "Baggage.override" || bundle.InjectedLatencyMS < 20 {
+		t.Fatalf("expected the baggage latency as the only fault, got %+v (%.1fms)", bundle.Faults, bundle.InjectedLatencyMS)
+	}
+	if bundle.Response.Headers[seedHeader] == "" || bundle.Chunks == nil || bundle.Chunks.Count == 0 {
+		t.Errorf("expected the response headers and chunk timings, got %+v %+v", bundle.Response.Headers, bundle.Chunks)
+	}
+	if bundle.Trace == nil || bundle.Trace.TraceID != traceID || bundle.Trace.SpanID == "" || bundle.Faults[0].SpanID == "" {
+		t.Errorf("expected trace %s, got %+v", traceID, bundle.Trace)
+	}
+	if missing.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 for a request not captured, got %d", missing.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_Requests_ExportAndReset(t *testing.T) {
+	// Given: two captured requests
+	srv := newTestServer(t)
+	defer srv.Close()
+	for _, input := range []string{"one", "two"} {
+		resp := postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"`+input+`"}`)
+		_ = resp.Body.Close()
+	}
+
+	// When: they are exported as a traffic log
+	exportResp, err := http.Get(srv.URL + "/_mokku/requests?format=jsonl")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	reqs, err := readCapturedRequests(exportResp.Body)
+	_ = exportResp.Body.Close()
+
+	// Then: replay-load reads them oldest first
+	if err != nil || len(reqs) != 2 || !strings.Contains(string(reqs[0].Body), "one") {
+		t.Fatalf("expected 2 requests oldest first, got %+v, %v", reqs, err)
+	}
+
+	// When: they are exported as a Postman collection
+	postmanResp, err := http.Get(srv.URL + "/_mokku/requests?format=postman")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	var collection postmanCollection
+	err = json.NewDecoder(postmanResp.Body).Decode(&collection)
+	_ = postmanResp.Body.Close()
+
+	// Then: the requests are sent to this server, oldest first
+	if err != nil || len(collection.Item) != 2 || !strings.Contains(collection.Item[0].Request.Body.Raw, "one") ||
+		collection.Variable[0].Value != srv.URL {
+		t.Fatalf("expected 2 requests oldest first for %s, got %+v, %v", srv.URL, collection, err)
+	}
+
+	// When: the capture is reset
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/requests", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE requests: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// Then: nothing is listed and fetching an old ID is a 404
+	if resp.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 204, got %d", resp.StatusCode)
+	}
+	listResp, err := http.Get(srv.URL + "/_mokku/requests")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	defer func() { _ = listResp.Body.Close() }()
+	if data := mustDecodeJSON(t, listResp.Body)["data"].([]interface{}); len(data) != 0 {
+		t.Errorf("expected no requests, got %v", data)
+	}
+	getResp, err := http.Get(srv.URL + "/_mokku/requests/1")
+	if err != nil {
+		t.Fatalf("GET request: %v", err)
+	}
+	_ = getResp.Body.Close()
+	if getResp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", getResp.StatusCode)
+	}
+}
+
+func TestIntegration_Regions_ConfiguredOutageFailsRegionRequests(t *testing.T) {
+	// Given: eu is down and us is healthy
+	srv := newTestServerWithConfig(t, Config{Regions: []regionConfig{{Name: "eu", Status: 503}, {Name: "us"}}})
+	defer srv.Close()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+	send := func(region string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if region != "" {
+			req.Header.Set(regionHeader, region)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		return resp
+	}
+
+	// When: a request is sent to eu
+	resp := send("eu")
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: it fails with an OpenAI error naming the region
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get(regionHeader) != "eu" {
+		t.Errorf("expected 503 from eu, got %d %q", resp.StatusCode, resp.Header.Get(regionHeader))
+	}
+	if errBody := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{}); errBody["type"] != "server_error" {
+		t.Errorf("expected a server_error, got %v", errBody)
+	}
+
+	// Then: us and requests without a region are served
+	for _, region := range []string{"us", ""} {
+		resp := send(region)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("region %q: expected 200, got %d", region, resp.StatusCode)
+		}
+	}
+}
+
+func TestIntegration_Admin_Regions_SetAtRuntime(t *testing.T) {
+	// Given: a healthy us region
+	srv := newTestServerWithConfig(t, Config{Regions: []regionConfig{{Name: "us"}}})
+	defer srv.Close()
+
+	// When: us is taken down through the admin API
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/_mokku/regions/us", strings.NewReader(`{"status":429}`))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT region: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	// Then: us requests fail with the new status
+	apiReq, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"x"}`))
+	apiReq.Header.Set("Content-Type", "application/json")
+	apiReq.Header.Set(regionHeader, "us")
+	apiResp, err := http.DefaultClient.Do(apiReq)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	_ = apiResp.Body.Close()
+	if apiResp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", apiResp.StatusCode)
+	}
+
+	// Then: the region list reports the change and the failure
+	listResp, err := http.Get(srv.URL + "/_mokku/regions")
+	if err != nil {
+		t.Fatalf("GET regions: %v", err)
+	}
+	defer func() { _ = listResp.Body.Close() }()
+	var list regionsResponse
+	if err := json.NewDecoder(listResp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(list.Data) != 1 || list.Data[0].Source != regionSourceAdmin || list.Data[0].Requests != 1 || list.Data[0].Failures != 1 {
+		t.Errorf("unexpected regions %+v", list.Data)
+	}
+
+	// When / Then: an invalid region is rejected
+	req, _ = http.NewRequest(http.MethodPut, srv.URL+"/_mokku/regions/us", strings.NewReader(`{"error_rate":2}`))
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT region: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Chaos_ConfiguredProfileFailsRequests(t *testing.T) {
+	// Given: an outage profile active at startup
+	srv := newTestServerWithConfig(t, Config{Chaos: chaosConfig{
+		Profile:  "outage",
+		Profiles: []chaosProfileConfig{{Name: "outage", ErrorRate: 1, ErrorStatus: 503}},
+	}})
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"x"}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the request fails with an OpenAI error naming the profile
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get(chaosHeader) != "outage" {
+		t.Errorf("expected 503 from outage, got %d %q", resp.StatusCode, resp.Header.Get(chaosHeader))
+	}
+	if errBody := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{}); errBody["type"] != "server_error" {
+		t.Errorf("expected a server_error, got %v", errBody)
+	}
+
+	// When: chaos is turned off
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/chaos", nil)
+	stopResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE chaos: %v", err)
+	}
+	_ = stopResp.Body.Close()
+
+	// Then: requests succeed again
+	resp = postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"x"}`)
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(chaosHeader) != "" {
+		t.Errorf("expected 200 without chaos, got %d %q", resp.StatusCode, resp.Header.Get(chaosHeader))
+	}
+}
+
+func TestIntegration_Scenarios_StrictModeRejectsUnmatchedRequests(t *testing.T) {
+	// Given: strict scenarios
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagStrictScenarios: true}}, testScenarios)
+	defer srv.Close()
+
+	// When: a request differing from the weather scenario in its message, and a matching request
+	miss := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"rain in Oslo"}]}`)
+	defer func() { _ = miss.Body.Close() }()
+	hit := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"weather in Oslo"}]}`)
+	_ = hit.Body.Close()
+
+	// Then: the miss gets the unexpected status with the diff against the closest scenario
+	if miss.StatusCode != unexpectedStatus {
+		t.Fatalf("expected %d, got %d", unexpectedStatus, miss.StatusCode)
+	}
+	var body unmatchedError
+	if err := json.NewDecoder(miss.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.Code != "scenario_not_matched" || body.Mokku.Closest == nil || *body.Mokku.Closest != "weather" {
+		t.Errorf("expected the weather scenario as the closest, got %+v", body)
+	}
+	if len(body.Mokku.Diff) != 1 || body.Mokku.Diff[0].Field != "message" || body.Mokku.Diff[0].Actual != "rain in Oslo" {
+		t.Errorf("expected a message diff, got %+v", body.Mokku.Diff)
+	}
+	if hit.StatusCode != http.StatusOK {
+		t.Errorf("expected the matching request to succeed, got %d", hit.StatusCode)
+	}
+
+	// Then: verification fails with the miss until it is reset
+	verify := func() verification {
+		resp, err := http.Get(srv.URL + "/_mokku/verify")
+		if err != nil {
+			t.Fatalf("GET verify: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		var v verification
+		if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if v := verify(); v.Passed || v.UnexpectedRequests != 1 || len(v.Recent) != 1 || v.Recent[0].Path != "/v1/chat/completions" || *v.Recent[0].Closest != "weather" {
+		t.Errorf("expected one unexpected request, got %+v", v)
+	}
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/verify", nil)
+	reset, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = reset.Body.Close()
+	if v := verify(); reset.StatusCode != http.StatusNoContent || !v.Passed || v.UnexpectedRequests != 0 {
+		t.Errorf("expected a passing verification after reset, got %d %+v", reset.StatusCode, v)
+	}
+}
+
+func TestIntegration_Scenarios_StrictModeWithoutRulesRejectsEveryMethod(t *testing.T) {
+	// Given: strict scenarios without any rule
+	srv := newTestServerWithConfig(t, Config{Features: map[string]bool{flagStrictScenarios: true}})
+	defer srv.Close()
+
+	// When: a chat completion is posted and the models are listed
+	chat := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	_ = chat.Body.Close()
+	models, err := http.Get(srv.URL + "/v1/models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = models.Body.Close() }()
+
+	// Then: both are unexpected, with no closest scenario, and counted
+	if chat.StatusCode != unexpectedStatus || models.StatusCode != unexpectedStatus {
+		t.Fatalf("expected %d for both, got %d and %d", unexpectedStatus, chat.StatusCode, models.StatusCode)
+	}
+	var body unmatchedError
+	if err := json.NewDecoder(models.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Error.Message != "No scenario matches GET /v1/models." || body.Mokku.Closest != nil {
+		t.Errorf("expected an unmatched GET without a closest scenario, got %+v", body)
+	}
+	resp, err := http.Get(srv.URL + "/_mokku/verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if v := mustDecodeJSON(t, resp.Body); v["unexpected_requests"] != float64(2) {
+		t.Errorf("expected two unexpected requests, got %v", v)
+	}
+}
+
+func TestIntegration_Scenarios_StrictModeLetsMatchedGetRequestsThrough(t *testing.T) {
+	// Given: strict scenarios with a rule expecting the models list
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagStrictScenarios: true}},
+		"scenarios:\n  - name: models\n    match:\n      path: /v1/models\n    response:\n      content: unused\n")
+	defer srv.Close()
+
+	// When
+	resp, err := http.Get(srv.URL + "/v1/models")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the generated list is served
+	if resp.StatusCode != http.StatusOK || mustDecodeJSON(t, resp.Body)["object"] != "list" {
+		t.Errorf("expected the models list, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_Evaluate_ReportsMatchWithoutSending(t *testing.T) {
+	// Given
+	srv := newTestServerWithScenarios(t, Config{}, testScenarios)
+	defer srv.Close()
+	evaluate := func(body string) *http.Response {
+		resp, err := http.Post(srv.URL+"/_mokku/evaluate", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST evaluate: %v", err)
+		}
+		return resp
+	}
+
+	// When: a candidate request with the overload header is evaluated
+	resp := evaluate(`{"path":"/v1/embeddings","headers":{"X-Test-Case":"overload"},"body":{"model":"text-embedding-3-small","input":"hi"}}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the overload rule would answer with a 503, and the rules before it explain the mismatch
+	var eval scenarioEvaluation
+	if err := json.NewDecoder(resp.Body).Decode(&eval); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || eval.Matched == nil || *eval.Matched != "overloaded" || eval.Response.Status != 503 {
+		t.Fatalf("expected the overloaded rule, got %d %+v", resp.StatusCode, eval)
+	}
+	if eval.Trace[0].Matched || len(eval.Trace[0].Reasons) == 0 {
+		t.Errorf("expected the first rule to explain its mismatch, got %+v", eval.Trace[0])
+	}
+	captured, err := http.Get(srv.URL + "/_mokku/requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = captured.Body.Close() }()
+	if list := mustDecodeJSON(t, captured.Body); len(list["data"].([]interface{})) != 0 {
+		t.Errorf("expected no captured request, got %v", list["data"])
+	}
+
+	// When / Then: a non-API path is rejected
+	bad := evaluate(`{"path":"/healthz"}`)
+	_ = bad.Body.Close()
+	if bad.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", bad.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_Scenarios_ReplaceAtomically(t *testing.T) {
+	// Given: the test scenarios
+	srv := newTestServerWithScenarios(t, Config{}, testScenarios)
+	defer srv.Close()
+	replace := func(body string) (*http.Response, map[string]interface{}) {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/_mokku/scenarios", strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PUT scenarios: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		return resp, mustDecodeJSON(t, resp.Body)
+	}
+	chat := func() string {
+		resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}]}`)
+		defer func() { _ = resp.Body.Close() }()
+		return getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})["message"].(map[string]interface{})["content"].(string)
+	}
+
+	// When: a set with an invalid rule is sent
+	bad, _ := replace(`{"scenarios":[{"name":"greeting","response":{"content":"hi there"}},{"name":"broken","response":{"status":99}}]}`)
+
+	// Then: it is rejected and the test scenarios keep answering
+	if bad.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", bad.StatusCode)
+	}
+	if content := chat(); content != "Echo: hello" {
+		t.Errorf("expected the echo, got %q", content)
+	}
+
+	// When: a valid YAML set is sent
+	ok, list := replace("scenarios:\n  - name: greeting\n    response:\n      content: hi there\n")
+
+	// Then: it replaces every rule
+	if ok.StatusCode != http.StatusOK || fmt.Sprint(list["data"]) != "[greeting]" {
+		t.Fatalf("expected the greeting rule, got %d %v", ok.StatusCode, list)
+	}
+	if content := chat(); content != "hi there" {
+		t.Errorf("expected the greeting content, got %q", content)
+	}
+	resp, err := http.Get(srv.URL + "/_mokku/scenarios")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if got := mustDecodeJSON(t, resp.Body); fmt.Sprint(got["data"]) != "[greeting]" {
+		t.Errorf("expected the greeting rule to be listed, got %v", got)
+	}
+}
+
+func TestIntegration_Admin_Scenarios_DeleteAndRestore(t *testing.T) {
+	// Given: the test scenarios
+	srv := newTestServerWithScenarios(t, Config{}, testScenarios)
+	defer srv.Close()
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest(method, srv.URL+"/_mokku"+path, strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode == http.StatusNotFound {
+			return resp.StatusCode, nil
+		}
+		return resp.StatusCode, mustDecodeJSON(t, resp.Body)
+	}
+	weather := func() string {
+		resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"weather in Tokyo"}]}`)
+		defer func() { _ = resp.Body.Close() }()
+		return getChoices(t, mustDecodeJSON(t, resp.Body))[0].(map[string]interface{})["message"].(map[string]interface{})["content"].(string)
+	}
+
+	// When: the weather rule is deleted
+	status, deleted := do(http.MethodDelete, "/scenarios/weather", "")
+
+	// Then: it stops matching and is listed as restorable from its position
+	if status != http.StatusOK || deleted["name"] != "weather" || deleted["position"] != float64(0) || deleted["reason"] != "deleted" {
+		t.Fatalf("expected the deleted weather rule, got %d %v", status, deleted)
+	}
+	if content := weather(); content != "Echo: weather in Tokyo" {
+		t.Errorf("expected the echo, got %q", content)
+	}
+	_, list := do(http.MethodGet, "/scenarios/deleted", "")
+	if data := list["data"].([]interface{}); len(data) != 1 || data[0].(map[string]interface{})["name"] != "weather" {
+		t.Errorf("expected the weather rule in the trash, got %v", list)
+	}
+
+	// When: it is restored
+	status, names := do(http.MethodPost, "/scenarios/weather/restore", "")
+
+	// Then: it is back first in match order and matches again
+	if status != http.StatusOK || fmt.Sprint(names["data"]) != "[weather overloaded no-quota]" {
+		t.Fatalf("expected the rules in their order, got %d %v", status, names)
+	}
+	if content := weather(); content != "It is sunny. (gpt-4o: weather in Tokyo)" {
+		t.Errorf("expected the weather content, got %q", content)
+	}
+	if status, _ := do(http.MethodPost, "/scenarios/missing/restore", ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 restoring a rule never deleted, got %d", status)
+	}
+
+	// When: a set leaving out the weather rule but with another rule named weather is restored over
+	do(http.MethodPut, "/scenarios", "scenarios:\n  - name: overloaded\n    response:\n      status: 503\n")
+	do(http.MethodPut, "/scenarios", "scenarios:\n  - name: weather\n    response:\n      content: rain\n")
+	status, conflict := do(http.MethodPost, "/scenarios/overloaded/restore", "")
+
+	// Then: the rule left out by the replacement is restorable, and restoring an active name conflicts
+	if status != http.StatusOK {
+		t.Errorf("expected the replaced overloaded rule to be restored, got %d %v", status, conflict)
+	}
+	if status, body := do(http.MethodPost, "/scenarios/weather/restore", ""); status != http.StatusConflict {
+		t.Errorf("expected 409 restoring an active name, got %d %v", status, body)
+	}
+	if status, _ := do(http.MethodDelete, "/scenarios/missing", ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 deleting a missing rule, got %d", status)
+	}
+}
+
+func TestIntegration_Admin_Chaos_ActivatesProfileAtRuntime(t *testing.T) {
+	// Given: profiles that rate limit and truncate every request
+	srv := newTestServerWithConfig(t, Config{Chaos: chaosConfig{Profiles: []chaosProfileConfig{
+		{Name: "throttled", RateLimitRate: 1},
+		{Name: "cut", TruncateRate: 1},
+	}}})
+	defer srv.Close()
+	activate := func(body string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPut, srv.URL+"/_mokku/chaos", strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PUT chaos: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+
+	// When: throttled is activated
+	if resp := activate(`{"profile":"throttled"}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	_ = resp.Body.Close()
+
+	// Then: requests are rejected with 429 and Retry-After
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" {
+		t.Errorf("expected 429 with Retry-After, got %d %q", resp.StatusCode, resp.Header.Get("Retry-After"))
+	}
+
+	// When: cut is activated and a stream is requested
+	activate(`{"profile":"cut"}`)
+	resp = postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+
+	// Then: the connection drops before finish_reason and [DONE]
+	if err == nil || strings.Contains(string(body), "[DONE]") || strings.Contains(string(body), "finish_reason\":\"stop") {
+		t.Errorf("expected a truncated stream, got %v: %q", err, body)
+	}
+	statusResp, err := http.Get(srv.URL + "/_mokku/chaos")
+	if err != nil {
+		t.Fatalf("GET chaos: %v", err)
+	}
+	defer func() { _ = statusResp.Body.Close() }()
+	var status chaosStatus
+	if err := json.NewDecoder(statusResp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.Active != "cut" || status.Source != chaosSourceAdmin || status.Stats.Truncated != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// When / Then: an unknown profile is rejected
+	if resp := activate(`{"profile":"meltdown"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Admin_Timeline_ExportsInjectedFaults(t *testing.T) {
+	// Given: an outage profile active at startup and eu taken down at runtime
+	srv := newTestServerWithConfig(t, Config{Chaos: chaosConfig{
+		Profile:  "outage",
+		Profiles: []chaosProfileConfig{{Name: "outage", ErrorRate: 1, ErrorStatus: 503}},
+	}})
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPut, srv.URL+"/_mokku/regions/eu", strings.NewReader(`{"status":502}`))
+	regionResp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("PUT region: %v", err)
+	}
+	_ = regionResp.Body.Close()
+
+	// When: a request fails while both are active
+	resp := postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"x"}`)
+	_ = resp.Body.Close()
+	failedAt := time.Now().UTC().Format(time.RFC3339Nano)
+	timelineResp, err := http.Get(srv.URL + "/_mokku/timeline?at=" + failedAt)
+	if err != nil {
+		t.Fatalf("GET timeline: %v", err)
+	}
+	defer func() { _ = timelineResp.Body.Close() }()
+
+	// Then: the faults active at the time of the failure are exported with the injected error
+	var timeline timelineResponse
+	if err := json.NewDecoder(timelineResp.Body).Decode(&timeline); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(timeline.Data) != 2 {
+		t.Fatalf("expected the chaos and eu periods, got %+v", timeline.Data)
+	}
+	chaos, eu := timeline.Data[0], timeline.Data[1]
+	if chaos.Kind != faultKindChaos || chaos.Name != "outage" || chaos.End != nil || chaos.Stats.Errors != 1 {
+		t.Errorf("unexpected chaos period %+v", chaos)
+	}
+	if eu.Kind != faultKindRegion || eu.Name != "eu" || eu.Region.Status != http.StatusBadGateway {
+		t.Errorf("unexpected eu period %+v", eu)
+	}
+
+	// When / Then: an invalid instant is rejected
+	badResp, err := http.Get(srv.URL + "/_mokku/timeline?at=yesterday")
+	if err != nil {
+		t.Fatalf("GET timeline: %v", err)
+	}
+	_ = badResp.Body.Close()
+	if badResp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", badResp.StatusCode)
+	}
+}
+
+func TestIntegration_Seeds_PinnedSeedReproducesChaos(t *testing.T) {
+	// Given: a profile failing half of the requests
+	srv := newTestServerWithConfig(t, Config{Chaos: chaosConfig{
+		Profile:  "coin",
+		Profiles: []chaosProfileConfig{{Name: "coin", ErrorRate: 0.5}},
+	}})
+	defer srv.Close()
+	send := func(seed string) (int, string) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"x"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if seed != "" {
+			req.Header.Set(seedHeader, seed)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode, resp.Header.Get(seedHeader)
+	}
+	statuses := map[string]int{}
+	for range 20 {
+		status, seed := send("")
+		if seed == "" {
+			t.Fatal("expected a seed header")
+		}
+		statuses[seed] = status
+	}
+
+	// When: every request is resent with the seed it reported
+	// Then: it meets the same outcome
+	failures := 0
+	for seed, want := range statuses {
+		if got, echoed := send(seed); got != want || echoed != seed {
+			t.Errorf("seed %s: expected %d, got %d (seed %s)", seed, want, got, echoed)
+		}
+		if want != http.StatusOK {
+			failures++
+		}
+	}
+	if failures == 0 || failures == len(statuses) {
+		t.Errorf("expected some of %d requests to fail, got %d", len(statuses), failures)
+	}
+
+	// When / Then: an invalid seed is rejected
+	if status, _ := send("-1"); status != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", status)
+	}
+}
+
+func TestIntegration_Seeds_CapturedWithRequests(t *testing.T) {
+	// Given: a request with a pinned seed
+	srv := newTestServer(t)
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"x"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(seedHeader, "1234")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// When: the capture is listed and exported
+	listResp, err := http.Get(srv.URL + "/_mokku/requests")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	var list capturedRequestsResponse
+	err = json.NewDecoder(listResp.Body).Decode(&list)
+	_ = listResp.Body.Close()
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	exportResp, err := http.Get(srv.URL + "/_mokku/requests?format=jsonl")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	reqs, err := readCapturedRequests(exportResp.Body)
+	_ = exportResp.Body.Close()
+
+	// Then: the seed is recorded and pinned in the exported traffic log for replay-load
+	if len(list.Data) != 1 || list.Data[0].Seed != 1234 {
+		t.Errorf("expected seed 1234, got %+v", list.Data)
+	}
+	if err != nil || len(reqs) != 1 || reqs[0].Headers[seedHeader] != "1234" {
+		t.Errorf("expected the seed header in the export, got %+v, %v", reqs, err)
+	}
+}
+
+func TestIntegration_Admin_Streams_CountsCompletedStreams(t *testing.T) {
+	// Given: a completed streaming request
+	srv := newTestServer(t)
+	defer srv.Close()
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	_ = readStreamedContent(t, resp.Body)
+	_ = resp.Body.Close()
+
+	// When
+	statsResp, err := http.Get(srv.URL + "/_mokku/streams")
+	if err != nil {
+		t.Fatalf("GET streams: %v", err)
+	}
+	defer func() { _ = statsResp.Body.Close() }()
+
+	// Then: one stream started and completed, none cancelled
+	stats := mustDecodeJSON(t, statsResp.Body)
+	if stats["started"] != float64(1) || stats["completed"] != float64(1) || stats["cancelled"] != float64(0) {
+		t.Errorf("unexpected stream stats: %v", stats)
+	}
+}
+
+func TestIntegration_Admin_Connections_ReportsReusedConnections(t *testing.T) {
+	// Given: a client that sends three streaming requests over one pooled connection
+	srv := newTestServer(t)
+	defer srv.Close()
+	transport := &http.Transport{MaxConnsPerHost: 1}
+	defer transport.CloseIdleConnections()
+	client := &http.Client{Transport: transport}
+	for range 3 {
+		resp, err := client.Post(srv.URL+"/v1/chat/completions", "application/json",
+			strings.NewReader(`{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`))
+		if err != nil {
+			t.Fatalf("POST: %v", err)
+		}
+		_ = readStreamedContent(t, resp.Body)
+		_ = resp.Body.Close()
+	}
+
+	// When: the stats and the captured requests are read over a new connection
+	statsResp, err := http.Get(srv.URL + "/_mokku/connections")
+	if err != nil {
+		t.Fatalf("GET connections: %v", err)
+	}
+	defer func() { _ = statsResp.Body.Close() }()
+	capturedResp, err := http.Get(srv.URL + "/_mokku/requests?limit=1")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	defer func() { _ = capturedResp.Body.Close() }()
+
+	// Then: the pooled connection served every stream, and the last stream is recorded as its third request
+	stats := mustDecodeJSON(t, statsResp.Body)
+	if stats["opened"] != float64(2) || stats["requests"] != float64(4) || stats["reused_requests"] != float64(2) {
+		t.Errorf("unexpected connection stats: %v", stats)
+	}
+	open, _ := stats["open"].([]interface{})
+	if len(open) == 0 || open[0].(map[string]interface{})["requests"] != float64(3) {
+		t.Errorf("expected the first connection to serve 3 requests, got %v", open)
+	}
+	data, _ := mustDecodeJSON(t, capturedResp.Body)["data"].([]interface{})
+	if len(data) != 1 {
+		t.Fatalf("expected one captured request, got %v", data)
+	}
+	conn, _ := data[0].(map[string]interface{})["connection"].(map[string]interface{})
+	if conn["request"] != float64(3) || conn["reused"] != true || conn["proto"] != "HTTP/1.1" {
+		t.Errorf("unexpected captured connection %v", conn)
+	}
+}
+
+func TestIntegration_Images_B64JSON(t *testing.T) {
+	// Given: an image request with b64_json output
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"prompt":"a lighthouse at dusk","size":"256x256","n":2,"response_format":"b64_json"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/images/generations", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: two decodable PNGs, the first identical to the deterministic rendering
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	data, ok := result["data"].([]interface{})
+	if !ok || len(data) != 2 {
+		t.Fatalf("expected 2 images, got %v", result["data"])
+	}
+	b64, _ := data[0].(map[string]interface{})["b64_json"].(string)
+	decoded, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		t.Fatalf("expected valid base64: %v", err)
+	}
+	if !bytes.Equal(decoded, generateImagePNG("a lighthouse at dusk", 0, 256, 256)) {
+		t.Error("expected image to match the prompt-derived rendering")
+	}
+}
+
+func TestIntegration_Images_URLIsServed(t *testing.T) {
+	// Given: an image request with the default url output
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"prompt":"a lighthouse at dusk","size":"256x256","model":"dall-e-3"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/images/generations", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the URL serves a PNG and dall-e-3 includes a revised prompt
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	data, ok := result["data"].([]interface{})
+	if !ok || len(data) != 1 {
+		t.Fatalf("expected 1 image, got %v", result["data"])
+	}
+	item := data[0].(map[string]interface{})
+	if revised, _ := item["revised_prompt"].(string); revised != "a lighthouse at dusk" {
+		t.Errorf("expected revised_prompt, got %q", revised)
+	}
+	url, _ := item["url"].(string)
+	if !strings.HasPrefix(url, srv.URL+"/_mokku/images/") {
+		t.Fatalf("expected image URL on the mock server, got %q", url)
+	}
+	imgResp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer func() { _ = imgResp.Body.Close() }()
+	if imgResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for image URL, got %d", imgResp.StatusCode)
+	}
+	if ct := imgResp.Header.Get("Content-Type"); ct != "image/png" {
+		t.Errorf("expected Content-Type image/png, got %q", ct)
+	}
+}
+
+func TestIntegration_Images_URLExpiresWhenTheClockIsAdvanced(t *testing.T) {
+	// Given: an image URL
+	srv := newTestServer(t)
+	defer srv.Close()
+	resp := postJSON(t, srv.URL+"/v1/images/generations", `{"model":"dall-e-2","prompt":"a clock","size":"256x256"}`)
+	result := mustDecodeJSON(t, resp.Body)
+	_ = resp.Body.Close()
+	url, _ := result["data"].([]interface{})[0].(map[string]interface{})["url"].(string)
+	fetch := func() int {
+		imgResp, err := http.Get(url)
+		if err != nil {
+			t.Fatalf("GET %s: %v", url, err)
+		}
+		_ = imgResp.Body.Close()
+		return imgResp.StatusCode
+	}
+	if !strings.Contains(url, "?se=") {
+		t.Errorf("expected the URL to carry its expiry, got %q", url)
+	}
+
+	// When: the virtual clock passes the hour the URL is valid for
+	fresh := fetch()
+	advance := postJSON(t, srv.URL+"/_mokku/clock/advance", `{"seconds":3600}`)
+	status := mustDecodeJSON(t, advance.Body)
+	_ = advance.Body.Close()
+	expired := fetch()
+
+	// Then
+	if fresh != http.StatusOK || expired != http.StatusForbidden {
+		t.Errorf("expected 200 then 403, got %d and %d", fresh, expired)
+	}
+	if advance.StatusCode != http.StatusOK || status["offset_seconds"] != 3600.0 {
+		t.Errorf("expected a 3600s offset, got %d %v", advance.StatusCode, status)
+	}
+
+	// When: the clock is reset
+	req, _ := http.NewRequest(http.MethodDelete, srv.URL+"/_mokku/clock", nil)
+	reset, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = reset.Body.Close()
+
+	// Then: the URL is valid again
+	if got := fetch(); got != http.StatusOK {
+		t.Errorf("expected 200 after the reset, got %d", got)
+	}
+}
+
+func TestIntegration_Admin_Clock_RejectsInvalidAdvance(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/_mokku/clock/advance", `{"seconds":-5}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Images_InvalidSize(t *testing.T) {
+	// Given: an unsupported size
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/images/generations", `{"prompt":"x","size":"100x100"}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 400 Bad Request
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Images_GPTImageUsage(t *testing.T) {
+	// Given: a gpt-image-1 request with explicit quality, size, and background
+	srv := newTestServer(t)
+	defer srv.Close()
+	body := `{"model":"gpt-image-1","prompt":"a red fox","n":2,"quality":"low","size":"1536x1024","background":"transparent"}`
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/images/generations", body)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: b64_json images plus token usage and the resolved parameters
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	data, _ := result["data"].([]interface{})
+	if len(data) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(data))
+	}
+	if b64, _ := data[0].(map[string]interface{})["b64_json"].(string); b64 == "" {
+		t.Error("expected b64_json for GPT image models")
+	}
+	for field, want := range map[string]string{"quality": "low", "size": "1536x1024", "background": "transparent", "output_format": "png"} {
+		if got, _ := result[field].(string); got != want {
+			t.Errorf("expected %s=%q, got %q", field, want, got)
+		}
+	}
+	usage, ok := result["usage"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected usage object")
+	}
+	textTokens := countTokens("a red fox")
+	if got, _ := usage["input_tokens"].(float64); int(got) != textTokens {
+		t.Errorf("expected input_tokens=%d, got %v", textTokens, usage["input_tokens"])
+	}
+	if got, _ := usage["output_tokens"].(float64); int(got) != 2*400 {
+		t.Errorf("expected output_tokens=800, got %v", usage["output_tokens"])
+	}
+	if got, _ := usage["total_tokens"].(float64); int(got) != textTokens+800 {
+		t.Errorf("expected total_tokens=%d, got %v", textTokens+800, usage["total_tokens"])
+	}
+	details, _ := usage["input_tokens_details"].(map[string]interface{})
+	if got, _ := details["text_tokens"].(float64); int(got) != textTokens {
+		t.Errorf("expected text_tokens=%d, got %v", textTokens, details["text_tokens"])
+	}
+}
+
+func TestIntegration_Images_DallEHasNoUsage(t *testing.T) {
+	// Given: a DALL·E request
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/images/generations", `{"model":"dall-e-2","prompt":"x","size":"256x256","response_format":"b64_json"}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: per-image pricing, so no usage block
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	if _, ok := result["usage"]; ok {
+		t.Error("expected no usage for DALL·E models")
+	}
+}
+
+func TestIntegration_Images_GPTImageUnsupportedSize(t *testing.T) {
+	// Given: a GPT image request with a DALL·E-only size
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/images/generations", `{"model":"gpt-image-1","prompt":"x","size":"256x256"}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: 400 in the OpenAI error format
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", resp.StatusCode)
+	}
+	result := mustDecodeJSON(t, resp.Body)
+	errObj, ok := result["error"].(map[string]interface{})
+	if !ok {
+		t.Fatal("expected error object in response")
+	}
+	if param, _ := errObj["param"].(string); param != "size" {
+		t.Errorf("expected param=size, got %q", param)
+	}
+}
+
+// --- Concurrency ---
+
+func TestIntegration_Concurrent_SharedStateStaysConsistent(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping concurrency stress test in short mode")
+	}
+	// Given: a server hit concurrently by streams, embeddings, images, and admin calls
+	srv := newTestServer(t)
+	defer srv.Close()
+	const workers = 16
+	const iterations = 10
+
+	// When
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				input := fmt.Sprintf("worker %d request %d", w, i)
+				requests := []struct{ path, body string }{
+					{"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"` + input + `"}]}`},
+					{"/v1/completions", `{"model":"mokku-lorem-1","stream":true,"prompt":"` + input + `"}`},
+					{"/v1/embeddings", `{"model":"text-embedding-3-small","input":"` + input + `"}`},
+					{"/v1/images/generations", `{"prompt":"` + input + `","size":"256x256"}`},
+					{"/_mokku/embeddings/search", `{"query":"` + input + `","top_k":3}`},
+				}
+				for _, req := range requests {
+					resp := postJSON(t, srv.URL+req.path, req.body)
+					_, _ = io.Copy(io.Discard, resp.Body)
+					_ = resp.Body.Close()
+					if resp.StatusCode != http.StatusOK {
+						t.Errorf("POST %s: expected 200, got %d", req.path, resp.StatusCode)
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: every stream is accounted for exactly once
+	resp, err := http.Get(srv.URL + "/_mokku/streams")
+	if err != nil {
+		t.Fatalf("GET streams: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	stats := mustDecodeJSON(t, resp.Body)
+	started, _ := stats["started"].(float64)
+	completed, _ := stats["completed"].(float64)
+	cancelled, _ := stats["cancelled"].(float64)
+	if started != 2*workers*iterations || completed+cancelled != started {
+		t.Errorf("inconsistent stream stats: %v", stats)
+	}
+}
+
+func TestIntegration_ColdStart_DelaysFirstRequestToModel(t *testing.T) {
+	// Given: gpt-4o with a cold start
+	srv := newTestServerWithConfig(t, Config{ColdStart: map[string]coldStartConfig{"gpt-4o": {Latency: "100ms"}}})
+	defer srv.Close()
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+
+	// When
+	start := time.Now()
+	cold := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	coldElapsed := time.Since(start)
+	_ = cold.Body.Close()
+	warm := postJSON(t, srv.URL+"/v1/chat/completions", body)
+	_ = warm.Body.Close()
+
+	// Then: only the first request is delayed and reports its cold start
+	if cold.StatusCode != http.StatusOK || cold.Header.Get(coldStartHeader) != "100" || coldElapsed < 100*time.Millisecond {
+		t.Errorf("expected a delayed 200 with %s: 100, got %d %q after %v", coldStartHeader, cold.StatusCode, cold.Header.Get(coldStartHeader), coldElapsed)
+	}
+	if warm.StatusCode != http.StatusOK || warm.Header.Get(coldStartHeader) != "" {
+		t.Errorf("expected a warm 200, got %d %q", warm.StatusCode, warm.Header.Get(coldStartHeader))
+	}
+}
+
+func TestIntegration_Proxies_ForwardedClientIPDrivesRateLimitsAndAudit(t *testing.T) {
+	// Given: the test client as a trusted proxy and a default budget of one request per client IP
+	srv := newTestServerWithConfig(t, Config{
+		Proxies:    proxyConfig{Trusted: []string{"127.0.0.1"}},
+		RateLimits: rateLimitConfig{Default: &rateLimitBudget{RequestsPerMinute: 1}, DefaultScope: rateLimitScopeIP},
+	})
+	defer srv.Close()
+	do := func(method, path, forwardedFor, body string) *http.Response {
+		req, _ := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}]}`
+
+	// When: one client sends two requests, another client one, and the first resets the streams
+	first := do(http.MethodPost, "/v1/chat/completions", "198.51.100.1", body)
+	second := do(http.MethodPost, "/v1/chat/completions", "198.51.100.1", body)
+	other := do(http.MethodPost, "/v1/chat/completions", "198.51.100.2", body)
+	do(http.MethodDelete, "/_mokku/streams", "198.51.100.1", "")
+
+	// Then: each client IP has its own budget and the audit log names the client
+	if first.StatusCode != http.StatusOK || second.StatusCode != http.StatusTooManyRequests || other.StatusCode != http.StatusOK {
+		t.Errorf("expected 200, 429, 200, got %d, %d, %d", first.StatusCode, second.StatusCode, other.StatusCode)
+	}
+	auditResp, err := http.Get(srv.URL + "/_mokku/audit")
+	if err != nil {
+		t.Fatalf("GET audit: %v", err)
+	}
+	defer func() { _ = auditResp.Body.Close() }()
+	data, _ := mustDecodeJSON(t, auditResp.Body)["data"].([]interface{})
+	if len(data) != 1 || data[0].(map[string]interface{})["remote_addr"] != "198.51.100.1" {
+		t.Errorf("expected the forwarded client in the audit log, got %v", data)
+	}
+}
+
+func TestIntegration_Proxies_ProxyProtocolNamesConnectionClient(t *testing.T) {
+	// Given: a server behind a trusted L4 load balancer speaking the PROXY protocol
+	proxies := newTestProxies(t, proxyConfig{Trusted: []string{"127.0.0.1"}, ProxyProtocol: true})
+	srv := newUnstartedTestServer(t, Config{}, "")
+	srv.Listener = &proxyProtocolListener{Listener: srv.Listener, proxies: proxies}
+	srv.Start()
+	defer srv.Close()
+
+	// When: a request arrives after a PROXY protocol v1 header
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = conn.Close() }()
+	_, _ = conn.Write([]byte("PROXY TCP4 198.51.100.7 10.0.0.1 40000 8080\r\nGET /_mokku/connections HTTP/1.1\r\nHost: mokku\r\n\r\n"))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("read response: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the connection is reported with the client address from the header
+	open, _ := mustDecodeJSON(t, resp.Body)["open"].([]interface{})
+	if resp.StatusCode != http.StatusOK || len(open) != 1 || open[0].(map[string]interface{})["remote_addr"] != "198.51.100.7:40000" {
+		t.Errorf("expected the proxied client address, got %d %v", resp.StatusCode, open)
+	}
+}
+
+func TestIntegration_Overhead_SeparatesInjectedLatency(t *testing.T) {
+	// Given: a cold start of 200ms and a generous overhead SLO
+	srv := newTestServerWithConfig(t, Config{
+		ColdStart:   map[string]coldStartConfig{"gpt-4o": {Latency: "200ms"}},
+		OverheadSLO: map[string]string{overheadAnyEndpoint: "150ms"},
+	})
+	defer srv.Close()
+
+	// When: the cold request is sent
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}]}`)
+	_ = resp.Body.Close()
+	statsResp, err := http.Get(srv.URL + "/_mokku/overhead")
+	if err != nil {
+		t.Fatalf("GET overhead: %v", err)
+	}
+	defer func() { _ = statsResp.Body.Close() }()
+
+	// Then: the header and the endpoint stats leave out the injected latency
+	overhead, err := strconv.ParseFloat(resp.Header.Get(overheadHeader), 64)
+	if err != nil || overhead >= 150 {
+		t.Errorf("expected an overhead below 150ms, got %q", resp.Header.Get(overheadHeader))
+	}
+	var stats overheadStats
+	if err := json.NewDecoder(statsResp.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Endpoints) != 1 {
+		t.Fatalf("expected one endpoint, got %+v", stats.Endpoints)
+	}
+	e := stats.Endpoints[0]
+	if e.Endpoint != "POST /v1/chat/completions" || e.Requests != 1 || e.Injected.Max < 200 || e.SLO != 150 || e.SLOViolations != 0 {
+		t.Errorf("unexpected endpoint overhead %+v", e)
+	}
+}
+
+func TestIntegration_Capture_ReportsOccupancyAndEvictions(t *testing.T) {
+	// Given: the default capture and one captured request
+	srv := newTestServer(t)
+	defer srv.Close()
+	resp := postJSON(t, srv.URL+"/v1/embeddings", `{"model":"text-embedding-3-small","input":"hello"}`)
+	_ = resp.Body.Close()
+
+	// When
+	statsResp, err := http.Get(srv.URL + "/_mokku/capture")
+	if err != nil {
+		t.Fatalf("GET capture: %v", err)
+	}
+	defer func() { _ = statsResp.Body.Close() }()
+
+	// Then
+	var stats captureStats
+	if err := json.NewDecoder(statsResp.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Enabled || stats.Entries != 1 || stats.Captured != 1 || stats.Bytes == 0 || stats.Eviction != captureEvictOldest || stats.Evicted["size"] != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestIntegration_Tags_FilterCapturedRequestsAndRollUp(t *testing.T) {
+	// Given: a rule tagging conversations that return tool results
+	yes := true
+	srv := newTestServerWithConfig(t, Config{Tags: []tagConfig{{Tag: "agent-loop", Match: tagMatchConfig{ToolResults: &yes}}}})
+	defer srv.Close()
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"}]}`)
+	_ = resp.Body.Close()
+	resp = postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hello"},`+
+		`{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"f","arguments":"{}"}}]},`+
+		`{"role":"tool","tool_call_id":"call_1","content":"{}"}]}`)
+	_ = resp.Body.Close()
+
+	// When
+	listResp, err := http.Get(srv.URL + "/_mokku/requests?tag=agent-loop")
+	if err != nil {
+		t.Fatalf("GET requests: %v", err)
+	}
+	defer func() { _ = listResp.Body.Close() }()
+	statsResp, err := http.Get(srv.URL + "/_mokku/tags")
+	if err != nil {
+		t.Fatalf("GET tags: %v", err)
+	}
+	defer func() { _ = statsResp.Body.Close() }()
+
+	// Then: only the agent turn is listed and rolled up under the tag
+	var list capturedRequestsResponse
+	if err := json.NewDecoder(listResp.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Data) != 1 || !slices.Equal(list.Data[0].Tags, []string{"agent-loop"}) {
+		t.Errorf("expected the tagged request, got %+v", list.Data)
+	}
+	var stats tagStats
+	if err := json.NewDecoder(statsResp.Body).Decode(&stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Untagged != 1 || len(stats.Tags) != 1 || stats.Tags[0].Tag != "agent-loop" || stats.Tags[0].Requests != 1 || stats.Tags[0].Errors != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestIntegration_Alerts_ReportsSDKDowngrade(t *testing.T) {
+	// Given: SDK downgrade alerts and a client that upgraded its SDK
+	srv := newTestServerWithConfig(t, Config{Alerts: alertConfig{SDKDowngrade: true}})
+	defer srv.Close()
+	for _, version := range []string{"1.55.0", "1.40.0"} {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"hello"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Stainless-Lang", "python")
+		req.Header.Set("X-Stainless-Package-Version", version)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST embeddings: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+
+	// When
+	resp, err := http.Get(srv.URL + "/_mokku/alerts")
+	if err != nil {
+		t.Fatalf("GET alerts: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	var alerts alertsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&alerts); err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts.Data) != 1 || alerts.Data[0].Kind != alertSDKDowngrade || alerts.Data[0].Version != "1.40.0" || alerts.Data[0].Previous != "1.55.0" {
+		t.Errorf("unexpected alerts %+v", alerts.Data)
+	}
+}
+
+func TestIntegration_Baggage_OverridesLatencyAndError(t *testing.T) {
+	// Given: a request whose trace carries mokku overrides next to the caller's own baggage
+	srv := newTestServerWithConfig(t, Config{Features: map[string]bool{flagBaggageOverrides: true}})
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions",
+		strings.NewReader(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Baggage", "tenant=acme,mokku.latency=50ms,mokku.error=429")
+
+	// When
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then: the request is delayed, then fails like a rate limited request
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("expected a delay of at least 50ms, got %s", elapsed)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") != "1" ||
+		resp.Header.Get(baggageHeader) != "mokku.error=429,mokku.latency=50ms" {
+		t.Errorf("expected 429 with the applied overrides, got %d %v", resp.StatusCode, resp.Header)
+	}
+	if errBody := mustDecodeJSON(t, resp.Body)["error"].(map[string]interface{}); errBody["code"] != "rate_limit_exceeded" {
+		t.Errorf("expected rate_limit_exceeded, got %v", errBody)
+	}
+}
+
+func TestIntegration_Baggage_IgnoredWhenFlagDisabled(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/embeddings", strings.NewReader(`{"model":"text-embedding-3-small","input":"hi"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Baggage", "mokku.error=500")
+
+	// When
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// Then
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(baggageHeader) != "" {
+		t.Errorf("expected the baggage to be ignored, got %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+// skipInMinimalBuild skips a test of the provider dialects, which the minimal build leaves out.
+func skipInMinimalBuild(t *testing.T) {
+	t.Helper()
+	if minimalBuild {
+		t.Skip("the minimal build serves no provider dialects")
+	}
+}
+
+func TestIntegration_Dialects_ScenariosDriveEveryProviderSurface(t *testing.T) {
+	skipInMinimalBuild(t)
+	// Given: the weather scenario and the provider surfaces enabled
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagProviderDialects: true}}, testScenarios)
+	defer srv.Close()
+
+	// When: the same prompt is sent to the Anthropic and Azure OpenAI surfaces
+	anthropic := postJSON(t, srv.URL+"/v1/messages",
+		`{"model":"claude-sonnet-4-5","max_tokens":100,"system":"be brief","messages":[{"role":"user","content":[{"type":"text","text":"weather in Tokyo?"}]}]}`)
+	defer func() { _ = anthropic.Body.Close() }()
+	azure := postJSON(t, srv.URL+"/openai/deployments/gpt-4o/chat/completions?api-version=2024-10-21",
+		`{"messages":[{"role":"user","content":"weather in Tokyo?"}]}`)
+	defer func() { _ = azure.Body.Close() }()
+
+	// Then: both carry the scenario's content and finish reason in their provider's format
+	if anthropic.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from /v1/messages, got %d", anthropic.StatusCode)
+	}
+	message := mustDecodeJSON(t, anthropic.Body)
+	content := message["content"].([]interface{})[0].(map[string]interface{})
+	if message["type"] != "message" || !strings.HasPrefix(message["id"].(string), "msg_") || message["stop_reason"] != "max_tokens" ||
+		content["type"] != "text" || !strings.HasPrefix(content["text"].(string), "It is sunny. (claude-sonnet-4-5:") {
+		t.Errorf("unexpected Anthropic message %v", message)
+	}
+	if usage := message["usage"].(map[string]interface{}); usage["input_tokens"].(float64) == 0 || usage["output_tokens"].(float64) == 0 {
+		t.Errorf("expected usage, got %v", usage)
+	}
+	if azure.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from the Azure deployment, got %d", azure.StatusCode)
+	}
+	completion := mustDecodeJSON(t, azure.Body)
+	choice := completion["choices"].([]interface{})[0].(map[string]interface{})
+	if completion["model"] != "gpt-4o" || choice["finish_reason"] != "length" || choice["content_filter_results"] == nil ||
+		!strings.HasPrefix(choice["message"].(map[string]interface{})["content"].(string), "It is sunny. (gpt-4o:") {
+		t.Errorf("unexpected Azure completion %v", completion)
+	}
+	if _, ok := completion["prompt_filter_results"]; !ok {
+		t.Errorf("expected prompt_filter_results, got %v", completion)
+	}
+}
+
+func TestIntegration_Dialects_AnthropicStreamAndErrors(t *testing.T) {
+	skipInMinimalBuild(t)
+	// Given
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagProviderDialects: true}}, testScenarios)
+	defer srv.Close()
+
+	// When: a streamed message, a request without max_tokens, and one the overload scenario fails
+	stream := postJSON(t, srv.URL+"/v1/messages", `{"model":"claude-sonnet-4-5","max_tokens":100,"stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = stream.Body.Close() }()
+	invalid := postJSON(t, srv.URL+"/v1/messages", `{"model":"claude-sonnet-4-5","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = invalid.Body.Close() }()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/messages", strings.NewReader(`{"model":"claude-sonnet-4-5","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-Case", "overload")
+	overloaded, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = overloaded.Body.Close() }()
+
+	// Then: the stream has the Messages API events, and errors have the Anthropic error format
+	raw, _ := io.ReadAll(stream.Body)
+	var events []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, name)
+		}
+	}
+	want := "message_start,content_block_start,content_block_delta,content_block_stop,message_delta,message_stop"
+	if got := strings.Join(events, ","); got != want || !strings.Contains(string(raw), `"text":"Echo: hi"`) {
+		t.Errorf("expected events %s with the echo, got %s", want, raw)
+	}
+	for _, tc := range []struct {
+		resp      *http.Response
+		status    int
+		errorType string
+	}{
+		{invalid, http.StatusBadRequest, "invalid_request_error"},
+		{overloaded, http.StatusServiceUnavailable, "api_error"},
+	} {
+		body := mustDecodeJSON(t, tc.resp.Body)
+		if tc.resp.StatusCode != tc.status || body["type"] != "error" || body["error"].(map[string]interface{})["type"] != tc.errorType {
+			t.Errorf("expected %d %s, got %d %v", tc.status, tc.errorType, tc.resp.StatusCode, body)
+		}
+	}
+}
+
+func TestIntegration_Dialects_DisabledByDefault(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/messages", `{"model":"claude-sonnet-4-5","max_tokens":100,"messages":[{"role":"user","content":"hi"}]}`)
+	_ = resp.Body.Close()
+
+	// Then
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 without the provider_dialects flag, got %d", resp.StatusCode)
+	}
+}
+
+func TestIntegration_Dialects_GeminiGenerateContent(t *testing.T) {
+	skipInMinimalBuild(t)
+	// Given
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagProviderDialects: true}}, testScenarios)
+	defer srv.Close()
+	body := `{"contents":[{"role":"user","parts":[{"text":"weather in Tokyo?"}]}]}`
+
+	// When: the weather prompt is sent to generateContent and streamGenerateContent
+	resp := postJSON(t, srv.URL+"/v1beta/models/gemini-2.0-flash:generateContent", body)
+	defer func() { _ = resp.Body.Close() }()
+	stream := postJSON(t, srv.URL+"/v1beta/models/gemini-2.0-flash:streamGenerateContent?alt=sse", body)
+	defer func() { _ = stream.Body.Close() }()
+
+	// Then: the scenario's content and finish reason come in the Gemini format
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	generated := mustDecodeJSON(t, resp.Body)
+	candidate := generated["candidates"].([]interface{})[0].(map[string]interface{})
+	text := candidate["content"].(map[string]interface{})["parts"].([]interface{})[0].(map[string]interface{})["text"]
+	if candidate["finishReason"] != "MAX_TOKENS" || !strings.HasPrefix(text.(string), "It is sunny. (gemini-2.0-flash:") ||
+		generated["modelVersion"] != "gemini-2.0-flash" {
+		t.Errorf("unexpected response %v", generated)
+	}
+	raw, _ := io.ReadAll(stream.Body)
+	if stream.Header.Get("Content-Type") != "text/event-stream" || strings.Count(string(raw), "data: ") != 2 ||
+		!strings.Contains(string(raw), `"finishReason":"MAX_TOKENS"`) || strings.Contains(string(raw), "[DONE]") {
+		t.Errorf("expected two chunks ending with the finish reason, got %s", raw)
+	}
+}
+
+func TestIntegration_Dialects_BedrockInvokeModel(t *testing.T) {
+	skipInMinimalBuild(t)
+	// Given
+	srv := newTestServerWithScenarios(t, Config{Features: map[string]bool{flagProviderDialects: true}}, testScenarios)
+	defer srv.Close()
+	body := `{"anthropic_version":"bedrock-2023-05-31","max_tokens":100,"messages":[{"role":"user","content":"weather in Tokyo?"}]}`
+	model := "/model/anthropic.claude-3-5-sonnet-20240620-v1:0"
+
+	// When: the weather prompt is invoked with and without a response stream, and with a bad body
+	resp := postJSON(t, srv.URL+model+"/invoke", body)
+	defer func() { _ = resp.Body.Close() }()
+	stream := postJSON(t, srv.URL+model+"/invoke-with-response-stream", body)
+	defer func() { _ = stream.Body.Close() }()
+	invalid := postJSON(t, srv.URL+model+"/invoke", `{"max_tokens":100,"messages":[]}`)
+	defer func() { _ = invalid.Body.Close() }()
+
+	// Then: the scenario's content comes as a Claude message and as event stream chunks
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	message := mustDecodeJSON(t, resp.Body)
+	text := message["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if message["stop_reason"] != "max_tokens" || !strings.HasPrefix(text, "It is sunny. (anthropic.claude-3-5-sonnet-20240620-v1:0:") {
+		t.Errorf("unexpected message %v", message)
+	}
+	if stream.Header.Get("Content-Type") != eventStreamContentType {
+		t.Fatalf("expected %s, got %s", eventStreamContentType, stream.Header.Get("Content-Type"))
+	}
+	var events []string
+	for {
+		headers, payload, ok := readEventStreamMessage(t, stream.Body)
+		if !ok {
+			break
+		}
+		var chunk struct {
+			Bytes []byte `json:"bytes"`
+		}
+		var event map[string]interface{}
+		if err := json.Unmarshal(payload, &chunk); err != nil || json.Unmarshal(chunk.Bytes, &event) != nil || headers[":event-type"] != "chunk" {
+			t.Fatalf("unexpected chunk %v %s", headers, payload)
+		}
+		events = append(events, event["type"].(string))
+		if event["type"] == "message_stop" && event["amazon-bedrock-invocationMetrics"] == nil {
+			t.Errorf("expected invocation metrics on message_stop, got %v", event)
+		}
+	}
+	if got := strings.Join(events, ","); got != "message_start,content_block_start,content_block_delta,content_block_stop,message_delta,message_stop" {
+		t.Errorf("unexpected events %s", got)
+	}
+	if invalid.StatusCode != http.StatusBadRequest || !strings.HasPrefix(invalid.Header.Get("X-Amzn-ErrorType"), "ValidationException:") {
+		t.Errorf("expected a ValidationException, got %d %v", invalid.StatusCode, invalid.Header)
+	}
+}
+
+func TestIntegration_Personality_StableAcrossTurns(t *testing.T) {
+	// Given: sticky personalities; conv-2 is fast, verbose, and uses emoji
+	srv := newTestServerWithConfig(t, Config{Features: map[string]bool{flagStickyPersonality: true}})
+	defer srv.Close()
+	send := func(body string) (*http.Response, string) {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(conversationHeader, "conv-2")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if body := mustDecodeJSON(t, resp.Body); resp.StatusCode == http.StatusOK {
+			return resp, getChoices(t, body)[0].(map[string]interface{})["message"].(map[string]interface{})["content"].(string)
+		}
+		return resp, ""
+	}
+
+	// When: two turns of the conversation are sent
+	first, firstContent := send(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	second, secondContent := send(`{"model":"gpt-4o","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"},{"role":"user","content":"more"}]}`)
+
+	// Then: both turns are served with the same personality
+	want := "latency=fast; verbosity=verbose; emoji=true"
+	if first.Header.Get(personalityHeader) != want || second.Header.Get(personalityHeader) != want {
+		t.Errorf("expected %q on both turns, got %q and %q", want, first.Header.Get(personalityHeader), second.Header.Get(personalityHeader))
+	}
+	if firstContent != "Echo: hi"+personalityVerboseSuffix+" 🙂" || !strings.HasSuffix(secondContent, personalityVerboseSuffix+" 🙂") {
+		t.Errorf("expected verbose content with emoji, got %q and %q", firstContent, secondContent)
+	}
+
+	// When / Then: requests without a conversation or end-user ID keep the default behavior
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	_ = resp.Body.Close()
+	if resp.Header.Get(personalityHeader) != "" {
+		t.Errorf("expected no personality, got %q", resp.Header.Get(personalityHeader))
+	}
+}
+
+func TestIntegration_EndpointFidelity_StrictLenientAndDisabled(t *testing.T) {
+	// Given: strict chat completions, lenient embeddings, and disabled image generation
+	srv := newTestServerWithConfig(t, Config{EndpointFidelity: map[string]string{
+		"POST /v1/chat/completions": fidelityStrict,
+		"/v1/embeddings":            fidelityLenient,
+		"/v1/images/generations":    fidelityDisabled,
+	}})
+	defer srv.Close()
+
+	// When: a strict endpoint is asked for an unknown model, streaming with an invalid temperature
+	unknown := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-9","messages":[{"role":"user","content":"hi"}]}`)
+	_ = unknown.Body.Close()
+	invalid := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"temperature":7,"messages":[{"role":"user","content":"hi"}]}`)
+	_ = invalid.Body.Close()
+
+	// Then: both are rejected without the strict_model_validation flag
+	if unknown.StatusCode != http.StatusNotFound || unknown.Header.Get(fidelityHeader) != fidelityStrict {
+		t.Errorf("expected 404 for the unknown model, got %d %q", unknown.StatusCode, unknown.Header.Get(fidelityHeader))
+	}
+	if invalid.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected 400 for the invalid stream, got %d", invalid.StatusCode)
+	}
+
+	// When: a lenient endpoint gets a request without input, with a bad dimensions type
+	lenient, err := http.Post(srv.URL+"/v1/embeddings", "text/plain", strings.NewReader(`{"model":"text-embedding-3-large","dimensions":"many"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = lenient.Body.Close() }()
+
+	// Then: it is served best-effort and the repairs are reported
+	body := mustDecodeJSON(t, lenient.Body)
+	if lenient.StatusCode != http.StatusOK || body["model"] != "text-embedding-3-large" {
+		t.Fatalf("expected a best-effort embedding, got %d %v", lenient.StatusCode, body)
+	}
+	if got := lenient.Header.Get(fidelityHeader); got != "lenient; repaired=dimensions,input" {
+		t.Errorf("unexpected fidelity header %q", got)
+	}
+
+	// When: the disabled endpoint is called
+	disabled := postJSON(t, srv.URL+"/v1/images/generations", `{"prompt":"a cat"}`)
+	defer func() { _ = disabled.Body.Close() }()
+
+	// Then: it answers like a URL the API does not serve
+	errBody := mustDecodeJSON(t, disabled.Body)["error"].(map[string]interface{})
+	if disabled.StatusCode != http.StatusNotFound || errBody["message"] != "Invalid URL (POST /v1/images/generations)" {
+		t.Errorf("expected 404 Invalid URL, got %d %v", disabled.StatusCode, errBody)
+	}
+}
@@ -2,18 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
-	"openai-mokku/api"
-
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -27,11 +24,34 @@ func main() {
 	if len(os.Args) > 1 && os.Args[1] == "-healthcheck" {
 		os.Exit(runHealthCheck())
 	}
+	// Replay a captured traffic log against a target as a load test
+	if len(os.Args) > 1 && os.Args[1] == "replay-load" {
+		os.Exit(runReplayLoad(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// Convert the API calls of a HAR file into scenarios
+	if len(os.Args) > 1 && os.Args[1] == "import-har" {
+		os.Exit(runImportHAR(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// Compare the requests captured in a test run with a baseline run
+	if len(os.Args) > 1 && os.Args[1] == "diff-requests" {
+		os.Exit(runDiffRequests(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	// Show live requests and toggle behaviors in a terminal UI
+	if len(os.Args) > 1 && os.Args[1] == "tui" {
+		os.Exit(runTUI(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
+	configurePlatformLogging()
 
 	ctx := context.Background()
 
 	// Initialize OpenTelemetry Tracer Provider
-	tp, err := initTracerProvider(ctx)
+	tracingCfg, err := tracingConfigFromEnv(os.Getenv)
+	if err != nil {
+		log.Fatalf("Invalid tracing settings: %v", err)
+	}
+	omitSpanBodies.Store(!tracingCfg.Bodies)
+	tp, err := initTracerProvider(ctx, tracingCfg)
 	if err != nil {
 		log.Printf("Warning: Failed to initialize tracer provider: %v", err)
 	} else {
@@ -44,41 +64,131 @@ func main() {
 		}()
 	}
 
-	// Create handler
-	handler := &MockHandler{}
+	// Load the optional config file and resolve feature flags
+	configPath := os.Getenv("MOKKU_CONFIG")
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	scenariosPath := os.Getenv("MOKKU_SCENARIOS")
+	captureCfg, err := captureConfigFromEnv(os.Getenv)
+	if err != nil {
+		log.Fatalf("Failed to configure request capture: %v", err)
+	}
+	seed, err := seedFromEnv(os.Getenv("MOKKU_SEED"))
+	if err != nil {
+		log.Fatalf("Failed to configure seed: %v", err)
+	}
 
-	// Create server with OpenTelemetry instrumentation
-	// ogen automatically uses the global tracer provider set by otel.SetTracerProvider
-	ogenServer, err := api.NewServer(handler,
-		api.WithPathPrefix("/v1"),
-	)
+	// Create shared state and handlers
+	watermark, err := watermarkModeFromEnv(os.Getenv("MOKKU_WATERMARK"))
+	if err != nil {
+		log.Fatalf("Failed to configure watermarks: %v", err)
+	}
+	state, err := newServerState(cfg, serverOptions{
+		ScenariosPath: scenariosPath,
+		Features:      os.Getenv("MOKKU_FEATURES"),
+		AdminToken:    os.Getenv("MOKKU_ADMIN_TOKEN"),
+		Capture:       captureCfg,
+		Seed:          seed,
+		Watermark:     watermark,
+	})
+	if err != nil {
+		log.Fatalf("Failed to start: %v", err)
+	}
+	if !state.auth.Enabled() {
+		log.Printf("Warning: the %s control API is unauthenticated; set MOKKU_ADMIN_TOKEN or admin tokens in MOKKU_CONFIG on shared deployments", adminPathPrefix)
+	}
+	instanceID := resolveInstanceID()
+	rootHandler, admin, err := state.newHandler(instanceID)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
 
-	// Wrap with streaming handler
-	streamingHandler := NewStreamingHandler(ogenServer)
+	affinity, err := affinityRouterFromEnv(instanceID, os.Getenv("MOKKU_PEERS"))
+	if err != nil {
+		log.Fatalf("Failed to configure session affinity: %v", err)
+	}
+	warnIfReplicated(affinity)
 
-	// Create HTTP server
+	// Create HTTP server, serving HTTPS and requiring client certificates when configured
+	tlsConfig, err := newServerTLSConfig(cfg.TLS)
+	if err != nil {
+		log.Fatalf("Failed to load TLS settings: %v", err)
+	}
+	if tlsConfig != nil && tlsConfig.ClientCAs != nil {
+		rootHandler = withClientCertificates(rootHandler)
+	}
 	addr := ":8080"
 	httpServer := &http.Server{
 		Addr:              addr,
-		Handler:           streamingHandler,
+		Handler:           withAffinity(affinity, withInstanceHeader(instanceID, rootHandler)),
+		TLSConfig:         tlsConfig,
+		ConnContext:       state.connections.ConnContext,
+		ConnState:         state.connections.ConnState,
 		ReadHeaderTimeout: 30 * time.Second,
 	}
 
+	caps, err := admin.Capabilities()
+	if err != nil {
+		log.Fatalf("Failed to describe capabilities: %v", err)
+	}
+	logStartupBanner(addr, caps)
+
+	// Take over the listener and state of the process being upgraded, if one serves the handoff
+	// socket, or start listening
+	handoffPath := os.Getenv("MOKKU_HANDOFF_SOCKET")
+	var inherited *handoff
+	if handoffPath != "" {
+		if inherited, err = dialHandoff(handoffPath); err != nil {
+			log.Fatalf("Failed to take over from the running process: %v", err)
+		}
+	}
+	var tcpListener net.Listener
+	if inherited != nil {
+		tcpListener = inherited.Listener
+		state.restoreHandoff(inherited.State)
+		log.Printf("Took over from the running process (%s)", inherited.State.summary())
+	} else if tcpListener, err = net.Listen("tcp", addr); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+
 	// Start server in a goroutine
+	// Connections from trusted proxies may start with a PROXY protocol header naming the client
+	listener := net.Listener(&proxyProtocolListener{Listener: tcpListener, proxies: state.proxies})
 	go func() {
 		log.Printf("Starting OpenAI Mock Server on %s", addr)
-		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		serve := func() error { return httpServer.Serve(listener) }
+		if tlsConfig != nil {
+			serve = func() error { return httpServer.ServeTLS(listener, "", "") }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
-	// Wait for interrupt signal
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	if inherited != nil {
+		if err := inherited.Confirm(); err != nil {
+			log.Fatalf("Failed to confirm the takeover: %v", err)
+		}
+	}
+	var handedOff <-chan struct{}
+	if handoffPath != "" {
+		if handedOff, err = listenHandoff(handoffPath, tcpListener, state); err != nil {
+			log.Fatalf("Failed to serve the handoff socket: %v", err)
+		}
+		log.Printf("Serving state handoffs to upgraded processes on %s", handoffPath)
+	}
+
+	// Handle runtime control signals until an interrupt signal or a handoff
+	controls := &runtimeControls{
+		serverState:   state,
+		configPath:    configPath,
+		scenariosPath: scenariosPath,
+		startedAt:     time.Now(),
+		handedOff:     handedOff,
+	}
+	controls.waitForShutdown()
 
 	log.Println("Shutting down server...")
 
@@ -93,10 +203,17 @@ func main() {
 	log.Println("Server exited")
 }
 
-// runHealthCheck probes the /healthz endpoint and returns the process exit code.
+// runHealthCheck probes the /healthz endpoint and returns the process exit code. It uses HTTPS when
+// MOKKU_CONFIG configures TLS, without verifying the server certificate, which is not issued for
+// localhost.
 func runHealthCheck() int {
 	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Get("http://localhost:8080/healthz")
+	url := "http://localhost:8080/healthz"
+	if cfg, err := loadConfig(os.Getenv("MOKKU_CONFIG")); err == nil && cfg.TLS.CertFile != "" {
+		url = "https://localhost:8080/healthz"
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	resp, err := client.Get(url)
 	if err != nil {
 		return 1
 	}
@@ -108,16 +225,8 @@ func runHealthCheck() int {
 	return 0
 }
 
-func initTracerProvider(ctx context.Context) (*sdktrace.TracerProvider, error) {
-	otlpEndpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
-	if otlpEndpoint == "" {
-		otlpEndpoint = "jaeger:4317"
-	}
-
-	exporter, err := otlptracegrpc.New(ctx,
-		otlptracegrpc.WithEndpoint(otlpEndpoint),
-		otlptracegrpc.WithInsecure(),
-	)
+func initTracerProvider(ctx context.Context, cfg tracingConfig) (*sdktrace.TracerProvider, error) {
+	exporter, err := newSpanExporter(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
 	}
@@ -125,18 +234,18 @@ func initTracerProvider(ctx context.Context) (*sdktrace.TracerProvider, error) {
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
 			semconv.ServiceName("openai-mokku"),
-			semconv.ServiceVersion("1.0.0"),
+			semconv.ServiceVersion(serviceVersion),
 		),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
-		sdktrace.WithResource(res),
-		sdktrace.WithSampler(sdktrace.AlwaysSample()),
-	)
+	opts := append(cfg.tracerProviderOptions(), sdktrace.WithResource(res))
+	if exporter != nil {
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	}
+	tp := sdktrace.NewTracerProvider(opts...)
 
 	otel.SetTracerProvider(tp)
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
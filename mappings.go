@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// fieldMapping is a compiled line of a scenario's response.map, such as
+//
+//	choices[0].message.content = request.messages[-1].content | upper
+//
+// It sets a field of the JSON response body to a field of the request or the response, or to a
+// JSON literal, passed through filters.
+type fieldMapping struct {
+	line    string
+	target  []pathStep
+	root    string // "request", "response", or "" for a literal
+	source  []pathStep
+	literal any
+	filters []mappingFilter
+}
+
+// pathStep is an object key or, with index set, an array index; negative indexes count from the end.
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// mappingFilter transforms a mapped value.
+type mappingFilter func(v any) (any, error)
+
+// mappingFilters are the filters of mappings, named like the template functions.
+var mappingFilters = map[string]mappingFilter{
+	"upper":  stringFilter("upper", strings.ToUpper),
+	"lower":  stringFilter("lower", strings.ToLower),
+	"trim":   stringFilter("trim", strings.TrimSpace),
+	"b64enc": stringFilter("b64enc", func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }),
+	"b64dec": func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("b64dec needs a string, got %T", v)
+		}
+		return b64dec(s)
+	},
+	"tokens": func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("tokens needs a string, got %T", v)
+		}
+		return countTokens(s), nil
+	},
+	"toJSON": func(v any) (any, error) { return toJSON(v) },
+}
+
+// stringFilter adapts a string function to a mappingFilter.
+func stringFilter(name string, f func(string) string) mappingFilter {
+	return func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s needs a string, got %T", name, v)
+		}
+		return f(s), nil
+	}
+}
+
+// parseMapping compiles a mapping line: target = source | filter | ...
+func parseMapping(line string) (*fieldMapping, error) {
+	lhs, rhs, ok := strings.Cut(line, "=")
+	if !ok {
+		return nil, fmt.Errorf("%q: expected target = source", line)
+	}
+	m := &fieldMapping{line: line}
+	var err error
+	if m.target, err = parsePath(strings.TrimSpace(lhs)); err != nil {
+		return nil, fmt.Errorf("%q: target: %w", line, err)
+	}
+	parts := splitFilters(rhs)
+	source := strings.TrimSpace(parts[0])
+	for _, root := range []string{"request", "response"} {
+		if rest, ok := strings.CutPrefix(source, root+"."); ok {
+			m.root = root
+			if m.source, err = parsePath(rest); err != nil {
+				return nil, fmt.Errorf("%q: source: %w", line, err)
+			}
+		}
+	}
+	if m.root == "" {
+		if err := json.Unmarshal([]byte(source), &m.literal); err != nil {
+			return nil, fmt.Errorf("%q: source must be request.<path>, response.<path>, or a JSON literal", line)
+		}
+	}
+	for _, part := range parts[1:] {
+		name := strings.TrimSpace(part)
+		filter, ok := mappingFilters[name]
+		if !ok {
+			return nil, fmt.Errorf("%q: unknown filter %q", line, name)
+		}
+		m.filters = append(m.filters, filter)
+	}
+	return m, nil
+}
+
+// splitFilters splits the source of a mapping line from its filters at the | outside JSON strings,
+// arrays, and objects, so a literal such as "a|b" stays whole.
+func splitFilters(rhs string) []string {
+	var parts []string
+	depth, start := 0, 0
+	inString, escaped := false, false
+	for i, c := range rhs {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == '|' && depth == 0:
+			parts = append(parts, rhs[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, rhs[start:])
+}
+
+// parsePath parses a path such as choices[0].message.content.
+func parsePath(path string) ([]pathStep, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty path")
+	}
+	var steps []pathStep
+	for _, segment := range strings.Split(path, ".") {
+		key, rest, _ := strings.Cut(segment, "[")
+		if key == "" {
+			return nil, fmt.Errorf("empty key in %q", path)
+		}
+		steps = append(steps, pathStep{key: key})
+		for rest != "" {
+			index, after, ok := strings.Cut(rest, "]")
+			i, err := strconv.Atoi(index)
+			if !ok || err != nil || (after != "" && after[0] != '[') {
+				return nil, fmt.Errorf("bad index in %q", path)
+			}
+			steps = append(steps, pathStep{index: i, isIndex: true})
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	return steps, nil
+}
+
+// lookupPath returns the value at path in decoded JSON.
+func lookupPath(doc any, path []pathStep) (any, bool) {
+	v := doc
+	for _, step := range path {
+		if step.isIndex {
+			arr, ok := v.([]any)
+			i := step.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if !ok || i < 0 || i >= len(arr) {
+				return nil, false
+			}
+			v = arr[i]
+			continue
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if v, ok = obj[step.key]; !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// setPath sets the value at path in decoded JSON, creating missing object keys. Array elements must
+// exist.
+func setPath(doc any, path []pathStep, value any) error {
+	v := doc
+	for n, step := range path {
+		last := n == len(path)-1
+		if step.isIndex {
+			arr, ok := v.([]any)
+			i := step.index
+			if i < 0 {
+				i += len(arr)
+			}
+			if !ok || i < 0 || i >= len(arr) {
+				return fmt.Errorf("no element %d", step.index)
+			}
+			if last {
+				arr[i] = value
+				return nil
+			}
+			v = arr[i]
+			continue
+		}
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s is not in an object", step.key)
+		}
+		if last {
+			obj[step.key] = value
+			return nil
+		}
+		if _, ok := obj[step.key]; !ok {
+			obj[step.key] = map[string]any{}
+		}
+		v = obj[step.key]
+	}
+	return nil
+}
+
+// apply sets the mapping's target in the decoded response resp of the request req. A missing
+// source field leaves the target unchanged.
+func (m *fieldMapping) apply(req, resp any) error {
+	value := m.literal
+	switch m.root {
+	case "request", "response":
+		doc := req
+		if m.root == "response" {
+			doc = resp
+		}
+		var ok bool
+		if value, ok = lookupPath(doc, m.source); !ok {
+			return nil
+		}
+	}
+	// Copied, so later mappings writing inside the value change neither the shared literal nor the
+	// source field
+	value = copyJSON(value)
+	for _, filter := range m.filters {
+		var err error
+		if value, err = filter(value); err != nil {
+			return fmt.Errorf("%q: %w", m.line, err)
+		}
+	}
+	if err := setPath(resp, m.target, value); err != nil {
+		return fmt.Errorf("%q: %w", m.line, err)
+	}
+	return nil
+}
+
+// copyJSON returns a deep copy of decoded JSON.
+func copyJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		c := make(map[string]any, len(v))
+		for key, value := range v {
+			c[key] = copyJSON(value)
+		}
+		return c
+	case []any:
+		c := make([]any, len(v))
+		for i, value := range v {
+			c[i] = copyJSON(value)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
+// mappingWriter buffers a non-streaming response and applies a scenario's mappings to its JSON body
+// when the handler is done. Error responses and other bodies are written unchanged.
+type mappingWriter struct {
+	http.ResponseWriter
+	r        *http.Request
+	scenario string
+	mappings []*fieldMapping
+	req      any
+	status   int
+	body     bytes.Buffer
+}
+
+// newMappingWriter wraps w for a request with the decoded body req.
+func newMappingWriter(w http.ResponseWriter, r *http.Request, s matchedScenario, req any) *mappingWriter {
+	return &mappingWriter{ResponseWriter: w, r: r, scenario: s.Name, mappings: s.Mappings, req: req}
+}
+
+// streamRequested reports whether a decoded request body asks for a streaming response.
+func streamRequested(doc any) bool {
+	m, ok := doc.(map[string]any)
+	return ok && m["stream"] == true
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *mappingWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+// Write implements http.ResponseWriter
+func (w *mappingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.body.Write(b)
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *mappingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish writes the buffered response with the mappings applied.
+func (w *mappingWriter) finish() {
+	if w.status == 0 {
+		return
+	}
+	body := w.body.Bytes()
+	var resp any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if w.status == http.StatusOK && dec.Decode(&resp) == nil {
+		for _, m := range w.mappings {
+			if err := m.apply(w.req, resp); err != nil {
+				handleAPIError(w.r.Context(), w.ResponseWriter, w.r, scenarioFailure(w.scenario, err))
+				return
+			}
+		}
+		body, _ = json.Marshal(resp)
+		w.Header().Del("Content-Length")
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
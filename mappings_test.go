@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// --- parseMapping ---
+
+func TestParseMapping_RejectsInvalidLines(t *testing.T) {
+	// Given
+	for _, line := range []string{
+		"choices[0].message.content",
+		" = request.model",
+		"choices[x].text = request.model",
+		"choices[0.text = request.model",
+		"a..b = request.model",
+		"usage = request.",
+		"id = model",
+		"id = request.model | shout",
+	} {
+		// When
+		_, err := parseMapping(line)
+		// Then
+		if err == nil {
+			t.Errorf("%q: expected an error", line)
+		}
+	}
+}
+
+func TestParseMapping_KeepsPipesInsideLiterals(t *testing.T) {
+	// Given: literals holding a |, passed through filters or not
+	for line, want := range map[string]string{
+		`system_fingerprint = "fp|mokku"`:            "fp|mokku",
+		`system_fingerprint = "a \"|\" b" | upper`:   `A "|" B`,
+		`system_fingerprint = ["a|b", "c"] | toJSON`: `["a|b","c"]`,
+		`system_fingerprint = {"k": "x|y"} | toJSON`: `{"k":"x|y"}`,
+		`system_fingerprint = "[" | lower`:           "[",
+	} {
+		// When
+		m, err := parseMapping(line)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", line, err)
+			continue
+		}
+		resp := map[string]any{}
+		if err := m.apply(nil, resp); err != nil {
+			t.Errorf("%q: unexpected error: %v", line, err)
+			continue
+		}
+		// Then
+		if got := resp["system_fingerprint"]; got != want {
+			t.Errorf("%q: expected %q, got %q", line, want, got)
+		}
+	}
+}
+
+// --- fieldMapping.apply ---
+
+func TestFieldMapping_Apply(t *testing.T) {
+	// Given: a decoded request and response
+	var req, resp any
+	_ = json.Unmarshal([]byte(`{"model":"gpt-4o","user":"u-1","messages":[{"role":"system","content":"x"},{"role":"user","content":"hi there"}]}`), &req)
+	_ = json.Unmarshal([]byte(`{"id":"chatcmpl-1","choices":[{"message":{"role":"assistant","content":"echo"}}],"usage":{"prompt_tokens":3}}`), &resp)
+	lines := []string{
+		"choices[0].message.content = request.messages[-1].content | upper",
+		"usage.completion_tokens = request.messages[-1].content | tokens",
+		"usage.total_tokens = response.usage.prompt_tokens",
+		"system_fingerprint = \"fp_mokku\"",
+		"metadata.user = request.user | b64enc",
+		"metadata.missing = request.no.such.field",
+	}
+
+	// When
+	for _, line := range lines {
+		m, err := parseMapping(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := m.apply(req, resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Then
+	got, _ := json.Marshal(resp)
+	want := `{"choices":[{"message":{"content":"HI THERE","role":"assistant"}}],"id":"chatcmpl-1","metadata":{"user":"dS0x"},` +
+		`"system_fingerprint":"fp_mokku","usage":{"completion_tokens":3,"prompt_tokens":3,"total_tokens":3}}`
+	if string(got) != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestFieldMapping_Apply_WritesInsideLiteralsOfEachResponse(t *testing.T) {
+	// Given: an object literal, and a mapping writing inside it, applied to concurrent responses
+	var mappings []*fieldMapping
+	for _, line := range []string{
+		`metadata = {"source": "mokku"}`,
+		"metadata.user = request.user",
+	} {
+		m, err := parseMapping(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mappings = append(mappings, m)
+	}
+	var wg sync.WaitGroup
+	got := make([]string, 8)
+	for i := range got {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := map[string]any{"user": fmt.Sprintf("u-%d", i)}
+			resp := map[string]any{}
+			// When
+			for _, m := range mappings {
+				if err := m.apply(req, resp); err != nil {
+					t.Error(err)
+				}
+			}
+			data, _ := json.Marshal(resp)
+			got[i] = string(data)
+		}()
+	}
+	wg.Wait()
+	// Then: every response has its own user, and the literal is unchanged
+	for i, body := range got {
+		if want := fmt.Sprintf(`{"metadata":{"source":"mokku","user":"u-%d"}}`, i); body != want {
+			t.Errorf("expected %s, got %s", want, body)
+		}
+	}
+	if literal, _ := json.Marshal(mappings[0].literal); string(literal) != `{"source":"mokku"}` {
+		t.Errorf("expected the literal unchanged, got %s", literal)
+	}
+}
+
+func TestFieldMapping_Apply_Errors(t *testing.T) {
+	// Given
+	resp := map[string]any{"choices": []any{}, "id": "x"}
+	for _, line := range []string{"choices[0].text = \"a\"", "id.value = \"a\"", "id = response.choices | upper"} {
+		m, err := parseMapping(line)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// When
+		err = m.apply(nil, resp)
+		// Then
+		if err == nil {
+			t.Errorf("%q: expected an error", line)
+		}
+	}
+}
+
+// --- mappingWriter ---
+
+func TestMappingWriter_LeavesErrorsUnchanged(t *testing.T) {
+	// Given: a mapping writer around an error response
+	m, _ := parseMapping(`id = "mapped"`)
+	rec := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/v1/embeddings", nil)
+	w := newMappingWriter(rec, r, matchedScenario{Name: "s", Mappings: []*fieldMapping{m}}, nil)
+
+	// When
+	w.WriteHeader(http.StatusBadRequest)
+	_, _ = w.Write([]byte(`{"id":"original"}`))
+	w.finish()
+
+	// Then
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "original") {
+		t.Errorf("expected the error unchanged, got %d %s", rec.Code, rec.Body)
+	}
+}
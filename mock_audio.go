@@ -0,0 +1,124 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+
+	"openai-mokku/api"
+)
+
+// audioTokensPerSecond is the number of prompt tokens billed per second of input audio.
+const audioTokensPerSecond = 10
+
+// mp3BytesPerSecond is the assumed MP3 bitrate (128 kbps) used to estimate durations.
+const mp3BytesPerSecond = 128000 / 8
+
+// transcriptWordsPerSecond is the speaking rate used to size fabricated transcripts.
+const transcriptWordsPerSecond = 2.5
+
+// transcriptVocabulary is the word list fabricated transcripts are drawn from.
+var transcriptVocabulary = []string{
+	"hello", "please", "could", "you", "tell", "me", "about", "the",
+	"weather", "today", "and", "what", "time", "is", "it", "now",
+	"I", "would", "like", "to", "book", "a", "table", "for",
+	"two", "tomorrow", "evening", "thanks", "very", "much", "okay", "great",
+}
+
+// decodeInputAudio decodes base64 audio data from an input_audio content part.
+func decodeInputAudio(audio api.ChatCompletionRequestMessageContentPartInputAudio) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(audio.Data)
+	if err != nil {
+		return nil, newInvalidRequestError("messages", "The data provided for 'input_audio' is not of valid base64 format.")
+	}
+	return data, nil
+}
+
+// audioDuration returns the duration of the audio in seconds.
+// WAV durations are read from the RIFF header; MP3 (and unparseable WAV) durations
+// are estimated from the byte length at mp3BytesPerSecond.
+func audioDuration(data []byte, format api.ChatCompletionRequestMessageContentPartInputAudioFormat) float64 {
+	if format == api.ChatCompletionRequestMessageContentPartInputAudioFormatWav {
+		if seconds, ok := wavDuration(data); ok {
+			return seconds
+		}
+	}
+	return float64(len(data)) / mp3BytesPerSecond
+}
+
+// wavDuration reads the duration of a RIFF/WAVE file from its fmt and data chunks.
+func wavDuration(data []byte) (float64, bool) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return 0, false
+	}
+	var byteRate uint32
+	for offset := 12; offset+8 <= len(data); {
+		chunkID := string(data[offset : offset+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		switch chunkID {
+		case "fmt ":
+			if body+12 > len(data) {
+				return 0, false
+			}
+			byteRate = binary.LittleEndian.Uint32(data[body+8 : body+12])
+		case "data":
+			if byteRate == 0 {
+				return 0, false
+			}
+			return float64(chunkSize) / float64(byteRate), true
+		}
+		offset = body + chunkSize + chunkSize%2
+	}
+	return 0, false
+}
+
+// audioTokens converts an audio duration to billed prompt tokens, rounding up.
+func audioTokens(seconds float64) int {
+	return int(math.Ceil(seconds * audioTokensPerSecond))
+}
+
+// fabricateTranscript returns a deterministic fake transcript for the audio.
+// Words are drawn from transcriptVocabulary using the audio hash, and the transcript
+// length follows the audio duration at transcriptWordsPerSecond.
+func fabricateTranscript(data []byte, seconds float64) string {
+	sum := sha256.Sum256(data)
+	count := max(1, int(math.Round(seconds*transcriptWordsPerSecond)))
+	words := make([]string, count)
+	for i := range words {
+		words[i] = transcriptVocabulary[int(sum[i%len(sum)]+byte(i/len(sum)))%len(transcriptVocabulary)]
+	}
+	return strings.Join(words, " ")
+}
+
+// countAudioTokens returns the total audio prompt tokens across all input_audio parts in messages.
+func countAudioTokens(messages []api.ChatCompletionRequestMessage) (int, error) {
+	total := 0
+	for _, m := range messages {
+		for _, part := range m.Content.Value.ChatCompletionRequestMessageContentPartArray {
+			if part.Type != api.ChatCompletionRequestMessageContentPartTypeInputAudio {
+				continue
+			}
+			data, err := decodeInputAudio(part.InputAudio.Value)
+			if err != nil {
+				return 0, err
+			}
+			total += audioTokens(audioDuration(data, part.InputAudio.Value.Format))
+		}
+	}
+	return total, nil
+}
+
+// inputAudioTranscript renders the fabricated transcript of an input_audio part as message text.
+// Undecodable audio yields a placeholder; validation errors are reported by countAudioTokens.
+func inputAudioTranscript(audio api.ChatCompletionRequestMessageContentPartInputAudio) string {
+	data, err := decodeInputAudio(audio)
+	if err != nil {
+		return "[unreadable audio]"
+	}
+	seconds := audioDuration(data, audio.Format)
+	return fmt.Sprintf("[transcript %.1fs] %s", seconds, fabricateTranscript(data, seconds))
+}
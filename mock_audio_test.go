@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"openai-mokku/api"
+)
+
+// makeWAV builds a PCM WAV file with the given byte rate and number of data bytes.
+func makeWAV(byteRate, dataSize int) []byte {
+	buf := make([]byte, 44+dataSize)
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(36+dataSize))
+	copy(buf[8:12], "WAVE")
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], 16)
+	binary.LittleEndian.PutUint16(buf[20:22], 1)
+	binary.LittleEndian.PutUint16(buf[22:24], 1)
+	binary.LittleEndian.PutUint32(buf[24:28], uint32(byteRate/2))
+	binary.LittleEndian.PutUint32(buf[28:32], uint32(byteRate))
+	binary.LittleEndian.PutUint16(buf[32:34], 2)
+	binary.LittleEndian.PutUint16(buf[34:36], 16)
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(dataSize))
+	return buf
+}
+
+// audioMessage builds a user message with a single input_audio part.
+func audioMessage(data string, format api.ChatCompletionRequestMessageContentPartInputAudioFormat) api.ChatCompletionRequestMessage {
+	return api.ChatCompletionRequestMessage{
+		Role: api.ChatCompletionRequestMessageRoleUser,
+		Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent(
+			[]api.ChatCompletionRequestMessageContentPart{{
+				Type: api.ChatCompletionRequestMessageContentPartTypeInputAudio,
+				InputAudio: api.NewOptChatCompletionRequestMessageContentPartInputAudio(
+					api.ChatCompletionRequestMessageContentPartInputAudio{Data: data, Format: format}),
+			}})),
+	}
+}
+
+// --- audioDuration ---
+
+func TestAudioDuration_WAV_ReadsHeader(t *testing.T) {
+	// Given: 2 seconds of 16 kHz mono 16-bit PCM
+	wav := makeWAV(32000, 64000)
+	// When
+	got := audioDuration(wav, api.ChatCompletionRequestMessageContentPartInputAudioFormatWav)
+	// Then
+	if got != 2 {
+		t.Errorf("expected 2s, got %v", got)
+	}
+}
+
+func TestAudioDuration_MP3_EstimatesFromBitrate(t *testing.T) {
+	// Given: 48000 bytes of MP3 data at the assumed 128 kbps
+	data := make([]byte, 48000)
+	// When
+	got := audioDuration(data, api.ChatCompletionRequestMessageContentPartInputAudioFormatMp3)
+	// Then
+	if got != 3 {
+		t.Errorf("expected 3s, got %v", got)
+	}
+}
+
+// --- audioTokens ---
+
+func TestAudioTokens_RoundsUp(t *testing.T) {
+	// Given: 1.01 seconds of audio
+	// When
+	got := audioTokens(1.01)
+	// Then: ceil(10.1) = 11
+	if got != 11 {
+		t.Errorf("expected 11 tokens, got %d", got)
+	}
+}
+
+// --- fabricateTranscript ---
+
+func TestFabricateTranscript_IsDeterministic(t *testing.T) {
+	// Given: the same audio
+	data := []byte("some audio bytes")
+	// When: transcribed twice
+	first := fabricateTranscript(data, 4)
+	second := fabricateTranscript(data, 4)
+	// Then
+	if first != second {
+		t.Errorf("expected deterministic transcript: %q vs %q", first, second)
+	}
+}
+
+func TestFabricateTranscript_LengthFollowsDuration(t *testing.T) {
+	// Given: 4 seconds of audio
+	// When
+	got := fabricateTranscript([]byte("audio"), 4)
+	// Then: 4s * 2.5 words/s = 10 words
+	if n := len(strings.Fields(got)); n != 10 {
+		t.Errorf("expected 10 words, got %d (%q)", n, got)
+	}
+}
+
+// --- countAudioTokens ---
+
+func TestCountAudioTokens_SumsAudioParts(t *testing.T) {
+	// Given: two messages with 1s and 2s of WAV audio
+	messages := []api.ChatCompletionRequestMessage{
+		audioMessage(base64.StdEncoding.EncodeToString(makeWAV(32000, 32000)), api.ChatCompletionRequestMessageContentPartInputAudioFormatWav),
+		audioMessage(base64.StdEncoding.EncodeToString(makeWAV(32000, 64000)), api.ChatCompletionRequestMessageContentPartInputAudioFormatWav),
+	}
+	// When
+	got, err := countAudioTokens(messages)
+	// Then: (1 + 2) * 10
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != 30 {
+		t.Errorf("expected 30 tokens, got %d", got)
+	}
+}
+
+func TestCountAudioTokens_InvalidBase64_ReturnsInvalidRequestError(t *testing.T) {
+	// Given: audio data that is not base64
+	messages := []api.ChatCompletionRequestMessage{
+		audioMessage("not base64!", api.ChatCompletionRequestMessageContentPartInputAudioFormatMp3),
+	}
+	// When
+	_, err := countAudioTokens(messages)
+	// Then
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+		t.Errorf("expected 400 APIError, got %v", err)
+	}
+}
+
+func TestCountAudioTokens_TextOnly_ReturnsZero(t *testing.T) {
+	// Given: a plain string message
+	messages := []api.ChatCompletionRequestMessage{
+		{Role: api.ChatCompletionRequestMessageRoleUser, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("hello"))},
+	}
+	// When
+	got, err := countAudioTokens(messages)
+	// Then
+	if err != nil || got != 0 {
+		t.Errorf("expected 0 tokens and no error, got %d, %v", got, err)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"openai-mokku/api"
+)
+
+// maxCitations is the number of sentences of a searched answer that cite a source.
+const maxCitations = 3
+
+// citationDomains are the reserved example domains (RFC 2606) cited pages live on.
+var citationDomains = []string{"example.com", "example.org", "example.net"}
+
+// urlCitation is a web page cited by a span of the message text. The span covers the
+// markdown link appended to the sentence it supports; indices count Unicode code points.
+type urlCitation struct {
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+}
+
+// chatAnnotation is a chat message annotation as sent in streamed deltas, which
+// openai-go and the python SDK decode like the non-streamed message's annotations.
+type chatAnnotation struct {
+	Type        string      `json:"type"`
+	URLCitation urlCitation `json:"url_citation"`
+}
+
+// fileCitation is a file cited at a position of the text by the file_search tool.
+type fileCitation struct {
+	Index    int
+	FileID   string
+	Filename string
+}
+
+// webSearchRequested reports whether a chat completion searches the web: the request
+// sets web_search_options or targets a search model such as gpt-4o-search-preview.
+func webSearchRequested(req *api.CreateChatCompletionRequest) bool {
+	return req.WebSearchOptions.Set || strings.Contains(req.Model, "-search-")
+}
+
+// citeWebSources appends a markdown link to each of the first maxCitations sentences of
+// text, the way search models cite the pages they read, and returns the annotated text
+// with one citation per link. Sources are derived from the sentences, so the same text
+// always cites the same pages.
+func citeWebSources(text string) (string, []urlCitation) {
+	var b strings.Builder
+	var citations []urlCitation
+	last := 0
+	for _, end := range sentenceEnds(text) {
+		sentence := text[last:end]
+		b.WriteString(sentence)
+		sum := sha256.Sum256([]byte(sentence))
+		domain := citationDomains[int(sum[0])%len(citationDomains)]
+		url := fmt.Sprintf("https://%s/%s-%s?utm_source=openai", domain, citationSlug(sentence, 5), hex.EncodeToString(sum[:3]))
+		link := fmt.Sprintf("([%s](%s))", domain, url)
+		b.WriteString(" ")
+		start := utf8.RuneCountInString(b.String())
+		b.WriteString(link)
+		citations = append(citations, urlCitation{
+			StartIndex: start,
+			EndIndex:   start + utf8.RuneCountInString(link),
+			URL:        url,
+			Title:      citationTitle(sentence),
+		})
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String(), citations
+}
+
+// citeFiles returns a citation at the end of each of the first maxCitations sentences of
+// text, attributed to files in the searched vector stores.
+func citeFiles(text string, vectorStoreIDs []string) []fileCitation {
+	store := strings.Join(vectorStoreIDs, ",")
+	var citations []fileCitation
+	last := 0
+	for _, end := range sentenceEnds(text) {
+		sentence := text[last:end]
+		sum := sha256.Sum256([]byte(store + "\x00" + sentence))
+		citations = append(citations, fileCitation{
+			Index:    utf8.RuneCountInString(text[:end]),
+			FileID:   "file-" + hex.EncodeToString(sum[:11]),
+			Filename: citationSlug(sentence, 4) + ".pdf",
+		})
+		last = end
+	}
+	return citations
+}
+
+// sentenceEnds returns the byte offsets just past the first maxCitations sentences of
+// text. Text without sentence punctuation is one sentence; blank text has none.
+func sentenceEnds(text string) []int {
+	var ends []int
+	start := 0
+	for i, r := range text {
+		if len(ends) == maxCitations {
+			break
+		}
+		next := i + 1
+		if !isSentenceEnd(r) || (next < len(text) && !unicode.IsSpace(rune(text[next]))) {
+			continue
+		}
+		if strings.TrimFunc(text[start:next], isSentencePunct) != "" {
+			ends = append(ends, next)
+			start = next
+		}
+	}
+	if len(ends) == 0 && strings.TrimSpace(text) != "" {
+		ends = append(ends, len(strings.TrimRightFunc(text, unicode.IsSpace)))
+	}
+	return ends
+}
+
+func isSentenceEnd(r rune) bool {
+	return r == '.' || r == '!' || r == '?'
+}
+
+func isSentencePunct(r rune) bool {
+	return unicode.IsSpace(r) || isSentenceEnd(r)
+}
+
+// citationWords returns the lower-cased alphanumeric words of a sentence.
+func citationWords(sentence string) []string {
+	return strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// citationSlug joins up to n words of the sentence into a URL path segment.
+func citationSlug(sentence string, n int) string {
+	words := citationWords(sentence)
+	if len(words) == 0 {
+		return "source"
+	}
+	return strings.Join(words[:min(n, len(words))], "-")
+}
+
+// citationTitle builds a page title from up to eight words of the sentence.
+func citationTitle(sentence string) string {
+	words := strings.Fields(strings.TrimFunc(sentence, isSentencePunct))
+	if len(words) == 0 {
+		return "Untitled"
+	}
+	return strings.Join(words[:min(8, len(words))], " ")
+}
+
+// chatAnnotations converts citations to chat completion message annotations.
+func chatAnnotations(citations []urlCitation) []api.ChatCompletionMessageAnnotation {
+	out := make([]api.ChatCompletionMessageAnnotation, 0, len(citations))
+	for _, c := range citations {
+		out = append(out, api.ChatCompletionMessageAnnotation{
+			Type:        api.ChatCompletionMessageAnnotationTypeURLCitation,
+			URLCitation: api.URLCitation{StartIndex: c.StartIndex, EndIndex: c.EndIndex, URL: c.URL, Title: c.Title},
+		})
+	}
+	return out
+}
+
+// chatAnnotationDeltas converts citations to the annotations of a streamed delta.
+func chatAnnotationDeltas(citations []urlCitation) []chatAnnotation {
+	var out []chatAnnotation
+	for _, c := range citations {
+		out = append(out, chatAnnotation{Type: "url_citation", URLCitation: c})
+	}
+	return out
+}
+
+// responseURLAnnotations converts citations to Responses API output_text annotations.
+func responseURLAnnotations(citations []urlCitation) []api.ResponseOutputAnnotation {
+	out := make([]api.ResponseOutputAnnotation, 0, len(citations))
+	for _, c := range citations {
+		out = append(out, api.ResponseOutputAnnotation{
+			Type:       api.ResponseOutputAnnotationTypeURLCitation,
+			StartIndex: api.NewOptInt(c.StartIndex),
+			EndIndex:   api.NewOptInt(c.EndIndex),
+			URL:        api.NewOptString(c.URL),
+			Title:      api.NewOptString(c.Title),
+		})
+	}
+	return out
+}
+
+// responseFileAnnotations converts file citations to Responses API output_text annotations.
+func responseFileAnnotations(citations []fileCitation) []api.ResponseOutputAnnotation {
+	out := make([]api.ResponseOutputAnnotation, 0, len(citations))
+	for _, c := range citations {
+		out = append(out, api.ResponseOutputAnnotation{
+			Type:     api.ResponseOutputAnnotationTypeFileCitation,
+			Index:    api.NewOptInt(c.Index),
+			FileID:   api.NewOptString(c.FileID),
+			Filename: api.NewOptString(c.Filename),
+		})
+	}
+	return out
+}
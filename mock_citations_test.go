@@ -0,0 +1,100 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"openai-mokku/api"
+)
+
+// runeSlice returns the code points [start, end) of s, the way annotation indices address text.
+func runeSlice(s string, start, end int) string {
+	return string([]rune(s)[start:end])
+}
+
+// --- citeWebSources ---
+
+func TestCiteWebSources_LinksEachSentenceUpToTheLimit(t *testing.T) {
+	// Given: four sentences, one with non-ASCII text
+	text := "Tokyo is the capital. Café prices rose! Is it raining? Last one."
+
+	// When
+	cited, citations := citeWebSources(text)
+
+	// Then: the first three sentences cite a page, and each span is its markdown link
+	if len(citations) != maxCitations {
+		t.Fatalf("expected %d citations, got %+v", maxCitations, citations)
+	}
+	for _, c := range citations {
+		span := runeSlice(cited, c.StartIndex, c.EndIndex)
+		if !strings.HasPrefix(span, "([example.") || !strings.Contains(span, "]("+c.URL+"))") {
+			t.Errorf("expected the span to be the link to %s, got %q", c.URL, span)
+		}
+	}
+	if citations[1].Title != "Café prices rose" {
+		t.Errorf("expected a title from the sentence, got %q", citations[1].Title)
+	}
+	if !strings.HasSuffix(cited, " Last one.") {
+		t.Errorf("expected the uncited sentence to be kept, got %q", cited)
+	}
+}
+
+func TestCiteWebSources_IsDeterministic(t *testing.T) {
+	// Given
+	text := "Echo: hello"
+
+	// When
+	first, a := citeWebSources(text)
+	second, b := citeWebSources(text)
+
+	// Then: text without punctuation is one sentence, cited the same way every time
+	if first != second || len(a) != 1 || a[0] != b[0] {
+		t.Errorf("expected identical citations, got %q %+v and %q %+v", first, a, second, b)
+	}
+}
+
+func TestCiteWebSources_BlankText(t *testing.T) {
+	// When
+	cited, citations := citeWebSources("  ")
+
+	// Then
+	if cited != "  " || citations != nil {
+		t.Errorf("expected blank text to be left uncited, got %q %+v", cited, citations)
+	}
+}
+
+// --- citeFiles ---
+
+func TestCiteFiles_CitesSentenceEndsPerVectorStore(t *testing.T) {
+	// Given
+	text := "Refunds take five days. Contact support."
+
+	// When
+	a := citeFiles(text, []string{"vs_a"})
+	b := citeFiles(text, []string{"vs_b"})
+
+	// Then
+	if len(a) != 2 || a[0].Index != len("Refunds take five days.") || a[1].Index != len(text) {
+		t.Fatalf("unexpected citations %+v", a)
+	}
+	if a[0].Filename != "refunds-take-five-days.pdf" || !strings.HasPrefix(a[0].FileID, "file-") {
+		t.Errorf("unexpected file %+v", a[0])
+	}
+	if a[0].FileID == b[0].FileID {
+		t.Errorf("expected different vector stores to cite different files, got %s", a[0].FileID)
+	}
+}
+
+// --- webSearchRequested ---
+
+func TestWebSearchRequested_OptionsOrSearchModel(t *testing.T) {
+	// Given
+	withOptions := &api.CreateChatCompletionRequest{Model: "gpt-4o", WebSearchOptions: api.NewOptWebSearchOptions(api.WebSearchOptions{})}
+	searchModel := &api.CreateChatCompletionRequest{Model: "gpt-4o-search-preview"}
+	plain := &api.CreateChatCompletionRequest{Model: "gpt-4o"}
+
+	// When / Then
+	if !webSearchRequested(withOptions) || !webSearchRequested(searchModel) || webSearchRequested(plain) {
+		t.Error("expected web search only with web_search_options or a search model")
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"openai-mokku/api"
+)
+
+// completionCandidate is one of the best_of samples generated for a legacy completion.
+type completionCandidate struct {
+	Text  string
+	Score float64
+	// Truncated is set when Text was cut at the context window.
+	Truncated bool
+}
+
+// resolveCompletionCounts returns the number of choices to return (n) and of candidates to
+// generate (best_of), validating that best_of is at least n as the OpenAI API does.
+// best_of 1 is the schema default, which the decoder fills in, so it is treated as unset.
+func resolveCompletionCounts(n, bestOf api.OptInt) (int, int, error) {
+	choices := 1
+	if n.Set {
+		choices = n.Value
+	}
+	candidates := choices
+	if bestOf.Set && bestOf.Value > 1 {
+		if bestOf.Value < choices {
+			return 0, 0, newInvalidRequestError("best_of", "best_of must be greater than or equal to n.")
+		}
+		candidates = bestOf.Value
+	}
+	return choices, candidates, nil
+}
+
+// completionCandidateScore returns the deterministic score by which best_of candidates are
+// ranked, standing in for the mean token log probability the real API uses.
+func completionCandidateScore(text string, variant int) float64 {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s#%d", text, variant)))
+	return float64(binary.LittleEndian.Uint64(sum[:8])>>11) / (1 << 53)
+}
+
+// bestCompletionCandidates returns the n highest-scoring candidates, best first.
+func bestCompletionCandidates(candidates []completionCandidate, n int) []completionCandidate {
+	ranked := append([]completionCandidate{}, candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
+	})
+	return ranked[:min(n, len(ranked))]
+}
+
+// fimContextRunes is how much of the prompt tail and suffix head fill-in-middle markers quote.
+const fimContextRunes = 24
+
+// fillInMiddleMarker returns the echo model's fill-in-middle content: a marker naming the end of the
+// prompt and the start of the suffix, so clients can see which gap the completion fills.
+func fillInMiddleMarker(prompt, suffix string) string {
+	head := []rune(strings.TrimSpace(suffix))
+	tail := []rune(strings.TrimSpace(prompt))
+	head = head[:min(len(head), fimContextRunes)]
+	tail = tail[max(0, len(tail)-fimContextRunes):]
+	return fmt.Sprintf("[fill between %q and %q]", string(tail), string(head))
+}
+
+// fitBetween pads middle with a space on either side where needed, so that
+// prompt + middle + suffix reads as continuous text.
+func fitBetween(prompt, middle, suffix string) string {
+	if prompt != "" && !endsWithSpace(prompt) && !startsWithSpace(middle) {
+		middle = " " + middle
+	}
+	if suffix != "" && !startsWithSpace(suffix) && !endsWithSpace(middle) {
+		middle += " "
+	}
+	return middle
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return unicode.IsSpace(r)
+}
+
+// completionPrompt returns the text of a legacy completion prompt and its prompt tokens. A prompt may
+// be a string, a list of strings, a list of token IDs, or a list of token ID lists; token IDs are
+// rendered with detokenize and billed one token each, not by their rendered text. Only the first
+// prompt of a list of prompts is used.
+func completionPrompt(prompt api.CreateCompletionRequestPrompt) (string, int, error) {
+	if prompt.IsString() {
+		return prompt.String, countTokens(prompt.String), nil
+	}
+	items := prompt.AnyArray
+	if len(items) == 0 {
+		return "", 0, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(items[0], &text); err == nil {
+		return text, countTokens(text), nil
+	}
+	var ids []int
+	if err := json.Unmarshal(items[0], &ids); err == nil {
+		text, err := detokenize(ids)
+		return text, len(ids), err
+	}
+	ids = make([]int, len(items))
+	for i, item := range items {
+		if err := json.Unmarshal(item, &ids[i]); err != nil {
+			return "", 0, newInvalidRequestError("prompt",
+				"Invalid type for 'prompt': expected a string, an array of strings, an array of tokens, or an array of token arrays.")
+		}
+	}
+	text, err := detokenize(ids)
+	return text, len(ids), err
+}
+
+// detokenize renders token IDs as text. Mock token IDs are hashes of token text and cannot be
+// reversed, so each token is rendered as a <|id|> placeholder.
+func detokenize(ids []int) (string, error) {
+	var b strings.Builder
+	for _, id := range ids {
+		if id < 0 || id >= tokenVocabularySize {
+			return "", newInvalidRequestError("prompt",
+				fmt.Sprintf("Invalid token in 'prompt': %d. Token IDs must be between 0 and %d.", id, tokenVocabularySize-1))
+		}
+		fmt.Fprintf(&b, "<|%d|>", id)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"openai-mokku/api"
+
+	"github.com/go-faster/jx"
+)
+
+// --- resolveCompletionCounts ---
+
+func TestResolveCompletionCounts_Defaults_ReturnsOne(t *testing.T) {
+	// Given: neither n nor best_of
+	// When
+	n, bestOf, err := resolveCompletionCounts(api.OptInt{}, api.OptInt{})
+	// Then
+	if err != nil || n != 1 || bestOf != 1 {
+		t.Errorf("expected 1/1, got %d/%d (%v)", n, bestOf, err)
+	}
+}
+
+func TestResolveCompletionCounts_BestOfDefaultsToN(t *testing.T) {
+	// Given: only n
+	// When
+	n, bestOf, err := resolveCompletionCounts(api.NewOptInt(3), api.OptInt{})
+	// Then
+	if err != nil || n != 3 || bestOf != 3 {
+		t.Errorf("expected 3/3, got %d/%d (%v)", n, bestOf, err)
+	}
+}
+
+func TestResolveCompletionCounts_DefaultBestOf_DoesNotLimitN(t *testing.T) {
+	// Given: n with the schema default best_of of 1
+	// When
+	n, bestOf, err := resolveCompletionCounts(api.NewOptInt(3), api.NewOptInt(1))
+	// Then
+	if err != nil || n != 3 || bestOf != 3 {
+		t.Errorf("expected 3/3, got %d/%d (%v)", n, bestOf, err)
+	}
+}
+
+func TestResolveCompletionCounts_BestOfBelowN_ReturnsError(t *testing.T) {
+	// Given: best_of smaller than n
+	// When
+	_, _, err := resolveCompletionCounts(api.NewOptInt(3), api.NewOptInt(2))
+	// Then
+	if err == nil {
+		t.Error("expected error when best_of < n")
+	}
+}
+
+// --- bestCompletionCandidates ---
+
+func TestBestCompletionCandidates_ReturnsHighestScoresFirst(t *testing.T) {
+	// Given
+	candidates := []completionCandidate{
+		{Text: "a", Score: 0.2},
+		{Text: "b", Score: 0.9},
+		{Text: "c", Score: 0.5},
+	}
+	// When
+	got := bestCompletionCandidates(candidates, 2)
+	// Then
+	if len(got) != 2 || got[0].Text != "b" || got[1].Text != "c" {
+		t.Errorf("expected [b c], got %v", got)
+	}
+}
+
+func TestCompletionCandidateScore_IsDeterministicAndInUnitRange(t *testing.T) {
+	// Given
+	// When
+	first := completionCandidateScore("text", 1)
+	second := completionCandidateScore("text", 1)
+	// Then
+	if first != second || first < 0 || first >= 1 {
+		t.Errorf("expected identical scores in [0, 1), got %v and %v", first, second)
+	}
+}
+
+// --- fillInMiddleMarker ---
+
+func TestFillInMiddleMarker_QuotesPromptTailAndSuffixHead(t *testing.T) {
+	// Given
+	prompt := "def add(a, b):\n    "
+	suffix := "\n\nprint(add(1, 2))"
+	// When
+	got := fillInMiddleMarker(prompt, suffix)
+	// Then
+	want := `[fill between "def add(a, b):" and "print(add(1, 2))"]`
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestFillInMiddleMarker_TruncatesLongContext(t *testing.T) {
+	// Given: prompt and suffix longer than fimContextRunes
+	prompt := strings.Repeat("a", 40) + "END"
+	suffix := "START" + strings.Repeat("b", 40)
+	// When
+	got := fillInMiddleMarker(prompt, suffix)
+	// Then
+	if !strings.Contains(got, `"`+strings.Repeat("a", fimContextRunes-3)+`END"`) ||
+		!strings.Contains(got, `"START`+strings.Repeat("b", fimContextRunes-5)+`"`) {
+		t.Errorf("expected truncated context, got %q", got)
+	}
+}
+
+// --- fitBetween ---
+
+func TestFitBetween_AddsMissingSpaces(t *testing.T) {
+	// Given: prompt and suffix that touch the middle without whitespace
+	// When
+	got := fitBetween("The quick", "brown fox", "jumps")
+	// Then
+	if got != " brown fox " {
+		t.Errorf("expected %q, got %q", " brown fox ", got)
+	}
+}
+
+func TestFitBetween_KeepsExistingWhitespace(t *testing.T) {
+	// Given: prompt ending and suffix starting with whitespace
+	// When
+	got := fitBetween("line one\n", "middle", "\nline three")
+	// Then
+	if got != "middle" {
+		t.Errorf("expected %q, got %q", "middle", got)
+	}
+}
+
+// --- completionPrompt ---
+
+func TestCompletionPrompt_String_ReturnsString(t *testing.T) {
+	// Given
+	prompt := api.NewStringCreateCompletionRequestPrompt("hello")
+	// When
+	got, tokens, err := completionPrompt(prompt)
+	// Then
+	if err != nil || got != "hello" || tokens != countTokens("hello") {
+		t.Errorf("expected %q, got %q with %d tokens (%v)", "hello", got, tokens, err)
+	}
+}
+
+func TestCompletionPrompt_StringArray_ReturnsFirst(t *testing.T) {
+	// Given
+	prompt := api.NewAnyArrayCreateCompletionRequestPrompt([]jx.Raw{jx.Raw(`"first"`), jx.Raw(`"second"`)})
+	// When
+	got, _, err := completionPrompt(prompt)
+	// Then
+	if err != nil || got != "first" {
+		t.Errorf("expected %q, got %q (%v)", "first", got, err)
+	}
+}
+
+func TestCompletionPrompt_TokenArray_RendersPlaceholders(t *testing.T) {
+	// Given
+	prompt := api.NewAnyArrayCreateCompletionRequestPrompt([]jx.Raw{jx.Raw(`15339`), jx.Raw(`1917`)})
+	// When
+	got, tokens, err := completionPrompt(prompt)
+	// Then: each token ID is one prompt token
+	if err != nil || got != "<|15339|><|1917|>" || tokens != 2 {
+		t.Errorf("expected placeholders of 2 tokens, got %q with %d tokens (%v)", got, tokens, err)
+	}
+}
+
+func TestCompletionPrompt_TokenArrays_ReturnsFirst(t *testing.T) {
+	// Given
+	prompt := api.NewAnyArrayCreateCompletionRequestPrompt([]jx.Raw{jx.Raw(`[1, 2]`), jx.Raw(`[3]`)})
+	// When
+	got, tokens, err := completionPrompt(prompt)
+	// Then
+	if err != nil || got != "<|1|><|2|>" || tokens != 2 {
+		t.Errorf("expected first token array of 2 tokens, got %q with %d tokens (%v)", got, tokens, err)
+	}
+}
+
+func TestCompletionPrompt_OutOfVocabularyToken_ReturnsError(t *testing.T) {
+	// Given
+	prompt := api.NewAnyArrayCreateCompletionRequestPrompt([]jx.Raw{jx.Raw(`-1`)})
+	// When
+	_, _, err := completionPrompt(prompt)
+	// Then
+	if err == nil {
+		t.Error("expected error for negative token ID")
+	}
+}
+
+func TestCompletionPrompt_InvalidItems_ReturnsError(t *testing.T) {
+	// Given: an array of booleans
+	prompt := api.NewAnyArrayCreateCompletionRequestPrompt([]jx.Raw{jx.Raw(`true`)})
+	// When
+	_, _, err := completionPrompt(prompt)
+	// Then
+	if err == nil {
+		t.Error("expected error for invalid prompt items")
+	}
+}
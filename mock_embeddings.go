@@ -1,6 +1,11 @@
 package main
 
-import "openai-mokku/api"
+import (
+	"math"
+	"sort"
+
+	"openai-mokku/api"
+)
 
 // defaultEmbeddingDimensions is the number of dimensions used when none is specified.
 const defaultEmbeddingDimensions = 1536
@@ -29,3 +34,126 @@ func generateVector(text string, dimensions int) []float64 {
 	}
 	return vector
 }
+
+// cosineSimilarity returns the cosine similarity of two vectors of equal length.
+// Returns 0 if the lengths differ or either vector has zero magnitude.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// embeddingMatch is a previously embedded input ranked against a query.
+type embeddingMatch struct {
+	Input      string  `json:"input"`
+	Model      string  `json:"model"`
+	Dimensions int     `json:"dimensions"`
+	Score      float64 `json:"score"`
+}
+
+// embeddingEntry is a single input recorded by the embedding index.
+type embeddingEntry struct {
+	input  string
+	model  string
+	vector []float64
+}
+
+// embeddingKey identifies a unique embedded input.
+type embeddingKey struct {
+	model      string
+	input      string
+	dimensions int
+}
+
+// maxIndexedEmbeddings is the number of embedded inputs kept for similarity search.
+const maxIndexedEmbeddings = 10000
+
+// embeddingIndex records the inputs embedded by the mock so that they can be searched later.
+// The oldest inputs are forgotten once maxIndexedEmbeddings is exceeded.
+// It is safe for concurrent use.
+type embeddingIndex struct {
+	entries *boundedMap[embeddingKey, embeddingEntry]
+}
+
+// newEmbeddingIndex creates an empty embedding index.
+func newEmbeddingIndex() *embeddingIndex {
+	return &embeddingIndex{entries: newBoundedMap[embeddingKey, embeddingEntry](maxIndexedEmbeddings)}
+}
+
+// Add records an embedded input. Duplicate inputs are ignored.
+func (idx *embeddingIndex) Add(model, input string, vector []float64) {
+	key := embeddingKey{model: model, input: input, dimensions: len(vector)}
+	idx.entries.Put(key, embeddingEntry{input: input, model: model, vector: vector})
+}
+
+// Search ranks recorded inputs by cosine similarity to the query, highest first.
+// The query is embedded with each entry's dimensions so entries of any size are comparable.
+// If model is non-empty only entries embedded with that model are considered.
+// At most topK matches are returned; topK <= 0 returns all matches.
+func (idx *embeddingIndex) Search(query, model string, topK int) []embeddingMatch {
+	entries := idx.entries.Values()
+	matches := make([]embeddingMatch, 0, len(entries))
+	queryVectors := make(map[int][]float64)
+	for _, e := range entries {
+		if model != "" && e.model != model {
+			continue
+		}
+		dims := len(e.vector)
+		qv, ok := queryVectors[dims]
+		if !ok {
+			qv = generateVector(query, dims)
+			queryVectors[dims] = qv
+		}
+		matches = append(matches, embeddingMatch{
+			Input:      e.input,
+			Model:      e.model,
+			Dimensions: dims,
+			Score:      cosineSimilarity(qv, e.vector),
+		})
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	if topK > 0 && len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches
+}
+
+// Len returns the number of recorded inputs.
+func (idx *embeddingIndex) Len() int {
+	return idx.entries.Len()
+}
+
+// Reset removes all recorded inputs.
+func (idx *embeddingIndex) Reset() {
+	idx.entries.Clear()
+}
+
+// Snapshot returns the recorded inputs, oldest first, for handing them off to a new process.
+func (idx *embeddingIndex) Snapshot() []handoffEmbedding {
+	entries := idx.entries.Values()
+	embeddings := make([]handoffEmbedding, len(entries))
+	for i, e := range entries {
+		embeddings[i] = handoffEmbedding{Model: e.model, Input: e.input, Vector: e.vector}
+	}
+	return embeddings
+}
+
+// Restore records inputs handed off by a previous process.
+func (idx *embeddingIndex) Restore(embeddings []handoffEmbedding) {
+	for _, e := range embeddings {
+		idx.Add(e.Model, e.Input, e.Vector)
+	}
+}
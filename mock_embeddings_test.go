@@ -1,6 +1,7 @@
 package main
 
 import (
+	"math"
 	"testing"
 
 	"openai-mokku/api"
@@ -124,3 +125,138 @@ func TestNormalizeInputStrings_EmptyStringSlice_ReturnsEmpty(t *testing.T) {
 		t.Errorf("expected empty result, got %d elements", len(got))
 	}
 }
+
+// --- cosineSimilarity ---
+
+func TestCosineSimilarity_IdenticalVectors_ReturnsOne(t *testing.T) {
+	// Given: the same vector twice
+	v := generateVector("same", 32)
+	// When
+	got := cosineSimilarity(v, v)
+	// Then
+	if math.Abs(got-1) > 1e-9 {
+		t.Errorf("expected 1, got %v", got)
+	}
+}
+
+func TestCosineSimilarity_OppositeVectors_ReturnsMinusOne(t *testing.T) {
+	// Given: a vector and its negation
+	a := []float64{1, 2, 3}
+	b := []float64{-1, -2, -3}
+	// When
+	got := cosineSimilarity(a, b)
+	// Then
+	if math.Abs(got+1) > 1e-9 {
+		t.Errorf("expected -1, got %v", got)
+	}
+}
+
+func TestCosineSimilarity_LengthMismatch_ReturnsZero(t *testing.T) {
+	// Given: vectors of different lengths
+	// When
+	got := cosineSimilarity([]float64{1, 2}, []float64{1, 2, 3})
+	// Then
+	if got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+}
+
+func TestCosineSimilarity_ZeroVector_ReturnsZero(t *testing.T) {
+	// Given: a zero-magnitude vector
+	// When
+	got := cosineSimilarity([]float64{0, 0}, []float64{1, 1})
+	// Then
+	if got != 0 {
+		t.Errorf("expected 0, got %v", got)
+	}
+}
+
+// --- embeddingIndex ---
+
+func TestEmbeddingIndex_Search_ExactInputRanksFirst(t *testing.T) {
+	// Given: several embedded inputs
+	idx := newEmbeddingIndex()
+	for _, text := range []string{"apple pie", "banana bread", "cherry tart"} {
+		idx.Add("text-embedding-3-small", text, generateVector(text, 64))
+	}
+	// When: searching for one of the inputs verbatim
+	got := idx.Search("banana bread", "", 0)
+	// Then: it ranks first with a score of 1
+	if len(got) != 3 {
+		t.Fatalf("expected 3 matches, got %d", len(got))
+	}
+	if got[0].Input != "banana bread" {
+		t.Errorf("expected 'banana bread' first, got %q", got[0].Input)
+	}
+	if math.Abs(got[0].Score-1) > 1e-9 {
+		t.Errorf("expected score 1 for exact match, got %v", got[0].Score)
+	}
+}
+
+func TestEmbeddingIndex_Search_SortedByDescendingScore(t *testing.T) {
+	// Given: several embedded inputs
+	idx := newEmbeddingIndex()
+	for _, text := range []string{"one", "two", "three", "four"} {
+		idx.Add("m", text, generateVector(text, 16))
+	}
+	// When
+	got := idx.Search("query", "", 0)
+	// Then: scores never increase
+	for i := 1; i < len(got); i++ {
+		if got[i].Score > got[i-1].Score {
+			t.Errorf("results not sorted at index %d: %v > %v", i, got[i].Score, got[i-1].Score)
+		}
+	}
+}
+
+func TestEmbeddingIndex_Search_TopKLimitsResults(t *testing.T) {
+	// Given: three embedded inputs
+	idx := newEmbeddingIndex()
+	for _, text := range []string{"a", "b", "c"} {
+		idx.Add("m", text, generateVector(text, 8))
+	}
+	// When
+	got := idx.Search("a", "", 2)
+	// Then
+	if len(got) != 2 {
+		t.Errorf("expected 2 matches, got %d", len(got))
+	}
+}
+
+func TestEmbeddingIndex_Search_FiltersByModel(t *testing.T) {
+	// Given: inputs embedded with two different models
+	idx := newEmbeddingIndex()
+	idx.Add("model-a", "shared text", generateVector("shared text", 8))
+	idx.Add("model-b", "other text", generateVector("other text", 8))
+	// When
+	got := idx.Search("shared text", "model-b", 0)
+	// Then: only model-b entries are returned
+	if len(got) != 1 || got[0].Model != "model-b" {
+		t.Errorf("expected a single model-b match, got %+v", got)
+	}
+}
+
+func TestEmbeddingIndex_Add_DeduplicatesInputs(t *testing.T) {
+	// Given: the same input embedded twice
+	idx := newEmbeddingIndex()
+	idx.Add("m", "dup", generateVector("dup", 8))
+	idx.Add("m", "dup", generateVector("dup", 8))
+	// When
+	got := idx.Search("dup", "", 0)
+	// Then
+	if len(got) != 1 {
+		t.Errorf("expected 1 match after deduplication, got %d", len(got))
+	}
+}
+
+func TestEmbeddingIndex_Reset_RemovesAllEntries(t *testing.T) {
+	// Given: a populated index
+	idx := newEmbeddingIndex()
+	idx.Add("m", "text", generateVector("text", 8))
+	// When
+	idx.Reset()
+	// Then
+	if got := idx.Search("text", "", 0); len(got) != 0 {
+		t.Errorf("expected empty index after reset, got %d matches", len(got))
+	}
+}
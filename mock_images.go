@@ -0,0 +1,275 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// defaultImageSize is the image size used when none (or "auto") is specified.
+const defaultImageSize = "1024x1024"
+
+// maxStoredImages is the number of generated images kept for URL retrieval.
+const maxStoredImages = 100
+
+// parseImageSize parses a "WIDTHxHEIGHT" size string.
+// "auto" and the empty string resolve to defaultImageSize.
+func parseImageSize(size string) (width, height int, ok bool) {
+	if size == "" || size == "auto" {
+		size = defaultImageSize
+	}
+	w, h, found := strings.Cut(size, "x")
+	if !found {
+		return 0, 0, false
+	}
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// imageSeed returns the hash that determines the pixels of the variant-th image for prompt.
+// The first variant is derived from the prompt alone so it can be recomputed by tests.
+func imageSeed(prompt string, variant int) [sha256.Size]byte {
+	if variant == 0 {
+		return sha256.Sum256([]byte(prompt))
+	}
+	return sha256.Sum256([]byte(fmt.Sprintf("%s#%d", prompt, variant)))
+}
+
+// generateImagePNG renders a deterministic opaque placeholder image for the prompt as PNG.
+func generateImagePNG(prompt string, variant, width, height int) []byte {
+	return encodeImage(renderImage(prompt, variant, width, height, false), "png")
+}
+
+// renderImage renders a deterministic placeholder image for the prompt.
+// The background is a 4x2 grid of colors taken from the prompt hash (left transparent when
+// transparent is set), overlaid with a white panel showing the hash prefix and the prompt
+// text, so the prompt that produced an image can be verified both by pixel comparison and by eye.
+func renderImage(prompt string, variant, width, height int, transparent bool) *image.RGBA {
+	seed := imageSeed(prompt, variant)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	// Background: 8 color blocks from the first 24 bytes of the hash
+	for i := 0; i < 8 && !transparent; i++ {
+		col, row := i%4, i/4
+		block := image.Rect(col*width/4, row*height/2, (col+1)*width/4, (row+1)*height/2)
+		c := color.RGBA{R: seed[i*3], G: seed[i*3+1], B: seed[i*3+2], A: 0xFF}
+		draw.Draw(img, block, &image.Uniform{C: c}, image.Point{}, draw.Src)
+	}
+
+	// Text panel
+	scale := max(1, min(width, height)/256)
+	margin := width / 16
+	panel := image.Rect(margin, height/4, width-margin, height*3/4)
+	draw.Draw(img, panel, image.White, image.Point{}, draw.Src)
+
+	face := basicfont.Face7x13
+	lineChars := max(1, (panel.Dx()-2*scale*face.Advance)/(scale*face.Advance))
+	maxLines := max(1, (panel.Dy()-face.Height*scale)/(face.Height*scale))
+	lines := append([]string{"#" + hex.EncodeToString(seed[:8])}, wrapText(prompt, lineChars)...)
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+
+	text := image.NewRGBA(image.Rect(0, 0, (panel.Dx()+scale-1)/scale, (panel.Dy()+scale-1)/scale))
+	draw.Draw(text, text.Bounds(), image.White, image.Point{}, draw.Src)
+	drawer := &font.Drawer{Dst: text, Src: image.Black, Face: face}
+	for i, line := range lines {
+		drawer.Dot = fixed.P(face.Advance, (i+1)*face.Height+face.Ascent/2)
+		drawer.DrawString(line)
+	}
+	for y := 0; y < panel.Dy(); y++ {
+		for x := 0; x < panel.Dx(); x++ {
+			img.Set(panel.Min.X+x, panel.Min.Y+y, text.At(x/scale, y/scale))
+		}
+	}
+
+	return img
+}
+
+// encodeImage encodes img as JPEG when format is "jpeg", as lossless WebP when it is "webp", and as
+// PNG otherwise.
+func encodeImage(img image.Image, format string) []byte {
+	var buf bytes.Buffer
+	switch format {
+	case "jpeg":
+		_ = jpeg.Encode(&buf, img, nil)
+	case "webp":
+		return encodeWebP(img)
+	default:
+		_ = png.Encode(&buf, img)
+	}
+	return buf.Bytes()
+}
+
+// wrapText splits text into lines of at most width runes, breaking on spaces where possible.
+func wrapText(text string, width int) []string {
+	var lines []string
+	var current []rune
+	for _, word := range strings.Fields(text) {
+		w := []rune(word)
+		if len(current) > 0 && len(current)+1+len(w) > width {
+			lines = append(lines, string(current))
+			current = nil
+		}
+		if len(current) > 0 {
+			current = append(current, ' ')
+		}
+		current = append(current, w...)
+		for len(current) > width {
+			lines = append(lines, string(current[:width]))
+			current = current[width:]
+		}
+	}
+	if len(current) > 0 {
+		lines = append(lines, string(current))
+	}
+	return lines
+}
+
+// gptImageModelPrefix identifies GPT image models, which bill by token and always return b64_json.
+const gptImageModelPrefix = "gpt-image-"
+
+// gptImageOutputTokens is the number of output tokens billed per GPT image by quality and size.
+var gptImageOutputTokens = map[string]map[string]int{
+	"low":    {"1024x1024": 272, "1024x1536": 408, "1536x1024": 400},
+	"medium": {"1024x1024": 1056, "1024x1536": 1584, "1536x1024": 1568},
+	"high":   {"1024x1024": 4160, "1024x1536": 6240, "1536x1024": 6208},
+}
+
+// isGPTImageModel reports whether model is a token-billed GPT image model.
+func isGPTImageModel(model string) bool {
+	return strings.HasPrefix(model, gptImageModelPrefix)
+}
+
+// resolveGPTImageOptions validates GPT image parameters and resolves "auto" values to the
+// concrete quality, size, and background the mock renders with.
+func resolveGPTImageOptions(quality, size, background, outputFormat string) (string, string, string, error) {
+	if quality == "" || quality == "auto" {
+		quality = "medium"
+	}
+	tokensBySize, ok := gptImageOutputTokens[quality]
+	if !ok {
+		return "", "", "", newInvalidRequestError("quality",
+			fmt.Sprintf("Invalid value: '%s'. Supported values are: 'low', 'medium', 'high', and 'auto'.", quality))
+	}
+	if size == "auto" {
+		size = defaultImageSize
+	}
+	if _, ok := tokensBySize[size]; !ok {
+		return "", "", "", newInvalidRequestError("size",
+			fmt.Sprintf("Invalid value: '%s'. Supported values are: '1024x1024', '1024x1536', '1536x1024', and 'auto'.", size))
+	}
+	if background == "auto" {
+		background = "opaque"
+	}
+	if background == "transparent" && outputFormat == "jpeg" {
+		return "", "", "", newInvalidRequestError("background",
+			"Transparent background is not supported for output_format 'jpeg'. Use 'png' or 'webp'.")
+	}
+	return quality, size, background, nil
+}
+
+// imageURLTTL is how long an image URL can be fetched, like the signed URLs of the images API.
+const imageURLTTL = time.Hour
+
+// storedImage is a generated image served by URL until it expires.
+type storedImage struct {
+	id        string
+	data      []byte
+	expiresAt time.Time
+}
+
+// imageStore keeps the most recently generated images so that they can be served by URL until
+// imageURLTTL has passed on the virtual clock. It is safe for concurrent use.
+type imageStore struct {
+	images *boundedMap[string, storedImage]
+	clock  *virtualClock
+}
+
+// newImageStore creates an empty image store whose URLs expire by clock.
+func newImageStore(clock *virtualClock) *imageStore {
+	return &imageStore{images: newBoundedMap[string, storedImage](maxStoredImages), clock: clock}
+}
+
+// Put stores an image, evicting the oldest image once maxStoredImages is exceeded, and returns
+// when its URL expires.
+func (s *imageStore) Put(id string, data []byte) time.Time {
+	expiresAt := s.clock.Now().Add(imageURLTTL)
+	s.images.Put(id, storedImage{id: id, data: data, expiresAt: expiresAt})
+	return expiresAt
+}
+
+// Get returns a stored image and when its URL expires. Expired images are still returned so
+// they can be told apart from unknown ones.
+func (s *imageStore) Get(id string) ([]byte, time.Time, bool) {
+	img, ok := s.images.Get(id)
+	return img.data, img.expiresAt, ok
+}
+
+// Expired reports whether a URL expiring at expiresAt can no longer be fetched.
+func (s *imageStore) Expired(expiresAt time.Time) bool {
+	return !s.clock.Now().Before(expiresAt)
+}
+
+// Len returns the number of stored images.
+func (s *imageStore) Len() int {
+	return s.images.Len()
+}
+
+// Snapshot returns the stored images, oldest first, for handing them off to a new process.
+func (s *imageStore) Snapshot() []handoffImage {
+	stored := s.images.Values()
+	images := make([]handoffImage, len(stored))
+	for i, img := range stored {
+		images[i] = handoffImage{ID: img.id, Data: img.data, ExpiresAt: img.expiresAt}
+	}
+	return images
+}
+
+// Restore stores images handed off by a previous process, keeping their expiry.
+func (s *imageStore) Restore(images []handoffImage) {
+	for _, img := range images {
+		s.images.Put(img.ID, storedImage{id: img.ID, data: img.Data, expiresAt: img.ExpiresAt})
+	}
+}
+
+type baseURLContextKey struct{}
+
+// withBaseURL stores the externally visible base URL of the request in the context.
+func withBaseURL(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, baseURLContextKey{}, requestBaseURL(r))
+}
+
+// requestBaseURL returns the externally visible base URL of the server a request was sent to.
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host
+}
+
+// baseURLFromContext returns the base URL stored by withBaseURL.
+func baseURLFromContext(ctx context.Context) string {
+	baseURL, _ := ctx.Value(baseURLContextKey{}).(string)
+	return baseURL
+}
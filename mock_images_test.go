@@ -0,0 +1,260 @@
+package main
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// --- parseImageSize ---
+
+func TestParseImageSize_ValidSize_ReturnsDimensions(t *testing.T) {
+	// Given
+	// When
+	w, h, ok := parseImageSize("1792x1024")
+	// Then
+	if !ok || w != 1792 || h != 1024 {
+		t.Errorf("expected 1792x1024, got %dx%d (ok=%v)", w, h, ok)
+	}
+}
+
+func TestParseImageSize_Auto_ReturnsDefault(t *testing.T) {
+	// Given: "auto" size
+	// When
+	w, h, ok := parseImageSize("auto")
+	// Then
+	if !ok || w != 1024 || h != 1024 {
+		t.Errorf("expected 1024x1024, got %dx%d (ok=%v)", w, h, ok)
+	}
+}
+
+func TestParseImageSize_Malformed_ReturnsNotOK(t *testing.T) {
+	// Given: sizes that are not WIDTHxHEIGHT
+	for _, size := range []string{"large", "1024", "0x10", "ax b"} {
+		// When
+		_, _, ok := parseImageSize(size)
+		// Then
+		if ok {
+			t.Errorf("expected %q to be rejected", size)
+		}
+	}
+}
+
+// --- generateImagePNG ---
+
+func TestGenerateImagePNG_DecodesWithRequestedSize(t *testing.T) {
+	// Given
+	// When
+	data := generateImagePNG("a red bicycle", 0, 256, 128)
+	// Then
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("expected valid PNG: %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 128 {
+		t.Errorf("expected 256x128, got %dx%d", b.Dx(), b.Dy())
+	}
+}
+
+func TestGenerateImagePNG_IsDeterministic(t *testing.T) {
+	// Given: same prompt and size
+	// When
+	first := generateImagePNG("a red bicycle", 0, 256, 256)
+	second := generateImagePNG("a red bicycle", 0, 256, 256)
+	// Then
+	if !bytes.Equal(first, second) {
+		t.Error("expected identical images for the same prompt")
+	}
+}
+
+func TestGenerateImagePNG_DifferentPromptsDiffer(t *testing.T) {
+	// Given: two different prompts
+	// When
+	a := generateImagePNG("a red bicycle", 0, 256, 256)
+	b := generateImagePNG("a blue bicycle", 0, 256, 256)
+	// Then
+	if bytes.Equal(a, b) {
+		t.Error("expected different images for different prompts")
+	}
+}
+
+func TestGenerateImagePNG_VariantsDiffer(t *testing.T) {
+	// Given: the same prompt with two variant indexes
+	// When
+	a := generateImagePNG("a red bicycle", 0, 256, 256)
+	b := generateImagePNG("a red bicycle", 1, 256, 256)
+	// Then
+	if bytes.Equal(a, b) {
+		t.Error("expected different images for different variants")
+	}
+}
+
+func TestGenerateImagePNG_BackgroundColorFromPromptHash(t *testing.T) {
+	// Given: a prompt whose hash determines the top-left block color
+	seed := imageSeed("hash colors", 0)
+	// When
+	img, err := png.Decode(bytes.NewReader(generateImagePNG("hash colors", 0, 256, 256)))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	// Then: the top-left pixel has the first hash color
+	r, g, b, _ := img.At(0, 0).RGBA()
+	if uint8(r>>8) != seed[0] || uint8(g>>8) != seed[1] || uint8(b>>8) != seed[2] {
+		t.Errorf("expected color %v, got (%d,%d,%d)", seed[:3], r>>8, g>>8, b>>8)
+	}
+}
+
+// --- wrapText ---
+
+func TestWrapText_BreaksOnSpaces(t *testing.T) {
+	// Given
+	// When
+	got := wrapText("the quick brown fox", 10)
+	// Then
+	want := []string{"the quick", "brown fox"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestWrapText_SplitsLongWords(t *testing.T) {
+	// Given: a word longer than the line width
+	// When
+	got := wrapText("abcdefghij", 4)
+	// Then
+	want := []string{"abcd", "efgh", "ij"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+// --- imageStore ---
+
+func TestImageStore_EvictsOldestBeyondCapacity(t *testing.T) {
+	// Given: a store filled past capacity
+	store := newImageStore(newVirtualClock())
+	for i := 0; i <= maxStoredImages; i++ {
+		store.Put(strings.Repeat("x", i+1), []byte{byte(i)})
+	}
+	// When
+	_, _, oldest := store.Get("x")
+	_, _, newest := store.Get(strings.Repeat("x", maxStoredImages+1))
+	// Then
+	if oldest {
+		t.Error("expected oldest image to be evicted")
+	}
+	if !newest {
+		t.Error("expected newest image to be retained")
+	}
+}
+
+func TestImageStore_URLsExpireOnTheVirtualClock(t *testing.T) {
+	// Given
+	clock := newVirtualClock()
+	store := newImageStore(clock)
+	expiresAt := store.Put("img", []byte{1})
+
+	// When / Then: the URL is valid until imageURLTTL has passed on the clock
+	if store.Expired(expiresAt) {
+		t.Fatal("expected a new image not to be expired")
+	}
+	if _, err := clock.Advance(imageURLTTL.Seconds()); err != nil {
+		t.Fatal(err)
+	}
+	data, got, ok := store.Get("img")
+	if !ok || got != expiresAt || len(data) != 1 {
+		t.Fatalf("expected the expired image to be kept, got %v %v", got, ok)
+	}
+	if !store.Expired(expiresAt) {
+		t.Error("expected the image to expire after an hour")
+	}
+}
+
+func TestRenderImage_TransparentBackground_LeavesBackgroundClear(t *testing.T) {
+	// Given: a transparent rendering
+	// When
+	img := renderImage("glass", 0, 256, 256, true)
+	// Then: the top-left corner is fully transparent while the text panel is opaque
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Errorf("expected transparent background, got alpha %d", a)
+	}
+	if _, _, _, a := img.At(128, 128).RGBA(); a != 0xFFFF {
+		t.Errorf("expected opaque text panel, got alpha %d", a)
+	}
+}
+
+func TestEncodeImage_JPEG_ProducesJPEG(t *testing.T) {
+	// Given
+	img := renderImage("jpeg please", 0, 64, 64, false)
+	// When
+	data := encodeImage(img, "jpeg")
+	// Then: JPEG SOI marker
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		t.Errorf("expected JPEG data, got prefix % x", data[:min(len(data), 4)])
+	}
+}
+
+func TestEncodeImage_WebP_ProducesWebP(t *testing.T) {
+	// Given
+	img := renderImage("webp please", 0, 64, 64, false)
+	// When
+	data := encodeImage(img, "webp")
+	// Then: RIFF container with a WEBP form type
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		t.Errorf("expected WebP data, got prefix % x", data[:min(len(data), 12)])
+	}
+}
+
+// --- resolveGPTImageOptions ---
+
+func TestResolveGPTImageOptions_AutoValues_ResolveToDefaults(t *testing.T) {
+	// Given: all auto values
+	// When
+	quality, size, background, err := resolveGPTImageOptions("auto", "auto", "auto", "png")
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quality != "medium" || size != "1024x1024" || background != "opaque" {
+		t.Errorf("expected medium/1024x1024/opaque, got %s/%s/%s", quality, size, background)
+	}
+}
+
+func TestResolveGPTImageOptions_UnsupportedSize_ReturnsInvalidRequest(t *testing.T) {
+	// Given: a DALL·E-only size
+	// When
+	_, _, _, err := resolveGPTImageOptions("high", "256x256", "auto", "png")
+	// Then
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.StatusCode != 400 || apiErr.Detail.Param == nil || *apiErr.Detail.Param != "size" {
+		t.Errorf("expected 400 on param size, got %+v", apiErr)
+	}
+}
+
+func TestResolveGPTImageOptions_UnsupportedQuality_ReturnsInvalidRequest(t *testing.T) {
+	// Given: a DALL·E-only quality
+	// When
+	_, _, _, err := resolveGPTImageOptions("hd", "1024x1024", "auto", "png")
+	// Then
+	if _, ok := err.(*APIError); !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+}
+
+func TestResolveGPTImageOptions_TransparentJPEG_ReturnsInvalidRequest(t *testing.T) {
+	// Given: a transparent background with JPEG output
+	// When
+	_, _, _, err := resolveGPTImageOptions("low", "1024x1024", "transparent", "jpeg")
+	// Then
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Detail.Param == nil || *apiErr.Detail.Param != "background" {
+		t.Errorf("expected error on param background, got %+v", apiErr.Detail)
+	}
+}
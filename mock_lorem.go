@@ -0,0 +1,59 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+)
+
+// LoremModelName is the model name that generates lorem ipsum text shaped by the penalty parameters.
+const LoremModelName = "mokku-lorem-1"
+
+// loremWordCount is the number of words generated per lorem response.
+const loremWordCount = 48
+
+// loremRepetitionBonus is how strongly an already-used word is preferred when no penalty applies,
+// so that zero penalties produce deliberately repetitive text.
+const loremRepetitionBonus = 0.6
+
+// loremVocabulary is the word list lorem responses are drawn from.
+var loremVocabulary = strings.Fields(`lorem ipsum dolor sit amet consectetur adipiscing elit sed do
+eiusmod tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud
+exercitation ullamco laboris nisi aliquip ex ea commodo consequat duis aute irure in
+reprehenderit voluptate velit esse cillum fugiat nulla pariatur excepteur sint occaecat cupidatat
+non proident sunt culpa qui officia deserunt mollit anim id est laborum`)
+
+// generateLoremText generates deterministic lorem ipsum text for the variant-th sample of the message
+// (like imageSeed, variants other than 0 are seeded separately) whose repetition is
+// modulated by presence_penalty and frequency_penalty the way sampling penalties act on logits:
+// each candidate word scores a seeded random base, plus loremRepetitionBonus if it was already used,
+// minus presencePenalty if it was used at all and frequencyPenalty per previous use.
+func generateLoremText(message string, variant int, presencePenalty, frequencyPenalty float64) string {
+	sum := sha256.Sum256([]byte(message))
+	if variant > 0 {
+		sum = sha256.Sum256([]byte(fmt.Sprintf("%s#%d", message, variant)))
+	}
+	rng := rand.New(rand.NewPCG(binary.LittleEndian.Uint64(sum[0:8]), binary.LittleEndian.Uint64(sum[8:16])))
+
+	counts := make(map[string]int)
+	words := make([]string, loremWordCount)
+	for i := range words {
+		best, bestScore := "", 0.0
+		for _, candidate := range loremVocabulary {
+			score := rng.Float64()
+			if n := counts[candidate]; n > 0 {
+				score += loremRepetitionBonus - presencePenalty - frequencyPenalty*float64(n)
+			}
+			if best == "" || score > bestScore {
+				best, bestScore = candidate, score
+			}
+		}
+		counts[best]++
+		words[i] = best
+	}
+
+	text := strings.Join(words, " ")
+	return strings.ToUpper(text[:1]) + text[1:] + "."
+}
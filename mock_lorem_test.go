@@ -0,0 +1,80 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// distinctWords returns the number of distinct words in a lorem response.
+func distinctWords(text string) int {
+	seen := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(strings.TrimSuffix(text, "."))) {
+		seen[w] = true
+	}
+	return len(seen)
+}
+
+// --- generateLoremText ---
+
+func TestGenerateLoremText_IsDeterministic(t *testing.T) {
+	// Given: the same message and penalties
+	// When
+	first := generateLoremText("hello", 0, 0.5, 0.5)
+	second := generateLoremText("hello", 0, 0.5, 0.5)
+	// Then
+	if first != second {
+		t.Errorf("expected deterministic text: %q vs %q", first, second)
+	}
+}
+
+func TestGenerateLoremText_HasFixedWordCount(t *testing.T) {
+	// Given
+	// When
+	got := generateLoremText("hello", 0, 0, 0)
+	// Then
+	if n := len(strings.Fields(got)); n != loremWordCount {
+		t.Errorf("expected %d words, got %d", loremWordCount, n)
+	}
+}
+
+func TestGenerateLoremText_ZeroPenalties_Repeats(t *testing.T) {
+	// Given: no penalties
+	// When
+	got := generateLoremText("hello", 0, 0, 0)
+	// Then: many words are reused
+	if n := distinctWords(got); n > loremWordCount/2 {
+		t.Errorf("expected deliberate repetition, got %d distinct words in %q", n, got)
+	}
+}
+
+func TestGenerateLoremText_HighPresencePenalty_NoRepeats(t *testing.T) {
+	// Given: the maximum presence penalty
+	// When
+	got := generateLoremText("hello", 0, 2, 0)
+	// Then: every word is distinct
+	if n := distinctWords(got); n != loremWordCount {
+		t.Errorf("expected %d distinct words, got %d in %q", loremWordCount, n, got)
+	}
+}
+
+func TestGenerateLoremText_FrequencyPenalty_IncreasesVariety(t *testing.T) {
+	// Given: a moderate frequency penalty versus none
+	// When
+	plain := distinctWords(generateLoremText("hello", 0, 0, 0))
+	penalized := distinctWords(generateLoremText("hello", 0, 0, 0.5))
+	// Then
+	if penalized <= plain {
+		t.Errorf("expected more distinct words with frequency penalty: %d vs %d", penalized, plain)
+	}
+}
+
+func TestGenerateLoremText_VariantsDiffer(t *testing.T) {
+	// Given: two variants of the same message
+	// When
+	first := generateLoremText("hello", 0, 0, 0)
+	second := generateLoremText("hello", 1, 0, 0)
+	// Then
+	if first == second {
+		t.Errorf("expected variants to differ, both %q", first)
+	}
+}
@@ -1,13 +1,35 @@
 package main
 
-import "openai-mokku/api"
+import (
+	"strings"
 
-// extractLastUserMessage returns the content of the last user-role message.
+	"openai-mokku/api"
+)
+
+// extractLastUserMessage returns the text content of the last user-role message.
 func extractLastUserMessage(messages []api.ChatCompletionRequestMessage) string {
 	for i := len(messages) - 1; i >= 0; i-- {
 		if messages[i].Role == api.ChatCompletionRequestMessageRoleUser {
-			return messages[i].Content
+			return messageContentText(messages[i].Content.Value)
 		}
 	}
 	return ""
 }
+
+// messageContentText flattens message content to text. Text parts are kept as-is,
+// input_audio parts are replaced by a fabricated transcript, and other parts are skipped.
+func messageContentText(content api.ChatCompletionRequestMessageContent) string {
+	if content.IsString() {
+		return content.String
+	}
+	var texts []string
+	for _, part := range content.ChatCompletionRequestMessageContentPartArray {
+		switch part.Type {
+		case api.ChatCompletionRequestMessageContentPartTypeText:
+			texts = append(texts, part.Text.Value)
+		case api.ChatCompletionRequestMessageContentPartTypeInputAudio:
+			texts = append(texts, inputAudioTranscript(part.InputAudio.Value))
+		}
+	}
+	return strings.Join(texts, " ")
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"encoding/base64"
+	"strings"
 	"testing"
 
 	"openai-mokku/api"
@@ -11,7 +13,7 @@ import (
 func TestExtractLastUserMessage_SingleUserMessage_ReturnsContent(t *testing.T) {
 	// Given
 	messages := []api.ChatCompletionRequestMessage{
-		{Role: api.ChatCompletionRequestMessageRoleUser, Content: "hello"},
+		{Role: api.ChatCompletionRequestMessageRoleUser, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("hello"))},
 	}
 	// When
 	got := extractLastUserMessage(messages)
@@ -24,10 +26,10 @@ func TestExtractLastUserMessage_SingleUserMessage_ReturnsContent(t *testing.T) {
 func TestExtractLastUserMessage_MultipleMessages_ReturnsLastUser(t *testing.T) {
 	// Given: system, then two user messages interleaved with assistant
 	messages := []api.ChatCompletionRequestMessage{
-		{Role: api.ChatCompletionRequestMessageRoleSystem, Content: "you are helpful"},
-		{Role: api.ChatCompletionRequestMessageRoleUser, Content: "first message"},
-		{Role: api.ChatCompletionRequestMessageRoleAssistant, Content: "first response"},
-		{Role: api.ChatCompletionRequestMessageRoleUser, Content: "second message"},
+		{Role: api.ChatCompletionRequestMessageRoleSystem, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("you are helpful"))},
+		{Role: api.ChatCompletionRequestMessageRoleUser, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("first message"))},
+		{Role: api.ChatCompletionRequestMessageRoleAssistant, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("first response"))},
+		{Role: api.ChatCompletionRequestMessageRoleUser, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("second message"))},
 	}
 	// When
 	got := extractLastUserMessage(messages)
@@ -40,7 +42,7 @@ func TestExtractLastUserMessage_MultipleMessages_ReturnsLastUser(t *testing.T) {
 func TestExtractLastUserMessage_NoUserMessages_ReturnsEmpty(t *testing.T) {
 	// Given: only system messages
 	messages := []api.ChatCompletionRequestMessage{
-		{Role: api.ChatCompletionRequestMessageRoleSystem, Content: "system only"},
+		{Role: api.ChatCompletionRequestMessageRoleSystem, Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("system only"))},
 	}
 	// When
 	got := extractLastUserMessage(messages)
@@ -59,3 +61,30 @@ func TestExtractLastUserMessage_EmptyMessages_ReturnsEmpty(t *testing.T) {
 		t.Errorf("expected empty string, got %q", got)
 	}
 }
+
+func TestExtractLastUserMessage_ContentParts_JoinsTextAndTranscript(t *testing.T) {
+	// Given: a user message with a text part, an image part, and an audio part
+	audio := api.ChatCompletionRequestMessageContentPartInputAudio{
+		Data:   base64.StdEncoding.EncodeToString(make([]byte, 32000)),
+		Format: api.ChatCompletionRequestMessageContentPartInputAudioFormatMp3,
+	}
+	messages := []api.ChatCompletionRequestMessage{{
+		Role: api.ChatCompletionRequestMessageRoleUser,
+		Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewChatCompletionRequestMessageContentPartArrayChatCompletionRequestMessageContent(
+			[]api.ChatCompletionRequestMessageContentPart{
+				{Type: api.ChatCompletionRequestMessageContentPartTypeText, Text: api.NewOptString("listen:")},
+				{Type: api.ChatCompletionRequestMessageContentPartTypeImageURL},
+				{Type: api.ChatCompletionRequestMessageContentPartTypeInputAudio, InputAudio: api.NewOptChatCompletionRequestMessageContentPartInputAudio(audio)},
+			})),
+	}}
+	// When
+	got := extractLastUserMessage(messages)
+	// Then: the text is followed by the fabricated transcript of the 2s clip
+	want := "listen: " + inputAudioTranscript(audio)
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if !strings.Contains(got, "[transcript 2.0s]") {
+		t.Errorf("expected transcript marker in %q", got)
+	}
+}
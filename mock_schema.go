@@ -1,6 +1,10 @@
 package main
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"openai-mokku/api"
+)
 
 // generateDummyValue generates a deterministic dummy value that conforms to the given JSON Schema.
 // Supported keywords: type, properties, required, items, enum.
@@ -104,3 +108,25 @@ func generateJSONFromSchemaBytes(schemaBytes []byte) string {
 	}
 	return generateJSONSchemaResponse(schemaMap)
 }
+
+// generateJSONModeContent returns the assistant content for a JSON-mode chat request.
+// A json_schema (or json_object with a schema) yields a conforming document; a bare json_object
+// wraps the echoed message in {"message": ...}. ok is false when JSON mode is not requested.
+func generateJSONModeContent(format api.OptChatCompletionResponseFormat, message string) (content string, ok bool) {
+	if !format.Set {
+		return "", false
+	}
+	switch format.Value.Type {
+	case api.ChatCompletionResponseFormatTypeJSONSchema, api.ChatCompletionResponseFormatTypeJSONObject:
+	default:
+		return "", false
+	}
+	if format.Value.JSONSchema.Set {
+		return generateJSONFromSchemaBytes(format.Value.JSONSchema.Value.Schema), true
+	}
+	if format.Value.Type == api.ChatCompletionResponseFormatTypeJSONSchema {
+		return `{}`, true
+	}
+	b, _ := json.Marshal(map[string]string{"message": message})
+	return string(b), true
+}
@@ -1,7 +1,10 @@
 package main
 
 import (
+	"encoding/json"
 	"testing"
+
+	"openai-mokku/api"
 )
 
 func TestGenerateDummyValue_StringType_ReturnsString(t *testing.T) {
@@ -193,3 +196,49 @@ func TestGenerateDummyValue_IsDeterministic(t *testing.T) {
 		t.Errorf("expected deterministic output: %q vs %q", v1, v2)
 	}
 }
+
+// --- generateJSONModeContent ---
+
+func TestGenerateJSONModeContent_NotSet_ReturnsFalse(t *testing.T) {
+	// Given: no response_format
+	// When
+	_, ok := generateJSONModeContent(api.OptChatCompletionResponseFormat{}, "hi")
+	// Then
+	if ok {
+		t.Error("expected JSON mode to be off")
+	}
+}
+
+func TestGenerateJSONModeContent_JSONObjectWithoutSchema_WrapsMessage(t *testing.T) {
+	// Given: json_object without a schema
+	format := api.NewOptChatCompletionResponseFormat(api.ChatCompletionResponseFormat{
+		Type: api.ChatCompletionResponseFormatTypeJSONObject,
+	})
+	// When
+	got, ok := generateJSONModeContent(format, `say "hi"`)
+	// Then
+	if !ok || got != `{"message":"say \"hi\""}` {
+		t.Errorf("expected wrapped message, got %q (ok=%v)", got, ok)
+	}
+}
+
+func TestGenerateJSONModeContent_JSONSchema_ConformsToSchema(t *testing.T) {
+	// Given: json_schema with a single string property
+	format := api.NewOptChatCompletionResponseFormat(api.ChatCompletionResponseFormat{
+		Type: api.ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: api.NewOptChatCompletionJSONSchemaSpec(api.ChatCompletionJSONSchemaSpec{
+			Name:   "answer",
+			Schema: []byte(`{"type":"object","properties":{"answer":{"type":"string"}},"required":["answer"]}`),
+		}),
+	})
+	// When
+	got, ok := generateJSONModeContent(format, "hi")
+	// Then
+	var obj map[string]interface{}
+	if !ok || json.Unmarshal([]byte(got), &obj) != nil {
+		t.Fatalf("expected valid JSON, got %q (ok=%v)", got, ok)
+	}
+	if _, has := obj["answer"]; !has {
+		t.Errorf("expected answer property, got %v", obj)
+	}
+}
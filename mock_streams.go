@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// maxStoredStreamCancellations is the number of cancelled streams kept for inspection.
+const maxStoredStreamCancellations = 100
+
+// Stream cancellation reasons.
+const (
+	streamCancelReasonClientDisconnected = "client_disconnected"
+	streamCancelReasonWriteFailed        = "write_failed"
+	streamCancelReasonTruncated          = "truncated"
+)
+
+// errStreamTruncated is the error of a stream cut off after its event limit.
+var errStreamTruncated = errors.New("stream truncated")
+
+// streamCancellation records where a streaming response was cut off.
+type streamCancellation struct {
+	ID          string    `json:"id"`
+	Path        string    `json:"path"`
+	Model       string    `json:"model"`
+	Reason      string    `json:"reason"`
+	ChunksSent  int       `json:"chunks_sent"`
+	BytesSent   int       `json:"bytes_sent"`
+	CancelledAt time.Time `json:"cancelled_at"`
+}
+
+// streamStats is the snapshot returned by GET /_mokku/streams.
+type streamStats struct {
+	Started       int                  `json:"started"`
+	Completed     int                  `json:"completed"`
+	Cancelled     int                  `json:"cancelled"`
+	Cancellations []streamCancellation `json:"cancellations"`
+	// Reconciled and Mismatched count the streams checked with the stream_reconciliation flag and
+	// those that differed from the non-streaming response.
+	Reconciled int              `json:"reconciled"`
+	Mismatched int              `json:"mismatched"`
+	Mismatches []streamMismatch `json:"mismatches"`
+}
+
+// streamLog counts streaming responses and keeps the most recent cancellations and reconciliation
+// mismatches. It is safe for concurrent use.
+type streamLog struct {
+	mu            sync.Mutex
+	started       int
+	completed     int
+	cancelled     int
+	cancellations *ringBuffer[streamCancellation]
+	reconciled    int
+	mismatched    int
+	mismatches    *ringBuffer[streamMismatch]
+}
+
+// newStreamLog creates an empty stream log.
+func newStreamLog() *streamLog {
+	return &streamLog{
+		cancellations: newRingBuffer[streamCancellation](maxStoredStreamCancellations),
+		mismatches:    newRingBuffer[streamMismatch](maxStoredStreamMismatches),
+	}
+}
+
+// Started records the start of a streaming response.
+func (l *streamLog) Started() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.started++
+}
+
+// Completed records a streaming response that was sent in full.
+func (l *streamLog) Completed() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.completed++
+}
+
+// Cancelled records a streaming response that was cut off, evicting the oldest
+// record once maxStoredStreamCancellations is exceeded.
+func (l *streamLog) Cancelled(c streamCancellation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cancelled++
+	l.cancellations.Push(c)
+}
+
+// Reconciled records a stream checked against the non-streaming response, with its mismatch if it
+// differed, evicting the oldest mismatch once maxStoredStreamMismatches is exceeded.
+func (l *streamLog) Reconciled(mismatch *streamMismatch) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reconciled++
+	if mismatch != nil {
+		l.mismatched++
+		l.mismatches.Push(*mismatch)
+	}
+}
+
+// Stats returns a snapshot of the stream counters, recent cancellations, and recent mismatches.
+func (l *streamLog) Stats() streamStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return streamStats{
+		Started:       l.started,
+		Completed:     l.completed,
+		Cancelled:     l.cancelled,
+		Cancellations: l.cancellations.Snapshot(),
+		Reconciled:    l.reconciled,
+		Mismatched:    l.mismatched,
+		Mismatches:    l.mismatches.Snapshot(),
+	}
+}
+
+// Reset clears all counters, cancellation records, and mismatches.
+func (l *streamLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.started, l.completed, l.cancelled, l.reconciled, l.mismatched = 0, 0, 0, 0, 0
+	l.cancellations.Clear()
+	l.mismatches.Clear()
+}
+
+// sseWriter writes server-sent events and tracks how much of the stream reached the client.
+// Once the request context is done, a write fails, or limit events were sent, every further send
+// is refused.
+type sseWriter struct {
+	ctx     context.Context
+	w       http.ResponseWriter
+	flusher http.Flusher
+	// limit is the number of events sent before the stream is truncated; 0 is unlimited.
+	limit  int
+	chunks int
+	bytes  int
+	err    error
+	// record keeps the events sent in events, for the reconciliation check.
+	record bool
+	events []any
+}
+
+// send writes v as a JSON data event and flushes it. It returns false if the stream is broken.
+func (s *sseWriter) send(v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	if !s.write(fmt.Sprintf("data: %s\n\n", data)) {
+		return false
+	}
+	if s.record {
+		s.events = append(s.events, v)
+	}
+	return true
+}
+
+// sendEvent writes v as a JSON data event of the named event type and flushes it, like send.
+func (s *sseWriter) sendEvent(event string, v interface{}) bool {
+	data, err := json.Marshal(v)
+	if err != nil {
+		s.err = err
+		return false
+	}
+	return s.write(fmt.Sprintf("event: %s\ndata: %s\n\n", event, data))
+}
+
+// sendDone writes the terminating [DONE] event.
+func (s *sseWriter) sendDone() bool {
+	return s.write("data: [DONE]\n\n")
+}
+
+func (s *sseWriter) write(event string) bool {
+	if s.err != nil {
+		return false
+	}
+	if err := s.ctx.Err(); err != nil {
+		s.err = err
+		return false
+	}
+	if s.limit > 0 && s.chunks >= s.limit {
+		s.err = errStreamTruncated
+		return false
+	}
+	n, err := fmt.Fprint(s.w, event)
+	s.bytes += n
+	if err != nil {
+		s.err = err
+		return false
+	}
+	s.chunks++
+	s.flusher.Flush()
+	return true
+}
+
+// cancelReason classifies why the stream was cut off.
+func (s *sseWriter) cancelReason() string {
+	if errors.Is(s.err, errStreamTruncated) {
+		return streamCancelReasonTruncated
+	}
+	if errors.Is(s.err, context.Canceled) || errors.Is(s.err, context.DeadlineExceeded) {
+		return streamCancelReasonClientDisconnected
+	}
+	return streamCancelReasonWriteFailed
+}
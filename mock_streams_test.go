@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// failingResponseWriter accepts limit bytes and then fails every write.
+type failingResponseWriter struct {
+	*httptest.ResponseRecorder
+	limit int
+}
+
+func (f *failingResponseWriter) Write(p []byte) (int, error) {
+	if f.Body.Len()+len(p) > f.limit {
+		return 0, errors.New("connection reset by peer")
+	}
+	return f.ResponseRecorder.Write(p)
+}
+
+// --- sseWriter ---
+
+func TestSSEWriter_Send_CountsChunksAndBytes(t *testing.T) {
+	// Given: a healthy stream
+	rec := httptest.NewRecorder()
+	stream := &sseWriter{ctx: context.Background(), w: rec, flusher: rec}
+	// When
+	ok := stream.send(map[string]int{"a": 1}) && stream.sendDone()
+	// Then
+	if !ok || stream.chunks != 2 || stream.bytes != rec.Body.Len() {
+		t.Errorf("expected 2 chunks and %d bytes, got ok=%v chunks=%d bytes=%d", rec.Body.Len(), ok, stream.chunks, stream.bytes)
+	}
+}
+
+func TestSSEWriter_CancelledContext_ReportsClientDisconnected(t *testing.T) {
+	// Given: a stream whose client has gone away
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	stream := &sseWriter{ctx: ctx, w: rec, flusher: rec}
+	// When
+	ok := stream.send(map[string]int{"a": 1})
+	// Then
+	if ok || stream.chunks != 0 || stream.cancelReason() != streamCancelReasonClientDisconnected {
+		t.Errorf("expected refused send with client_disconnected, got ok=%v chunks=%d reason=%s", ok, stream.chunks, stream.cancelReason())
+	}
+}
+
+func TestSSEWriter_WriteError_ReportsWriteFailedAndStops(t *testing.T) {
+	// Given: a connection that breaks after the first event
+	w := &failingResponseWriter{ResponseRecorder: httptest.NewRecorder(), limit: 20}
+	stream := &sseWriter{ctx: context.Background(), w: w, flusher: w}
+	// When
+	first := stream.send(map[string]int{"a": 1})
+	second := stream.send(map[string]int{"b": 2})
+	third := stream.sendDone()
+	// Then
+	if !first || second || third {
+		t.Fatalf("expected only the first send to succeed, got %v %v %v", first, second, third)
+	}
+	if stream.chunks != 1 || stream.cancelReason() != streamCancelReasonWriteFailed {
+		t.Errorf("expected 1 chunk and write_failed, got chunks=%d reason=%s", stream.chunks, stream.cancelReason())
+	}
+}
+
+func TestSSEWriter_Limit_ReportsTruncated(t *testing.T) {
+	// Given: a stream limited to one event
+	rec := httptest.NewRecorder()
+	stream := &sseWriter{ctx: context.Background(), w: rec, flusher: rec, limit: 1}
+	// When
+	first := stream.send(map[string]int{"a": 1})
+	done := stream.sendDone()
+	// Then: [DONE] is never written
+	if !first || done || strings.Contains(rec.Body.String(), "[DONE]") {
+		t.Fatalf("expected only the first send to succeed, got %v %v: %q", first, done, rec.Body.String())
+	}
+	if stream.chunks != 1 || stream.cancelReason() != streamCancelReasonTruncated {
+		t.Errorf("expected 1 chunk and truncated, got chunks=%d reason=%s", stream.chunks, stream.cancelReason())
+	}
+}
+
+// --- streamLog ---
+
+func TestStreamLog_Cancelled_EvictsOldest(t *testing.T) {
+	// Given
+	log := newStreamLog()
+	// When: more cancellations than are kept
+	for i := 0; i <= maxStoredStreamCancellations; i++ {
+		log.Cancelled(streamCancellation{ChunksSent: i})
+	}
+	// Then: the count is exact and the oldest record was dropped
+	stats := log.Stats()
+	if stats.Cancelled != maxStoredStreamCancellations+1 || len(stats.Cancellations) != maxStoredStreamCancellations {
+		t.Fatalf("expected %d cancelled and %d records, got %d and %d",
+			maxStoredStreamCancellations+1, maxStoredStreamCancellations, stats.Cancelled, len(stats.Cancellations))
+	}
+	if stats.Cancellations[0].ChunksSent != 1 {
+		t.Errorf("expected oldest record to be evicted, first is %d", stats.Cancellations[0].ChunksSent)
+	}
+}
+
+// --- StreamingHandler cancellation ---
+
+func TestStreamingHandler_ClientDisconnect_RecordsCancellation(t *testing.T) {
+	// Given: a streaming request whose client has already disconnected
+	state, _ := newServerState(Config{}, serverOptions{})
+	streams := state.streams
+	h := NewStreamingHandler(http.NotFoundHandler(), &MockHandler{}, http.NotFoundHandler(), state)
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)).WithContext(ctx)
+	// When
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	// Then
+	stats := streams.Stats()
+	if stats.Started != 1 || stats.Cancelled != 1 || stats.Completed != 0 {
+		t.Fatalf("expected 1 started and 1 cancelled, got %+v", stats)
+	}
+	c := stats.Cancellations[0]
+	if c.Reason != streamCancelReasonClientDisconnected || c.Model != "gpt-4o" || c.ChunksSent != 0 || c.Path != "/v1/chat/completions" {
+		t.Errorf("unexpected cancellation record: %+v", c)
+	}
+}
+
+func TestStreamingHandler_ClientDisconnect_RecordsCancellationInCapture(t *testing.T) {
+	// Given: request capture, and a streaming request whose client has already disconnected
+	state, _ := newServerState(Config{}, serverOptions{Capture: captureConfig{Size: 10}})
+	h := NewStreamingHandler(http.NotFoundHandler(), &MockHandler{}, http.NotFoundHandler(), state)
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)).WithContext(ctx)
+	// When
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	// Then: the captured request records where the stream was cut off
+	e, ok := state.capture.Get(1)
+	if !ok {
+		t.Fatal("expected the request to be captured")
+	}
+	want := capturedCancellation{Reason: streamCancelReasonClientDisconnected}
+	if e.Cancellation == nil || *e.Cancellation != want {
+		t.Errorf("expected cancellation %+v, got %+v", want, e.Cancellation)
+	}
+}
+
+func TestStreamingHandler_CompletedStream_CapturesNoCancellation(t *testing.T) {
+	// Given: request capture
+	state, _ := newServerState(Config{}, serverOptions{Capture: captureConfig{Size: 10}})
+	h := NewStreamingHandler(http.NotFoundHandler(), &MockHandler{}, http.NotFoundHandler(), state)
+	body := `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	// When: the stream is sent in full
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	// Then
+	e, ok := state.capture.Get(1)
+	if !ok {
+		t.Fatal("expected the request to be captured")
+	}
+	if e.Cancellation != nil || e.Chunks == nil || e.Chunks.Count == 0 {
+		t.Errorf("expected a completed stream, got cancellation %+v and chunks %+v", e.Cancellation, e.Chunks)
+	}
+}
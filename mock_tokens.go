@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"openai-mokku/api"
+)
+
+// bytesPerToken is the average number of bytes per token for English text in BPE tokenizers.
+const bytesPerToken = 4
+
+// tokenVocabularySize is the size of the mock token ID space (that of cl100k_base).
+const tokenVocabularySize = 100256
+
+// bannedTokenBias is the logit_bias value that bans a token outright.
+const bannedTokenBias = -100
+
+// countTokens returns a deterministic approximation of the number of BPE tokens in text.
+// Each run of letters or digits contributes one token per bytesPerToken bytes (rounded up),
+// and every punctuation or symbol rune counts as a token of its own. Whitespace is free.
+func countTokens(text string) int {
+	tokens := 0
+	wordBytes := 0
+	flushWord := func() {
+		if wordBytes > 0 {
+			tokens += (wordBytes + bytesPerToken - 1) / bytesPerToken
+			wordBytes = 0
+		}
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			wordBytes += utf8.RuneLen(r)
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushWord()
+			tokens++
+		}
+	}
+	flushWord()
+	return tokens
+}
+
+// countMessageTokens returns the number of tokens in the text of all messages.
+func countMessageTokens(messages []api.ChatCompletionRequestMessage) int {
+	total := 0
+	for _, m := range messages {
+		total += countTokens(messageContentText(m.Content.Value))
+	}
+	return total
+}
+
+// splitTokens splits text into the pieces counted by countTokens, the way a model emits tokens
+// while streaming. Letter/digit runs are cut every bytesPerToken bytes, each punctuation rune is a
+// piece of its own, and whitespace is attached to the start of the following piece (trailing
+// whitespace to the last piece). Concatenating the pieces always yields text.
+func splitTokens(text string) []string {
+	var pieces []string
+	start := 0
+	wordBytes := 0
+	emit := func(end int) {
+		if end > start {
+			pieces = append(pieces, text[start:end])
+			start = end
+		}
+	}
+	for i, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if wordBytes >= bytesPerToken {
+				emit(i)
+				wordBytes = 0
+			}
+			wordBytes += utf8.RuneLen(r)
+		case unicode.IsSpace(r):
+			if wordBytes > 0 {
+				emit(i)
+				wordBytes = 0
+			}
+		default:
+			if wordBytes > 0 {
+				emit(i)
+				wordBytes = 0
+			}
+			emit(i + utf8.RuneLen(r))
+		}
+	}
+	if start < len(text) {
+		if wordBytes == 0 && len(pieces) > 0 {
+			pieces[len(pieces)-1] += text[start:]
+		} else {
+			pieces = append(pieces, text[start:])
+		}
+	}
+	return pieces
+}
+
+// truncateTokens cuts text to its first n pieces as split by splitTokens, reporting whether it
+// was longer.
+func truncateTokens(text string, n int) (string, bool) {
+	pieces := splitTokens(text)
+	if len(pieces) <= n {
+		return text, false
+	}
+	return strings.Join(pieces[:max(n, 0)], ""), true
+}
+
+// tokenID returns the deterministic mock token ID of a piece produced by splitTokens.
+// As in BPE vocabularies, a piece with leading whitespace is a different token from the bare piece.
+func tokenID(piece string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(piece))
+	return int(h.Sum32() % tokenVocabularySize)
+}
+
+// validateLogitBias checks that every key is a token ID in the mock vocabulary and every
+// value is within [-100, 100], mirroring the OpenAI API's validation.
+func validateLogitBias(bias map[string]int) error {
+	for key, value := range bias {
+		id, err := strconv.Atoi(key)
+		if err != nil || id < 0 || id >= tokenVocabularySize {
+			return newInvalidRequestError("logit_bias",
+				fmt.Sprintf("Invalid key in 'logit_bias': %s. You should only be submitting non-negative integers.", key))
+		}
+		if value < -100 || value > 100 {
+			return newInvalidRequestError("logit_bias",
+				fmt.Sprintf("Invalid value in 'logit_bias': %d. Values must be between -100 and 100.", value))
+		}
+	}
+	return nil
+}
+
+// stripBannedTokens removes every token of text whose logit_bias is bannedTokenBias and
+// returns the remaining text with the number of tokens removed.
+func stripBannedTokens(text string, bias map[string]int) (string, int) {
+	if len(bias) == 0 {
+		return text, 0
+	}
+	var b strings.Builder
+	removed := 0
+	for _, piece := range splitTokens(text) {
+		if bias[strconv.Itoa(tokenID(piece))] == bannedTokenBias {
+			removed++
+			continue
+		}
+		b.WriteString(piece)
+	}
+	return b.String(), removed
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// --- countTokens ---
+
+func TestCountTokens_EmptyString_ReturnsZero(t *testing.T) {
+	// Given: empty text
+	// When
+	got := countTokens("")
+	// Then
+	if got != 0 {
+		t.Errorf("expected 0 tokens, got %d", got)
+	}
+}
+
+func TestCountTokens_ShortWords_OneTokenEach(t *testing.T) {
+	// Given: words no longer than four bytes
+	// When
+	got := countTokens("the cat sat")
+	// Then
+	if got != 3 {
+		t.Errorf("expected 3 tokens, got %d", got)
+	}
+}
+
+func TestCountTokens_LongWord_SplitsIntoMultipleTokens(t *testing.T) {
+	// Given: a nine-byte word
+	// When
+	got := countTokens("embedding")
+	// Then: ceil(9/4) = 3
+	if got != 3 {
+		t.Errorf("expected 3 tokens, got %d", got)
+	}
+}
+
+func TestCountTokens_Punctuation_CountsSeparately(t *testing.T) {
+	// Given: words followed by punctuation
+	// When
+	got := countTokens("hello, world!")
+	// Then: hello(2) + ","(1) + world(2) + "!"(1)
+	if got != 6 {
+		t.Errorf("expected 6 tokens, got %d", got)
+	}
+}
+
+func TestCountTokens_WhitespaceOnly_ReturnsZero(t *testing.T) {
+	// Given: only whitespace
+	// When
+	got := countTokens(" \t\n ")
+	// Then
+	if got != 0 {
+		t.Errorf("expected 0 tokens, got %d", got)
+	}
+}
+
+func TestCountTokens_MultiByteRunes_CountsBytes(t *testing.T) {
+	// Given: a word of three 3-byte runes (9 bytes)
+	// When
+	got := countTokens("日本語")
+	// Then: ceil(9/4) = 3
+	if got != 3 {
+		t.Errorf("expected 3 tokens, got %d", got)
+	}
+}
+
+func TestCountTokens_IsDeterministic(t *testing.T) {
+	// Given: the same text
+	text := "Deterministic token counts, please."
+	// When: counted twice
+	first := countTokens(text)
+	second := countTokens(text)
+	// Then
+	if first != second {
+		t.Errorf("expected deterministic count: %d vs %d", first, second)
+	}
+}
+
+// --- splitTokens ---
+
+func TestSplitTokens_ConcatenationReproducesText(t *testing.T) {
+	// Given: text mixing words, punctuation, and whitespace
+	text := `  {"name": "embedding", "n": 42}  `
+	// When
+	pieces := splitTokens(text)
+	// Then
+	if got := strings.Join(pieces, ""); got != text {
+		t.Errorf("expected concatenation %q, got %q", text, got)
+	}
+}
+
+func TestSplitTokens_MatchesCountTokens(t *testing.T) {
+	// Given: text with long words and punctuation
+	text := "hello, embedding world!"
+	// When
+	pieces := splitTokens(text)
+	// Then: one piece per counted token
+	if len(pieces) != countTokens(text) {
+		t.Errorf("expected %d pieces, got %d (%q)", countTokens(text), len(pieces), pieces)
+	}
+}
+
+func TestSplitTokens_WhitespaceLeadsNextPiece(t *testing.T) {
+	// Given
+	// When
+	pieces := splitTokens("the cat")
+	// Then
+	if len(pieces) != 2 || pieces[0] != "the" || pieces[1] != " cat" {
+		t.Errorf("expected [\"the\" \" cat\"], got %q", pieces)
+	}
+}
+
+// --- tokenID ---
+
+func TestTokenID_LeadingSpaceIsDistinctToken(t *testing.T) {
+	// Given: a piece with and without leading whitespace
+	// When
+	bare, spaced := tokenID("cat"), tokenID(" cat")
+	// Then
+	if bare == spaced {
+		t.Errorf("expected distinct IDs, both %d", bare)
+	}
+	if bare < 0 || bare >= tokenVocabularySize {
+		t.Errorf("expected ID in vocabulary, got %d", bare)
+	}
+}
+
+// --- validateLogitBias ---
+
+func TestValidateLogitBias_ValidMap_ReturnsNil(t *testing.T) {
+	// Given
+	bias := map[string]int{"50256": -100, "0": 100}
+	// When / Then
+	if err := validateLogitBias(bias); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestValidateLogitBias_NonIntegerKey_ReturnsError(t *testing.T) {
+	// Given
+	bias := map[string]int{"hello": 1}
+	// When / Then
+	if err := validateLogitBias(bias); err == nil {
+		t.Error("expected error for non-integer key")
+	}
+}
+
+func TestValidateLogitBias_OutOfRangeValue_ReturnsError(t *testing.T) {
+	// Given
+	bias := map[string]int{"1": 101}
+	// When / Then
+	if err := validateLogitBias(bias); err == nil {
+		t.Error("expected error for value above 100")
+	}
+}
+
+// --- stripBannedTokens ---
+
+func TestStripBannedTokens_RemovesBannedPieces(t *testing.T) {
+	// Given: " cat" is banned and " dog" only discouraged
+	bias := map[string]int{
+		strconv.Itoa(tokenID(" cat")): -100,
+		strconv.Itoa(tokenID(" dog")): -50,
+	}
+	// When
+	got, removed := stripBannedTokens("the cat and the dog", bias)
+	// Then
+	if got != "the and the dog" || removed != 1 {
+		t.Errorf("expected %q with 1 removal, got %q with %d", "the and the dog", got, removed)
+	}
+}
+
+func TestStripBannedTokens_EmptyBias_ReturnsTextUnchanged(t *testing.T) {
+	// Given
+	// When
+	got, removed := stripBannedTokens("hello world", nil)
+	// Then
+	if got != "hello world" || removed != 0 {
+		t.Errorf("expected unchanged text, got %q with %d", got, removed)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"openai-mokku/api"
+)
+
+// toolCallPrefix starts a line of the last user message that makes the model call a tool:
+// "call:<tool_name> {json args}". The arguments are optional and default to {}.
+const toolCallPrefix = "call:"
+
+// mockToolCall is a tool call made by the mock model.
+type mockToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// planToolCalls returns the tool calls the mock model makes for a chat request, or nil when it
+// answers with text. The model never calls tools when none are declared, with tool_choice "none",
+// or when the last message is a tool result. Otherwise the call: lines of the last user message
+// are called; without them, the tool named by tool_choice (or the first tool) is called with
+// {"input": <last user message>}. parallel_tool_calls=false keeps only the first call.
+func planToolCalls(req *api.CreateChatCompletionRequest, lastUserMessage string) ([]mockToolCall, error) {
+	if len(req.Tools) == 0 {
+		return nil, nil
+	}
+	choice, _ := req.ToolChoice.Get()
+	if mode, ok := choice.GetCreateChatCompletionRequestToolChoice0(); ok && mode == api.CreateChatCompletionRequestToolChoice0None {
+		return nil, nil
+	}
+	if n := len(req.Messages); n > 0 && req.Messages[n-1].Role == api.ChatCompletionRequestMessageRoleTool {
+		return nil, nil
+	}
+
+	declared := make([]string, 0, len(req.Tools))
+	for _, tool := range req.Tools {
+		declared = append(declared, tool.Function.Name)
+	}
+	forced := ""
+	if named, ok := choice.GetChatCompletionNamedToolChoice(); ok {
+		forced = named.Function.Name
+		if !slices.Contains(declared, forced) {
+			return nil, newInvalidRequestError("tool_choice", fmt.Sprintf("Invalid value for 'tool_choice': tool '%s' is not in tools.", forced))
+		}
+	}
+
+	calls, err := parseToolCallLines(lastUserMessage, declared)
+	if err != nil {
+		return nil, err
+	}
+	if forced != "" {
+		calls = slices.DeleteFunc(calls, func(c mockToolCall) bool { return c.Name != forced })
+	}
+	if len(calls) == 0 {
+		name := forced
+		if name == "" {
+			name = declared[0]
+		}
+		args, _ := json.Marshal(map[string]string{"input": lastUserMessage})
+		calls = []mockToolCall{{Name: name, Arguments: string(args)}}
+	}
+	if req.ParallelToolCalls.Set && !req.ParallelToolCalls.Value {
+		calls = calls[:1]
+	}
+	for i := range calls {
+		calls[i].ID = "call_" + uuid.New().String()
+	}
+	return calls, nil
+}
+
+// parseToolCallLines parses the call: lines of a message. Calls must name a declared tool and
+// carry a JSON object as arguments.
+func parseToolCallLines(message string, declared []string) ([]mockToolCall, error) {
+	var calls []mockToolCall
+	for _, line := range strings.Split(message, "\n") {
+		rest, ok := strings.CutPrefix(strings.TrimSpace(line), toolCallPrefix)
+		if !ok {
+			continue
+		}
+		name, args, _ := strings.Cut(strings.TrimSpace(rest), " ")
+		if !slices.Contains(declared, name) {
+			return nil, newInvalidRequestError("messages", fmt.Sprintf("'%s%s' names a tool that is not in tools.", toolCallPrefix, name))
+		}
+		args = strings.TrimSpace(args)
+		if args == "" {
+			args = "{}"
+		}
+		var obj map[string]any
+		if err := json.Unmarshal([]byte(args), &obj); err != nil {
+			return nil, newInvalidRequestError("messages", fmt.Sprintf("Arguments of '%s%s' must be a JSON object: %v", toolCallPrefix, name, err))
+		}
+		var compact bytes.Buffer
+		_ = json.Compact(&compact, []byte(args))
+		calls = append(calls, mockToolCall{Name: name, Arguments: compact.String()})
+	}
+	return calls, nil
+}
+
+// apiToolCalls converts tool calls to the response message representation.
+func apiToolCalls(calls []mockToolCall) []api.ChatCompletionMessageToolCall {
+	out := make([]api.ChatCompletionMessageToolCall, 0, len(calls))
+	for _, c := range calls {
+		out = append(out, api.ChatCompletionMessageToolCall{
+			ID:   c.ID,
+			Type: api.ChatCompletionMessageToolCallTypeFunction,
+			Function: api.ChatCompletionMessageToolCallFunction{
+				Name:      c.Name,
+				Arguments: c.Arguments,
+			},
+		})
+	}
+	return out
+}
+
+// toolCallDeltas splits tool calls into the fragments of a stream: for each call, one with its
+// index, id, type and name and empty arguments, then one per token of its arguments.
+func toolCallDeltas(calls []mockToolCall) []ChatCompletionChunkToolCall {
+	var deltas []ChatCompletionChunkToolCall
+	for i, c := range calls {
+		deltas = append(deltas, ChatCompletionChunkToolCall{
+			Index:    i,
+			ID:       c.ID,
+			Type:     string(api.ChatCompletionMessageToolCallTypeFunction),
+			Function: ChatCompletionChunkToolCallFunction{Name: c.Name},
+		})
+		for _, piece := range splitTokens(c.Arguments) {
+			deltas = append(deltas, ChatCompletionChunkToolCall{
+				Index:    i,
+				Function: ChatCompletionChunkToolCallFunction{Arguments: piece},
+			})
+		}
+	}
+	return deltas
+}
+
+// toolCallTokens counts the completion tokens of tool calls: their names and arguments.
+func toolCallTokens(calls []mockToolCall) int {
+	tokens := 0
+	for _, c := range calls {
+		tokens += countTokens(c.Name) + countTokens(c.Arguments)
+	}
+	return tokens
+}
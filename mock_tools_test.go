@@ -0,0 +1,200 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"openai-mokku/api"
+)
+
+// toolRequest builds a chat request declaring the named tools with the given user message.
+func toolRequest(message string, tools ...string) *api.CreateChatCompletionRequest {
+	req := &api.CreateChatCompletionRequest{
+		Model: "gpt-4o",
+		Messages: []api.ChatCompletionRequestMessage{{
+			Role:    api.ChatCompletionRequestMessageRoleUser,
+			Content: api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent(message)),
+		}},
+	}
+	for _, name := range tools {
+		req.Tools = append(req.Tools, api.ChatCompletionTool{
+			Type:     api.ChatCompletionToolTypeFunction,
+			Function: api.ChatCompletionToolFunction{Name: name},
+		})
+	}
+	return req
+}
+
+// --- planToolCalls ---
+
+func TestPlanToolCalls_NoTools_ReturnsNil(t *testing.T) {
+	// Given: a request without tools
+	req := toolRequest("call:get_weather {}")
+	// When
+	calls, err := planToolCalls(req, "call:get_weather {}")
+	// Then
+	if err != nil || calls != nil {
+		t.Errorf("expected no calls, got %v, %v", calls, err)
+	}
+}
+
+func TestPlanToolCalls_Default_CallsFirstToolWithInput(t *testing.T) {
+	// Given: tools and a plain message
+	req := toolRequest("hello", "first", "second")
+	// When
+	calls, err := planToolCalls(req, "hello")
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 1 || calls[0].Name != "first" || calls[0].Arguments != `{"input":"hello"}` {
+		t.Errorf("unexpected calls %+v", calls)
+	}
+	if !strings.HasPrefix(calls[0].ID, "call_") {
+		t.Errorf("expected call_ id, got %q", calls[0].ID)
+	}
+}
+
+func TestPlanToolCalls_CallLines_CallsEachTool(t *testing.T) {
+	// Given: two call: lines among other text
+	message := "please\ncall:get_weather {\"city\": \"Tokyo\"}\n  call:get_time\n"
+	req := toolRequest(message, "get_weather", "get_time")
+	// When
+	calls, err := planToolCalls(req, message)
+	// Then: both are called in order, arguments are compacted and default to {}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 calls, got %+v", calls)
+	}
+	if calls[0].Name != "get_weather" || calls[0].Arguments != `{"city":"Tokyo"}` {
+		t.Errorf("unexpected first call %+v", calls[0])
+	}
+	if calls[1].Name != "get_time" || calls[1].Arguments != `{}` {
+		t.Errorf("unexpected second call %+v", calls[1])
+	}
+	if calls[0].ID == calls[1].ID {
+		t.Error("expected distinct call ids")
+	}
+}
+
+func TestPlanToolCalls_ParallelDisabled_KeepsFirstCall(t *testing.T) {
+	// Given: two call: lines with parallel_tool_calls=false
+	message := "call:a\ncall:b"
+	req := toolRequest(message, "a", "b")
+	req.ParallelToolCalls = api.NewOptBool(false)
+	// When
+	calls, err := planToolCalls(req, message)
+	// Then
+	if err != nil || len(calls) != 1 || calls[0].Name != "a" {
+		t.Errorf("expected only a, got %+v, %v", calls, err)
+	}
+}
+
+func TestPlanToolCalls_ToolChoiceNone_ReturnsNil(t *testing.T) {
+	// Given: tool_choice=none
+	req := toolRequest("call:a", "a")
+	req.ToolChoice = api.NewOptCreateChatCompletionRequestToolChoice(
+		api.NewCreateChatCompletionRequestToolChoice0CreateChatCompletionRequestToolChoice(api.CreateChatCompletionRequestToolChoice0None))
+	// When
+	calls, err := planToolCalls(req, "call:a")
+	// Then
+	if err != nil || calls != nil {
+		t.Errorf("expected no calls, got %+v, %v", calls, err)
+	}
+}
+
+func TestPlanToolCalls_NamedToolChoice_CallsThatTool(t *testing.T) {
+	// Given: tool_choice names the second tool and the message calls both
+	message := "call:a {\"x\":1}\ncall:b {\"y\":2}"
+	req := toolRequest(message, "a", "b")
+	req.ToolChoice = api.NewOptCreateChatCompletionRequestToolChoice(api.NewChatCompletionNamedToolChoiceCreateChatCompletionRequestToolChoice(
+		api.ChatCompletionNamedToolChoice{
+			Type:     api.ChatCompletionNamedToolChoiceTypeFunction,
+			Function: api.ChatCompletionNamedToolChoiceFunction{Name: "b"},
+		}))
+	// When
+	calls, err := planToolCalls(req, message)
+	// Then
+	if err != nil || len(calls) != 1 || calls[0].Name != "b" || calls[0].Arguments != `{"y":2}` {
+		t.Errorf("expected only b, got %+v, %v", calls, err)
+	}
+}
+
+func TestPlanToolCalls_LastMessageIsToolResult_ReturnsNil(t *testing.T) {
+	// Given: the conversation ends with a tool result
+	req := toolRequest("call:a", "a")
+	req.Messages = append(req.Messages, api.ChatCompletionRequestMessage{
+		Role:       api.ChatCompletionRequestMessageRoleTool,
+		Content:    api.NewOptNilChatCompletionRequestMessageContent(api.NewStringChatCompletionRequestMessageContent("sunny")),
+		ToolCallID: api.NewOptString("call_1"),
+	})
+	// When
+	calls, err := planToolCalls(req, "call:a")
+	// Then: the model answers with text
+	if err != nil || calls != nil {
+		t.Errorf("expected no calls, got %+v, %v", calls, err)
+	}
+}
+
+func TestPlanToolCalls_Invalid_ReturnsInvalidRequestError(t *testing.T) {
+	cases := map[string]string{
+		"undeclared tool": "call:missing {}",
+		"invalid json":    "call:a {not json",
+		"non-object json": "call:a [1]",
+	}
+	for name, message := range cases {
+		t.Run(name, func(t *testing.T) {
+			// Given
+			req := toolRequest(message, "a")
+			// When
+			_, err := planToolCalls(req, message)
+			// Then
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 {
+				t.Errorf("expected 400 APIError, got %v", err)
+			}
+		})
+	}
+}
+
+// --- toolCallDeltas ---
+
+func TestToolCallDeltas_HeaderThenArgumentFragments(t *testing.T) {
+	// Given: two calls
+	calls := []mockToolCall{
+		{ID: "call_1", Name: "a", Arguments: `{"city":"Tokyo"}`},
+		{ID: "call_2", Name: "b", Arguments: `{}`},
+	}
+	// When
+	deltas := toolCallDeltas(calls)
+	// Then: each call starts with id/type/name, and its fragments join to its arguments
+	args := map[int]string{}
+	headers := 0
+	for i, d := range deltas {
+		if d.ID != "" {
+			headers++
+			if d.Type != "function" || d.Function.Name != calls[d.Index].Name || d.Function.Arguments != "" {
+				t.Errorf("delta %d: unexpected header %+v", i, d)
+			}
+			continue
+		}
+		if d.Function.Name != "" || d.Type != "" {
+			t.Errorf("delta %d: fragment repeats the header %+v", i, d)
+		}
+		args[d.Index] += d.Function.Arguments
+	}
+	if headers != 2 || deltas[0].ID != "call_1" {
+		t.Errorf("expected 2 headers starting with call_1, got %+v", deltas)
+	}
+	for i, c := range calls {
+		if args[i] != c.Arguments {
+			t.Errorf("call %d: expected arguments %q, got %q", i, c.Arguments, args[i])
+		}
+	}
+	if len(deltas) <= 4 {
+		t.Errorf("expected arguments split into several fragments, got %d deltas", len(deltas))
+	}
+}
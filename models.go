@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+)
+
+// modelMetadata describes a model the way provider model catalogs do.
+type modelMetadata struct {
+	ID               string   `json:"id" yaml:"id"`
+	ContextWindow    int      `json:"context_window" yaml:"context_window"`
+	MaxOutputTokens  int      `json:"max_output_tokens" yaml:"max_output_tokens"`
+	InputModalities  []string `json:"input_modalities" yaml:"input_modalities"`
+	OutputModalities []string `json:"output_modalities" yaml:"output_modalities"`
+	KnowledgeCutoff  string   `json:"knowledge_cutoff,omitempty" yaml:"knowledge_cutoff"`
+	// ContextOverflow is what the model does with requests that do not fit its context window:
+	// contextOverflowReject (the default), contextOverflowTruncate, or contextOverflowIgnore.
+	ContextOverflow string `json:"context_overflow,omitempty" yaml:"context_overflow"`
+}
+
+// Behaviors of a model for requests that do not fit its context window.
+const (
+	// contextOverflowReject fails the request with context_length_exceeded, as the real API does.
+	contextOverflowReject = "reject"
+	// contextOverflowTruncate keeps the end of the prompt that fits the window and cuts the
+	// completion to the tokens left, with finish_reason "length".
+	contextOverflowTruncate = "truncate"
+	// contextOverflowIgnore serves the request as if the window were unlimited.
+	contextOverflowIgnore = "ignore"
+)
+
+// builtinModels are the models served by default, in the order listed by GET /v1/models.
+var builtinModels = []modelMetadata{
+	{
+		ID:               "mokku-echo-1",
+		ContextWindow:    128000,
+		MaxOutputTokens:  16384,
+		InputModalities:  []string{"text", "image", "audio"},
+		OutputModalities: []string{"text"},
+	},
+	{
+		ID:               LoremModelName,
+		ContextWindow:    128000,
+		MaxOutputTokens:  16384,
+		InputModalities:  []string{"text"},
+		OutputModalities: []string{"text"},
+	},
+	{
+		ID:               "gpt-4o",
+		ContextWindow:    128000,
+		MaxOutputTokens:  16384,
+		InputModalities:  []string{"text", "image"},
+		OutputModalities: []string{"text"},
+		KnowledgeCutoff:  "2023-10-01",
+	},
+	{
+		ID:               "gpt-4o-mini",
+		ContextWindow:    128000,
+		MaxOutputTokens:  16384,
+		InputModalities:  []string{"text", "image"},
+		OutputModalities: []string{"text"},
+		KnowledgeCutoff:  "2023-10-01",
+	},
+}
+
+// modelCatalog holds the metadata of the served models: builtinModels merged with the models
+// section of the config file. It is safe for concurrent use and can be replaced on config reload.
+type modelCatalog struct {
+	mu     sync.RWMutex
+	models []modelMetadata
+}
+
+// newModelCatalog creates a catalog from builtinModels and the configured models.
+func newModelCatalog(configured []modelMetadata) (*modelCatalog, error) {
+	c := &modelCatalog{}
+	if err := c.Load(configured); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load replaces the catalog with builtinModels merged with the configured models. A configured
+// model with a built-in ID overrides the non-zero fields of the built-in entry; other configured
+// models are appended. Nothing changes if a configured model has no ID.
+func (c *modelCatalog) Load(configured []modelMetadata) error {
+	return installNow(c.prepare(configured))
+}
+
+// prepare validates the configured models and returns the function installing the catalog.
+func (c *modelCatalog) prepare(configured []modelMetadata) (func(), error) {
+	models := slices.Clone(builtinModels)
+	for _, m := range configured {
+		if m.ID == "" {
+			return nil, fmt.Errorf("configured model without id")
+		}
+		switch m.ContextOverflow {
+		case "", contextOverflowReject, contextOverflowTruncate, contextOverflowIgnore:
+		default:
+			return nil, fmt.Errorf("model %s: invalid context_overflow %q (want %s, %s, or %s)",
+				m.ID, m.ContextOverflow, contextOverflowReject, contextOverflowTruncate, contextOverflowIgnore)
+		}
+		i := slices.IndexFunc(models, func(b modelMetadata) bool { return b.ID == m.ID })
+		if i < 0 {
+			models = append(models, m)
+			continue
+		}
+		models[i] = mergeModelMetadata(models[i], m)
+	}
+	return func() {
+		c.mu.Lock()
+		c.models = models
+		c.mu.Unlock()
+	}, nil
+}
+
+// mergeModelMetadata returns base with the non-zero fields of override applied.
+func mergeModelMetadata(base, override modelMetadata) modelMetadata {
+	if override.ContextWindow > 0 {
+		base.ContextWindow = override.ContextWindow
+	}
+	if override.MaxOutputTokens > 0 {
+		base.MaxOutputTokens = override.MaxOutputTokens
+	}
+	if len(override.InputModalities) > 0 {
+		base.InputModalities = override.InputModalities
+	}
+	if len(override.OutputModalities) > 0 {
+		base.OutputModalities = override.OutputModalities
+	}
+	if override.KnowledgeCutoff != "" {
+		base.KnowledgeCutoff = override.KnowledgeCutoff
+	}
+	if override.ContextOverflow != "" {
+		base.ContextOverflow = override.ContextOverflow
+	}
+	return base
+}
+
+// List returns the metadata of every served model.
+func (c *modelCatalog) List() []modelMetadata {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.models)
+}
+
+// Get returns the metadata of the named model.
+func (c *modelCatalog) Get(id string) (modelMetadata, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, m := range c.models {
+		if m.ID == id {
+			return m, true
+		}
+	}
+	return modelMetadata{}, false
+}
+
+// contextFit is how a request fits the context window of its model.
+type contextFit struct {
+	// PromptTokens is the number of prompt tokens the model reads: the whole prompt, or the part
+	// that fits the window when it was truncated.
+	PromptTokens int
+	// Truncated is set when the model truncates the request; the completion is then cut to
+	// CompletionLimit tokens.
+	Truncated       bool
+	CompletionLimit int
+}
+
+// limit cuts text to the completion tokens left in the context window, reporting whether it did.
+func (f contextFit) limit(text string) (string, bool) {
+	if !f.Truncated {
+		return text, false
+	}
+	return truncateTokens(text, f.CompletionLimit)
+}
+
+// checkContextWindow checks that a request fits the model's limits. Max tokens above the model's
+// output limit are rejected with the error of the real API. Prompt plus max tokens above its context
+// window are handled as the model's ContextOverflow says: rejected with the real API's
+// context_length_exceeded error, truncated, or ignored. promptParam names the request field holding
+// the prompt. Models without metadata are not checked; maxTokens <= 0 means unset.
+func (c *modelCatalog) checkContextWindow(model, promptParam string, promptTokens, maxTokens int) (contextFit, error) {
+	fit := contextFit{PromptTokens: promptTokens}
+	m, ok := c.Get(model)
+	if !ok {
+		return fit, nil
+	}
+	if m.MaxOutputTokens > 0 && maxTokens > m.MaxOutputTokens {
+		return fit, newInvalidRequestError("max_tokens", fmt.Sprintf(
+			"max_tokens is too large: %d. This model supports at most %d completion tokens, whereas you provided %d.",
+			maxTokens, m.MaxOutputTokens, maxTokens))
+	}
+	requested := promptTokens + max(maxTokens, 0)
+	if m.ContextWindow <= 0 || requested <= m.ContextWindow {
+		return fit, nil
+	}
+	switch m.ContextOverflow {
+	case contextOverflowIgnore:
+		return fit, nil
+	case contextOverflowTruncate:
+		fit.PromptTokens = min(promptTokens, m.ContextWindow)
+		fit.Truncated = true
+		fit.CompletionLimit = m.ContextWindow - fit.PromptTokens
+		return fit, nil
+	}
+	return fit, &APIError{
+		StatusCode: http.StatusBadRequest,
+		Detail: OpenAIErrorDetail{
+			Message: fmt.Sprintf("This model's maximum context length is %d tokens. However, you requested %d tokens "+
+				"(%d in the %s, %d in the completion). Please reduce the length of the %s or completion.",
+				m.ContextWindow, requested, promptTokens, promptParam, max(maxTokens, 0), promptParam),
+			Type:  "invalid_request_error",
+			Param: &promptParam,
+			Code:  "context_length_exceeded",
+		},
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// --- newModelCatalog ---
+
+func TestNewModelCatalog_BuiltinOrder(t *testing.T) {
+	// When
+	c, err := newModelCatalog(nil)
+	// Then
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	models := c.List()
+	if len(models) != len(builtinModels) || models[0].ID != "mokku-echo-1" {
+		t.Errorf("expected the built-in models in order, got %v", models)
+	}
+}
+
+func TestNewModelCatalog_OverridesAndAdds(t *testing.T) {
+	// Given: an override of one field of gpt-4o and a new model
+	configured := []modelMetadata{
+		{ID: "gpt-4o", ContextWindow: 8192},
+		{ID: "ft:gpt-4o:acme", ContextWindow: 4096, MaxOutputTokens: 1024},
+	}
+	// When
+	c, err := newModelCatalog(configured)
+	// Then: the override keeps the other built-in fields, the new model is appended
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gpt4o, _ := c.Get("gpt-4o")
+	if gpt4o.ContextWindow != 8192 || gpt4o.MaxOutputTokens != 16384 || gpt4o.KnowledgeCutoff != "2023-10-01" {
+		t.Errorf("unexpected merged metadata: %+v", gpt4o)
+	}
+	models := c.List()
+	if models[len(models)-1].ID != "ft:gpt-4o:acme" {
+		t.Errorf("expected the new model last, got %v", models)
+	}
+}
+
+func TestNewModelCatalog_InvalidContextOverflowIsError(t *testing.T) {
+	// When
+	_, err := newModelCatalog([]modelMetadata{{ID: "gpt-4o", ContextOverflow: "wrap"}})
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "context_overflow") {
+		t.Errorf("expected a context_overflow error, got %v", err)
+	}
+}
+
+func TestNewModelCatalog_MissingIDIsError(t *testing.T) {
+	// When
+	_, err := newModelCatalog([]modelMetadata{{ContextWindow: 10}})
+	// Then
+	if err == nil {
+		t.Error("expected an error for a model without id")
+	}
+}
+
+// --- modelCatalog.checkContextWindow ---
+
+func TestCheckContextWindow_WithinLimits(t *testing.T) {
+	// Given
+	c, _ := newModelCatalog(nil)
+	// When
+	_, err := c.checkContextWindow("gpt-4o", "messages", 1000, 1000)
+	// Then
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckContextWindow_ExceedsContextWindow(t *testing.T) {
+	// Given
+	c, _ := newModelCatalog([]modelMetadata{{ID: "gpt-4o", ContextWindow: 100}})
+	// When
+	_, err := c.checkContextWindow("gpt-4o", "messages", 90, 20)
+	// Then
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Detail.Code != "context_length_exceeded" || *apiErr.Detail.Param != "messages" {
+		t.Errorf("expected context_length_exceeded on messages, got %v", err)
+	}
+}
+
+func TestCheckContextWindow_MaxTokensAboveOutputLimit(t *testing.T) {
+	// Given
+	c, _ := newModelCatalog(nil)
+	// When
+	_, err := c.checkContextWindow("gpt-4o", "messages", 10, 20000)
+	// Then
+	apiErr, ok := err.(*APIError)
+	if !ok || *apiErr.Detail.Param != "max_tokens" {
+		t.Errorf("expected a max_tokens error, got %v", err)
+	}
+}
+
+func TestCheckContextWindow_UnknownModelIsNotChecked(t *testing.T) {
+	// Given
+	c, _ := newModelCatalog(nil)
+	// When
+	_, err := c.checkContextWindow("gpt-9", "messages", 1<<30, 1<<30)
+	// Then
+	if err != nil {
+		t.Errorf("expected no check for unknown models, got %v", err)
+	}
+}
+
+func TestCheckContextWindow_TruncateCutsCompletionToRoomLeft(t *testing.T) {
+	// Given: a 100-token window that truncates
+	c, _ := newModelCatalog([]modelMetadata{{ID: "gpt-4o", ContextWindow: 100, ContextOverflow: contextOverflowTruncate}})
+	// When: the completion does not fit, then the prompt alone does not fit
+	fit, err := c.checkContextWindow("gpt-4o", "messages", 90, 20)
+	overflow, overflowErr := c.checkContextWindow("gpt-4o", "messages", 150, 0)
+	// Then: the completion gets the 10 tokens left, and nothing is left past a truncated prompt
+	if err != nil || !fit.Truncated || fit.PromptTokens != 90 || fit.CompletionLimit != 10 {
+		t.Errorf("expected 10 completion tokens left, got %+v, %v", fit, err)
+	}
+	if overflowErr != nil || overflow.PromptTokens != 100 || overflow.CompletionLimit != 0 {
+		t.Errorf("expected the prompt truncated to the window, got %+v, %v", overflow, overflowErr)
+	}
+	if text, cut := fit.limit("a b c d e f g h i j k l"); !cut || text != "a b c d e f g h i j" {
+		t.Errorf("expected the text cut to 10 tokens, got %q", text)
+	}
+}
+
+func TestCheckContextWindow_IgnoreServesAnyPrompt(t *testing.T) {
+	// Given
+	c, _ := newModelCatalog([]modelMetadata{{ID: "gpt-4o", ContextWindow: 100, ContextOverflow: contextOverflowIgnore}})
+	// When
+	fit, err := c.checkContextWindow("gpt-4o", "messages", 1000, 20)
+	// Then
+	if err != nil || fit.Truncated || fit.PromptTokens != 1000 {
+		t.Errorf("expected the request served as is, got %+v, %v", fit, err)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// moderationConfig is the moderation section of the config file.
+type moderationConfig struct {
+	// BannedPhrases are rejected wherever they appear in a request body, ignoring case.
+	BannedPhrases []string `yaml:"banned_phrases" json:"banned_phrases"`
+	// BannedUsers are end-user IDs whose requests are rejected, compared exactly with the
+	// safety_identifier and user fields of a request body (see userDenylist).
+	BannedUsers []string `yaml:"banned_users" json:"banned_users"`
+}
+
+// moderationFilter rejects API requests containing banned phrases with the real API's policy
+// violation errors, and requests made on behalf of banned end-users. It is inactive without
+// phrases and users and can be reconfigured on config reload.
+type moderationFilter struct {
+	phrases atomic.Pointer[[]string]
+	users   *userDenylist
+}
+
+// newModerationFilter creates a filter for the given config.
+func newModerationFilter(cfg moderationConfig) *moderationFilter {
+	f := &moderationFilter{users: newUserDenylist(nil)}
+	f.Load(cfg)
+	return f
+}
+
+// Load replaces the banned phrases and users, including users banned at runtime.
+func (f *moderationFilter) Load(cfg moderationConfig) {
+	phrases := make([]string, 0, len(cfg.BannedPhrases))
+	for _, p := range cfg.BannedPhrases {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" {
+			phrases = append(phrases, p)
+		}
+	}
+	f.phrases.Store(&phrases)
+	f.users.Load(cfg.BannedUsers)
+}
+
+// Active reports whether any phrase or user is banned.
+func (f *moderationFilter) Active() bool {
+	return len(*f.phrases.Load()) > 0 || f.users.Active()
+}
+
+// CheckUser returns the field (safety_identifier, then user) of a decoded JSON request body
+// naming a banned end-user.
+func (f *moderationFilter) CheckUser(doc any) (string, bool) {
+	return f.users.Check(doc)
+}
+
+// endUser identifies the end-user a request is made on behalf of. safety_identifier replaces
+// the older user field; clients may send either or both.
+type endUser struct {
+	SafetyIdentifier string `json:"safety_identifier,omitempty"`
+	User             string `json:"user,omitempty"`
+}
+
+// requestEndUser returns the end-user fields of a decoded JSON request body.
+func requestEndUser(doc any) endUser {
+	var id endUser
+	if m, ok := doc.(map[string]any); ok {
+		id.SafetyIdentifier, _ = m["safety_identifier"].(string)
+		id.User, _ = m["user"].(string)
+	}
+	return id
+}
+
+// Check returns the first banned phrase found in any string value of a JSON request body.
+// Keys are not checked, and bodies that are not JSON are checked as plain text.
+func (f *moderationFilter) Check(body []byte) (string, bool) {
+	phrases := *f.phrases.Load()
+	if len(phrases) == 0 {
+		return "", false
+	}
+	var doc any
+	var texts []string
+	if err := json.Unmarshal(body, &doc); err == nil {
+		texts = collectStrings(doc, texts)
+	} else {
+		texts = []string{string(body)}
+	}
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, phrase := range phrases {
+			if strings.Contains(text, phrase) {
+				return phrase, true
+			}
+		}
+	}
+	return "", false
+}
+
+// collectStrings appends every string value in a decoded JSON document to texts.
+func collectStrings(v any, texts []string) []string {
+	switch v := v.(type) {
+	case string:
+		texts = append(texts, v)
+	case []any:
+		for _, e := range v {
+			texts = collectStrings(e, texts)
+		}
+	case map[string]any:
+		for _, e := range v {
+			texts = collectStrings(e, texts)
+		}
+	}
+	return texts
+}
+
+// newPolicyViolationError returns the error the real API sends for a flagged request on path:
+// content_policy_violation for image generation and invalid_prompt for everything else.
+func newPolicyViolationError(path string) *APIError {
+	if strings.HasPrefix(path, "/v1/images/") {
+		return &APIError{
+			StatusCode: http.StatusBadRequest,
+			Detail: OpenAIErrorDetail{
+				Message: "Your request was rejected as a result of our safety system. " +
+					"Your prompt may contain text that is not allowed by our safety system.",
+				Type: "invalid_request_error",
+				Code: "content_policy_violation",
+			},
+		}
+	}
+	return &APIError{
+		StatusCode: http.StatusBadRequest,
+		Detail: OpenAIErrorDetail{
+			Message: "Invalid prompt: your prompt was flagged as potentially violating our usage policy. " +
+				"Please try again with a different prompt.",
+			Type: "invalid_request_error",
+			Code: "invalid_prompt",
+		},
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import "testing"
+
+// --- moderationFilter.Check ---
+
+func TestModerationFilter_Inactive(t *testing.T) {
+	// Given
+	f := newModerationFilter(moderationConfig{BannedPhrases: []string{" ", ""}})
+	// When
+	_, flagged := f.Check([]byte(`{"prompt":"anything"}`))
+	// Then
+	if f.Active() || flagged {
+		t.Error("expected blank phrases to leave the filter inactive")
+	}
+}
+
+func TestModerationFilter_MatchesNestedStringsIgnoringCase(t *testing.T) {
+	// Given
+	f := newModerationFilter(moderationConfig{BannedPhrases: []string{"Forbidden Word"}})
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":[{"type":"text","text":"say the FORBIDDEN word"}]}]}`
+	// When
+	phrase, flagged := f.Check([]byte(body))
+	// Then
+	if !flagged || phrase != "forbidden word" {
+		t.Errorf("expected a match, got %q, %v", phrase, flagged)
+	}
+}
+
+func TestModerationFilter_MatchesEscapedText(t *testing.T) {
+	// Given: the phrase is only visible after JSON unescaping
+	f := newModerationFilter(moderationConfig{BannedPhrases: []string{"bad"}})
+	// When
+	_, flagged := f.Check([]byte(`{"prompt":"bad"}`))
+	// Then
+	if !flagged {
+		t.Error("expected unescaped text to be checked")
+	}
+}
+
+func TestModerationFilter_IgnoresKeys(t *testing.T) {
+	// Given
+	f := newModerationFilter(moderationConfig{BannedPhrases: []string{"prompt"}})
+	// When
+	_, flagged := f.Check([]byte(`{"prompt":"hello"}`))
+	// Then
+	if flagged {
+		t.Error("expected keys not to be checked")
+	}
+}
+
+// --- moderationFilter.CheckUser ---
+
+func TestModerationFilter_CheckUser_SafetyIdentifierThenUser(t *testing.T) {
+	// Given
+	f := newModerationFilter(moderationConfig{BannedUsers: []string{"u-banned", ""}})
+	cases := map[string]struct {
+		doc   map[string]any
+		field string
+	}{
+		"safety identifier": {map[string]any{"safety_identifier": "u-banned", "user": "u-ok"}, "safety_identifier"},
+		"user":              {map[string]any{"safety_identifier": "u-ok", "user": "u-banned"}, "user"},
+		"allowed":           {map[string]any{"safety_identifier": "U-BANNED"}, ""},
+		"none":              {map[string]any{"model": "gpt-4o"}, ""},
+	}
+	for name, tc := range cases {
+		// When
+		field, blocked := f.CheckUser(tc.doc)
+		// Then
+		if field != tc.field || blocked != (tc.field != "") {
+			t.Errorf("%s: expected %q, got %q, %v", name, tc.field, field, blocked)
+		}
+	}
+	if !f.Active() {
+		t.Error("expected banned users to activate the filter")
+	}
+}
+
+// --- newPolicyViolationError ---
+
+func TestNewPolicyViolationError_ByPath(t *testing.T) {
+	// When
+	chat := newPolicyViolationError("/v1/chat/completions")
+	images := newPolicyViolationError("/v1/images/generations")
+	// Then
+	if chat.Detail.Code != "invalid_prompt" || images.Detail.Code != "content_policy_violation" {
+		t.Errorf("unexpected codes: %q, %q", chat.Detail.Code, images.Detail.Code)
+	}
+}
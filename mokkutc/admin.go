@@ -0,0 +1,682 @@
+package mokkutc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AdminClient is a typed client for the mokku control API (/_mokku).
+type AdminClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewAdminClient creates a client for the mokku instance at baseURL. token is sent as a bearer token
+// when the control API requires authentication; pass "" otherwise.
+func NewAdminClient(baseURL, token string) *AdminClient {
+	return &AdminClient{baseURL: strings.TrimSuffix(baseURL, "/"), token: token, client: http.DefaultClient}
+}
+
+// Capabilities is the response of GET /_mokku/capabilities.
+type Capabilities struct {
+	Version      string          `json:"version"`
+	SpecVersion  string          `json:"spec_version"`
+	Instance     string          `json:"instance"`
+	MagicModels  []string        `json:"magic_models"`
+	FeatureFlags map[string]bool `json:"feature_flags"`
+	// Seed is the global seed of the instance (MOKKU_SEED).
+	Seed uint64 `json:"seed"`
+}
+
+// FlagStatus is a feature flag reported by GET /_mokku/flags.
+type FlagStatus struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+	Source      string `json:"source"`
+	Evaluations int64  `json:"evaluations"`
+	Hits        int64  `json:"hits"`
+}
+
+// StreamStats is the response of GET /_mokku/streams.
+type StreamStats struct {
+	Started   int `json:"started"`
+	Completed int `json:"completed"`
+	Cancelled int `json:"cancelled"`
+}
+
+// ConnectionStats is the response of GET /_mokku/connections.
+type ConnectionStats struct {
+	Opened   int64 `json:"opened"`
+	Closed   int64 `json:"closed"`
+	Requests int64 `json:"requests"`
+	// ReusedRequests counts the requests that arrived on a connection that had already served one.
+	ReusedRequests        int64            `json:"reused_requests"`
+	RequestsPerConnection float64          `json:"requests_per_connection"`
+	Protocols             map[string]int64 `json:"protocols"`
+	Open                  []Connection     `json:"open"`
+	Recent                []Connection     `json:"recent"`
+}
+
+// Connection is a client connection reported by GET /_mokku/connections.
+type Connection struct {
+	ID         int64      `json:"id"`
+	RemoteAddr string     `json:"remote_addr"`
+	Proto      string     `json:"proto"`
+	Opened     time.Time  `json:"opened"`
+	Closed     *time.Time `json:"closed"`
+	Requests   int64      `json:"requests"`
+}
+
+// CaptureStats is the response of GET /_mokku/capture.
+type CaptureStats struct {
+	Enabled  bool   `json:"enabled"`
+	Entries  int    `json:"entries"`
+	Bytes    int64  `json:"bytes"`
+	MaxSize  int    `json:"max_entries"`
+	MaxBytes int64  `json:"max_bytes"`
+	Eviction string `json:"eviction"`
+	TTL      string `json:"ttl"`
+	Captured int64  `json:"captured"`
+	// Evicted counts the evicted requests by reason: "size", "bytes", or "ttl".
+	Evicted      map[string]int64 `json:"evicted"`
+	EvictedBytes int64            `json:"evicted_bytes"`
+}
+
+// EndpointOverhead is mokku's own processing time of one endpoint, reported by GET /_mokku/overhead.
+type EndpointOverhead struct {
+	// Endpoint is the method and path pattern, such as "POST /v1/chat/completions".
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+	// Overhead excludes the injected latency (regions, chaos, cold starts, scenarios), which is
+	// reported as Injected.
+	Overhead      LatencySummary `json:"overhead_ms"`
+	Injected      LatencySummary `json:"injected_ms"`
+	SLO           float64        `json:"slo_ms"`
+	SLOViolations int64          `json:"slo_violations"`
+}
+
+// TagStats is the roll-up of the requests classified by the tag rules, reported by GET /_mokku/tags.
+type TagStats struct {
+	Tags []TagSummary `json:"tags"`
+	// Untagged counts the requests no rule matched.
+	Untagged int64 `json:"untagged"`
+}
+
+// TagSummary is the roll-up of one tag.
+type TagSummary struct {
+	Tag      string `json:"tag"`
+	Requests int64  `json:"requests"`
+	// Errors counts the responses with a 4xx or 5xx status and Streams the streamed responses.
+	Errors  int64 `json:"errors"`
+	Streams int64 `json:"streams"`
+	// Duration is the time until the response was complete, over the tag's recent requests.
+	Duration LatencySummary `json:"duration_ms"`
+}
+
+// Alert is a regression in client behavior reported by GET /_mokku/alerts.
+type Alert struct {
+	Time time.Time `json:"time"`
+	// Kind is prompt_tokens, retries, or sdk_downgrade.
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	// Baseline and Observed are the average prompt tokens or retried share of the compared windows.
+	Baseline float64 `json:"baseline"`
+	Observed float64 `json:"observed"`
+	// SDK, Version, and Previous name a downgraded SDK, its version, and the higher version seen before.
+	SDK      string `json:"sdk"`
+	Version  string `json:"version"`
+	Previous string `json:"previous"`
+}
+
+// BannedUser is an end-user whose requests mokku rejects with a 403, listed by GET /_mokku/banned-users.
+type BannedUser struct {
+	// ID is compared with the safety_identifier and user fields of request bodies.
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+	// Source is config or admin.
+	Source   string    `json:"source"`
+	BannedAt time.Time `json:"banned_at"`
+	// Blocked counts the requests rejected since the ban.
+	Blocked int64 `json:"blocked"`
+}
+
+// LatencySummary is a latency distribution over recent requests, in milliseconds.
+type LatencySummary struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// Tokenization is the response of POST /_mokku/tokenize.
+type Tokenization struct {
+	Model string `json:"model"`
+	Count int    `json:"count"`
+	Data  []struct {
+		ID   int    `json:"id"`
+		Text string `json:"text"`
+	} `json:"data"`
+}
+
+// AuditEntry is an admin mutation reported by GET /_mokku/audit.
+type AuditEntry struct {
+	ID     int64  `json:"id"`
+	Actor  string `json:"actor"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Status int    `json:"status"`
+}
+
+// CapturedRequest is an API request recorded by mokku, with the response it sent.
+type CapturedRequest struct {
+	ID      int64             `json:"id"`
+	Time    time.Time         `json:"time"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+	Model   string            `json:"model"`
+	Stream  bool              `json:"stream"`
+	// SafetyIdentifier and User are the end-user IDs of the request body.
+	SafetyIdentifier string `json:"safety_identifier"`
+	User             string `json:"user"`
+	// Seed reproduces the request's randomized behavior when sent as the X-Mokku-Seed header.
+	Seed uint64 `json:"seed"`
+	// Tags are the tags the request was classified with by the tag rules of the config file.
+	Tags []string `json:"tags"`
+	// RemoteAddr is the client address; behind trusted proxies, the forwarded client.
+	RemoteAddr string `json:"remote_addr"`
+	// Connection is the client connection the request arrived on; Request is its 1-based number on it.
+	Connection struct {
+		ID      int64  `json:"id"`
+		Request int64  `json:"request"`
+		Proto   string `json:"proto"`
+		Reused  bool   `json:"reused"`
+	} `json:"connection"`
+	Response struct {
+		Status int `json:"status"`
+		// Body is the JSON response, or a JSON string of a preview of the server-sent events of a
+		// stream (see RequestBody for the full stream). It is only set by Request.
+		Body json.RawMessage `json:"body"`
+		// BodyFile is the file the full stream was spilled to, when mokku runs with
+		// MOKKU_CAPTURE_SPILL_DIR.
+		BodyFile string `json:"body_file"`
+	} `json:"response"`
+	// Chunks is the timing of a streamed response, nil for other responses.
+	Chunks     *StreamChunks `json:"chunks"`
+	DurationMS int64         `json:"duration_ms"`
+	Truncated  bool          `json:"truncated"`
+}
+
+// StreamChunks is the timing of the chunks (server-sent events) of a captured stream.
+type StreamChunks struct {
+	Count int   `json:"count"`
+	Bytes int64 `json:"bytes"`
+	// Timings has the first chunks' offsets, in milliseconds since the request arrived; TimingsTruncated reports
+	// that later chunks were only counted.
+	Timings []struct {
+		OffsetMS float64 `json:"offset_ms"`
+		Bytes    int     `json:"bytes"`
+	} `json:"timings"`
+	TimingsTruncated bool `json:"timings_truncated"`
+}
+
+// RequestFilter selects captured requests. Empty fields match everything.
+type RequestFilter struct {
+	Method string
+	Path   string
+	Model  string
+	Tag    string
+	// SafetyIdentifier and User select requests made for an end-user.
+	SafetyIdentifier string
+	User             string
+	Since            time.Time
+	Limit            int
+}
+
+// Region is the simulated health of a region selected by the X-Mokku-Region header. The zero
+// Region is healthy.
+type Region struct {
+	// Status is the error status of failed requests; 503 when only ErrorRate is set.
+	Status int `json:"status,omitempty"`
+	// ErrorRate is the fraction (0-1) of requests that fail; 1 when only Status is set.
+	ErrorRate float64 `json:"error_rate,omitempty"`
+	// Latency delays every request to the region, e.g. "2s".
+	Latency string `json:"latency,omitempty"`
+}
+
+// RegionStatus is a region reported by GET /_mokku/regions.
+type RegionStatus struct {
+	Name string `json:"name"`
+	Region
+	Healthy  bool   `json:"healthy"`
+	Source   string `json:"source"`
+	Requests int64  `json:"requests"`
+	Failures int64  `json:"failures"`
+}
+
+// ChaosStatus is the response of GET /_mokku/chaos.
+type ChaosStatus struct {
+	// Active is the active chaos profile, "" when chaos is off.
+	Active    string  `json:"active"`
+	Source    string  `json:"source"`
+	Intensity float64 `json:"intensity"`
+	Stats     struct {
+		Requests    int64 `json:"requests"`
+		Errors      int64 `json:"errors"`
+		RateLimited int64 `json:"rate_limited"`
+		Truncated   int64 `json:"truncated"`
+	} `json:"stats"`
+	Profiles []struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Builtin     bool   `json:"builtin"`
+	} `json:"profiles"`
+}
+
+// ClockStatus is the virtual clock stored objects expire by, as returned by GET /_mokku/clock.
+type ClockStatus struct {
+	Now           time.Time `json:"now"`
+	OffsetSeconds float64   `json:"offset_seconds"`
+}
+
+// Candidate is an API request evaluated by POST /_mokku/evaluate without being sent.
+type Candidate struct {
+	// Method defaults to POST.
+	Method  string            `json:"method,omitempty"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	// Body is the JSON request body, e.g. a map or an openai-go params struct.
+	Body any `json:"body,omitempty"`
+	// Seed is the request seed of randomized template functions; 0 when nil.
+	Seed *uint64 `json:"seed,omitempty"`
+}
+
+// Evaluation is the response of POST /_mokku/evaluate.
+type Evaluation struct {
+	// Matched is the scenario the request would match, "" when it keeps the generated response.
+	Matched  string `json:"matched"`
+	Seed     uint64 `json:"seed"`
+	Response *struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Code    string `json:"code"`
+		} `json:"error"`
+		Content      *string             `json:"content"`
+		FinishReason string              `json:"finish_reason"`
+		Headers      map[string][]string `json:"headers"`
+		LatencyMs    int64               `json:"latency_ms"`
+		Map          []string            `json:"map"`
+	} `json:"response"`
+	// Trace lists every scenario in order, with the reasons it does not match.
+	Trace []struct {
+		Name    string   `json:"name"`
+		Matched bool     `json:"matched"`
+		Reasons []string `json:"reasons"`
+	} `json:"trace"`
+}
+
+// Verification is the response of GET /_mokku/verify: the requests no scenario matched in
+// strict_scenarios mode since the last reset.
+type Verification struct {
+	// Passed reports whether no unexpected request arrived.
+	Passed             bool  `json:"passed"`
+	UnexpectedRequests int64 `json:"unexpected_requests"`
+	// Recent lists the latest unexpected requests, oldest first.
+	Recent []struct {
+		Time    time.Time `json:"time"`
+		Method  string    `json:"method"`
+		Path    string    `json:"path"`
+		Closest string    `json:"closest"`
+		Diff    []struct {
+			Field    string `json:"field"`
+			Expected string `json:"expected"`
+			Pattern  bool   `json:"pattern"`
+			Actual   string `json:"actual"`
+		} `json:"diff"`
+	} `json:"recent"`
+}
+
+// StatusError is returned for a control API response with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+// Error implements error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("mokku admin API returned %d: %s", e.StatusCode, e.Body)
+}
+
+// Capabilities returns the endpoints, flags, and version of the instance.
+func (c *AdminClient) Capabilities(ctx context.Context) (Capabilities, error) {
+	var caps Capabilities
+	err := c.do(ctx, http.MethodGet, "/capabilities", nil, &caps)
+	return caps, err
+}
+
+// Flags returns every feature flag.
+func (c *AdminClient) Flags(ctx context.Context) ([]FlagStatus, error) {
+	var resp struct {
+		Data []FlagStatus `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/flags", nil, &resp)
+	return resp.Data, err
+}
+
+// SetFlag enables or disables a feature flag until the instance restarts or reloads its config.
+func (c *AdminClient) SetFlag(ctx context.Context, name string, enabled bool) (FlagStatus, error) {
+	var status FlagStatus
+	err := c.do(ctx, http.MethodPut, "/flags/"+url.PathEscape(name), map[string]bool{"enabled": enabled}, &status)
+	return status, err
+}
+
+// Streams returns the streaming response counters.
+func (c *AdminClient) Streams(ctx context.Context) (StreamStats, error) {
+	var stats StreamStats
+	err := c.do(ctx, http.MethodGet, "/streams", nil, &stats)
+	return stats, err
+}
+
+// ResetStreams clears the streaming response counters.
+func (c *AdminClient) ResetStreams(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/streams", nil, nil)
+}
+
+// Connections returns the client connection counters.
+func (c *AdminClient) Connections(ctx context.Context) (ConnectionStats, error) {
+	var stats ConnectionStats
+	err := c.do(ctx, http.MethodGet, "/connections", nil, &stats)
+	return stats, err
+}
+
+// ResetConnections clears the client connection counters.
+func (c *AdminClient) ResetConnections(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/connections", nil, nil)
+}
+
+// Overhead returns mokku's own processing time per endpoint.
+func (c *AdminClient) Overhead(ctx context.Context) ([]EndpointOverhead, error) {
+	var resp struct {
+		Endpoints []EndpointOverhead `json:"endpoints"`
+	}
+	err := c.do(ctx, http.MethodGet, "/overhead", nil, &resp)
+	return resp.Endpoints, err
+}
+
+// ResetOverhead clears the recorded processing times.
+func (c *AdminClient) ResetOverhead(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/overhead", nil, nil)
+}
+
+// Tags returns the roll-up of the requests classified by the tag rules.
+func (c *AdminClient) Tags(ctx context.Context) (TagStats, error) {
+	var stats TagStats
+	err := c.do(ctx, http.MethodGet, "/tags", nil, &stats)
+	return stats, err
+}
+
+// ResetTags clears the tag roll-ups.
+func (c *AdminClient) ResetTags(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/tags", nil, nil)
+}
+
+// Alerts returns the recent regressions in client behavior, newest first.
+func (c *AdminClient) Alerts(ctx context.Context) ([]Alert, error) {
+	var resp struct {
+		Data []Alert `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/alerts", nil, &resp)
+	return resp.Data, err
+}
+
+// ResetAlerts clears the alerts and the client behavior observed so far.
+func (c *AdminClient) ResetAlerts(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/alerts", nil, nil)
+}
+
+// ResetEmbeddings clears the stored embeddings.
+func (c *AdminClient) ResetEmbeddings(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/embeddings", nil, nil)
+}
+
+// Tokenize splits text into mock tokens; Count equals the prompt_tokens of usage.
+func (c *AdminClient) Tokenize(ctx context.Context, model, text string) (Tokenization, error) {
+	var tok Tokenization
+	err := c.do(ctx, http.MethodPost, "/tokenize", map[string]string{"model": model, "text": text}, &tok)
+	return tok, err
+}
+
+// Audit returns the recorded admin mutations, newest first.
+func (c *AdminClient) Audit(ctx context.Context) ([]AuditEntry, error) {
+	var resp struct {
+		Data []AuditEntry `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/audit", nil, &resp)
+	return resp.Data, err
+}
+
+// Requests returns the captured API requests matching filter, newest first, without response bodies.
+func (c *AdminClient) Requests(ctx context.Context, filter RequestFilter) ([]CapturedRequest, error) {
+	q := url.Values{}
+	for key, value := range map[string]string{"method": filter.Method, "path": filter.Path, "model": filter.Model, "tag": filter.Tag,
+		"safety_identifier": filter.SafetyIdentifier, "user": filter.User} {
+		if value != "" {
+			q.Set(key, value)
+		}
+	}
+	if !filter.Since.IsZero() {
+		q.Set("since", filter.Since.Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		q.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	path := "/requests"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var resp struct {
+		Data []CapturedRequest `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, path, nil, &resp)
+	return resp.Data, err
+}
+
+// Request returns a captured API request with its response body.
+func (c *AdminClient) Request(ctx context.Context, id int64) (CapturedRequest, error) {
+	var req CapturedRequest
+	err := c.do(ctx, http.MethodGet, "/requests/"+strconv.FormatInt(id, 10), nil, &req)
+	return req, err
+}
+
+// RequestBody returns the full server-sent events of a captured stream, available when mokku runs
+// with MOKKU_CAPTURE_SPILL_DIR.
+func (c *AdminClient) RequestBody(ctx context.Context, id int64) ([]byte, error) {
+	var body []byte
+	err := c.do(ctx, http.MethodGet, "/requests/"+strconv.FormatInt(id, 10)+"/body", nil, &body)
+	return body, err
+}
+
+// CaptureStats returns the occupancy of the request capture and its eviction counters.
+func (c *AdminClient) CaptureStats(ctx context.Context) (CaptureStats, error) {
+	var stats CaptureStats
+	err := c.do(ctx, http.MethodGet, "/capture", nil, &stats)
+	return stats, err
+}
+
+// ResetRequests forgets the captured API requests, e.g. between tests.
+func (c *AdminClient) ResetRequests(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/requests", nil, nil)
+}
+
+// Regions returns every region with its request and failure counts.
+func (c *AdminClient) Regions(ctx context.Context) ([]RegionStatus, error) {
+	var resp struct {
+		Data []RegionStatus `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/regions", nil, &resp)
+	return resp.Data, err
+}
+
+// SetRegion changes or adds a region until the instance restarts or reloads its config, e.g. to take
+// it down during a failover test.
+func (c *AdminClient) SetRegion(ctx context.Context, name string, region Region) (RegionStatus, error) {
+	var status RegionStatus
+	err := c.do(ctx, http.MethodPut, "/regions/"+url.PathEscape(name), region, &status)
+	return status, err
+}
+
+// BannedUsers returns the banned end-users sorted by ID.
+func (c *AdminClient) BannedUsers(ctx context.Context) ([]BannedUser, error) {
+	var resp struct {
+		Data []BannedUser `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/banned-users", nil, &resp)
+	return resp.Data, err
+}
+
+// BanUser bans an end-user until it is unbanned or the instance restarts or reloads its config.
+func (c *AdminClient) BanUser(ctx context.Context, id, reason string) (BannedUser, error) {
+	var user BannedUser
+	err := c.do(ctx, http.MethodPut, "/banned-users/"+url.PathEscape(id), map[string]string{"reason": reason}, &user)
+	return user, err
+}
+
+// UnbanUser lifts the ban of an end-user, as an appeal would. It returns a *StatusError with
+// status 404 when the user is not banned.
+func (c *AdminClient) UnbanUser(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/banned-users/"+url.PathEscape(id), nil, nil)
+}
+
+// Chaos returns the active chaos profile, the faults it injected, and the available profiles.
+func (c *AdminClient) Chaos(ctx context.Context) (ChaosStatus, error) {
+	var status ChaosStatus
+	err := c.do(ctx, http.MethodGet, "/chaos", nil, &status)
+	return status, err
+}
+
+// SetChaos activates a chaos profile such as "flaky-network" until the instance restarts or
+// reloads its config.
+func (c *AdminClient) SetChaos(ctx context.Context, profile string) (ChaosStatus, error) {
+	var status ChaosStatus
+	err := c.do(ctx, http.MethodPut, "/chaos", map[string]string{"profile": profile}, &status)
+	return status, err
+}
+
+// StopChaos turns chaos off.
+func (c *AdminClient) StopChaos(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/chaos", nil, nil)
+}
+
+// Clock returns the virtual clock.
+func (c *AdminClient) Clock(ctx context.Context) (ClockStatus, error) {
+	var status ClockStatus
+	err := c.do(ctx, http.MethodGet, "/clock", nil, &status)
+	return status, err
+}
+
+// AdvanceClock moves the virtual clock forward by d, expiring the stored objects it passes, such as
+// image URLs after an hour.
+func (c *AdminClient) AdvanceClock(ctx context.Context, d time.Duration) (ClockStatus, error) {
+	var status ClockStatus
+	err := c.do(ctx, http.MethodPost, "/clock/advance", map[string]float64{"seconds": d.Seconds()}, &status)
+	return status, err
+}
+
+// ResetClock puts the virtual clock back on the wall clock.
+func (c *AdminClient) ResetClock(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/clock", nil, nil)
+}
+
+// Scenarios returns the names of the scenario rules in match order.
+func (c *AdminClient) Scenarios(ctx context.Context) ([]string, error) {
+	var list struct {
+		Data []string `json:"data"`
+	}
+	err := c.do(ctx, http.MethodGet, "/scenarios", nil, &list)
+	return list.Data, err
+}
+
+// ReplaceScenarios replaces every scenario rule with scenarios, the rules of a scenario file such as
+// a slice of maps, and returns the new rule names. The rules are validated before any is applied; on
+// error the current rules are kept.
+func (c *AdminClient) ReplaceScenarios(ctx context.Context, scenarios any) ([]string, error) {
+	var list struct {
+		Data []string `json:"data"`
+	}
+	err := c.do(ctx, http.MethodPut, "/scenarios", map[string]any{"scenarios": scenarios}, &list)
+	return list.Data, err
+}
+
+// Evaluate reports which scenario a request would match, the response it would render, and why the
+// other scenarios do not match, without sending the request.
+func (c *AdminClient) Evaluate(ctx context.Context, candidate Candidate) (Evaluation, error) {
+	var eval Evaluation
+	err := c.do(ctx, http.MethodPost, "/evaluate", candidate, &eval)
+	return eval, err
+}
+
+// Verify returns the requests no scenario matched since the last reset; a contract test asserts
+// that Passed is true.
+func (c *AdminClient) Verify(ctx context.Context) (Verification, error) {
+	var v Verification
+	err := c.do(ctx, http.MethodGet, "/verify", nil, &v)
+	return v, err
+}
+
+// ResetVerify forgets the unexpected requests, e.g. between tests.
+func (c *AdminClient) ResetVerify(ctx context.Context) error {
+	return c.do(ctx, http.MethodDelete, "/verify", nil, nil)
+}
+
+// do sends a control API request with an optional JSON body and decodes a 2xx response into out.
+func (c *AdminClient) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+"/_mokku"+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if out == nil {
+		return nil
+	}
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = io.ReadAll(resp.Body)
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,402 @@
+package mokkutc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAdminClient_SetFlag_SendsTokenAndBody(t *testing.T) {
+	// Given: a control API expecting a bearer token
+	var gotAuth, gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotAuth, gotPath, gotBody = r.Header.Get("Authorization"), r.Method+" "+r.URL.Path, string(body)
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "strict_model_validation", "enabled": true, "source": "admin"})
+	}))
+	defer srv.Close()
+	client := NewAdminClient(srv.URL+"/", "secret")
+
+	// When
+	status, err := client.SetFlag(context.Background(), "strict_model_validation", true)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer secret" || gotPath != "PUT /_mokku/flags/strict_model_validation" || gotBody != `{"enabled":true}` {
+		t.Errorf("unexpected request: %q %q %q", gotAuth, gotPath, gotBody)
+	}
+	if !status.Enabled || status.Source != "admin" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestAdminClient_ErrorStatus(t *testing.T) {
+	// Given: a control API rejecting the token
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"code":"invalid_admin_token"}}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	// When
+	_, err := NewAdminClient(srv.URL, "").Streams(context.Background())
+
+	// Then
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a 401 StatusError, got %v", err)
+	}
+}
+
+func TestAdminClient_Requests_EncodesFilter(t *testing.T) {
+	// Given: a control API listing one captured request
+	var gotQuery string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"id":7,"method":"POST","path":"/v1/chat/completions",` +
+			`"body":{"model":"gpt-4o"},"model":"gpt-4o","safety_identifier":"u-42","response":{"status":200}}]}`))
+	}))
+	defer srv.Close()
+	client := NewAdminClient(srv.URL, "")
+
+	// When
+	reqs, err := client.Requests(context.Background(), RequestFilter{Path: "/v1/chat/completions", Tag: "vision", SafetyIdentifier: "u-42", Limit: 1})
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotQuery != "limit=1&path=%2Fv1%2Fchat%2Fcompletions&safety_identifier=u-42&tag=vision" {
+		t.Errorf("unexpected query %q", gotQuery)
+	}
+	if len(reqs) != 1 || reqs[0].ID != 7 || reqs[0].Model != "gpt-4o" || reqs[0].SafetyIdentifier != "u-42" || reqs[0].Response.Status != 200 {
+		t.Errorf("unexpected requests %+v", reqs)
+	}
+}
+
+func TestAdminClient_SetRegion_SendsRegion(t *testing.T) {
+	// Given: a control API accepting a region change
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.Method+" "+r.URL.Path, string(body)
+		_, _ = w.Write([]byte(`{"name":"eu","status":503,"error_rate":1,"healthy":false,"source":"admin","requests":3}`))
+	}))
+	defer srv.Close()
+
+	// When
+	status, err := NewAdminClient(srv.URL, "").SetRegion(context.Background(), "eu", Region{Status: 503})
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "PUT /_mokku/regions/eu" || gotBody != `{"status":503}` {
+		t.Errorf("unexpected request: %q %q", gotPath, gotBody)
+	}
+	if status.Name != "eu" || status.Healthy || status.ErrorRate != 1 || status.Requests != 3 {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestAdminClient_BanUser_SendsReasonAndUnbans(t *testing.T) {
+	// Given: a control API banning a user once
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, r.Method+" "+r.URL.EscapedPath()+" "+string(body))
+		switch {
+		case r.Method == http.MethodPut:
+			_, _ = w.Write([]byte(`{"id":"u/7","reason":"fraud","source":"admin","banned_at":"2026-01-01T00:00:00Z"}`))
+		case len(got) == 2:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	client := NewAdminClient(srv.URL, "")
+
+	// When
+	user, err := client.BanUser(context.Background(), "u/7", "fraud")
+	unbanErr := client.UnbanUser(context.Background(), "u/7")
+	againErr := client.UnbanUser(context.Background(), "u/7")
+
+	// Then
+	if err != nil || unbanErr != nil {
+		t.Fatal(err, unbanErr)
+	}
+	if user.ID != "u/7" || user.Source != "admin" || user.BannedAt.IsZero() {
+		t.Errorf("unexpected user %+v", user)
+	}
+	if got[0] != `PUT /_mokku/banned-users/u%2F7 {"reason":"fraud"}` || got[1] != "DELETE /_mokku/banned-users/u%2F7 " {
+		t.Errorf("unexpected requests %q", got)
+	}
+	var statusErr *StatusError
+	if !errors.As(againErr, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 StatusError, got %v", againErr)
+	}
+}
+
+func TestAdminClient_AdvanceClock_SendsSeconds(t *testing.T) {
+	// Given: a control API advancing the clock
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = r.Method + " " + r.URL.Path + " " + string(body)
+		_, _ = w.Write([]byte(`{"now":"2026-01-01T01:30:00Z","offset_seconds":5400}`))
+	}))
+	defer srv.Close()
+	client := NewAdminClient(srv.URL, "")
+
+	// When
+	status, err := client.AdvanceClock(context.Background(), 90*time.Minute)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `POST /_mokku/clock/advance {"seconds":5400}` {
+		t.Errorf("unexpected request %q", got)
+	}
+	if status.OffsetSeconds != 5400 || status.Now.IsZero() {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+func TestAdminClient_SetChaos_SendsProfile(t *testing.T) {
+	// Given: a control API activating a chaos profile
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.Method+" "+r.URL.Path, string(body)
+		_, _ = w.Write([]byte(`{"active":"flaky-network","source":"admin","intensity":1,"stats":{"requests":0},` +
+			`"profiles":[{"name":"flaky-network","builtin":true}]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	status, err := NewAdminClient(srv.URL, "").SetChaos(context.Background(), "flaky-network")
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "PUT /_mokku/chaos" || gotBody != `{"profile":"flaky-network"}` {
+		t.Errorf("unexpected request: %q %q", gotPath, gotBody)
+	}
+	if status.Active != "flaky-network" || len(status.Profiles) != 1 || !status.Profiles[0].Builtin {
+		t.Errorf("unexpected status: %+v", status)
+	}
+}
+
+func TestAdminClient_Evaluate_SendsCandidate(t *testing.T) {
+	// Given: a control API evaluating a candidate request
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.Method+" "+r.URL.Path, string(body)
+		_, _ = w.Write([]byte(`{"object":"mokku.evaluation","matched":"overloaded","seed":0,` +
+			`"response":{"status":503,"error":{"message":"busy","type":"server_error"},"latency_ms":0},` +
+			`"trace":[{"name":"weather","matched":false,"reasons":["model is \"a\", want \"b\""]},{"name":"overloaded","matched":true}]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	eval, err := NewAdminClient(srv.URL, "").Evaluate(context.Background(), Candidate{
+		Path:    "/v1/chat/completions",
+		Headers: map[string]string{"X-Test-Case": "overload"},
+		Body:    map[string]any{"model": "a"},
+	})
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"path":"/v1/chat/completions","headers":{"X-Test-Case":"overload"},"body":{"model":"a"}}`
+	if gotPath != "POST /_mokku/evaluate" || gotBody != want {
+		t.Errorf("unexpected request: %q %q", gotPath, gotBody)
+	}
+	if eval.Matched != "overloaded" || eval.Response.Status != 503 || len(eval.Trace) != 2 || len(eval.Trace[0].Reasons) != 1 {
+		t.Errorf("unexpected evaluation: %+v", eval)
+	}
+}
+
+func TestAdminClient_ReplaceScenarios_SendsScenarioFile(t *testing.T) {
+	// Given: a control API accepting a scenario set
+	var gotPath, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotPath, gotBody = r.Method+" "+r.URL.Path, string(body)
+		_, _ = w.Write([]byte(`{"object":"list","data":["greeting"]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	names, err := NewAdminClient(srv.URL, "").ReplaceScenarios(context.Background(), []map[string]any{
+		{"name": "greeting", "response": map[string]any{"content": "hi"}},
+	})
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"scenarios":[{"name":"greeting","response":{"content":"hi"}}]}`
+	if gotPath != "PUT /_mokku/scenarios" || gotBody != want {
+		t.Errorf("unexpected request: %q %q", gotPath, gotBody)
+	}
+	if len(names) != 1 || names[0] != "greeting" {
+		t.Errorf("unexpected names: %v", names)
+	}
+}
+
+func TestAdminClient_Verify_DecodesUnexpectedRequests(t *testing.T) {
+	// Given: a control API reporting one unexpected request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"mokku.verification","passed":false,"unexpected_requests":1,"recent":[` +
+			`{"time":"2024-05-06T07:08:09Z","method":"POST","path":"/v1/completions","closest":"weather",` +
+			`"diff":[{"field":"message","expected":"weather","pattern":true,"actual":"rain"}]}]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	v, err := NewAdminClient(srv.URL, "").Verify(context.Background())
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.Passed || v.UnexpectedRequests != 1 || v.Recent[0].Closest != "weather" || v.Recent[0].Diff[0].Actual != "rain" {
+		t.Errorf("unexpected verification: %+v", v)
+	}
+}
+
+func TestAdminClient_Connections_DecodesStats(t *testing.T) {
+	// Given: a control API reporting one reused connection
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"mokku.connections","opened":1,"closed":0,"requests":3,"reused_requests":2,` +
+			`"requests_per_connection":3,"protocols":{"HTTP/1.1":3},"open":[{"id":1,"remote_addr":"127.0.0.1:5000",` +
+			`"proto":"HTTP/1.1","opened":"2024-05-06T07:08:09Z","requests":3}],"recent":[]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	stats, err := NewAdminClient(srv.URL, "").Connections(context.Background())
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.ReusedRequests != 2 || stats.Protocols["HTTP/1.1"] != 3 || stats.Open[0].Requests != 3 || stats.Open[0].Closed != nil {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAdminClient_CaptureStats_DecodesEvictions(t *testing.T) {
+	// Given: a control API reporting evicted requests
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"mokku.capture","enabled":true,"entries":2,"bytes":2048,"max_entries":1000,` +
+			`"max_bytes":67108864,"eviction":"lru","captured":5,"evicted":{"size":0,"bytes":3,"ttl":0},"evicted_bytes":3072}`))
+	}))
+	defer srv.Close()
+
+	// When
+	stats, err := NewAdminClient(srv.URL, "").CaptureStats(context.Background())
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 2 || stats.Eviction != "lru" || stats.Evicted["bytes"] != 3 || stats.EvictedBytes != 3072 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAdminClient_Overhead_DecodesEndpoints(t *testing.T) {
+	// Given: a control API reporting one endpoint
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"mokku.overhead","endpoints":[{"endpoint":"POST /v1/chat/completions","requests":2,` +
+			`"overhead_ms":{"p50":0.2,"p90":0.4,"p99":0.4,"max":0.4},"injected_ms":{"p50":0,"p90":200,"p99":200,"max":200},` +
+			`"slo_ms":5,"slo_violations":0}]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	endpoints, err := NewAdminClient(srv.URL, "").Overhead(context.Background())
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(endpoints) != 1 || endpoints[0].Requests != 2 || endpoints[0].Overhead.P90 != 0.4 || endpoints[0].Injected.Max != 200 || endpoints[0].SLO != 5 {
+		t.Errorf("unexpected endpoints: %+v", endpoints)
+	}
+}
+
+func TestAdminClient_RequestBody_ReturnsRawStream(t *testing.T) {
+	// Given: a control API serving a spilled stream
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {}\n\ndata: [DONE]\n\n"))
+	}))
+	defer srv.Close()
+
+	// When
+	body, err := NewAdminClient(srv.URL, "").RequestBody(context.Background(), 7)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/_mokku/requests/7/body" || string(body) != "data: {}\n\ndata: [DONE]\n\n" {
+		t.Errorf("unexpected path %q or body %q", gotPath, body)
+	}
+}
+
+func TestAdminClient_Tags_DecodesRollUp(t *testing.T) {
+	// Given: a control API reporting one tag
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"mokku.tags","tags":[{"tag":"agent-loop","requests":3,"errors":1,"streams":2,` +
+			`"duration_ms":{"p50":12,"p90":40,"p99":40,"max":40}}],"untagged":5}`))
+	}))
+	defer srv.Close()
+
+	// When
+	stats, err := NewAdminClient(srv.URL, "").Tags(context.Background())
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Untagged != 5 || len(stats.Tags) != 1 || stats.Tags[0].Tag != "agent-loop" || stats.Tags[0].Errors != 1 || stats.Tags[0].Duration.P50 != 12 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestAdminClient_Alerts_DecodesDowngrade(t *testing.T) {
+	// Given: a control API reporting one alert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"object":"list","data":[{"time":"2026-01-01T00:00:00Z","kind":"sdk_downgrade",` +
+			`"message":"python SDK 1.40.0 downgraded from 1.55.0","sdk":"python","version":"1.40.0","previous":"1.55.0"}]}`))
+	}))
+	defer srv.Close()
+
+	// When
+	alerts, err := NewAdminClient(srv.URL, "").Alerts(context.Background())
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alerts) != 1 || alerts[0].Kind != "sdk_downgrade" || alerts[0].Previous != "1.55.0" {
+		t.Errorf("unexpected alerts: %+v", alerts)
+	}
+}
@@ -0,0 +1,101 @@
+// Package mokkutc is a testcontainers-go module for openai-mokku, a deterministic mock of the OpenAI
+// API. It starts mokku in Docker with programmatic config, waits until it is healthy, and exposes a
+// typed client for the control API.
+//
+//	ctr, err := mokkutc.Run(ctx, mokkutc.DefaultImage,
+//		mokkutc.WithFeatures("strict_model_validation"),
+//		mokkutc.WithAdminToken("admin-secret"))
+//	defer testcontainers.CleanupContainer(t, ctr)
+//	baseURL, _ := ctr.BaseURL(ctx) // use baseURL + "/v1" as the OpenAI base URL
+package mokkutc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// DefaultImage is the released mokku image. To test local changes, build the repository's Dockerfile
+// and pass its tag to Run instead.
+const DefaultImage = "ghcr.io/takumi3488/openai-mokku-go:latest"
+
+const (
+	port       = "8080/tcp"
+	configPath = "/mokku.yaml"
+)
+
+// Container is a running mokku container.
+type Container struct {
+	testcontainers.Container
+	adminToken string
+}
+
+// Run starts a mokku container from img and waits until GET /healthz succeeds. Tracing is disabled
+// unless OTEL_EXPORTER_OTLP_ENDPOINT is set with testcontainers.WithEnv.
+func Run(ctx context.Context, img string, opts ...testcontainers.ContainerCustomizer) (*Container, error) {
+	req := testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        img,
+			ExposedPorts: []string{port},
+			Env:          map[string]string{"OTEL_EXPORTER_OTLP_ENDPOINT": "localhost:4317"},
+			WaitingFor:   wait.ForHTTP("/healthz").WithPort(port),
+		},
+		Started: true,
+	}
+	for _, opt := range opts {
+		if err := opt.Customize(&req); err != nil {
+			return nil, err
+		}
+	}
+
+	ctr, err := testcontainers.GenericContainer(ctx, req)
+	var c *Container
+	if ctr != nil {
+		c = &Container{Container: ctr, adminToken: req.Env["MOKKU_ADMIN_TOKEN"]}
+	}
+	if err != nil {
+		return c, fmt.Errorf("start mokku: %w", err)
+	}
+	return c, nil
+}
+
+// WithConfig mounts a MOKKU_CONFIG file with the given YAML (or JSON) content.
+func WithConfig(config string) testcontainers.CustomizeRequestOption {
+	return func(req *testcontainers.GenericContainerRequest) error {
+		req.Files = append(req.Files, testcontainers.ContainerFile{
+			Reader:            strings.NewReader(config),
+			ContainerFilePath: configPath,
+			FileMode:          0o644,
+		})
+		return testcontainers.WithEnv(map[string]string{"MOKKU_CONFIG": configPath})(req)
+	}
+}
+
+// WithFeatures enables feature flags through MOKKU_FEATURES; prefix a name with "-" to disable it.
+func WithFeatures(names ...string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(map[string]string{"MOKKU_FEATURES": strings.Join(names, ",")})
+}
+
+// WithAdminToken protects the control API with a read-write bearer token, which the client returned
+// by Container.Admin sends.
+func WithAdminToken(token string) testcontainers.CustomizeRequestOption {
+	return testcontainers.WithEnv(map[string]string{"MOKKU_ADMIN_TOKEN": token})
+}
+
+// BaseURL returns the URL of the mokku server, e.g. "http://localhost:32768". The OpenAI base URL is
+// BaseURL + "/v1".
+func (c *Container) BaseURL(ctx context.Context) (string, error) {
+	return c.PortEndpoint(ctx, port, "http")
+}
+
+// Admin returns a client for the control API of the container.
+func (c *Container) Admin(ctx context.Context) (*AdminClient, error) {
+	baseURL, err := c.BaseURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewAdminClient(baseURL, c.adminToken), nil
+}
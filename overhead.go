@@ -0,0 +1,239 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"openai-mokku/api"
+)
+
+// overheadHeader is the response header carrying mokku's own processing time of a request, in
+// milliseconds: openai-processing-ms without the injected latency.
+const overheadHeader = "X-Mokku-Overhead-Ms"
+
+// overheadAnyEndpoint is the overhead_slo key applying to endpoints without their own entry.
+const overheadAnyEndpoint = "*"
+
+// maxOverheadEndpoints is the number of endpoints whose overhead is tracked; the least recently
+// added endpoint is forgotten beyond it.
+const maxOverheadEndpoints = 200
+
+// maxOverheadSamples is the number of recent requests per endpoint the percentiles are computed from.
+const maxOverheadSamples = 1000
+
+// injectedDelay accumulates the simulated latency waited for while serving a request. It is safe
+// for concurrent use.
+type injectedDelay struct {
+	total atomic.Int64
+}
+
+// Add records a waited latency.
+func (d *injectedDelay) Add(latency time.Duration) {
+	if d != nil {
+		d.total.Add(int64(latency))
+	}
+}
+
+// Total returns the latency waited for so far.
+func (d *injectedDelay) Total() time.Duration {
+	if d == nil {
+		return 0
+	}
+	return time.Duration(d.total.Load())
+}
+
+// injectedDelayContextKey is the context key of a request's injectedDelay.
+type injectedDelayContextKey struct{}
+
+// withInjectedDelay returns a context that waitLatency records the request's injected latency in.
+func withInjectedDelay(ctx context.Context) (context.Context, *injectedDelay) {
+	d := &injectedDelay{}
+	return context.WithValue(ctx, injectedDelayContextKey{}, d), d
+}
+
+// injectedDelayFromContext returns the injectedDelay of a request, or nil.
+func injectedDelayFromContext(ctx context.Context) *injectedDelay {
+	d, _ := ctx.Value(injectedDelayContextKey{}).(*injectedDelay)
+	return d
+}
+
+// latencySummary is the distribution of a latency over an endpoint's recent requests, in milliseconds.
+type latencySummary struct {
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// endpointOverhead is the overhead of one endpoint reported by GET /_mokku/overhead.
+type endpointOverhead struct {
+	// Endpoint is the method and path pattern, such as "POST /v1/chat/completions".
+	Endpoint string `json:"endpoint"`
+	Requests int64  `json:"requests"`
+	// Overhead is mokku's own processing time and Injected the simulated latency, over the most
+	// recent maxOverheadSamples requests.
+	Overhead latencySummary `json:"overhead_ms"`
+	Injected latencySummary `json:"injected_ms"`
+	// SLO is the endpoint's overhead objective and SLOViolations the requests that exceeded it.
+	SLO           float64 `json:"slo_ms,omitempty"`
+	SLOViolations int64   `json:"slo_violations"`
+}
+
+// overheadStats is the response body of GET /_mokku/overhead.
+type overheadStats struct {
+	Object    string             `json:"object"`
+	Endpoints []endpointOverhead `json:"endpoints"`
+}
+
+// overheadSample is the latency split of one request.
+type overheadSample struct {
+	overhead time.Duration
+	injected time.Duration
+}
+
+// endpointSamples is the tracked state of one endpoint.
+type endpointSamples struct {
+	endpoint   string
+	requests   int64
+	violations int64
+	recent     *ringBuffer[overheadSample]
+}
+
+// overheadTracker records mokku's own processing time per endpoint, separately from the latency it
+// injects, so load tests can tell simulated provider latency from mock overhead, and counts the
+// requests exceeding the configured overhead SLOs. It is safe for concurrent use.
+type overheadTracker struct {
+	mu        sync.Mutex
+	slos      map[string]time.Duration
+	endpoints *boundedMap[string, *endpointSamples]
+}
+
+// newOverheadTracker creates a tracker with the configured overhead SLOs.
+func newOverheadTracker(slos map[string]string) (*overheadTracker, error) {
+	t := &overheadTracker{endpoints: newBoundedMap[string, *endpointSamples](maxOverheadEndpoints)}
+	if err := t.Load(slos); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Load replaces the overhead SLOs, keyed by endpoint or "*". Recorded requests are kept. On error
+// the current SLOs are kept.
+func (t *overheadTracker) Load(slos map[string]string) error {
+	return installNow(t.prepare(slos))
+}
+
+// prepare validates the SLOs and returns the function installing them.
+func (t *overheadTracker) prepare(slos map[string]string) (func(), error) {
+	parsed := make(map[string]time.Duration, len(slos))
+	for endpoint, value := range slos {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("overhead_slo.%s must be a positive duration such as 5ms, got %q", endpoint, value)
+		}
+		parsed[endpoint] = d
+	}
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.slos = parsed
+	}, nil
+}
+
+// slo returns the overhead SLO of an endpoint, 0 without one. The caller holds t.mu.
+func (t *overheadTracker) slo(endpoint string) time.Duration {
+	if d, ok := t.slos[endpoint]; ok {
+		return d
+	}
+	return t.slos[overheadAnyEndpoint]
+}
+
+// Record adds a request to an endpoint.
+func (t *overheadTracker) Record(endpoint string, overhead, injected time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.endpoints.Get(endpoint)
+	if !ok {
+		s = &endpointSamples{endpoint: endpoint, recent: newRingBuffer[overheadSample](maxOverheadSamples)}
+		t.endpoints.Put(endpoint, s)
+	}
+	s.requests++
+	if slo := t.slo(endpoint); slo > 0 && overhead > slo {
+		s.violations++
+	}
+	s.recent.Push(overheadSample{overhead: overhead, injected: injected})
+}
+
+// Stats returns the overhead of every tracked endpoint, sorted by endpoint.
+func (t *overheadTracker) Stats() overheadStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := overheadStats{Object: "mokku.overhead", Endpoints: []endpointOverhead{}}
+	for _, s := range t.endpoints.Values() {
+		samples := s.recent.Snapshot()
+		overhead := make([]time.Duration, len(samples))
+		injected := make([]time.Duration, len(samples))
+		for i, sample := range samples {
+			overhead[i], injected[i] = sample.overhead, sample.injected
+		}
+		stats.Endpoints = append(stats.Endpoints, endpointOverhead{
+			Endpoint:      s.endpoint,
+			Requests:      s.requests,
+			Overhead:      summarizeLatency(overhead),
+			Injected:      summarizeLatency(injected),
+			SLO:           durationMS(t.slo(s.endpoint)),
+			SLOViolations: s.violations,
+		})
+	}
+	slices.SortFunc(stats.Endpoints, func(a, b endpointOverhead) int { return cmp.Compare(a.Endpoint, b.Endpoint) })
+	return stats
+}
+
+// Reset forgets every recorded request.
+func (t *overheadTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endpoints.Clear()
+}
+
+// summarizeLatency returns the nearest-rank percentiles of latencies.
+func summarizeLatency(latencies []time.Duration) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+	slices.Sort(latencies)
+	rank := func(p float64) float64 {
+		return durationMS(latencies[int(math.Ceil(p*float64(len(latencies))))-1])
+	}
+	return latencySummary{P50: rank(0.5), P90: rank(0.9), P99: rank(0.99), Max: durationMS(latencies[len(latencies)-1])}
+}
+
+// durationMS converts a duration to milliseconds with microsecond precision.
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// formatOverheadMS formats a duration for the X-Mokku-Overhead-Ms header.
+func formatOverheadMS(d time.Duration) string {
+	return strconv.FormatFloat(durationMS(d), 'f', 3, 64)
+}
+
+// endpointName returns the method and path pattern of a /v1 request routed by the ogen server, such
+// as "GET /v1/files/{file_id}", or its method and path when no route matches.
+func endpointName(h http.Handler, r *http.Request) string {
+	if s, ok := h.(*api.Server); ok {
+		// Patterns are relative to the /v1 path prefix of the server
+		if route, ok := s.FindRoute(r.Method, r.URL.Path); ok {
+			return r.Method + " /v1" + route.PathPattern()
+		}
+	}
+	return r.Method + " " + r.URL.Path
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// --- newOverheadTracker ---
+
+func TestNewOverheadTracker_RejectsInvalidSLOs(t *testing.T) {
+	// Given
+	for _, slo := range []string{"fast", "0s", "-1ms"} {
+		// When
+		_, err := newOverheadTracker(map[string]string{overheadAnyEndpoint: slo})
+		// Then
+		if err == nil {
+			t.Errorf("expected an error for %q", slo)
+		}
+	}
+}
+
+// --- overheadTracker.Record ---
+
+func TestOverheadTracker_Record_CountsSLOViolationsPerEndpoint(t *testing.T) {
+	// Given: a 5ms SLO for every endpoint and 50ms for image generation
+	tr, err := newOverheadTracker(map[string]string{overheadAnyEndpoint: "5ms", "POST /v1/images/generations": "50ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	tr.Record("POST /v1/chat/completions", time.Millisecond, 2*time.Second)
+	tr.Record("POST /v1/chat/completions", 8*time.Millisecond, 0)
+	tr.Record("POST /v1/images/generations", 8*time.Millisecond, 0)
+
+	// Then: the injected latency does not count against the SLO
+	stats := tr.Stats()
+	if len(stats.Endpoints) != 2 {
+		t.Fatalf("expected 2 endpoints, got %+v", stats.Endpoints)
+	}
+	chat, images := stats.Endpoints[0], stats.Endpoints[1]
+	if chat.Requests != 2 || chat.SLO != 5 || chat.SLOViolations != 1 || chat.Overhead.Max != 8 || chat.Injected.Max != 2000 {
+		t.Errorf("unexpected chat overhead %+v", chat)
+	}
+	if images.Requests != 1 || images.SLO != 50 || images.SLOViolations != 0 {
+		t.Errorf("unexpected image overhead %+v", images)
+	}
+}
+
+func TestOverheadTracker_Concurrent_RecordAndStats(t *testing.T) {
+	// Given
+	tr, _ := newOverheadTracker(map[string]string{overheadAnyEndpoint: "1ms"})
+	var wg sync.WaitGroup
+
+	// When: requests to more endpoints than are tracked are recorded while stats are read
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 500 {
+				tr.Record(fmt.Sprintf("POST /v1/e%d", (i*500+j)%(maxOverheadEndpoints+50)), time.Duration(j)*time.Microsecond*10, 0)
+				if j%50 == 0 {
+					_ = tr.Stats()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: only the most recent endpoints and samples are kept
+	stats := tr.Stats()
+	if len(stats.Endpoints) != maxOverheadEndpoints {
+		t.Errorf("expected %d endpoints, got %d", maxOverheadEndpoints, len(stats.Endpoints))
+	}
+	for _, e := range stats.Endpoints {
+		if e.Requests == 0 || e.SLOViolations > e.Requests {
+			t.Errorf("unexpected endpoint %+v", e)
+		}
+	}
+}
+
+// --- summarizeLatency ---
+
+func TestSummarizeLatency_NearestRankPercentiles(t *testing.T) {
+	// Given: 1ms through 100ms in random order
+	latencies := make([]time.Duration, 100)
+	for i := range latencies {
+		latencies[i] = time.Duration((i*37)%100+1) * time.Millisecond
+	}
+	// When
+	got := summarizeLatency(latencies)
+	// Then
+	if want := (latencySummary{P50: 50, P90: 90, P99: 99, Max: 100}); got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if got := summarizeLatency(nil); got != (latencySummary{}) {
+		t.Errorf("expected zeros without samples, got %+v", got)
+	}
+}
+
+// --- waitLatency ---
+
+func TestWaitLatency_AddsWaitedTimeToInjectedDelay(t *testing.T) {
+	// Given
+	ctx, delay := withInjectedDelay(context.Background())
+	// When
+	waitLatency(ctx, 20*time.Millisecond)
+	waitLatency(ctx, 10*time.Millisecond)
+	// Then
+	if total := delay.Total(); total < 30*time.Millisecond || total > time.Second {
+		t.Errorf("expected about 30ms, got %v", total)
+	}
+}
+
+// --- endpointName ---
+
+func TestEndpointName_FallsBackToPathWithoutRoutes(t *testing.T) {
+	// Given
+	r, _ := http.NewRequest(http.MethodGet, "/v1/files/file-abc", nil)
+	// When
+	got := endpointName(http.NotFoundHandler(), r)
+	// Then
+	if got != "GET /v1/files/file-abc" {
+		t.Errorf("unexpected endpoint %q", got)
+	}
+}
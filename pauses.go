@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+)
+
+// pauseMarker matches the inline delay markers of scenario content, such as <<pause:500ms>>.
+var pauseMarker = regexp.MustCompile(`<<pause:([^<>]*)>>`)
+
+// maxContentPause is the longest delay a pause marker can add, so a typo cannot hang a test run.
+const maxContentPause = time.Minute
+
+// contentPause is a delay marked in scenario content, so a UI test can reproduce a model hesitating
+// mid-sentence at an exact position.
+type contentPause struct {
+	// Offset is the byte offset of the pause in the content without markers.
+	Offset int
+	Delay  time.Duration
+}
+
+// stripPauses removes the pause markers from content and returns them in content order.
+func stripPauses(content string) (string, []contentPause, error) {
+	matches := pauseMarker.FindAllStringSubmatchIndex(content, -1)
+	if len(matches) == 0 {
+		return content, nil, nil
+	}
+	stripped := make([]byte, 0, len(content))
+	pauses := make([]contentPause, 0, len(matches))
+	last := 0
+	for _, m := range matches {
+		value := content[m[2]:m[3]]
+		delay, err := time.ParseDuration(value)
+		if err != nil || delay < 0 || delay > maxContentPause {
+			return content, nil, fmt.Errorf("pause marker %s must be a duration between 0s and %s", content[m[0]:m[1]], maxContentPause)
+		}
+		stripped = append(stripped, content[last:m[0]]...)
+		pauses = append(pauses, contentPause{Offset: len(stripped), Delay: delay})
+		last = m[1]
+	}
+	stripped = append(stripped, content[last:]...)
+	return string(stripped), pauses, nil
+}
+
+// totalPause returns the sum of the delays of pauses.
+func totalPause(pauses []contentPause) time.Duration {
+	var total time.Duration
+	for _, p := range pauses {
+		total += p.Delay
+	}
+	return total
+}
+
+// pausesStreamed reports whether the pauses of a request's scenario content are applied between the
+// chunks of its stream: that of a streamed chat completion. Other responses, including the streams
+// of the other provider surfaces, are sent after all the pauses.
+func pausesStreamed(r *http.Request, doc any) bool {
+	if _, ok := dialectFromContext(r.Context()); ok || !streamRequested(doc) {
+		return false
+	}
+	return r.URL.Path == "/v1/chat/completions"
+}
+
+// cutAtPauses cuts the chunks of content wherever a pause falls inside one, so every pause is at the
+// start of a chunk.
+func cutAtPauses(chunks []string, pauses []contentPause) []string {
+	out := make([]string, 0, len(chunks)+len(pauses))
+	pos, next := 0, 0
+	for _, chunk := range chunks {
+		for {
+			for next < len(pauses) && pauses[next].Offset <= pos {
+				next++
+			}
+			if next == len(pauses) || pauses[next].Offset >= pos+len(chunk) {
+				break
+			}
+			cut := pauses[next].Offset - pos
+			out = append(out, chunk[:cut])
+			chunk, pos = chunk[cut:], pos+cut
+		}
+		out = append(out, chunk)
+		pos += len(chunk)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// --- stripPauses ---
+
+func TestStripPauses_RemovesMarkersAndRecordsTheirOffsets(t *testing.T) {
+	// Given
+	content := "<<pause:10ms>>Hello,<<pause:500ms>> world<<pause:1s>>"
+
+	// When
+	stripped, pauses, err := stripPauses(content)
+
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []contentPause{{0, 10 * time.Millisecond}, {6, 500 * time.Millisecond}, {12, time.Second}}
+	if stripped != "Hello, world" || !slices.Equal(pauses, want) {
+		t.Errorf("expected %q with %v, got %q with %v", "Hello, world", want, stripped, pauses)
+	}
+	if total := totalPause(pauses); total != 1510*time.Millisecond {
+		t.Errorf("expected 1.51s in total, got %s", total)
+	}
+}
+
+func TestStripPauses_RejectsInvalidDelays(t *testing.T) {
+	for _, content := range []string{"a<<pause:soon>>b", "a<<pause:-1s>>b", "a<<pause:2m>>b"} {
+		// When
+		_, _, err := stripPauses(content)
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", content)
+		}
+	}
+}
+
+// --- cutAtPauses ---
+
+func TestCutAtPauses_CutsChunksSoPausesStartOne(t *testing.T) {
+	// Given: pauses inside the first chunk, at a chunk boundary, and at the end
+	chunks := []string{"Hello, wo", "rld", "!"}
+	pauses := []contentPause{{Offset: 2}, {Offset: 6}, {Offset: 12}, {Offset: 13}}
+
+	// When
+	cut := cutAtPauses(chunks, pauses)
+
+	// Then
+	want := []string{"He", "llo,", " wo", "rld", "!"}
+	if !slices.Equal(cut, want) {
+		t.Errorf("expected %q, got %q", want, cut)
+	}
+}
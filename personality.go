@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// conversationHeader is the request header naming the conversation a request belongs to.
+const conversationHeader = "X-Mokku-Conversation"
+
+// personalityHeader is the response header describing the personality a request was served with.
+const personalityHeader = "X-Mokku-Personality"
+
+// personalityTerseWords is the number of words a terse personality keeps of generated text.
+const personalityTerseWords = 12
+
+// personalityLatencies are the latency bands of personalities, by name.
+var personalityLatencies = []struct {
+	name  string
+	delay time.Duration
+}{{"fast", 25 * time.Millisecond}, {"steady", 250 * time.Millisecond}, {"slow", time.Second}}
+
+// personalityVerbosities are the verbosity levels of personalities.
+var personalityVerbosities = []string{"terse", "balanced", "verbose"}
+
+// personalityEmojis are the emojis that end the responses of personalities using emoji.
+var personalityEmojis = []string{"🙂", "🚀", "✨", "👍"}
+
+// personalityVerboseSuffix is the sentence a verbose personality adds to generated text.
+const personalityVerboseSuffix = " Let me know if you would like me to go into more detail on any part of this."
+
+// personality is the stable character of the mock model within one conversation: how fast it answers,
+// how much it says, and whether it uses emoji. It is derived from the conversation ID, so every turn
+// of a multi-turn test sees the same model rather than behavior varying from request to request.
+type personality struct {
+	Latency   string
+	Verbosity string
+	// Emoji is the emoji ending responses, "" for none.
+	Emoji string
+	delay time.Duration
+}
+
+// personalityFor derives the personality of a conversation from a hash of its ID.
+func personalityFor(conversation string) personality {
+	sum := sha256.Sum256([]byte(conversation))
+	latency := personalityLatencies[int(sum[0])%len(personalityLatencies)]
+	p := personality{
+		Latency:   latency.name,
+		Verbosity: personalityVerbosities[int(sum[1])%len(personalityVerbosities)],
+		delay:     latency.delay,
+	}
+	if sum[2]%2 == 0 {
+		p.Emoji = personalityEmojis[int(sum[3])%len(personalityEmojis)]
+	}
+	return p
+}
+
+// String formats the personality as the value of personalityHeader.
+func (p *personality) String() string {
+	return fmt.Sprintf("latency=%s; verbosity=%s; emoji=%t", p.Latency, p.Verbosity, p.Emoji != "")
+}
+
+// shape rewrites generated text in the personality's style. A nil personality keeps the text.
+func (p *personality) shape(text string) string {
+	if p == nil {
+		return text
+	}
+	switch p.Verbosity {
+	case "terse":
+		if words := strings.Fields(text); len(words) > personalityTerseWords {
+			text = strings.TrimRight(strings.Join(words[:personalityTerseWords], " "), ".,;:") + "."
+		}
+	case "verbose":
+		text += personalityVerboseSuffix
+	}
+	if p.Emoji != "" {
+		text += " " + p.Emoji
+	}
+	return text
+}
+
+// conversationID returns the ID of the conversation a request belongs to: conversationHeader, the
+// conversation parameter (an ID or an object with one), metadata.conversation_id, or else the
+// end-user of the request. It returns "" when the request names none of them.
+func conversationID(r *http.Request, doc any) string {
+	if id := r.Header.Get(conversationHeader); id != "" {
+		return id
+	}
+	m, _ := doc.(map[string]any)
+	switch c := m["conversation"].(type) {
+	case string:
+		if c != "" {
+			return c
+		}
+	case map[string]any:
+		if id, _ := c["id"].(string); id != "" {
+			return id
+		}
+	}
+	if metadata, ok := m["metadata"].(map[string]any); ok {
+		if id, _ := metadata["conversation_id"].(string); id != "" {
+			return id
+		}
+	}
+	user := requestEndUser(doc)
+	if user.SafetyIdentifier != "" {
+		return user.SafetyIdentifier
+	}
+	return user.User
+}
+
+type personalityContextKey struct{}
+
+// withPersonality stores the personality of a request in the context.
+func withPersonality(ctx context.Context, p *personality) context.Context {
+	return context.WithValue(ctx, personalityContextKey{}, p)
+}
+
+// personalityFromContext returns the personality stored by withPersonality, or nil.
+func personalityFromContext(ctx context.Context) *personality {
+	p, _ := ctx.Value(personalityContextKey{}).(*personality)
+	return p
+}
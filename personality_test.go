@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// --- personalityFor ---
+
+func TestPersonalityFor_StablePerConversation(t *testing.T) {
+	// When: the personality of each conversation is derived twice
+	seen := map[string]bool{}
+	for _, id := range []string{"conv-1", "conv-2", "conv-3", "conv-4", "conv-5", "conv-6", "conv-7", "conv-8", "alice", "bob"} {
+		first, second := personalityFor(id), personalityFor(id)
+
+		// Then: it is the same every time, and conversations differ
+		if first != second {
+			t.Errorf("%s: expected a stable personality, got %+v and %+v", id, first, second)
+		}
+		seen[first.String()] = true
+	}
+	if len(seen) < 3 {
+		t.Errorf("expected varied personalities, got %v", seen)
+	}
+}
+
+// --- personality.shape ---
+
+func TestPersonality_Shape(t *testing.T) {
+	long := "one two three four five six seven eight nine ten eleven twelve thirteen fourteen."
+	tests := []struct {
+		name        string
+		personality *personality
+		text        string
+		want        string
+	}{
+		{name: "none", text: "Echo: hi", want: "Echo: hi"},
+		{name: "balanced", personality: &personality{Verbosity: "balanced"}, text: "Echo: hi", want: "Echo: hi"},
+		{name: "terse", personality: &personality{Verbosity: "terse"}, text: long, want: "one two three four five six seven eight nine ten eleven twelve."},
+		{name: "terse short text", personality: &personality{Verbosity: "terse"}, text: "Echo: hi", want: "Echo: hi"},
+		{name: "verbose with emoji", personality: &personality{Verbosity: "verbose", Emoji: "🚀"}, text: "Echo: hi", want: "Echo: hi" + personalityVerboseSuffix + " 🚀"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			got := tt.personality.shape(tt.text)
+
+			// Then
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+// --- conversationID ---
+
+func TestConversationID(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		doc    map[string]any
+		want   string
+	}{
+		{name: "header wins", header: "conv-h", doc: map[string]any{"conversation": "conv-b", "user": "alice"}, want: "conv-h"},
+		{name: "conversation id", doc: map[string]any{"conversation": "conv-b", "user": "alice"}, want: "conv-b"},
+		{name: "conversation object", doc: map[string]any{"conversation": map[string]any{"id": "conv-o"}}, want: "conv-o"},
+		{name: "metadata", doc: map[string]any{"metadata": map[string]any{"conversation_id": "conv-m"}, "user": "alice"}, want: "conv-m"},
+		{name: "safety identifier", doc: map[string]any{"safety_identifier": "hash-1", "user": "alice"}, want: "hash-1"},
+		{name: "user", doc: map[string]any{"user": "alice"}, want: "alice"},
+		{name: "none", doc: map[string]any{"model": "gpt-4o"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+			if tt.header != "" {
+				r.Header.Set(conversationHeader, tt.header)
+			}
+
+			// When
+			got := conversationID(r, any(tt.doc))
+
+			// Then
+			if got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
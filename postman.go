@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// postmanSchema is the Postman collection format written by newPostmanCollection. Insomnia imports it too.
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Postman variables the exported requests refer to. They are defined on the collection and can be
+// overridden by a Postman environment with the same names.
+const (
+	postmanBaseURLVar = "baseUrl"
+	postmanAPIKeyVar  = "apiKey"
+)
+
+// postmanHopHeaders are captured headers left out of exported requests, as the client sets them.
+var postmanHopHeaders = map[string]bool{
+	"Host": true, "Content-Length": true, "Connection": true, "Accept-Encoding": true,
+	"Transfer-Encoding": true, "X-Forwarded-For": true,
+}
+
+// postmanCollection is a Postman collection (v2.1).
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Variable []postmanVariable `json:"variable"`
+}
+
+// postmanInfo describes a collection.
+type postmanInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// postmanVariable is a collection variable, referenced as {{key}}.
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+// postmanItem is a request of a collection.
+type postmanItem struct {
+	Name    string         `json:"name"`
+	Request postmanRequest `json:"request"`
+}
+
+// postmanRequest is the HTTP request of an item.
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Header []postmanHeader `json:"header"`
+	Body   *postmanBody    `json:"body,omitempty"`
+	URL    postmanURL      `json:"url"`
+}
+
+// postmanHeader is a request header.
+type postmanHeader struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// postmanBody is a raw request body.
+type postmanBody struct {
+	Mode    string `json:"mode"`
+	Raw     string `json:"raw"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+// postmanURL is a request URL relative to the base URL variable.
+type postmanURL struct {
+	Raw  string   `json:"raw"`
+	Host []string `json:"host"`
+	Path []string `json:"path"`
+}
+
+// newPostmanCollection converts captured requests, oldest first, into a Postman collection whose
+// requests are sent to {{baseUrl}} with the {{apiKey}} bearer token. Captured credentials are never
+// exported (see capturedHeaders), and the seed is pinned with the X-Mokku-Seed header so sending a
+// request to mokku reproduces its randomized behavior.
+func newPostmanCollection(name, baseURL string, entries []capturedExchange) postmanCollection {
+	c := postmanCollection{
+		Info: postmanInfo{
+			Name:        name,
+			Description: fmt.Sprintf("%d requests captured by openai-mokku %s", len(entries), serviceVersion),
+			Schema:      postmanSchema,
+		},
+		Item: make([]postmanItem, 0, len(entries)),
+		Variable: []postmanVariable{
+			{Key: postmanBaseURLVar, Value: strings.TrimSuffix(baseURL, "/"), Type: "string"},
+			{Key: postmanAPIKeyVar, Value: "", Type: "secret"},
+		},
+	}
+	for _, e := range entries {
+		c.Item = append(c.Item, newPostmanItem(e))
+	}
+	return c
+}
+
+// newPostmanItem converts a captured request into a collection item.
+func newPostmanItem(e capturedExchange) postmanItem {
+	itemName := fmt.Sprintf("#%d %s %s", e.ID, e.Method, e.Path)
+	if e.Model != "" {
+		itemName += " (" + e.Model + ")"
+	}
+	req := postmanRequest{
+		Method: e.Method,
+		Header: []postmanHeader{{Key: "Authorization", Value: "Bearer {{" + postmanAPIKeyVar + "}}"}},
+		URL: postmanURL{
+			Raw:  "{{" + postmanBaseURLVar + "}}" + e.Path,
+			Host: []string{"{{" + postmanBaseURLVar + "}}"},
+			Path: strings.Split(strings.TrimPrefix(e.Path, "/"), "/"),
+		},
+	}
+	names := make([]string, 0, len(e.Headers))
+	for name := range e.Headers {
+		canonical := http.CanonicalHeaderKey(name)
+		if !postmanHopHeaders[canonical] && canonical != seedHeader {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		req.Header = append(req.Header, postmanHeader{Key: name, Value: e.Headers[name]})
+	}
+	req.Header = append(req.Header, postmanHeader{Key: seedHeader, Value: strconv.FormatUint(e.Seed, 10)})
+	if len(e.Body) > 0 {
+		req.Body = &postmanBody{Mode: "raw", Raw: string(e.Body)}
+		req.Body.Options.Raw.Language = "json"
+		// Bodies that are not JSON documents are captured as JSON strings
+		var text string
+		if json.Unmarshal(e.Body, &text) == nil {
+			req.Body.Raw = text
+			req.Body.Options.Raw.Language = "text"
+		}
+	}
+	return postmanItem{Name: itemName, Request: req}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// --- newPostmanCollection ---
+
+func TestNewPostmanCollection_UsesVariablesAndPinsSeeds(t *testing.T) {
+	// Given: a captured chat request and a captured non-JSON body
+	entries := []capturedExchange{
+		{ID: 1, capturedRequest: capturedRequest{Method: "POST", Path: "/v1/chat/completions",
+			Headers: map[string]string{"Content-Type": "application/json", "Content-Length": "42", "X-Request-Id": "abc"},
+			Body:    json.RawMessage(`{"model":"gpt-4o"}`)}, Model: "gpt-4o", Seed: 7},
+		{ID: 2, capturedRequest: capturedRequest{Method: "POST", Path: "/v1/embeddings", Body: json.RawMessage(`"not json"`)}},
+	}
+
+	// When
+	c := newPostmanCollection("captured", "http://localhost:8080/", entries)
+
+	// Then: requests go to {{baseUrl}} with the {{apiKey}} token, without hop headers, with the seed
+	if c.Info.Schema != postmanSchema || len(c.Variable) != 2 || c.Variable[0].Value != "http://localhost:8080" {
+		t.Errorf("unexpected collection: %+v", c)
+	}
+	chat := c.Item[0]
+	if chat.Name != "#1 POST /v1/chat/completions (gpt-4o)" || chat.Request.URL.Raw != "{{baseUrl}}/v1/chat/completions" ||
+		strings.Join(chat.Request.URL.Path, "/") != "v1/chat/completions" {
+		t.Errorf("unexpected item: %+v", chat)
+	}
+	var headers []string
+	for _, h := range chat.Request.Header {
+		headers = append(headers, h.Key+": "+h.Value)
+	}
+	want := "Authorization: Bearer {{apiKey}},Content-Type: application/json,X-Request-Id: abc,X-Mokku-Seed: 7"
+	if strings.Join(headers, ",") != want {
+		t.Errorf("expected %s, got %v", want, headers)
+	}
+	if chat.Request.Body.Raw != `{"model":"gpt-4o"}` || chat.Request.Body.Options.Raw.Language != "json" {
+		t.Errorf("unexpected body: %+v", chat.Request.Body)
+	}
+	if body := c.Item[1].Request.Body; body.Raw != "not json" || body.Options.Raw.Language != "text" {
+		t.Errorf("expected the raw text body, got %+v", body)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// openAIVersion is the API version the real API reports in the openai-version header.
+const openAIVersion = "2020-10-01"
+
+// processingTimeWriter sets the openai-processing-ms and openai-version headers of the real API when
+// the response headers are written. The processing time runs from the arrival of the request until
+// then, so it covers scenario latency and generation but not the time spent streaming the body.
+// It also sets X-Mokku-Overhead-Ms, the processing time without the injected latency, and reports
+// both to record, if set.
+type processingTimeWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	delay       *injectedDelay
+	record      func(overhead, injected time.Duration)
+	wroteHeader bool
+}
+
+// newProcessingTimeWriter wraps w for a request that arrived at start and whose injected latency is
+// accumulated in delay.
+func newProcessingTimeWriter(w http.ResponseWriter, start time.Time, delay *injectedDelay, record func(overhead, injected time.Duration)) *processingTimeWriter {
+	return &processingTimeWriter{ResponseWriter: w, start: start, delay: delay, record: record}
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *processingTimeWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		elapsed, injected := time.Since(w.start), w.delay.Total()
+		overhead := max(elapsed-injected, 0)
+		w.Header().Set("openai-processing-ms", strconv.FormatInt(elapsed.Milliseconds(), 10))
+		w.Header().Set("openai-version", openAIVersion)
+		w.Header().Set(overheadHeader, formatOverheadMS(overhead))
+		if w.record != nil {
+			w.record(overhead, injected)
+		}
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter
+func (w *processingTimeWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *processingTimeWriter) Flush() {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *processingTimeWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestProcessingTimeWriter_SetsHeadersOnFirstWrite(t *testing.T) {
+	// Given: a request that arrived 120ms ago
+	rec := httptest.NewRecorder()
+	w := newProcessingTimeWriter(rec, time.Now().Add(-120*time.Millisecond), nil, nil)
+	// When
+	_, _ = w.Write([]byte("a"))
+	w.Flush()
+	// Then
+	ms, err := strconv.Atoi(rec.Header().Get("openai-processing-ms"))
+	if err != nil || ms < 120 || ms > 1000 {
+		t.Errorf("expected about 120ms, got %q", rec.Header().Get("openai-processing-ms"))
+	}
+	if rec.Header().Get("openai-version") != openAIVersion || rec.Code != http.StatusOK || !rec.Flushed {
+		t.Errorf("unexpected response: %d %v flushed=%t", rec.Code, rec.Header(), rec.Flushed)
+	}
+}
+
+func TestProcessingTimeWriter_OverheadExcludesInjectedLatency(t *testing.T) {
+	// Given: a request that arrived 120ms ago, 100ms of which were injected
+	rec := httptest.NewRecorder()
+	delay := &injectedDelay{}
+	delay.Add(100 * time.Millisecond)
+	var overhead, injected time.Duration
+	w := newProcessingTimeWriter(rec, time.Now().Add(-120*time.Millisecond), delay, func(o, i time.Duration) { overhead, injected = o, i })
+	// When
+	w.WriteHeader(http.StatusOK)
+	// Then
+	ms, err := strconv.ParseFloat(rec.Header().Get(overheadHeader), 64)
+	if err != nil || ms < 20 || ms > 900 {
+		t.Errorf("expected about 20ms, got %q", rec.Header().Get(overheadHeader))
+	}
+	if injected != 100*time.Millisecond || overhead < 20*time.Millisecond || overhead > 900*time.Millisecond {
+		t.Errorf("unexpected recorded split: overhead %v, injected %v", overhead, injected)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// proxyHeaderTimeout bounds the time a trusted proxy has to send its PROXY protocol header.
+const proxyHeaderTimeout = 5 * time.Second
+
+// maxProxyV1HeaderBytes is the longest PROXY protocol v1 header, including the CRLF.
+const maxProxyV1HeaderBytes = 107
+
+// proxyV2Signature starts every PROXY protocol v2 header.
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyConfig is the proxies section of the config file.
+type proxyConfig struct {
+	// Trusted lists the addresses (IPs or CIDR ranges) of the load balancers in front of mokku. Only
+	// their PROXY protocol headers and X-Forwarded-For headers are honored.
+	Trusted []string `yaml:"trusted" json:"trusted"`
+	// ProxyProtocol accepts PROXY protocol (v1 or v2) headers on connections from trusted proxies.
+	ProxyProtocol bool `yaml:"proxy_protocol" json:"proxy_protocol"`
+}
+
+// trustedProxies resolves the client address of connections and requests relayed by trusted
+// proxies. It is safe for concurrent use.
+type trustedProxies struct {
+	state atomic.Pointer[proxyState]
+}
+
+// proxyState is a compiled proxyConfig.
+type proxyState struct {
+	prefixes      []netip.Prefix
+	proxyProtocol bool
+}
+
+// newTrustedProxies creates a resolver for the configured proxies.
+func newTrustedProxies(cfg proxyConfig) (*trustedProxies, error) {
+	p := &trustedProxies{}
+	if err := p.Load(cfg); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Load replaces the trusted proxies. On error the current ones are kept.
+func (p *trustedProxies) Load(cfg proxyConfig) error {
+	return installNow(p.prepare(cfg))
+}
+
+// prepare validates the trusted proxies and returns the function installing them.
+func (p *trustedProxies) prepare(cfg proxyConfig) (func(), error) {
+	state := &proxyState{proxyProtocol: cfg.ProxyProtocol}
+	for i, entry := range cfg.Trusted {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(entry)
+			if addrErr != nil {
+				return nil, fmt.Errorf("proxies.trusted[%d]: %q is not an IP address or CIDR range", i, entry)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		state.prefixes = append(state.prefixes, prefix.Masked())
+	}
+	if cfg.ProxyProtocol && len(state.prefixes) == 0 {
+		return nil, fmt.Errorf("proxies.proxy_protocol requires proxies.trusted")
+	}
+	return func() {
+		p.state.Store(state)
+	}, nil
+}
+
+// trusts reports whether addr, a host or host:port, is a trusted proxy.
+func (p *trustedProxies) trusts(addr string) bool {
+	ip, ok := parseHostIP(addr)
+	if !ok {
+		return false
+	}
+	for _, prefix := range p.state.Load().prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseHostIP returns the IP of a host or host:port address.
+func parseHostIP(addr string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return ip.Unmap(), true
+}
+
+// ClientAddr returns the address of the client a request comes from: the peer address, or, when the
+// peer is a trusted proxy, the rightmost X-Forwarded-For address that is not a trusted proxy.
+func (p *trustedProxies) ClientAddr(r *http.Request) string {
+	if !p.trusts(r.RemoteAddr) {
+		return r.RemoteAddr
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		for _, hop := range strings.Split(header, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	client := r.RemoteAddr
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip, ok := parseHostIP(hops[i])
+		if !ok {
+			break
+		}
+		client = ip.String()
+		if !p.trusts(client) {
+			break
+		}
+	}
+	return client
+}
+
+// withClientAddr replaces the remote address of requests relayed by trusted proxies with the client
+// address, so audit entries and per-IP rate limits see the real client.
+func withClientAddr(proxies *trustedProxies, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if addr := proxies.ClientAddr(r); addr != r.RemoteAddr {
+			r = r.Clone(r.Context())
+			r.RemoteAddr = addr
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// proxyProtocolListener reads the PROXY protocol header of connections from trusted proxies, so
+// their RemoteAddr is the client behind an L4 load balancer.
+type proxyProtocolListener struct {
+	net.Listener
+	proxies *trustedProxies
+}
+
+// Accept implements net.Listener. The header is read on first use of the connection, in its own
+// goroutine, so a slow proxy cannot stall the accept loop.
+func (l *proxyProtocolListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if !l.proxies.state.Load().proxyProtocol {
+		return c, nil
+	}
+	return &proxyConn{Conn: c, proxies: l.proxies}, nil
+}
+
+// proxyConn is a connection that may start with a PROXY protocol header.
+type proxyConn struct {
+	net.Conn
+	proxies *trustedProxies
+	once    sync.Once
+	r       *bufio.Reader
+	remote  net.Addr
+	err     error
+}
+
+// init reads the PROXY protocol header once, if the peer is a trusted proxy. A connection from a
+// trusted proxy without a header keeps its peer address, so direct health checks still work.
+func (c *proxyConn) init() {
+	c.once.Do(func() {
+		c.r = bufio.NewReader(c.Conn)
+		c.remote = c.Conn.RemoteAddr()
+		if !c.proxies.trusts(c.remote.String()) {
+			return
+		}
+		_ = c.Conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		defer func() { _ = c.Conn.SetReadDeadline(time.Time{}) }()
+		addr, err := readProxyHeader(c.r)
+		if err != nil {
+			c.err = fmt.Errorf("PROXY protocol header from %s: %w", c.remote, err)
+			return
+		}
+		if addr != nil {
+			c.remote = addr
+		}
+	})
+}
+
+// Read implements net.Conn
+func (c *proxyConn) Read(b []byte) (int, error) {
+	c.init()
+	if c.err != nil {
+		return 0, c.err
+	}
+	return c.r.Read(b)
+}
+
+// RemoteAddr implements net.Conn
+func (c *proxyConn) RemoteAddr() net.Addr {
+	c.init()
+	return c.remote
+}
+
+// readProxyHeader consumes a PROXY protocol v1 or v2 header and returns the source address it
+// carries. It returns nil without consuming anything when the stream does not start with a header,
+// and nil after consuming a header without an address (v1 UNKNOWN or v2 LOCAL).
+func readProxyHeader(r *bufio.Reader) (net.Addr, error) {
+	if prefix, _ := r.Peek(len(proxyV2Signature)); bytes.Equal(prefix, proxyV2Signature) {
+		return readProxyV2Header(r)
+	}
+	if prefix, err := r.Peek(6); err != nil || string(prefix) != "PROXY " {
+		return nil, nil
+	}
+	line, err := r.ReadSlice('\n')
+	if err != nil || len(line) > maxProxyV1HeaderBytes || !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("malformed v1 header")
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, errors.New("malformed v1 header")
+	}
+	ip, err := netip.ParseAddr(fields[2])
+	port, portErr := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil || portErr != nil {
+		return nil, errors.New("malformed v1 address")
+	}
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, uint16(port))), nil
+}
+
+// readProxyV2Header consumes a PROXY protocol v2 header, including its TLVs.
+func readProxyV2Header(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	verCmd, family := header[12], header[13]
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("unsupported version %d", verCmd>>4)
+	}
+	if verCmd&0xf == 0 {
+		return nil, nil // LOCAL: a health check from the proxy itself
+	}
+	var ipLen int
+	switch family >> 4 {
+	case 1:
+		ipLen = 4
+	case 2:
+		ipLen = 16
+	default:
+		return nil, nil // AF_UNSPEC or AF_UNIX: no IP source address
+	}
+	if len(payload) < 2*ipLen+4 {
+		return nil, errors.New("truncated v2 addresses")
+	}
+	ip, _ := netip.AddrFromSlice(payload[:ipLen])
+	port := binary.BigEndian.Uint16(payload[2*ipLen:])
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip.Unmap(), port)), nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestProxies(t *testing.T, cfg proxyConfig) *trustedProxies {
+	t.Helper()
+	p, err := newTrustedProxies(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// --- trustedProxies.Load ---
+
+func TestTrustedProxies_Load_RejectsInvalidConfig(t *testing.T) {
+	// Given
+	cases := []proxyConfig{
+		{Trusted: []string{"lb.internal"}},
+		{Trusted: []string{"10.0.0.0/33"}},
+		{ProxyProtocol: true},
+	}
+	for _, cfg := range cases {
+		// When
+		_, err := newTrustedProxies(cfg)
+		// Then
+		if err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+// --- trustedProxies.ClientAddr ---
+
+func TestTrustedProxies_ClientAddr(t *testing.T) {
+	// Given: a trusted load balancer subnet and a single trusted address
+	p := newTestProxies(t, proxyConfig{Trusted: []string{"10.0.0.0/8", "192.0.2.1"}})
+	cases := []struct {
+		name, remote, forwardedFor, want string
+	}{
+		{"untrusted peer ignores the header", "203.0.113.5:1234", "198.51.100.1", "203.0.113.5:1234"},
+		{"trusted peer without header", "10.1.2.3:1234", "", "10.1.2.3:1234"},
+		{"trusted peer names the client", "10.1.2.3:1234", "198.51.100.1", "198.51.100.1"},
+		{"trusted hops are skipped", "10.1.2.3:1234", "198.51.100.1, 192.0.2.1, 10.9.9.9", "198.51.100.1"},
+		{"spoofed leftmost hop is ignored", "10.1.2.3:1234", "6.6.6.6, 198.51.100.1", "198.51.100.1"},
+		{"unparsable hop stops the walk", "10.1.2.3:1234", "198.51.100.1, garbage", "10.1.2.3:1234"},
+		{"IPv6 client", "192.0.2.1:1234", "2001:db8::1", "2001:db8::1"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tc.remote
+			if tc.forwardedFor != "" {
+				r.Header.Set("X-Forwarded-For", tc.forwardedFor)
+			}
+			// When
+			got := p.ClientAddr(r)
+			// Then
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestTrustedProxies_Concurrent_ClientAddrDuringReload(t *testing.T) {
+	// Given
+	p := newTestProxies(t, proxyConfig{Trusted: []string{"10.0.0.0/8"}})
+	var wg sync.WaitGroup
+
+	// When: requests are resolved while the trusted proxies are reloaded
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 200 {
+				if i%2 == 0 {
+					_ = p.Load(proxyConfig{Trusted: []string{fmt.Sprintf("10.%d.0.0/16", j%256)}})
+					continue
+				}
+				r, _ := http.NewRequest(http.MethodGet, "/", nil)
+				r.RemoteAddr = "127.0.0.1:1234"
+				r.Header.Set("X-Forwarded-For", "198.51.100.1")
+				if got := p.ClientAddr(r); got != r.RemoteAddr {
+					t.Errorf("expected the untrusted peer address, got %q", got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: the last configuration stays in effect
+	if !p.trusts("10.0.0.1") && !p.trusts("10.199.0.1") {
+		t.Error("expected a trusted 10.x subnet after the reloads")
+	}
+}
+
+// --- readProxyHeader ---
+
+func TestReadProxyHeader(t *testing.T) {
+	// Given
+	v2 := func(verCmd, family byte, payload ...byte) string {
+		return string(proxyV2Signature) + string([]byte{verCmd, family, 0, byte(len(payload))}) + string(payload)
+	}
+	cases := []struct {
+		name, input, want, rest string
+		wantErr                 bool
+	}{
+		{name: "v1 TCP4", input: "PROXY TCP4 198.51.100.1 10.0.0.1 5555 8080\r\nGET", want: "198.51.100.1:5555", rest: "GET"},
+		{name: "v1 TCP6", input: "PROXY TCP6 2001:db8::1 2001:db8::2 5555 8080\r\nGET", want: "[2001:db8::1]:5555", rest: "GET"},
+		{name: "v1 UNKNOWN", input: "PROXY UNKNOWN\r\nGET", rest: "GET"},
+		{name: "no header", input: "GET / HTTP/1.1\r\n", rest: "GET"},
+		{name: "v1 bad address", input: "PROXY TCP4 lb 10.0.0.1 5555 8080\r\nGET", wantErr: true},
+		{name: "v1 missing CRLF", input: "PROXY TCP4 198.51.100.1 10.0.0.1 5555 8080\nGET", wantErr: true},
+		{name: "v2 IPv4", input: v2(0x21, 0x11, 198, 51, 100, 1, 10, 0, 0, 1, 0x15, 0xb3, 0x1f, 0x90, 0xaa) + "GET", want: "198.51.100.1:5555", rest: "GET"},
+		{name: "v2 LOCAL", input: v2(0x20, 0x00) + "GET", rest: "GET"},
+		{name: "v2 truncated addresses", input: v2(0x21, 0x11, 198, 51) + "GET", wantErr: true},
+		{name: "v2 bad version", input: v2(0x31, 0x11) + "GET", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := bufio.NewReader(strings.NewReader(tc.input))
+			// When
+			addr, err := readProxyHeader(r)
+			// Then
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %v", addr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(addr); (tc.want == "" && addr != nil) || (tc.want != "" && got != tc.want) {
+				t.Errorf("expected %q, got %v", tc.want, addr)
+			}
+			if rest, _ := r.Peek(len(tc.rest)); string(rest) != tc.rest {
+				t.Errorf("expected %q to remain, got %q", tc.rest, rest)
+			}
+		})
+	}
+}
+
+// --- proxyProtocolListener ---
+
+func TestProxyProtocolListener_UsesHeaderOnlyFromTrustedPeers(t *testing.T) {
+	for _, tc := range []struct {
+		trusted, want string
+	}{{"127.0.0.1", "198.51.100.1:5555"}, {"10.0.0.0/8", "127.0.0.1"}} {
+		// Given: a listener trusting the loopback address or another subnet
+		inner, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l := &proxyProtocolListener{Listener: inner, proxies: newTestProxies(t, proxyConfig{Trusted: []string{tc.trusted}, ProxyProtocol: true})}
+		client, err := net.Dial("tcp", inner.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = client.Write([]byte("PROXY TCP4 198.51.100.1 10.0.0.1 5555 8080\r\n"))
+
+		// When
+		c, err := l.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		addr := c.RemoteAddr().String()
+		_ = client.Close()
+		_ = c.Close()
+		_ = l.Close()
+
+		// Then
+		if !strings.HasPrefix(addr, tc.want) {
+			t.Errorf("trusting %s: expected remote address %s, got %s", tc.trusted, tc.want, addr)
+		}
+	}
+}
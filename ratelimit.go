@@ -0,0 +1,342 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// rateLimitWindow is the length of a rate limit window. Windows are aligned to the wall clock, so
+// every replica reports the same reset time.
+const rateLimitWindow = time.Minute
+
+// maxRateLimitedTenants is the number of tenants whose usage is tracked at once.
+const maxRateLimitedTenants = 10000
+
+// rateLimitBudget is a per-minute request and token budget. Zero means unlimited.
+type rateLimitBudget struct {
+	RequestsPerMinute int `yaml:"requests_per_minute" json:"requests_per_minute"`
+	TokensPerMinute   int `yaml:"tokens_per_minute" json:"tokens_per_minute"`
+}
+
+// tenantBudgetConfig is the budget of the tenant using APIKey, or presenting a client certificate
+// with one of the ClientCerts names (common name or subject alternative name) when mTLS is on.
+type tenantBudgetConfig struct {
+	Name            string   `yaml:"name" json:"name"`
+	APIKey          string   `yaml:"api_key" json:"api_key"`
+	ClientCerts     []string `yaml:"client_certs" json:"client_certs,omitempty"`
+	rateLimitBudget `yaml:",inline"`
+}
+
+// clientIdentity identifies the tenant of a request: its API key, the names of its verified client
+// certificate, and its IP.
+type clientIdentity struct {
+	APIKey    string
+	CertNames []string
+	IP        string
+}
+
+// requestIdentity returns the identity of a request. The IP is that of the client behind trusted
+// proxies (see withClientAddr).
+func requestIdentity(r *http.Request) clientIdentity {
+	id := clientIdentity{APIKey: bearerToken(r), CertNames: clientCertNames(r)}
+	if ip, ok := parseHostIP(r.RemoteAddr); ok {
+		id.IP = ip.String()
+	}
+	return id
+}
+
+// Scopes of the default rate limit budget.
+const (
+	rateLimitScopeAPIKey = "api_key"
+	rateLimitScopeIP     = "ip"
+)
+
+// rateLimitConfig is the rate_limits section of the config file. Default, if set, applies to clients
+// without a tenant entry, counted per API key or, with DefaultScope "ip", per client IP.
+type rateLimitConfig struct {
+	Default      *rateLimitBudget     `yaml:"default" json:"default"`
+	DefaultScope string               `yaml:"default_scope" json:"default_scope,omitempty"`
+	Tenants      []tenantBudgetConfig `yaml:"tenants" json:"tenants"`
+}
+
+// tenantUsage is a tenant's consumption in the current window.
+type tenantUsage struct {
+	window   time.Time
+	requests int
+	tokens   int
+}
+
+// rateLimitDecision is the outcome of charging a request against a tenant's budget.
+type rateLimitDecision struct {
+	// Tenant names the tenant in errors and spans; a tenant identified by its API key is named by the
+	// masked key (see maskAPIKey).
+	Tenant  string
+	Budget  rateLimitBudget
+	Usage   tenantUsage
+	Reset   time.Duration
+	Allowed bool
+	// Exceeded names the exhausted budget ("requests" or "tokens") of a rejected request.
+	Exceeded  string
+	Requested int
+}
+
+// rateLimiter enforces per-tenant budgets over fixed, clock-aligned windows. Usage is kept per
+// instance; see statefulEndpoints. It is safe for concurrent use.
+type rateLimiter struct {
+	mu     sync.Mutex
+	config atomic.Pointer[rateLimitConfig]
+	usage  *boundedMap[string, *tenantUsage]
+	now    func() time.Time
+}
+
+// newRateLimiter creates a limiter for the given config.
+func newRateLimiter(cfg rateLimitConfig) (*rateLimiter, error) {
+	l := &rateLimiter{usage: newBoundedMap[string, *tenantUsage](maxRateLimitedTenants), now: time.Now}
+	if err := l.Load(cfg); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Load validates and replaces the budgets. Usage in the current window is kept. On error the
+// current budgets are kept.
+func (l *rateLimiter) Load(cfg rateLimitConfig) error {
+	return installNow(l.prepare(cfg))
+}
+
+// prepare validates the budgets and returns the function installing them.
+func (l *rateLimiter) prepare(cfg rateLimitConfig) (func(), error) {
+	if cfg.Default != nil {
+		if err := cfg.Default.validate(); err != nil {
+			return nil, fmt.Errorf("rate_limits.default: %w", err)
+		}
+	}
+	switch cfg.DefaultScope {
+	case "", rateLimitScopeAPIKey, rateLimitScopeIP:
+	default:
+		return nil, fmt.Errorf("rate_limits.default_scope must be %q or %q, got %q", rateLimitScopeAPIKey, rateLimitScopeIP, cfg.DefaultScope)
+	}
+	seen, seenCerts := map[string]bool{}, map[string]bool{}
+	for i, t := range cfg.Tenants {
+		if t.APIKey == "" && len(t.ClientCerts) == 0 {
+			return nil, fmt.Errorf("rate_limits.tenants[%d]: api_key or client_certs is required", i)
+		}
+		if t.APIKey != "" && seen[t.APIKey] {
+			return nil, fmt.Errorf("rate_limits.tenants[%d]: duplicate api_key", i)
+		}
+		seen[t.APIKey] = true
+		for _, name := range t.ClientCerts {
+			if seenCerts[name] {
+				return nil, fmt.Errorf("rate_limits.tenants[%d]: duplicate client_certs name %q", i, name)
+			}
+			seenCerts[name] = true
+		}
+		if err := t.validate(); err != nil {
+			return nil, fmt.Errorf("rate_limits.tenants[%d]: %w", i, err)
+		}
+	}
+	return func() {
+		l.config.Store(&cfg)
+	}, nil
+}
+
+// validate rejects negative budgets.
+func (b rateLimitBudget) validate() error {
+	if b.RequestsPerMinute < 0 || b.TokensPerMinute < 0 {
+		return fmt.Errorf("requests_per_minute and tokens_per_minute must not be negative")
+	}
+	return nil
+}
+
+// Active reports whether any budget is configured.
+func (l *rateLimiter) Active() bool {
+	cfg := l.config.Load()
+	return cfg.Default != nil || len(cfg.Tenants) > 0
+}
+
+// budget returns the tenant name and budget for a client, and whether the name is the client's API
+// key. A tenant matching the client certificate takes precedence over one matching the API key. The
+// default budget of the api_key scope counts a client without a key by its certificate or else its IP.
+func (l *rateLimiter) budget(id clientIdentity) (string, bool, rateLimitBudget, bool) {
+	cfg := l.config.Load()
+	for _, t := range cfg.Tenants {
+		for _, name := range t.ClientCerts {
+			if slices.Contains(id.CertNames, name) {
+				tenant, keyed := t.tenantName()
+				return tenant, keyed, t.rateLimitBudget, true
+			}
+		}
+	}
+	for _, t := range cfg.Tenants {
+		if t.APIKey != "" && t.APIKey == id.APIKey {
+			tenant, keyed := t.tenantName()
+			return tenant, keyed, t.rateLimitBudget, true
+		}
+	}
+	if cfg.Default != nil {
+		if cfg.DefaultScope == rateLimitScopeIP {
+			return id.IP, false, *cfg.Default, true
+		}
+		switch {
+		case id.APIKey != "":
+			return id.APIKey, true, *cfg.Default, true
+		case len(id.CertNames) > 0:
+			return id.CertNames[0], false, *cfg.Default, true
+		}
+		// Clients without a key are counted per IP, so they do not share one budget
+		return id.IP, false, *cfg.Default, true
+	}
+	return "", false, rateLimitBudget{}, false
+}
+
+// tenantName returns the name of a tenant, defaulting to its API key or first client certificate
+// name, and whether the name is the API key.
+func (t tenantBudgetConfig) tenantName() (string, bool) {
+	switch {
+	case t.Name != "":
+		return t.Name, false
+	case t.APIKey != "":
+		return t.APIKey, true
+	}
+	return t.ClientCerts[0], false
+}
+
+// maskAPIKey hides an API key but for its prefix and last four characters, like sk-…abcd, so a
+// tenant named by its key can be reported without leaking the key into logs and traces.
+func maskAPIKey(key string) string {
+	prefix := ""
+	if i := strings.IndexByte(key, '-'); i > 0 && i < 8 {
+		prefix = key[:i+1]
+	}
+	if len(key)-len(prefix) < 8 {
+		return prefix + "…"
+	}
+	return prefix + "…" + key[len(key)-4:]
+}
+
+// Charge counts one request of the given estimated tokens against the budget of the client.
+// Rejected requests are not counted. ok is false for clients without a budget.
+func (l *rateLimiter) Charge(id clientIdentity, tokens int) (rateLimitDecision, bool) {
+	tenant, keyed, budget, ok := l.budget(id)
+	if !ok {
+		return rateLimitDecision{}, false
+	}
+	label := tenant
+	if keyed {
+		label = maskAPIKey(tenant)
+	}
+
+	now := l.now()
+	window := now.Truncate(rateLimitWindow)
+	decision := rateLimitDecision{
+		Tenant:    label,
+		Budget:    budget,
+		Reset:     window.Add(rateLimitWindow).Sub(now),
+		Allowed:   true,
+		Requested: tokens,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	usage, ok := l.usage.Get(tenant)
+	if !ok {
+		usage = &tenantUsage{}
+		l.usage.Put(tenant, usage)
+	}
+	if !usage.window.Equal(window) {
+		*usage = tenantUsage{window: window}
+	}
+	switch {
+	case budget.RequestsPerMinute > 0 && usage.requests+1 > budget.RequestsPerMinute:
+		decision.Allowed, decision.Exceeded = false, "requests"
+	case budget.TokensPerMinute > 0 && usage.tokens+tokens > budget.TokensPerMinute:
+		decision.Allowed, decision.Exceeded = false, "tokens"
+	default:
+		usage.requests++
+		usage.tokens += tokens
+	}
+	decision.Usage = *usage
+	return decision, true
+}
+
+// Reset forgets all usage.
+func (l *rateLimiter) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.usage.Clear()
+}
+
+// writeHeaders sets the x-ratelimit-* headers of the real API for the tenant's budget.
+func (d rateLimitDecision) writeHeaders(h http.Header) {
+	reset := d.Reset.Round(time.Millisecond).String()
+	if d.Budget.RequestsPerMinute > 0 {
+		h.Set("x-ratelimit-limit-requests", strconv.Itoa(d.Budget.RequestsPerMinute))
+		h.Set("x-ratelimit-remaining-requests", strconv.Itoa(max(d.Budget.RequestsPerMinute-d.Usage.requests, 0)))
+		h.Set("x-ratelimit-reset-requests", reset)
+	}
+	if d.Budget.TokensPerMinute > 0 {
+		h.Set("x-ratelimit-limit-tokens", strconv.Itoa(d.Budget.TokensPerMinute))
+		h.Set("x-ratelimit-remaining-tokens", strconv.Itoa(max(d.Budget.TokensPerMinute-d.Usage.tokens, 0)))
+		h.Set("x-ratelimit-reset-tokens", reset)
+	}
+}
+
+// error returns the 429 the real API sends when a budget is exhausted.
+func (d rateLimitDecision) error() *APIError {
+	limit, used, unit := d.Budget.RequestsPerMinute, d.Usage.requests, "requests per min (RPM)"
+	requested := 1
+	if d.Exceeded == "tokens" {
+		limit, used, unit = d.Budget.TokensPerMinute, d.Usage.tokens, "tokens per min (TPM)"
+		requested = d.Requested
+	}
+	return &APIError{
+		StatusCode: http.StatusTooManyRequests,
+		Detail: OpenAIErrorDetail{
+			Message: fmt.Sprintf("Rate limit reached for %s on %s: Limit %d, Used %d, Requested %d. Please try again in %s.",
+				d.Tenant, unit, limit, used, requested, d.Reset.Round(time.Millisecond)),
+			Type: d.Exceeded,
+			Code: "rate_limit_exceeded",
+		},
+	}
+}
+
+// bearerToken returns the bearer token of a request, or "" without one, including when Authorization
+// uses another scheme.
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+	return token
+}
+
+// estimateRequestTokens estimates the tokens a request consumes the way the real API does before
+// generating: the prompt tokens (every string value of the body) plus the requested completion limit.
+func estimateRequestTokens(doc any) int {
+	tokens := estimatePromptTokens(doc)
+	if m, ok := doc.(map[string]any); ok {
+		for _, key := range []string{"max_completion_tokens", "max_tokens", "max_output_tokens"} {
+			if n, ok := m[key].(float64); ok && n > 0 {
+				tokens += int(n)
+				break
+			}
+		}
+	}
+	return tokens
+}
+
+// estimatePromptTokens estimates the prompt tokens of a request: the tokens of every string value of
+// the body.
+func estimatePromptTokens(doc any) int {
+	tokens := 0
+	for _, text := range collectStrings(doc, nil) {
+		tokens += countTokens(text)
+	}
+	return tokens
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(t *testing.T, cfg rateLimitConfig, now *time.Time) *rateLimiter {
+	t.Helper()
+	l, err := newRateLimiter(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+// --- rateLimiter.Load ---
+
+func TestRateLimiter_Load_RejectsInvalidBudgets(t *testing.T) {
+	// Given
+	cases := []rateLimitConfig{
+		{Default: &rateLimitBudget{RequestsPerMinute: -1}},
+		{Tenants: []tenantBudgetConfig{{Name: "a"}}},
+		{Tenants: []tenantBudgetConfig{{APIKey: "k"}, {APIKey: "k"}}},
+		{Tenants: []tenantBudgetConfig{{ClientCerts: []string{"a"}}, {APIKey: "k", ClientCerts: []string{"a"}}}},
+		{Default: &rateLimitBudget{RequestsPerMinute: 1}, DefaultScope: "tenant"},
+	}
+	for _, cfg := range cases {
+		// When
+		_, err := newRateLimiter(cfg)
+		// Then
+		if err == nil {
+			t.Errorf("expected an error for %+v", cfg)
+		}
+	}
+}
+
+// --- rateLimiter.Charge ---
+
+func TestRateLimiter_Charge_UnknownKeyWithoutDefault(t *testing.T) {
+	// Given
+	now := time.Now()
+	l := newTestRateLimiter(t, rateLimitConfig{Tenants: []tenantBudgetConfig{{APIKey: "k", rateLimitBudget: rateLimitBudget{RequestsPerMinute: 1}}}}, &now)
+	// When
+	_, ok := l.Charge(clientIdentity{APIKey: "other"}, 10)
+	// Then
+	if ok {
+		t.Error("expected keys without a budget to be unlimited")
+	}
+}
+
+func TestRateLimiter_Charge_ResetsAtWindowBoundary(t *testing.T) {
+	// Given: a budget of one request, used 15s into a minute
+	now := time.Date(2026, 1, 1, 12, 0, 15, 0, time.UTC)
+	l := newTestRateLimiter(t, rateLimitConfig{Default: &rateLimitBudget{RequestsPerMinute: 1}}, &now)
+	first, _ := l.Charge(clientIdentity{APIKey: "k"}, 0)
+
+	// When: a second request arrives in the same minute and a third in the next
+	now = now.Add(30 * time.Second)
+	second, _ := l.Charge(clientIdentity{APIKey: "k"}, 0)
+	now = now.Add(15 * time.Second)
+	third, _ := l.Charge(clientIdentity{APIKey: "k"}, 0)
+
+	// Then: the reset is the time to the end of the minute
+	if !first.Allowed || first.Reset != 45*time.Second {
+		t.Errorf("expected the first request allowed with a 45s reset, got %+v", first)
+	}
+	if second.Allowed || second.Exceeded != "requests" || second.Reset != 15*time.Second {
+		t.Errorf("expected the second request rejected with a 15s reset, got %+v", second)
+	}
+	if !third.Allowed {
+		t.Errorf("expected the budget to reset in the next minute, got %+v", third)
+	}
+}
+
+func TestRateLimiter_Charge_RejectedRequestsAreNotCounted(t *testing.T) {
+	// Given: a 100 token budget
+	now := time.Now()
+	l := newTestRateLimiter(t, rateLimitConfig{Default: &rateLimitBudget{TokensPerMinute: 100}}, &now)
+
+	// When: a request too large for the budget is followed by one that fits
+	large, _ := l.Charge(clientIdentity{APIKey: "k"}, 150)
+	small, _ := l.Charge(clientIdentity{APIKey: "k"}, 60)
+
+	// Then
+	if large.Allowed || large.Exceeded != "tokens" {
+		t.Errorf("expected the large request rejected, got %+v", large)
+	}
+	if !small.Allowed || small.Usage.tokens != 60 {
+		t.Errorf("expected the small request counted alone, got %+v", small)
+	}
+}
+
+// --- rateLimitDecision.writeHeaders ---
+
+func TestRateLimitDecision_WriteHeaders(t *testing.T) {
+	// Given
+	d := rateLimitDecision{
+		Budget: rateLimitBudget{RequestsPerMinute: 60, TokensPerMinute: 1000},
+		Usage:  tenantUsage{requests: 3, tokens: 250},
+		Reset:  1500 * time.Millisecond,
+	}
+	h := http.Header{}
+	// When
+	d.writeHeaders(h)
+	// Then
+	want := map[string]string{
+		"x-ratelimit-limit-requests":     "60",
+		"x-ratelimit-remaining-requests": "57",
+		"x-ratelimit-reset-requests":     "1.5s",
+		"x-ratelimit-limit-tokens":       "1000",
+		"x-ratelimit-remaining-tokens":   "750",
+		"x-ratelimit-reset-tokens":       "1.5s",
+	}
+	for name, value := range want {
+		if got := h.Get(name); got != value {
+			t.Errorf("%s: expected %q, got %q", name, value, got)
+		}
+	}
+}
+
+// --- estimateRequestTokens ---
+
+func TestEstimateRequestTokens_AddsCompletionLimit(t *testing.T) {
+	// Given
+	doc := map[string]any{"model": "gpt", "max_tokens": float64(10), "messages": []any{map[string]any{"content": "hello world"}}}
+	// When
+	tokens := estimateRequestTokens(doc)
+	// Then
+	if want := countTokens("gpt") + countTokens("hello world") + 10; tokens != want {
+		t.Errorf("expected %d, got %d", want, tokens)
+	}
+}
+
+func TestRateLimiter_Charge_ClientCertificateTakesPrecedence(t *testing.T) {
+	// Given: one tenant identified by API key and one by client certificate
+	now := time.Now()
+	l := newTestRateLimiter(t, rateLimitConfig{Tenants: []tenantBudgetConfig{
+		{Name: "by-key", APIKey: "k", rateLimitBudget: rateLimitBudget{RequestsPerMinute: 1}},
+		{ClientCerts: []string{"billing.svc"}, rateLimitBudget: rateLimitBudget{RequestsPerMinute: 2}},
+	}}, &now)
+
+	// When: a client presents both
+	decision, ok := l.Charge(clientIdentity{APIKey: "k", CertNames: []string{"billing", "billing.svc"}}, 0)
+
+	// Then: the certificate's tenant, named after its certificate name, is charged
+	if !ok || decision.Tenant != "billing.svc" || decision.Budget.RequestsPerMinute != 2 {
+		t.Errorf("expected the certificate tenant, got %+v", decision)
+	}
+}
+
+func TestRateLimiter_Charge_DefaultScopeIPCountsPerClientIP(t *testing.T) {
+	// Given: a default budget of one request per client IP and a keyed tenant
+	now := time.Now()
+	l := newTestRateLimiter(t, rateLimitConfig{
+		Default:      &rateLimitBudget{RequestsPerMinute: 1},
+		DefaultScope: rateLimitScopeIP,
+		Tenants:      []tenantBudgetConfig{{APIKey: "k", rateLimitBudget: rateLimitBudget{RequestsPerMinute: 5}}},
+	}, &now)
+
+	// When: one IP sends two requests with different keys and another IP sends one
+	first, _ := l.Charge(clientIdentity{APIKey: "a", IP: "198.51.100.1"}, 0)
+	second, _ := l.Charge(clientIdentity{APIKey: "b", IP: "198.51.100.1"}, 0)
+	other, _ := l.Charge(clientIdentity{APIKey: "a", IP: "198.51.100.2"}, 0)
+	tenant, _ := l.Charge(clientIdentity{APIKey: "k", IP: "198.51.100.1"}, 0)
+
+	// Then: the IP's budget is shared across keys, while tenants keep their own budget
+	if !first.Allowed || second.Allowed || !other.Allowed || second.Tenant != "198.51.100.1" {
+		t.Errorf("expected one request per IP, got %+v, %+v, %+v", first, second, other)
+	}
+	if !tenant.Allowed || tenant.Budget.RequestsPerMinute != 5 {
+		t.Errorf("expected the tenant budget for its key, got %+v", tenant)
+	}
+}
+
+func TestRateLimiter_Charge_MasksTenantsNamedByAPIKey(t *testing.T) {
+	// Given: a named tenant, a tenant known by its key only, and a default budget per key
+	now := time.Now()
+	l := newTestRateLimiter(t, rateLimitConfig{
+		Tenants: []tenantBudgetConfig{
+			{Name: "billing", APIKey: "sk-billing-0001", rateLimitBudget: rateLimitBudget{RequestsPerMinute: 1}},
+			{APIKey: "sk-proj-secret-abcd", rateLimitBudget: rateLimitBudget{RequestsPerMinute: 1}},
+		},
+		Default: &rateLimitBudget{RequestsPerMinute: 1},
+	}, &now)
+
+	// When
+	named, _ := l.Charge(clientIdentity{APIKey: "sk-billing-0001"}, 0)
+	keyed, _ := l.Charge(clientIdentity{APIKey: "sk-proj-secret-abcd"}, 0)
+	_, _ = l.Charge(clientIdentity{APIKey: "sk-default-key-wxyz"}, 0)
+	rejected, _ := l.Charge(clientIdentity{APIKey: "sk-default-key-wxyz"}, 0)
+
+	// Then: names are kept, keys are masked, also in the 429 message
+	if named.Tenant != "billing" || keyed.Tenant != "sk-…abcd" || rejected.Tenant != "sk-…wxyz" {
+		t.Errorf("expected billing, sk-…abcd, and sk-…wxyz, got %q %q %q", named.Tenant, keyed.Tenant, rejected.Tenant)
+	}
+	if msg := rejected.error().Detail.Message; strings.Contains(msg, "sk-default-key-wxyz") || !strings.Contains(msg, "sk-…wxyz") {
+		t.Errorf("expected the masked key in the error, got %q", msg)
+	}
+}
+
+func TestRateLimiter_Charge_ClientsWithoutKeyAreCountedPerIP(t *testing.T) {
+	// Given: a default budget per API key, and two clients without a key
+	now := time.Now()
+	l := newTestRateLimiter(t, rateLimitConfig{Default: &rateLimitBudget{RequestsPerMinute: 1}}, &now)
+
+	// When
+	first, _ := l.Charge(clientIdentity{IP: "10.0.0.1"}, 0)
+	other, _ := l.Charge(clientIdentity{IP: "10.0.0.2"}, 0)
+	again, _ := l.Charge(clientIdentity{IP: "10.0.0.1"}, 0)
+
+	// Then: each client has its own budget
+	if !first.Allowed || !other.Allowed || again.Allowed {
+		t.Errorf("expected each IP to get one request, got %t %t %t", first.Allowed, other.Allowed, again.Allowed)
+	}
+	if again.Tenant != "10.0.0.1" {
+		t.Errorf("expected the tenant named by its IP, got %q", again.Tenant)
+	}
+}
+
+// --- bearerToken ---
+
+func TestBearerToken(t *testing.T) {
+	// Given/When/Then: only the Bearer scheme carries an API key
+	cases := map[string]string{
+		"Bearer sk-test":     "sk-test",
+		"Basic dXNlcjpwYXNz": "",
+		"sk-test":            "",
+		"":                   "",
+	}
+	for header, want := range cases {
+		r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		if got := bearerToken(r); got != want {
+			t.Errorf("%q: expected %q, got %q", header, want, got)
+		}
+	}
+}
+
+// --- maskAPIKey ---
+
+func TestMaskAPIKey(t *testing.T) {
+	// Given/When/Then: the prefix and last four characters are kept, short keys are hidden entirely
+	cases := map[string]string{
+		"sk-proj-abcdefgh1234": "sk-…1234",
+		"mykeywithoutprefix":   "…efix",
+		"sk-short":             "sk-…",
+		"":                     "…",
+	}
+	for key, want := range cases {
+		if got := maskAPIKey(key); got != want {
+			t.Errorf("maskAPIKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"openai-mokku/api"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// maxStoredStreamMismatches is the number of stream reconciliation mismatches kept for inspection.
+const maxStoredStreamMismatches = 100
+
+// streamMismatch records a streamed response that, reassembled, differs from the non-streaming
+// response to the same request.
+type streamMismatch struct {
+	ID    string `json:"id"`
+	Path  string `json:"path"`
+	Model string `json:"model"`
+	// Differences describes the differing fields, one line each, from the non-streaming response to
+	// the reassembled stream.
+	Differences []string  `json:"differences"`
+	CheckedAt   time.Time `json:"checked_at"`
+}
+
+// reconciledCompletion is the part of a completion the streaming and non-streaming paths must agree
+// on. IDs, timestamps, and tool call IDs are generated per response and left out.
+type reconciledCompletion struct {
+	Model   string             `json:"model"`
+	Choices []reconciledChoice `json:"choices"`
+}
+
+// reconciledChoice is a choice of a reconciledCompletion, with the content of a chat message or
+// the text of a legacy completion.
+type reconciledChoice struct {
+	Index        int                  `json:"index"`
+	Content      string               `json:"content"`
+	ToolCalls    []reconciledToolCall `json:"tool_calls,omitempty"`
+	Annotations  []urlCitation        `json:"annotations,omitempty"`
+	FinishReason string               `json:"finish_reason"`
+}
+
+// reconciledToolCall is a tool call of a reconciledChoice.
+type reconciledToolCall struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// reconcileChatStream checks a chat completion stream sent in full, with the stream_reconciliation
+// flag, against the non-streaming response the handlers produce for the same request and context.
+func (h *StreamingHandler) reconcileChatStream(ctx context.Context, stream *sseWriter, id, path string, req *api.CreateChatCompletionRequest) {
+	if !stream.record || stream.err != nil {
+		return
+	}
+	plain := *req
+	plain.Stream = api.OptBool{}
+	resp, err := h.mock.CreateChatCompletion(ctx, &plain)
+	var want reconciledCompletion
+	if err == nil {
+		want = reconcileChatResponse(resp)
+	}
+	h.reconcileStream(ctx, stream, id, path, req.Model, want, err, reassembleChatStream(stream.events))
+}
+
+// reconcileCompletionStream checks a legacy completion stream like reconcileChatStream.
+func (h *StreamingHandler) reconcileCompletionStream(ctx context.Context, stream *sseWriter, id, path string, req *api.CreateCompletionRequest) {
+	if !stream.record || stream.err != nil {
+		return
+	}
+	plain := *req
+	plain.Stream = api.OptBool{}
+	resp, err := h.mock.CreateCompletion(ctx, &plain)
+	var want reconciledCompletion
+	if err == nil {
+		want = reconcileCompletionResponse(resp)
+	}
+	h.reconcileStream(ctx, stream, id, path, req.Model, want, err, reassembleCompletionStream(stream.events))
+}
+
+// reconcileStream compares the reassembled stream got with the non-streaming response want, or
+// the error the non-streaming path failed with, and records the outcome in the stream log. A
+// mismatch is logged, so it fails loudly in a test run's output.
+func (h *StreamingHandler) reconcileStream(ctx context.Context, stream *sseWriter, id, path, model string, want reconciledCompletion, wantErr error, got reconciledCompletion) {
+	_, span := tracer.Start(ctx, "StreamReconciliation")
+	defer span.End()
+
+	var differences []string
+	if wantErr != nil {
+		differences = []string{"the non-streaming path failed: " + wantErr.Error()}
+	} else {
+		diffJSON("", reconciledJSON(want), reconciledJSON(got), &differences)
+	}
+	span.SetAttributes(attribute.Int("stream.events", len(stream.events)), attribute.Bool("stream.reconciled", len(differences) == 0))
+	if len(differences) == 0 {
+		h.streams.Reconciled(nil)
+		return
+	}
+	mismatch := streamMismatch{ID: id, Path: path, Model: model, Differences: differences, CheckedAt: time.Now()}
+	h.streams.Reconciled(&mismatch)
+	span.SetAttributes(attribute.StringSlice("stream.differences", differences))
+	log.Printf("Stream reconciliation mismatch: %s %s: %s", path, id, strings.Join(differences, "; "))
+}
+
+// reconciledJSON decodes c as diffJSON compares it.
+func reconciledJSON(c reconciledCompletion) any {
+	var v any
+	data, _ := json.Marshal(c)
+	_ = json.Unmarshal(data, &v)
+	return v
+}
+
+// reassembleChatStream joins the chunks of a chat completion stream into the choices they make up:
+// content deltas are concatenated, and tool call fragments appended to the call at their index.
+func reassembleChatStream(events []any) reconciledCompletion {
+	var c reconciledCompletion
+	for _, event := range events {
+		chunk, ok := event.(ChatCompletionChunk)
+		if !ok {
+			continue
+		}
+		c.Model = chunk.Model
+		for _, delta := range chunk.Choices {
+			choice := c.choice(delta.Index)
+			choice.Content += delta.Delta.Content
+			for _, call := range delta.Delta.ToolCalls {
+				for len(choice.ToolCalls) <= call.Index {
+					choice.ToolCalls = append(choice.ToolCalls, reconciledToolCall{})
+				}
+				tc := &choice.ToolCalls[call.Index]
+				if call.Type != "" {
+					tc.Type = call.Type
+				}
+				tc.Name += call.Function.Name
+				tc.Arguments += call.Function.Arguments
+			}
+			for _, a := range delta.Delta.Annotations {
+				choice.Annotations = append(choice.Annotations, a.URLCitation)
+			}
+			if delta.FinishReason != nil {
+				choice.FinishReason = *delta.FinishReason
+			}
+		}
+	}
+	return c
+}
+
+// reassembleCompletionStream joins the chunks of a legacy completion stream into the choices they
+// make up, the echoed prompt included.
+func reassembleCompletionStream(events []any) reconciledCompletion {
+	var c reconciledCompletion
+	for _, event := range events {
+		chunk, ok := event.(CompletionChunk)
+		if !ok {
+			continue
+		}
+		c.Model = chunk.Model
+		for _, delta := range chunk.Choices {
+			choice := c.choice(delta.Index)
+			choice.Content += delta.Text
+			if delta.FinishReason != nil {
+				choice.FinishReason = *delta.FinishReason
+			}
+		}
+	}
+	return c
+}
+
+// choice returns the choice at index, adding it when the stream has not sent it before.
+func (c *reconciledCompletion) choice(index int) *reconciledChoice {
+	i := slices.IndexFunc(c.Choices, func(choice reconciledChoice) bool { return choice.Index == index })
+	if i < 0 {
+		c.Choices = append(c.Choices, reconciledChoice{Index: index})
+		i = len(c.Choices) - 1
+	}
+	return &c.Choices[i]
+}
+
+// reconcileChatResponse extracts the reconciled part of a non-streaming chat completion.
+func reconcileChatResponse(resp *api.CreateChatCompletionResponse) reconciledCompletion {
+	c := reconciledCompletion{Model: resp.Model}
+	for _, choice := range resp.Choices {
+		rc := reconciledChoice{Index: choice.Index, Content: choice.Message.Content.Value, FinishReason: string(choice.FinishReason)}
+		for _, call := range choice.Message.ToolCalls {
+			rc.ToolCalls = append(rc.ToolCalls, reconciledToolCall{Type: string(call.Type), Name: call.Function.Name, Arguments: call.Function.Arguments})
+		}
+		for _, a := range choice.Message.Annotations {
+			u := a.URLCitation
+			rc.Annotations = append(rc.Annotations, urlCitation{StartIndex: u.StartIndex, EndIndex: u.EndIndex, URL: u.URL, Title: u.Title})
+		}
+		c.Choices = append(c.Choices, rc)
+	}
+	return c
+}
+
+// reconcileCompletionResponse extracts the reconciled part of a non-streaming legacy completion.
+func reconcileCompletionResponse(resp *api.CreateCompletionResponse) reconciledCompletion {
+	c := reconciledCompletion{Model: resp.Model}
+	for _, choice := range resp.Choices {
+		c.Choices = append(c.Choices, reconciledChoice{Index: choice.Index, Content: choice.Text, FinishReason: string(choice.FinishReason)})
+	}
+	return c
+}
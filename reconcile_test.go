@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"openai-mokku/api"
+)
+
+// --- reassembleChatStream ---
+
+func TestReassembleChatStream_JoinsContentToolCallsAndFinishReason(t *testing.T) {
+	// Given: a role chunk, two content deltas, a tool call in two fragments, and a final chunk
+	stop := "tool_calls"
+	chunk := func(delta ChatCompletionChunkDelta, finish *string) ChatCompletionChunk {
+		return ChatCompletionChunk{Model: "gpt-4o", Choices: []ChatCompletionChunkChoice{{Delta: delta, FinishReason: finish}}}
+	}
+	events := []any{
+		chunk(ChatCompletionChunkDelta{Role: "assistant"}, nil),
+		chunk(ChatCompletionChunkDelta{Content: "Hello,"}, nil),
+		chunk(ChatCompletionChunkDelta{Content: " world"}, nil),
+		chunk(ChatCompletionChunkDelta{ToolCalls: []ChatCompletionChunkToolCall{{ID: "call_1", Type: "function", Function: ChatCompletionChunkToolCallFunction{Name: "get_weather"}}}}, nil),
+		chunk(ChatCompletionChunkDelta{ToolCalls: []ChatCompletionChunkToolCall{{Function: ChatCompletionChunkToolCallFunction{Arguments: `{"city":"Tokyo"}`}}}}, nil),
+		chunk(ChatCompletionChunkDelta{}, &stop),
+	}
+
+	// When
+	got := reassembleChatStream(events)
+
+	// Then: it equals the non-streaming response, whose tool call ID differs
+	want := reconcileChatResponse(&api.CreateChatCompletionResponse{
+		Model: "gpt-4o",
+		Choices: []api.ChatCompletionChoice{{
+			Message: api.ChatCompletionResponseMessage{
+				Content: api.NewNilString("Hello, world"),
+				ToolCalls: []api.ChatCompletionMessageToolCall{{
+					ID: "call_2", Type: api.ChatCompletionMessageToolCallTypeFunction,
+					Function: api.ChatCompletionMessageToolCallFunction{Name: "get_weather", Arguments: `{"city":"Tokyo"}`},
+				}},
+			},
+			FinishReason: api.ChatCompletionChoiceFinishReasonToolCalls,
+		}},
+	})
+	var differences []string
+	diffJSON("", reconciledJSON(want), reconciledJSON(got), &differences)
+	if len(differences) != 0 {
+		t.Errorf("expected no differences, got %v", differences)
+	}
+}
+
+// --- reconcileStream ---
+
+func TestReconcileStream_RecordsMismatchesAndFailures(t *testing.T) {
+	// Given
+	state, _ := newServerState(Config{}, serverOptions{})
+	h := NewStreamingHandler(http.NotFoundHandler(), &MockHandler{}, http.NotFoundHandler(), state)
+	stream := &sseWriter{record: true}
+	want := reconciledCompletion{Model: "gpt-4o", Choices: []reconciledChoice{{Content: "Echo: hi", FinishReason: "stop"}}}
+	got := reconciledCompletion{Model: "gpt-4o", Choices: []reconciledChoice{{Content: "Echo: hi", FinishReason: "length"}}}
+
+	// When: a matching stream, a differing one, and one the non-streaming path fails for are checked
+	h.reconcileStream(context.Background(), stream, "a", "/v1/chat/completions", "gpt-4o", want, nil, want)
+	h.reconcileStream(context.Background(), stream, "b", "/v1/chat/completions", "gpt-4o", want, nil, got)
+	h.reconcileStream(context.Background(), stream, "c", "/v1/completions", "gpt-4o", reconciledCompletion{}, errors.New("boom"), got)
+
+	// Then
+	stats := state.streams.Stats()
+	if stats.Reconciled != 3 || stats.Mismatched != 2 || len(stats.Mismatches) != 2 {
+		t.Fatalf("expected 3 reconciled and 2 mismatched, got %+v", stats)
+	}
+	if m := stats.Mismatches[0]; m.ID != "b" || strings.Join(m.Differences, ";") != `choices[0].finish_reason: "stop" -> "length"` {
+		t.Errorf("unexpected mismatch %+v", m)
+	}
+	if m := stats.Mismatches[1]; m.ID != "c" || !strings.Contains(m.Differences[0], "boom") {
+		t.Errorf("unexpected failure %+v", m)
+	}
+}
+
+func TestStreamingHandler_ReconcilesStreamsWithTheFlagOnly(t *testing.T) {
+	// Given: the same streamed chat and legacy completions, without and with the flag
+	for _, features := range []string{"", flagStreamReconciliation} {
+		state, _ := newServerState(Config{}, serverOptions{Features: features})
+		handler, _, _ := state.newHandler("test-instance")
+		for path, body := range map[string]string{
+			"/v1/chat/completions": `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`,
+			"/v1/completions":      `{"model":"gpt-3.5-turbo-instruct","stream":true,"echo":true,"n":2,"prompt":"Say hi"}`,
+		} {
+			// When
+			req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		// Then: both streams are reconciled, and match, only with the flag
+		stats := state.streams.Stats()
+		want := 0
+		if features != "" {
+			want = 2
+		}
+		if stats.Completed != 2 || stats.Reconciled != want || stats.Mismatched != 0 {
+			t.Errorf("features %q: expected 2 completed and %d reconciled streams without mismatches, got %+v", features, want, stats)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// regionHeader is the request header naming the region a request is sent to. Clients routing between
+// regional endpoints set it per endpoint (or their endpoints' proxies do).
+const regionHeader = "X-Mokku-Region"
+
+// maxRegions is the number of regions that can be configured, including those added at runtime.
+const maxRegions = 100
+
+// Region sources.
+const (
+	regionSourceConfig = "config"
+	regionSourceAdmin  = "admin"
+)
+
+// regionConfig is the health of a region in the regions section of the config file or in
+// PUT /_mokku/regions/{name}. A region without status, error rate, and latency is healthy.
+type regionConfig struct {
+	Name string `yaml:"name" json:"name"`
+	// Status is the error status of failed requests; 503 when only ErrorRate is set.
+	Status int `yaml:"status" json:"status"`
+	// ErrorRate is the fraction (0-1) of requests that fail; 1 when only Status is set.
+	ErrorRate float64 `yaml:"error_rate" json:"error_rate"`
+	// Latency delays every request to the region, e.g. "2s".
+	Latency string `yaml:"latency" json:"latency"`
+}
+
+// regionStatus is the view of a region returned by GET /_mokku/regions.
+type regionStatus struct {
+	regionConfig
+	Healthy  bool   `json:"healthy"`
+	Source   string `json:"source"`
+	Requests int64  `json:"requests"`
+	Failures int64  `json:"failures"`
+}
+
+// regionState is the compiled health of a region and its counters.
+type regionState struct {
+	cfg      regionConfig
+	err      *APIError
+	latency  time.Duration
+	source   string
+	requests int64
+	failures int64
+}
+
+// regionRouter simulates per-region outages for requests carrying regionHeader, so client failover
+// between regions can be tested against one instance. Requests without the header or for unknown
+// regions are served normally. Failures follow the error rate deterministically: with a rate of
+// 0.25, every fourth request fails. It is safe for concurrent use.
+type regionRouter struct {
+	mu      sync.Mutex
+	regions map[string]*regionState
+	// timeline records the outages, nil until Observe is called.
+	timeline *faultTimeline
+}
+
+// newRegionRouter creates a router for the configured regions.
+func newRegionRouter(cfgs []regionConfig) (*regionRouter, error) {
+	rr := &regionRouter{regions: map[string]*regionState{}}
+	if err := rr.Load(cfgs); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// Load replaces all regions, including those set at runtime, with the configured ones. On error the
+// current regions are kept.
+func (rr *regionRouter) Load(cfgs []regionConfig) error {
+	return installNow(rr.prepare(cfgs))
+}
+
+// prepare validates the regions and returns the function installing them.
+func (rr *regionRouter) prepare(cfgs []regionConfig) (func(), error) {
+	if len(cfgs) > maxRegions {
+		return nil, fmt.Errorf("regions: at most %d regions can be configured", maxRegions)
+	}
+	regions := make(map[string]*regionState, len(cfgs))
+	for i, cfg := range cfgs {
+		state, err := compileRegion(cfg, regionSourceConfig)
+		if err != nil {
+			return nil, fmt.Errorf("regions[%d]: %w", i, err)
+		}
+		if _, ok := regions[state.cfg.Name]; ok {
+			return nil, fmt.Errorf("regions[%d]: duplicate name %q", i, cfg.Name)
+		}
+		regions[state.cfg.Name] = state
+	}
+	return func() {
+		rr.mu.Lock()
+		defer rr.mu.Unlock()
+		rr.regions = regions
+		rr.timeline.finishAll(faultKindRegion)
+		rr.recordAll()
+	}, nil
+}
+
+// Set replaces the health of a region at runtime, adding it if needed. Its counters are kept.
+func (rr *regionRouter) Set(cfg regionConfig) (regionStatus, error) {
+	state, err := compileRegion(cfg, regionSourceAdmin)
+	if err != nil {
+		return regionStatus{}, err
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if old, ok := rr.regions[state.cfg.Name]; ok {
+		state.requests, state.failures = old.requests, old.failures
+	} else if len(rr.regions) >= maxRegions {
+		return regionStatus{}, fmt.Errorf("at most %d regions can be configured", maxRegions)
+	}
+	rr.regions[state.cfg.Name] = state
+	rr.record(state)
+	return state.status(), nil
+}
+
+// Observe records the outages in timeline from now on, starting with the regions currently down.
+func (rr *regionRouter) Observe(timeline *faultTimeline) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.timeline = timeline
+	rr.recordAll()
+}
+
+// recordAll starts the outage periods of every unhealthy region, in name order. The caller holds
+// rr.mu.
+func (rr *regionRouter) recordAll() {
+	names := make([]string, 0, len(rr.regions))
+	for name := range rr.regions {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		rr.record(rr.regions[name])
+	}
+}
+
+// record starts the outage period of an unhealthy region in the timeline, or ends the current one
+// when the region is healthy. The caller holds rr.mu.
+func (rr *regionRouter) record(state *regionState) {
+	if state.status().Healthy {
+		rr.timeline.finish(faultKindRegion, state.cfg.Name)
+		return
+	}
+	cfg := state.cfg
+	rr.timeline.begin(faultPeriod{Kind: faultKindRegion, Name: cfg.Name, Source: state.source, Region: &cfg})
+}
+
+// compileRegion validates a region and normalizes its name to lower case.
+func compileRegion(cfg regionConfig, source string) (*regionState, error) {
+	cfg.Name = strings.ToLower(strings.TrimSpace(cfg.Name))
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if cfg.ErrorRate < 0 || cfg.ErrorRate > 1 {
+		return nil, fmt.Errorf("error_rate must be between 0 and 1, got %v", cfg.ErrorRate)
+	}
+	if cfg.Status != 0 && (cfg.Status < 400 || cfg.Status > 599) {
+		return nil, fmt.Errorf("status must be an HTTP error status (400-599), got %d", cfg.Status)
+	}
+	switch {
+	case cfg.Status != 0 && cfg.ErrorRate == 0:
+		cfg.ErrorRate = 1
+	case cfg.Status == 0 && cfg.ErrorRate > 0:
+		cfg.Status = http.StatusServiceUnavailable
+	}
+	state := &regionState{cfg: cfg, source: source}
+	if cfg.Latency != "" {
+		latency, err := time.ParseDuration(cfg.Latency)
+		if err != nil || latency < 0 {
+			return nil, fmt.Errorf("latency must be a duration such as 500ms, got %q", cfg.Latency)
+		}
+		state.latency = latency
+	}
+	if cfg.Status != 0 {
+		state.err = scenarioError(cfg.Status, nil)
+	}
+	return state, nil
+}
+
+// Route counts a request to the region named by r's regionHeader and returns the region's latency
+// and, for a failed request, its error. ok is false for requests outside any configured region.
+func (rr *regionRouter) Route(r *http.Request) (name string, latency time.Duration, err *APIError, ok bool) {
+	name = strings.ToLower(strings.TrimSpace(r.Header.Get(regionHeader)))
+	if name == "" {
+		return "", 0, nil, false
+	}
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	state, ok := rr.regions[name]
+	if !ok {
+		return name, 0, nil, false
+	}
+	state.requests++
+	// Fail when the expected failure count crosses the next integer, e.g. requests 4, 8, ... at 0.25
+	failed := state.err != nil && int64(float64(state.requests)*state.cfg.ErrorRate+1e-9) > state.failures
+	rr.timeline.count(faultKindRegion, name, func(s *chaosStats) {
+		s.Requests++
+		if failed {
+			s.Errors++
+		}
+	})
+	if failed {
+		state.failures++
+		return name, state.latency, state.err, true
+	}
+	return name, state.latency, nil, true
+}
+
+// Status returns every region sorted by name.
+func (rr *regionRouter) Status() []regionStatus {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	statuses := make([]regionStatus, 0, len(rr.regions))
+	for _, state := range rr.regions {
+		statuses = append(statuses, state.status())
+	}
+	slices.SortFunc(statuses, func(a, b regionStatus) int { return strings.Compare(a.Name, b.Name) })
+	return statuses
+}
+
+// status returns the view of a region. The caller holds the router's lock.
+func (s *regionState) status() regionStatus {
+	return regionStatus{
+		regionConfig: s.cfg,
+		Healthy:      s.err == nil && s.latency == 0,
+		Source:       s.source,
+		Requests:     s.requests,
+		Failures:     s.failures,
+	}
+}
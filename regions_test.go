@@ -0,0 +1,182 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// regionRequest builds a request sent to the given region.
+func regionRequest(region string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	if region != "" {
+		r.Header.Set(regionHeader, region)
+	}
+	return r
+}
+
+// --- newRegionRouter ---
+
+func TestNewRegionRouter_RejectsInvalidRegions(t *testing.T) {
+	cases := map[string][]regionConfig{
+		"missing name":   {{Status: 503}},
+		"duplicate name": {{Name: "eu"}, {Name: "EU"}},
+		"bad status":     {{Name: "eu", Status: 200}},
+		"bad error rate": {{Name: "eu", ErrorRate: 1.5}},
+		"bad latency":    {{Name: "eu", Latency: "soon"}},
+	}
+	for name, cfgs := range cases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			_, err := newRegionRouter(cfgs)
+			// Then
+			if err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+// --- regionRouter.Route ---
+
+func TestRegionRouter_Route_FullOutage(t *testing.T) {
+	// Given: eu is down with 503, us is healthy
+	rr, err := newRegionRouter([]regionConfig{{Name: "eu", Status: 503}, {Name: "us"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// When / Then: every eu request fails, matching the header case-insensitively
+	for range 3 {
+		name, _, apiErr, ok := rr.Route(regionRequest("EU"))
+		if !ok || name != "eu" || apiErr == nil || apiErr.StatusCode != 503 || apiErr.Detail.Type != "server_error" {
+			t.Fatalf("expected a 503 from eu, got %q %+v %t", name, apiErr, ok)
+		}
+	}
+	if _, _, apiErr, ok := rr.Route(regionRequest("us")); !ok || apiErr != nil {
+		t.Errorf("expected us to be healthy, got %+v %t", apiErr, ok)
+	}
+}
+
+func TestRegionRouter_Route_UnknownOrMissingRegionIsIgnored(t *testing.T) {
+	// Given
+	rr, _ := newRegionRouter([]regionConfig{{Name: "eu", Status: 503}})
+	for _, region := range []string{"", "ap"} {
+		// When
+		_, _, apiErr, ok := rr.Route(regionRequest(region))
+		// Then
+		if ok || apiErr != nil {
+			t.Errorf("region %q: expected no routing, got %+v %t", region, apiErr, ok)
+		}
+	}
+}
+
+func TestRegionRouter_Route_PartialOutageIsDeterministic(t *testing.T) {
+	// Given: a quarter of eu requests fail, with the default 503
+	rr, _ := newRegionRouter([]regionConfig{{Name: "eu", ErrorRate: 0.25, Latency: "5ms"}})
+	// When: eight requests are routed
+	var failed []int
+	for i := 1; i <= 8; i++ {
+		_, latency, apiErr, _ := rr.Route(regionRequest("eu"))
+		if latency != 5*time.Millisecond {
+			t.Errorf("expected 5ms latency, got %s", latency)
+		}
+		if apiErr != nil {
+			if apiErr.StatusCode != http.StatusServiceUnavailable {
+				t.Errorf("expected 503, got %d", apiErr.StatusCode)
+			}
+			failed = append(failed, i)
+		}
+	}
+	// Then: requests 4 and 8 failed and the counters agree
+	if len(failed) != 2 || failed[0] != 4 || failed[1] != 8 {
+		t.Errorf("expected requests [4 8] to fail, got %v", failed)
+	}
+	status := rr.Status()[0]
+	if status.Requests != 8 || status.Failures != 2 || status.Healthy || status.Status != 503 {
+		t.Errorf("unexpected status %+v", status)
+	}
+}
+
+// --- regionRouter.Set / Load ---
+
+func TestRegionRouter_Set_AddsAndKeepsCounters(t *testing.T) {
+	// Given: a healthy eu region that served a request
+	rr, _ := newRegionRouter([]regionConfig{{Name: "eu"}})
+	rr.Route(regionRequest("eu"))
+	// When: eu is taken down and us is added at runtime
+	status, err := rr.Set(regionConfig{Name: "eu", Status: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rr.Set(regionConfig{Name: "us"}); err != nil {
+		t.Fatal(err)
+	}
+	// Then
+	if status.Healthy || status.Source != regionSourceAdmin || status.Requests != 1 || status.ErrorRate != 1 {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if _, _, apiErr, _ := rr.Route(regionRequest("eu")); apiErr == nil || apiErr.StatusCode != 500 {
+		t.Errorf("expected a 500 from eu, got %+v", apiErr)
+	}
+	if names := rr.Status(); len(names) != 2 || names[0].Name != "eu" || names[1].Name != "us" {
+		t.Errorf("expected eu and us, got %+v", names)
+	}
+}
+
+func TestRegionRouter_Set_RejectsInvalidRegion(t *testing.T) {
+	// Given
+	rr, _ := newRegionRouter(nil)
+	// When
+	_, err := rr.Set(regionConfig{Name: "eu", ErrorRate: -1})
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "error_rate") {
+		t.Errorf("expected an error_rate error, got %v", err)
+	}
+	if len(rr.Status()) != 0 {
+		t.Error("expected no region to be added")
+	}
+}
+
+func TestRegionRouter_Load_ReplacesRuntimeRegions(t *testing.T) {
+	// Given: a region added at runtime
+	rr, _ := newRegionRouter(nil)
+	_, _ = rr.Set(regionConfig{Name: "eu", Status: 503})
+	// When: the config is reloaded
+	if err := rr.Load([]regionConfig{{Name: "us", Latency: "1s"}}); err != nil {
+		t.Fatal(err)
+	}
+	// Then
+	statuses := rr.Status()
+	if len(statuses) != 1 || statuses[0].Name != "us" || statuses[0].Source != regionSourceConfig || statuses[0].Healthy {
+		t.Errorf("expected only the configured us region, got %+v", statuses)
+	}
+}
+
+func TestRegionRouter_Concurrent_CountsEveryRequest(t *testing.T) {
+	// Given: a half-down region
+	rr, _ := newRegionRouter([]regionConfig{{Name: "eu", ErrorRate: 0.5}})
+	var wg sync.WaitGroup
+	// When: requests are routed while the region is updated and read concurrently
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				rr.Route(regionRequest("eu"))
+				_ = rr.Status()
+			}
+			if i == 0 {
+				_, _ = rr.Set(regionConfig{Name: "eu", ErrorRate: 0.5})
+			}
+		}()
+	}
+	wg.Wait()
+	// Then: every request was counted and half of them failed
+	status := rr.Status()[0]
+	if status.Requests != 400 || status.Failures != 200 {
+		t.Errorf("expected 400 requests and 200 failures, got %+v", status)
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errNoRequests is returned for a traffic log without requests.
+var errNoRequests = errors.New("traffic log contains no requests")
+
+// capturedRequest is one line of a captured traffic log (JSON lines).
+type capturedRequest struct {
+	Time    time.Time         `json:"time"`
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// replayOptions configures a replay. A Speed of 0 sends every request as soon as a slot is free.
+type replayOptions struct {
+	Target      string
+	Speed       float64
+	Concurrency int
+	Headers     http.Header
+}
+
+// replayResult is the outcome of one replayed request.
+type replayResult struct {
+	Status  int
+	Latency time.Duration
+	Err     error
+}
+
+// replayReport summarizes a replay.
+type replayReport struct {
+	Requests  int
+	Errors    int
+	Statuses  map[int]int
+	Latencies []time.Duration // sorted, successful requests only
+	Duration  time.Duration
+}
+
+// headerFlags collects repeated -header "Name: value" flags.
+type headerFlags http.Header
+
+func (h headerFlags) String() string { return "" }
+
+func (h headerFlags) Set(v string) error {
+	name, value, ok := strings.Cut(v, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header %q must be \"Name: value\"", v)
+	}
+	http.Header(h).Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+// runReplayLoad implements "openai-mokku replay-load" and returns the exit code.
+func runReplayLoad(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("replay-load", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("target", "http://localhost:8080", "base URL of the server to load (mokku or a real gateway)")
+	speed := fs.Float64("speed", 1, "timing multiplier: 1 replays at the original timing, 2 twice as fast, 0 as fast as possible")
+	concurrency := fs.Int("concurrency", 16, "maximum number of requests in flight")
+	headers := headerFlags{}
+	fs.Var(headers, "header", "header to set on every request, e.g. \"Authorization: Bearer sk-...\" (repeatable)")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "usage: openai-mokku replay-load [flags] <traffic.jsonl | ->")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 || *speed < 0 || *concurrency < 1 {
+		fs.Usage()
+		return 2
+	}
+
+	in := io.Reader(os.Stdin)
+	if path := fs.Arg(0); path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "replay-load: %v\n", err)
+			return 1
+		}
+		defer func() { _ = f.Close() }()
+		in = f
+	}
+	reqs, err := readCapturedRequests(in)
+	if err != nil {
+		_, _ = fmt.Fprintf(stderr, "replay-load: %v\n", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	opts := replayOptions{Target: *target, Speed: *speed, Concurrency: *concurrency, Headers: http.Header(headers)}
+	report := replayLoad(ctx, http.DefaultClient, reqs, opts)
+	report.write(stdout)
+	if report.Errors > 0 {
+		return 1
+	}
+	return 0
+}
+
+// readCapturedRequests parses a captured traffic log. Blank lines are skipped; a log without
+// requests is an error.
+func readCapturedRequests(r io.Reader) ([]capturedRequest, error) {
+	var reqs []capturedRequest
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		var req capturedRequest
+		if err := json.Unmarshal(text, &req); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if req.Method == "" || !strings.HasPrefix(req.Path, "/") {
+			return nil, fmt.Errorf("line %d: method and an absolute path are required", line)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(reqs) == 0 {
+		return nil, errNoRequests
+	}
+	return reqs, nil
+}
+
+// replayLoad sends reqs to opts.Target, keeping their original spacing divided by opts.Speed, with
+// at most opts.Concurrency requests in flight. When all slots are busy, later requests are delayed.
+// Cancelling ctx stops sending; requests not sent are not reported.
+func replayLoad(ctx context.Context, client *http.Client, reqs []capturedRequest, opts replayOptions) replayReport {
+	results := make([]replayResult, 0, len(reqs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, opts.Concurrency)
+	start := time.Now()
+
+	for _, req := range reqs {
+		if opts.Speed > 0 && !req.Time.IsZero() && !reqs[0].Time.IsZero() {
+			offset := time.Duration(float64(req.Time.Sub(reqs[0].Time)) / opts.Speed)
+			if !sleepContext(ctx, time.Until(start.Add(offset))) {
+				break
+			}
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result := sendCapturedRequest(ctx, client, opts, req)
+			mu.Lock()
+			results = append(results, result)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return newReplayReport(results, time.Since(start))
+}
+
+// sleepContext sleeps for d and reports whether ctx is still active.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sendCapturedRequest sends one request and reads the whole response, so the latency of a
+// streamed response covers the full stream.
+func sendCapturedRequest(ctx context.Context, client *http.Client, opts replayOptions, req capturedRequest) replayResult {
+	var body io.Reader
+	if len(req.Body) > 0 {
+		body = bytes.NewReader(req.Body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Method, strings.TrimSuffix(opts.Target, "/")+req.Path, body)
+	if err != nil {
+		return replayResult{Err: err}
+	}
+	for name, value := range req.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	if body != nil && httpReq.Header.Get("Content-Type") == "" {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range opts.Headers {
+		httpReq.Header[name] = values
+	}
+
+	sent := time.Now()
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return replayResult{Err: err}
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return replayResult{Status: resp.StatusCode, Err: err}
+	}
+	return replayResult{Status: resp.StatusCode, Latency: time.Since(sent)}
+}
+
+// newReplayReport aggregates results.
+func newReplayReport(results []replayResult, duration time.Duration) replayReport {
+	report := replayReport{Requests: len(results), Statuses: map[int]int{}, Duration: duration}
+	for _, r := range results {
+		if r.Err != nil {
+			report.Errors++
+			continue
+		}
+		report.Statuses[r.Status]++
+		report.Latencies = append(report.Latencies, r.Latency)
+	}
+	slices.Sort(report.Latencies)
+	return report
+}
+
+// percentile returns the p-th percentile (0-100) of the sorted latencies using the nearest-rank method.
+func (r replayReport) percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+	rank := int(p/100*float64(len(r.Latencies))+0.5) - 1
+	return r.Latencies[max(0, min(rank, len(r.Latencies)-1))]
+}
+
+// write prints the report in a human-readable form.
+func (r replayReport) write(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "requests:   %d in %s", r.Requests, r.Duration.Round(time.Millisecond))
+	if secs := r.Duration.Seconds(); secs > 0 {
+		_, _ = fmt.Fprintf(w, " (%.1f req/s)", float64(r.Requests)/secs)
+	}
+	_, _ = fmt.Fprintln(w)
+	_, _ = fmt.Fprintf(w, "errors:     %d\n", r.Errors)
+	statuses := make([]int, 0, len(r.Statuses))
+	for status := range r.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	for _, status := range statuses {
+		_, _ = fmt.Fprintf(w, "status %d: %d\n", status, r.Statuses[status])
+	}
+	if len(r.Latencies) > 0 {
+		_, _ = fmt.Fprintf(w, "latency:    p50 %s  p90 %s  p99 %s  max %s\n",
+			r.percentile(50).Round(time.Microsecond), r.percentile(90).Round(time.Microsecond),
+			r.percentile(99).Round(time.Microsecond), r.Latencies[len(r.Latencies)-1].Round(time.Microsecond))
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// --- readCapturedRequests ---
+
+func TestReadCapturedRequests_ParsesLinesAndSkipsBlanks(t *testing.T) {
+	// Given
+	log := `{"time":"2026-01-01T00:00:00Z","method":"POST","path":"/v1/chat/completions","body":{"model":"gpt-4o"}}
+
+{"time":"2026-01-01T00:00:01Z","method":"GET","path":"/v1/models"}
+`
+	// When
+	reqs, err := readCapturedRequests(strings.NewReader(log))
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reqs) != 2 || reqs[0].Method != http.MethodPost || string(reqs[0].Body) != `{"model":"gpt-4o"}` || reqs[1].Path != "/v1/models" {
+		t.Errorf("unexpected requests: %+v", reqs)
+	}
+}
+
+func TestReadCapturedRequests_Errors(t *testing.T) {
+	// Given
+	cases := map[string]string{
+		"invalid JSON":  `{"method":`,
+		"relative path": `{"method":"GET","path":"v1/models"}`,
+		"empty":         "\n",
+	}
+	for name, log := range cases {
+		// When
+		_, err := readCapturedRequests(strings.NewReader(log))
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if _, err := readCapturedRequests(strings.NewReader("")); !errors.Is(err, errNoRequests) {
+		t.Errorf("expected errNoRequests, got %v", err)
+	}
+}
+
+// --- replayLoad ---
+
+func TestReplayLoad_SendsRequestsWithHeaders(t *testing.T) {
+	// Given: a target recording requests
+	var mu sync.Mutex
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got = append(got, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization")+" "+r.Header.Get("Content-Type")+" "+string(body))
+		mu.Unlock()
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	reqs := []capturedRequest{
+		{Method: http.MethodPost, Path: "/v1/embeddings", Headers: map[string]string{"Authorization": "Bearer redacted"}, Body: []byte(`{"input":"hi"}`)},
+		{Method: http.MethodGet, Path: "/missing"},
+	}
+
+	// When: replayed as fast as possible with an overriding header
+	report := replayLoad(context.Background(), srv.Client(), reqs, replayOptions{
+		Target: srv.URL + "/", Concurrency: 1, Headers: http.Header{"Authorization": {"Bearer sk-real"}},
+	})
+
+	// Then
+	want := []string{
+		"POST /v1/embeddings Bearer sk-real application/json " + `{"input":"hi"}`,
+		"GET /missing Bearer sk-real  ",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+	if report.Requests != 2 || report.Errors != 0 || report.Statuses[200] != 1 || report.Statuses[404] != 1 || len(report.Latencies) != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestReplayLoad_KeepsTimingDividedBySpeed(t *testing.T) {
+	// Given: two requests captured 200ms apart
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	reqs := []capturedRequest{
+		{Time: t0, Method: http.MethodGet, Path: "/"},
+		{Time: t0.Add(200 * time.Millisecond), Method: http.MethodGet, Path: "/"},
+	}
+
+	// When: replayed at twice the original speed
+	report := replayLoad(context.Background(), srv.Client(), reqs, replayOptions{Target: srv.URL, Speed: 2, Concurrency: 4})
+
+	// Then: the replay takes about 100ms
+	if report.Requests != 2 || report.Duration < 100*time.Millisecond || report.Duration > time.Second {
+		t.Errorf("expected 2 requests over ~100ms, got %d over %s", report.Requests, report.Duration)
+	}
+}
+
+func TestReplayLoad_LimitsConcurrency(t *testing.T) {
+	// Given: a slow target tracking requests in flight
+	var inFlight, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inFlight.Add(-1)
+	}))
+	defer srv.Close()
+	reqs := make([]capturedRequest, 10)
+	for i := range reqs {
+		reqs[i] = capturedRequest{Method: http.MethodGet, Path: "/"}
+	}
+
+	// When
+	report := replayLoad(context.Background(), srv.Client(), reqs, replayOptions{Target: srv.URL, Concurrency: 3})
+
+	// Then
+	if report.Requests != 10 || peak.Load() > 3 {
+		t.Errorf("expected 10 requests with at most 3 in flight, got %d with %d", report.Requests, peak.Load())
+	}
+}
+
+func TestReplayLoad_ConnectionErrorsAreCounted(t *testing.T) {
+	// Given: a closed target
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	// When
+	report := replayLoad(context.Background(), http.DefaultClient, []capturedRequest{{Method: http.MethodGet, Path: "/"}},
+		replayOptions{Target: srv.URL, Concurrency: 1})
+	// Then
+	if report.Errors != 1 || len(report.Latencies) != 0 {
+		t.Errorf("expected one error, got %+v", report)
+	}
+}
+
+// --- replayReport ---
+
+func TestReplayReport_PercentilesAndOutput(t *testing.T) {
+	// Given: latencies of 1..100ms
+	var results []replayResult
+	for i := 100; i >= 1; i-- {
+		results = append(results, replayResult{Status: 200, Latency: time.Duration(i) * time.Millisecond})
+	}
+	report := newReplayReport(results, 2*time.Second)
+	// When
+	var out bytes.Buffer
+	report.write(&out)
+	// Then
+	if report.percentile(50) != 50*time.Millisecond || report.percentile(99) != 99*time.Millisecond {
+		t.Errorf("unexpected percentiles: p50 %s p99 %s", report.percentile(50), report.percentile(99))
+	}
+	for _, want := range []string{"100 in 2s (50.0 req/s)", "status 200: 100", "p50 50ms", "max 100ms"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in %q", want, out.String())
+		}
+	}
+}
+
+// --- runReplayLoad ---
+
+func TestRunReplayLoad_InvalidArguments(t *testing.T) {
+	// Given / When
+	var stderr bytes.Buffer
+	code := runReplayLoad([]string{"-speed", "-1", "traffic.jsonl"}, io.Discard, &stderr)
+	// Then
+	if code != 2 || !strings.Contains(stderr.String(), "usage: openai-mokku replay-load") {
+		t.Errorf("expected usage with exit code 2, got %d: %s", code, stderr.String())
+	}
+}
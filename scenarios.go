@@ -0,0 +1,836 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"time"
+
+	"github.com/go-faster/yaml"
+)
+
+// scenarioFile is the MOKKU_SCENARIOS file. Include names other scenario files (see loadFragments).
+type scenarioFile struct {
+	Include   []string         `yaml:"include,omitempty" json:"include,omitempty"`
+	Scenarios []scenarioConfig `yaml:"scenarios,omitempty" json:"scenarios,omitempty"`
+}
+
+// scenarioConfig is a rule of the scenario file: requests matching Match get Response.
+type scenarioConfig struct {
+	Name     string                 `yaml:"name,omitempty" json:"name,omitempty"`
+	Match    scenarioMatchConfig    `yaml:"match,omitempty" json:"match,omitempty"`
+	Response scenarioResponseConfig `yaml:"response,omitempty" json:"response,omitempty"`
+}
+
+// scenarioMatchConfig selects requests. Empty fields match everything; Model, Path, and the
+// end-user IDs (the safety_identifier and user body fields) are exact, Message and header values
+// are regular expressions.
+type scenarioMatchConfig struct {
+	Model            string            `yaml:"model,omitempty" json:"model,omitempty"`
+	Path             string            `yaml:"path,omitempty" json:"path,omitempty"`
+	Message          string            `yaml:"message,omitempty" json:"message,omitempty"`
+	Headers          map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`
+	SafetyIdentifier string            `yaml:"safety_identifier,omitempty" json:"safety_identifier,omitempty"`
+	User             string            `yaml:"user,omitempty" json:"user,omitempty"`
+}
+
+// scenarioResponseConfig is the behavior of a matched request. With Status set, the request fails
+// with an error; otherwise Content and FinishReason replace the generated response. Script runs
+// last as a template hook and may override all of them and set response headers. Map sets fields of non-streaming
+// response bodies (see fieldMapping).
+type scenarioResponseConfig struct {
+	Content      *string              `yaml:"content,omitempty" json:"content,omitempty"`
+	Script       string               `yaml:"script,omitempty" json:"script,omitempty"`
+	Map          []string             `yaml:"map,omitempty" json:"map,omitempty"`
+	FinishReason string               `yaml:"finish_reason,omitempty" json:"finish_reason,omitempty"`
+	Status       int                  `yaml:"status,omitempty" json:"status,omitempty"`
+	Error        *scenarioErrorConfig `yaml:"error,omitempty" json:"error,omitempty"`
+	Latency      string               `yaml:"latency,omitempty" json:"latency,omitempty"`
+}
+
+// scenarioErrorConfig overrides fields of the default error body for the status.
+type scenarioErrorConfig struct {
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+	Type    string `yaml:"type,omitempty" json:"type,omitempty"`
+	Code    string `yaml:"code,omitempty" json:"code,omitempty"`
+	Param   string `yaml:"param,omitempty" json:"param,omitempty"`
+}
+
+// scenarioFinishReasons are the finish reasons a scenario may set.
+var scenarioFinishReasons = map[string]bool{"stop": true, "length": true, "content_filter": true}
+
+// scenarioRule is a compiled scenarioConfig.
+type scenarioRule struct {
+	// cfg is the rule as configured, named, to restore or send it again.
+	cfg          scenarioConfig
+	name         string
+	model        string
+	path         string
+	endUser      endUser
+	message      *regexp.Regexp
+	headers      map[string]*regexp.Regexp
+	content      *template.Template
+	script       *template.Template
+	mappings     []*fieldMapping
+	finishReason string
+	err          *APIError
+	latency      time.Duration
+}
+
+// scenarioData is the data available to content templates.
+type scenarioData struct {
+	Model   string
+	Message string
+	Path    string
+	// Body is the decoded JSON request body.
+	Body any
+	// SafetyIdentifier and User are the end-user IDs of the request body.
+	SafetyIdentifier string
+	User             string
+	// Seed is the request's seed, which Choice and RandInt draw from.
+	Seed uint64
+	rand *rand.Rand
+}
+
+// matchedScenario is the behavior of the scenario matched by a request, passed to the handlers
+// through the request context.
+type matchedScenario struct {
+	Name string
+	// Content is the rendered content without its pause markers; nil keeps the generated content.
+	Content *string
+	// Pauses are the pause markers of Content.
+	Pauses []contentPause
+	// FinishReason replaces finish_reason when set.
+	FinishReason string
+	// Header is added to the response.
+	Header http.Header
+	// Err fails the request when set.
+	Err *APIError
+	// Mappings are applied to the response body by mappingWriter.
+	Mappings []*fieldMapping
+}
+
+// scenarioEngine matches API requests against the rules of the scenario file, first match wins.
+// It is inactive without rules and can be reloaded at runtime. In strict mode it also records the
+// requests no rule matches. Rules deleted through the admin API are kept in its trash for the
+// restore window.
+type scenarioEngine struct {
+	// mu serializes changes to the rules; matching reads them without locking.
+	mu    sync.Mutex
+	rules atomic.Pointer[[]*scenarioRule]
+	// replaced is the scenario file sent through the admin API, or of the rules once changed through
+	// it, nil while the rules are those of the file at the configured path.
+	replaced   atomic.Pointer[[]byte]
+	unexpected *unexpectedLog
+	trash      *scenarioTrash
+}
+
+// newScenarioEngine creates an engine with the rules of the file at path; an empty path yields
+// an inactive engine.
+func newScenarioEngine(path string) (*scenarioEngine, error) {
+	e := &scenarioEngine{unexpected: newUnexpectedLog(maxUnexpectedRequests), trash: newScenarioTrash()}
+	e.rules.Store(&[]*scenarioRule{})
+	if err := e.Load(path); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Load replaces the rules with those of the YAML (or JSON) file at path, the files it includes,
+// and their MOKKU_ENV overlays. The rules of fragments with higher precedence come first (see
+// loadFragments): overlays before the file they overlay, a file before the files it includes, and
+// later includes before earlier ones.
+// On error the current rules are kept.
+func (e *scenarioEngine) Load(path string) error {
+	return installNow(e.prepare(path))
+}
+
+// prepare reads and validates the rules of the file at path and returns the function installing them.
+func (e *scenarioEngine) prepare(path string) (func(), error) {
+	rules := []*scenarioRule{}
+	if path != "" {
+		fragments, err := loadFragments(path, os.Getenv(envVar))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load scenarios: %w", err)
+		}
+		for i := len(fragments) - 1; i >= 0; i-- {
+			parsed, err := parseScenarios(fragments[i].data)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse scenarios %s: %w", fragments[i].path, err)
+			}
+			rules = append(rules, parsed...)
+		}
+	}
+	return func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.rules.Store(&rules)
+		e.replaced.Store(nil)
+	}, nil
+}
+
+// Replace atomically replaces the rules with those of a scenario file sent through the admin API.
+// Every rule is validated before any is applied, so requests never see a partial set; on error the
+// current rules are kept. Includes are not supported, as there is no file to resolve them against.
+// The current rules whose names the file leaves out go to the trash.
+func (e *scenarioEngine) Replace(data []byte) error {
+	var file scenarioFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return err
+	}
+	if len(file.Include) > 0 {
+		return fmt.Errorf("include is not supported; send the included scenarios instead")
+	}
+	rules, err := compileScenarios(file.Scenarios)
+	if err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i, rule := range *e.rules.Load() {
+		if !slices.ContainsFunc(rules, func(r *scenarioRule) bool { return r.name == rule.name }) {
+			e.trash.Add(rule, i, deleteReasonReplaced)
+		}
+	}
+	e.rules.Store(&rules)
+	e.replaced.Store(&data)
+	return nil
+}
+
+// Delete removes the first rule named name and keeps it in the trash. It reports false when no rule
+// has the name.
+func (e *scenarioEngine) Delete(name string) (deletedScenario, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	rules := *e.rules.Load()
+	i := slices.IndexFunc(rules, func(r *scenarioRule) bool { return r.name == name })
+	if i < 0 {
+		return deletedScenario{}, false
+	}
+	d := e.trash.Add(rules[i], i, deleteReasonDeleted)
+	e.storeLocked(slices.Delete(slices.Clone(rules), i, i+1))
+	return d, true
+}
+
+// Restore puts the last rule deleted with name back at its position in match order, or at the end
+// when fewer rules are left. It fails with errScenarioActive while a rule has the name, and with
+// errScenarioNotDeleted when none can be restored.
+func (e *scenarioEngine) Restore(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	rules := *e.rules.Load()
+	if slices.ContainsFunc(rules, func(r *scenarioRule) bool { return r.name == name }) {
+		return errScenarioActive
+	}
+	d, ok := e.trash.Take(name)
+	if !ok {
+		return errScenarioNotDeleted
+	}
+	e.storeLocked(slices.Insert(slices.Clone(rules), min(d.Position, len(rules)), d.rule))
+	return nil
+}
+
+// Deleted returns the rules that can be restored, oldest deletion first.
+func (e *scenarioEngine) Deleted() []deletedScenario {
+	return e.trash.List()
+}
+
+// SetRestoreWindow changes how long later deletions can be restored.
+func (e *scenarioEngine) SetRestoreWindow(window time.Duration) {
+	e.trash.SetWindow(window)
+}
+
+// storeLocked installs rules changed through the admin API, with the scenario file they make up as
+// the replaced file, so they survive a handoff like rules sent with Replace.
+func (e *scenarioEngine) storeLocked(rules []*scenarioRule) {
+	file := scenarioFile{Scenarios: make([]scenarioConfig, len(rules))}
+	for i, rule := range rules {
+		file.Scenarios[i] = rule.cfg
+	}
+	if data, err := yaml.Marshal(file); err == nil {
+		e.replaced.Store(&data)
+	}
+	e.rules.Store(&rules)
+}
+
+// Replaced returns the scenario file of the last Replace, or nil if the rules were loaded from the
+// configured path since.
+func (e *scenarioEngine) Replaced() []byte {
+	if data := e.replaced.Load(); data != nil {
+		return *data
+	}
+	return nil
+}
+
+// Names returns the names of the rules in match order.
+func (e *scenarioEngine) Names() []string {
+	rules := *e.rules.Load()
+	names := make([]string, len(rules))
+	for i, rule := range rules {
+		names[i] = rule.name
+	}
+	return names
+}
+
+// Active reports whether any rule is loaded.
+func (e *scenarioEngine) Active() bool {
+	return len(*e.rules.Load()) > 0
+}
+
+// Len returns the number of rules.
+func (e *scenarioEngine) Len() int {
+	return len(*e.rules.Load())
+}
+
+// parseScenarios decodes and compiles a scenario file.
+func parseScenarios(data []byte) ([]*scenarioRule, error) {
+	var file scenarioFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	return compileScenarios(file.Scenarios)
+}
+
+// compileScenarios compiles the rules of a scenario file, reporting every invalid rule.
+func compileScenarios(configs []scenarioConfig) ([]*scenarioRule, error) {
+	rules := make([]*scenarioRule, 0, len(configs))
+	var errs []error
+	for i, cfg := range configs {
+		rule, err := compileScenario(cfg)
+		if err != nil {
+			name := cfg.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			errs = append(errs, fmt.Errorf("scenario %s: %w", name, err))
+			continue
+		}
+		if rule.name == "" {
+			rule.name = fmt.Sprintf("scenario-%d", i)
+		}
+		rule.cfg = cfg
+		rule.cfg.Name = rule.name
+		rules = append(rules, rule)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return rules, nil
+}
+
+// compileScenario validates a rule and compiles its patterns and template.
+func compileScenario(cfg scenarioConfig) (*scenarioRule, error) {
+	rule := &scenarioRule{name: cfg.Name, model: cfg.Match.Model, path: cfg.Match.Path, headers: map[string]*regexp.Regexp{},
+		endUser: endUser{SafetyIdentifier: cfg.Match.SafetyIdentifier, User: cfg.Match.User}}
+	var err error
+	if cfg.Match.Message != "" {
+		if rule.message, err = regexp.Compile(cfg.Match.Message); err != nil {
+			return nil, fmt.Errorf("match.message: %w", err)
+		}
+	}
+	for name, pattern := range cfg.Match.Headers {
+		if rule.headers[name], err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("match.headers.%s: %w", name, err)
+		}
+	}
+
+	resp := cfg.Response
+	if resp.Content != nil {
+		if rule.content, err = template.New(rule.name).Option("missingkey=error").Funcs(templateFuncs).Parse(*resp.Content); err != nil {
+			return nil, fmt.Errorf("response.content: %w", err)
+		}
+	}
+	if resp.Script != "" {
+		if rule.script, err = template.New(rule.name).Option("missingkey=error").Funcs(templateFuncs).Parse(resp.Script); err != nil {
+			return nil, fmt.Errorf("response.script: %w", err)
+		}
+	}
+	for i, line := range resp.Map {
+		m, err := parseMapping(line)
+		if err != nil {
+			return nil, fmt.Errorf("response.map[%d]: %w", i, err)
+		}
+		rule.mappings = append(rule.mappings, m)
+	}
+	if resp.FinishReason != "" && !scenarioFinishReasons[resp.FinishReason] {
+		return nil, fmt.Errorf("response.finish_reason must be stop, length, or content_filter, got %q", resp.FinishReason)
+	}
+	rule.finishReason = resp.FinishReason
+	if resp.Latency != "" {
+		if rule.latency, err = time.ParseDuration(resp.Latency); err != nil || rule.latency < 0 {
+			return nil, fmt.Errorf("response.latency must be a duration such as 500ms, got %q", resp.Latency)
+		}
+	}
+	switch {
+	case resp.Status == 0 && resp.Error != nil:
+		return nil, fmt.Errorf("response.error requires response.status")
+	case resp.Status != 0 && (resp.Status < 400 || resp.Status > 599):
+		return nil, fmt.Errorf("response.status must be an HTTP error status (400-599), got %d", resp.Status)
+	case resp.Status != 0:
+		rule.err = scenarioError(resp.Status, resp.Error)
+	}
+	return rule, nil
+}
+
+// scenarioError returns the error the real API sends for status, with the fields set in override.
+func scenarioError(status int, override *scenarioErrorConfig) *APIError {
+	detail := OpenAIErrorDetail{Message: http.StatusText(status), Type: "invalid_request_error"}
+	if status >= 500 {
+		detail.Type = "server_error"
+	}
+	switch status {
+	case http.StatusUnauthorized:
+		detail = OpenAIErrorDetail{Message: "Incorrect API key provided.", Type: "invalid_request_error", Code: "invalid_api_key"}
+	case http.StatusPaymentRequired:
+		detail = OpenAIErrorDetail{Message: "You exceeded your current quota, please check your plan and billing details.",
+			Type: "insufficient_quota", Code: "insufficient_quota"}
+	case http.StatusTooManyRequests:
+		detail = OpenAIErrorDetail{Message: "Rate limit reached. Please try again later.", Type: "requests", Code: "rate_limit_exceeded"}
+	case http.StatusInternalServerError:
+		detail = OpenAIErrorDetail{Message: "The server had an error while processing your request. Sorry about that!", Type: "server_error"}
+	case http.StatusServiceUnavailable:
+		detail = OpenAIErrorDetail{Message: "The engine is currently overloaded, please try again later.", Type: "server_error"}
+	}
+	if override != nil {
+		if override.Message != "" {
+			detail.Message = override.Message
+		}
+		if override.Type != "" {
+			detail.Type = override.Type
+		}
+		if override.Code != "" {
+			detail.Code = override.Code
+		}
+		if override.Param != "" {
+			detail.Param = &override.Param
+		}
+	}
+	return &APIError{StatusCode: status, Detail: detail}
+}
+
+// Match returns the first rule matching a request with the decoded JSON body doc.
+func (e *scenarioEngine) Match(r *http.Request, doc any) (*scenarioRule, scenarioData, bool) {
+	data := newScenarioData(r, doc)
+	for _, rule := range *e.rules.Load() {
+		if rule.matches(r, data) {
+			return rule, data, true
+		}
+	}
+	return nil, data, false
+}
+
+// newScenarioData returns the data rules are matched against for a request with the decoded JSON
+// body doc.
+func newScenarioData(r *http.Request, doc any) scenarioData {
+	id := requestEndUser(doc)
+	data := scenarioData{Path: r.URL.Path, Message: scenarioMessage(doc), Body: doc, SafetyIdentifier: id.SafetyIdentifier, User: id.User}
+	data.Seed, _ = seedFromContext(r.Context())
+	if m, ok := doc.(map[string]any); ok {
+		data.Model, _ = m["model"].(string)
+	}
+	if route, ok := dialectFromContext(r.Context()); ok && route.model != "" {
+		data.Model = route.model
+	}
+	return data
+}
+
+// matches reports whether the rule selects a request.
+func (rule *scenarioRule) matches(r *http.Request, data scenarioData) bool {
+	return len(rule.mismatches(r, data)) == 0
+}
+
+// scenarioMismatch is a match field of a rule that a request does not satisfy.
+type scenarioMismatch struct {
+	// Field is model, path, safety_identifier, user, message, or header:<name>.
+	Field string `json:"field"`
+	// Expected is the exact value, or the regular expression when Pattern is set.
+	Expected string `json:"expected"`
+	Pattern  bool   `json:"pattern"`
+	Actual   string `json:"actual"`
+}
+
+// String describes the mismatch.
+func (m scenarioMismatch) String() string {
+	field := m.Field
+	if name, ok := strings.CutPrefix(field, "header:"); ok {
+		field = "header " + name
+	}
+	if m.Pattern {
+		return fmt.Sprintf("%s %q does not match %q", field, m.Actual, m.Expected)
+	}
+	return fmt.Sprintf("%s is %q, want %q", field, m.Actual, m.Expected)
+}
+
+// mismatches returns the match fields of the rule a request does not satisfy, none when it matches.
+func (rule *scenarioRule) mismatches(r *http.Request, data scenarioData) []scenarioMismatch {
+	var diff []scenarioMismatch
+	if rule.model != "" && rule.model != data.Model {
+		diff = append(diff, scenarioMismatch{Field: "model", Expected: rule.model, Actual: data.Model})
+	}
+	if rule.path != "" && rule.path != data.Path {
+		diff = append(diff, scenarioMismatch{Field: "path", Expected: rule.path, Actual: data.Path})
+	}
+	if rule.endUser.SafetyIdentifier != "" && rule.endUser.SafetyIdentifier != data.SafetyIdentifier {
+		diff = append(diff, scenarioMismatch{Field: "safety_identifier", Expected: rule.endUser.SafetyIdentifier, Actual: data.SafetyIdentifier})
+	}
+	if rule.endUser.User != "" && rule.endUser.User != data.User {
+		diff = append(diff, scenarioMismatch{Field: "user", Expected: rule.endUser.User, Actual: data.User})
+	}
+	if rule.message != nil && !rule.message.MatchString(data.Message) {
+		diff = append(diff, scenarioMismatch{Field: "message", Expected: rule.message.String(), Pattern: true, Actual: data.Message})
+	}
+	for _, name := range slices.Sorted(maps.Keys(rule.headers)) {
+		if pattern := rule.headers[name]; !pattern.MatchString(r.Header.Get(name)) {
+			diff = append(diff, scenarioMismatch{Field: "header:" + name, Expected: pattern.String(), Pattern: true, Actual: r.Header.Get(name)})
+		}
+	}
+	return diff
+}
+
+// Closest returns the rule with the fewest mismatches for a request with the decoded JSON body doc
+// and those mismatches; the earlier rule wins a tie. It returns nil without rules.
+func (e *scenarioEngine) Closest(r *http.Request, doc any) (*scenarioRule, []scenarioMismatch) {
+	data := newScenarioData(r, doc)
+	var closest *scenarioRule
+	var diff []scenarioMismatch
+	for _, rule := range *e.rules.Load() {
+		if d := rule.mismatches(r, data); closest == nil || len(d) < len(diff) {
+			closest, diff = rule, d
+		}
+	}
+	return closest, diff
+}
+
+// apply renders the rule's content and runs its script for a request, then takes the pause markers
+// out of the content.
+func (rule *scenarioRule) apply(r *http.Request, data scenarioData) (matchedScenario, error) {
+	matched := matchedScenario{Name: rule.name, FinishReason: rule.finishReason, Err: rule.err, Mappings: rule.mappings}
+	if rule.content != nil {
+		var b strings.Builder
+		if err := rule.content.Execute(&b, &data); err != nil {
+			return matched, err
+		}
+		content := b.String()
+		matched.Content = &content
+	}
+	if rule.script != nil {
+		// The hook's effects are collected on a copy, installed only once it has run to the end, so a
+		// failing hook leaves no half-applied response
+		effects := matched
+		effects.Header = matched.Header.Clone()
+		env := &scriptEnv{scenarioData: data, request: r.Header, matched: &effects}
+		if err := rule.script.Execute(io.Discard, env); err != nil {
+			return matched, err
+		}
+		matched = effects
+	}
+	if matched.Content != nil {
+		content, pauses, err := stripPauses(*matched.Content)
+		if err != nil {
+			return matched, err
+		}
+		matched.Content, matched.Pauses = &content, pauses
+	}
+	return matched, nil
+}
+
+// scriptEnv is the data of a template hook (response.script): the request fields of content
+// templates, the request headers, and the methods setting the response. The methods run in template
+// order, a later call overriding an earlier one, and only record their effect; apply installs the
+// effects once the whole template has run. The text a hook writes is discarded.
+type scriptEnv struct {
+	scenarioData
+	request http.Header
+	matched *matchedScenario
+}
+
+// Header returns a request header.
+func (e *scriptEnv) Header(name string) string {
+	return e.request.Get(name)
+}
+
+// SetContent replaces the generated content.
+func (e *scriptEnv) SetContent(content string) string {
+	e.matched.Content = &content
+	return ""
+}
+
+// SetFinishReason replaces finish_reason.
+func (e *scriptEnv) SetFinishReason(reason string) (string, error) {
+	if !scenarioFinishReasons[reason] {
+		return "", fmt.Errorf("finish reason must be stop, length, or content_filter, got %q", reason)
+	}
+	e.matched.FinishReason = reason
+	return "", nil
+}
+
+// SetStatus fails the request with the error of an HTTP error status, with an optional message.
+func (e *scriptEnv) SetStatus(status int, message ...string) (string, error) {
+	if status < 400 || status > 599 {
+		return "", fmt.Errorf("status must be an HTTP error status (400-599), got %d", status)
+	}
+	e.matched.Err = scenarioError(status, &scenarioErrorConfig{Message: strings.Join(message, " ")})
+	return "", nil
+}
+
+// SetHeader sets a response header.
+func (e *scriptEnv) SetHeader(name, value string) string {
+	if e.matched.Header == nil {
+		e.matched.Header = http.Header{}
+	}
+	e.matched.Header.Set(name, value)
+	return ""
+}
+
+// scenarioMessage returns the text a message pattern is matched against: the last user message of
+// a chat or Anthropic Messages request, the last user turn of a Gemini request, or the prompt or
+// input of other requests.
+func scenarioMessage(doc any) string {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return ""
+	}
+	if messages, ok := m["messages"].([]any); ok {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if msg, ok := messages[i].(map[string]any); ok && msg["role"] == "user" {
+				return messageText(msg["content"])
+			}
+		}
+		return ""
+	}
+	if contents, ok := m["contents"].([]any); ok {
+		// Gemini turns, whose role defaults to user
+		for i := len(contents) - 1; i >= 0; i-- {
+			if c, ok := contents[i].(map[string]any); ok && (c["role"] == nil || c["role"] == "user") {
+				return strings.Join(collectStrings(c["parts"], nil), " ")
+			}
+		}
+		return ""
+	}
+	for _, key := range []string{"prompt", "input"} {
+		if v, ok := m[key]; ok {
+			return strings.Join(collectStrings(v, nil), " ")
+		}
+	}
+	return ""
+}
+
+// messageText returns the text of decoded chat message content: a string, or the text of its text parts.
+func messageText(content any) string {
+	parts, ok := content.([]any)
+	if !ok {
+		text, _ := content.(string)
+		return text
+	}
+	var texts []string
+	for _, part := range parts {
+		if p, ok := part.(map[string]any); ok && p["type"] == "text" {
+			if text, ok := p["text"].(string); ok {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, " ")
+}
+
+// scenarioFailure is the error of a request whose scenario could not be applied.
+func scenarioFailure(name string, err error) *APIError {
+	return &APIError{
+		StatusCode: http.StatusInternalServerError,
+		Detail: OpenAIErrorDetail{
+			Message: fmt.Sprintf("scenario %s: %v", name, err),
+			Type:    "server_error",
+		},
+	}
+}
+
+// unexpectedStatus is the status of requests no scenario matches in strict mode. The real API never
+// sends it and the SDKs do not retry it, so it cannot be mistaken for a simulated API error.
+const unexpectedStatus = http.StatusTeapot
+
+// maxUnexpectedRequests is the number of recent unexpected requests kept for GET /_mokku/verify.
+const maxUnexpectedRequests = 100
+
+// unexpectedRequest is a request no scenario matched in strict mode.
+type unexpectedRequest struct {
+	Time   time.Time `json:"time"`
+	Method string    `json:"method"`
+	Path   string    `json:"path"`
+	scenarioDiff
+}
+
+// verification is the response of GET /_mokku/verify.
+type verification struct {
+	Object string `json:"object"`
+	// Passed reports whether no unexpected request arrived since the last reset.
+	Passed             bool                `json:"passed"`
+	UnexpectedRequests int64               `json:"unexpected_requests"`
+	Recent             []unexpectedRequest `json:"recent"`
+}
+
+// unexpectedLog counts the unexpected requests and keeps the most recent ones. It is safe for
+// concurrent use.
+type unexpectedLog struct {
+	mu     sync.Mutex
+	total  int64
+	recent *ringBuffer[unexpectedRequest]
+}
+
+// newUnexpectedLog creates a log keeping the last capacity requests.
+func newUnexpectedLog(capacity int) *unexpectedLog {
+	return &unexpectedLog{recent: newRingBuffer[unexpectedRequest](capacity)}
+}
+
+// Record counts an unexpected request.
+func (l *unexpectedLog) Record(req unexpectedRequest) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total++
+	l.recent.Push(req)
+}
+
+// Verification reports the unexpected requests since the last reset, most recent last.
+func (l *unexpectedLog) Verification() verification {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return verification{Object: "mokku.verification", Passed: l.total == 0, UnexpectedRequests: l.total, Recent: l.recent.Snapshot()}
+}
+
+// Reset forgets the unexpected requests, e.g. between test cases.
+func (l *unexpectedLog) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total = 0
+	l.recent.Clear()
+}
+
+// Unexpected returns the log of requests no rule matched in strict mode.
+func (e *scenarioEngine) Unexpected() *unexpectedLog {
+	return e.unexpected
+}
+
+// unmatchedError is the response body of a request no scenario matches in strict mode: the OpenAI
+// error, and the diff against the closest scenario for test tooling.
+type unmatchedError struct {
+	Error OpenAIErrorDetail `json:"error"`
+	Mokku scenarioDiff      `json:"mokku"`
+}
+
+// scenarioDiff is the difference between a request and the closest scenario.
+type scenarioDiff struct {
+	Closest *string            `json:"closest"`
+	Diff    []scenarioMismatch `json:"diff"`
+}
+
+// newUnmatchedError describes a request no scenario matches, given the closest scenario.
+func newUnmatchedError(r *http.Request, closest *scenarioRule, diff []scenarioMismatch) unmatchedError {
+	resp := unmatchedError{
+		Error: OpenAIErrorDetail{
+			Message: fmt.Sprintf("No scenario matches %s %s.", r.Method, r.URL.Path),
+			Type:    "invalid_request_error",
+			Code:    "scenario_not_matched",
+		},
+		Mokku: scenarioDiff{Diff: []scenarioMismatch{}},
+	}
+	if closest != nil {
+		fields := make([]string, len(diff))
+		for i, m := range diff {
+			fields[i] = m.Field
+		}
+		resp.Error.Message += fmt.Sprintf(" The closest scenario %s differs in %s.", closest.name, strings.Join(fields, ", "))
+		resp.Mokku = scenarioDiff{Closest: &closest.name, Diff: diff}
+	}
+	return resp
+}
+
+// scenarioEvaluation is the result of a dry run of the rules against a request.
+type scenarioEvaluation struct {
+	Object string `json:"object"`
+	// Matched is the name of the rule the request would get, nil when none matches.
+	Matched *string `json:"matched"`
+	Seed    uint64  `json:"seed"`
+	// Response is the behavior of the matched rule, nil when the generated response is kept.
+	Response *scenarioOutcome `json:"response"`
+	Trace    []scenarioTrace  `json:"trace"`
+}
+
+// scenarioOutcome is the rendered behavior of a rule for a request.
+type scenarioOutcome struct {
+	Status       int                `json:"status"`
+	Error        *OpenAIErrorDetail `json:"error,omitempty"`
+	Content      *string            `json:"content,omitempty"`
+	FinishReason string             `json:"finish_reason,omitempty"`
+	Headers      http.Header        `json:"headers,omitempty"`
+	LatencyMs    int64              `json:"latency_ms"`
+	Map          []string           `json:"map,omitempty"`
+}
+
+// scenarioTrace reports whether a rule selects a request and why not.
+type scenarioTrace struct {
+	Name    string   `json:"name"`
+	Matched bool     `json:"matched"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Evaluate reports which rule a request with the decoded JSON body doc would match, its rendered
+// behavior, and why each rule does or does not match, without waiting for latency.
+func (e *scenarioEngine) Evaluate(r *http.Request, doc any) scenarioEvaluation {
+	data := newScenarioData(r, doc)
+	eval := scenarioEvaluation{Object: "mokku.evaluation", Seed: data.Seed, Trace: []scenarioTrace{}}
+	for _, rule := range *e.rules.Load() {
+		var reasons []string
+		for _, m := range rule.mismatches(r, data) {
+			reasons = append(reasons, m.String())
+		}
+		eval.Trace = append(eval.Trace, scenarioTrace{Name: rule.name, Matched: len(reasons) == 0, Reasons: reasons})
+		if len(reasons) > 0 || eval.Matched != nil {
+			continue
+		}
+		eval.Matched = &rule.name
+		eval.Response = rule.outcome(r, data)
+	}
+	return eval
+}
+
+// outcome renders the rule's behavior for a request like applyScenario.
+func (rule *scenarioRule) outcome(r *http.Request, data scenarioData) *scenarioOutcome {
+	out := &scenarioOutcome{Status: http.StatusOK, LatencyMs: rule.latency.Milliseconds()}
+	for _, m := range rule.mappings {
+		out.Map = append(out.Map, m.line)
+	}
+	matched, err := rule.apply(r, data)
+	if err != nil {
+		matched.Err = scenarioFailure(rule.name, err)
+	}
+	out.Headers = matched.Header
+	if matched.Err != nil {
+		out.Status, out.Error = matched.Err.StatusCode, &matched.Err.Detail
+		return out
+	}
+	out.Content, out.FinishReason = matched.Content, matched.FinishReason
+	return out
+}
+
+type scenarioContextKey struct{}
+
+// withScenario stores the matched scenario of a request in the context.
+func withScenario(ctx context.Context, s matchedScenario) context.Context {
+	return context.WithValue(ctx, scenarioContextKey{}, s)
+}
+
+// scenarioFromContext returns the scenario stored by withScenario.
+func scenarioFromContext(ctx context.Context) (matchedScenario, bool) {
+	s, ok := ctx.Value(scenarioContextKey{}).(matchedScenario)
+	return s, ok
+}
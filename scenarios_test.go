@@ -0,0 +1,456 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// --- parseScenarios ---
+
+func TestParseScenarios_RejectsInvalidRules(t *testing.T) {
+	// Given
+	cases := map[string]string{
+		"bad message regex":    `{"scenarios":[{"match":{"message":"("}}]}`,
+		"bad header regex":     `{"scenarios":[{"match":{"headers":{"X-A":"["}}}]}`,
+		"bad template":         `{"scenarios":[{"response":{"content":"{{.Model"}}]}`,
+		"bad script":           `{"scenarios":[{"response":{"script":"{{if .Model}}"}}]}`,
+		"unknown script func":  `{"scenarios":[{"response":{"script":"{{exec .Model}}"}}]}`,
+		"bad mapping":          `{"scenarios":[{"response":{"map":["id = request.model | shout"]}}]}`,
+		"bad finish_reason":    `{"scenarios":[{"response":{"finish_reason":"tool_calls"}}]}`,
+		"bad latency":          `{"scenarios":[{"response":{"latency":"soon"}}]}`,
+		"non-error status":     `{"scenarios":[{"response":{"status":200}}]}`,
+		"error without status": `{"scenarios":[{"response":{"error":{"code":"x"}}}]}`,
+		"not a scenario doc":   `scenarios: 3`,
+	}
+	for name, doc := range cases {
+		// When
+		_, err := parseScenarios([]byte(doc))
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestParseScenarios_DefaultErrorBodies(t *testing.T) {
+	// Given
+	doc := `
+scenarios:
+  - response: {status: 429}
+  - response: {status: 500}
+  - response: {status: 418, error: {param: model}}
+`
+	// When
+	rules, err := parseScenarios([]byte(doc))
+	// Then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules[0].err.Detail.Code != "rate_limit_exceeded" || rules[1].err.Detail.Type != "server_error" {
+		t.Errorf("unexpected defaults: %+v, %+v", rules[0].err.Detail, rules[1].err.Detail)
+	}
+	if d := rules[2].err.Detail; d.Type != "invalid_request_error" || d.Param == nil || *d.Param != "model" || rules[2].name != "scenario-2" {
+		t.Errorf("unexpected generic error: %+v (%s)", d, rules[2].name)
+	}
+}
+
+// --- scenarioEngine.Match ---
+
+func TestScenarioEngine_Match_FirstMatchingRuleWins(t *testing.T) {
+	// Given
+	rules, err := parseScenarios([]byte(`
+scenarios:
+  - name: header
+    match: {headers: {X-Case: "^a$"}, model: gpt-4o}
+  - name: message
+    match: {message: "hello"}
+  - name: fallback
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &scenarioEngine{}
+	e.rules.Store(&rules)
+	doc := map[string]any{"model": "gpt-4o", "messages": []any{
+		map[string]any{"role": "user", "content": []any{map[string]any{"type": "text", "text": "hello there"}}},
+		map[string]any{"role": "assistant", "content": "hi"},
+	}}
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	// When
+	byMessage, data, _ := e.Match(r, doc)
+	r.Header.Set("X-Case", "a")
+	byHeader, _, _ := e.Match(r, doc)
+	fallback, _, _ := e.Match(r, map[string]any{"model": "gpt-4o-mini"})
+
+	// Then
+	if byMessage.name != "message" || data.Message != "hello there" || data.Model != "gpt-4o" {
+		t.Errorf("expected the message rule with the last user message, got %s %+v", byMessage.name, data)
+	}
+	if byHeader.name != "header" || fallback.name != "fallback" {
+		t.Errorf("expected header and fallback rules, got %s and %s", byHeader.name, fallback.name)
+	}
+}
+
+func TestScenarioEngine_Match_EndUserIDs(t *testing.T) {
+	// Given: a rule blocking one end-user
+	rules, err := parseScenarios([]byte(`
+scenarios:
+  - name: blocked
+    match: {safety_identifier: u-123}
+    response: {status: 403, error: {code: user_blocked}}
+  - name: legacy
+    match: {user: u-456}
+    response: {content: "hello {{.User}}"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &scenarioEngine{}
+	e.rules.Store(&rules)
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	// When
+	blocked, _, _ := e.Match(r, map[string]any{"safety_identifier": "u-123"})
+	legacy, data, _ := e.Match(r, map[string]any{"user": "u-456"})
+	_, other, matched := e.Match(r, map[string]any{"safety_identifier": "u-1234", "user": "u-4567"})
+	_, diff := e.Closest(r, map[string]any{"safety_identifier": "u-1234"})
+
+	// Then
+	if blocked.name != "blocked" || blocked.err.Detail.Code != "user_blocked" {
+		t.Errorf("expected the blocking rule, got %+v", blocked)
+	}
+	if legacy.name != "legacy" || data.User != "u-456" {
+		t.Errorf("expected the user rule with the user in its data, got %s %+v", legacy.name, data)
+	}
+	if matched || other.SafetyIdentifier != "u-1234" {
+		t.Errorf("expected IDs to match exactly, got %+v", other)
+	}
+	if len(diff) != 1 || diff[0].Field != "safety_identifier" || diff[0].Actual != "u-1234" {
+		t.Errorf("expected a safety_identifier mismatch, got %+v", diff)
+	}
+}
+
+func TestScenarioMessage_PromptAndInput(t *testing.T) {
+	// Given / When / Then
+	if got := scenarioMessage(map[string]any{"prompt": []any{"a", "b"}}); got != "a b" {
+		t.Errorf("expected prompts joined, got %q", got)
+	}
+	if got := scenarioMessage(map[string]any{"input": "text"}); got != "text" {
+		t.Errorf("expected the input, got %q", got)
+	}
+}
+
+func TestScenarioMessage_GeminiContents(t *testing.T) {
+	// Given: a Gemini conversation whose last turn is the model's
+	var doc any
+	_ = json.Unmarshal([]byte(`{"contents":[{"parts":[{"text":"weather"},{"text":"in Tokyo"}]},{"role":"model","parts":[{"text":"sunny"}]}]}`), &doc)
+
+	// When
+	got := scenarioMessage(doc)
+
+	// Then: the last user turn is matched, its role defaulting to user
+	if got != "weather in Tokyo" {
+		t.Errorf("expected the last user turn, got %q", got)
+	}
+}
+
+// --- scenarioRule.apply ---
+
+func TestScenarioRule_Apply_ScriptOverridesResponse(t *testing.T) {
+	// Given: a rule whose script branches on a header and the message
+	rules, err := parseScenarios([]byte(`
+scenarios:
+  - response:
+      content: "static"
+      script: |
+        {{if eq (.Header "X-Plan") "free"}}{{.SetStatus 402 "Upgrade to continue."}}{{end}}
+        {{if contains (lower .Message) "short"}}{{.SetFinishReason "length"}}{{end}}
+        {{.SetContent (printf "%s says %s" .Model (upper .Message))}}
+        {{.SetHeader "X-Plan-Seen" (.Header "X-Plan")}}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	data := scenarioData{Model: "gpt-4o", Message: "Keep it short"}
+
+	// When: applied to a paid request and a free request
+	r.Header.Set("X-Plan", "pro")
+	paid, err := rules[0].apply(r, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("X-Plan", "free")
+	free, err := rules[0].apply(r, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then
+	if paid.Content == nil || *paid.Content != "gpt-4o says KEEP IT SHORT" || paid.FinishReason != "length" || paid.Err != nil {
+		t.Errorf("unexpected paid scenario %+v", paid)
+	}
+	if paid.Header.Get("X-Plan-Seen") != "pro" {
+		t.Errorf("expected the script header, got %v", paid.Header)
+	}
+	if free.Err == nil || free.Err.StatusCode != http.StatusPaymentRequired || free.Err.Detail.Message != "Upgrade to continue." ||
+		free.Err.Detail.Code != "insufficient_quota" {
+		t.Errorf("expected a 402 with the script message, got %+v", free.Err)
+	}
+}
+
+func TestScenarioRule_Apply_ScriptErrors(t *testing.T) {
+	// Given
+	r := httptest.NewRequest(http.MethodPost, "/v1/completions", nil)
+	for _, script := range []string{`{{.SetStatus 200}}`, `{{.SetFinishReason "tool_calls"}}`, `{{.Unknown}}`} {
+		rules, err := parseScenarios([]byte(fmt.Sprintf("scenarios:\n  - response: {script: '%s'}\n", script)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// When
+		_, err = rules[0].apply(r, scenarioData{})
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", script)
+		}
+	}
+}
+
+func TestScenarioRule_Apply_FailingScriptLeavesNoEffect(t *testing.T) {
+	// Given: a script setting the content and a header before it fails
+	rules, err := parseScenarios([]byte(`
+scenarios:
+  - response:
+      content: "static"
+      script: '{{.SetContent "scripted"}}{{.SetHeader "X-Step" "1"}}{{.SetStatus 200}}'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// When
+	matched, err := rules[0].apply(httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil), scenarioData{})
+	// Then: the error is returned, and neither effect before it is applied
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if matched.Content == nil || *matched.Content != "static" || matched.Header != nil {
+		t.Errorf("expected the response before the script, got content %v and headers %v", matched.Content, matched.Header)
+	}
+}
+
+// --- scenarioEngine.Evaluate ---
+
+func TestScenarioEngine_Evaluate_TracesEveryRule(t *testing.T) {
+	// Given: a header rule, a failing rule, and a content rule after it
+	rules, err := parseScenarios([]byte(`
+scenarios:
+  - name: by-header
+    match: {model: gpt-4o-mini, headers: {X-Case: "^a$", X-Other: "^b$"}}
+  - name: overloaded
+    match: {message: "(?i)busy"}
+    response: {status: 503, latency: 250ms, script: '{{.SetHeader "Retry-After" "2"}}'}
+  - name: fallback
+    response: {content: "{{.Choice \"x\" \"y\"}}", map: ["id = \"fixed\""]}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &scenarioEngine{}
+	e.rules.Store(&rules)
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	r.Header.Set("X-Case", "z")
+
+	// When
+	busy := e.Evaluate(r, map[string]any{"model": "gpt-4o", "prompt": "are you busy?"})
+	other := e.Evaluate(r.WithContext(withSeed(r.Context(), 3)), map[string]any{"model": "gpt-4o", "prompt": "hi"})
+
+	// Then: the first match wins, later rules are still traced, and mismatches are explained
+	if busy.Matched == nil || *busy.Matched != "overloaded" || len(busy.Trace) != 3 || !busy.Trace[2].Matched {
+		t.Fatalf("expected overloaded with a full trace, got %+v", busy)
+	}
+	want := []string{`model is "gpt-4o", want "gpt-4o-mini"`, `header X-Case "z" does not match "^a$"`, `header X-Other "" does not match "^b$"`}
+	if got := busy.Trace[0].Reasons; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("expected reasons %q, got %q", want, got)
+	}
+	if resp := busy.Response; resp.Status != 503 || resp.Error == nil || resp.LatencyMs != 250 || resp.Headers.Get("Retry-After") != "2" {
+		t.Errorf("unexpected busy response %+v", resp)
+	}
+	if other.Matched == nil || *other.Matched != "fallback" || other.Seed != 3 || other.Response.Content == nil || len(other.Response.Map) != 1 {
+		t.Errorf("unexpected fallback evaluation %+v", other)
+	}
+	if other.Trace[1].Reasons[0] != `message "hi" does not match "(?i)busy"` {
+		t.Errorf("unexpected message reason %q", other.Trace[1].Reasons)
+	}
+}
+
+// --- scenarioEngine.Closest ---
+
+func TestScenarioEngine_Closest_FewestMismatches(t *testing.T) {
+	// Given: rules differing from the request in two fields, one field, and one field later
+	rules, err := parseScenarios([]byte(`
+scenarios:
+  - name: far
+    match: {model: gpt-4o-mini, path: /v1/embeddings}
+  - name: near
+    match: {model: gpt-4o, headers: {X-Case: "^a$"}}
+  - name: tie
+    match: {message: "^bye$"}
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &scenarioEngine{}
+	e.rules.Store(&rules)
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	r.Header.Set("X-Case", "b")
+
+	// When
+	closest, diff := e.Closest(r, map[string]any{"model": "gpt-4o", "prompt": "hi"})
+	unmatched := newUnmatchedError(r, closest, diff)
+
+	// Then
+	want := []scenarioMismatch{{Field: "header:X-Case", Expected: "^a$", Pattern: true, Actual: "b"}}
+	if closest == nil || closest.name != "near" || !reflect.DeepEqual(diff, want) {
+		t.Fatalf("expected near with %+v, got %v %+v", want, closest, diff)
+	}
+	if unmatched.Error.Code != "scenario_not_matched" || !strings.Contains(unmatched.Error.Message, "near differs in header:X-Case") {
+		t.Errorf("unexpected error %+v", unmatched.Error)
+	}
+}
+
+func TestScenarioEngine_Closest_NoRules(t *testing.T) {
+	// Given
+	e, _ := newScenarioEngine("")
+	r := httptest.NewRequest(http.MethodPost, "/v1/completions", nil)
+	// When
+	closest, diff := e.Closest(r, nil)
+	unmatched := newUnmatchedError(r, closest, diff)
+	// Then
+	if closest != nil || unmatched.Mokku.Closest != nil || unmatched.Mokku.Diff == nil {
+		t.Errorf("expected no closest scenario and an empty diff, got %+v", unmatched)
+	}
+}
+
+// --- unexpectedLog ---
+
+func TestUnexpectedLog_Concurrent_CountsEveryRequestAndKeepsTheLast(t *testing.T) {
+	// Given: a log keeping five requests
+	l := newUnexpectedLog(5)
+	var wg sync.WaitGroup
+	// When: requests are recorded while the log is read concurrently
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				l.Record(unexpectedRequest{Method: http.MethodPost, Path: "/v1/completions"})
+				_ = l.Verification()
+			}
+		}()
+	}
+	wg.Wait()
+	// Then: every request is counted and only the last five are kept
+	if v := l.Verification(); v.Passed || v.UnexpectedRequests != 400 || len(v.Recent) != 5 {
+		t.Errorf("expected 400 requests with 5 kept, got %d with %d kept", v.UnexpectedRequests, len(v.Recent))
+	}
+	l.Reset()
+	if v := l.Verification(); !v.Passed || v.UnexpectedRequests != 0 || len(v.Recent) != 0 {
+		t.Errorf("expected a reset log, got %+v", v)
+	}
+}
+
+// --- scenarioEngine.Load ---
+
+func TestScenarioEngine_Load_KeepsRulesOnError(t *testing.T) {
+	// Given: an engine loaded from a valid file
+	path := filepath.Join(t.TempDir(), "scenarios.yml")
+	if err := os.WriteFile(path, []byte("scenarios:\n  - name: a\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	e, err := newScenarioEngine(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When: the file becomes invalid and is reloaded
+	if err := os.WriteFile(path, []byte("scenarios:\n  - response: {status: 99}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = e.Load(path)
+
+	// Then
+	if err == nil || !strings.Contains(err.Error(), "status") || e.Len() != 1 {
+		t.Errorf("expected an error with the rule kept, got %v with %d rules", err, e.Len())
+	}
+}
+
+func TestScenarioEngine_Load_IncludesAndOverlay(t *testing.T) {
+	// Given: a root file including team files, and a staging overlay
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"scenarios.yml":         "include: [teams/*.yml]\nscenarios:\n  - name: root\n",
+		"scenarios.staging.yml": "scenarios:\n  - name: staging\n",
+		"teams/a.yml":           "scenarios:\n  - name: a\n",
+		"teams/b.yml":           "scenarios:\n  - name: b\n",
+	})
+	t.Setenv(envVar, "staging")
+
+	// When
+	e, err := newScenarioEngine(filepath.Join(dir, "scenarios.yml"))
+
+	// Then: overlay rules come first, then the root's, then the included ones, the last first
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, rule := range *e.rules.Load() {
+		names = append(names, rule.name)
+	}
+	if strings.Join(names, ",") != "staging,root,b,a" {
+		t.Errorf("expected staging,root,b,a, got %v", names)
+	}
+}
+
+// --- scenarioEngine.Replace ---
+
+func TestScenarioEngine_Replace_AppliesAllOrNone(t *testing.T) {
+	// Given: an engine with one rule
+	e, err := newScenarioEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Replace([]byte(`{"scenarios":[{"name":"old"}]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	// When: a set with two invalid rules among valid ones is sent
+	err = e.Replace([]byte("scenarios:\n  - name: a\n  - name: bad-status\n    response: {status: 99}\n  - response: {latency: soon}\n"))
+
+	// Then: both invalid rules are reported and the current rule is kept
+	if err == nil || !strings.Contains(err.Error(), "bad-status") || !strings.Contains(err.Error(), "#2") {
+		t.Errorf("expected both invalid rules to be reported, got %v", err)
+	}
+	if names := e.Names(); strings.Join(names, ",") != "old" {
+		t.Errorf("expected the old rule to be kept, got %v", names)
+	}
+
+	// When: a valid set is sent
+	err = e.Replace([]byte("scenarios:\n  - name: a\n  - {}\n"))
+
+	// Then: it replaces the rules in order, with default names
+	if err != nil || strings.Join(e.Names(), ",") != "a,scenario-1" {
+		t.Errorf("expected a,scenario-1, got %v (%v)", e.Names(), err)
+	}
+	if err := e.Replace([]byte("include: [other.yml]\n")); err == nil {
+		t.Error("expected includes to be rejected")
+	}
+}
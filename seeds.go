@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+)
+
+// seedHeader is the request header pinning the seed of an API request, and the response header
+// reporting the seed a request was served with.
+const seedHeader = "X-Mokku-Seed"
+
+// maxSeed is the largest seed, 2^53-1, so seeds survive tools that parse JSON numbers as floats.
+const maxSeed = 1<<53 - 1
+
+// seedSource assigns every API request the seed its randomized behavior is drawn from. Seeds are
+// derived from a global seed and the request's sequence number, unless the request pins one with
+// seedHeader, so a failure can be reproduced by resending the request with the seed it reported.
+// It is safe for concurrent use.
+type seedSource struct {
+	global uint64
+	next   atomic.Uint64
+}
+
+// newSeedSource creates a source deriving request seeds from global.
+func newSeedSource(global uint64) *seedSource {
+	return &seedSource{global: global}
+}
+
+// seedFromEnv parses MOKKU_SEED. Without it a random seed is chosen, which is logged at startup so
+// the run can be repeated.
+func seedFromEnv(value string) (uint64, error) {
+	if value == "" {
+		return rand.Uint64N(maxSeed + 1), nil
+	}
+	seed, err := parseSeed(value)
+	if err != nil {
+		return 0, fmt.Errorf("MOKKU_SEED %w", err)
+	}
+	return seed, nil
+}
+
+// parseSeed parses a decimal seed.
+func parseSeed(value string) (uint64, error) {
+	seed, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || seed > maxSeed {
+		return 0, fmt.Errorf("must be an integer between 0 and %d, got %q", uint64(maxSeed), value)
+	}
+	return seed, nil
+}
+
+// Global returns the seed request seeds are derived from.
+func (s *seedSource) Global() uint64 {
+	return s.global
+}
+
+// Seed returns the seed of a request: the one pinned by its seedHeader, or the next seed derived
+// from the global seed.
+func (s *seedSource) Seed(r *http.Request) (uint64, error) {
+	if value := r.Header.Get(seedHeader); value != "" {
+		seed, err := parseSeed(value)
+		if err != nil {
+			return 0, fmt.Errorf("%s %w", seedHeader, err)
+		}
+		return seed, nil
+	}
+	return mixSeed(s.global+s.next.Add(1)) & maxSeed, nil
+}
+
+// mixSeed scrambles x with the SplitMix64 finalizer, so consecutive inputs yield unrelated seeds.
+func mixSeed(x uint64) uint64 {
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
+
+// seededRand returns the random source of one randomized behavior of a request. Each behavior
+// draws from its own source, so draws added for one behavior do not change the others.
+func seededRand(seed uint64, behavior string) *rand.Rand {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(behavior))
+	return rand.New(rand.NewPCG(seed, h.Sum64()))
+}
+
+type seedContextKey struct{}
+
+// withSeed returns a context carrying the seed of a request.
+func withSeed(ctx context.Context, seed uint64) context.Context {
+	return context.WithValue(ctx, seedContextKey{}, seed)
+}
+
+// seedFromContext returns the seed of a request.
+func seedFromContext(ctx context.Context) (uint64, bool) {
+	seed, ok := ctx.Value(seedContextKey{}).(uint64)
+	return seed, ok
+}
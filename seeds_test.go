@@ -0,0 +1,122 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// --- seedFromEnv ---
+
+func TestSeedFromEnv(t *testing.T) {
+	cases := map[string]struct {
+		value   string
+		want    uint64
+		wantErr bool
+	}{
+		"zero":      {"0", 0, false},
+		"custom":    {"12345", 12345, false},
+		"largest":   {"9007199254740991", maxSeed, false},
+		"too large": {"9007199254740992", 0, true},
+		"negative":  {"-1", 0, true},
+		"invalid":   {"lucky", 0, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			// When
+			got, err := seedFromEnv(tc.value)
+			// Then
+			if (err != nil) != tc.wantErr || got != tc.want {
+				t.Errorf("expected %d (error %t), got %d, %v", tc.want, tc.wantErr, got, err)
+			}
+		})
+	}
+}
+
+func TestSeedFromEnv_UnsetChoosesSeed(t *testing.T) {
+	// When
+	seed, err := seedFromEnv("")
+	// Then
+	if err != nil || seed > maxSeed {
+		t.Errorf("expected a seed up to %d, got %d, %v", uint64(maxSeed), seed, err)
+	}
+}
+
+// --- seedSource.Seed ---
+
+func TestSeedSource_Seed_DerivesFromGlobalSeed(t *testing.T) {
+	// Given: two sources with the same global seed and one with another
+	a, b, other := newSeedSource(42), newSeedSource(42), newSeedSource(43)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	seen := map[uint64]bool{}
+	for range 10 {
+		// When
+		seedA, _ := a.Seed(req)
+		seedB, _ := b.Seed(req)
+		seedOther, _ := other.Seed(req)
+		// Then: the sequences match, differ from the other source, and do not repeat
+		if seedA != seedB || seedA == seedOther || seedA > maxSeed {
+			t.Fatalf("unexpected seeds %d %d %d", seedA, seedB, seedOther)
+		}
+		if seen[seedA] {
+			t.Fatalf("seed %d repeated", seedA)
+		}
+		seen[seedA] = true
+	}
+}
+
+func TestSeedSource_Seed_HeaderPinsSeed(t *testing.T) {
+	// Given
+	s := newSeedSource(42)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	req.Header.Set(seedHeader, "777")
+	// When
+	seed, err := s.Seed(req)
+	// Then
+	if err != nil || seed != 777 {
+		t.Errorf("expected 777, got %d, %v", seed, err)
+	}
+	// When / Then: an invalid header is an error
+	req.Header.Set(seedHeader, "abc")
+	if _, err := s.Seed(req); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSeededRand_SeparatesBehaviors(t *testing.T) {
+	// When
+	a, b, c := seededRand(1, "chaos"), seededRand(1, "chaos"), seededRand(1, "other")
+	// Then
+	x, y, z := a.Uint64(), b.Uint64(), c.Uint64()
+	if x != y || x == z {
+		t.Errorf("expected equal draws for the same behavior only, got %d %d %d", x, y, z)
+	}
+}
+
+func TestSeedSource_Concurrent_DerivesUniqueSeeds(t *testing.T) {
+	// Given
+	s := newSeedSource(42)
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := map[uint64]bool{}
+	// When: seeds are derived concurrently
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				seed, _ := s.Seed(req)
+				mu.Lock()
+				seen[seed] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	// Then
+	if len(seen) != 800 {
+		t.Errorf("expected 800 unique seeds, got %d", len(seen))
+	}
+}
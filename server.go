@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"openai-mokku/api"
+)
+
+// serverState is the shared state behind the request handlers and the runtime controls. It is
+// embedded by AdminHandler, StreamingHandler, and runtimeControls, and built by newServerState for
+// main and the tests alike, so the handlers are always wired the same way.
+type serverState struct {
+	flags       *featureFlags
+	models      *modelCatalog
+	moderation  *moderationFilter
+	limiter     *rateLimiter
+	scenarios   *scenarioEngine
+	regions     *regionRouter
+	chaos       *chaosEngine
+	timeline    *faultTimeline
+	coldStart   *coldStartTracker
+	cadence     *cadenceTable
+	fidelity    *fidelityTable
+	overhead    *overheadTracker
+	tags        *requestTagger
+	alerts      *alertMonitor
+	proxies     *trustedProxies
+	auth        *adminAuth
+	seeds       *seedSource
+	embeddings  *embeddingIndex
+	clock       *virtualClock
+	images      *imageStore
+	streams     *streamLog
+	capture     *requestCapture
+	connections *connectionTracker
+	audit       *auditLog
+	// bans is the denylist of moderation, served by the control API.
+	bans *userDenylist
+	// watermark is the watermark mode of API responses.
+	watermark string
+}
+
+// serverOptions are the settings taken from the environment rather than the config file.
+type serverOptions struct {
+	// ScenariosPath is the MOKKU_SCENARIOS file; empty for none.
+	ScenariosPath string
+	// Features is MOKKU_FEATURES.
+	Features string
+	// AdminToken is MOKKU_ADMIN_TOKEN.
+	AdminToken string
+	Capture    captureConfig
+	// Seed is MOKKU_SEED.
+	Seed uint64
+	// Watermark is MOKKU_WATERMARK; empty for off.
+	Watermark string
+}
+
+// newServerState creates the shared state for a config file and the environment settings.
+func newServerState(cfg Config, opts serverOptions) (*serverState, error) {
+	s := &serverState{moderation: newModerationFilter(cfg.Moderation)}
+	var err error
+	if s.flags, err = newFeatureFlags(cfg.Features, opts.Features); err != nil {
+		return nil, fmt.Errorf("failed to resolve feature flags: %w", err)
+	}
+	if s.models, err = newModelCatalog(cfg.Models); err != nil {
+		return nil, fmt.Errorf("failed to load model metadata: %w", err)
+	}
+	if s.limiter, err = newRateLimiter(cfg.RateLimits); err != nil {
+		return nil, fmt.Errorf("failed to load rate limits: %w", err)
+	}
+	if s.regions, err = newRegionRouter(cfg.Regions); err != nil {
+		return nil, fmt.Errorf("failed to load regions: %w", err)
+	}
+	if s.chaos, err = newChaosEngine(cfg.Chaos); err != nil {
+		return nil, fmt.Errorf("failed to load chaos profiles: %w", err)
+	}
+	s.timeline = newFaultTimeline()
+	s.regions.Observe(s.timeline)
+	s.chaos.Observe(s.timeline)
+	if s.coldStart, err = newColdStartTracker(cfg.ColdStart); err != nil {
+		return nil, fmt.Errorf("failed to load cold starts: %w", err)
+	}
+	if s.cadence, err = newCadenceTable(cfg.StreamingCadence); err != nil {
+		return nil, fmt.Errorf("failed to load streaming cadences: %w", err)
+	}
+	if s.fidelity, err = newFidelityTable(cfg.EndpointFidelity); err != nil {
+		return nil, fmt.Errorf("failed to load endpoint fidelity: %w", err)
+	}
+	if s.overhead, err = newOverheadTracker(cfg.OverheadSLO); err != nil {
+		return nil, fmt.Errorf("failed to load overhead SLOs: %w", err)
+	}
+	if s.tags, err = newRequestTagger(cfg.Tags); err != nil {
+		return nil, fmt.Errorf("failed to load tag rules: %w", err)
+	}
+	if s.alerts, err = newAlertMonitor(cfg.Alerts); err != nil {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+	if s.proxies, err = newTrustedProxies(cfg.Proxies); err != nil {
+		return nil, fmt.Errorf("failed to load trusted proxies: %w", err)
+	}
+	if s.scenarios, err = newScenarioEngine(opts.ScenariosPath); err != nil {
+		return nil, fmt.Errorf("failed to load scenarios: %w", err)
+	}
+	if s.auth, err = newAdminAuth(cfg.Admin, opts.AdminToken); err != nil {
+		return nil, fmt.Errorf("failed to load admin tokens: %w", err)
+	}
+	restoreWindow, err := parseRestoreWindow(cfg.Admin.RestoreWindow)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin restore window: %w", err)
+	}
+	s.scenarios.SetRestoreWindow(restoreWindow)
+	s.seeds = newSeedSource(opts.Seed)
+	s.embeddings = newEmbeddingIndex()
+	s.clock = newVirtualClock()
+	s.images = newImageStore(s.clock)
+	s.streams = newStreamLog()
+	s.capture = newRequestCapture(opts.Capture)
+	s.connections = newConnectionTracker()
+	s.audit = newAuditLog()
+	s.bans = s.moderation.users
+	if s.watermark = opts.Watermark; s.watermark == "" {
+		s.watermark = watermarkOff
+	}
+	return s, nil
+}
+
+// newHandler creates the ogen server for the mock endpoints, the control API, and the
+// StreamingHandler in front of them. It returns the handler serving every request, which resolves
+// client addresses through trusted proxies and counts requests per connection, and the control API.
+func (s *serverState) newHandler(instanceID string) (http.Handler, *AdminHandler, error) {
+	handler := &MockHandler{embeddings: s.embeddings, images: s.images, flags: s.flags, models: s.models}
+	// ogen automatically uses the global tracer provider set by otel.SetTracerProvider
+	ogenServer, err := api.NewServer(handler,
+		api.WithPathPrefix("/v1"),
+		api.WithErrorHandler(handleAPIError),
+		api.WithMiddleware(linkRequestSpansMiddleware),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	admin := NewAdminHandler(s, instanceID)
+	streaming := NewStreamingHandler(ogenServer, handler, admin, s)
+	return withConnectionTracking(s.connections, withClientAddr(s.proxies, streaming)), admin, nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// runtimeControls is the state that operators act on at runtime: reloading the config file and
+// dumping the current state to the log. How they are triggered is platform specific, see
+// waitForShutdown in signals_unix.go and signals_windows.go.
+type runtimeControls struct {
+	*serverState
+	configPath    string
+	scenariosPath string
+	startedAt     time.Time
+	// handedOff is closed once a new process took over (see listenHandoff); nil without
+	// MOKKU_HANDOFF_SOCKET.
+	handedOff <-chan struct{}
+}
+
+// reload re-reads the config file, MOKKU_FEATURES, and the scenario file. Every section is
+// validated before any is installed, so a reload applies in full or not at all: on error the running
+// configuration is kept.
+func (c *runtimeControls) reload() {
+	cfg, err := loadConfig(c.configPath)
+	if err != nil {
+		log.Printf("Config reload failed, keeping the current configuration: %v", err)
+		return
+	}
+	sections := []struct {
+		name    string
+		prepare func() (func(), error)
+	}{
+		{"Feature flag", func() (func(), error) { return c.flags.prepare(cfg.Features, os.Getenv("MOKKU_FEATURES")) }},
+		{"Model metadata", func() (func(), error) { return c.models.prepare(cfg.Models) }},
+		{"Admin token", func() (func(), error) { return c.auth.prepare(cfg.Admin, os.Getenv("MOKKU_ADMIN_TOKEN")) }},
+		{"Restore window", func() (func(), error) {
+			window, err := parseRestoreWindow(cfg.Admin.RestoreWindow)
+			return func() { c.scenarios.SetRestoreWindow(window) }, err
+		}},
+		{"Rate limit", func() (func(), error) { return c.limiter.prepare(cfg.RateLimits) }},
+		{"Region", func() (func(), error) { return c.regions.prepare(cfg.Regions) }},
+		{"Chaos profile", func() (func(), error) { return c.chaos.prepare(cfg.Chaos) }},
+		{"Cold start", func() (func(), error) { return c.coldStart.prepare(cfg.ColdStart) }},
+		{"Streaming cadence", func() (func(), error) { return c.cadence.prepare(cfg.StreamingCadence) }},
+		{"Endpoint fidelity", func() (func(), error) { return c.fidelity.prepare(cfg.EndpointFidelity) }},
+		{"Overhead SLO", func() (func(), error) { return c.overhead.prepare(cfg.OverheadSLO) }},
+		{"Tag rule", func() (func(), error) { return c.tags.prepare(cfg.Tags) }},
+		{"Alert", func() (func(), error) { return c.alerts.prepare(cfg.Alerts) }},
+		{"Trusted proxy", func() (func(), error) { return c.proxies.prepare(cfg.Proxies) }},
+		{"Scenario", func() (func(), error) { return c.scenarios.prepare(c.scenariosPath) }},
+	}
+	installs := make([]func(), 0, len(sections))
+	for _, section := range sections {
+		install, err := section.prepare()
+		if err != nil {
+			log.Printf("%s reload failed, keeping the current configuration: %v", section.name, err)
+			return
+		}
+		installs = append(installs, install)
+	}
+	for _, install := range installs {
+		install()
+	}
+	c.moderation.Load(cfg.Moderation)
+	if c.scenariosPath != "" {
+		log.Printf("Scenarios reloaded from %s (%d rules)", c.scenariosPath, c.scenarios.Len())
+	}
+	if c.configPath == "" {
+		log.Println("Feature flags reloaded from MOKKU_FEATURES (MOKKU_CONFIG is not set)")
+		return
+	}
+	log.Printf("Config reloaded from %s", c.configPath)
+}
+
+// dumpState logs active streams, stored state, enabled feature flags, and memory usage.
+func (c *runtimeControls) dumpState() {
+	stats := c.streams.Stats()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	var enabled []string
+	for _, status := range c.flags.Status() {
+		if status.Enabled {
+			enabled = append(enabled, status.Name)
+		}
+	}
+
+	log.Printf("State dump (uptime %s):", time.Since(c.startedAt).Round(time.Second))
+	log.Printf("  streams: %d active, %d started, %d completed, %d cancelled",
+		stats.Started-stats.Completed-stats.Cancelled, stats.Started, stats.Completed, stats.Cancelled)
+	log.Printf("  stores: %d indexed embeddings, %d stored images, %d captured requests", c.embeddings.Len(), c.images.Len(), c.capture.Len())
+	log.Printf("  feature flags enabled: [%s]", strings.Join(enabled, ", "))
+	log.Printf("  memory: %d MiB heap in use, %d MiB from OS, %d goroutines, %d GC cycles",
+		mem.HeapInuse>>20, mem.Sys>>20, runtime.NumGoroutine(), mem.NumGC)
+}
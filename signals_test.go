@@ -0,0 +1,198 @@
+package main
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestControls returns runtime controls over fresh state and a config file at the returned path.
+func newTestControls(t *testing.T, config string) (*runtimeControls, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mokku.yml")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state, err := newServerState(Config{}, serverOptions{Capture: captureConfig{Size: defaultCaptureSize}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &runtimeControls{serverState: state, configPath: path, startedAt: time.Now()}, path
+}
+
+// captureLog redirects the standard logger for the duration of the test.
+func captureLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+	return &buf
+}
+
+// --- runtimeControls.reload ---
+
+func TestRuntimeControls_Reload_AppliesConfigAndDropsAdminToggles(t *testing.T) {
+	// Given: an admin toggle, then the config file enabling the flag
+	t.Setenv("MOKKU_FEATURES", "")
+	c, path := newTestControls(t, "version: 1\n")
+	_ = c.flags.Set(flagStrictModelValidation, true, flagSourceAdmin)
+	if err := os.WriteFile(path, []byte("version: 1\nfeatures:\n  strict_model_validation: false\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_ = captureLog(t)
+	// When
+	c.reload()
+	// Then: the config file value wins over the earlier admin toggle
+	status := flagStatus(t, c.flags, flagStrictModelValidation)
+	if status.Enabled || status.Source != flagSourceConfig {
+		t.Errorf("expected disabled from config, got %+v", status)
+	}
+}
+
+func TestRuntimeControls_Reload_InvalidConfigKeepsCurrent(t *testing.T) {
+	// Given: flags enabled, then a config naming an unknown flag
+	t.Setenv("MOKKU_FEATURES", "")
+	c, path := newTestControls(t, "version: 1\n")
+	_ = c.flags.Set(flagStrictModelValidation, true, flagSourceAdmin)
+	if err := os.WriteFile(path, []byte("version: 1\nfeatures:\n  no_such_flag: true\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logs := captureLog(t)
+	// When
+	c.reload()
+	// Then
+	if !c.flags.Enabled(flagStrictModelValidation) {
+		t.Error("expected the running flag values to be kept")
+	}
+	if !strings.Contains(logs.String(), "reload failed") {
+		t.Errorf("expected a reload failure log, got %q", logs.String())
+	}
+}
+
+func TestRuntimeControls_Reload_LateFailureInstallsNothing(t *testing.T) {
+	// Given: a config file changing flags, fidelity, cadence, and the restore window, whose trusted
+	// proxies, validated near the end, are invalid
+	t.Setenv("MOKKU_FEATURES", "")
+	c, path := newTestControls(t, "version: 1\n")
+	config := `version: 1
+features:
+  strict_model_validation: true
+endpoint_fidelity:
+  /v1/embeddings: disabled
+streaming_cadence:
+  - name: custom
+    models: ["custom-*"]
+    tokens_per_second: 10
+admin:
+  restore_window: 0s
+proxies:
+  trusted: ["not-an-address"]
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logs := captureLog(t)
+
+	// When
+	c.reload()
+
+	// Then: none of the earlier sections is installed
+	if c.flags.Enabled(flagStrictModelValidation) {
+		t.Error("expected the flags to be kept")
+	}
+	if level := c.fidelity.Level("POST /v1/embeddings"); level != "" {
+		t.Errorf("expected the fidelity levels to be kept, got %q", level)
+	}
+	if cadence, _ := c.cadence.For("custom-1"); cadence.family == "custom" {
+		t.Error("expected the cadence table to be kept")
+	}
+	if c.scenarios.trash.window != defaultRestoreWindow {
+		t.Errorf("expected the restore window to be kept, got %s", c.scenarios.trash.window)
+	}
+	if !strings.Contains(logs.String(), "Trusted proxy reload failed") {
+		t.Errorf("expected the failing section in the log, got %q", logs.String())
+	}
+}
+
+// --- runtimeControls.dumpState ---
+
+func TestRuntimeControls_DumpState_LogsStreamsAndStores(t *testing.T) {
+	// Given: one active stream and one stored image
+	c, _ := newTestControls(t, "version: 1\n")
+	c.streams.Started()
+	c.images.Put("img", []byte{1})
+	logs := captureLog(t)
+	// When
+	c.dumpState()
+	// Then
+	out := logs.String()
+	for _, want := range []string{"1 active", "1 stored images", "0 indexed embeddings", "goroutines"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in state dump, got %q", want, out)
+		}
+	}
+}
+
+func TestRuntimeControls_Reload_AppliesModelMetadata(t *testing.T) {
+	// Given: a config file that adds a model
+	t.Setenv("MOKKU_FEATURES", "")
+	c, _ := newTestControls(t, "version: 1\nmodels:\n  - id: ft:gpt-4o:acme\n    context_window: 8192\n")
+	_ = captureLog(t)
+	// When
+	c.reload()
+	// Then
+	m, ok := c.models.Get("ft:gpt-4o:acme")
+	if !ok || m.ContextWindow != 8192 {
+		t.Errorf("expected the configured model after reload, got %+v, %v", m, ok)
+	}
+}
+
+func TestRuntimeControls_Reload_AppliesScenarios(t *testing.T) {
+	// Given: a scenario file written after startup
+	t.Setenv("MOKKU_FEATURES", "")
+	c, _ := newTestControls(t, "version: 1\n")
+	c.scenariosPath = filepath.Join(t.TempDir(), "scenarios.yml")
+	if err := os.WriteFile(c.scenariosPath, []byte("scenarios:\n  - name: a\n  - name: b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	logs := captureLog(t)
+	// When
+	c.reload()
+	// Then
+	if c.scenarios.Len() != 2 || !strings.Contains(logs.String(), "(2 rules)") {
+		t.Errorf("expected 2 scenarios reloaded, got %d: %q", c.scenarios.Len(), logs.String())
+	}
+}
+
+func TestRuntimeControls_Reload_AppliesRegions(t *testing.T) {
+	// Given: a config file with a region outage and a region set at runtime
+	t.Setenv("MOKKU_FEATURES", "")
+	c, _ := newTestControls(t, "version: 1\nregions:\n  - name: eu\n    status: 503\n")
+	_, _ = c.regions.Set(regionConfig{Name: "us", Status: 500})
+	_ = captureLog(t)
+	// When
+	c.reload()
+	// Then: only the configured region remains
+	statuses := c.regions.Status()
+	if len(statuses) != 1 || statuses[0].Name != "eu" || statuses[0].Status != 503 {
+		t.Errorf("expected the configured eu region after reload, got %+v", statuses)
+	}
+}
+
+func TestRuntimeControls_Reload_AppliesChaosProfile(t *testing.T) {
+	// Given: a config file activating a chaos profile and a different profile activated at runtime
+	t.Setenv("MOKKU_FEATURES", "")
+	c, _ := newTestControls(t, "version: 1\nchaos:\n  profile: provider-incident\n")
+	_, _ = c.chaos.Activate("flaky-network")
+	_ = captureLog(t)
+	// When
+	c.reload()
+	// Then
+	if status := c.chaos.Status(); status.Active != "provider-incident" || status.Source != chaosSourceConfig {
+		t.Errorf("expected the configured profile after reload, got %+v", status)
+	}
+}
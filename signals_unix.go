@@ -0,0 +1,35 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// configurePlatformLogging is a no-op outside Windows; logs go to stderr.
+func configurePlatformLogging() {}
+
+// waitForShutdown handles SIGHUP (reload) and SIGUSR1 (state dump) until SIGINT or SIGTERM is received
+// or a new process took over.
+func (c *runtimeControls) waitForShutdown() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGUSR1)
+	defer signal.Stop(sigs)
+	for {
+		select {
+		case sig := <-sigs:
+			switch sig {
+			case syscall.SIGHUP:
+				c.reload()
+			case syscall.SIGUSR1:
+				c.dumpState()
+			default:
+				return
+			}
+		case <-c.handedOff:
+			return
+		}
+	}
+}
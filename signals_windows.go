@@ -0,0 +1,96 @@
+//go:build windows
+
+package main
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// windowsServiceName is the name passed to the service control manager.
+const windowsServiceName = "openai-mokku"
+
+// serviceControlDumpState is the user-defined service control code that dumps the state to the log
+// (sc control openai-mokku 128), standing in for SIGUSR1.
+const serviceControlDumpState = svc.Cmd(128)
+
+// isWindowsService reports whether the process was started by the service control manager.
+func isWindowsService() bool {
+	service, err := svc.IsWindowsService()
+	if err != nil {
+		log.Printf("Warning: failed to detect Windows service mode: %v", err)
+		return false
+	}
+	return service
+}
+
+// configurePlatformLogging sends logs to a file when running as a Windows service, since services
+// have no console: MOKKU_LOG_FILE if set, otherwise openai-mokku.log next to the executable.
+func configurePlatformLogging() {
+	if !isWindowsService() {
+		return
+	}
+	path := os.Getenv("MOKKU_LOG_FILE")
+	if path == "" {
+		exe, err := os.Executable()
+		if err != nil {
+			return
+		}
+		path = filepath.Join(filepath.Dir(exe), windowsServiceName+".log")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	log.SetOutput(f)
+}
+
+// waitForShutdown blocks until the server should shut down. As a Windows service it answers the
+// service control manager: stop and shutdown end the service, paramchange reloads the config, and
+// control code 128 dumps the state. From a console, Ctrl+C, Ctrl+Break, and closing the window shut
+// down; Windows has no equivalent of SIGHUP or SIGUSR1.
+func (c *runtimeControls) waitForShutdown() {
+	if isWindowsService() {
+		if err := svc.Run(windowsServiceName, &windowsService{controls: c}); err != nil {
+			log.Printf("Windows service failed: %v", err)
+		}
+		return
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+	<-sigs
+}
+
+// windowsService adapts runtimeControls to the service control manager.
+type windowsService struct {
+	controls *runtimeControls
+}
+
+// Execute implements svc.Handler
+func (s *windowsService) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptParamChange
+	status <- svc.Status{State: svc.Running, Accepts: accepted}
+	for req := range requests {
+		switch req.Cmd {
+		case svc.Interrogate:
+			status <- req.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case svc.ParamChange:
+			s.controls.reload()
+		case serviceControlDumpState:
+			s.controls.dumpState()
+		default:
+			log.Printf("Ignoring unexpected service control request %d", req.Cmd)
+		}
+	}
+	return false, 0
+}
@@ -0,0 +1,142 @@
+package main
+
+import "sync"
+
+// boundedMap is a map that keeps at most capacity entries, evicting the oldest insertion first.
+// It is safe for concurrent use. Invariants (checked by tests): every key in order is present
+// in items and vice versa, and len(order) never exceeds capacity.
+type boundedMap[K comparable, V any] struct {
+	mu       sync.RWMutex
+	capacity int
+	items    map[K]V
+	order    []K
+}
+
+// newBoundedMap creates an empty map holding at most capacity entries.
+func newBoundedMap[K comparable, V any](capacity int) *boundedMap[K, V] {
+	return &boundedMap[K, V]{capacity: capacity, items: make(map[K]V)}
+}
+
+// Put stores value under key. Storing an existing key replaces its value without changing its
+// eviction order. It reports whether the key was newly added.
+func (m *boundedMap[K, V]) Put(key K, value V) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[key]; ok {
+		m.items[key] = value
+		return false
+	}
+	m.items[key] = value
+	m.order = append(m.order, key)
+	if len(m.order) > m.capacity {
+		delete(m.items, m.order[0])
+		var zero K
+		m.order[0] = zero
+		m.order = m.order[1:]
+	}
+	return true
+}
+
+// Get returns the value stored under key.
+func (m *boundedMap[K, V]) Get(key K) (V, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.items[key]
+	return value, ok
+}
+
+// Delete removes key and reports whether it was stored.
+func (m *boundedMap[K, V]) Delete(key K) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.items[key]; !ok {
+		return false
+	}
+	delete(m.items, key)
+	for i, k := range m.order {
+		if k == key {
+			m.order = append(m.order[:i], m.order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Values returns a snapshot of the stored values, oldest first.
+func (m *boundedMap[K, V]) Values() []V {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([]V, len(m.order))
+	for i, key := range m.order {
+		values[i] = m.items[key]
+	}
+	return values
+}
+
+// Len returns the number of stored entries.
+func (m *boundedMap[K, V]) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.order)
+}
+
+// Clear removes all entries.
+func (m *boundedMap[K, V]) Clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items = make(map[K]V)
+	m.order = nil
+}
+
+// ringBuffer keeps the most recent capacity items, overwriting the oldest.
+// It is safe for concurrent use.
+type ringBuffer[T any] struct {
+	mu    sync.Mutex
+	items []T
+	start int
+	size  int
+}
+
+// newRingBuffer creates an empty ring buffer holding at most capacity items.
+func newRingBuffer[T any](capacity int) *ringBuffer[T] {
+	return &ringBuffer[T]{items: make([]T, capacity)}
+}
+
+// Push appends an item, overwriting the oldest item once the buffer is full.
+func (b *ringBuffer[T]) Push(item T) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.size < len(b.items) {
+		b.items[(b.start+b.size)%len(b.items)] = item
+		b.size++
+		return
+	}
+	b.items[b.start] = item
+	b.start = (b.start + 1) % len(b.items)
+}
+
+// Snapshot returns the buffered items, oldest first.
+func (b *ringBuffer[T]) Snapshot() []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	items := make([]T, b.size)
+	for i := range items {
+		items[i] = b.items[(b.start+i)%len(b.items)]
+	}
+	return items
+}
+
+// Len returns the number of buffered items.
+func (b *ringBuffer[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+// Clear removes all items.
+func (b *ringBuffer[T]) Clear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	clear(b.items)
+	b.start, b.size = 0, 0
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// checkBoundedMapInvariants fails the test if order and items disagree or capacity is exceeded.
+func checkBoundedMapInvariants[K comparable, V any](t *testing.T, m *boundedMap[K, V]) {
+	t.Helper()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.order) != len(m.items) {
+		t.Fatalf("order has %d keys but items has %d", len(m.order), len(m.items))
+	}
+	if len(m.order) > m.capacity {
+		t.Fatalf("%d entries exceed capacity %d", len(m.order), m.capacity)
+	}
+	for _, key := range m.order {
+		if _, ok := m.items[key]; !ok {
+			t.Fatalf("key %v is ordered but not stored", key)
+		}
+	}
+}
+
+// --- boundedMap ---
+
+func TestBoundedMap_Put_EvictsOldest(t *testing.T) {
+	// Given: a map of capacity 2
+	m := newBoundedMap[string, int](2)
+	// When: three keys are stored
+	m.Put("a", 1)
+	m.Put("b", 2)
+	m.Put("c", 3)
+	// Then: the oldest key is gone
+	if _, ok := m.Get("a"); ok {
+		t.Error("expected a to be evicted")
+	}
+	if got := m.Values(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("expected [2 3], got %v", got)
+	}
+	checkBoundedMapInvariants(t, m)
+}
+
+func TestBoundedMap_Put_ExistingKeyKeepsOrder(t *testing.T) {
+	// Given
+	m := newBoundedMap[string, int](2)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	// When: a is replaced, then c is added
+	added := m.Put("a", 10)
+	m.Put("c", 3)
+	// Then: a was not re-added and is still evicted first
+	if added {
+		t.Error("expected replacing a key not to report an addition")
+	}
+	if _, ok := m.Get("a"); ok {
+		t.Error("expected a to be evicted")
+	}
+	checkBoundedMapInvariants(t, m)
+}
+
+func TestBoundedMap_Delete_RemovesKey(t *testing.T) {
+	// Given
+	m := newBoundedMap[string, int](2)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	// When: a is deleted, a missing key is deleted, and c is added
+	deleted := m.Delete("a")
+	missing := m.Delete("x")
+	m.Put("c", 3)
+	// Then: b is the oldest entry left
+	if !deleted || missing {
+		t.Errorf("expected only a to be deleted, got %v and %v", deleted, missing)
+	}
+	if got := m.Values(); len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("expected [2 3], got %v", got)
+	}
+	checkBoundedMapInvariants(t, m)
+}
+
+func TestBoundedMap_Clear_RemovesAll(t *testing.T) {
+	// Given
+	m := newBoundedMap[string, int](2)
+	m.Put("a", 1)
+	// When
+	m.Clear()
+	// Then
+	if m.Len() != 0 {
+		t.Errorf("expected empty map, got %d entries", m.Len())
+	}
+	checkBoundedMapInvariants(t, m)
+}
+
+func TestBoundedMap_Concurrent_KeepsInvariants(t *testing.T) {
+	// Given: a small map hammered by writers, readers, and clears
+	m := newBoundedMap[string, int](16)
+	var wg sync.WaitGroup
+	// When
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("%d-%d", w, i%40)
+				m.Put(key, i)
+				m.Get(key)
+				_ = m.Values()
+				if i%7 == 0 {
+					m.Delete(key)
+				}
+				if i%100 == 99 {
+					m.Clear()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// Then
+	checkBoundedMapInvariants(t, m)
+}
+
+// --- ringBuffer ---
+
+func TestRingBuffer_Push_OverwritesOldest(t *testing.T) {
+	// Given: a buffer of capacity 3
+	b := newRingBuffer[int](3)
+	// When: five items are pushed
+	for i := 1; i <= 5; i++ {
+		b.Push(i)
+	}
+	// Then: the last three remain, oldest first
+	if got := b.Snapshot(); len(got) != 3 || got[0] != 3 || got[1] != 4 || got[2] != 5 {
+		t.Errorf("expected [3 4 5], got %v", got)
+	}
+	if b.Len() != 3 {
+		t.Errorf("expected Len 3, got %d", b.Len())
+	}
+}
+
+func TestRingBuffer_Clear_RemovesAll(t *testing.T) {
+	// Given
+	b := newRingBuffer[int](3)
+	b.Push(1)
+	// When
+	b.Clear()
+	b.Push(2)
+	// Then
+	if got := b.Snapshot(); len(got) != 1 || got[0] != 2 {
+		t.Errorf("expected [2], got %v", got)
+	}
+}
+
+func TestRingBuffer_Concurrent_NeverExceedsCapacity(t *testing.T) {
+	// Given
+	b := newRingBuffer[int](10)
+	var wg sync.WaitGroup
+	// When: many goroutines push and snapshot
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				b.Push(i)
+				if n := len(b.Snapshot()); n > 10 {
+					t.Errorf("snapshot of %d items exceeds capacity", n)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// Then
+	if n := len(b.Snapshot()); n != 10 {
+		t.Errorf("expected a full buffer, got %d items", n)
+	}
+}
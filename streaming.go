@@ -2,16 +2,24 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
+	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"openai-mokku/api"
 
 	"github.com/google/uuid"
+	"github.com/ogen-go/ogen/ogenerrors"
 	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // CreditErrorModelName is the model name that triggers a 402 credit error
@@ -19,6 +27,8 @@ const CreditErrorModelName = "credit-error"
 
 const chatCompletionChunkObject = "chat.completion.chunk"
 
+const completionChunkObject = "text_completion"
+
 // modelRequest is used to extract just the model field from any completion request
 type modelRequest struct {
 	Model string `json:"model"`
@@ -61,6 +71,45 @@ type OpenAIErrorDetail struct {
 	Code    string  `json:"code"`
 }
 
+// APIError is an error returned by MockHandler that is rendered as an OpenAI API error response
+type APIError struct {
+	StatusCode int
+	Detail     OpenAIErrorDetail
+}
+
+// Error implements error
+func (e *APIError) Error() string {
+	return e.Detail.Message
+}
+
+// newInvalidRequestError creates a 400 invalid_request_error for the given request parameter
+func newInvalidRequestError(param, message string) *APIError {
+	return &APIError{
+		StatusCode: http.StatusBadRequest,
+		Detail: OpenAIErrorDetail{
+			Message: message,
+			Type:    "invalid_request_error",
+			Param:   &param,
+			Code:    "invalid_value",
+		},
+	}
+}
+
+// handleAPIError is the ogen error handler. APIErrors are written in the OpenAI error format,
+// all other errors are handled by ogen's default error handler.
+func handleAPIError(ctx context.Context, w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if route, ok := dialectFromContext(r.Context()); ok {
+			route.dialect.writeError(w, apiErr)
+			return
+		}
+		writeOpenAIError(w, apiErr.StatusCode, apiErr.Detail)
+		return
+	}
+	ogenerrors.DefaultErrorHandler(ctx, w, r, err)
+}
+
 // ChatCompletionChunk represents a streaming response chunk
 type ChatCompletionChunk struct {
 	ID                string                      `json:"id"`
@@ -80,24 +129,275 @@ type ChatCompletionChunkChoice struct {
 
 // ChatCompletionChunkDelta represents the delta content in a streaming chunk
 type ChatCompletionChunkDelta struct {
-	Role    string `json:"role,omitempty"`
-	Content string `json:"content,omitempty"`
+	Role      string                        `json:"role,omitempty"`
+	Content   string                        `json:"content,omitempty"`
+	ToolCalls []ChatCompletionChunkToolCall `json:"tool_calls,omitempty"`
+	// Annotations cite the sources of a web-searched answer; they follow its content.
+	Annotations []chatAnnotation `json:"annotations,omitempty"`
+}
+
+// ChatCompletionChunkToolCall is a tool call fragment in a streaming chunk. The first fragment of
+// a call carries its id, type and name; the following ones append to its arguments.
+type ChatCompletionChunkToolCall struct {
+	Index    int                                 `json:"index"`
+	ID       string                              `json:"id,omitempty"`
+	Type     string                              `json:"type,omitempty"`
+	Function ChatCompletionChunkToolCallFunction `json:"function"`
+}
+
+// ChatCompletionChunkToolCallFunction is the function of a tool call fragment.
+type ChatCompletionChunkToolCallFunction struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// CompletionChunk represents a streaming legacy completion chunk
+type CompletionChunk struct {
+	ID                string                  `json:"id"`
+	Object            string                  `json:"object"`
+	Created           int64                   `json:"created"`
+	Model             string                  `json:"model"`
+	SystemFingerprint string                  `json:"system_fingerprint,omitempty"`
+	Choices           []CompletionChunkChoice `json:"choices"`
+}
+
+// CompletionChunkChoice represents a choice in a streaming legacy completion chunk
+type CompletionChunkChoice struct {
+	Index        int     `json:"index"`
+	Text         string  `json:"text"`
+	Logprobs     any     `json:"logprobs"`
+	FinishReason *string `json:"finish_reason"`
 }
 
 // StreamingHandler wraps the ogen server and handles streaming requests
 type StreamingHandler struct {
+	*serverState
 	ogenServer http.Handler
+	admin      http.Handler
+	// mock produces the non-streaming responses streams are reconciled with.
+	mock *MockHandler
 }
 
 // NewStreamingHandler creates a new streaming handler
-func NewStreamingHandler(ogenServer http.Handler) *StreamingHandler {
-	return &StreamingHandler{
-		ogenServer: ogenServer,
+func NewStreamingHandler(ogenServer http.Handler, mock *MockHandler, admin http.Handler, state *serverState) *StreamingHandler {
+	return &StreamingHandler{serverState: state, ogenServer: ogenServer, mock: mock, admin: admin}
+}
+
+// chargeRateLimit charges a request against the budget of its API key and sets the x-ratelimit-*
+// headers. It writes a 429 and returns false when the budget is exhausted.
+func (h *StreamingHandler) chargeRateLimit(w http.ResponseWriter, r *http.Request, doc any) bool {
+	decision, ok := h.limiter.Charge(requestIdentity(r), estimateRequestTokens(doc))
+	if !ok {
+		return true
+	}
+	decision.writeHeaders(w.Header())
+	if decision.Allowed {
+		return true
+	}
+	_, span := startRequestSpan(r, "RateLimit.exceeded", attribute.String("ratelimit.tenant", decision.Tenant),
+		attribute.String("ratelimit.type", decision.Exceeded))
+	span.End()
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(decision.Reset.Seconds()))))
+	handleAPIError(r.Context(), w, r, decision.error())
+	return false
+}
+
+// rejectUnmatched records a request no scenario matches as unexpected and writes unexpectedStatus
+// with the diff against the closest scenario.
+func (h *StreamingHandler) rejectUnmatched(w http.ResponseWriter, r *http.Request, doc any) {
+	closest, diff := h.scenarios.Closest(r, doc)
+	resp := newUnmatchedError(r, closest, diff)
+	h.scenarios.Unexpected().Record(unexpectedRequest{Time: time.Now(), Method: r.Method, Path: r.URL.Path, scenarioDiff: resp.Mokku})
+	reasons := make([]string, len(diff))
+	for i, m := range diff {
+		reasons[i] = m.String()
+	}
+	log.Printf("%s Mismatches: %s", resp.Error.Message, strings.Join(reasons, "; "))
+	writeJSON(w, unexpectedStatus, resp)
+}
+
+// applyScenario applies the first scenario matching a request: it waits for the scenario's latency,
+// adds the headers set by its script, then either writes the scenario's error and returns true, or
+// waits for the pauses of its content (unless they are streamed) and returns the request with the
+// scenario in its context for the handlers. With strict_scenarios, a
+// request no scenario matches is recorded as unexpected and gets unexpectedStatus with the diff
+// against the closest scenario.
+func (h *StreamingHandler) applyScenario(w http.ResponseWriter, r *http.Request, doc any) (*http.Request, bool) {
+	rule, data, ok := h.scenarios.Match(r, doc)
+	if !ok {
+		if !h.flags.Enabled(flagStrictScenarios) {
+			return r, false
+		}
+		h.rejectUnmatched(w, r, doc)
+		return r, true
+	}
+	ctx, span := startRequestSpan(r, "Scenario.matched", attribute.String("scenario.name", rule.name))
+	defer span.End()
+
+	if !waitLatency(ctx, rule.latency) {
+		return r, true
+	}
+	matched, err := rule.apply(r, data)
+	if err != nil {
+		handleAPIError(ctx, w, r, scenarioFailure(rule.name, err))
+		return r, true
+	}
+	for name, values := range matched.Header {
+		w.Header()[name] = values
+	}
+	if mark := watermarkFromContext(ctx); mark != nil {
+		mark.Scenario = rule.name
+		w.Header().Set(watermarkHeader, mark.String())
+		if matched.Content != nil {
+			content := watermarkText(ctx, *matched.Content)
+			matched.Content = &content
+		}
+	}
+	if matched.Err != nil {
+		handleAPIError(ctx, w, r, matched.Err)
+		return r, true
+	}
+	if pause := totalPause(matched.Pauses); pause > 0 && !pausesStreamed(r, doc) {
+		if !waitLatency(ctx, pause) {
+			return r, true
+		}
+	}
+	return r.WithContext(withScenario(r.Context(), matched)), false
+}
+
+// applyBaggage applies the behavior overrides in the baggage of a request: it waits for the
+// overridden latency, then writes the overridden error and returns true for a failed request.
+// Invalid overrides are rejected with 400, so a misspelled member does not go unnoticed.
+func (h *StreamingHandler) applyBaggage(w http.ResponseWriter, r *http.Request) bool {
+	overrides, err := parseBaggageOverrides(r)
+	if err != nil {
+		writeInvalidRequestError(w, err.Error())
+		return true
+	}
+	if len(overrides.Applied) == 0 {
+		return false
+	}
+	w.Header().Set(baggageHeader, strings.Join(overrides.Applied, ","))
+	_, span := startRequestSpan(r, "Baggage.override", attribute.StringSlice("baggage.overrides", overrides.Applied))
+	span.End()
+	if !waitLatency(r.Context(), overrides.Latency) {
+		return true
+	}
+	if overrides.Err == nil {
+		return false
+	}
+	if overrides.Err.StatusCode == http.StatusTooManyRequests {
+		w.Header().Set("Retry-After", "1")
+	}
+	handleAPIError(r.Context(), w, r, overrides.Err)
+	return true
+}
+
+// applyRegion simulates the health of the region a request is routed to: it waits for the region's
+// latency, then writes the region's error and returns true for a failed request.
+func (h *StreamingHandler) applyRegion(w http.ResponseWriter, r *http.Request) bool {
+	name, latency, err, ok := h.regions.Route(r)
+	if !ok {
+		return false
+	}
+	w.Header().Set(regionHeader, name)
+	if !waitLatency(r.Context(), latency) {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	_, span := startRequestSpan(r, "Region.outage", attribute.String("region", name), attribute.Int("status", err.StatusCode))
+	span.End()
+	handleAPIError(r.Context(), w, r, err)
+	return true
+}
+
+// applyChaos injects the faults the active chaos profile draws for a request: it waits for the
+// drawn latency, then either writes a rate limit or server error and returns true, or returns the
+// request with its stream marked for truncation.
+func (h *StreamingHandler) applyChaos(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	seed, _ := seedFromContext(r.Context())
+	fault := h.chaos.Draw(seed)
+	if fault.Profile == "" {
+		return r, false
+	}
+	w.Header().Set(chaosHeader, fault.Profile)
+	if !waitLatency(r.Context(), fault.Latency) {
+		return r, true
+	}
+	if fault.RateLimited || fault.Err != nil {
+		err := fault.Err
+		if fault.RateLimited {
+			err = chaosRateLimitError()
+			w.Header().Set("Retry-After", "1")
+		}
+		_, span := startRequestSpan(r, "Chaos.fault", attribute.String("chaos.profile", fault.Profile), attribute.Int("status", err.StatusCode),
+			attribute.String("seed", strconv.FormatUint(seed, 10)))
+		span.End()
+		handleAPIError(r.Context(), w, r, err)
+		return r, true
+	}
+	if fault.Truncate {
+		_, span := startRequestSpan(r, "Chaos.truncate", attribute.String("chaos.profile", fault.Profile), attribute.String("seed", strconv.FormatUint(seed, 10)))
+		span.End()
+		r = r.WithContext(withChaosTruncation(r.Context()))
+	}
+	return r, false
+}
+
+// applyColdStart delays a request to a cold model by its cold-start latency and reports it in the
+// X-Mokku-Cold-Start header. It returns false if the request was cancelled while waiting.
+func (h *StreamingHandler) applyColdStart(w http.ResponseWriter, r *http.Request, doc any) bool {
+	latency := h.coldStart.Serve(requestModel(doc))
+	if latency == 0 {
+		return true
+	}
+	w.Header().Set(coldStartHeader, strconv.FormatInt(latency.Milliseconds(), 10))
+	_, span := startRequestSpan(r, "ColdStart.wait", semconv.GenAIRequestModel(requestModel(doc)), attribute.Int64("latency_ms", latency.Milliseconds()))
+	defer span.End()
+	return waitLatency(r.Context(), latency)
+}
+
+// applyPersonality gives a request the personality of its conversation, if it names one: it reports
+// the personality in the X-Mokku-Personality header, waits for its latency, and returns the request
+// with the personality that shapes the generated text. It returns false if the request was cancelled
+// while waiting.
+func (h *StreamingHandler) applyPersonality(w http.ResponseWriter, r *http.Request, doc any) (*http.Request, bool) {
+	id := conversationID(r, doc)
+	if id == "" {
+		return r, true
+	}
+	p := personalityFor(id)
+	w.Header().Set(personalityHeader, p.String())
+	_, span := startRequestSpan(r, "Personality.apply", attribute.String("personality.latency", p.Latency),
+		attribute.String("personality.verbosity", p.Verbosity), attribute.Bool("personality.emoji", p.Emoji != ""))
+	defer span.End()
+	return r.WithContext(withPersonality(r.Context(), &p)), waitLatency(r.Context(), p.delay)
+}
+
+// waitLatency waits for a simulated latency and adds the time waited to the request's injected
+// latency. It returns false if the request was cancelled first.
+func waitLatency(ctx context.Context, latency time.Duration) bool {
+	if latency <= 0 {
+		return true
+	}
+	start := time.Now()
+	defer func() { injectedDelayFromContext(ctx).Add(time.Since(start)) }()
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
 	}
 }
 
 // ServeHTTP implements http.Handler
 func (h *StreamingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = r.WithContext(withBaseURL(r.Context(), r))
+
 	// Handle health check endpoint
 	if r.Method == http.MethodGet && r.URL.Path == "/healthz" {
 		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
@@ -106,6 +406,134 @@ func (h *StreamingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Route control API requests to the admin handler
+	if strings.HasPrefix(r.URL.Path, adminPathPrefix+"/") {
+		h.admin.ServeHTTP(w, r)
+		return
+	}
+
+	// Report the processing time of API requests like the real API and mokku's own share of it,
+	// assign them the seed their randomized behavior is drawn from, watch their clients' behavior, tag
+	// them by workload, and capture them with their responses for verification. Requests to the other
+	// provider surfaces are API requests too.
+	route, hasDialect := h.resolveDialect(r)
+	apiRequest := hasDialect || strings.HasPrefix(r.URL.Path, "/v1/")
+	if apiRequest {
+		ctx := withRequestTrace(r.Context(), h.ogenServer, r)
+		if hasDialect {
+			ctx = withDialect(ctx, route)
+		}
+		ctx, delay := withInjectedDelay(ctx)
+		r = r.WithContext(ctx)
+		endpoint := endpointName(h.ogenServer, r)
+		w = newProcessingTimeWriter(w, time.Now(), delay, func(overhead, injected time.Duration) {
+			h.overhead.Record(endpoint, overhead, injected)
+		})
+		seed, err := h.seeds.Seed(r)
+		if err != nil {
+			writeInvalidRequestError(w, err.Error())
+			return
+		}
+		r = r.WithContext(withSeed(r.Context(), seed))
+		w.Header().Set(seedHeader, strconv.FormatUint(seed, 10))
+		if h.watermark != watermarkOff {
+			mark := newWatermark(seed, h.watermark)
+			r = r.WithContext(withWatermark(r.Context(), mark))
+			w.Header().Set(watermarkHeader, mark.String())
+		}
+		if h.alerts.Active() {
+			h.alerts.Observe(r)
+		}
+		if h.tags.Active() {
+			var done func()
+			w, r, done = h.tags.Begin(w, r)
+			defer done()
+		}
+		if h.capture.Enabled() {
+			var done func()
+			w, r, done = h.capture.Begin(w, r)
+			defer done()
+		}
+		var handled bool
+		if r, handled = h.applyFidelity(w, r, endpoint); handled {
+			return
+		}
+		if r.Header.Get("Baggage") != "" && h.flags.Enabled(flagBaggageOverrides) && h.applyBaggage(w, r) {
+			return
+		}
+		if h.applyRegion(w, r) {
+			return
+		}
+		if r, handled = h.applyChaos(w, r); handled {
+			return
+		}
+	}
+
+	// Reject API requests containing banned phrases, made for banned end-users, or exceeding their
+	// tenant's rate limit, delay requests to cold models, give conversations their personality, then
+	// apply the matching scenario, before any other processing
+	personalities := h.flags.Enabled(flagStickyPersonality)
+	strict := h.flags.Enabled(flagStrictScenarios)
+	if r.Method == http.MethodPost && apiRequest && (h.moderation.Active() || h.limiter.Active() || h.coldStart.Active() || h.scenarios.Active() || strict || personalities) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Failed to read request body", http.StatusBadRequest)
+			return
+		}
+		_ = r.Body.Close()
+		if phrase, flagged := h.moderation.Check(body); flagged {
+			_, span := startRequestSpan(r, "Moderation.flagged", attribute.String("moderation.phrase", phrase))
+			span.End()
+			handleAPIError(r.Context(), w, r, newPolicyViolationError(r.URL.Path))
+			return
+		}
+		var doc any
+		_ = json.Unmarshal(body, &doc)
+		if field, blocked := h.moderation.CheckUser(doc); blocked {
+			_, span := startRequestSpan(r, "Moderation.blockedUser", attribute.String("moderation.field", field))
+			span.End()
+			handleAPIError(r.Context(), w, r, newBlockedUserError(field))
+			return
+		}
+		if !h.chargeRateLimit(w, r, doc) {
+			return
+		}
+		if !h.applyColdStart(w, r, doc) {
+			return
+		}
+		if personalities {
+			var ok bool
+			if r, ok = h.applyPersonality(w, r, doc); !ok {
+				return
+			}
+		}
+		var handled bool
+		if r, handled = h.applyScenario(w, r, doc); handled {
+			return
+		}
+		if s, ok := scenarioFromContext(r.Context()); ok && len(s.Mappings) > 0 && !streamRequested(doc) {
+			mw := newMappingWriter(w, r, s, doc)
+			defer mw.finish()
+			w = mw
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	// With strict_scenarios, API requests of the other methods must match a scenario too. Scenario
+	// responses are for POST requests, so a match only lets them through.
+	if r.Method != http.MethodPost && apiRequest && strict {
+		if _, _, ok := h.scenarios.Match(r, nil); !ok {
+			h.rejectUnmatched(w, r, nil)
+			return
+		}
+	}
+
+	// Serve the other provider surfaces from the canonical completion model
+	if hasDialect {
+		h.serveDialect(w, r, route)
+		return
+	}
+
 	// Intercept POST /v1/chat/completions
 	if r.Method == http.MethodPost && r.URL.Path == "/v1/chat/completions" {
 		body, handled := readBodyAndCheckCreditError(w, r)
@@ -121,7 +549,31 @@ func (h *StreamingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 		// Check if streaming is requested
 		if req.Stream.Set && req.Stream.Value {
-			h.handleStreamingRequest(w, r, &req)
+			if fidelityFromContext(r.Context()) == fidelityStrict {
+				if err := req.Validate(); err != nil {
+					handleAPIError(r.Context(), w, r, newInvalidRequestError("body", err.Error()))
+					return
+				}
+			}
+			if err := validateModel(r.Context(), h.flags, h.models, req.Model); err != nil {
+				handleAPIError(r.Context(), w, r, err)
+				return
+			}
+			audioTokens, err := countAudioTokens(req.Messages)
+			if err != nil {
+				handleAPIError(r.Context(), w, r, err)
+				return
+			}
+			if err := validateLogitBias(req.LogitBias.Value); err != nil {
+				handleAPIError(r.Context(), w, r, err)
+				return
+			}
+			fit, err := h.models.checkContextWindow(req.Model, "messages", countMessageTokens(req.Messages)+audioTokens, chatMaxTokens(&req))
+			if err != nil {
+				handleAPIError(r.Context(), w, r, err)
+				return
+			}
+			h.handleStreamingRequest(w, r, &req, fit)
 			return
 		}
 
@@ -129,14 +581,36 @@ func (h *StreamingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		r.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
-	// Intercept POST /v1/completions for credit error simulation
+	// Intercept POST /v1/completions
 	if r.Method == http.MethodPost && r.URL.Path == "/v1/completions" {
 		body, handled := readBodyAndCheckCreditError(w, r)
 		if handled {
 			return
 		}
 
-		// Reconstruct the body and pass to ogen server
+		var req api.CreateCompletionRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, "Failed to parse request body", http.StatusBadRequest)
+			return
+		}
+
+		// Check if streaming is requested
+		if req.Stream.Set && req.Stream.Value {
+			if fidelityFromContext(r.Context()) == fidelityStrict {
+				if err := req.Validate(); err != nil {
+					handleAPIError(r.Context(), w, r, newInvalidRequestError("body", err.Error()))
+					return
+				}
+			}
+			if err := validateModel(r.Context(), h.flags, h.models, req.Model); err != nil {
+				handleAPIError(r.Context(), w, r, err)
+				return
+			}
+			h.handleCompletionStreamingRequest(w, r, &req)
+			return
+		}
+
+		// For non-streaming requests, reconstruct the body and pass to ogen server
 		r.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
@@ -144,23 +618,106 @@ func (h *StreamingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	h.ogenServer.ServeHTTP(w, r)
 }
 
+// finishStream records the outcome of a streaming response in the stream log and, if the
+// stream was cut off, on its span.
+func (h *StreamingHandler) finishStream(span trace.Span, stream *sseWriter, id, path, model string) {
+	if stream.err == nil {
+		h.streams.Completed()
+		return
+	}
+	cancellation := streamCancellation{
+		ID:          id,
+		Path:        path,
+		Model:       model,
+		Reason:      stream.cancelReason(),
+		ChunksSent:  stream.chunks,
+		BytesSent:   stream.bytes,
+		CancelledAt: time.Now(),
+	}
+	h.streams.Cancelled(cancellation)
+	recordCapturedCancellation(stream.ctx, cancellation)
+	span.SetAttributes(
+		attribute.Bool("stream.cancelled", true),
+		attribute.String("stream.cancel_reason", cancellation.Reason),
+		attribute.Int("stream.chunks_sent", cancellation.ChunksSent),
+		attribute.Int("stream.bytes_sent", cancellation.BytesSent),
+	)
+	if cancellation.Reason == streamCancelReasonTruncated {
+		// Drop the connection like a broken network path, so clients see an incomplete response
+		// rather than the end of the stream
+		h.chaos.Truncated()
+		panic(http.ErrAbortHandler)
+	}
+}
+
 // handleStreamingRequest handles streaming chat completion requests
-func (h *StreamingHandler) handleStreamingRequest(w http.ResponseWriter, r *http.Request, req *api.CreateChatCompletionRequest) {
+func (h *StreamingHandler) handleStreamingRequest(w http.ResponseWriter, r *http.Request, req *api.CreateChatCompletionRequest, fit contextFit) {
+	r, operation := startOperationSpan(r)
+	defer operation.End()
 	ctx, span := tracer.Start(r.Context(), "CreateChatCompletion.streaming")
 	defer span.End()
 
-	span.SetAttributes(attribute.String("request.full_json", marshalJSON(req)))
+	setSpanBody(span, "request.full_json", req)
 	span.SetAttributes(attribute.Bool("stream", true))
 
 	lastUserMessage := extractLastUserMessage(req.Messages)
 
-	span.SetAttributes(
-		attribute.String("model", req.Model),
-		attribute.Int("message_count", len(req.Messages)),
-		attribute.String("last_user_message", lastUserMessage),
-	)
-
-	echoMessage := generateEchoResponse(ctx, lastUserMessage)
+	span.SetAttributes(genAIChatRequestAttributes(req)...)
+	addGenAIMessageEvents(span, req.Messages)
+
+	// JSON-mode content is streamed token by token so that every accumulated prefix is a
+	// prefix of the final document, as with real models; echo content is sent in one chunk.
+	// Scenario content is sent like echo content.
+	// Tool calls are streamed as a fragment with the id and name of each call followed by its
+	// arguments token by token.
+	scenario, _ := scenarioFromContext(ctx)
+	content, jsonMode := generateJSONModeContent(req.ResponseFormat, lastUserMessage)
+	contentPieces := splitTokens(content)
+	var toolCalls []mockToolCall
+	var citations []urlCitation
+	if scenario.Content == nil && !jsonMode {
+		var err error
+		if toolCalls, err = planToolCalls(req, lastUserMessage); err != nil {
+			handleAPIError(ctx, w, r, err)
+			return
+		}
+	}
+	switch {
+	case scenario.Content != nil:
+		content, jsonMode = *scenario.Content, false
+		contentPieces = []string{content}
+	case len(toolCalls) > 0:
+		content, contentPieces = "", nil
+		span.SetAttributes(attribute.Int("tool_calls", len(toolCalls)))
+	case !jsonMode:
+		var bannedTokens int
+		text := generateAssistantText(ctx, req.Model, lastUserMessage, 0, req.PresencePenalty.Value, req.FrequencyPenalty.Value)
+		content, bannedTokens = stripBannedTokens(text, req.LogitBias.Value)
+		if webSearchRequested(req) {
+			content, citations = citeWebSources(content)
+			span.SetAttributes(attribute.Int("url_citations", len(citations)))
+		}
+		contentPieces = []string{content}
+		span.SetAttributes(attribute.Int("logit_bias.removed_tokens", bannedTokens))
+	}
+	span.SetAttributes(attribute.Bool("json_mode", jsonMode))
+	// A truncated request gets the content that fits the tokens left in the context window.
+	truncated := false
+	if len(toolCalls) == 0 {
+		if content, truncated = fit.limit(content); truncated {
+			citations = nil
+			contentPieces = []string{content}
+			if jsonMode {
+				contentPieces = splitTokens(content)
+			}
+			span.SetAttributes(attribute.Bool("context_window.truncated", true))
+		}
+	}
+	pacer := h.newStreamPacer(ctx, req.Model)
+	if scenario.Content != nil {
+		pacer.pauses = scenario.Pauses
+	}
+	contentPieces = pacer.split(content, contentPieces)
 
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -176,6 +733,13 @@ func (h *StreamingHandler) handleStreamingRequest(w http.ResponseWriter, r *http
 	completionID := "chatcmpl-" + uuid.New().String()
 	created := time.Now().Unix()
 
+	stream := &sseWriter{ctx: ctx, w: w, flusher: flusher, record: h.flags.Enabled(flagStreamReconciliation)}
+	if chaosTruncationFromContext(ctx) {
+		stream.limit = chaosStreamEvents
+	}
+	h.streams.Started()
+	defer h.finishStream(span, stream, completionID, r.URL.Path, req.Model)
+
 	// Send first chunk with role
 	firstChunk := ChatCompletionChunk{
 		ID:                completionID,
@@ -194,38 +758,91 @@ func (h *StreamingHandler) handleStreamingRequest(w http.ResponseWriter, r *http
 		},
 	}
 
-	if err := writeSSEChunk(w, firstChunk); err != nil {
-		span.SetAttributes(attribute.String("error", err.Error()))
+	if !stream.send(firstChunk) {
 		return
 	}
-	flusher.Flush()
 
-	// Send content chunk
-	contentChunk := ChatCompletionChunk{
-		ID:                completionID,
-		Object:            chatCompletionChunkObject,
-		Created:           created,
-		Model:             req.Model,
-		SystemFingerprint: systemFingerprint,
-		Choices: []ChatCompletionChunkChoice{
-			{
-				Index: 0,
-				Delta: ChatCompletionChunkDelta{
-					Content: echoMessage,
+	// Send content chunks
+	for _, piece := range contentPieces {
+		pacer.wait(piece)
+		contentChunk := ChatCompletionChunk{
+			ID:                completionID,
+			Object:            chatCompletionChunkObject,
+			Created:           created,
+			Model:             req.Model,
+			SystemFingerprint: systemFingerprint,
+			Choices: []ChatCompletionChunkChoice{
+				{
+					Index: 0,
+					Delta: ChatCompletionChunkDelta{
+						Content: piece,
+					},
+					FinishReason: nil,
 				},
-				FinishReason: nil,
 			},
-		},
+		}
+
+		if !stream.send(contentChunk) {
+			return
+		}
 	}
+	pacer.finish()
+
+	// Send the citations of a web-searched answer
+	if len(citations) > 0 {
+		annotationChunk := ChatCompletionChunk{
+			ID:                completionID,
+			Object:            chatCompletionChunkObject,
+			Created:           created,
+			Model:             req.Model,
+			SystemFingerprint: systemFingerprint,
+			Choices: []ChatCompletionChunkChoice{
+				{
+					Index:        0,
+					Delta:        ChatCompletionChunkDelta{Annotations: chatAnnotationDeltas(citations)},
+					FinishReason: nil,
+				},
+			},
+		}
 
-	if err := writeSSEChunk(w, contentChunk); err != nil {
-		span.SetAttributes(attribute.String("error", err.Error()))
-		return
+		if !stream.send(annotationChunk) {
+			return
+		}
+	}
+
+	// Send tool call chunks
+	for _, delta := range toolCallDeltas(toolCalls) {
+		toolCallChunk := ChatCompletionChunk{
+			ID:                completionID,
+			Object:            chatCompletionChunkObject,
+			Created:           created,
+			Model:             req.Model,
+			SystemFingerprint: systemFingerprint,
+			Choices: []ChatCompletionChunkChoice{
+				{
+					Index:        0,
+					Delta:        ChatCompletionChunkDelta{ToolCalls: []ChatCompletionChunkToolCall{delta}},
+					FinishReason: nil,
+				},
+			},
+		}
+
+		if !stream.send(toolCallChunk) {
+			return
+		}
 	}
-	flusher.Flush()
 
 	// Send final chunk with finish_reason
 	finishReason := "stop"
+	if len(toolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+	if truncated {
+		finishReason = finishReasonLength
+	}
+	if scenario.FinishReason != "" {
+		finishReason = scenario.FinishReason
+	}
 	finalChunk := ChatCompletionChunk{
 		ID:                completionID,
 		Object:            chatCompletionChunkObject,
@@ -241,42 +858,164 @@ func (h *StreamingHandler) handleStreamingRequest(w http.ResponseWriter, r *http
 		},
 	}
 
-	if err := writeSSEChunk(w, finalChunk); err != nil {
-		span.SetAttributes(attribute.String("error", err.Error()))
+	if !stream.send(finalChunk) {
 		return
 	}
-	flusher.Flush()
 
 	// Send [DONE] marker
-	_, _ = fmt.Fprintf(w, "data: [DONE]\n\n")
-	flusher.Flush()
+	if !stream.sendDone() {
+		return
+	}
 
-	span.SetAttributes(attribute.String("response.echo_message", echoMessage))
+	completionTokens := countTokens(content)
+	switch {
+	case len(toolCalls) > 0:
+		content, completionTokens = marshalJSON(apiToolCalls(toolCalls)), toolCallTokens(toolCalls)
+		setSpanText(span, "response.tool_calls", content)
+	case jsonMode:
+		setSpanText(span, "response.json_content", content)
+	default:
+		setSpanText(span, "response.echo_message", content)
+	}
+	addGenAIChoiceEvent(span, 0, finishReason, content)
+	span.SetAttributes(genAIResponseAttributes(completionID, req.Model, fit.PromptTokens, completionTokens, finishReason)...)
+	h.reconcileChatStream(ctx, stream, completionID, r.URL.Path, req)
 }
 
 // writeCreditError writes a 402 credit error response
 func writeCreditError(w http.ResponseWriter) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusPaymentRequired)
-
-	errorResp := OpenAIError{
-		Error: OpenAIErrorDetail{
-			Message: "You exceeded your current quota, please check your plan and billing details. For more information on this error, read the docs: https://platform.openai.com/docs/guides/error-codes/api-errors.",
-			Type:    "insufficient_quota",
-			Param:   nil,
-			Code:    "insufficient_quota",
-		},
-	}
+	writeOpenAIError(w, http.StatusPaymentRequired, OpenAIErrorDetail{
+		Message: "You exceeded your current quota, please check your plan and billing details. For more information on this error, read the docs: https://platform.openai.com/docs/guides/error-codes/api-errors.",
+		Type:    "insufficient_quota",
+		Param:   nil,
+		Code:    "insufficient_quota",
+	})
+}
+
+// writeInvalidRequestError writes a 400 invalid_request_error response
+func writeInvalidRequestError(w http.ResponseWriter, message string) {
+	writeOpenAIError(w, http.StatusBadRequest, OpenAIErrorDetail{
+		Message: message,
+		Type:    "invalid_request_error",
+		Param:   nil,
+		Code:    "invalid_request_error",
+	})
+}
 
-	_ = json.NewEncoder(w).Encode(errorResp)
+// writeOpenAIError writes an OpenAI-style error response with the given status code
+func writeOpenAIError(w http.ResponseWriter, status int, detail OpenAIErrorDetail) {
+	writeJSON(w, status, OpenAIError{Error: detail})
 }
 
-// writeSSEChunk writes a chunk in SSE format
-func writeSSEChunk(w http.ResponseWriter, chunk ChatCompletionChunk) error {
-	data, err := json.Marshal(chunk)
+// handleCompletionStreamingRequest handles streaming legacy completion requests.
+// Each choice is streamed in turn: the echoed prompt (with echo set), the completion text,
+// and a final chunk with finish_reason.
+func (h *StreamingHandler) handleCompletionStreamingRequest(w http.ResponseWriter, r *http.Request, req *api.CreateCompletionRequest) {
+	r, operation := startOperationSpan(r)
+	defer operation.End()
+	ctx, span := tracer.Start(r.Context(), "CreateCompletion.streaming")
+	defer span.End()
+
+	setSpanBody(span, "request.full_json", req)
+	span.SetAttributes(attribute.Bool("stream", true))
+
+	prompt, promptTokens, err := completionPrompt(req.Prompt)
 	if err != nil {
-		return err
+		handleAPIError(ctx, w, r, err)
+		return
+	}
+
+	span.SetAttributes(genAICompletionRequestAttributes(req)...)
+	span.SetAttributes(attribute.Bool("echo", req.Echo.Value))
+	addGenAIPromptEvent(span, prompt)
+
+	n, bestOf, err := resolveCompletionCounts(req.N, req.BestOf)
+	var fit contextFit
+	if err == nil {
+		fit, err = h.models.checkContextWindow(req.Model, "prompt", promptTokens, req.MaxTokens.Value)
+	}
+	if err == nil && bestOf > n {
+		err = newInvalidRequestError("best_of", "Cannot stream results when best_of is greater than n.")
+	}
+	var choices []api.CompletionChoice
+	var completionTokens int
+	if err == nil {
+		choices, completionTokens, err = generateCompletionChoices(ctx, req, prompt, fit)
+	}
+	if err != nil {
+		handleAPIError(ctx, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	completionID := "cmpl-" + uuid.New().String()
+	created := time.Now().Unix()
+
+	stream := &sseWriter{ctx: ctx, w: w, flusher: flusher, record: h.flags.Enabled(flagStreamReconciliation)}
+	if chaosTruncationFromContext(ctx) {
+		stream.limit = chaosStreamEvents
+	}
+	h.streams.Started()
+	defer h.finishStream(span, stream, completionID, r.URL.Path, req.Model)
+
+	chunk := func(index int, text string, finishReason *string) CompletionChunk {
+		return CompletionChunk{
+			ID:                completionID,
+			Object:            completionChunkObject,
+			Created:           created,
+			Model:             req.Model,
+			SystemFingerprint: systemFingerprint,
+			Choices: []CompletionChunkChoice{
+				{
+					Index:        index,
+					Text:         text,
+					FinishReason: finishReason,
+				},
+			},
+		}
+	}
+
+	pacer := h.newStreamPacer(ctx, req.Model)
+	for _, choice := range choices {
+		finishReason := string(choice.FinishReason)
+		text := choice.Text
+		if req.Echo.Value {
+			if !stream.send(chunk(choice.Index, prompt, nil)) {
+				return
+			}
+			text = strings.TrimPrefix(text, prompt)
+		}
+		for _, piece := range pacer.split(text, []string{text}) {
+			pacer.wait(piece)
+			if !stream.send(chunk(choice.Index, piece, nil)) {
+				return
+			}
+		}
+		if !stream.send(chunk(choice.Index, "", &finishReason)) {
+			return
+		}
+	}
+
+	// Send [DONE] marker
+	if !stream.sendDone() {
+		return
+	}
+
+	finishReasons := make([]string, len(choices))
+	for i, choice := range choices {
+		finishReasons[i] = string(choice.FinishReason)
+		addGenAIChoiceEvent(span, choice.Index, finishReasons[i], choice.Text)
 	}
-	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
-	return err
+	span.SetAttributes(genAIResponseAttributes(completionID, req.Model, fit.PromptTokens, completionTokens, finishReasons...)...)
+	h.reconcileCompletionStream(ctx, stream, completionID, r.URL.Path, req)
 }
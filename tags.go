@@ -0,0 +1,388 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxTagStats is the number of tags whose requests are rolled up; the least recently added tag is
+// forgotten beyond it.
+const maxTagStats = 200
+
+// maxTagSamples is the number of recent requests per tag the duration percentiles are computed from.
+const maxTagSamples = 1000
+
+// tagNamePattern is the form of a tag name, safe to use unescaped in a query parameter.
+var tagNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]*$`)
+
+// tagConfig is a rule of the tags section of the config file: requests matching Match get Tag.
+// Several rules may give the same tag.
+type tagConfig struct {
+	Tag   string         `yaml:"tag" json:"tag"`
+	Match tagMatchConfig `yaml:"match" json:"match"`
+}
+
+// tagMatchConfig selects requests by their content. Empty fields match everything; Path is exact,
+// Model, Message, Tool, and header values are regular expressions.
+type tagMatchConfig struct {
+	Model   string            `yaml:"model" json:"model,omitempty"`
+	Path    string            `yaml:"path" json:"path,omitempty"`
+	Message string            `yaml:"message" json:"message,omitempty"`
+	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+	// Tools matches requests that declare tools (true) or none (false).
+	Tools *bool `yaml:"tools" json:"tools,omitempty"`
+	// Tool matches requests declaring a tool whose name matches.
+	Tool string `yaml:"tool" json:"tool,omitempty"`
+	// ToolResults matches conversations that carry tool results back to the model, the turns of an
+	// agent loop.
+	ToolResults *bool `yaml:"tool_results" json:"tool_results,omitempty"`
+	// Images matches requests with image inputs.
+	Images *bool `yaml:"images" json:"images,omitempty"`
+	// MinMessages matches conversations of at least this many messages or input items.
+	MinMessages int `yaml:"min_messages" json:"min_messages,omitempty"`
+}
+
+// tagRule is a compiled tagConfig.
+type tagRule struct {
+	tag         string
+	model       *regexp.Regexp
+	path        string
+	message     *regexp.Regexp
+	headers     map[string]*regexp.Regexp
+	tools       *bool
+	tool        *regexp.Regexp
+	toolResults *bool
+	images      *bool
+	minMessages int
+}
+
+// compileTagRule validates and compiles the i-th rule of the tags section.
+func compileTagRule(i int, cfg tagConfig) (*tagRule, error) {
+	if !tagNamePattern.MatchString(cfg.Tag) {
+		return nil, fmt.Errorf("tags[%d].tag must be letters, digits, '.', ':', '_', or '-', got %q", i, cfg.Tag)
+	}
+	m := cfg.Match
+	if m.MinMessages < 0 {
+		return nil, fmt.Errorf("tags[%d].match.min_messages must not be negative, got %d", i, m.MinMessages)
+	}
+	rule := &tagRule{tag: cfg.Tag, path: m.Path, headers: map[string]*regexp.Regexp{}, tools: m.Tools,
+		toolResults: m.ToolResults, images: m.Images, minMessages: m.MinMessages}
+	patterns := []struct {
+		field   string
+		pattern string
+		dst     **regexp.Regexp
+	}{{"model", m.Model, &rule.model}, {"message", m.Message, &rule.message}, {"tool", m.Tool, &rule.tool}}
+	var err error
+	for _, p := range patterns {
+		if p.pattern == "" {
+			continue
+		}
+		if *p.dst, err = regexp.Compile(p.pattern); err != nil {
+			return nil, fmt.Errorf("tags[%d].match.%s: %w", i, p.field, err)
+		}
+	}
+	for name, pattern := range m.Headers {
+		if rule.headers[name], err = regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("tags[%d].match.headers.%s: %w", i, name, err)
+		}
+	}
+	return rule, nil
+}
+
+// requestTraits are the features of a request the tag rules inspect, computed once per request.
+type requestTraits struct {
+	model       string
+	message     string
+	tools       []string
+	hasTools    bool
+	toolResults bool
+	images      bool
+	messages    int
+}
+
+// newRequestTraits extracts the traits of a request with the decoded JSON body doc.
+func newRequestTraits(doc any) requestTraits {
+	t := requestTraits{message: scenarioMessage(doc)}
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return t
+	}
+	t.model, _ = m["model"].(string)
+	for _, key := range []string{"tools", "functions"} {
+		declared, _ := m[key].([]any)
+		t.hasTools = t.hasTools || len(declared) > 0
+		for _, tool := range declared {
+			if name := toolName(tool); name != "" {
+				t.tools = append(t.tools, name)
+			}
+		}
+	}
+	for _, key := range []string{"messages", "input"} {
+		if items, ok := m[key].([]any); ok {
+			t.messages = len(items)
+			t.toolResults, t.images = scanConversation(items)
+			break
+		}
+	}
+	return t
+}
+
+// toolName returns the name of a declared tool: a chat completions function tool, a legacy function,
+// or a Responses API tool.
+func toolName(tool any) string {
+	t, _ := tool.(map[string]any)
+	if fn, ok := t["function"].(map[string]any); ok {
+		name, _ := fn["name"].(string)
+		return name
+	}
+	name, _ := t["name"].(string)
+	return name
+}
+
+// scanConversation reports whether chat messages or Responses API input items carry tool results and
+// image inputs.
+func scanConversation(items []any) (toolResults, images bool) {
+	for _, item := range items {
+		msg, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch {
+		case msg["role"] == "tool", msg["role"] == "function", msg["type"] == "function_call_output":
+			toolResults = true
+		}
+		parts, _ := msg["content"].([]any)
+		for _, part := range parts {
+			if p, ok := part.(map[string]any); ok && (p["type"] == "image_url" || p["type"] == "input_image") {
+				images = true
+			}
+		}
+	}
+	return toolResults, images
+}
+
+// matches reports whether the rule selects a request.
+func (rule *tagRule) matches(r *http.Request, t requestTraits) bool {
+	switch {
+	case rule.path != "" && rule.path != r.URL.Path,
+		rule.model != nil && !rule.model.MatchString(t.model),
+		rule.message != nil && !rule.message.MatchString(t.message),
+		rule.tools != nil && *rule.tools != t.hasTools,
+		rule.tool != nil && !slices.ContainsFunc(t.tools, rule.tool.MatchString),
+		rule.toolResults != nil && *rule.toolResults != t.toolResults,
+		rule.images != nil && *rule.images != t.images,
+		t.messages < rule.minMessages:
+		return false
+	}
+	for name, pattern := range rule.headers {
+		if !pattern.MatchString(r.Header.Get(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// tagSummary is the roll-up of one tag reported by GET /_mokku/tags.
+type tagSummary struct {
+	Tag      string `json:"tag"`
+	Requests int64  `json:"requests"`
+	// Errors counts the responses with a 4xx or 5xx status and Streams the streamed responses.
+	Errors  int64 `json:"errors"`
+	Streams int64 `json:"streams"`
+	// Duration is the time until the response was complete, over the most recent maxTagSamples
+	// requests.
+	Duration latencySummary `json:"duration_ms"`
+}
+
+// tagStats is the response body of GET /_mokku/tags.
+type tagStats struct {
+	Object string       `json:"object"`
+	Tags   []tagSummary `json:"tags"`
+	// Untagged counts the requests no rule matched.
+	Untagged int64 `json:"untagged"`
+}
+
+// tagSamples is the tracked state of one tag.
+type tagSamples struct {
+	tag      string
+	requests int64
+	errors   int64
+	streams  int64
+	recent   *ringBuffer[time.Duration]
+}
+
+// requestTagger classifies API requests with the tag rules of the config file, so captured traffic
+// can be sliced by workload (agent loops, summarization, vision) and each workload's volume, errors,
+// and durations are rolled up. It is safe for concurrent use.
+type requestTagger struct {
+	rules    atomic.Pointer[[]*tagRule]
+	mu       sync.Mutex
+	tags     *boundedMap[string, *tagSamples]
+	untagged int64
+}
+
+// newRequestTagger creates a tagger with the configured rules.
+func newRequestTagger(cfg []tagConfig) (*requestTagger, error) {
+	t := &requestTagger{tags: newBoundedMap[string, *tagSamples](maxTagStats)}
+	if err := t.Load(cfg); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Load replaces the rules. The roll-ups are kept. On error the current rules are kept.
+func (t *requestTagger) Load(cfg []tagConfig) error {
+	return installNow(t.prepare(cfg))
+}
+
+// prepare validates the rules and returns the function installing them.
+func (t *requestTagger) prepare(cfg []tagConfig) (func(), error) {
+	rules := make([]*tagRule, 0, len(cfg))
+	for i, c := range cfg {
+		rule, err := compileTagRule(i, c)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return func() {
+		t.rules.Store(&rules)
+	}, nil
+}
+
+// Active reports whether any rule is loaded.
+func (t *requestTagger) Active() bool {
+	return len(*t.rules.Load()) > 0
+}
+
+// Classify returns the tags of a request with the decoded JSON body doc, in rule order and without
+// duplicates.
+func (t *requestTagger) Classify(r *http.Request, doc any) []string {
+	traits := newRequestTraits(doc)
+	var tags []string
+	for _, rule := range *t.rules.Load() {
+		if !slices.Contains(tags, rule.tag) && rule.matches(r, traits) {
+			tags = append(tags, rule.tag)
+		}
+	}
+	return tags
+}
+
+// Begin classifies a request. It reads the request body, restores it for the handlers, and returns
+// the request carrying its tags (see requestTagsFromContext) and a writer recording the response
+// status; done rolls the request up once the response is complete.
+func (t *requestTagger) Begin(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request, func()) {
+	start := time.Now()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return w, r, func() {}
+	}
+	_ = r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var doc any
+	_ = json.Unmarshal(body, &doc)
+	tags := t.Classify(r, doc)
+	rec := &tagWriter{ResponseWriter: w, status: http.StatusOK}
+	return rec, r.WithContext(context.WithValue(r.Context(), requestTagsContextKey{}, tags)), func() {
+		t.Record(tags, rec.status, streamRequested(doc), time.Since(start))
+	}
+}
+
+// Record rolls up a request with its tags, response status, whether it was streamed, and the time
+// until its response was complete.
+func (t *requestTagger) Record(tags []string, status int, stream bool, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(tags) == 0 {
+		t.untagged++
+		return
+	}
+	for _, tag := range tags {
+		s, ok := t.tags.Get(tag)
+		if !ok {
+			s = &tagSamples{tag: tag, recent: newRingBuffer[time.Duration](maxTagSamples)}
+			t.tags.Put(tag, s)
+		}
+		s.requests++
+		if status >= http.StatusBadRequest {
+			s.errors++
+		}
+		if stream {
+			s.streams++
+		}
+		s.recent.Push(duration)
+	}
+}
+
+// Stats returns the roll-up of every tag, sorted by tag.
+func (t *requestTagger) Stats() tagStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := tagStats{Object: "mokku.tags", Tags: []tagSummary{}, Untagged: t.untagged}
+	for _, s := range t.tags.Values() {
+		stats.Tags = append(stats.Tags, tagSummary{
+			Tag:      s.tag,
+			Requests: s.requests,
+			Errors:   s.errors,
+			Streams:  s.streams,
+			Duration: summarizeLatency(s.recent.Snapshot()),
+		})
+	}
+	slices.SortFunc(stats.Tags, func(a, b tagSummary) int { return cmp.Compare(a.Tag, b.Tag) })
+	return stats
+}
+
+// Reset forgets the roll-ups.
+func (t *requestTagger) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tags.Clear()
+	t.untagged = 0
+}
+
+// requestTagsContextKey is the context key of a request's tags.
+type requestTagsContextKey struct{}
+
+// requestTagsFromContext returns the tags the tagger gave a request.
+func requestTagsFromContext(ctx context.Context) []string {
+	tags, _ := ctx.Value(requestTagsContextKey{}).([]string)
+	return tags
+}
+
+// tagWriter records the status of a response.
+type tagWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *tagWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (w *tagWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap returns the wrapped writer for http.ResponseController.
+func (w *tagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// --- newRequestTagger ---
+
+func TestNewRequestTagger_RejectsInvalidRules(t *testing.T) {
+	// Given
+	for name, rule := range map[string]tagConfig{
+		"empty tag":            {},
+		"tag with a comma":     {Tag: "a,b"},
+		"bad model pattern":    {Tag: "x", Match: tagMatchConfig{Model: "("}},
+		"bad header pattern":   {Tag: "x", Match: tagMatchConfig{Headers: map[string]string{"X-Team": "["}}},
+		"negative min message": {Tag: "x", Match: tagMatchConfig{MinMessages: -1}},
+	} {
+		// When
+		_, err := newRequestTagger([]tagConfig{rule})
+
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// --- Classify ---
+
+func TestRequestTagger_Classify_MatchesContentToolsAndModel(t *testing.T) {
+	// Given: rules for agent loops, summarization, vision, and a model family
+	yes := true
+	tagger, err := newRequestTagger([]tagConfig{
+		{Tag: "agent-loop", Match: tagMatchConfig{ToolResults: &yes}},
+		{Tag: "summarization", Match: tagMatchConfig{Message: `(?i)\bsummari[sz]e\b`}},
+		{Tag: "vision", Match: tagMatchConfig{Images: &yes}},
+		{Tag: "search-tool", Match: tagMatchConfig{Tool: "^web_search$"}},
+		{Tag: "gpt-4o", Match: tagMatchConfig{Model: "^gpt-4o"}},
+		{Tag: "vision", Match: tagMatchConfig{Path: "/v1/responses"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name string
+		path string
+		body string
+		want []string
+	}{
+		{"plain", "/v1/chat/completions", `{"model":"gpt-3.5-turbo","messages":[{"role":"user","content":"hi"}]}`, nil},
+		{"summarize", "/v1/chat/completions", `{"model":"gpt-4o-mini","messages":[{"role":"user","content":"Summarize this"}]}`,
+			[]string{"summarization", "gpt-4o"}},
+		{"agent turn", "/v1/chat/completions", `{"model":"o3","tools":[{"type":"function","function":{"name":"web_search"}}],` +
+			`"messages":[{"role":"user","content":"go"},{"role":"assistant","tool_calls":[]},{"role":"tool","content":"{}"}]}`,
+			[]string{"agent-loop", "search-tool"}},
+		{"image part", "/v1/chat/completions", `{"model":"o3","messages":[{"role":"user","content":[{"type":"image_url","image_url":{"url":"x"}}]}]}`,
+			[]string{"vision"}},
+		{"responses", "/v1/responses", `{"model":"o3","input":[{"type":"function_call_output","output":"{}"}]}`,
+			[]string{"agent-loop", "vision"}},
+	}
+	for _, tc := range cases {
+		var doc any
+		_ = json.Unmarshal([]byte(tc.body), &doc)
+
+		// When
+		got := tagger.Classify(httptest.NewRequest(http.MethodPost, tc.path, nil), doc)
+
+		// Then: tags come in rule order without duplicates
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("%s: expected tags %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestRequestTagger_Classify_MinMessagesAndHeaders(t *testing.T) {
+	// Given
+	no := false
+	tagger, _ := newRequestTagger([]tagConfig{{Tag: "long-chat", Match: tagMatchConfig{
+		MinMessages: 3, Tools: &no, Headers: map[string]string{"X-Team": "^search$"},
+	}}})
+	var doc any
+	_ = json.Unmarshal([]byte(`{"messages":[{"role":"user"},{"role":"assistant"},{"role":"user"}]}`), &doc)
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+
+	// When
+	without := tagger.Classify(r, doc)
+	r.Header.Set("X-Team", "search")
+	with := tagger.Classify(r, doc)
+
+	// Then
+	if without != nil || !slices.Equal(with, []string{"long-chat"}) {
+		t.Errorf("expected tags only with the header, got %v and %v", without, with)
+	}
+}
+
+// --- Record / Stats ---
+
+func TestRequestTagger_Record_RollsUpPerTag(t *testing.T) {
+	// Given
+	tagger, _ := newRequestTagger(nil)
+
+	// When
+	tagger.Record([]string{"vision", "agent-loop"}, http.StatusOK, true, 100*time.Millisecond)
+	tagger.Record([]string{"vision"}, http.StatusTooManyRequests, false, 10*time.Millisecond)
+	tagger.Record(nil, http.StatusOK, false, time.Millisecond)
+
+	// Then
+	stats := tagger.Stats()
+	if stats.Untagged != 1 || len(stats.Tags) != 2 || stats.Tags[0].Tag != "agent-loop" {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	vision := stats.Tags[1]
+	if vision.Requests != 2 || vision.Errors != 1 || vision.Streams != 1 || vision.Duration.Max != 100 || vision.Duration.P50 != 10 {
+		t.Errorf("unexpected vision roll-up %+v", vision)
+	}
+	tagger.Reset()
+	if stats := tagger.Stats(); len(stats.Tags) != 0 || stats.Untagged != 0 {
+		t.Errorf("expected no roll-ups after reset, got %+v", stats)
+	}
+}
+
+func TestRequestTagger_Concurrent_RecordClassifyAndLoad(t *testing.T) {
+	// Given
+	tagger, _ := newRequestTagger([]tagConfig{{Tag: "chat", Match: tagMatchConfig{Path: "/v1/chat/completions"}}})
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+	var wg sync.WaitGroup
+
+	// When: more tags than are rolled up are recorded while rules are reloaded and stats are read
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range 500 {
+				tagger.Record([]string{fmt.Sprintf("t%d", (i*500+j)%(maxTagStats+50))}, http.StatusOK, false, time.Millisecond)
+				_ = tagger.Classify(r, nil)
+				if j%50 == 0 {
+					_ = tagger.Load([]tagConfig{{Tag: fmt.Sprintf("chat-%d", j)}})
+					_ = tagger.Stats()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Then: only the most recent tags are kept
+	if stats := tagger.Stats(); len(stats.Tags) != maxTagStats {
+		t.Errorf("expected %d tags, got %d", maxTagStats, len(stats.Tags))
+	}
+}
+
+// --- Begin ---
+
+func TestRequestTagger_Begin_RestoresBodyAndTagsContext(t *testing.T) {
+	// Given
+	tagger, _ := newRequestTagger([]tagConfig{{Tag: "chat", Match: tagMatchConfig{Model: "gpt"}}})
+	body := `{"model":"gpt-4o","stream":true}`
+	r := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	// When
+	w, r, done := tagger.Begin(rec, r)
+	got, _ := io.ReadAll(r.Body)
+	w.WriteHeader(http.StatusBadGateway)
+	done()
+
+	// Then
+	if string(got) != body || !slices.Equal(requestTagsFromContext(r.Context()), []string{"chat"}) {
+		t.Errorf("unexpected body %q or tags %v", got, requestTagsFromContext(r.Context()))
+	}
+	if s := tagger.Stats().Tags; len(s) != 1 || s[0].Errors != 1 || s[0].Streams != 1 {
+		t.Errorf("unexpected roll-up %+v", s)
+	}
+}
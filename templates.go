@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are the functions available to scenario content templates and scripts, besides the
+// template builtins. Randomness is drawn through scenarioData methods, from the request's seed.
+var templateFuncs = template.FuncMap{
+	"lower":        strings.ToLower,
+	"upper":        strings.ToUpper,
+	"trim":         strings.TrimSpace,
+	"contains":     strings.Contains,
+	"hasPrefix":    strings.HasPrefix,
+	"hasSuffix":    strings.HasSuffix,
+	"replace":      strings.ReplaceAll,
+	"jsonGet":      jsonGet,
+	"toJSON":       toJSON,
+	"regexMatch":   regexMatch,
+	"regexFind":    regexFind,
+	"regexCapture": regexCapture,
+	"tokens":       countTokens,
+	"now":          time.Now,
+	"dateAdd":      dateAdd,
+	"dateFormat":   dateFormat,
+	"unix":         func(t time.Time) int64 { return t.Unix() },
+	"b64enc":       func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"b64dec":       b64dec,
+}
+
+// jsonGet returns the value at a dot-separated path of decoded JSON, such as messages.0.content.
+// Negative indexes count from the end of arrays; a missing value is the empty string.
+func jsonGet(doc any, path string) any {
+	v := doc
+	for _, key := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]any:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return ""
+			}
+		case []any:
+			i, err := strconv.Atoi(key)
+			if i < 0 {
+				i += len(node)
+			}
+			if err != nil || i < 0 || i >= len(node) {
+				return ""
+			}
+			v = node[i]
+		default:
+			return ""
+		}
+	}
+	return v
+}
+
+// toJSON encodes a value as JSON.
+func toJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// regexMatch reports whether s contains a match of pattern.
+func regexMatch(pattern, s string) (bool, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
+
+// regexFind returns the first match of pattern in s, or the empty string.
+func regexFind(pattern, s string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.FindString(s), nil
+}
+
+// regexCapture returns group n of the first match of pattern in s, or the empty string.
+func regexCapture(pattern, s string, n int) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	if n < 0 || n > re.NumSubexp() {
+		return "", fmt.Errorf("pattern %q has no group %d", pattern, n)
+	}
+	if m := re.FindStringSubmatch(s); m != nil {
+		return m[n], nil
+	}
+	return "", nil
+}
+
+// dateAdd adds a duration such as 24h or -90m to t.
+func dateAdd(duration string, t time.Time) (time.Time, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return t, err
+	}
+	return t.Add(d), nil
+}
+
+// dateFormat formats t with a Go layout, or one of the names RFC3339, DateOnly, and DateTime.
+func dateFormat(layout string, t time.Time) string {
+	switch layout {
+	case "RFC3339":
+		layout = time.RFC3339
+	case "DateOnly":
+		layout = time.DateOnly
+	case "DateTime":
+		layout = time.DateTime
+	}
+	return t.UTC().Format(layout)
+}
+
+// b64dec decodes standard base64.
+func b64dec(s string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	return string(data), err
+}
+
+// random returns the source of a template's random choices, drawn from the request's seed.
+func (d *scenarioData) random() *rand.Rand {
+	if d.rand == nil {
+		d.rand = seededRand(d.Seed, "template")
+	}
+	return d.rand
+}
+
+// Choice returns one of items, chosen with the request's seed.
+func (d *scenarioData) Choice(items ...string) (string, error) {
+	if len(items) == 0 {
+		return "", fmt.Errorf("choice needs at least one item")
+	}
+	return items[d.random().IntN(len(items))], nil
+}
+
+// RandInt returns an integer in [0, n), chosen with the request's seed.
+func (d *scenarioData) RandInt(n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("random bound must be positive, got %d", n)
+	}
+	return d.random().IntN(n), nil
+}
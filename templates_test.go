@@ -0,0 +1,111 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+// renderTemplate executes a template with templateFuncs on data.
+func renderTemplate(t *testing.T, text string, data *scenarioData) (string, error) {
+	t.Helper()
+	tmpl, err := template.New("test").Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	err = tmpl.Execute(&b, data)
+	return b.String(), err
+}
+
+// --- templateFuncs ---
+
+func TestTemplateFuncs_Render(t *testing.T) {
+	// Given: a decoded chat request
+	body := map[string]any{"model": "gpt-4o", "temperature": 0.5, "messages": []any{
+		map[string]any{"role": "system", "content": "be brief"},
+		map[string]any{"role": "user", "content": "order #4521 is late"},
+	}}
+	data := &scenarioData{Body: body, Message: "order #4521 is late"}
+	cases := map[string]string{
+		`{{jsonGet .Body "messages.-1.content"}}`:               "order #4521 is late",
+		`{{jsonGet .Body "messages.0.role"}}`:                   "system",
+		`{{jsonGet .Body "temperature"}}`:                       "0.5",
+		`[{{jsonGet .Body "messages.5.content"}}]`:              "[]",
+		`{{toJSON (jsonGet .Body "messages.0")}}`:               `{"content":"be brief","role":"system"}`,
+		`{{regexCapture "#(\\d+)" .Message 1}}`:                 "4521",
+		`{{regexFind "\\d+" .Message}}`:                         "4521",
+		`{{if regexMatch "(?i)LATE" .Message}}yes{{end}}`:       "yes",
+		`{{tokens .Message}}`:                                   "6",
+		`{{b64enc "hi"}} {{b64dec "aGk="}}`:                     "aGk= hi",
+		`{{upper (trim "  x ")}}{{replace "a-b" "-" "+"}}`:      "Xa+b",
+		`{{dateAdd "24h" (now) | dateFormat "DateOnly" | len}}`: "10",
+	}
+	for text, want := range cases {
+		// When
+		got, err := renderTemplate(t, text, data)
+		// Then
+		if err != nil || got != want {
+			t.Errorf("%s: expected %q, got %q (%v)", text, want, got, err)
+		}
+	}
+}
+
+func TestTemplateFuncs_Errors(t *testing.T) {
+	// Given
+	for _, text := range []string{
+		`{{regexFind "(" "x"}}`,
+		`{{regexCapture "(a)" "a" 2}}`,
+		`{{b64dec "%%"}}`,
+		`{{dateAdd "soon" (now)}}`,
+		`{{.Choice}}`,
+		`{{.RandInt 0}}`,
+	} {
+		// When
+		_, err := renderTemplate(t, text, &scenarioData{})
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", text)
+		}
+	}
+}
+
+func TestDateFormat_NamedLayouts(t *testing.T) {
+	// Given
+	ts := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+	// When / Then
+	if got := dateFormat("RFC3339", ts); got != "2024-05-06T07:08:09Z" {
+		t.Errorf("unexpected RFC3339 %q", got)
+	}
+	if got := dateFormat("Jan 2", ts); got != "May 6" {
+		t.Errorf("unexpected custom layout %q", got)
+	}
+}
+
+// --- scenarioData.Choice / RandInt ---
+
+func TestScenarioData_Choice_SameSeedSameChoices(t *testing.T) {
+	// Given
+	text := `{{.Choice "a" "b" "c" "d"}}{{.Choice "a" "b" "c" "d"}}{{.RandInt 1000}}`
+	render := func(seed uint64) string {
+		got, err := renderTemplate(t, text, &scenarioData{Seed: seed})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+	// When
+	first, again := render(7), render(7)
+	// Then: the same seed repeats the choices, and some other seed differs
+	if first != again {
+		t.Errorf("expected the same choices, got %q and %q", first, again)
+	}
+	differs := false
+	for seed := uint64(8); seed < 20 && !differs; seed++ {
+		differs = render(seed) != first
+	}
+	if !differs {
+		t.Error("expected other seeds to choose differently")
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// maxFaultPeriods is the number of fault periods kept in the timeline, the oldest dropped first.
+const maxFaultPeriods = 1000
+
+// Kinds of fault periods.
+const (
+	faultKindChaos  = "chaos"
+	faultKindRegion = "region"
+)
+
+// faultPeriod is a span of time during which a chaos profile or a region outage was active, with the
+// faults it injected.
+type faultPeriod struct {
+	Kind string `json:"kind"`
+	// Name is the chaos profile or the region.
+	Name   string    `json:"name"`
+	Source string    `json:"source"`
+	Start  time.Time `json:"start"`
+	// End is nil while the period is active.
+	End *time.Time `json:"end"`
+	// Chaos is the profile of a chaos period and Region the health of a region period.
+	Chaos  *chaosProfileConfig `json:"chaos,omitempty"`
+	Region *regionConfig       `json:"region,omitempty"`
+	// Stats counts the requests the faults applied to; Errors counts region failures for regions.
+	Stats chaosStats `json:"stats"`
+}
+
+// faultTimeline records when chaos profiles and region outages were active, so errors seen by
+// clients during a test run can be correlated with the provider conditions injected at the time.
+// It is fed by chaosEngine and regionRouter and is safe for concurrent use.
+type faultTimeline struct {
+	mu      sync.Mutex
+	periods []*faultPeriod
+	// open indexes the active periods by faultPeriodKey.
+	open map[string]*faultPeriod
+	now  func() time.Time
+}
+
+// newFaultTimeline creates an empty timeline.
+func newFaultTimeline() *faultTimeline {
+	return &faultTimeline{open: map[string]*faultPeriod{}, now: time.Now}
+}
+
+// faultPeriodKey identifies an active period: only one chaos profile is active at a time, and each
+// region has its own outage.
+func faultPeriodKey(kind, name string) string {
+	if kind == faultKindChaos {
+		return kind
+	}
+	return kind + "/" + name
+}
+
+// begin ends the active period of the same kind and name, if any, and starts p. A nil timeline
+// records nothing.
+func (t *faultTimeline) begin(p faultPeriod) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	key := faultPeriodKey(p.Kind, p.Name)
+	t.end(key)
+	p.Start = t.now()
+	period := &p
+	t.open[key] = period
+	t.periods = append(t.periods, period)
+	if len(t.periods) > maxFaultPeriods {
+		t.periods[0] = nil
+		t.periods = t.periods[1:]
+	}
+}
+
+// finish ends the active chaos period, or the outage of a region.
+func (t *faultTimeline) finish(kind, name string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.end(faultPeriodKey(kind, name))
+}
+
+// finishAll ends every active period of a kind.
+func (t *faultTimeline) finishAll(kind string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, p := range t.open {
+		if p.Kind == kind {
+			t.end(key)
+		}
+	}
+}
+
+// end ends the active period under key. The caller holds t.mu.
+func (t *faultTimeline) end(key string) {
+	p, ok := t.open[key]
+	if !ok {
+		return
+	}
+	end := t.now()
+	p.End = &end
+	delete(t.open, key)
+}
+
+// count updates the stats of the active period of a kind and name, if any.
+func (t *faultTimeline) count(kind, name string, update func(*chaosStats)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if p, ok := t.open[faultPeriodKey(kind, name)]; ok {
+		update(&p.Stats)
+	}
+}
+
+// Periods returns the periods overlapping the window from since to until, oldest first. Zero times
+// leave the window open.
+func (t *faultTimeline) Periods(since, until time.Time) []faultPeriod {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	periods := make([]faultPeriod, 0, len(t.periods))
+	for _, p := range t.periods {
+		if !since.IsZero() && p.End != nil && p.End.Before(since) {
+			continue
+		}
+		if !until.IsZero() && p.Start.After(until) {
+			continue
+		}
+		period := *p
+		if p.End != nil {
+			end := *p.End
+			period.End = &end
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// Active returns the periods still active at time at, such as when a client saw an error.
+func (t *faultTimeline) Active(at time.Time) []faultPeriod {
+	active := []faultPeriod{}
+	for _, p := range t.Periods(at, at) {
+		if p.End == nil || p.End.After(at) {
+			active = append(active, p)
+		}
+	}
+	return active
+}
+
+// Reset drops the ended periods. Active periods are kept with their stats.
+func (t *faultTimeline) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	periods := make([]*faultPeriod, 0, len(t.open))
+	for _, p := range t.periods {
+		if p.End == nil {
+			periods = append(periods, p)
+		}
+	}
+	t.periods = periods
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// steppedTimeline returns a timeline whose clock advances by a minute on every reading, starting at t0.
+func steppedTimeline(t0 time.Time) *faultTimeline {
+	timeline := newFaultTimeline()
+	now := t0
+	timeline.now = func() time.Time {
+		now = now.Add(time.Minute)
+		return now
+	}
+	return timeline
+}
+
+// --- chaosEngine.Observe ---
+
+func TestFaultTimeline_ChaosActivationsBecomePeriods(t *testing.T) {
+	// Given: an engine with the outage profile active at startup, observed by a timeline
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	e, err := newChaosEngine(chaosConfig{Profile: "outage", Profiles: []chaosProfileConfig{{Name: "outage", ErrorRate: 1, ErrorStatus: 503}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeline := steppedTimeline(t0)
+	e.Observe(timeline)
+
+	// When: two requests fail, then flaky-network replaces outage and chaos is turned off
+	e.Draw(1)
+	e.Draw(2)
+	if _, err := e.Activate("flaky-network"); err != nil {
+		t.Fatal(err)
+	}
+	e.Deactivate()
+
+	// Then: both periods are recorded back to back with the faults they injected
+	periods := timeline.Periods(time.Time{}, time.Time{})
+	if len(periods) != 2 {
+		t.Fatalf("expected 2 periods, got %+v", periods)
+	}
+	outage, flaky := periods[0], periods[1]
+	if outage.Kind != faultKindChaos || outage.Name != "outage" || outage.Source != chaosSourceConfig || outage.Chaos.ErrorStatus != 503 {
+		t.Errorf("unexpected outage period %+v", outage)
+	}
+	if outage.Stats.Requests != 2 || outage.Stats.Errors != 2 {
+		t.Errorf("expected 2 failed requests, got %+v", outage.Stats)
+	}
+	if outage.End == nil || flaky.End == nil || !outage.Start.Before(*outage.End) || !outage.End.Before(*flaky.End) {
+		t.Errorf("expected consecutive ended periods, got %+v and %+v", outage, flaky)
+	}
+	if flaky.Name != "flaky-network" || flaky.Source != chaosSourceAdmin || flaky.Stats.Requests != 0 {
+		t.Errorf("unexpected flaky-network period %+v", flaky)
+	}
+}
+
+// --- regionRouter.Observe ---
+
+func TestFaultTimeline_RegionOutagesBecomePeriods(t *testing.T) {
+	// Given: eu is down and us is healthy
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	rr, err := newRegionRouter([]regionConfig{{Name: "eu", Status: 503}, {Name: "us"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeline := steppedTimeline(t0)
+	rr.Observe(timeline)
+
+	// When: eu fails a request and recovers, and us goes down
+	rr.Route(regionRequest("eu"))
+	rr.Route(regionRequest("us"))
+	if _, err := rr.Set(regionConfig{Name: "eu"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rr.Set(regionConfig{Name: "us", Latency: "2s"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: only unhealthy regions have periods, and us is still active
+	periods := timeline.Periods(time.Time{}, time.Time{})
+	if len(periods) != 2 {
+		t.Fatalf("expected 2 periods, got %+v", periods)
+	}
+	eu, us := periods[0], periods[1]
+	if eu.Name != "eu" || eu.End == nil || eu.Stats.Requests != 1 || eu.Stats.Errors != 1 || eu.Region.Status != 503 {
+		t.Errorf("unexpected eu period %+v", eu)
+	}
+	if us.Name != "us" || us.End != nil || us.Source != regionSourceAdmin || us.Region.Latency != "2s" {
+		t.Errorf("unexpected us period %+v", us)
+	}
+
+	// When: the config is reloaded with every region healthy
+	if err := rr.Load([]regionConfig{{Name: "eu"}, {Name: "us"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: the us outage has ended
+	if periods := timeline.Periods(time.Time{}, time.Time{}); periods[1].End == nil {
+		t.Errorf("expected the us outage to end on reload, got %+v", periods[1])
+	}
+}
+
+// --- faultTimeline.Active ---
+
+func TestFaultTimeline_Active_ReturnsPeriodsAtAnInstant(t *testing.T) {
+	// Given: chaos from t0+1m to t0+3m and an eu outage from t0+2m on
+	t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	timeline := steppedTimeline(t0)
+	timeline.begin(faultPeriod{Kind: faultKindChaos, Name: "flaky-network"})
+	timeline.begin(faultPeriod{Kind: faultKindRegion, Name: "eu"})
+	timeline.finish(faultKindChaos, "")
+
+	cases := []struct {
+		at   time.Duration
+		want int
+	}{{30 * time.Second, 0}, {90 * time.Second, 1}, {150 * time.Second, 2}, {time.Hour, 1}}
+	for _, tc := range cases {
+		// When
+		active := timeline.Active(t0.Add(tc.at))
+		// Then
+		if len(active) != tc.want {
+			t.Errorf("at %s: expected %d active periods, got %+v", tc.at, tc.want, active)
+		}
+	}
+
+	// When: the timeline is reset
+	timeline.Reset()
+
+	// Then: only the active outage is kept
+	if periods := timeline.Periods(time.Time{}, time.Time{}); len(periods) != 1 || periods[0].Name != "eu" {
+		t.Errorf("expected the eu outage only, got %+v", periods)
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCert is a certificate and key issued for tests.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// issueTestCert issues a certificate from template, signed by parent (self-signed if nil).
+func issueTestCert(t *testing.T, template *x509.Certificate, parent *testCert) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.SerialNumber = big.NewInt(time.Now().UnixNano())
+	template.NotBefore, template.NotAfter = time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// writePEM writes the certificate and key of c to name.pem and name-key.pem in dir.
+func (c *testCert) writePEM(t *testing.T, dir, name string) (certFile, keyFile string) {
+	t.Helper()
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile = filepath.Join(dir, name+".pem"), filepath.Join(dir, name+"-key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// tlsCertificate returns c as a tls.Certificate.
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key, Leaf: c.cert}
+}
+
+// testPKI is a CA with a server certificate for 127.0.0.1 and the tls config section using them.
+type testPKI struct {
+	ca  *testCert
+	cfg tlsConfig
+}
+
+// newTestPKI issues a CA and a server certificate and writes them to a temporary directory.
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+	dir := t.TempDir()
+	ca := issueTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "mesh-ca"}, IsCA: true, BasicConstraintsValid: true,
+		KeyUsage: x509.KeyUsageCertSign}, nil)
+	server := issueTestCert(t, &x509.Certificate{Subject: pkix.Name{CommonName: "mokku"}, IPAddresses: []net.IP{net.IPv4(127, 0, 0, 1)},
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, ca)
+	caFile, _ := ca.writePEM(t, dir, "ca")
+	certFile, keyFile := server.writePEM(t, dir, "server")
+	return testPKI{ca: ca, cfg: tlsConfig{CertFile: certFile, KeyFile: keyFile, ClientCAFile: caFile}}
+}
+
+// client issues a client certificate for template and returns an HTTP client presenting it (none
+// for a nil template) and trusting the CA.
+func (p testPKI) client(t *testing.T, template *x509.Certificate) *http.Client {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(p.ca.cert)
+	tc := &tls.Config{RootCAs: roots}
+	if template != nil {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		tc.Certificates = []tls.Certificate{issueTestCert(t, template, p.ca).tlsCertificate()}
+	}
+	transport := &http.Transport{TLSClientConfig: tc}
+	t.Cleanup(transport.CloseIdleConnections)
+	return &http.Client{Transport: transport}
+}
+
+// --- newServerTLSConfig ---
+
+func TestNewServerTLSConfig_RejectsIncompleteSettings(t *testing.T) {
+	// Given
+	pki := newTestPKI(t)
+	for name, cfg := range map[string]tlsConfig{
+		"cert without key":       {CertFile: pki.cfg.CertFile},
+		"client CA without cert": {ClientCAFile: pki.cfg.ClientCAFile},
+		"missing cert file":      {CertFile: "nope.pem", KeyFile: pki.cfg.KeyFile},
+		"CA without PEM":         {CertFile: pki.cfg.CertFile, KeyFile: pki.cfg.KeyFile, ClientCAFile: pki.cfg.KeyFile},
+	} {
+		// When
+		_, err := newServerTLSConfig(cfg)
+		// Then
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestNewServerTLSConfig_OffWithoutSettings(t *testing.T) {
+	// When
+	tc, err := newServerTLSConfig(tlsConfig{})
+	// Then
+	if err != nil || tc != nil {
+		t.Errorf("expected TLS off, got %v, %v", tc, err)
+	}
+}
+
+// --- clientCertNames ---
+
+func TestClientCertNames_ReturnsCommonNameAndSANs(t *testing.T) {
+	// Given: a request with a verified certificate
+	spiffe, _ := url.Parse("spiffe://mesh/ns/billing/sa/api")
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}, DNSNames: []string{"billing.svc"}, URIs: []*url.URL{spiffe},
+		EmailAddresses: []string{"billing@example.com"}}
+	r := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	r.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+
+	// When
+	names := clientCertNames(r)
+
+	// Then
+	want := []string{"billing", "billing.svc", "spiffe://mesh/ns/billing/sa/api", "billing@example.com"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("expected %v, got %v", want, names)
+	}
+}
+
+// --- mTLS ---
+
+func TestIntegration_MTLS_RequiresClientCertificateAndMapsTenant(t *testing.T) {
+	// Given: mokku requiring client certificates, with a tenant mapped to a SPIFFE ID
+	pki := newTestPKI(t)
+	tc, err := newServerTLSConfig(pki.cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newUnstartedTestServer(t, Config{TLS: pki.cfg, RateLimits: rateLimitConfig{Tenants: []tenantBudgetConfig{
+		{Name: "billing", ClientCerts: []string{"spiffe://mesh/ns/billing/sa/api"}, rateLimitBudget: rateLimitBudget{RequestsPerMinute: 7}},
+	}}}, "")
+	srv.Config.Handler = withClientCertificates(srv.Config.Handler)
+	srv.TLS = tc
+	srv.StartTLS()
+	defer srv.Close()
+	spiffe, _ := url.Parse("spiffe://mesh/ns/billing/sa/api")
+	billing := pki.client(t, &x509.Certificate{Subject: pkix.Name{CommonName: "billing-api"}, URIs: []*url.URL{spiffe}})
+	anonymous := pki.client(t, nil)
+	body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`
+
+	// When
+	withCert, err := billing.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST with a certificate: %v", err)
+	}
+	_ = withCert.Body.Close()
+	withoutCert, err := anonymous.Post(srv.URL+"/v1/chat/completions", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST without a certificate: %v", err)
+	}
+	defer func() { _ = withoutCert.Body.Close() }()
+	health, err := anonymous.Get(srv.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("GET healthz: %v", err)
+	}
+	_ = health.Body.Close()
+
+	// Then: the certificate's tenant budget applies, and only the health check needs no certificate
+	if withCert.StatusCode != http.StatusOK || withCert.Header.Get("x-ratelimit-limit-requests") != "7" {
+		t.Errorf("expected 200 with the billing budget, got %d %q", withCert.StatusCode, withCert.Header.Get("x-ratelimit-limit-requests"))
+	}
+	if withoutCert.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 without a certificate, got %d", withoutCert.StatusCode)
+	}
+	if errBody := mustDecodeJSON(t, withoutCert.Body)["error"].(map[string]interface{}); errBody["code"] != "client_certificate_required" {
+		t.Errorf("expected client_certificate_required, got %v", errBody)
+	}
+	if health.StatusCode != http.StatusOK {
+		t.Errorf("expected the health check to pass without a certificate, got %d", health.StatusCode)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ogen-go/ogen/middleware"
+	"github.com/ogen-go/ogen/otelogen"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"openai-mokku/api"
+)
+
+// requestTrace is the tracing state of an API request. Every span of a request is a child of the span
+// propagated by the client, whether ogen or StreamingHandler serves the operation, and carries the
+// same request attributes as ogen's operation spans. The spans StreamingHandler starts before the
+// operation, such as rate limits and scenarios, are linked from the operation span, so trace
+// assertions can find them from either side. It is safe for concurrent use.
+type requestTrace struct {
+	// operation is the ogen operation name, empty when no operation matches the request.
+	operation string
+	attrs     []attribute.KeyValue
+
+	mu    sync.Mutex
+	links []trace.Link
+	// steps are the request spans, and operationSpan the operation span once it started, kept for the
+	// capture of the request. parent is the span propagated by the client.
+	steps         []requestStep
+	operationSpan trace.SpanContext
+	parent        trace.SpanContext
+}
+
+// requestStep is a step StreamingHandler took for a request before the operation, such as a scenario
+// match or an injected fault, as recorded on its span.
+type requestStep struct {
+	Name       string            `json:"name"`
+	SpanID     string            `json:"span_id,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// capturedTrace is the tracing state of a captured request: the trace and operation span IDs, valid
+// when the request was traced or propagated a trace, and the steps taken before the operation.
+type capturedTrace struct {
+	TraceID string        `json:"trace_id,omitempty"`
+	SpanID  string        `json:"span_id,omitempty"`
+	Steps   []requestStep `json:"steps,omitempty"`
+}
+
+type requestTraceContextKey struct{}
+
+// withRequestTrace continues the trace propagated in the headers of an API request and prepares the
+// request attributes from the operation of the ogen server matching it.
+func withRequestTrace(ctx context.Context, ogenServer http.Handler, r *http.Request) context.Context {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(r.Header))
+	t := &requestTrace{attrs: []attribute.KeyValue{semconv.HTTPRequestMethodKey.String(r.Method), semconv.URLPath(r.URL.Path)}, parent: trace.SpanContextFromContext(ctx)}
+	if s, ok := ogenServer.(*api.Server); ok {
+		if route, ok := s.FindRoute(r.Method, r.URL.Path); ok {
+			t.operation = route.Name()
+			t.attrs = []attribute.KeyValue{
+				otelogen.OperationID(route.OperationID()),
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.HTTPRouteKey.String(route.PathPattern()),
+			}
+		}
+	}
+	return context.WithValue(ctx, requestTraceContextKey{}, t)
+}
+
+// requestTraceFromContext returns the tracing state of an API request, or nil outside of one.
+func requestTraceFromContext(ctx context.Context) *requestTrace {
+	t, _ := ctx.Value(requestTraceContextKey{}).(*requestTrace)
+	return t
+}
+
+// startRequestSpan starts a span for a step StreamingHandler takes before the operation, with the
+// request attributes, and records it to be linked from the operation span.
+func startRequestSpan(r *http.Request, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	t := requestTraceFromContext(r.Context())
+	if t == nil {
+		return tracer.Start(r.Context(), name, trace.WithAttributes(attrs...))
+	}
+	ctx, span := tracer.Start(r.Context(), name, trace.WithAttributes(t.attrs...), trace.WithAttributes(attrs...))
+	step := requestStep{Name: name}
+	if sc := span.SpanContext(); sc.HasSpanID() {
+		step.SpanID = sc.SpanID().String()
+	}
+	if len(attrs) > 0 {
+		step.Attributes = make(map[string]string, len(attrs))
+		for _, attr := range attrs {
+			step.Attributes[string(attr.Key)] = attr.Value.Emit()
+		}
+	}
+	t.mu.Lock()
+	t.links = append(t.links, trace.Link{SpanContext: span.SpanContext()})
+	t.steps = append(t.steps, step)
+	t.mu.Unlock()
+	return ctx, span
+}
+
+// captured returns the tracing state of the request for its capture, or nil outside of a request. The
+// trace ID is that of the client's trace when no operation span started, as for requests rejected
+// before the operation.
+func (t *requestTrace) captured() *capturedTrace {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	c := &capturedTrace{Steps: slices.Clone(t.steps)}
+	if t.operationSpan.HasTraceID() {
+		c.TraceID = t.operationSpan.TraceID().String()
+	} else if t.parent.HasTraceID() {
+		c.TraceID = t.parent.TraceID().String()
+	}
+	if t.operationSpan.HasSpanID() {
+		c.SpanID = t.operationSpan.SpanID().String()
+	}
+	return c
+}
+
+// linkRequestSpans links an operation span to the spans started by startRequestSpan for its request,
+// and records it as the operation span of the request.
+func linkRequestSpans(ctx context.Context, span trace.Span) {
+	t := requestTraceFromContext(ctx)
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, link := range t.links {
+		span.AddLink(link)
+	}
+	t.operationSpan = span.SpanContext()
+}
+
+// startOperationSpan starts the server span of an operation StreamingHandler serves itself, such as a
+// streaming chat completion, named and attributed like the span ogen starts for the operations it
+// serves, and returns the request with the span in its context.
+func startOperationSpan(r *http.Request) (*http.Request, trace.Span) {
+	name, attrs := r.Method+" "+r.URL.Path, []attribute.KeyValue(nil)
+	if t := requestTraceFromContext(r.Context()); t != nil {
+		if t.operation != "" {
+			name = t.operation
+		}
+		attrs = t.attrs
+	}
+	ctx, span := tracer.Start(r.Context(), name, trace.WithAttributes(attrs...), trace.WithSpanKind(trace.SpanKindServer))
+	linkRequestSpans(ctx, span)
+	return r.WithContext(ctx), span
+}
+
+// linkRequestSpansMiddleware is the ogen middleware linking the operation spans ogen starts to the
+// request spans.
+func linkRequestSpansMiddleware(req middleware.Request, next middleware.Next) (middleware.Response, error) {
+	linkRequestSpans(req.Context, trace.SpanFromContext(req.Context))
+	return next(req)
+}
+
+// Samplers selected by OTEL_TRACES_SAMPLER, named as in the OpenTelemetry SDK configuration.
+const (
+	samplerAlwaysOn                = "always_on"
+	samplerAlwaysOff               = "always_off"
+	samplerTraceIDRatio            = "traceidratio"
+	samplerParentBasedAlwaysOn     = "parentbased_always_on"
+	samplerParentBasedAlwaysOff    = "parentbased_always_off"
+	samplerParentBasedTraceIDRatio = "parentbased_traceidratio"
+)
+
+// tracingConfig is the tracing setup taken from the environment. The defaults record every span in
+// full; load tests sample and trim them so the tracing pipeline keeps up.
+type tracingConfig struct {
+	// Sampler decides which traces are recorded (OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG).
+	Sampler sdktrace.Sampler
+	// AttributeLimit caps the length of string attribute values; 0 means no limit
+	// (MOKKU_TRACE_ATTRIBUTE_LIMIT).
+	AttributeLimit int
+	// Bodies records request and response bodies and message contents on spans (MOKKU_TRACE_BODIES).
+	Bodies bool
+}
+
+// tracingConfigFromEnv reads OTEL_TRACES_SAMPLER, OTEL_TRACES_SAMPLER_ARG, MOKKU_TRACE_ATTRIBUTE_LIMIT,
+// and MOKKU_TRACE_BODIES.
+func tracingConfigFromEnv(getenv func(string) string) (tracingConfig, error) {
+	cfg := tracingConfig{Sampler: sdktrace.AlwaysSample(), Bodies: true}
+	ratio := 1.0
+	arg := getenv("OTEL_TRACES_SAMPLER_ARG")
+	if arg != "" {
+		var err error
+		ratio, err = strconv.ParseFloat(arg, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return tracingConfig{}, fmt.Errorf("OTEL_TRACES_SAMPLER_ARG must be a ratio from 0 to 1, got %q", arg)
+		}
+	}
+	switch value := getenv("OTEL_TRACES_SAMPLER"); value {
+	case "", samplerAlwaysOn:
+	case samplerAlwaysOff:
+		cfg.Sampler = sdktrace.NeverSample()
+	case samplerTraceIDRatio:
+		cfg.Sampler = sdktrace.TraceIDRatioBased(ratio)
+	case samplerParentBasedAlwaysOn:
+		cfg.Sampler = sdktrace.ParentBased(sdktrace.AlwaysSample())
+	case samplerParentBasedAlwaysOff:
+		cfg.Sampler = sdktrace.ParentBased(sdktrace.NeverSample())
+	case samplerParentBasedTraceIDRatio:
+		cfg.Sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))
+	default:
+		return tracingConfig{}, fmt.Errorf("OTEL_TRACES_SAMPLER must be one of %s, %s, %s, %s, %s, or %s, got %q",
+			samplerAlwaysOn, samplerAlwaysOff, samplerTraceIDRatio, samplerParentBasedAlwaysOn, samplerParentBasedAlwaysOff,
+			samplerParentBasedTraceIDRatio, value)
+	}
+	if value := getenv("MOKKU_TRACE_ATTRIBUTE_LIMIT"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 0 {
+			return tracingConfig{}, fmt.Errorf("MOKKU_TRACE_ATTRIBUTE_LIMIT must be a non-negative integer, got %q", value)
+		}
+		cfg.AttributeLimit = limit
+	}
+	if value := getenv("MOKKU_TRACE_BODIES"); value != "" {
+		bodies, err := strconv.ParseBool(value)
+		if err != nil {
+			return tracingConfig{}, fmt.Errorf("MOKKU_TRACE_BODIES must be true or false, got %q", value)
+		}
+		cfg.Bodies = bodies
+	}
+	return cfg, nil
+}
+
+// tracerProviderOptions returns the sampler and span limits of the tracer provider.
+func (c tracingConfig) tracerProviderOptions() []sdktrace.TracerProviderOption {
+	limits := sdktrace.NewSpanLimits()
+	if c.AttributeLimit > 0 {
+		limits.AttributeValueLengthLimit = c.AttributeLimit
+	}
+	return []sdktrace.TracerProviderOption{sdktrace.WithSampler(c.Sampler), sdktrace.WithSpanLimits(limits)}
+}
+
+// omitSpanBodies drops bodies and message contents from spans, as set by MOKKU_TRACE_BODIES. Like the
+// global tracer provider, it applies to the whole process.
+var omitSpanBodies atomic.Bool
+
+// setSpanBody records a request or response body as JSON on a span, unless bodies are omitted or the
+// span is not sampled, so the body is not even marshalled then.
+func setSpanBody(span trace.Span, key string, body any) {
+	if omitSpanBodies.Load() || !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(attribute.String(key, marshalJSON(body)))
+}
+
+// setSpanText records generated text, such as a completion, on a span, unless bodies are omitted.
+func setSpanText(span trace.Span, key, text string) {
+	if omitSpanBodies.Load() {
+		return
+	}
+	span.SetAttributes(attribute.String(key, text))
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	spanRecorderOnce sync.Once
+	spanRecorder     *tracetest.SpanRecorder
+)
+
+// recordSpans installs a global tracer provider recording every span, once per test binary, as
+// tracers obtained before the first provider is set keep delegating to it.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	spanRecorderOnce.Do(func() {
+		spanRecorder = tracetest.NewSpanRecorder()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spanRecorder)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	return spanRecorder
+}
+
+// waitForSpan returns the ended span with a name in a trace, waiting for the handler to end it after
+// the response was sent.
+func waitForSpan(t *testing.T, recorder *tracetest.SpanRecorder, traceID trace.TraceID, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		for _, span := range recorder.Ended() {
+			if span.SpanContext().TraceID() == traceID && span.Name() == name {
+				return span
+			}
+		}
+	}
+	t.Fatalf("span %s not recorded", name)
+	return nil
+}
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) string {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value.Emit()
+		}
+	}
+	return ""
+}
+
+func TestIntegration_Tracing_RequestSpansShareTheCallersTraceAndAreLinked(t *testing.T) {
+	recorder := recordSpans(t)
+	for _, tt := range []struct {
+		name   string
+		stream bool
+	}{
+		{name: "served by ogen"},
+		{name: "streamed", stream: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given: a cold model, and a caller propagating its trace
+			srv := newTestServerWithConfig(t, Config{ColdStart: map[string]coldStartConfig{"gpt-4o": {Latency: "1ms"}}})
+			defer srv.Close()
+			traceID, parentID := trace.TraceID{0x4b, 0xf9, 0x2f}, trace.SpanID{0x00, 0xf0, 0x67}
+			if tt.stream {
+				traceID[15], parentID[7] = 1, 1
+			}
+			body := `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]`
+			if tt.stream {
+				body += `,"stream":true`
+			}
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body+"}"))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Traceparent", "00-"+traceID.String()+"-"+parentID.String()+"-01")
+
+			// When
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			// Then: the cold start and operation spans are children of the caller's span with the same
+			// request attributes, and the operation span links the cold start span
+			coldStart := waitForSpan(t, recorder, traceID, "ColdStart.wait")
+			operation := waitForSpan(t, recorder, traceID, "CreateChatCompletion")
+			for _, span := range []sdktrace.ReadOnlySpan{coldStart, operation} {
+				if span.Parent().SpanID() != parentID {
+					t.Errorf("%s: expected parent %s, got %s", span.Name(), parentID, span.Parent().SpanID())
+				}
+				if spanAttribute(span, "http.route") != "/chat/completions" || spanAttribute(span, "http.request.method") != http.MethodPost {
+					t.Errorf("%s: unexpected attributes %v", span.Name(), span.Attributes())
+				}
+			}
+			if operation.SpanKind() != trace.SpanKindServer {
+				t.Errorf("expected a server span, got %s", operation.SpanKind())
+			}
+			if links := operation.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != coldStart.SpanContext().SpanID() {
+				t.Errorf("expected a link to the cold start span, got %+v", links)
+			}
+		})
+	}
+}
+
+func TestIntegration_Tracing_GenAIAttributesAndEvents(t *testing.T) {
+	recorder := recordSpans(t)
+	for _, tt := range []struct {
+		name   string
+		stream bool
+		span   string
+	}{
+		{name: "served by ogen", span: "CreateChatCompletion.process"},
+		{name: "streamed", stream: true, span: "CreateChatCompletion.streaming"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// Given
+			srv := newTestServer(t)
+			defer srv.Close()
+			traceID := trace.TraceID{0x9e, 0x11}
+			if tt.stream {
+				traceID[15] = 1
+			}
+			body := fmt.Sprintf(`{"model":"gpt-4o","temperature":0.5,"stream":%t,"messages":[{"role":"system","content":"be brief"},{"role":"user","content":"hi"}]}`, tt.stream)
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Traceparent", "00-"+traceID.String()+"-00f067aa0ba902b7-01")
+
+			// When
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("POST: %v", err)
+			}
+			_ = resp.Body.Close()
+
+			// Then: the request, response, and usage use the GenAI conventions, and the prompts and
+			// the completion are events
+			span := waitForSpan(t, recorder, traceID, tt.span)
+			for key, want := range map[attribute.Key]string{
+				"gen_ai.provider.name":           "openai",
+				"gen_ai.operation.name":          "chat",
+				"gen_ai.request.model":           "gpt-4o",
+				"gen_ai.request.temperature":     "0.5",
+				"gen_ai.response.finish_reasons": `["stop"]`,
+				"gen_ai.usage.input_tokens":      strconv.Itoa(countTokens("be brief") + countTokens("hi")),
+			} {
+				if got := spanAttribute(span, key); got != want {
+					t.Errorf("%s: expected %q, got %q", key, want, got)
+				}
+			}
+			if spanAttribute(span, "model") != "" || spanAttribute(span, "last_user_message") != "" {
+				t.Errorf("expected no ad-hoc attributes, got %v", span.Attributes())
+			}
+			var events []string
+			for _, event := range span.Events() {
+				events = append(events, event.Name)
+			}
+			if strings.Join(events, ",") != "gen_ai.system.message,gen_ai.user.message,gen_ai.choice" {
+				t.Errorf("unexpected events %v", events)
+			}
+		})
+	}
+}
+
+func TestIntegration_Tracing_BodiesOmitted(t *testing.T) {
+	// Given: bodies are left out of spans
+	recorder := recordSpans(t)
+	omitSpanBodies.Store(true)
+	t.Cleanup(func() { omitSpanBodies.Store(false) })
+	srv := newTestServer(t)
+	defer srv.Close()
+	traceID := trace.TraceID{0x5a, 0x3c}
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/v1/chat/completions",
+		strings.NewReader(`{"model":"gpt-4o","messages":[{"role":"user","content":"secret"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Traceparent", "00-"+traceID.String()+"-00f067aa0ba902b7-01")
+
+	// When
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	// Then: the span keeps its GenAI attributes and events, without bodies or contents
+	span := waitForSpan(t, recorder, traceID, "CreateChatCompletion.process")
+	if spanAttribute(span, "request.full_json") != "" || spanAttribute(span, "response.full_json") != "" {
+		t.Errorf("expected no bodies, got %v", span.Attributes())
+	}
+	if spanAttribute(span, "gen_ai.request.model") != "gpt-4o" || len(span.Events()) != 2 {
+		t.Errorf("expected the GenAI attributes and events, got %v %v", span.Attributes(), span.Events())
+	}
+	for _, event := range span.Events() {
+		for _, kv := range event.Attributes {
+			if kv.Key == genAIContentKey {
+				t.Errorf("expected no content in %s, got %q", event.Name, kv.Value.Emit())
+			}
+		}
+	}
+}
+
+// --- tracingConfigFromEnv ---
+
+func TestTracingConfigFromEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		sampler string
+		limit   int
+		bodies  bool
+		wantErr string
+	}{
+		{name: "defaults", sampler: "AlwaysOnSampler", bodies: true},
+		{name: "parent-based ratio", env: map[string]string{"OTEL_TRACES_SAMPLER": "parentbased_traceidratio", "OTEL_TRACES_SAMPLER_ARG": "0.01"},
+			sampler: "ParentBased{root:TraceIDRatioBased{0.01}", bodies: true},
+		{name: "limits and no bodies", env: map[string]string{"MOKKU_TRACE_ATTRIBUTE_LIMIT": "1024", "MOKKU_TRACE_BODIES": "false"},
+			sampler: "AlwaysOnSampler", limit: 1024},
+		{name: "unknown sampler", env: map[string]string{"OTEL_TRACES_SAMPLER": "sometimes"}, wantErr: "OTEL_TRACES_SAMPLER must be one of"},
+		{name: "ratio out of range", env: map[string]string{"OTEL_TRACES_SAMPLER": "traceidratio", "OTEL_TRACES_SAMPLER_ARG": "2"},
+			wantErr: "OTEL_TRACES_SAMPLER_ARG must be a ratio"},
+		{name: "negative limit", env: map[string]string{"MOKKU_TRACE_ATTRIBUTE_LIMIT": "-1"}, wantErr: "MOKKU_TRACE_ATTRIBUTE_LIMIT"},
+		{name: "invalid bodies", env: map[string]string{"MOKKU_TRACE_BODIES": "sometimes"}, wantErr: "MOKKU_TRACE_BODIES"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// When
+			cfg, err := tracingConfigFromEnv(func(key string) string { return tt.env[key] })
+
+			// Then
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(cfg.Sampler.Description(), tt.sampler) || cfg.AttributeLimit != tt.limit || cfg.Bodies != tt.bodies {
+				t.Errorf("unexpected config %+v (%s)", cfg, cfg.Sampler.Description())
+			}
+		})
+	}
+}
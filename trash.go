@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultRestoreWindow is how long deleted scenario rules can be restored without
+// admin.restore_window.
+const defaultRestoreWindow = time.Hour
+
+// maxDeletedScenarios is the number of deleted scenario rules kept; beyond it the oldest deletion is
+// dropped even within the restore window.
+const maxDeletedScenarios = 1000
+
+// Reasons a scenario rule was deleted.
+const (
+	// deleteReasonDeleted is a rule deleted with DELETE /_mokku/scenarios/{name}.
+	deleteReasonDeleted = "deleted"
+	// deleteReasonReplaced is a rule left out of the scenario file sent with PUT /_mokku/scenarios.
+	deleteReasonReplaced = "replaced"
+)
+
+var (
+	// errScenarioNotDeleted is the error of restoring a rule that was not deleted, or whose restore
+	// window has passed.
+	errScenarioNotDeleted = errors.New("no deleted scenario with this name can be restored")
+	// errScenarioActive is the error of restoring a rule while a rule with the same name is active.
+	errScenarioActive = errors.New("a scenario with this name is active")
+)
+
+// parseRestoreWindow parses admin.restore_window: a duration, "0s" making deletions final, or
+// defaultRestoreWindow when empty.
+func parseRestoreWindow(value string) (time.Duration, error) {
+	if value == "" {
+		return defaultRestoreWindow, nil
+	}
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return 0, fmt.Errorf("admin.restore_window must be a duration such as 1h, got %q", value)
+	}
+	return window, nil
+}
+
+// deletedScenario is a scenario rule deleted through the control API, restorable until ExpiresAt.
+type deletedScenario struct {
+	Name string `json:"name"`
+	// Position is the index of the rule in match order when it was deleted; it is restored there.
+	Position  int            `json:"position"`
+	Reason    string         `json:"reason"`
+	DeletedAt time.Time      `json:"deleted_at"`
+	ExpiresAt time.Time      `json:"expires_at"`
+	Scenario  scenarioConfig `json:"scenario"`
+	rule      *scenarioRule
+}
+
+// scenarioTrash keeps the scenario rules deleted through the control API for the restore window, so
+// a shared team's rules deleted by mistake mid-run can be restored instead of provisioned again. It
+// is safe for concurrent use.
+type scenarioTrash struct {
+	mu      sync.Mutex
+	window  time.Duration
+	now     func() time.Time
+	deleted []deletedScenario
+}
+
+// newScenarioTrash creates an empty trash keeping deletions for defaultRestoreWindow.
+func newScenarioTrash() *scenarioTrash {
+	return &scenarioTrash{window: defaultRestoreWindow, now: time.Now}
+}
+
+// SetWindow changes the restore window of later deletions.
+func (t *scenarioTrash) SetWindow(window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.window = window
+}
+
+// Add keeps rule, deleted from position for reason, and returns its record. Nothing is kept with a
+// zero window; the record then expires as it is deleted.
+func (t *scenarioTrash) Add(rule *scenarioRule, position int, reason string) deletedScenario {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	d := deletedScenario{Name: rule.name, Position: position, Reason: reason,
+		DeletedAt: now, ExpiresAt: now.Add(t.window), Scenario: rule.cfg, rule: rule}
+	if t.window == 0 {
+		return d
+	}
+	t.deleted = append(t.deleted, d)
+	if len(t.deleted) > maxDeletedScenarios {
+		t.deleted = slices.Delete(t.deleted, 0, len(t.deleted)-maxDeletedScenarios)
+	}
+	return d
+}
+
+// List returns the restorable rules, oldest deletion first.
+func (t *scenarioTrash) List() []deletedScenario {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expireLocked()
+	return slices.Clone(t.deleted)
+}
+
+// Take removes and returns the last restorable rule deleted with name.
+func (t *scenarioTrash) Take(name string) (deletedScenario, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.expireLocked()
+	for i := len(t.deleted) - 1; i >= 0; i-- {
+		if d := t.deleted[i]; d.Name == name {
+			t.deleted = slices.Delete(t.deleted, i, i+1)
+			return d, true
+		}
+	}
+	return deletedScenario{}, false
+}
+
+func (t *scenarioTrash) expireLocked() {
+	now := t.now()
+	t.deleted = slices.DeleteFunc(t.deleted, func(d deletedScenario) bool { return !now.Before(d.ExpiresAt) })
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// --- parseRestoreWindow ---
+
+func TestParseRestoreWindow(t *testing.T) {
+	// Given/When/Then: the window defaults to an hour, 0s makes deletions final, and a negative or
+	// invalid window is rejected
+	if window, err := parseRestoreWindow(""); err != nil || window != defaultRestoreWindow {
+		t.Errorf("expected the default window, got %v %v", window, err)
+	}
+	if window, err := parseRestoreWindow("0s"); err != nil || window != 0 {
+		t.Errorf("expected no window, got %v %v", window, err)
+	}
+	for _, value := range []string{"-1m", "soon"} {
+		if _, err := parseRestoreWindow(value); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+// --- scenarioTrash ---
+
+func TestScenarioTrash_ExpiresDeletionsAfterTheWindow(t *testing.T) {
+	// Given: a trash with a 10 minute window and a controllable clock
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	trash := newScenarioTrash()
+	trash.now = func() time.Time { return now }
+	trash.SetWindow(10 * time.Minute)
+
+	// When: a rule is deleted and the clock moves past the window
+	trash.Add(&scenarioRule{name: "greeting"}, 2, deleteReasonDeleted)
+	now = now.Add(9 * time.Minute)
+	kept := trash.List()
+	now = now.Add(time.Minute)
+
+	// Then: it is restorable within the window only
+	if len(kept) != 1 || kept[0].Position != 2 || !kept[0].ExpiresAt.Equal(kept[0].DeletedAt.Add(10*time.Minute)) {
+		t.Errorf("expected the rule within the window, got %+v", kept)
+	}
+	if _, ok := trash.Take("greeting"); ok {
+		t.Error("expected the rule to expire with the window")
+	}
+}
+
+func TestScenarioTrash_TakeReturnsTheLastDeletion(t *testing.T) {
+	// Given: two deletions of rules with the same name
+	trash := newScenarioTrash()
+	trash.Add(&scenarioRule{name: "greeting"}, 0, deleteReasonReplaced)
+	trash.Add(&scenarioRule{name: "greeting"}, 3, deleteReasonDeleted)
+
+	// When: the name is taken twice
+	first, ok1 := trash.Take("greeting")
+	second, ok2 := trash.Take("greeting")
+
+	// Then: the last deletion comes first, and each is taken once
+	if !ok1 || first.Position != 3 || !ok2 || second.Position != 0 {
+		t.Errorf("expected positions 3 then 0, got %+v %+v", first, second)
+	}
+	if _, ok := trash.Take("greeting"); ok {
+		t.Error("expected the trash to be empty")
+	}
+}
+
+func TestScenarioTrash_ZeroWindowMakesDeletionsFinal(t *testing.T) {
+	// Given: a trash without a restore window
+	trash := newScenarioTrash()
+	trash.SetWindow(0)
+
+	// When: a rule is deleted
+	d := trash.Add(&scenarioRule{name: "greeting"}, 0, deleteReasonDeleted)
+
+	// Then: its record expires as it is deleted and nothing is kept
+	if !d.ExpiresAt.Equal(d.DeletedAt) || len(trash.List()) != 0 {
+		t.Errorf("expected nothing kept, got %+v %v", d, trash.List())
+	}
+}
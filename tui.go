@@ -0,0 +1,313 @@
+//go:build !mokku_minimal
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+)
+
+// tuiChaosProfile is the chaos profile the TUI defines for its latency and error rate toggles.
+const tuiChaosProfile = "tui"
+
+// tuiRequests is the number of recent requests the TUI lists.
+const tuiRequests = 15
+
+// tuiLatencies and tuiErrorRates are the steps the latency and error rate toggles cycle through.
+var (
+	tuiLatencies  = []string{"", "250ms", "1s", "5s"}
+	tuiErrorRates = []float64{0, 0.05, 0.25, 1}
+)
+
+// tuiSnapshot is the state of a mokku instance shown by the TUI.
+type tuiSnapshot struct {
+	Requests []capturedExchange
+	Streams  streamStats
+	Chaos    chaosStatus
+	At       time.Time
+	Err      error
+}
+
+// tuiModel is the state of the TUI: what it last fetched from the instance and the toggles set
+// through it. Toggles are applied through the control API, so scripts and other TUIs see them too.
+type tuiModel struct {
+	base   string
+	token  string
+	client *http.Client
+
+	snapshot tuiSnapshot
+	// latency and errorRate index tuiLatencies and tuiErrorRates.
+	latency, errorRate int
+	// preset is the chaos profile activated with the preset toggle, "" for none.
+	preset string
+	// message reports the result of the last toggle.
+	message string
+}
+
+// runTUI implements the tui subcommand: a terminal UI for a developer running mokku next to their
+// app, showing live requests and streams with keys to toggle latency, error rate, and chaos presets.
+func runTUI(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	target := fs.String("target", "http://localhost:8080", "base URL of the mokku instance")
+	token := fs.String("token", os.Getenv("MOKKU_ADMIN_TOKEN"), "admin token of the control API (default $MOKKU_ADMIN_TOKEN)")
+	interval := fs.Duration("interval", time.Second, "refresh interval")
+	fs.Usage = func() {
+		_, _ = fmt.Fprintln(stderr, "usage: openai-mokku tui [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || *interval <= 0 {
+		fs.Usage()
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	restore := rawTerminal()
+	defer restore()
+	keys := make(chan byte)
+	go func() {
+		buf := make([]byte, 1)
+		for {
+			if _, err := os.Stdin.Read(buf); err != nil {
+				close(keys)
+				return
+			}
+			keys <- buf[0]
+		}
+	}()
+
+	m := &tuiModel{base: strings.TrimSuffix(*target, "/"), token: *token, client: &http.Client{Timeout: 5 * time.Second}}
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		m.refresh(ctx)
+		m.render(stdout)
+		select {
+		case <-ctx.Done():
+			return 0
+		case <-ticker.C:
+		case key, ok := <-keys:
+			if !ok || !m.handleKey(ctx, key) {
+				return 0
+			}
+		}
+	}
+}
+
+// rawTerminal switches the terminal to read keys without Enter and without echo, and returns the
+// function restoring it. Without a terminal (or stty), keys are read a line at a time.
+func rawTerminal() func() {
+	stty := func(args ...string) (string, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+	saved, err := stty("-g")
+	if err != nil {
+		return func() {}
+	}
+	if _, err := stty("-icanon", "-echo", "min", "1"); err != nil {
+		return func() {}
+	}
+	return func() { _, _ = stty(saved) }
+}
+
+// refresh fetches the recent requests, stream counters, and chaos status of the instance.
+func (m *tuiModel) refresh(ctx context.Context) {
+	s := tuiSnapshot{At: time.Now()}
+	var requests capturedRequestsResponse
+	s.Err = m.call(ctx, http.MethodGet, fmt.Sprintf("/_mokku/requests?limit=%d", tuiRequests), nil, &requests)
+	if s.Err == nil {
+		s.Err = m.call(ctx, http.MethodGet, "/_mokku/streams", nil, &s.Streams)
+	}
+	if s.Err == nil {
+		s.Err = m.call(ctx, http.MethodGet, "/_mokku/chaos", nil, &s.Chaos)
+	}
+	s.Requests = requests.Data
+	m.snapshot = s
+}
+
+// handleKey applies the toggle bound to key. It returns false when the TUI should quit.
+func (m *tuiModel) handleKey(ctx context.Context, key byte) bool {
+	var err error
+	switch key {
+	case 'q':
+		return false
+	case 'l':
+		m.latency = (m.latency + 1) % len(tuiLatencies)
+		err = m.applyFaults(ctx)
+	case 'e':
+		m.errorRate = (m.errorRate + 1) % len(tuiErrorRates)
+		err = m.applyFaults(ctx)
+	case 'p':
+		err = m.nextPreset(ctx)
+	case 'o':
+		m.latency, m.errorRate, m.preset = 0, 0, ""
+		err = m.call(ctx, http.MethodDelete, "/_mokku/chaos", nil, nil)
+		m.message = "all faults off"
+	case 'c':
+		err = m.call(ctx, http.MethodDelete, "/_mokku/requests", nil, nil)
+		m.message = "captured requests cleared"
+	default:
+		return true
+	}
+	if err != nil {
+		m.message = "error: " + err.Error()
+	}
+	return true
+}
+
+// applyFaults defines and activates tuiChaosProfile with the toggled latency and error rate, or
+// turns chaos off when both are off.
+func (m *tuiModel) applyFaults(ctx context.Context) error {
+	m.preset = ""
+	latency, errorRate := tuiLatencies[m.latency], tuiErrorRates[m.errorRate]
+	if latency == "" && errorRate == 0 {
+		m.message = "latency and errors off"
+		return m.call(ctx, http.MethodDelete, "/_mokku/chaos", nil, nil)
+	}
+	m.message = fmt.Sprintf("latency %s, error rate %s", tuiLatencyLabel(latency), tuiPercent(errorRate))
+	req := chaosRequest{Profile: tuiChaosProfile, Define: &chaosProfileConfig{
+		Description: "Latency and error rate set from the TUI",
+		Latency:     latency,
+		ErrorRate:   errorRate,
+	}}
+	return m.call(ctx, http.MethodPut, "/_mokku/chaos", req, nil)
+}
+
+// nextPreset activates the chaos profile after the current preset, in name order, or turns chaos
+// off after the last one. Profiles defined at runtime are not presets.
+func (m *tuiModel) nextPreset(ctx context.Context) error {
+	var presets []string
+	for _, p := range m.snapshot.Chaos.Profiles {
+		if !p.Defined {
+			presets = append(presets, p.Name)
+		}
+	}
+	next := ""
+	if i := slices.Index(presets, m.preset); i+1 < len(presets) {
+		next = presets[i+1]
+	}
+	m.latency, m.errorRate, m.preset = 0, 0, next
+	if next == "" {
+		m.message = "presets off"
+		return m.call(ctx, http.MethodDelete, "/_mokku/chaos", nil, nil)
+	}
+	m.message = "preset " + next
+	return m.call(ctx, http.MethodPut, "/_mokku/chaos", chaosRequest{Profile: next}, nil)
+}
+
+// call sends a control API request and decodes the JSON response into out, if not nil.
+func (m *tuiModel) call(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, m.base+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.token)
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		var apiErr OpenAIError
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("%s %s: %s", method, path, apiErr.Error.Message)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// render draws the screen: the instance's streams and chaos status, the toggles, and the recent
+// requests, newest first.
+func (m *tuiModel) render(w io.Writer) {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	s := m.snapshot
+	fmt.Fprintf(&b, "mokku %s  %s\r\n\r\n", m.base, s.At.Format("15:04:05"))
+	if s.Err != nil {
+		fmt.Fprintf(&b, "unreachable: %v\r\n\r\n", s.Err)
+	}
+	fmt.Fprintf(&b, "streams   %d active, %d started, %d completed, %d cancelled\r\n",
+		s.Streams.Started-s.Streams.Completed-s.Streams.Cancelled, s.Streams.Started, s.Streams.Completed, s.Streams.Cancelled)
+	chaos := "off"
+	if s.Chaos.Active != "" {
+		chaos = fmt.Sprintf("%s (%d requests, %d errors, %d rate limited, %d truncated)", s.Chaos.Active,
+			s.Chaos.Stats.Requests, s.Chaos.Stats.Errors, s.Chaos.Stats.RateLimited, s.Chaos.Stats.Truncated)
+	}
+	fmt.Fprintf(&b, "chaos     %s\r\n\r\n", chaos)
+	preset := m.preset
+	if preset == "" {
+		preset = "none"
+	}
+	fmt.Fprintf(&b, "[l] latency %s  [e] error rate %s  [p] preset %s  [o] all off  [c] clear requests  [q] quit\r\n",
+		tuiLatencyLabel(tuiLatencies[m.latency]), tuiPercent(tuiErrorRates[m.errorRate]), preset)
+	if m.message != "" {
+		fmt.Fprintf(&b, "> %s\r\n", m.message)
+	}
+	fmt.Fprintf(&b, "\r\n%-8s  %-6s  %-28s  %-16s  %-6s  %8s  %s\r\n", "TIME", "METHOD", "PATH", "MODEL", "STATUS", "DURATION", "STREAM")
+	for _, e := range s.Requests {
+		stream := ""
+		if e.Stream {
+			stream = "yes"
+			if e.Chunks != nil && e.Chunks.TokensPerSecond > 0 {
+				stream = fmt.Sprintf("%.0f tok/s", e.Chunks.TokensPerSecond)
+			}
+		}
+		fmt.Fprintf(&b, "%-8s  %-6s  %-28s  %-16s  %-6d  %6dms  %s\r\n", e.Time.Local().Format("15:04:05"), e.Method,
+			tuiTruncate(e.Path, 28), tuiTruncate(e.Model, 16), e.Response.Status, e.DurationMS, stream)
+	}
+	_, _ = io.WriteString(w, b.String())
+}
+
+// tuiLatencyLabel formats a latency toggle step.
+func tuiLatencyLabel(latency string) string {
+	if latency == "" {
+		return "off"
+	}
+	return latency
+}
+
+// tuiPercent formats an error rate toggle step.
+func tuiPercent(rate float64) string {
+	return fmt.Sprintf("%g%%", rate*100)
+}
+
+// tuiTruncate cuts s to n bytes for a table column.
+func tuiTruncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "…"
+}
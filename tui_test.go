@@ -0,0 +1,84 @@
+//go:build !mokku_minimal
+
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// --- tuiModel.handleKey ---
+
+func TestTUI_TogglesFaultsThroughControlAPI(t *testing.T) {
+	// Given: a TUI attached to a running instance
+	srv := newTestServer(t)
+	defer srv.Close()
+	ctx := context.Background()
+	m := &tuiModel{base: srv.URL, client: http.DefaultClient}
+	m.refresh(ctx)
+
+	// When: latency and error rate are each toggled one step
+	m.handleKey(ctx, 'l')
+	m.handleKey(ctx, 'e')
+	m.refresh(ctx)
+
+	// Then: the TUI's profile is active with both faults
+	chaos := m.snapshot.Chaos
+	if m.snapshot.Err != nil || chaos.Active != tuiChaosProfile {
+		t.Fatalf("expected the tui profile to be active, got %+v (%v)", chaos, m.snapshot.Err)
+	}
+	for _, p := range chaos.Profiles {
+		if p.Name == tuiChaosProfile && (p.Latency != "250ms" || p.ErrorRate != 0.05 || !p.Defined) {
+			t.Errorf("unexpected tui profile %+v", p)
+		}
+	}
+
+	// When: the preset toggle is pressed
+	m.handleKey(ctx, 'p')
+	m.refresh(ctx)
+
+	// Then: the first preset replaces the toggled faults
+	if m.snapshot.Chaos.Active != "flaky-network" || m.latency != 0 || m.errorRate != 0 {
+		t.Errorf("expected the flaky-network preset, got %q", m.snapshot.Chaos.Active)
+	}
+
+	// When: everything is switched off
+	m.handleKey(ctx, 'o')
+	m.refresh(ctx)
+
+	// Then
+	if m.snapshot.Chaos.Active != "" || m.message != "all faults off" {
+		t.Errorf("expected chaos to be off, got %q (%s)", m.snapshot.Chaos.Active, m.message)
+	}
+	if m.handleKey(ctx, 'q') {
+		t.Error("expected q to quit")
+	}
+}
+
+// --- tuiModel.render ---
+
+func TestTUI_RenderShowsRequestsAndToggles(t *testing.T) {
+	// Given: a streamed chat request served by the instance
+	srv := newTestServer(t)
+	defer srv.Close()
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"hi"}]}`)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	m := &tuiModel{base: srv.URL, client: http.DefaultClient}
+	m.refresh(context.Background())
+
+	// When
+	var out strings.Builder
+	m.render(&out)
+
+	// Then
+	screen := out.String()
+	for _, want := range []string{"/v1/chat/completions", "gpt-4o", "yes", "1 started, 1 completed", "chaos     off", "[l] latency off", "[e] error rate 0%"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("expected %q on the screen, got:\n%s", want, screen)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// watermarkHeader is the response header carrying the watermark of an API response.
+const watermarkHeader = "X-Mokku-Watermark"
+
+// Watermark modes of MOKKU_WATERMARK.
+const (
+	watermarkOff     = "off"
+	watermarkHeaders = "header"
+	watermarkContent = "content"
+)
+
+// watermarkNoScenario is the scenario of a watermark when no scenario matched.
+const watermarkNoScenario = "none"
+
+// watermarkModeFromEnv parses MOKKU_WATERMARK: off (the default), header to watermark every API
+// response in watermarkHeader, or content to also append the watermark to generated text.
+func watermarkModeFromEnv(value string) (string, error) {
+	switch value {
+	case "":
+		return watermarkOff, nil
+	case watermarkOff, watermarkHeaders, watermarkContent:
+		return value, nil
+	}
+	return "", fmt.Errorf("MOKKU_WATERMARK must be %q, %q, or %q, got %q", watermarkOff, watermarkHeaders, watermarkContent, value)
+}
+
+// watermark identifies the mock behavior that produced a response, so content seen in a failed end
+// to end test can be traced back to its request in the capture and the scenario that shaped it.
+type watermark struct {
+	// RequestID identifies the request; it is recorded in the capture as request_id.
+	RequestID string
+	// Scenario is the name of the matched scenario, watermarkNoScenario if none.
+	Scenario string
+	Seed     uint64
+	// content is set when the watermark is appended to generated text.
+	content bool
+}
+
+// newWatermark creates the watermark of a request whose randomized behavior is drawn from seed.
+func newWatermark(seed uint64, mode string) *watermark {
+	id := strings.ReplaceAll(uuid.New().String(), "-", "")
+	return &watermark{RequestID: "req_" + id[:16], Scenario: watermarkNoScenario, Seed: seed, content: mode == watermarkContent}
+}
+
+// String formats the watermark as the value of watermarkHeader.
+func (m *watermark) String() string {
+	return fmt.Sprintf("request=%s; scenario=%s; seed=%d", m.RequestID, m.Scenario, m.Seed)
+}
+
+// marker formats the watermark as appended to generated text.
+func (m *watermark) marker() string {
+	return fmt.Sprintf(" [mokku request=%s scenario=%s seed=%d]", m.RequestID, m.Scenario, m.Seed)
+}
+
+type watermarkContextKey struct{}
+
+// withWatermark stores the watermark of a request in the context.
+func withWatermark(ctx context.Context, m *watermark) context.Context {
+	return context.WithValue(ctx, watermarkContextKey{}, m)
+}
+
+// watermarkFromContext returns the watermark stored by withWatermark, or nil when watermarking is off.
+func watermarkFromContext(ctx context.Context) *watermark {
+	m, _ := ctx.Value(watermarkContextKey{}).(*watermark)
+	return m
+}
+
+// watermarkText appends the watermark of the request to generated text in content mode.
+func watermarkText(ctx context.Context, text string) string {
+	if m := watermarkFromContext(ctx); m != nil && m.content {
+		return text + m.marker()
+	}
+	return text
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// --- watermarkModeFromEnv ---
+
+func TestWatermarkModeFromEnv(t *testing.T) {
+	for value, want := range map[string]string{"": watermarkOff, "off": watermarkOff, "header": watermarkHeaders, "content": watermarkContent} {
+		if got, err := watermarkModeFromEnv(value); err != nil || got != want {
+			t.Errorf("%q: expected %q, got %q %v", value, want, got, err)
+		}
+	}
+	if _, err := watermarkModeFromEnv("body"); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+}
+
+// --- content watermarks ---
+
+func TestIntegration_Watermark_TracesContentToRequestAndScenario(t *testing.T) {
+	// Given: content watermarks and the weather scenario
+	path := filepath.Join(t.TempDir(), "scenarios.yml")
+	if err := os.WriteFile(path, []byte(testScenarios), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	state, err := newServerState(Config{}, serverOptions{ScenariosPath: path, Watermark: watermarkContent,
+		Capture: captureConfig{Size: defaultCaptureSize}, Seed: 42})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handler, _, err := state.newHandler("test-instance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	// When: one request matches the scenario and one is echoed
+	matched := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"weather in Tokyo?"}]}`)
+	defer func() { _ = matched.Body.Close() }()
+	echoed := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = echoed.Body.Close() }()
+
+	// Then: the content ends with the watermark in the header, which carries the request seed and
+	// finds the captured request
+	for _, tc := range []struct {
+		resp     *http.Response
+		scenario string
+	}{{matched, "weather"}, {echoed, watermarkNoScenario}} {
+		header, seed := tc.resp.Header.Get(watermarkHeader), tc.resp.Header.Get(seedHeader)
+		if !strings.Contains(header, "scenario="+tc.scenario+";") || seed == "" || !strings.HasSuffix(header, "seed="+seed) {
+			t.Fatalf("unexpected watermark %q for seed %q", header, seed)
+		}
+		requestID := strings.TrimPrefix(strings.Split(header, ";")[0], "request=")
+		choice := mustDecodeJSON(t, tc.resp.Body)["choices"].([]interface{})[0].(map[string]interface{})
+		content := choice["message"].(map[string]interface{})["content"].(string)
+		if want := " [mokku request=" + requestID + " scenario=" + tc.scenario + " seed=" + seed + "]"; !strings.HasSuffix(content, want) {
+			t.Errorf("expected content ending with %q, got %q", want, content)
+		}
+		if captured := state.capture.Query(captureFilter{RequestID: requestID}); len(captured) != 1 {
+			t.Errorf("expected the request %s to be captured once, got %d", requestID, len(captured))
+		}
+	}
+}
+
+func TestIntegration_Watermark_OffByDefault(t *testing.T) {
+	// Given
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	// When
+	resp := postJSON(t, srv.URL+"/v1/chat/completions", `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`)
+	defer func() { _ = resp.Body.Close() }()
+
+	// Then
+	if resp.Header.Get(watermarkHeader) != "" {
+		t.Errorf("expected no watermark, got %q", resp.Header.Get(watermarkHeader))
+	}
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// WebP lossless (VP8L) bitstream constants.
+const (
+	vp8lSignature = 0x2f
+	// vp8lMaxCodeLength is the longest prefix code VP8L allows.
+	vp8lMaxCodeLength = 15
+	// vp8lMaxCopyLength is the longest backward reference the 24 length prefix codes can express.
+	vp8lMaxCopyLength = 4096
+	// vp8lLengthCodes is the number of length prefix codes after the 256 green literals.
+	vp8lLengthCodes = 24
+	// vp8lDistanceCodes is the size of the distance alphabet.
+	vp8lDistanceCodes = 40
+	// Distance plane codes of the pixel above and the pixel to the left.
+	vp8lPlaneAbove = 1
+	vp8lPlaneLeft  = 2
+)
+
+// encodeWebP encodes img as a lossless WebP (VP8L) image. It uses no transforms and no color cache;
+// runs of pixels equal to the pixel above or to the left are sent as backward references, which is
+// what makes the flat color blocks and text panel of the placeholder images small.
+func encodeWebP(img image.Image) []byte {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	pixels := make([]uint32, 0, width*height)
+	alpha := false
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			alpha = alpha || c.A != 0xff
+			pixels = append(pixels, uint32(c.A)<<24|uint32(c.R)<<16|uint32(c.G)<<8|uint32(c.B))
+		}
+	}
+	symbols := vp8lSymbols(pixels, width)
+
+	// Histograms of the five alphabets: green and lengths, red, blue, alpha, distance
+	hist := [5][]int{
+		make([]int, 256+vp8lLengthCodes), make([]int, 256), make([]int, 256), make([]int, 256), make([]int, vp8lDistanceCodes),
+	}
+	for _, s := range symbols {
+		if s.length == 0 {
+			hist[0][s.argb>>8&0xff]++
+			hist[1][s.argb>>16&0xff]++
+			hist[2][s.argb&0xff]++
+			hist[3][s.argb>>24]++
+			continue
+		}
+		code, _, _ := vp8lPrefixEncode(s.length)
+		hist[0][256+code]++
+		code, _, _ = vp8lPrefixEncode(s.distance)
+		hist[4][code]++
+	}
+
+	w := &bitWriter{}
+	w.write(vp8lSignature, 8)
+	w.write(uint32(width-1), 14)
+	w.write(uint32(height-1), 14)
+	w.write(boolBit(alpha), 1)
+	w.write(0, 3) // version
+	w.write(0, 1) // no transform
+	w.write(0, 1) // no color cache
+	w.write(0, 1) // no meta prefix codes
+	var codes [5][]prefixCode
+	for i, h := range hist {
+		codes[i] = w.writePrefixCode(h)
+	}
+	for _, s := range symbols {
+		if s.length == 0 {
+			w.writeCode(codes[0][s.argb>>8&0xff])
+			w.writeCode(codes[1][s.argb>>16&0xff])
+			w.writeCode(codes[2][s.argb&0xff])
+			w.writeCode(codes[3][s.argb>>24])
+			continue
+		}
+		code, extraBits, extra := vp8lPrefixEncode(s.length)
+		w.writeCode(codes[0][256+code])
+		w.write(extra, extraBits)
+		code, extraBits, extra = vp8lPrefixEncode(s.distance)
+		w.writeCode(codes[4][code])
+		w.write(extra, extraBits)
+	}
+	data := w.bytes()
+
+	var out bytes.Buffer
+	chunk := len(data) + len(data)%2
+	out.WriteString("RIFF")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(4+8+chunk))
+	out.WriteString("WEBPVP8L")
+	_ = binary.Write(&out, binary.LittleEndian, uint32(len(data)))
+	out.Write(data)
+	if len(data)%2 == 1 {
+		out.WriteByte(0)
+	}
+	return out.Bytes()
+}
+
+// vp8lSymbol is a literal pixel or, with length set, a backward reference to the pixels at a
+// distance code.
+type vp8lSymbol struct {
+	argb     uint32
+	length   int
+	distance int
+}
+
+// vp8lSymbols splits pixels into literals and runs copying the row above or the previous pixel.
+func vp8lSymbols(pixels []uint32, width int) []vp8lSymbol {
+	var symbols []vp8lSymbol
+	run := func(i, offset int) int {
+		n := 0
+		for i+n < len(pixels) && n < vp8lMaxCopyLength && pixels[i+n] == pixels[i+n-offset] {
+			n++
+		}
+		return n
+	}
+	for i := 0; i < len(pixels); {
+		above, left := 0, 0
+		if i >= width {
+			above = run(i, width)
+		}
+		if i >= 1 {
+			left = run(i, 1)
+		}
+		switch {
+		case above >= 2 && above >= left:
+			symbols = append(symbols, vp8lSymbol{length: above, distance: vp8lPlaneAbove})
+			i += above
+		case left >= 2:
+			symbols = append(symbols, vp8lSymbol{length: left, distance: vp8lPlaneLeft})
+			i += left
+		default:
+			symbols = append(symbols, vp8lSymbol{argb: pixels[i]})
+			i++
+		}
+	}
+	return symbols
+}
+
+// vp8lPrefixEncode returns the prefix code, extra bit count, and extra bits of a length or distance
+// value (from 1).
+func vp8lPrefixEncode(value int) (code int, extraBits int, extra uint32) {
+	d := value - 1
+	if d < 4 {
+		return d, 0, 0
+	}
+	high := 0
+	for d>>(high+1) != 0 {
+		high++
+	}
+	second := (d >> (high - 1)) & 1
+	extraBits = high - 1
+	return 2*high + second, extraBits, uint32(d & (1<<extraBits - 1))
+}
+
+// prefixCode is the canonical code of a symbol; a zero length sends no bits.
+type prefixCode struct {
+	bits   uint32
+	length int
+}
+
+// writePrefixCode writes the prefix code of a histogram and returns the code of each symbol.
+// Alphabets of at most two literal symbols use the simple code; the others a normal code whose code
+// lengths are themselves sent with 4-bit codes.
+func (w *bitWriter) writePrefixCode(hist []int) []prefixCode {
+	var used []int
+	for s, n := range hist {
+		if n > 0 {
+			used = append(used, s)
+		}
+	}
+	codes := make([]prefixCode, len(hist))
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < 256) {
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		w.write(1, 1) // simple code
+		w.write(uint32(len(used)-1), 1)
+		w.write(1, 1) // first symbol in 8 bits
+		w.write(uint32(used[0]), 8)
+		if len(used) == 2 {
+			w.write(uint32(used[1]), 8)
+			codes[used[0]] = prefixCode{bits: 0, length: 1}
+			codes[used[1]] = prefixCode{bits: 1, length: 1}
+		}
+		return codes
+	}
+
+	lengths := make([]int, len(hist))
+	if len(used) == 1 {
+		// A lone symbol is given length 1 but, like the simple code, sends no bits.
+		lengths[used[0]] = 1
+	} else {
+		lengths = huffmanLengths(hist, vp8lMaxCodeLength)
+	}
+	w.write(0, 1)  // normal code
+	w.write(15, 4) // all 19 code length code lengths follow
+	// kCodeLengthCodeOrder: 17, 18, 0, 1, ..., 5, 16, 6, ..., 15. Lengths 0-15 get 4-bit codes and
+	// the repeat codes 16-18 are unused.
+	for _, symbol := range []int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15} {
+		if symbol >= 16 {
+			w.write(0, 3)
+		} else {
+			w.write(4, 3)
+		}
+	}
+	w.write(0, 1) // max_symbol is the alphabet size
+	for _, l := range lengths {
+		w.writeCode(prefixCode{bits: uint32(l), length: 4})
+	}
+	if len(used) == 1 {
+		return codes
+	}
+	return canonicalCodes(lengths)
+}
+
+// huffmanLengths returns the Huffman code lengths of a histogram with at least two used symbols,
+// none longer than maxLength: the counts are flattened until the tree is shallow enough.
+func huffmanLengths(hist []int, maxLength int) []int {
+	counts := slices.Clone(hist)
+	for {
+		lengths := huffmanTree(counts)
+		if slices.Max(lengths) <= maxLength {
+			return lengths
+		}
+		for i, n := range counts {
+			if n > 0 {
+				counts[i] = n/2 + 1
+			}
+		}
+	}
+}
+
+// huffmanTree returns the depth of every used symbol in a Huffman tree of the counts.
+func huffmanTree(counts []int) []int {
+	type node struct {
+		count   int
+		symbols []int
+	}
+	var nodes []node
+	for s, n := range counts {
+		if n > 0 {
+			nodes = append(nodes, node{count: n, symbols: []int{s}})
+		}
+	}
+	lengths := make([]int, len(counts))
+	for len(nodes) > 1 {
+		slices.SortStableFunc(nodes, func(a, b node) int { return a.count - b.count })
+		merged := node{count: nodes[0].count + nodes[1].count, symbols: append(slices.Clone(nodes[0].symbols), nodes[1].symbols...)}
+		for _, s := range merged.symbols {
+			lengths[s]++
+		}
+		nodes = append([]node{merged}, nodes[2:]...)
+	}
+	return lengths
+}
+
+// canonicalCodes assigns canonical codes to code lengths, shorter codes and lower symbols first.
+func canonicalCodes(lengths []int) []prefixCode {
+	codes := make([]prefixCode, len(lengths))
+	var next uint32
+	for length := 1; length <= vp8lMaxCodeLength; length++ {
+		for s, l := range lengths {
+			if l == length {
+				codes[s] = prefixCode{bits: next, length: length}
+				next++
+			}
+		}
+		next <<= 1
+	}
+	return codes
+}
+
+// bitWriter writes a VP8L bitstream, least significant bit first.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	count int
+}
+
+// write writes the n low bits of v.
+func (w *bitWriter) write(v uint32, n int) {
+	w.acc |= uint64(v&(1<<n-1)) << w.count
+	w.count += n
+	for w.count >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.count -= 8
+	}
+}
+
+// writeCode writes a prefix code, most significant bit first as the decoder reads it.
+func (w *bitWriter) writeCode(c prefixCode) {
+	for i := c.length - 1; i >= 0; i-- {
+		w.write(c.bits>>i&1, 1)
+	}
+}
+
+// bytes returns the bitstream, padded to a whole byte.
+func (w *bitWriter) bytes() []byte {
+	if w.count > 0 {
+		return append(w.buf, byte(w.acc))
+	}
+	return w.buf
+}
+
+func boolBit(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// --- encodeWebP ---
+
+func TestEncodeWebP_DecodesToTheSamePixels(t *testing.T) {
+	// Given: placeholder images, opaque and transparent, and a noisy image without runs
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 23))
+	for i := range noise.Pix {
+		noise.Pix[i] = byte(i*7919 + i*i)
+	}
+	images := map[string]image.Image{
+		"opaque":      renderImage("a red fox", 0, 256, 192, false),
+		"transparent": renderImage("a red fox", 1, 128, 128, true),
+		"noise":       noise,
+		"one pixel":   image.NewNRGBA(image.Rect(0, 0, 1, 1)),
+	}
+	for name, img := range images {
+		// When
+		data := encodeWebP(img)
+		decoded, err := webp.Decode(bytes.NewReader(data))
+		// Then
+		if err != nil {
+			t.Errorf("%s: expected a valid WebP, got %v", name, err)
+			continue
+		}
+		if decoded.Bounds().Size() != img.Bounds().Size() {
+			t.Errorf("%s: expected size %v, got %v", name, img.Bounds().Size(), decoded.Bounds().Size())
+			continue
+		}
+		if x, y, ok := firstDifference(img, decoded); !ok {
+			t.Errorf("%s: pixel (%d, %d) differs: %v, decoded %v", name, x, y,
+				color.NRGBAModel.Convert(img.At(x, y)), color.NRGBAModel.Convert(decoded.At(x, y)))
+		}
+	}
+}
+
+func TestEncodeWebP_CompressesPlaceholders(t *testing.T) {
+	// Given
+	img := renderImage("a lighthouse at dusk", 0, 1024, 1024, false)
+	// When
+	data := encodeWebP(img)
+	// Then: the flat blocks and text panel are far smaller than raw pixels
+	if raw := 4 * 1024 * 1024; len(data) > raw/20 {
+		t.Errorf("expected under %d bytes, got %d", raw/20, len(data))
+	}
+	if string(data[:4]) != "RIFF" || string(data[8:16]) != "WEBPVP8L" {
+		t.Errorf("expected a RIFF WEBP VP8L header, got %q", data[:16])
+	}
+}
+
+// firstDifference returns the first pixel where a and b differ, with ok false, or ok true if none.
+func firstDifference(a, b image.Image) (x, y int, ok bool) {
+	ab, bb := a.Bounds(), b.Bounds()
+	for y := 0; y < ab.Dy(); y++ {
+		for x := 0; x < ab.Dx(); x++ {
+			ca := color.NRGBAModel.Convert(a.At(ab.Min.X+x, ab.Min.Y+y))
+			cb := color.NRGBAModel.Convert(b.At(bb.Min.X+x, bb.Min.Y+y))
+			if ca != cb {
+				return x, y, false
+			}
+		}
+	}
+	return 0, 0, true
+}